@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+)
+
+var errNotBinary = errors.New("a imagem não é binária (só 0 e 255)")
+
+// packBits empacota a máscara em 1 bit por pixel, MSB primeiro, com cada
+// linha completada até o byte seguinte. Pixels 255 viram 1. Devolve o
+// buffer, a largura e a altura.
+func packBits(mask *image.Gray) ([]byte, int, int) {
+	b := mask.Bounds()
+	w, h := b.Dx(), b.Dy()
+	stride := (w + 7) / 8
+	data := make([]byte, stride*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if mask.GrayAt(b.Min.X+x, b.Min.Y+y).Y >= 128 {
+				data[y*stride+x/8] |= 0x80 >> (x % 8)
+			}
+		}
+	}
+	return data, w, h
+}
+
+// unpackBits é o inverso de packBits; data precisa ter as h linhas de
+// (w+7)/8 bytes.
+func unpackBits(data []byte, w, h int) (*image.Gray, error) {
+	if w < 0 || h < 0 {
+		return nil, fmt.Errorf("tamanho inválido %dx%d", w, h)
+	}
+	stride := (w + 7) / 8
+	if h > 0 && len(data)/h < stride {
+		return nil, fmt.Errorf("máscara de 1 bit curta: %dx%d precisa de %d bytes, há %d", w, h, stride*h, len(data))
+	}
+	mask := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if data[y*stride+x/8]&(0x80>>(x%8)) != 0 {
+				mask.Pix[y*mask.Stride+x] = 255
+			}
+		}
+	}
+	return mask, nil
+}
+
+// toPaletted1 converte uma máscara binária para uma imagem de duas cores,
+// que o encoder PNG grava com profundidade de 1 bit.
+func toPaletted1(img image.Image) (*image.Paletted, error) {
+	gray := grayscale(img)
+	if !isBinary(gray) {
+		return nil, errNotBinary
+	}
+	b := gray.Bounds()
+	result := image.NewPaletted(b, color.Palette{color.Gray{0}, color.Gray{255}})
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if gray.GrayAt(x, y).Y == 255 {
+				result.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPackBitsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		mask       *image.Gray
+		wantStride int
+	}{
+		{"largura múltipla de 8", randomMask(16, 5, 0.5, 1), 2},
+		{"largura 1", randomMask(1, 7, 0.5, 2), 1},
+		{"largura 13", randomMask(13, 9, 0.3, 3), 2},
+		{"largura 9", randomMask(9, 4, 0.7, 4), 2},
+		{"recorte", randomMask(40, 40, 0.5, 5).SubImage(image.Rect(3, 5, 20, 17)).(*image.Gray), 3},
+		{"vazia", image.NewGray(image.Rect(0, 0, 0, 0)), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, w, h := packBits(tt.mask)
+			b := tt.mask.Bounds()
+			if w != b.Dx() || h != b.Dy() || len(data) != tt.wantStride*h {
+				t.Fatalf("%d bytes, %dx%d; esperado %d bytes, %dx%d", len(data), w, h, tt.wantStride*b.Dy(), b.Dx(), b.Dy())
+			}
+			got, err := unpackBits(data, w, h)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for y := 0; y < h; y++ {
+				for x := 0; x < w; x++ {
+					if got.GrayAt(x, y) != tt.mask.GrayAt(b.Min.X+x, b.Min.Y+y) {
+						t.Fatalf("pixel %d,%d difere após empacotar", x, y)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPackBitsLayout(t *testing.T) {
+	// linha de 10 pixels: brancos em 0, 7 e 9
+	mask := image.NewGray(image.Rect(0, 0, 10, 1))
+	mask.Pix[0], mask.Pix[7], mask.Pix[9] = 255, 255, 255
+	data, _, _ := packBits(mask)
+	if want := []byte{0x81, 0x40}; !bytes.Equal(data, want) {
+		t.Errorf("bytes %x, esperado %x", data, want)
+	}
+}
+
+func TestUnpackBitsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		w, h int
+	}{
+		{"vazio", nil, 8, 1},
+		{"uma linha a menos", make([]byte, 2*3), 10, 4},
+		{"linha curta", make([]byte, 5), 17, 2},
+		{"largura negativa", nil, -1, 1},
+	}
+	for _, tt := range tests {
+		if _, err := unpackBits(tt.data, tt.w, tt.h); err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+		}
+	}
+	// bytes sobrando no fim não atrapalham
+	if _, err := unpackBits(make([]byte, 9), 10, 4); err != nil {
+		t.Errorf("dados com sobra: %v", err)
+	}
+}
+
+// withBitDepth troca -bitdepth durante o teste.
+func withBitDepth(t *testing.T, depth int) {
+	old := *bitDepth
+	*bitDepth = depth
+	t.Cleanup(func() { *bitDepth = old })
+}
+
+func TestSaveImageOneBit(t *testing.T) {
+	withBitDepth(t, 1)
+	dir := t.TempDir()
+	mask := randomMask(1024, 1024, 0.5, 6)
+
+	path := filepath.Join(dir, "mask.png")
+	if err := saveImage(path, mask); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadImage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Pix, mask.Pix) {
+		t.Error("PNG de 1 bit não decodifica para a mesma máscara")
+	}
+
+	withBitDepth(t, 8)
+	path8 := filepath.Join(dir, "mask8.png")
+	if err := saveImage(path8, mask); err != nil {
+		t.Fatal(err)
+	}
+	one, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eight, err := os.Stat(path8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ruído não comprime: o PNG de 1 bit fica perto do buffer empacotado,
+	// 1/8 dos pixels em 8 bits, e abaixo do PNG de 8 bits, que o deflate
+	// só consegue reduzir a uns 2 bits por pixel
+	packed := int64(1024 * 1024 / 8)
+	if one.Size() > packed*21/20 {
+		t.Errorf("PNG de 1 bit com %d bytes, esperado perto de %d", one.Size(), packed)
+	}
+	if one.Size() >= eight.Size() {
+		t.Errorf("PNG de 1 bit com %d bytes, não menor que o de 8 bits (%d)", one.Size(), eight.Size())
+	}
+}
+
+func TestSaveImageOneBitErrors(t *testing.T) {
+	withBitDepth(t, 1)
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		path string
+		img  image.Image
+	}{
+		{"não binária", "gray.png", uniformGray(8, 128)},
+		{"formato sem 1 bit", "mask.jpg", uniformGray(8, 255)},
+	}
+	for _, tt := range tests {
+		if err := saveImage(filepath.Join(dir, tt.path), tt.img); err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+		}
+	}
+	if err := saveImage(filepath.Join(dir, "gray.png"), uniformGray(8, 128)); !errors.Is(err, errNotBinary) {
+		t.Errorf("erro %v, esperado errNotBinary", err)
+	}
+}
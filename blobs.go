@@ -0,0 +1,163 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"processing-images/imaging"
+)
+
+// Blob é uma detecção do espaço de escalas: centro, raio característico
+// (√2·σ) e intensidade da resposta LoG normalizada.
+type Blob struct {
+	X        int     `json:"x"`
+	Y        int     `json:"y"`
+	Sigma    float64 `json:"sigma"`
+	Radius   float64 `json:"radius"`
+	Response float64 `json:"response"`
+}
+
+// logScaleSpace devolve σ²∇²(G_σ * I) para cada σ. O sinal é positivo no
+// centro de blobs escuros.
+func logScaleSpace(img *image.Gray, sigmas []float64) [][][]float64 {
+	source := imaging.GrayToFloat(img)
+	stack := make([][][]float64, len(sigmas))
+	for s, sigma := range sigmas {
+		smooth := imaging.SmoothFloat(source, sigma)
+		height := len(smooth)
+		layer := make([][]float64, height)
+		for y := 0; y < height; y++ {
+			width := len(smooth[y])
+			layer[y] = make([]float64, width)
+			for x := 0; x < width; x++ {
+				xm, xp := max(x-1, 0), min(x+1, width-1)
+				ym, yp := max(y-1, 0), min(y+1, height-1)
+				laplacian := smooth[y][xm] + smooth[y][xp] + smooth[ym][x] + smooth[yp][x] - 4*smooth[y][x]
+				layer[y][x] = sigma * sigma * laplacian
+			}
+		}
+		stack[s] = layer
+	}
+	return stack
+}
+
+// blobSigmas gera steps escalas em progressão geométrica de minSigma a maxSigma.
+func blobSigmas(minSigma, maxSigma float64, steps int) []float64 {
+	if steps < 2 || maxSigma <= minSigma {
+		return []float64{minSigma}
+	}
+	ratio := math.Pow(maxSigma/minSigma, 1/float64(steps-1))
+	sigmas := make([]float64, steps)
+	for i := range sigmas {
+		sigmas[i] = minSigma * math.Pow(ratio, float64(i))
+	}
+	return sigmas
+}
+
+// detectBlobs procura máximos locais da resposta LoG em (x, y, escala) acima
+// do limiar e remove detecções sobrepostas, mantendo a mais forte. Com
+// bright=true procura blobs claros em fundo escuro.
+func detectBlobs(img *image.Gray, sigmas []float64, threshold float64, bright bool) []Blob {
+	stack := logScaleSpace(img, sigmas)
+	if bright {
+		for _, layer := range stack {
+			for _, row := range layer {
+				for x := range row {
+					row[x] = -row[x]
+				}
+			}
+		}
+	}
+
+	var blobs []Blob
+	for s, layer := range stack {
+		for y := 1; y < len(layer)-1; y++ {
+			for x := 1; x < len(layer[y])-1; x++ {
+				v := layer[y][x]
+				if v <= threshold || !isScaleSpaceMax(stack, s, x, y) {
+					continue
+				}
+				blobs = append(blobs, Blob{
+					X: x, Y: y,
+					Sigma:    sigmas[s],
+					Radius:   math.Sqrt2 * sigmas[s],
+					Response: v,
+				})
+			}
+		}
+	}
+
+	return suppressBlobs(blobs, 0.5)
+}
+
+// isScaleSpaceMax verifica se o ponto é máximo na vizinhança 3x3x3.
+func isScaleSpaceMax(stack [][][]float64, s, x, y int) bool {
+	v := stack[s][y][x]
+	for ds := -1; ds <= 1; ds++ {
+		if s+ds < 0 || s+ds >= len(stack) {
+			continue
+		}
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if ds == 0 && dx == 0 && dy == 0 {
+					continue
+				}
+				if stack[s+ds][y+dy][x+dx] >= v {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// suppressBlobs descarta blobs cuja área sobreposta a um blob mais forte
+// passa da fração maxOverlap da área do menor.
+func suppressBlobs(blobs []Blob, maxOverlap float64) []Blob {
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].Response > blobs[j].Response })
+
+	var kept []Blob
+	for _, b := range blobs {
+		keep := true
+		for _, k := range kept {
+			d := math.Hypot(float64(b.X-k.X), float64(b.Y-k.Y))
+			small := math.Min(b.Radius, k.Radius)
+			if circleOverlap(d, b.Radius, k.Radius) > maxOverlap*math.Pi*small*small {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			kept = append(kept, b)
+		}
+	}
+	return kept
+}
+
+// circleOverlap é a área de interseção de dois círculos com centros a
+// distância d.
+func circleOverlap(d, r1, r2 float64) float64 {
+	if d >= r1+r2 {
+		return 0
+	}
+	if d <= math.Abs(r1-r2) {
+		r := math.Min(r1, r2)
+		return math.Pi * r * r
+	}
+	a1 := r1 * r1 * math.Acos((d*d+r1*r1-r2*r2)/(2*d*r1))
+	a2 := r2 * r2 * math.Acos((d*d+r2*r2-r1*r1)/(2*d*r2))
+	a3 := 0.5 * math.Sqrt((-d+r1+r2)*(d+r1-r2)*(d-r1+r2)*(d+r1+r2))
+	return a1 + a2 - a3
+}
+
+// blobOverlay desenha um círculo vermelho com o raio de cada blob.
+func blobOverlay(img *image.Gray, blobs []Blob) *image.RGBA {
+	result := grayToRGBA(img)
+	red := color.RGBA{255, 0, 0, 255}
+	for _, b := range blobs {
+		drawCircle(result, image.Pt(b.X, b.Y), int(math.Round(b.Radius)), red)
+	}
+	return result
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+func TestDetectBlobs(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 160, 100))
+	for i := range img.Pix {
+		img.Pix[i] = synthetic.Background
+	}
+	circles := []synthetic.Circle{
+		{Center: image.Pt(20, 50), Radius: 4},
+		{Center: image.Pt(55, 50), Radius: 8},
+		{Center: image.Pt(110, 50), Radius: 16},
+	}
+	for _, c := range circles {
+		synthetic.FillCircle(img, c.Center, c.Radius, synthetic.Foreground)
+	}
+
+	blobs := detectBlobs(img, blobSigmas(2, 16, 13), 10, false)
+	if len(blobs) != len(circles) {
+		t.Fatalf("%d blobs detectados, esperado %d: %+v", len(blobs), len(circles), blobs)
+	}
+	for _, c := range circles {
+		found := 0
+		for _, b := range blobs {
+			if math.Hypot(float64(b.X-c.Center.X), float64(b.Y-c.Center.Y)) > float64(c.Radius)/2 {
+				continue
+			}
+			found++
+			if e := math.Abs(b.Radius-float64(c.Radius)) / float64(c.Radius); e > 0.2 {
+				t.Errorf("círculo de raio %d detectado com raio %.2f", c.Radius, b.Radius)
+			}
+		}
+		if found != 1 {
+			t.Errorf("círculo de raio %d detectado %d vezes, esperado 1", c.Radius, found)
+		}
+	}
+}
+
+func TestDetectBlobsBright(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 64, 64))
+	synthetic.FillCircle(img, image.Pt(32, 32), 8, 255)
+	tests := []struct {
+		bright bool
+		want   int
+	}{
+		{true, 1},
+		{false, 0},
+	}
+	for _, tt := range tests {
+		blobs := detectBlobs(img, blobSigmas(2, 16, 13), 10, tt.bright)
+		// com a polaridade errada o disco claro não vira blob
+		n := 0
+		for _, b := range blobs {
+			if b.X == 32 && b.Y == 32 {
+				n++
+			}
+		}
+		if n != tt.want {
+			t.Errorf("bright=%v: %d blobs no centro, esperado %d", tt.bright, n, tt.want)
+		}
+	}
+}
+
+func TestCircleOverlap(t *testing.T) {
+	tests := []struct {
+		name      string
+		d, r1, r2 float64
+		want      float64
+	}{
+		{"disjuntos", 10, 3, 3, 0},
+		{"tangentes", 6, 3, 3, 0},
+		{"contido", 1, 5, 2, 4 * math.Pi},
+		{"concêntricos iguais", 0, 3, 3, 9 * math.Pi},
+		// dois círculos unitários a distância 1: 2π/3 − √3/2
+		{"lente", 1, 1, 1, 2*math.Pi/3 - math.Sqrt(3)/2},
+	}
+	for _, tt := range tests {
+		if got := circleOverlap(tt.d, tt.r1, tt.r2); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: área %v, esperado %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// A biblioteca padrão não tem BMP. Estas funções gravam e leem o caso sem
+// compressão (BI_RGB): 8 bits com paleta (imagens em tons de cinza, com a
+// paleta de cinzas) e 24 ou 32 bits por pixel.
+
+func init() {
+	image.RegisterFormat("bmp", "BM", decodeBMP, decodeBMPConfig)
+}
+
+const bmpHeaderSize = 14 + 40 // cabeçalho do arquivo + BITMAPINFOHEADER
+
+var errBMPUnsupported = errors.New("BMP não suportado: só 8, 24 ou 32 bits sem compressão")
+
+// encodeBMP grava a imagem como BMP: *image.Gray em 8 bits com paleta de
+// cinzas, o resto em 24 bits. As linhas vão de baixo para cima, cada uma
+// completada até um múltiplo de 4 bytes.
+func encodeBMP(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	gray, isGray := img.(*image.Gray)
+	bpp, paletteSize := 24, 0
+	if isGray {
+		bpp, paletteSize = 8, 256*4
+	}
+	rowSize := (b.Dx()*bpp/8 + 3) &^ 3
+	offset := bmpHeaderSize + paletteSize
+
+	header := make([]byte, offset)
+	header[0], header[1] = 'B', 'M'
+	binary.LittleEndian.PutUint32(header[2:], uint32(offset+rowSize*b.Dy()))
+	binary.LittleEndian.PutUint32(header[10:], uint32(offset))
+	binary.LittleEndian.PutUint32(header[14:], 40)
+	binary.LittleEndian.PutUint32(header[18:], uint32(b.Dx()))
+	binary.LittleEndian.PutUint32(header[22:], uint32(b.Dy()))
+	binary.LittleEndian.PutUint16(header[26:], 1)
+	binary.LittleEndian.PutUint16(header[28:], uint16(bpp))
+	binary.LittleEndian.PutUint32(header[34:], uint32(rowSize*b.Dy()))
+	if isGray {
+		binary.LittleEndian.PutUint32(header[46:], 256)
+		for v := 0; v < 256; v++ {
+			p := header[bmpHeaderSize+4*v:]
+			p[0], p[1], p[2] = uint8(v), uint8(v), uint8(v)
+		}
+	}
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(header); err != nil {
+		return err
+	}
+
+	row := make([]byte, rowSize)
+	for y := b.Max.Y - 1; y >= b.Min.Y; y-- {
+		if isGray {
+			start := gray.PixOffset(b.Min.X, y)
+			copy(row, gray.Pix[start:start+b.Dx()])
+		} else {
+			for x := 0; x < b.Dx(); x++ {
+				c := color.RGBAModel.Convert(img.At(b.Min.X+x, y)).(color.RGBA)
+				row[3*x], row[3*x+1], row[3*x+2] = c.B, c.G, c.R
+			}
+		}
+		if _, err := bw.Write(row); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// bmpInfo é o que interessa do cabeçalho de um BMP.
+type bmpInfo struct {
+	width, height int
+	topDown       bool
+	bpp           int
+	offset        int
+	palette       color.Palette
+}
+
+func readBMPHeader(r io.Reader) (bmpInfo, error) {
+	var info bmpInfo
+	header := make([]byte, bmpHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return info, err
+	}
+	if header[0] != 'B' || header[1] != 'M' {
+		return info, errors.New("não é um BMP")
+	}
+	info.offset = int(binary.LittleEndian.Uint32(header[10:]))
+	infoSize := int(binary.LittleEndian.Uint32(header[14:]))
+	width := int(int32(binary.LittleEndian.Uint32(header[18:])))
+	height := int(int32(binary.LittleEndian.Uint32(header[22:])))
+	info.bpp = int(binary.LittleEndian.Uint16(header[28:]))
+	compression := binary.LittleEndian.Uint32(header[30:])
+	colors := int(binary.LittleEndian.Uint32(header[46:]))
+	// 32 bits às vezes vem com BI_BITFIELDS (3) na ordem BGRA de sempre
+	if infoSize < 40 || width <= 0 || height == 0 || compression != 0 && !(compression == 3 && info.bpp == 32) ||
+		info.bpp != 8 && info.bpp != 24 && info.bpp != 32 {
+		return info, errBMPUnsupported
+	}
+	info.width, info.height = width, height
+	if height < 0 {
+		info.height, info.topDown = -height, true
+	}
+	if info.width > maxDecodePixels/info.height {
+		return info, fmt.Errorf("BMP grande demais: %dx%d", info.width, info.height)
+	}
+
+	// o resto do cabeçalho (versões maiores que 40 bytes) e a paleta; o
+	// offset vem do arquivo, então o buffer cresce com o que é lido
+	n := max(0, info.offset-bmpHeaderSize)
+	extra, err := io.ReadAll(io.LimitReader(r, int64(n)))
+	if err != nil {
+		return info, err
+	}
+	if len(extra) < n {
+		return info, fmt.Errorf("BMP truncado: os pixels deviam começar no byte %d", info.offset)
+	}
+	if info.bpp == 8 {
+		if colors == 0 {
+			colors = 256
+		}
+		if colors > 256 || len(extra) < infoSize-40+4*colors {
+			return info, fmt.Errorf("paleta do BMP inválida")
+		}
+		pal := extra[infoSize-40:]
+		for i := 0; i < colors; i++ {
+			info.palette = append(info.palette, color.RGBA{pal[4*i+2], pal[4*i+1], pal[4*i], 255})
+		}
+	}
+	return info, nil
+}
+
+func decodeBMPConfig(r io.Reader) (image.Config, error) {
+	info, err := readBMPHeader(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	model := color.RGBAModel
+	if info.bpp == 8 {
+		model = info.palette
+	}
+	return image.Config{ColorModel: model, Width: info.width, Height: info.height}, nil
+}
+
+// decodeBMP lê um BMP sem compressão; 8 bits com paleta de cinzas vira
+// *image.Gray, o resto *image.RGBA (o alfa de 32 bits é ignorado).
+func decodeBMP(r io.Reader) (image.Image, error) {
+	info, err := readBMPHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	// os pixels são lidos antes de alocar a imagem, para o tamanho do
+	// cabeçalho não valer mais que os dados
+	rowSize := (info.width*info.bpp/8 + 3) &^ 3
+	data, err := io.ReadAll(io.LimitReader(r, int64(rowSize*info.height)))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < rowSize*info.height {
+		return nil, fmt.Errorf("BMP truncado: esperava %d bytes de pixels, há %d", rowSize*info.height, len(data))
+	}
+	// só a paleta completa de cinzas vira *image.Gray: numa mais curta os
+	// índices precisam ser conferidos, como nas outras paletas
+	grayPalette := info.bpp == 8 && len(info.palette) == 256
+	for i, c := range info.palette {
+		if c != (color.RGBA{uint8(i), uint8(i), uint8(i), 255}) {
+			grayPalette = false
+		}
+	}
+
+	rect := image.Rect(0, 0, info.width, info.height)
+	var gray *image.Gray
+	var rgba *image.RGBA
+	if grayPalette {
+		gray = image.NewGray(rect)
+	} else {
+		rgba = image.NewRGBA(rect)
+	}
+	for i := 0; i < info.height; i++ {
+		row := data[i*rowSize : (i+1)*rowSize]
+		y := info.height - 1 - i
+		if info.topDown {
+			y = i
+		}
+		for x := 0; x < info.width; x++ {
+			switch {
+			case grayPalette:
+				gray.Pix[y*gray.Stride+x] = row[x]
+			case info.bpp == 8:
+				if int(row[x]) >= len(info.palette) {
+					return nil, fmt.Errorf("índice %d fora da paleta do BMP", row[x])
+				}
+				rgba.Set(x, y, info.palette[row[x]])
+			default:
+				p := row[x*info.bpp/8:]
+				rgba.Pix[y*rgba.Stride+4*x] = p[2]
+				rgba.Pix[y*rgba.Stride+4*x+1] = p[1]
+				rgba.Pix[y*rgba.Stride+4*x+2] = p[0]
+				rgba.Pix[y*rgba.Stride+4*x+3] = 255
+			}
+		}
+	}
+	if grayPalette {
+		return gray, nil
+	}
+	return rgba, nil
+}
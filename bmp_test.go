@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Os arquivos de testdata têm 13x7 pixels (linhas do BMP com enchimento) e
+// o mesmo desenho: cinza (17x + 29y) mod 256 nos de tons de cinza e
+// RGB (40x, 30y, 20(x+y)) mod 256 nos coloridos.
+//   - gray8.bmp: 8 bits com paleta de cinzas, de baixo para cima
+//   - rgb24-topdown.bmp: 24 bits com altura negativa, de cima para baixo
+//   - gray-strips.tif: little-endian, cinza sem compressão, faixas de 3 linhas
+//   - rgb-lzw.tif: big-endian, RGB em LZW com preditor, faixas de 4 linhas
+
+func fixtureGray(x, y int) uint8 { return uint8((x*17 + y*29) % 256) }
+
+func fixtureRGB(x, y int) color.RGBA {
+	return color.RGBA{uint8(x * 40 % 256), uint8(y * 30 % 256), uint8((x + y) * 20 % 256), 255}
+}
+
+func TestLoadFixtures(t *testing.T) {
+	tests := []struct {
+		file   string
+		format string
+		color  bool
+	}{
+		{"gray8.bmp", "bmp", false},
+		{"rgb24-topdown.bmp", "bmp", true},
+		{"gray-strips.tif", "tiff", false},
+		{"rgb-lzw.tif", "tiff", true},
+	}
+	for _, tt := range tests {
+		path := "testdata/" + tt.file
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config, format, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil || format != tt.format || config.Width != 13 || config.Height != 7 {
+			t.Errorf("%s: %s %dx%d (%v), esperado %s 13x7", tt.file, format, config.Width, config.Height, err, tt.format)
+		}
+
+		img, _, err := decodeImage(path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.file, err)
+		}
+		for y := 0; y < 7; y++ {
+			for x := 0; x < 13; x++ {
+				want := color.RGBA{fixtureGray(x, y), fixtureGray(x, y), fixtureGray(x, y), 255}
+				if tt.color {
+					want = fixtureRGB(x, y)
+				}
+				if got := color.RGBAModel.Convert(img.At(x, y)); got != want {
+					t.Fatalf("%s: pixel %d,%d = %v, esperado %v", tt.file, x, y, got, want)
+				}
+			}
+		}
+
+		// loadImage converte para cinza sem nada a mais do chamador
+		gray, err := loadImage(path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.file, err)
+		}
+		if gray.Bounds() != image.Rect(0, 0, 13, 7) {
+			t.Errorf("%s: limites %v, esperado 13x7", tt.file, gray.Bounds())
+		}
+		if !tt.color && gray.GrayAt(5, 3).Y != fixtureGray(5, 3) {
+			t.Errorf("%s: cinza %d, esperado %d", tt.file, gray.GrayAt(5, 3).Y, fixtureGray(5, 3))
+		}
+	}
+}
+
+func TestBMPRoundTrip(t *testing.T) {
+	rgba := image.NewRGBA(image.Rect(0, 0, 6, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 6; x++ {
+			rgba.SetRGBA(x, y, fixtureRGB(x, y))
+		}
+	}
+	tests := []struct {
+		name string
+		img  image.Image
+	}{
+		{"cinza", randomImage(13, 7, 2)},
+		{"cinza de largura múltipla de 4", randomImage(8, 5, 3)},
+		{"SubImage", randomImage(20, 20, 4).SubImage(image.Rect(3, 5, 10, 9))},
+		{"RGB", rgba},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := encodeBMP(&buf, tt.img); err != nil {
+			t.Fatal(err)
+		}
+		got, err := decodeBMP(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		_, isGray := tt.img.(*image.Gray)
+		if _, gotGray := got.(*image.Gray); gotGray != isGray {
+			t.Errorf("%s: decodificado como %T", tt.name, got)
+		}
+		if got.Bounds().Size() != tt.img.Bounds().Size() || maxChannelDiff(got, tt.img) != 0 {
+			t.Errorf("%s: a imagem decodificada difere da gravada", tt.name)
+		}
+	}
+}
+
+func TestDecodeBMPErrors(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/rgb24-topdown.bmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	with := func(offset int, values ...byte) []byte {
+		data := bytes.Clone(fixture)
+		copy(data[offset:], values)
+		return data
+	}
+	tests := []struct {
+		name        string
+		data        []byte
+		unsupported bool
+	}{
+		{"vazio", nil, false},
+		{"assinatura errada", with(0, 'X', 'X'), false},
+		{"pixels truncados", fixture[:len(fixture)-10], false},
+		{"cabeçalho truncado", fixture[:30], false},
+		{"16 bits", with(28, 16), true},
+		{"RLE", with(30, 1), true},
+		{"largura zero", with(18, 0), true},
+	}
+	for _, tt := range tests {
+		_, err := decodeBMP(bytes.NewReader(tt.data))
+		if err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+			continue
+		}
+		if errors.Is(err, errBMPUnsupported) != tt.unsupported {
+			t.Errorf("%s: errors.Is(errBMPUnsupported) = %v: %v", tt.name, !tt.unsupported, err)
+		}
+	}
+	if _, err := decodeBMP(bytes.NewReader(fixture[:len(fixture)-10])); !strings.Contains(err.Error(), "truncado") {
+		t.Errorf("pixels truncados: %v", err)
+	}
+}
+
+func TestDecodeBMPShortGrayPalette(t *testing.T) {
+	// a paleta de 16 cinzas (0 a 15) declarada no cabeçalho de um BMP de 8
+	// bits; os pixels continuam no mesmo offset
+	shortPalette := func(img *image.Gray) []byte {
+		var buf bytes.Buffer
+		if err := encodeBMP(&buf, img); err != nil {
+			t.Fatal(err)
+		}
+		data := buf.Bytes()
+		data[46], data[47] = 16, 0
+		return data
+	}
+	tests := []struct {
+		name  string
+		value uint8
+		ok    bool
+	}{
+		{"índice na paleta", 15, true},
+		{"índice fora da paleta", 200, false},
+	}
+	for _, tt := range tests {
+		img := image.NewGray(image.Rect(0, 0, 3, 2))
+		img.Pix[4] = tt.value
+		got, err := decodeBMP(bytes.NewReader(shortPalette(img)))
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if err == nil && maxChannelDiff(got, img) != 0 {
+			t.Errorf("%s: a imagem decodificada difere da gravada", tt.name)
+		}
+	}
+}
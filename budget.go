@@ -0,0 +1,125 @@
+package main
+
+import (
+	"image"
+	"math"
+
+	"processing-images/imaging"
+)
+
+// fitMegapixels reduz a imagem (média de área) até caber em maxMP megapixels
+// e devolve o fator de escala original/processada (1 quando já cabe ou
+// maxMP <= 0, e aí a imagem volta sem alteração). Várias etapas alocam
+// buffers float64 do tamanho da imagem, então isso limita a memória.
+func fitMegapixels(img image.Image, maxMP float64) (image.Image, float64) {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	pixels := float64(w) * float64(h)
+	if maxMP <= 0 || pixels <= maxMP*1e6 {
+		return img, 1
+	}
+	scale := math.Sqrt(maxMP * 1e6 / pixels)
+	// floor garante que o resultado não passa do orçamento
+	tw := max(1, int(float64(w)*scale))
+	th := max(1, int(float64(h)*scale))
+
+	var result image.Image
+	if gray, ok := img.(*image.Gray); ok {
+		result = resizeArea(gray, tw, th)
+	} else {
+		channels := splitChannels(img)
+		for c := range channels {
+			channels[c] = resizeArea(channels[c], tw, th)
+		}
+		result = mergeChannels(channels)
+	}
+	return result, float64(w) / float64(tw)
+}
+
+// As medidas geométricas são calculadas na imagem reduzida; as funções
+// abaixo levam os relatórios de volta às coordenadas da original. Um pixel
+// reduzido cobre factor pixels originais, então o centro do pixel x vai para
+// (x+0.5)·factor − 0.5.
+
+func scaleCoord(v, factor float64) float64 {
+	return (v+0.5)*factor - 0.5
+}
+
+func scaleRect(r image.Rectangle, factor float64) image.Rectangle {
+	if factor == 1 {
+		return r
+	}
+	return image.Rect(
+		int(math.Round(float64(r.Min.X)*factor)), int(math.Round(float64(r.Min.Y)*factor)),
+		int(math.Round(float64(r.Max.X)*factor)), int(math.Round(float64(r.Max.Y)*factor)),
+	)
+}
+
+func scalePoint(p image.Point, factor float64) image.Point {
+	if factor == 1 {
+		return p
+	}
+	return image.Pt(int(math.Round(scaleCoord(float64(p.X), factor))), int(math.Round(scaleCoord(float64(p.Y), factor))))
+}
+
+func scaleBlobs(blobs []Blob, factor float64) []Blob {
+	if factor == 1 {
+		return blobs
+	}
+	scaled := make([]Blob, len(blobs))
+	for i, b := range blobs {
+		p := scalePoint(image.Pt(b.X, b.Y), factor)
+		b.X, b.Y = p.X, p.Y
+		b.Sigma *= factor
+		b.Radius *= factor
+		scaled[i] = b
+	}
+	return scaled
+}
+
+func scaleMSER(regions []MSERRegion, factor float64) []MSERRegion {
+	if factor == 1 {
+		return regions
+	}
+	scaled := make([]MSERRegion, len(regions))
+	for i, r := range regions {
+		r.Seed = scalePoint(r.Seed, factor)
+		r.Area = int(math.Round(float64(r.Area) * factor * factor))
+		r.Ellipse.CX = scaleCoord(r.Ellipse.CX, factor)
+		r.Ellipse.CY = scaleCoord(r.Ellipse.CY, factor)
+		r.Ellipse.Major *= factor
+		r.Ellipse.Minor *= factor
+		scaled[i] = r
+	}
+	return scaled
+}
+
+func scaleTextLines(lines []TextLine, factor float64) []TextLine {
+	if factor == 1 {
+		return lines
+	}
+	scaled := make([]TextLine, len(lines))
+	for i, line := range lines {
+		scaled[i].Box = scaleRect(line.Box, factor)
+		scaled[i].Words = make([]image.Rectangle, len(line.Words))
+		for j, w := range line.Words {
+			scaled[i].Words[j] = scaleRect(w, factor)
+		}
+	}
+	return scaled
+}
+
+func scaleRegions(regions []imaging.Region, factor float64) []imaging.Region {
+	if factor == 1 {
+		return regions
+	}
+	scaled := make([]imaging.Region, len(regions))
+	for i, r := range regions {
+		r.Area = int(math.Round(float64(r.Area) * factor * factor))
+		r.Centroid = [2]float64{scaleCoord(r.Centroid[0], factor), scaleCoord(r.Centroid[1], factor)}
+		r.Bounds = scaleRect(r.Bounds, factor)
+		r.Perimeter = int(math.Round(float64(r.Perimeter) * factor))
+		r.EquivalentDiameter *= factor
+		scaled[i] = r
+	}
+	return scaled
+}
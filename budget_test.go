@@ -0,0 +1,112 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"processing-images/imaging"
+	"processing-images/synthetic"
+)
+
+// objectRegions limiariza em 128 e mede os objetos escuros.
+func objectRegions(t *testing.T, img *image.Gray) []imaging.Region {
+	t.Helper()
+	binary := image.NewGray(img.Bounds())
+	for i, v := range img.Pix {
+		if v >= 128 {
+			binary.Pix[i] = 255
+		}
+	}
+	labels, _, err := imaging.LabelComponents(binary, 8, imaging.BlackObjects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return imaging.RegionProps(labels)
+}
+
+func TestBudgetCentroids(t *testing.T) {
+	img, circles := synthetic.Circles(2000, 1500, synthetic.CircleOptions{Count: 12, Radius: 40, Seed: 5})
+	full := objectRegions(t, img)
+	if len(full) != len(circles) {
+		t.Fatalf("%d objetos na resolução cheia, esperado %d", len(full), len(circles))
+	}
+
+	small, factor := fitMegapixels(img, 0.3)
+	if b := small.Bounds(); b.Dx()*b.Dy() > 300000 {
+		t.Fatalf("reduzida para %v, acima do orçamento", b)
+	}
+	if factor < 3 {
+		t.Fatalf("fator %.3f, esperado ≈ 3.16", factor)
+	}
+	reduced := scaleRegions(objectRegions(t, small.(*image.Gray)), factor)
+	if len(reduced) != len(full) {
+		t.Fatalf("%d objetos na reduzida, esperado %d", len(reduced), len(full))
+	}
+	for _, want := range full {
+		best := math.Inf(1)
+		for _, got := range reduced {
+			best = math.Min(best, math.Hypot(got.Centroid[0]-want.Centroid[0], got.Centroid[1]-want.Centroid[1]))
+		}
+		if best > 2 {
+			t.Errorf("objeto em (%.1f, %.1f): centroide reduzido a %.2f px, esperado até 2", want.Centroid[0], want.Centroid[1], best)
+		}
+	}
+}
+
+func TestBudgetUntouched(t *testing.T) {
+	gray := uniformGray(100, 50)
+	rgba := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	tests := []struct {
+		name  string
+		img   image.Image
+		maxMP float64
+	}{
+		{"desligado", gray, 0},
+		{"abaixo do orçamento", gray, 0.02},
+		{"exatamente no orçamento", gray, 0.01},
+		{"colorida abaixo", rgba, 1},
+	}
+	for _, tt := range tests {
+		got, factor := fitMegapixels(tt.img, tt.maxMP)
+		if got != tt.img || factor != 1 {
+			t.Errorf("%s: imagem trocada ou fator %g, esperado a mesma com fator 1", tt.name, factor)
+		}
+	}
+}
+
+func TestBudgetColor(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 400; x++ {
+			img.SetRGBA(x, y, color.RGBA{200, 100, 50, 255})
+		}
+	}
+	got, factor := fitMegapixels(img, 0.03)
+	b := got.Bounds()
+	if b.Dx()*b.Dy() > 30000 || factor <= 1 {
+		t.Fatalf("%v com fator %g", b, factor)
+	}
+	if r, g, bl, _ := got.At(b.Dx()/2, b.Dy()/2).RGBA(); r>>8 != 200 || g>>8 != 100 || bl>>8 != 50 {
+		t.Errorf("cor %v, esperado (200, 100, 50)", got.At(b.Dx()/2, b.Dy()/2))
+	}
+}
+
+func TestScaleCoord(t *testing.T) {
+	tests := []struct {
+		v, factor, want float64
+	}{
+		{0, 1, 0},
+		{10, 1, 10},
+		// o pixel 0 reduzido 2x cobre os originais 0 e 1: centro em 0.5
+		{0, 2, 0.5},
+		{3, 2, 6.5},
+		{0, 4, 1.5},
+	}
+	for _, tt := range tests {
+		if got := scaleCoord(tt.v, tt.factor); got != tt.want {
+			t.Errorf("scaleCoord(%g, %g) = %g, esperado %g", tt.v, tt.factor, got, tt.want)
+		}
+	}
+}
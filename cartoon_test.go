@@ -0,0 +1,131 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"processing-images/imaging"
+)
+
+// cartoonInput é uma cena colorida de retângulos e um disco, com ruído leve.
+func cartoonInput() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 96, 96))
+	rng := rand.New(rand.NewSource(1))
+	for y := 0; y < 96; y++ {
+		for x := 0; x < 96; x++ {
+			c := color.RGBA{200, 190, 170, 255}
+			switch {
+			case (x-60)*(x-60)+(y-60)*(y-60) < 20*20:
+				c = color.RGBA{40, 120, 200, 255}
+			case x >= 10 && x < 45 && y >= 12 && y < 40:
+				c = color.RGBA{180, 40, 50, 255}
+			}
+			noise := func(v uint8) uint8 { return uint8(min(255, max(0, int(v)+rng.Intn(9)-4))) }
+			img.SetRGBA(x, y, color.RGBA{noise(c.R), noise(c.G), noise(c.B), 255})
+		}
+	}
+	return img
+}
+
+func TestCartoonBands(t *testing.T) {
+	img := cartoonInput()
+	for _, bands := range []int{2, 4, 6} {
+		opts := defaultCartoonOptions
+		opts.Bands = bands
+		result := cartoon(img, opts)
+		edges := cartoonEdges(grayscale(img), opts.EdgeStrength, opts.EdgeWindow)
+
+		var levels [3]map[uint8]bool
+		for c := range levels {
+			levels[c] = make(map[uint8]bool)
+		}
+		for y := range edges {
+			for x := range edges[y] {
+				if edges[y][x] {
+					continue
+				}
+				p := result.RGBAAt(x, y)
+				levels[0][p.R], levels[1][p.G], levels[2][p.B] = true, true, true
+			}
+		}
+		for c := range levels {
+			if len(levels[c]) > bands {
+				t.Errorf("%d faixas: canal %d com %d níveis fora dos traços", bands, c, len(levels[c]))
+			}
+		}
+	}
+}
+
+func TestCartoonEdgesFollowCanny(t *testing.T) {
+	img := cartoonInput()
+	result := cartoon(img, defaultCartoonOptions)
+	canny, err := imaging.Canny(grayscale(img), imaging.DefaultCannyOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// os traços têm 2 ou 3 pixels de largura e o Canny 1, então conta como
+	// coincidente o traço a até 1 pixel de uma borda do Canny
+	nearCanny := func(x, y int) bool {
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				p := image.Pt(x+dx, y+dy)
+				if p.In(canny.Bounds()) && canny.GrayAt(p.X, p.Y).Y == 255 {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	lines, matched := 0, 0
+	black := color.RGBA{0, 0, 0, 255}
+	for y := 0; y < 96; y++ {
+		for x := 0; x < 96; x++ {
+			if result.RGBAAt(x, y) != black {
+				continue
+			}
+			lines++
+			if nearCanny(x, y) {
+				matched++
+			}
+		}
+	}
+	if lines == 0 {
+		t.Fatal("nenhum traço desenhado")
+	}
+	if frac := float64(matched) / float64(lines); frac < 0.8 {
+		t.Errorf("só %.0f%% dos traços coincidem com o Canny, esperado >= 80%%", 100*frac)
+	}
+}
+
+func TestQuantizeBands(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 256, 1))
+	for x := 0; x < 256; x++ {
+		img.Pix[x] = uint8(x)
+	}
+	tests := []struct {
+		bands int
+		want  map[uint8]bool
+	}{
+		{2, map[uint8]bool{64: true, 192: true}},
+		{4, map[uint8]bool{32: true, 96: true, 160: true, 224: true}},
+		{1, map[uint8]bool{64: true, 192: true}}, // menos de 2 vira 2
+	}
+	for _, tt := range tests {
+		got := make(map[uint8]bool)
+		for _, v := range quantizeBands(img, tt.bands).Pix {
+			got[v] = true
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%d faixas: níveis %v, esperado %v", tt.bands, got, tt.want)
+			continue
+		}
+		for v := range tt.want {
+			if !got[v] {
+				t.Errorf("%d faixas: falta o nível %d em %v", tt.bands, v, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"processing-images/imaging"
+)
+
+// chainJSON é um código de cadeia em freeman_chain.json.
+type chainJSON struct {
+	Label int    `json:"label"`
+	Start [2]int `json:"start"`
+	Code  string `json:"code"`
+}
+
+// writeChains grava os códigos de cadeia em freeman_chain.txt, uma linha
+// por objeto ("label=3 start=(12,40) code=0012344"), ou em
+// freeman_chain.json com format "json", e devolve o caminho.
+func writeChains(chains []imaging.ChainCode, format string) (string, error) {
+	if format == "json" {
+		path := outPath("freeman_chain.json")
+		list := make([]chainJSON, len(chains))
+		for i, c := range chains {
+			list[i] = chainJSON{c.Label, [2]int{c.Start.X, c.Start.Y}, chainString(c.Code)}
+		}
+		return path, writeJSON(path, list)
+	}
+	path := outPath("freeman_chain.txt")
+	var b strings.Builder
+	for _, c := range chains {
+		fmt.Fprintf(&b, "label=%d start=(%d,%d) code=%s\n", c.Label, c.Start.X, c.Start.Y, chainString(c.Code))
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return "", fmt.Errorf("erro ao gravar %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// chainString escreve os códigos de Freeman em sequência, ex: "0012344".
+func chainString(chain []int) string {
+	var b strings.Builder
+	for _, d := range chain {
+		b.WriteByte(byte('0' + d))
+	}
+	return b.String()
+}
+
+// readChains lê os códigos de cadeia gravados por writeChains, em texto ou
+// em JSON (pela extensão .json).
+func readChains(path string) ([]imaging.ChainCode, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+	var chains []imaging.ChainCode
+	if filepath.Ext(path) == ".json" {
+		var list []chainJSON
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, c := range list {
+			code, err := parseChain(c.Code)
+			if err != nil {
+				return nil, fmt.Errorf("%s: objeto %d: %w", path, c.Label, err)
+			}
+			chains = append(chains, imaging.ChainCode{Label: c.Label, Start: image.Pt(c.Start[0], c.Start[1]), Code: code})
+		}
+		return chains, nil
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var c imaging.ChainCode
+		var code string
+		if _, err := fmt.Sscanf(line, "label=%d start=(%d,%d) code=%s", &c.Label, &c.Start.X, &c.Start.Y, &code); err != nil {
+			// uma cadeia vazia (objeto de 1 pixel) termina em "code="
+			if _, err := fmt.Sscanf(line, "label=%d start=(%d,%d) code=", &c.Label, &c.Start.X, &c.Start.Y); err != nil {
+				return nil, fmt.Errorf("%s:%d: linha inválida %q", path, i+1, line)
+			}
+		}
+		if c.Code, err = parseChain(code); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, i+1, err)
+		}
+		chains = append(chains, c)
+	}
+	return chains, nil
+}
+
+// parseChain lê os dígitos de uma cadeia, ex: "0012344".
+func parseChain(s string) ([]int, error) {
+	code := make([]int, len(s))
+	for i, r := range s {
+		if r < '0' || r > '7' {
+			return nil, fmt.Errorf("código de cadeia inválido %q", r)
+		}
+		code[i] = int(r - '0')
+	}
+	return code, nil
+}
+
+// chainBounds é o retângulo de (0, 0) até o pixel mais distante que as
+// cadeias alcançam.
+func chainBounds(chains []imaging.ChainCode) image.Rectangle {
+	var bounds image.Rectangle
+	for _, c := range chains {
+		for _, p := range imaging.ChainPoints(c.Start, c.Code) {
+			bounds.Max.X = max(bounds.Max.X, p.X+1)
+			bounds.Max.Y = max(bounds.Max.Y, p.Y+1)
+		}
+	}
+	return bounds
+}
+
+// gotoshop chain-draw [-size LxA] [-out chain_reconstructed.png] freeman_chain.txt
+func runChainDraw(args []string) int {
+	fs := newFlagSet("chain-draw", "[-size LxA] [-out chain_reconstructed.png] freeman_chain.txt")
+	size := fs.String("size", "", "dimensões da imagem (LxA); vazio = até onde as cadeias chegam")
+	out := fs.String("out", "chain_reconstructed.png", "arquivo de saída")
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) != 1 {
+		fs.Usage()
+		return 2
+	}
+	chains, err := readChains(files[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	bounds := chainBounds(chains)
+	if *size != "" {
+		width, height, err := parseSize(*size)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		bounds = image.Rect(0, 0, width, height)
+	}
+
+	img := image.NewGray(bounds)
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	for _, c := range chains {
+		imaging.DrawChain(img, c.Start, c.Code)
+	}
+	if err := saveImage(*out, img); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Contornos de %d objetos desenhados em %s\n", len(chains), *out)
+	return 0
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"image"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"processing-images/imaging"
+)
+
+func TestChainString(t *testing.T) {
+	tests := []struct {
+		chain []int
+		want  string
+	}{
+		{nil, ""},
+		{[]int{0}, "0"},
+		{[]int{0, 0, 1, 2, 3, 4, 4, 7}, "00123447"},
+	}
+	for _, tt := range tests {
+		if got := chainString(tt.chain); got != tt.want {
+			t.Errorf("%v: %q, esperado %q", tt.chain, got, tt.want)
+		}
+	}
+}
+
+func TestWriteChains(t *testing.T) {
+	chains := []imaging.ChainCode{
+		{Label: 1, Start: image.Pt(12, 40), Code: []int{0, 0, 1, 2, 3, 4, 4}},
+		{Label: 2, Start: image.Pt(3, 5), Code: nil},
+	}
+	tests := []struct {
+		format string
+		chains []imaging.ChainCode
+		file   string
+		want   string
+	}{
+		{"text", chains, "freeman_chain.txt", "label=1 start=(12,40) code=0012344\nlabel=2 start=(3,5) code=\n"},
+		{"text", nil, "freeman_chain.txt", ""},
+		{"json", chains, "freeman_chain.json", `[{"label":1,"start":[12,40],"code":"0012344"},{"label":2,"start":[3,5],"code":""}]`},
+		{"json", []imaging.ChainCode{}, "freeman_chain.json", `[]`},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		withFlag(t, outDir, dir)
+		path, err := writeChains(tt.chains, tt.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path != filepath.Join(dir, tt.file) {
+			t.Errorf("%s: gravou em %s", tt.format, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := string(data)
+		if tt.format == "json" {
+			// a formatação do JSON não importa, só o conteúdo
+			var a, b any
+			if err := json.Unmarshal(data, &a); err != nil {
+				t.Fatal(err)
+			}
+			json.Unmarshal([]byte(tt.want), &b)
+			gotJSON, _ := json.Marshal(a)
+			wantJSON, _ := json.Marshal(b)
+			got, tt.want = string(gotJSON), string(wantJSON)
+		}
+		if got != tt.want {
+			t.Errorf("%s: %q, esperado %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestReadChainsRoundTrip(t *testing.T) {
+	chains := []imaging.ChainCode{
+		{Label: 1, Start: image.Pt(12, 40), Code: []int{0, 0, 1, 2, 3, 4, 4}},
+		{Label: 2, Start: image.Pt(3, 5), Code: []int{}},
+		{Label: 7, Start: image.Pt(0, 0), Code: []int{7, 6, 5}},
+	}
+	for _, format := range []string{"text", "json"} {
+		withFlag(t, outDir, t.TempDir())
+		path, err := writeChains(chains, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := readChains(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(chains) {
+			t.Fatalf("%s: %d cadeias, esperado %d", format, len(got), len(chains))
+		}
+		for i, c := range chains {
+			if got[i].Label != c.Label || got[i].Start != c.Start || chainString(got[i].Code) != chainString(c.Code) {
+				t.Errorf("%s: cadeia %d = %+v, esperado %+v", format, i, got[i], c)
+			}
+		}
+	}
+}
+
+func TestReadChainsErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name, content string
+	}{
+		{"errado.txt", "label=1 start=(1,2) code=0128\n"},
+		{"linha.txt", "label=1 start=(1,2) code=01\nqualquer coisa\n"},
+		{"errado.json", `[{"label": 1, "start": [0, 0], "code": "09"}]`},
+		{"quebrado.json", `[{"label": 1`},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readChains(path); err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+		}
+	}
+	if _, err := readChains(filepath.Join(dir, "nada.txt")); err == nil {
+		t.Error("arquivo inexistente sem erro")
+	}
+}
+
+func TestRunChainDraw(t *testing.T) {
+	dir := t.TempDir()
+	chains := filepath.Join(dir, "freeman_chain.txt")
+	// o contorno de um quadrado 3x3 a partir de (1,1)
+	if err := os.WriteFile(chains, []byte("label=1 start=(1,1) code=66002244\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		bounds image.Rectangle
+	}{
+		{"até onde a cadeia vai", []string{chains}, 0, image.Rect(0, 0, 4, 4)},
+		{"com -size", []string{"-size", "10x6", chains}, 0, image.Rect(0, 0, 10, 6)},
+		{"-size menor recorta", []string{"-size", "2x2", chains}, 0, image.Rect(0, 0, 2, 2)},
+		{"-size inválido", []string{"-size", "10", chains}, 2, image.Rectangle{}},
+		{"sem arquivo", nil, 2, image.Rectangle{}},
+		{"arquivo inexistente", []string{filepath.Join(dir, "nada.txt")}, 2, image.Rectangle{}},
+	}
+	for i, tt := range tests {
+		out := filepath.Join(dir, "saida"+strconv.Itoa(i)+".png")
+		if code := runChainDraw(append([]string{"-out", out}, tt.args...)); code != tt.code {
+			t.Errorf("%s: código %d, esperado %d", tt.name, code, tt.code)
+			continue
+		}
+		if tt.code != 0 {
+			continue
+		}
+		img, err := loadImage(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img.Bounds() != tt.bounds {
+			t.Errorf("%s: limites %v, esperado %v", tt.name, img.Bounds(), tt.bounds)
+		}
+		if img.GrayAt(1, 1).Y != 0 {
+			t.Errorf("%s: início da cadeia não desenhado", tt.name)
+		}
+		if tt.bounds.Dx() >= 3 && img.GrayAt(2, 2).Y != 255 {
+			t.Errorf("%s: miolo do quadrado desenhado", tt.name)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// códigos de saída do modo normal; os subcomandos usam 0, 1 e 2 do mesmo jeito
+const (
+	exitFailure = 1 // erro no processamento
+	exitUsage   = 2 // argumentos inválidos
+	exitDecode  = 3 // a entrada existe mas não é uma imagem legível
+)
+
+var errUsage = errors.New("uso incorreto")
+
+// subcomandos: gotoshop <nome> [flags] args...
+// cada um devolve o código de saída do processo
+var subcommands = map[string]func(args []string) int{
+	"diff":           runDiff,
+	"gen":            runGen,
+	"eval":           runEval,
+	"hash":           runHash,
+	"dedupe":         runDedupe,
+	"thumbnail":      runThumbnail,
+	"contactsheet":   runContactSheet,
+	"split":          runSplit,
+	"join":           runJoin,
+	"track":          runTrack,
+	"stats":          runStats,
+	"stack":          runStack,
+	"overlap":        runOverlap,
+	"watermark-read": runWatermarkRead,
+	"chain-draw":     runChainDraw,
+}
+
+// usage é a mensagem de flag.Usage do modo normal.
+func usage() {
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "uso: %s [flags] imagem.[jpg|png]\n", os.Args[0])
+	fmt.Fprintf(out, "     %s <subcomando> [flags] args...\n", os.Args[0])
+	fmt.Fprintf(out, "subcomandos: %s\n", strings.Join(names, ", "))
+	fmt.Fprintln(out, "flags:")
+	flag.PrintDefaults()
+}
+
+// inputPath valida os argumentos posicionais do modo normal: exatamente um
+// caminho, de um arquivo comum que existe. Sem argumentos devolve errUsage.
+func inputPath(args []string) (string, error) {
+	switch {
+	case len(args) == 0:
+		return "", errUsage
+	case len(args) > 1:
+		return "", fmt.Errorf("%w: esperado um arquivo, recebidos %d (%s); as flags vêm antes da imagem", errUsage, len(args), strings.Join(args, " "))
+	}
+	path := args[0]
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("entrada inválida: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("entrada inválida: %s não é um arquivo comum", path)
+	}
+	return path, nil
+}
+
+// parseArgs aceita flags antes, entre ou depois dos argumentos posicionais
+// (o pacote flag para no primeiro argumento que não é flag).
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// newFlagSet cria o conjunto de flags de um subcomando com uma mensagem de
+// uso padronizada.
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "uso: %s %s %s\n", os.Args[0], name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFloatList lê uma lista separada por vírgulas, ex: "1,2,3.5".
+func parseFloatList(s string) ([]float64, error) {
+	var values []float64
+	for _, field := range strings.Split(s, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("número inválido %q em %q", field, s)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
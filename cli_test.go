@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInputPath(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "entrada.png")
+	if err := saveImage(file, uniformGray(4, 0)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		args     []string
+		usage    bool
+		notExist bool
+		wantErr  string
+	}{
+		{"sem argumentos", nil, true, false, ""},
+		{"dois arquivos", []string{file, file}, true, false, "recebidos 2"},
+		{"arquivo que não existe", []string{filepath.Join(dir, "nada.png")}, false, true, "entrada inválida"},
+		{"diretório", []string{dir}, false, false, "não é um arquivo comum"},
+	}
+	for _, tt := range tests {
+		_, err := inputPath(tt.args)
+		if err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+			continue
+		}
+		if errors.Is(err, errUsage) != tt.usage {
+			t.Errorf("%s: errors.Is(errUsage) = %v: %v", tt.name, !tt.usage, err)
+		}
+		if errors.Is(err, os.ErrNotExist) != tt.notExist {
+			t.Errorf("%s: errors.Is(ErrNotExist) = %v: %v", tt.name, !tt.notExist, err)
+		}
+		if !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: erro %q, esperado conter %q", tt.name, err, tt.wantErr)
+		}
+	}
+	if path, err := inputPath([]string{file}); err != nil || path != file {
+		t.Errorf("arquivo comum: %q, %v", path, err)
+	}
+}
+
+func TestRunDecodeError(t *testing.T) {
+	// um arquivo que existe mas não é imagem sai com exitDecode, que main
+	// escolhe por errDecode
+	path := filepath.Join(t.TempDir(), "falsa.png")
+	if err := os.WriteFile(path, []byte("texto"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := run(path)
+	if !errors.Is(err, errDecode) || !strings.Contains(err.Error(), "falsa.png") {
+		t.Errorf("erro %v, esperado errDecode citando o arquivo", err)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	fs := newFlagSet("teste", "")
+	n := fs.Int("n", 0, "")
+	v := fs.Bool("v", false, "")
+	args, err := parseArgs(fs, []string{"a.png", "-n", "3", "b.png", "-v", "c.png"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(args, " ") != "a.png b.png c.png" || *n != 3 || !*v {
+		t.Errorf("argumentos %v, n=%d, v=%v", args, *n, *v)
+	}
+	if _, err := parseArgs(newFlagSet("teste", ""), []string{"-nada"}); err == nil {
+		t.Error("flag desconhecida sem erro")
+	}
+}
+
+func TestParseFloatList(t *testing.T) {
+	if got, err := parseFloatList("1, 2,3.5"); err != nil || len(got) != 3 || got[2] != 3.5 {
+		t.Errorf("1, 2,3.5: %v, %v", got, err)
+	}
+	if _, err := parseFloatList("1,,2"); err == nil {
+		t.Error("item vazio sem erro")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"image"
+	"image/draw"
+)
+
+// toRGBA converte qualquer imagem para RGBA com origem em (0, 0).
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) && rgba.Stride == 4*rgba.Bounds().Dx() {
+		return rgba
+	}
+	b := img.Bounds()
+	result := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(result, result.Bounds(), img, b.Min, draw.Src)
+	return result
+}
+
+// splitChannels separa R, G e B em três imagens em tons de cinza.
+func splitChannels(img image.Image) [3]*image.Gray {
+	rgba := toRGBA(img)
+	var channels [3]*image.Gray
+	for c := range channels {
+		channels[c] = image.NewGray(rgba.Bounds())
+	}
+	for i := 0; i < len(rgba.Pix)/4; i++ {
+		for c := 0; c < 3; c++ {
+			channels[c].Pix[i] = rgba.Pix[4*i+c]
+		}
+	}
+	return channels
+}
+
+// mergeChannels junta três canais numa imagem RGBA opaca.
+func mergeChannels(channels [3]*image.Gray) *image.RGBA {
+	result := image.NewRGBA(channels[0].Bounds())
+	for i := 0; i < len(result.Pix)/4; i++ {
+		for c := 0; c < 3; c++ {
+			result.Pix[4*i+c] = channels[c].Pix[i]
+		}
+		result.Pix[4*i+3] = 255
+	}
+	return result
+}
+
+// perChannel aplica fn a uma imagem em tons de cinza direto e a uma colorida
+// canal a canal.
+func perChannel(img image.Image, fn func(*image.Gray) *image.Gray) image.Image {
+	if gray, ok := img.(*image.Gray); ok {
+		return fn(gray)
+	}
+	channels := splitChannels(img)
+	for c := range channels {
+		channels[c] = fn(channels[c])
+	}
+	return mergeChannels(channels)
+}
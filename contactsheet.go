@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// medidas da folha de contato, em pixels
+const (
+	sheetPadding = 8
+	captionGap   = 4
+)
+
+var (
+	sheetBackground = color.RGBA{255, 255, 255, 255}
+	cellBackground  = color.RGBA{48, 48, 48, 255}
+	captionColor    = color.RGBA{0, 0, 0, 255}
+)
+
+// sheetItem é uma imagem da folha com a legenda.
+type sheetItem struct {
+	caption string
+	img     image.Image
+}
+
+// sheetCellHeight é a altura de uma célula: a miniatura e a legenda.
+func sheetCellHeight(thumb int) int {
+	return thumb + captionGap + glyphHeight
+}
+
+// contactSheets monta as folhas de contato: cada imagem é reduzida para
+// caber numa célula thumb x thumb, centralizada (letterbox) sobre fundo
+// escuro, com o nome embaixo. Quando as linhas não cabem em maxHeight, os
+// itens seguintes vão para outra folha.
+func contactSheets(items []sheetItem, cols, thumb, maxHeight int) []*image.RGBA {
+	cellH := sheetCellHeight(thumb)
+	rowsPerSheet := max(1, (maxHeight-sheetPadding)/(cellH+sheetPadding))
+	perSheet := rowsPerSheet * cols
+	width := cols*(thumb+sheetPadding) + sheetPadding
+
+	var sheets []*image.RGBA
+	for start := 0; start < len(items); start += perSheet {
+		page := items[start:min(start+perSheet, len(items))]
+		rows := (len(page) + cols - 1) / cols
+		sheet := image.NewRGBA(image.Rect(0, 0, width, rows*(cellH+sheetPadding)+sheetPadding))
+		draw.Draw(sheet, sheet.Bounds(), image.NewUniform(sheetBackground), image.Point{}, draw.Src)
+
+		for i, item := range page {
+			cell := sheetCell(i, cols, thumb)
+			box := image.Rect(cell.X, cell.Y, cell.X+thumb, cell.Y+thumb)
+			draw.Draw(sheet, box, image.NewUniform(cellBackground), image.Point{}, draw.Src)
+
+			small := thumbnail(item.img, thumb, thumb)
+			sb := small.Bounds()
+			at := box.Min.Add(image.Pt((thumb-sb.Dx())/2, (thumb-sb.Dy())/2))
+			draw.Draw(sheet, image.Rectangle{at, at.Add(sb.Size())}, small, sb.Min, draw.Src)
+
+			caption := fitText(item.caption, thumb, 1)
+			drawText(sheet, cell.X+(thumb-textWidth(caption, 1))/2, cell.Y+thumb+captionGap, caption, 1, captionColor)
+		}
+		sheets = append(sheets, sheet)
+	}
+	return sheets
+}
+
+// sheetCell é o canto superior esquerdo da i-ésima célula da folha.
+func sheetCell(i, cols, thumb int) image.Point {
+	return image.Pt(
+		sheetPadding+(i%cols)*(thumb+sheetPadding),
+		sheetPadding+(i/cols)*(sheetCellHeight(thumb)+sheetPadding),
+	)
+}
+
+// sheetPaths nomeia as folhas: out se for uma só, senão out_1, out_2...
+func sheetPaths(out string, n int) []string {
+	if n == 1 {
+		return []string{out}
+	}
+	ext := filepath.Ext(out)
+	base := strings.TrimSuffix(out, ext)
+	paths := make([]string, n)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("%s_%d%s", base, i+1, ext)
+	}
+	return paths
+}
+
+// loadSheetItems decodifica as imagens do diretório em ordem alfabética;
+// arquivos que não são imagens são avisados e ignorados.
+func loadSheetItems(dir string) ([]sheetItem, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", dir, err)
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var items []sheetItem
+	for _, name := range names {
+		img, _, err := decodeImage(filepath.Join(dir, name))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Aviso:", err)
+			continue
+		}
+		items = append(items, sheetItem{name, img})
+	}
+	if len(items) == 0 {
+		return nil, errors.New("nenhuma imagem em " + dir)
+	}
+	return items, nil
+}
+
+// gotoshop contactsheet -dir out/ [-cols 6] [-thumb 200] [-out sheet.png]
+func runContactSheet(args []string) int {
+	fs := newFlagSet("contactsheet", "-dir pasta [-cols 6] [-thumb 200] [-out sheet.png]")
+	dir := fs.String("dir", "", "diretório com as imagens")
+	cols := fs.Int("cols", 6, "colunas da grade")
+	thumb := fs.Int("thumb", 200, "lado das células")
+	out := fs.String("out", "sheet.png", "folha de saída (sheet_1.png, sheet_2.png... se não couber)")
+	maxHeight := fs.Int("max-height", 4000, "altura máxima de cada folha")
+
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+	if *dir == "" || len(rest) != 0 || *cols <= 0 || *thumb <= 0 {
+		fs.Usage()
+		return 2
+	}
+
+	items, err := loadSheetItems(*dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	sheets := contactSheets(items, *cols, *thumb, *maxHeight)
+	for i, path := range sheetPaths(*out, len(sheets)) {
+		if err := saveImage(path, sheets[i]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("- %s (%dx%d)\n", path, sheets[i].Bounds().Dx(), sheets[i].Bounds().Dy())
+	}
+	return 0
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// sheetTestItems gera n imagens de proporções variadas.
+func sheetTestItems(n int) []sheetItem {
+	items := make([]sheetItem, n)
+	for i := range items {
+		w, h := 60, 60
+		switch i % 3 {
+		case 1:
+			w, h = 120, 40
+		case 2:
+			w, h = 30, 90
+		}
+		items[i] = sheetItem{fmt.Sprintf("img%02d.png", i), synthetic.Checkerboard(w, h, 5)}
+	}
+	return items
+}
+
+// hasCaption diz se a faixa da legenda da célula i tem pixels pretos.
+func hasCaption(sheet *image.RGBA, i, cols, thumb int) bool {
+	cell := sheetCell(i, cols, thumb)
+	for y := cell.Y + thumb + captionGap; y < cell.Y+thumb+captionGap+glyphHeight; y++ {
+		for x := cell.X; x < cell.X+thumb; x++ {
+			if sheet.RGBAAt(x, y) == captionColor {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestContactSheetLayout(t *testing.T) {
+	const cols, thumb = 6, 40
+	sheets := contactSheets(sheetTestItems(14), cols, thumb, 4000)
+	if len(sheets) != 1 {
+		t.Fatalf("%d folhas, esperado 1", len(sheets))
+	}
+	sheet := sheets[0]
+	wantW := cols*(thumb+sheetPadding) + sheetPadding
+	wantH := 3*(thumb+captionGap+glyphHeight+sheetPadding) + sheetPadding
+	if b := sheet.Bounds(); b.Dx() != wantW || b.Dy() != wantH {
+		t.Fatalf("folha %dx%d, esperado %dx%d", b.Dx(), b.Dy(), wantW, wantH)
+	}
+	for i := 0; i < 3*cols; i++ {
+		if got, want := hasCaption(sheet, i, cols, thumb), i < 14; got != want {
+			t.Errorf("célula %d com legenda = %v, esperado %v", i, got, want)
+		}
+	}
+
+	// a imagem larga (item 1) fica centralizada com faixas escuras em cima e
+	// embaixo; a alta (item 2), dos lados
+	wide, tall := sheetCell(1, cols, thumb), sheetCell(2, cols, thumb)
+	if sheet.RGBAAt(wide.X+thumb/2, wide.Y) != cellBackground {
+		t.Error("imagem larga sem faixa no topo da célula")
+	}
+	if sheet.RGBAAt(tall.X, tall.Y+thumb/2) != cellBackground {
+		t.Error("imagem alta sem faixa na lateral da célula")
+	}
+}
+
+func TestContactSheetPagination(t *testing.T) {
+	const cols, thumb = 6, 40
+	rowH := thumb + captionGap + glyphHeight + sheetPadding
+	// duas linhas por folha: 14 imagens em 12 + 2
+	sheets := contactSheets(sheetTestItems(14), cols, thumb, 2*rowH+sheetPadding)
+	if len(sheets) != 2 {
+		t.Fatalf("%d folhas, esperado 2", len(sheets))
+	}
+	if h := sheets[1].Bounds().Dy(); h != rowH+sheetPadding {
+		t.Errorf("última folha com altura %d, esperado %d", h, rowH+sheetPadding)
+	}
+	paths := sheetPaths("out/sheet.png", 2)
+	if paths[0] != "out/sheet_1.png" || paths[1] != "out/sheet_2.png" {
+		t.Errorf("caminhos %v", paths)
+	}
+	if paths := sheetPaths("sheet.png", 1); paths[0] != "sheet.png" {
+		t.Errorf("folha única gravada em %v", paths)
+	}
+}
+
+func TestRunContactSheet(t *testing.T) {
+	dir := t.TempDir()
+	for _, item := range sheetTestItems(14) {
+		if err := saveImage(filepath.Join(dir, item.caption), item.img); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := filepath.Join(t.TempDir(), "sheet.png")
+	if code := runContactSheet([]string{"-dir", dir, "-cols", "6", "-thumb", "40", "-out", out}); code != 0 {
+		t.Fatalf("código de saída %d", code)
+	}
+	img, err := loadImage(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := img.Bounds().Dy(); h != 3*(40+captionGap+glyphHeight+sheetPadding)+sheetPadding {
+		t.Errorf("folha com altura %d, esperado 3 linhas", h)
+	}
+
+	empty := t.TempDir()
+	if err := os.WriteFile(filepath.Join(empty, "notas.txt"), []byte("nada"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"diretório sem imagens", []string{"-dir", empty}, 1},
+		{"diretório vazio", []string{"-dir", t.TempDir()}, 1},
+		{"sem diretório", nil, 2},
+		{"colunas zero", []string{"-dir", dir, "-cols", "0"}, 2},
+	}
+	for _, tt := range tests {
+		if code := runContactSheet(tt.args); code != tt.code {
+			t.Errorf("%s: código de saída %d, esperado %d", tt.name, code, tt.code)
+		}
+	}
+}
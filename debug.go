@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+
+	"processing-images/imaging"
+)
+
+// stageRecord descreve uma etapa intermediária salva por -debug-stages.
+type stageRecord struct {
+	Op     string         `json:"op"`
+	Stage  string         `json:"stage"`
+	File   string         `json:"file"`
+	Params map[string]any `json:"params,omitempty"`
+}
+
+// stageRecorder salva as etapas intermediárias das operações com várias
+// etapas (Canny, pipelines de -ops) como <op>_<NN>_<etapa>.png e lista
+// tudo em stages.json. Um recorder nil não faz nada, então as operações
+// podem chamar record sem checar se a depuração está ligada.
+type stageRecorder struct {
+	dir    string
+	stages []stageRecord
+	count  map[string]int
+	// primeiro erro de gravação, devolvido por writeReport
+	err error
+}
+
+func newStageRecorder(dir string) (*stageRecorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("erro ao criar %s: %w", dir, err)
+	}
+	return &stageRecorder{dir: dir, count: map[string]int{}}, nil
+}
+
+// record salva a imagem da etapa stage da operação op. Erros de gravação
+// não interrompem a operação; o primeiro volta em writeReport.
+func (r *stageRecorder) record(op, stage string, img image.Image, params map[string]any) {
+	if r == nil {
+		return
+	}
+	r.count[op]++
+	file := fmt.Sprintf("%s_%02d_%s.png", op, r.count[op], stage)
+	if err := saveImage(filepath.Join(r.dir, file), img); err != nil {
+		if r.err == nil {
+			r.err = err
+		}
+		return
+	}
+	r.stages = append(r.stages, stageRecord{op, stage, file, params})
+}
+
+// stageFunc adapta o registro para as funções de imaging, com as etapas
+// nomeadas por op; com r nil devolve nil e as etapas nem são geradas.
+func (r *stageRecorder) stageFunc(op string) imaging.StageFunc {
+	if r == nil {
+		return nil
+	}
+	return func(stage string, img image.Image, params map[string]any) {
+		r.record(op, stage, img, params)
+	}
+}
+
+// writeReport grava stages.json com a lista das etapas salvas.
+func (r *stageRecorder) writeReport() error {
+	if r == nil {
+		return nil
+	}
+	if r.err != nil {
+		return r.err
+	}
+	return writeJSON(filepath.Join(r.dir, "stages.json"), r.stages)
+}
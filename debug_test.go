@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+func TestDebugStagesCanny(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dbg")
+	rec, err := newStageRecorder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := synthetic.SiemensStar(80, 60, 12)
+	if _, err := opCanny(opInput{img: img, rec: rec}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.writeReport(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"canny_01_smoothed.png",
+		"canny_02_magnitude.png",
+		"canny_03_direction.png",
+		"canny_04_nms.png",
+		"canny_05_strong_weak.png",
+		"canny_06_edges.png",
+		"stages.json",
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	sort.Strings(got)
+	if len(got) != len(want) {
+		t.Fatalf("arquivos %v, esperado %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("arquivos %v, esperado %v", got, want)
+		}
+	}
+
+	for _, name := range want[:len(want)-1] {
+		stage, _, err := decodeImage(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if stage.Bounds().Size() != img.Bounds().Size() {
+			t.Errorf("%s: %v, esperado %v", name, stage.Bounds().Size(), img.Bounds().Size())
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "stages.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stages []stageRecord
+	if err := json.Unmarshal(data, &stages); err != nil {
+		t.Fatal(err)
+	}
+	if len(stages) != 6 {
+		t.Fatalf("%d etapas no relatório, esperado 6", len(stages))
+	}
+	for i, s := range stages {
+		if s.Op != "canny" || s.File != want[i] {
+			t.Errorf("etapa %d: %+v, esperado canny em %s", i, s, want[i])
+		}
+	}
+	if p := stages[4].Params; p["low"] != *cannyLow || p["high"] != *cannyHigh {
+		t.Errorf("parâmetros do limiar duplo %v, esperado low %v e high %v", p, *cannyLow, *cannyHigh)
+	}
+}
+
+func TestDebugStagesPipeline(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := newStageRecorder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _, _ := documentPage()
+	p := pipelines["docbin"]
+	if _, err := p.run(page, rec); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(p.stages()); len(rec.stages) != n {
+		t.Errorf("%d etapas registradas, esperado %d", len(rec.stages), n)
+	}
+	for i, s := range rec.stages {
+		if s.Op != "docbin" {
+			t.Errorf("etapa %d da operação %q, esperado docbin", i, s.Op)
+		}
+		if _, err := os.Stat(filepath.Join(dir, s.File)); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestNilStageRecorder(t *testing.T) {
+	// sem -debug-stages o recorder é nil e nada é gravado
+	var rec *stageRecorder
+	rec.record("canny", "edges", uniformGray(4, 0), nil)
+	if rec.stageFunc("canny") != nil {
+		t.Error("stageFunc de um recorder nil não é nil")
+	}
+	if err := rec.writeReport(); err != nil {
+		t.Error(err)
+	}
+}
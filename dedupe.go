@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// HashedFile é uma imagem do diretório com o seu hash perceptual.
+type HashedFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	Hash string `json:"hash"`
+
+	hash uint64
+}
+
+// DuplicatePair é uma ligação do grupo: dois arquivos dentro do limiar.
+type DuplicatePair struct {
+	A        string `json:"a"`
+	B        string `json:"b"`
+	Distance int    `json:"distance"`
+}
+
+// DuplicateGroup é um conjunto de quase-duplicatas (componente conexo do
+// grafo de pares dentro do limiar).
+type DuplicateGroup struct {
+	Files []HashedFile    `json:"files"`
+	Pairs []DuplicatePair `json:"pairs"`
+}
+
+// bkTree indexa hashes pela distância de Hamming (árvore BK): numa busca com
+// raio r só os filhos com distância em [d−r, d+r] do nó podem ter
+// resultados, o que evita comparar todos os pares.
+type bkTree struct {
+	root *bkNode
+}
+
+type bkNode struct {
+	hash     uint64
+	items    []int
+	children map[int]*bkNode
+}
+
+func (t *bkTree) insert(hash uint64, item int) {
+	if t.root == nil {
+		t.root = &bkNode{hash: hash, items: []int{item}}
+		return
+	}
+	node := t.root
+	for {
+		d := hammingDistance(hash, node.hash)
+		if d == 0 {
+			node.items = append(node.items, item)
+			return
+		}
+		child, ok := node.children[d]
+		if !ok {
+			if node.children == nil {
+				node.children = map[int]*bkNode{}
+			}
+			node.children[d] = &bkNode{hash: hash, items: []int{item}}
+			return
+		}
+		node = child
+	}
+}
+
+// search chama visit para cada item a no máximo radius bits de hash.
+func (t *bkTree) search(hash uint64, radius int, visit func(item, distance int)) {
+	if t.root == nil {
+		return
+	}
+	stack := []*bkNode{t.root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		d := hammingDistance(hash, node.hash)
+		if d <= radius {
+			for _, item := range node.items {
+				visit(item, d)
+			}
+		}
+		for cd, child := range node.children {
+			if cd >= d-radius && cd <= d+radius {
+				stack = append(stack, child)
+			}
+		}
+	}
+}
+
+// hashFiles calcula os hashes em paralelo. Arquivos que não decodificam são
+// avisados e ignorados.
+func hashFiles(paths []string, hash func(img *image.Gray) uint64) []HashedFile {
+	results := make([]*HashedFile, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				img, _, err := decodeImage(paths[i])
+				if err != nil {
+					fmt.Fprintln(os.Stderr, "Aviso:", err)
+					continue
+				}
+				info, err := os.Stat(paths[i])
+				if err != nil {
+					continue
+				}
+				h := hash(grayscale(img))
+				results[i] = &HashedFile{Path: paths[i], Size: info.Size(), Hash: fmt.Sprintf("%016x", h), hash: h}
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var files []HashedFile
+	for _, f := range results {
+		if f != nil {
+			files = append(files, *f)
+		}
+	}
+	return files
+}
+
+// findDuplicates agrupa os arquivos cujos hashes estão a até threshold bits
+// (union-find sobre os pares achados na árvore BK). Devolve só os grupos com
+// mais de um arquivo, e o número de arquivos sem par.
+func findDuplicates(files []HashedFile, threshold int) ([]DuplicateGroup, int) {
+	parent := make([]int, len(files))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	var tree bkTree
+	var pairs [][3]int
+	for i, f := range files {
+		tree.search(f.hash, threshold, func(j, d int) {
+			pairs = append(pairs, [3]int{j, i, d})
+			parent[find(i)] = find(j)
+		})
+		tree.insert(f.hash, i)
+	}
+
+	byRoot := map[int]*DuplicateGroup{}
+	var roots []int
+	for i, f := range files {
+		r := find(i)
+		g, ok := byRoot[r]
+		if !ok {
+			g = &DuplicateGroup{}
+			byRoot[r] = g
+			roots = append(roots, r)
+		}
+		g.Files = append(g.Files, f)
+	}
+	for _, p := range pairs {
+		g := byRoot[find(p[0])]
+		g.Pairs = append(g.Pairs, DuplicatePair{files[p[0]].Path, files[p[1]].Path, p[2]})
+	}
+
+	var groups []DuplicateGroup
+	singletons := 0
+	for _, r := range roots {
+		if len(byRoot[r].Files) == 1 {
+			singletons++
+			continue
+		}
+		groups = append(groups, *byRoot[r])
+	}
+	return groups, singletons
+}
+
+// gotoshop dedupe -dir fotos/ [-method phash] [-threshold 6] [-json grupos.json]
+func runDedupe(args []string) int {
+	fs := newFlagSet("dedupe", "-dir pasta [-method phash] [-threshold 6] [-json grupos.json]")
+	dir := fs.String("dir", "", "diretório com as imagens")
+	method := fs.String("method", "phash", "ahash, dhash ou phash")
+	threshold := fs.Int("threshold", 6, "distância de Hamming máxima entre quase-duplicatas")
+	jsonPath := fs.String("json", "", "exporta os grupos em JSON")
+
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+	if *dir == "" || len(rest) != 0 {
+		fs.Usage()
+		return 2
+	}
+	hash, ok := hashMethods[*method]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "método desconhecido %q, use ahash, dhash ou phash\n", *method)
+		return 2
+	}
+
+	entries, err := os.ReadDir(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro ao ler %s: %v\n", *dir, err)
+		return 1
+	}
+	var paths []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			paths = append(paths, filepath.Join(*dir, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+
+	files := hashFiles(paths, hash)
+	groups, singletons := findDuplicates(files, *threshold)
+
+	for i, g := range groups {
+		fmt.Printf("Grupo %d:\n", i+1)
+		for _, f := range g.Files {
+			fmt.Printf("- %s (%d bytes, %s)\n", f.Path, f.Size, f.Hash)
+		}
+		for _, p := range g.Pairs {
+			fmt.Printf("  %s ~ %s: %d\n", filepath.Base(p.A), filepath.Base(p.B), p.Distance)
+		}
+	}
+	fmt.Printf("%d imagens, %d grupos de quase-duplicatas, %d sem par\n", len(files), len(groups), singletons)
+
+	if *jsonPath != "" {
+		if err := writeJSON(*jsonPath, groups); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"image"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+func TestDedupeDirectory(t *testing.T) {
+	dir := t.TempDir()
+	original := hashScene(1)
+	files := map[string]image.Image{
+		"original.png":     original,
+		"reduzida.png":     resizeArea(original, 96, 96),
+		"recomprimida.jpg": jpegCopy(t, original, 60),
+		"outra.png":        synthetic.SiemensStar(128, 128, 16),
+	}
+	for name, img := range files {
+		if err := saveImage(filepath.Join(dir, name), img); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// arquivos que não são imagens são avisados e ignorados
+	if err := os.WriteFile(filepath.Join(dir, "notas.txt"), []byte("nada"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	jsonPath := filepath.Join(t.TempDir(), "grupos.json")
+	if code := runDedupe([]string{"-dir", dir, "-json", jsonPath}); code != 0 {
+		t.Fatalf("código de saída %d", code)
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var groups []DuplicateGroup
+	if err := json.Unmarshal(data, &groups); err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("%d grupos, esperado 1: %s", len(groups), data)
+	}
+	var names []string
+	for _, f := range groups[0].Files {
+		names = append(names, filepath.Base(f.Path))
+		if f.Size <= 0 || len(f.Hash) != 16 {
+			t.Errorf("arquivo %+v sem tamanho ou hash", f)
+		}
+	}
+	sort.Strings(names)
+	if want := []string{"original.png", "recomprimida.jpg", "reduzida.png"}; len(names) != 3 || names[0] != want[0] || names[1] != want[1] || names[2] != want[2] {
+		t.Errorf("grupo %v, esperado %v", names, want)
+	}
+	if len(groups[0].Pairs) < 2 {
+		t.Errorf("grupo de 3 com %d pares, esperado pelo menos 2", len(groups[0].Pairs))
+	}
+
+	paths, _ := filepath.Glob(filepath.Join(dir, "*"))
+	_, singletons := findDuplicates(hashFiles(paths, perceptualHash), 6)
+	if singletons != 1 {
+		t.Errorf("%d imagens sem par, esperado 1", singletons)
+	}
+}
+
+func TestBKTreeSearch(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	hashes := make([]uint64, 500)
+	var tree bkTree
+	for i := range hashes {
+		// hashes agrupados perto de poucos centros, para haver vizinhos
+		hashes[i] = uint64(rng.Intn(4))*0x0F0F0F0F0F0F0F0F ^ uint64(1)<<rng.Intn(64) ^ uint64(1)<<rng.Intn(64)
+		tree.insert(hashes[i], i)
+	}
+	for _, radius := range []int{0, 2, 6} {
+		for q := 0; q < 20; q++ {
+			query := hashes[rng.Intn(len(hashes))] ^ uint64(1)<<rng.Intn(64)
+			found := map[int]bool{}
+			tree.search(query, radius, func(item, d int) {
+				if d != hammingDistance(query, hashes[item]) {
+					t.Errorf("distância %d informada para o item %d, esperado %d", d, item, hammingDistance(query, hashes[item]))
+				}
+				found[item] = true
+			})
+			for i, h := range hashes {
+				if (hammingDistance(query, h) <= radius) != found[i] {
+					t.Fatalf("raio %d: item %d encontrado = %v, distância %d", radius, i, found[i], hammingDistance(query, h))
+				}
+			}
+		}
+	}
+}
+
+func TestRunDedupeUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"sem diretório", nil, 2},
+		{"método desconhecido", []string{"-dir", t.TempDir(), "-method", "xhash"}, 2},
+		{"diretório que não existe", []string{"-dir", filepath.Join(t.TempDir(), "nada")}, 1},
+		{"diretório vazio", []string{"-dir", t.TempDir()}, 0},
+	}
+	for _, tt := range tests {
+		if code := runDedupe(tt.args); code != tt.code {
+			t.Errorf("%s: código de saída %d, esperado %d", tt.name, code, tt.code)
+		}
+	}
+}
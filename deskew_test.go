@@ -0,0 +1,79 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"processing-images/imaging"
+)
+
+// documentImage é uma página branca com linhas de texto pretas.
+func documentImage() *image.Gray {
+	canvas := image.NewRGBA(image.Rect(0, 0, 320, 240))
+	for i := range canvas.Pix {
+		canvas.Pix[i] = 255
+	}
+	lines := []string{
+		"THE QUICK BROWN FOX JUMPS",
+		"OVER THE LAZY DOG 0123456",
+		"PACK MY BOX WITH FIVE DOZEN",
+		"LIQUOR JUGS. SPHINX OF BLACK",
+		"QUARTZ, JUDGE MY VOW 789",
+		"HOW VEXINGLY QUICK DAFT ZEBRAS",
+	}
+	for i, line := range lines {
+		drawText(canvas, 30, 40+i*28, line, 2, color.RGBA{0, 0, 0, 255})
+	}
+	return grayscale(canvas)
+}
+
+// rowProfileVariance é a variância do perfil de projeção horizontal dos
+// pixels escuros (Otsu).
+func rowProfileVariance(img *image.Gray) float64 {
+	binary := imaging.Otsu(img)
+	b := binary.Bounds()
+	profile := make([]float64, b.Dy())
+	for y := range profile {
+		for x := 0; x < b.Dx(); x++ {
+			if binary.Pix[y*binary.Stride+x] == 0 {
+				profile[y]++
+			}
+		}
+	}
+	var sum, sumSq float64
+	for _, v := range profile {
+		sum += v
+		sumSq += v * v
+	}
+	n := float64(len(profile))
+	return sumSq/n - (sum/n)*(sum/n)
+}
+
+func TestEstimateSkew(t *testing.T) {
+	doc := documentImage()
+	for _, angle := range []float64{2.3, -2.3, 0, 4.7} {
+		rotated := rotate(doc, angle, interpBilinear, false, 255)
+		if got := estimateSkew(rotated, 5); math.Abs(got-angle) > 0.2 {
+			t.Errorf("ângulo %.1f° estimado como %.2f°", angle, got)
+		}
+	}
+}
+
+func TestDeskewSharpensProfile(t *testing.T) {
+	rotated := rotate(documentImage(), 2.3, interpBilinear, false, 255)
+	straight, angle := deskew(rotated, 5)
+	if math.Abs(angle-2.3) > 0.2 {
+		t.Errorf("deskew devolveu o ângulo %.2f°, esperado 2.3°", angle)
+	}
+	if before, after := rowProfileVariance(rotated), rowProfileVariance(straight); after <= before {
+		t.Errorf("variância do perfil caiu de %.1f para %.1f", before, after)
+	}
+}
+
+func TestEstimateSkewBlank(t *testing.T) {
+	if got := estimateSkew(uniformGray(32, 255), 5); got != 0 {
+		t.Errorf("página em branco com ângulo %v, esperado 0", got)
+	}
+}
@@ -0,0 +1,198 @@
+// Package dft faz a transformada de Fourier discreta rápida (FFT) em 1D e
+// 2D, para qualquer tamanho: potências de 2 pelo Cooley-Tukey de raiz 2 e
+// os outros pelo algoritmo de Bluestein, que reescreve a transformada como
+// uma convolução de tamanho potência de 2. Também converte imagens em tons
+// de cinza e mostra o espectro.
+//
+// As transformadas trabalham no lugar; a inversa já divide por n, então
+// IFFT(FFT(a)) devolve a (a menos de arredondamento).
+package dft
+
+import (
+	"image"
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// FFT troca a pela sua transformada direta, Σ a[n]·e^(−2πi·kn/N).
+func FFT(a []complex128) {
+	transform(a, false)
+}
+
+// IFFT troca a pela transformada inversa, dividida por len(a).
+func IFFT(a []complex128) {
+	transform(a, true)
+	for i := range a {
+		a[i] /= complex(float64(len(a)), 0)
+	}
+}
+
+// FFT2 faz a transformada 2D de data ([y][x], linhas do mesmo tamanho): a
+// 1D em cada linha e depois em cada coluna.
+func FFT2(data [][]complex128) {
+	transform2(data, FFT)
+}
+
+// IFFT2 é a inversa de FFT2.
+func IFFT2(data [][]complex128) {
+	transform2(data, IFFT)
+}
+
+func transform2(data [][]complex128, fn func([]complex128)) {
+	if len(data) == 0 {
+		return
+	}
+	for _, row := range data {
+		fn(row)
+	}
+	column := make([]complex128, len(data))
+	for x := range data[0] {
+		for y, row := range data {
+			column[y] = row[x]
+		}
+		fn(column)
+		for y, row := range data {
+			row[x] = column[y]
+		}
+	}
+}
+
+// transform faz a transformada sem normalizar; inverse troca o sinal do
+// expoente.
+func transform(a []complex128, inverse bool) {
+	n := len(a)
+	switch {
+	case n <= 1:
+	case n&(n-1) == 0:
+		radix2(a, inverse)
+	default:
+		bluestein(a, inverse)
+	}
+}
+
+// radix2 é o Cooley-Tukey iterativo; len(a) precisa ser potência de 2.
+func radix2(a []complex128, inverse bool) {
+	n := len(a)
+	// permutação pelos bits invertidos
+	shift := 64 - bits.TrailingZeros(uint(n))
+	for i := range a {
+		if j := int(bits.Reverse64(uint64(i)) >> shift); j > i {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for size := 2; size <= n; size <<= 1 {
+		// os fatores são calculados direto, não multiplicados em sequência,
+		// para o erro não acumular nos tamanhos grandes
+		half := size / 2
+		for k := 0; k < half; k++ {
+			w := cmplx.Rect(1, sign*2*math.Pi*float64(k)/float64(size))
+			for start := 0; start < n; start += size {
+				even, odd := a[start+k], w*a[start+k+half]
+				a[start+k], a[start+k+half] = even+odd, even-odd
+			}
+		}
+	}
+}
+
+// bluestein usa kn = (k² + n² − (k−n)²)/2 para escrever a transformada
+// como a convolução de a[n]·w[n] com w̄, w[n] = e^(∓πi·n²/N), feita com
+// FFTs de raiz 2 de tamanho >= 2N−1.
+func bluestein(a []complex128, inverse bool) {
+	n := len(a)
+	m := 1
+	for m < 2*n-1 {
+		m <<= 1
+	}
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	w := make([]complex128, n)
+	for k := range w {
+		// k² mod 2N para o ângulo não perder precisão
+		sq := (k * k) % (2 * n)
+		w[k] = cmplx.Rect(1, sign*math.Pi*float64(sq)/float64(n))
+	}
+	x := make([]complex128, m)
+	y := make([]complex128, m)
+	for k := range a {
+		x[k] = a[k] * w[k]
+	}
+	y[0] = cmplx.Conj(w[0])
+	for k := 1; k < n; k++ {
+		y[k] = cmplx.Conj(w[k])
+		y[m-k] = y[k]
+	}
+	radix2(x, false)
+	radix2(y, false)
+	for i := range x {
+		x[i] *= y[i]
+	}
+	radix2(x, true)
+	for k := range a {
+		a[k] = w[k] * x[k] / complex(float64(m), 0)
+	}
+}
+
+// FFTOfGray devolve a transformada 2D da imagem ([v][u], frequência zero em
+// [0][0]).
+func FFTOfGray(img *image.Gray) [][]complex128 {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	data := make([][]complex128, height)
+	for y := range data {
+		data[y] = make([]complex128, width)
+		for x, v := range img.Pix[y*img.Stride : y*img.Stride+width] {
+			data[y][x] = complex(float64(v), 0)
+		}
+	}
+	FFT2(data)
+	return data
+}
+
+// Shift devolve uma cópia com a frequência zero no centro ([h/2][w/2]),
+// como o fftshift: cada quadrante troca com o oposto.
+func Shift(data [][]complex128) [][]complex128 {
+	height := len(data)
+	shifted := make([][]complex128, height)
+	for y := range shifted {
+		width := len(data[y])
+		shifted[(y+height/2)%height] = make([]complex128, width)
+		for x, v := range data[y] {
+			shifted[(y+height/2)%height][(x+width/2)%width] = v
+		}
+	}
+	return shifted
+}
+
+// SpectrumImage mostra o espectro de uma transformada: log(1 + |F|) com a
+// frequência zero no centro, normalizado para 0..255 pelo maior valor. O
+// logaritmo é o que deixa ver algo além do pico da frequência zero.
+func SpectrumImage(data [][]complex128) *image.Gray {
+	shifted := Shift(data)
+	height := len(shifted)
+	width := 0
+	if height > 0 {
+		width = len(shifted[0])
+	}
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	var peak float64
+	for _, row := range shifted {
+		for _, v := range row {
+			peak = math.Max(peak, math.Log1p(cmplx.Abs(v)))
+		}
+	}
+	if peak == 0 {
+		return result
+	}
+	for y, row := range shifted {
+		for x, v := range row {
+			result.Pix[y*result.Stride+x] = uint8(math.Round(math.Log1p(cmplx.Abs(v)) / peak * 255))
+		}
+	}
+	return result
+}
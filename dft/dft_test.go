@@ -0,0 +1,211 @@
+package dft
+
+import (
+	"image"
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+// naiveDFT é a definição direta, O(n²).
+func naiveDFT(a []complex128) []complex128 {
+	n := len(a)
+	out := make([]complex128, n)
+	for k := range out {
+		for j, v := range a {
+			out[k] += v * cmplx.Rect(1, -2*math.Pi*float64(k*j)/float64(n))
+		}
+	}
+	return out
+}
+
+func randomComplex(n int, seed int64) []complex128 {
+	rng := rand.New(rand.NewSource(seed))
+	a := make([]complex128, n)
+	for i := range a {
+		a[i] = complex(rng.Float64()*2-1, rng.Float64()*2-1)
+	}
+	return a
+}
+
+func TestFFTMatchesNaive(t *testing.T) {
+	// potências de 2 vão pelo raiz 2 e o resto por Bluestein
+	for _, n := range []int{1, 2, 3, 4, 5, 7, 8, 12, 16, 17, 31, 64, 100} {
+		a := randomComplex(n, int64(n))
+		want := naiveDFT(a)
+		got := append([]complex128(nil), a...)
+		FFT(got)
+		for k := range want {
+			if cmplx.Abs(got[k]-want[k]) > 1e-9*float64(n) {
+				t.Fatalf("n=%d: F[%d] = %v, esperado %v", n, k, got[k], want[k])
+			}
+		}
+	}
+}
+
+func TestFFTRoundTrip(t *testing.T) {
+	for _, n := range []int{1, 2, 6, 32, 45, 128, 243} {
+		a := randomComplex(n, 3)
+		got := append([]complex128(nil), a...)
+		FFT(got)
+		IFFT(got)
+		for i := range a {
+			if cmplx.Abs(got[i]-a[i]) > 1e-12*float64(n) {
+				t.Fatalf("n=%d: posição %d voltou %v, esperado %v", n, i, got[i], a[i])
+			}
+		}
+	}
+}
+
+func TestFFTImpulseIsFlat(t *testing.T) {
+	tests := []struct {
+		n, at int
+	}{
+		{8, 0},
+		{8, 3},
+		{15, 0},
+		{15, 7},
+	}
+	for _, tt := range tests {
+		a := make([]complex128, tt.n)
+		a[tt.at] = 1
+		FFT(a)
+		for k, v := range a {
+			// o impulso na origem dá tudo 1; deslocado, só muda a fase
+			if math.Abs(cmplx.Abs(v)-1) > 1e-12 || (tt.at == 0 && cmplx.Abs(v-1) > 1e-12) {
+				t.Fatalf("n=%d, impulso em %d: F[%d] = %v", tt.n, tt.at, k, v)
+			}
+		}
+	}
+
+	// em 2D também
+	data := make([][]complex128, 6)
+	for y := range data {
+		data[y] = make([]complex128, 10)
+	}
+	data[0][0] = 1
+	FFT2(data)
+	for y, row := range data {
+		for x, v := range row {
+			if cmplx.Abs(v-1) > 1e-12 {
+				t.Fatalf("2D: F[%d][%d] = %v, esperado 1", y, x, v)
+			}
+		}
+	}
+}
+
+func TestFFTOfGrayRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	full := image.NewGray(image.Rect(0, 0, 40, 30))
+	rng.Read(full.Pix)
+	tests := []struct {
+		name string
+		img  *image.Gray
+	}{
+		{"potência de 2", full.SubImage(image.Rect(0, 0, 32, 16)).(*image.Gray)},
+		{"qualquer tamanho", full},
+		{"recorte", full.SubImage(image.Rect(7, 5, 30, 26)).(*image.Gray)},
+	}
+	for _, tt := range tests {
+		data := FFTOfGray(tt.img)
+		b := tt.img.Bounds()
+		if len(data) != b.Dy() || len(data[0]) != b.Dx() {
+			t.Fatalf("%s: transformada %dx%d", tt.name, len(data[0]), len(data))
+		}
+		IFFT2(data)
+		for y := range data {
+			for x, v := range data[y] {
+				if want := float64(tt.img.GrayAt(b.Min.X+x, b.Min.Y+y).Y); math.Abs(real(v)-want) > 1e-9 || math.Abs(imag(v)) > 1e-9 {
+					t.Fatalf("%s: pixel %d,%d voltou %v, esperado %g", tt.name, x, y, v, want)
+				}
+			}
+		}
+	}
+}
+
+func TestSpectrumSinusoid(t *testing.T) {
+	// listras verticais com c ciclos na largura: dois picos simétricos em
+	// u = ±c na linha do centro
+	tests := []struct {
+		width, height, cycles int
+	}{
+		{64, 64, 8},
+		{60, 45, 5},
+	}
+	for _, tt := range tests {
+		img := image.NewGray(image.Rect(0, 0, tt.width, tt.height))
+		for y := 0; y < tt.height; y++ {
+			for x := 0; x < tt.width; x++ {
+				img.Pix[y*img.Stride+x] = uint8(math.Round(128 + 100*math.Cos(2*math.Pi*float64(tt.cycles*x)/float64(tt.width))))
+			}
+		}
+		spectrum := SpectrumImage(FFTOfGray(img))
+		cx, cy := tt.width/2, tt.height/2
+		if spectrum.GrayAt(cx, cy).Y != 255 {
+			t.Errorf("%dx%d: frequência zero %d, esperado 255", tt.width, tt.height, spectrum.GrayAt(cx, cy).Y)
+		}
+		left, right := spectrum.GrayAt(cx-tt.cycles, cy).Y, spectrum.GrayAt(cx+tt.cycles, cy).Y
+		if left != right || left < 200 {
+			t.Errorf("%dx%d: picos %d e %d", tt.width, tt.height, left, right)
+		}
+		for y := 0; y < tt.height; y++ {
+			for x := 0; x < tt.width; x++ {
+				peak := y == cy && (x == cx || x == cx-tt.cycles || x == cx+tt.cycles)
+				// a quantização em 8 bits deixa harmônicos, mas bem abaixo
+				if v := spectrum.GrayAt(x, y).Y; !peak && v > left-40 {
+					t.Fatalf("%dx%d: %d fora dos picos em %d,%d", tt.width, tt.height, v, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestShift(t *testing.T) {
+	tests := []struct {
+		width, height int
+	}{
+		{4, 4},
+		{5, 3},
+		{1, 2},
+	}
+	for _, tt := range tests {
+		data := make([][]complex128, tt.height)
+		for y := range data {
+			data[y] = make([]complex128, tt.width)
+		}
+		data[0][0] = 1
+		data[tt.height-1][tt.width-1] = 2
+		shifted := Shift(data)
+		// a origem vai para o centro e o último elemento para logo antes dele
+		if shifted[tt.height/2][tt.width/2] != 1 || shifted[(tt.height-1+tt.height/2)%tt.height][(tt.width-1+tt.width/2)%tt.width] != 2 {
+			t.Errorf("%dx%d: %v", tt.width, tt.height, shifted)
+		}
+		if data[0][0] != 1 {
+			t.Errorf("%dx%d: Shift alterou a entrada", tt.width, tt.height)
+		}
+	}
+}
+
+func TestSpectrumImageEmpty(t *testing.T) {
+	zero := SpectrumImage(FFTOfGray(image.NewGray(image.Rect(0, 0, 8, 6))))
+	for _, v := range zero.Pix {
+		if v != 0 {
+			t.Fatal("espectro de uma imagem preta não é preto")
+		}
+	}
+	if empty := SpectrumImage(nil); !empty.Bounds().Empty() {
+		t.Errorf("espectro vazio com limites %v", empty.Bounds())
+	}
+}
+
+func BenchmarkFFT2(b *testing.B) {
+	for _, size := range []int{256, 250} {
+		img := image.NewGray(image.Rect(0, 0, size, size))
+		b.Run(image.Pt(size, size).String(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				FFTOfGray(img)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"os"
+)
+
+// diffSummary resume as diferenças entre duas imagens.
+type diffSummary struct {
+	Regions       []image.Rectangle `json:"regions"`
+	ChangedPixels int               `json:"changed_pixels"`
+	MaxDiff       int               `json:"max_diff"`
+	PSNR          float64           `json:"-"`
+}
+
+// MarshalJSON exporta o PSNR como null quando as imagens são idênticas (+Inf
+// não é um número JSON válido).
+func (s diffSummary) MarshalJSON() ([]byte, error) {
+	type plain diffSummary
+	out := struct {
+		plain
+		PSNR *float64 `json:"psnr"`
+	}{plain: plain(s)}
+	if !math.IsInf(s.PSNR, 0) {
+		out.PSNR = &s.PSNR
+	}
+	return json.Marshal(out)
+}
+
+// compareImages calcula a diferença absoluta entre duas imagens, marca como
+// alterados os pixels acima do limiar e agrupa-os em regiões 8-conectadas.
+func compareImages(before, after *image.Gray, threshold int) (diffSummary, [][]bool, error) {
+	var summary diffSummary
+	if before.Bounds().Dx() != after.Bounds().Dx() || before.Bounds().Dy() != after.Bounds().Dy() {
+		return summary, nil, fmt.Errorf("dimensões diferentes: %dx%d e %dx%d",
+			before.Bounds().Dx(), before.Bounds().Dy(), after.Bounds().Dx(), after.Bounds().Dy())
+	}
+
+	width, height := after.Bounds().Dx(), after.Bounds().Dy()
+	changed := make([][]bool, height)
+	var squaredErr float64
+	for y := 0; y < height; y++ {
+		changed[y] = make([]bool, width)
+		for x := 0; x < width; x++ {
+			d := int(before.Pix[y*before.Stride+x]) - int(after.Pix[y*after.Stride+x])
+			if d < 0 {
+				d = -d
+			}
+			squaredErr += float64(d * d)
+			summary.MaxDiff = max(summary.MaxDiff, d)
+			if d > threshold {
+				changed[y][x] = true
+				summary.ChangedPixels++
+			}
+		}
+	}
+
+	summary.PSNR = psnr(squaredErr / float64(width*height))
+	summary.Regions = changedRegions(changed)
+	return summary, changed, nil
+}
+
+// psnr em dB para imagens de 8 bits; +Inf quando não há erro.
+func psnr(mse float64) float64 {
+	if mse == 0 {
+		return math.Inf(1)
+	}
+	return 10 * math.Log10(255*255/mse)
+}
+
+// changedRegions devolve a caixa envolvente de cada região conectada.
+func changedRegions(mask [][]bool) []image.Rectangle {
+	height := len(mask)
+	if height == 0 {
+		return nil
+	}
+	width := len(mask[0])
+	visited := make([][]bool, height)
+	for i := range visited {
+		visited[i] = make([]bool, width)
+	}
+
+	var regions []image.Rectangle
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if visited[y][x] || !mask[y][x] {
+				continue
+			}
+
+			box := image.Rect(x, y, x+1, y+1)
+			stack := [][2]int{{x, y}}
+			visited[y][x] = true
+			for len(stack) > 0 {
+				px, py := stack[len(stack)-1][0], stack[len(stack)-1][1]
+				stack = stack[:len(stack)-1]
+				box = box.Union(image.Rect(px, py, px+1, py+1))
+
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						nx, ny := px+dx, py+dy
+						if nx >= 0 && ny >= 0 && nx < width && ny < height && !visited[ny][nx] && mask[ny][nx] {
+							visited[ny][nx] = true
+							stack = append(stack, [2]int{nx, ny})
+						}
+					}
+				}
+			}
+			regions = append(regions, box)
+		}
+	}
+
+	return regions
+}
+
+// renderDiff desenha as regiões alteradas sobre uma cópia escurecida da
+// imagem nova.
+func renderDiff(after *image.Gray, changed [][]bool, regions []image.Rectangle) *image.RGBA {
+	width, height := after.Bounds().Dx(), after.Bounds().Dy()
+	result := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := after.Pix[y*after.Stride+x] / 3
+			if changed[y][x] {
+				result.SetRGBA(x, y, color.RGBA{255, v, v, 255})
+			} else {
+				result.SetRGBA(x, y, color.RGBA{v, v, v, 255})
+			}
+		}
+	}
+
+	red := color.RGBA{255, 0, 0, 255}
+	for _, r := range regions {
+		drawRect(result, r, red)
+	}
+	return result
+}
+
+// gotoshop diff old.png new.png [-t 8] [-out diff_report.png] [-json resumo.json]
+// saída 0: sem diferenças, 1: imagens diferentes, 2: erro
+func runDiff(args []string) int {
+	fs := newFlagSet("diff", "old.png new.png")
+	threshold := fs.Int("t", 8, "diferença mínima por pixel para contar como alteração")
+	out := fs.String("out", "diff_report.png", "imagem com as regiões alteradas")
+	jsonPath := fs.String("json", "", "exporta o resumo em JSON")
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	before, err := loadImage(files[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	after, err := loadImage(files[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	summary, changed, err := compareImages(before, after, *threshold)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro ao comparar %s e %s: %v\n", files[0], files[1], err)
+		return 2
+	}
+
+	if *jsonPath != "" {
+		if err := writeJSON(*jsonPath, summary); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+
+	if summary.ChangedPixels == 0 {
+		fmt.Println("no differences")
+		return 0
+	}
+
+	if err := saveImage(*out, renderDiff(after, changed, summary.Regions)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	fmt.Printf("Regiões alteradas: %d\n", len(summary.Regions))
+	for _, r := range summary.Regions {
+		fmt.Printf("- (%d,%d)-(%d,%d)\n", r.Min.X, r.Min.Y, r.Max.X, r.Max.Y)
+	}
+	fmt.Printf("Pixels alterados: %d\n", summary.ChangedPixels)
+	fmt.Printf("Diferença máxima: %d\n", summary.MaxDiff)
+	fmt.Printf("PSNR: %.2f dB\n", summary.PSNR)
+	fmt.Printf("Relatório salvo em %s\n", *out)
+	return 1
+}
+
+// writeJSON grava v indentado no arquivo.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("erro ao gravar %s: %w", path, err)
+	}
+	return nil
+}
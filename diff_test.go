@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+func TestCompareImages(t *testing.T) {
+	base := synthetic.Noise(64, 48, 128, 20, 1)
+	modified := image.NewGray(base.Rect)
+	copy(modified.Pix, base.Pix)
+	block := image.Rect(10, 20, 18, 26)
+	for y := block.Min.Y; y < block.Max.Y; y++ {
+		for x := block.Min.X; x < block.Max.X; x++ {
+			modified.Pix[y*modified.Stride+x] = 255 - modified.Pix[y*modified.Stride+x]/4
+		}
+	}
+
+	tests := []struct {
+		name          string
+		before, after *image.Gray
+		regions       []image.Rectangle
+		changed       int
+		err           string
+	}{
+		{"idênticas", base, base, nil, 0, ""},
+		{"um bloco", base, modified, []image.Rectangle{block}, block.Dx() * block.Dy(), ""},
+		{"tamanhos diferentes", base, image.NewGray(image.Rect(0, 0, 48, 64)), nil, 0, "dimensões diferentes: 64x48 e 48x64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, changed, err := compareImages(tt.before, tt.after, 8)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("erro %v, esperado %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(summary.Regions) != len(tt.regions) {
+				t.Fatalf("regiões %v, esperado %v", summary.Regions, tt.regions)
+			}
+			for i, r := range tt.regions {
+				if summary.Regions[i] != r {
+					t.Errorf("região %d = %v, esperado %v", i, summary.Regions[i], r)
+				}
+			}
+			if summary.ChangedPixels != tt.changed {
+				t.Errorf("%d pixels alterados, esperado %d", summary.ChangedPixels, tt.changed)
+			}
+			count := 0
+			for _, row := range changed {
+				for _, c := range row {
+					if c {
+						count++
+					}
+				}
+			}
+			if count != tt.changed {
+				t.Errorf("máscara com %d pixels, esperado %d", count, tt.changed)
+			}
+			if identical := tt.changed == 0; identical != math.IsInf(summary.PSNR, 1) {
+				t.Errorf("PSNR %g para %d pixels alterados", summary.PSNR, tt.changed)
+			}
+		})
+	}
+}
+
+func TestDiffSummaryJSON(t *testing.T) {
+	tests := []struct {
+		psnr float64
+		want string
+	}{
+		{math.Inf(1), `"psnr":null`},
+		{31.5, `"psnr":31.5`},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(diffSummary{PSNR: tt.psnr})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), tt.want) {
+			t.Errorf("JSON %s, esperado %s", data, tt.want)
+		}
+	}
+}
+
+func TestRunDiff(t *testing.T) {
+	dir := t.TempDir()
+	base := synthetic.Checkerboard(32, 32, 8)
+	modified := synthetic.Checkerboard(32, 32, 8)
+	synthetic.FillCircle(modified, image.Pt(16, 16), 4, 128)
+	files := map[string]*image.Gray{"a.png": base, "b.png": modified, "c.png": image.NewGray(image.Rect(0, 0, 16, 16))}
+	for name, img := range files {
+		if err := saveImage(filepath.Join(dir, name), img); err != nil {
+			t.Fatal(err)
+		}
+	}
+	report := filepath.Join(dir, "report.png")
+
+	tests := []struct {
+		name   string
+		files  []string
+		code   int
+		report bool
+	}{
+		{"idênticas", []string{"a.png", "a.png"}, 0, false},
+		{"diferentes", []string{"a.png", "b.png"}, 1, true},
+		{"tamanhos diferentes", []string{"a.png", "c.png"}, 2, false},
+		{"arquivo que não existe", []string{"a.png", "nada.png"}, 2, false},
+		{"só um arquivo", []string{"a.png"}, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(report)
+			args := []string{"-out", report}
+			for _, f := range tt.files {
+				args = append(args, filepath.Join(dir, f))
+			}
+			if code := runDiff(args); code != tt.code {
+				t.Errorf("código de saída %d, esperado %d", code, tt.code)
+			}
+			if _, err := os.Stat(report); (err == nil) != tt.report {
+				t.Errorf("relatório gravado = %v, esperado %v", err == nil, tt.report)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"processing-images/imaging"
+)
+
+// directionMap colore cada pixel de borda pelo setor da direção do gradiente.
+// Pixels com magnitude abaixo do limiar ficam pretos.
+func directionMap(img *image.Gray, bins int, magnitudeThreshold float64) *image.RGBA {
+	if bins != 8 {
+		bins = 4
+	}
+	mag, dir := imaging.SobelGradient(img)
+
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	result := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{0, 0, 0, 255}
+			if mag[y][x] > magnitudeThreshold {
+				c = imaging.DirectionColors[imaging.QuantizeDirection(dir[y][x], bins)]
+			}
+			result.SetRGBA(x, y, c)
+		}
+	}
+
+	return result
+}
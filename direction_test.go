@@ -0,0 +1,79 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"processing-images/imaging"
+)
+
+// edgeImage é 32x32 com 255 onde bright é verdadeiro e 0 no resto.
+func edgeImage(bright func(x, y int) bool) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			if bright(x, y) {
+				img.Pix[y*img.Stride+x] = 255
+			}
+		}
+	}
+	return img
+}
+
+func TestDirectionMap(t *testing.T) {
+	black := color.RGBA{0, 0, 0, 255}
+	tests := []struct {
+		name string
+		img  *image.Gray
+		bins int
+		bin  int         // setor de todos os pixels de borda; -1 quando não há borda
+		flat image.Point // um pixel longe da borda, que fica preto
+	}{
+		{"borda vertical", edgeImage(func(x, y int) bool { return x >= 16 }), 4, 0, image.Pt(5, 10)},
+		{"borda horizontal", edgeImage(func(x, y int) bool { return y >= 16 }), 4, 2, image.Pt(10, 25)},
+		{"borda a 45°", edgeImage(func(x, y int) bool { return x+y >= 32 }), 4, 1, image.Pt(5, 5)},
+		{"borda a 135°", edgeImage(func(x, y int) bool { return x > y }), 4, 3, image.Pt(25, 5)},
+		// com 8 setores o sentido conta: clara à esquerda é 180°
+		{"borda vertical invertida, 8 setores", edgeImage(func(x, y int) bool { return x < 16 }), 8, 4, image.Pt(25, 3)},
+		{"borda vertical invertida, 4 setores", edgeImage(func(x, y int) bool { return x < 16 }), 4, 0, image.Pt(25, 3)},
+		{"lisa", edgeImage(func(x, y int) bool { return false }), 4, -1, image.Pt(16, 16)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := directionMap(tt.img, tt.bins, 100)
+			// longe da borda da imagem, onde a borda replicada entorta as
+			// diagonais
+			edges := 0
+			for y := 2; y < 30; y++ {
+				for x := 2; x < 30; x++ {
+					c := result.RGBAAt(x, y)
+					if c == black {
+						continue
+					}
+					edges++
+					if tt.bin < 0 || c != imaging.DirectionColors[tt.bin] {
+						t.Fatalf("pixel %d,%d com cor %v, esperado o setor %d", x, y, c, tt.bin)
+					}
+				}
+			}
+			if c := result.RGBAAt(tt.flat.X, tt.flat.Y); c != black {
+				t.Errorf("região lisa em %v colorida de %v", tt.flat, c)
+			}
+			if tt.bin >= 0 && edges == 0 {
+				t.Error("nenhum pixel de borda colorido")
+			}
+		})
+	}
+}
+
+func TestDirectionMapThreshold(t *testing.T) {
+	img := edgeImage(func(x, y int) bool { return x >= 16 })
+	// a magnitude do sobel nessa borda é 1020: acima dela tudo fica preto
+	result := directionMap(img, 4, 1020)
+	for i := 0; i < len(result.Pix); i += 4 {
+		if result.Pix[i] != 0 || result.Pix[i+1] != 0 || result.Pix[i+2] != 0 {
+			t.Fatal("pixel colorido com magnitude abaixo do limiar")
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"processing-images/imaging"
+)
+
+// normalizeBackground divide a imagem pela sua versão muito borrada (o fundo
+// estimado), removendo gradientes de iluminação: o papel fica perto de 255 e
+// o texto mantém o contraste relativo.
+func normalizeBackground(img *image.Gray, sigma float64) *image.Gray {
+	source := imaging.GrayToFloat(img)
+	background := imaging.SmoothFloat(source, sigma)
+	for y := range source {
+		for x := range source[y] {
+			if background[y][x] > 0 {
+				source[y][x] = math.Min(255, source[y][x]/background[y][x]*255)
+			} else {
+				source[y][x] = 255
+			}
+		}
+	}
+	return imaging.FloatToGray(source, 255)
+}
+
+// removeSmallComponents apaga (pinta de branco) os componentes pretos
+// 8-conectados com menos de minSize pixels.
+func removeSmallComponents(binary *image.Gray, minSize int) *image.Gray {
+	width, height := binary.Bounds().Dx(), binary.Bounds().Dy()
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		copy(result.Pix[y*result.Stride:y*result.Stride+width], binary.Pix[y*binary.Stride:])
+	}
+
+	visited := make([]bool, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if visited[y*width+x] || result.Pix[y*result.Stride+x] != 0 {
+				continue
+			}
+
+			component := []int{y*width + x}
+			visited[y*width+x] = true
+			for i := 0; i < len(component); i++ {
+				px, py := component[i]%width, component[i]/width
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						nx, ny := px+dx, py+dy
+						if nx < 0 || ny < 0 || nx >= width || ny >= height || visited[ny*width+nx] {
+							continue
+						}
+						if result.Pix[ny*result.Stride+nx] == 0 {
+							visited[ny*width+nx] = true
+							component = append(component, ny*width+nx)
+						}
+					}
+				}
+			}
+
+			if len(component) < minSize {
+				for _, p := range component {
+					result.Pix[(p/width)*result.Stride+p%width] = 255
+				}
+			}
+		}
+	}
+	return result
+}
+
+// docbinStages é o preset de binarização de documentos para OCR: fundo
+// normalizado, Sauvola, remoção de sujeira e, opcionalmente, endireitamento.
+// O resultado é texto preto sobre branco.
+func docbinStages() []pipelineStage {
+	stages := []pipelineStage{
+		{"fundo", func(img *image.Gray) (*image.Gray, error) {
+			return normalizeBackground(img, *docbinSigma), nil
+		}},
+		{"sauvola", func(img *image.Gray) (*image.Gray, error) {
+			return sauvolaThreshold(img, *docbinWindow, *docbinK), nil
+		}},
+		{"sujeira", func(img *image.Gray) (*image.Gray, error) {
+			return removeSmallComponents(img, *docbinSpeck), nil
+		}},
+	}
+	if *docbinDeskew {
+		stages = append(stages, pipelineStage{"endireitar", func(img *image.Gray) (*image.Gray, error) {
+			straight, angle := deskew(img, *deskewMax)
+			fmt.Printf("Inclinação estimada: %.2f°\n", angle)
+			// a interpolação cria cinzas nas bordas do texto: binariza de novo
+			return thresholdAt(straight, 128), nil
+		}})
+	}
+	return stages
+}
+
+// thresholdAt binariza com um limiar fixo: acima dele 255, senão 0.
+func thresholdAt(img *image.Gray, threshold uint8) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if img.Pix[y*img.Stride+x] > threshold {
+				result.Pix[y*result.Stride+x] = 255
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+// documentPage gera uma página com iluminação em degradê, linhas de texto
+// escuras e pontos de sujeira de 2x2, e devolve a máscara do texto e os
+// pontos de sujeira.
+func documentPage() (page *image.Gray, text []bool, specks []image.Point) {
+	const width, height = 320, 240
+	strokes := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i, line := range []string{"PACK MY BOX WITH", "FIVE DOZEN LIQUOR", "JUGS 0123456789"} {
+		drawText(strokes, 20, 30+i*50, line, 3, color.RGBA{255, 255, 255, 255})
+	}
+
+	page = image.NewGray(image.Rect(0, 0, width, height))
+	text = make([]bool, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			paper := 140 + 110*x/(width-1)
+			v := paper
+			if strokes.Pix[strokes.PixOffset(x, y)] != 0 {
+				text[y*width+x] = true
+				v = paper * 35 / 100
+			}
+			page.Pix[y*page.Stride+x] = uint8(v)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for len(specks) < 40 {
+		p := image.Pt(rng.Intn(width-2), rng.Intn(height-2))
+		near := false
+		for dy := -4; dy <= 5 && !near; dy++ {
+			for dx := -4; dx <= 5; dx++ {
+				q := p.Add(image.Pt(dx, dy))
+				if q.In(page.Bounds()) && text[q.Y*width+q.X] {
+					near = true
+					break
+				}
+			}
+		}
+		if near {
+			continue
+		}
+		for dy := 0; dy < 2; dy++ {
+			for dx := 0; dx < 2; dx++ {
+				i := (p.Y+dy)*page.Stride + p.X + dx
+				page.Pix[i] = page.Pix[i] * 40 / 100
+			}
+		}
+		specks = append(specks, p)
+	}
+	return page, text, specks
+}
+
+func TestDocbin(t *testing.T) {
+	page, text, specks := documentPage()
+	result, err := pipelines["docbin"].run(page, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	width := page.Bounds().Dx()
+
+	strokes, kept := 0, 0
+	for i, isText := range text {
+		if !isText {
+			continue
+		}
+		strokes++
+		if result.Pix[(i/width)*result.Stride+i%width] == 0 {
+			kept++
+		}
+	}
+	if recall := float64(kept) / float64(strokes); recall < 0.98 {
+		t.Errorf("recall do texto %.3f, esperado >= 0.98", recall)
+	}
+
+	for _, p := range specks {
+		for dy := 0; dy < 2; dy++ {
+			for dx := 0; dx < 2; dx++ {
+				if v := result.GrayAt(p.X+dx, p.Y+dy).Y; v != 255 {
+					t.Fatalf("sujeira em %v continua na saída", p)
+				}
+			}
+		}
+	}
+}
+
+func TestRemoveSmallComponents(t *testing.T) {
+	img := uniformGray(16, 255)
+	set := func(points ...[2]int) {
+		for _, p := range points {
+			img.Pix[p[1]*img.Stride+p[0]] = 0
+		}
+	}
+	set([2]int{1, 1})                                               // 1 pixel
+	set([2]int{5, 5}, [2]int{6, 6}, [2]int{7, 7})                   // 3 pixels em diagonal
+	set([2]int{10, 2}, [2]int{11, 2}, [2]int{12, 2}, [2]int{13, 2}) // 4 pixels
+	tests := []struct {
+		minSize int
+		want    int // pixels pretos que sobram
+	}{
+		{0, 8},
+		{2, 7},
+		{4, 4},
+		{5, 0},
+	}
+	for _, tt := range tests {
+		black := 0
+		for _, v := range removeSmallComponents(img, tt.minSize).Pix {
+			if v == 0 {
+				black++
+			}
+		}
+		if black != tt.want {
+			t.Errorf("minSize %d: sobraram %d pixels pretos, esperado %d", tt.minSize, black, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// grayToRGBA copia a imagem em tons de cinza para uma RGBA, base das
+// sobreposições coloridas.
+func grayToRGBA(img *image.Gray) *image.RGBA {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	result := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := img.GrayAt(img.Bounds().Min.X+x, img.Bounds().Min.Y+y).Y
+			result.SetRGBA(x, y, color.RGBA{v, v, v, 255})
+		}
+	}
+	return result
+}
+
+// drawRect desenha o contorno de um retângulo (1 pixel) na imagem.
+func drawRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	r = r.Intersect(img.Bounds())
+	if r.Empty() {
+		return
+	}
+	for x := r.Min.X; x < r.Max.X; x++ {
+		img.SetRGBA(x, r.Min.Y, c)
+		img.SetRGBA(x, r.Max.Y-1, c)
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		img.SetRGBA(r.Min.X, y, c)
+		img.SetRGBA(r.Max.X-1, y, c)
+	}
+}
+
+// drawCircle desenha o contorno de um círculo pelo algoritmo do ponto médio.
+func drawCircle(img *image.RGBA, c image.Point, r int, col color.RGBA) {
+	if r <= 0 {
+		img.SetRGBA(c.X, c.Y, col)
+		return
+	}
+	x, y := r, 0
+	d := 1 - r
+	for x >= y {
+		for _, p := range [][2]int{
+			{x, y}, {y, x}, {-y, x}, {-x, y},
+			{-x, -y}, {-y, -x}, {y, -x}, {x, -y},
+		} {
+			pt := image.Pt(c.X+p[0], c.Y+p[1])
+			if pt.In(img.Bounds()) {
+				img.SetRGBA(pt.X, pt.Y, col)
+			}
+		}
+		y++
+		if d < 0 {
+			d += 2*y + 1
+		} else {
+			x--
+			d += 2*(y-x) + 1
+		}
+	}
+}
+
+// fillCircleGray pinta os pixels cujo centro está a até r de (cx, cy).
+func fillCircleGray(img *image.Gray, cx, cy, r float64, value uint8) {
+	b := img.Bounds()
+	x0, x1 := max(int(math.Floor(cx-r)), b.Min.X), min(int(math.Ceil(cx+r)), b.Max.X-1)
+	y0, y1 := max(int(math.Floor(cy-r)), b.Min.Y), min(int(math.Ceil(cy+r)), b.Max.Y-1)
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			dx, dy := float64(x)-cx, float64(y)-cy
+			if dx*dx+dy*dy <= r*r {
+				img.Pix[img.PixOffset(x, y)] = value
+			}
+		}
+	}
+}
+
+// drawLine desenha um segmento de (x0, y0) a (x1, y1) pelo algoritmo de
+// Bresenham; pontos fora da imagem são ignorados.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, col color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		if image.Pt(x0, y0).In(img.Bounds()) {
+			img.SetRGBA(x0, y0, col)
+		}
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// labelImage pinta cada rótulo com uma matiz própria (ângulo de ouro, para
+// rótulos vizinhos não ficarem parecidos); 0 (linhas do watershed, fundo
+// de -ops label) sai branco.
+func labelImage(labels [][]int) *image.RGBA {
+	height := len(labels)
+	width := 0
+	if height > 0 {
+		width = len(labels[0])
+	}
+	result := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y, row := range labels {
+		for x, l := range row {
+			c := color.RGBA{255, 255, 255, 255}
+			if l > 0 {
+				c = hsvToRGB(float64(l)*137.508, 0.7, 0.9)
+			}
+			result.SetRGBA(x, y, c)
+		}
+	}
+	return result
+}
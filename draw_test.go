@@ -0,0 +1,80 @@
+package main
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestLabelImage(t *testing.T) {
+	labels := [][]int{
+		{0, 1, 1, 2},
+		{3, 3, 0, 2},
+		{4, 5, 6, 7},
+	}
+	img := labelImage(labels)
+	if img.Bounds().Dx() != 4 || img.Bounds().Dy() != 3 {
+		t.Fatalf("limites %v", img.Bounds())
+	}
+	white := color.RGBA{255, 255, 255, 255}
+	colors := map[color.RGBA]int{}
+	for y, row := range labels {
+		for x, l := range row {
+			c := img.RGBAAt(x, y)
+			if (l == 0) != (c == white) {
+				t.Errorf("%d,%d: rótulo %d pintado de %v", x, y, l, c)
+			}
+			if l == 0 {
+				continue
+			}
+			if other, ok := colors[c]; ok && other != l {
+				t.Errorf("rótulos %d e %d com a mesma cor %v", other, l, c)
+			}
+			colors[c] = l
+		}
+	}
+	if len(colors) != 7 {
+		t.Errorf("%d cores para 7 rótulos", len(colors))
+	}
+	if empty := labelImage(nil); !empty.Bounds().Empty() {
+		t.Errorf("sem rótulos deu limites %v", empty.Bounds())
+	}
+}
+
+func TestOpLabel(t *testing.T) {
+	// dois quadrados escuros que só se tocam pela diagonal
+	img := uniformGray(20, 200)
+	for _, r := range [][2]int{{4, 4}, {9, 9}} {
+		for y := r[1]; y < r[1]+5; y++ {
+			for x := r[0]; x < r[0]+5; x++ {
+				img.Pix[y*img.Stride+x] = 20
+			}
+		}
+	}
+	tests := []struct {
+		connectivity int
+		same         bool
+	}{
+		{4, false},
+		{8, true},
+	}
+	old := *connectivity
+	t.Cleanup(func() { *connectivity = old })
+	for _, tt := range tests {
+		*connectivity = tt.connectivity
+		outputs, err := opLabel(opInput{img: img})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(outputs) != 1 || outputs[0].name != "labels.png" {
+			t.Fatalf("saídas %v", outputs)
+		}
+		got := outputs[0].img
+		if same := got.At(5, 5) == got.At(12, 12); same != tt.same {
+			t.Errorf("vizinhança %d: mesma cor nos dois quadrados %v, esperado %v", tt.connectivity, same, tt.same)
+		}
+	}
+	*connectivity = 5
+	if _, err := opLabel(opInput{img: img}); err == nil {
+		t.Error("vizinhança 5 sem erro")
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// polaridade do primeiro plano em máscaras binárias
+type polarity int
+
+const (
+	foregroundWhite polarity = iota // objetos em 255 (máscaras, saída do otsu)
+	foregroundBlack                 // objetos em 0 (convenção de imaging.CountObjects)
+)
+
+func parsePolarity(s string) (polarity, error) {
+	switch s {
+	case "white":
+		return foregroundWhite, nil
+	case "black":
+		return foregroundBlack, nil
+	}
+	return 0, fmt.Errorf("polaridade desconhecida %q, use white ou black", s)
+}
+
+// isForeground binariza um pixel pelo meio da escala segundo a polaridade.
+func (p polarity) isForeground(v uint8) bool {
+	if p == foregroundBlack {
+		return v < 128
+	}
+	return v >= 128
+}
+
+// Metrics são as métricas de uma segmentação comparada à verdade de campo.
+type Metrics struct {
+	TP, FP, FN, TN int
+
+	Accuracy  float64
+	Precision float64
+	Recall    float64
+	F1        float64
+	IoU       float64
+	Dice      float64
+}
+
+// evaluateSegmentation compara pred com truth considerando o primeiro plano
+// branco. Veja evaluateSegmentationPolarity.
+func evaluateSegmentation(pred, truth *image.Gray) Metrics {
+	return evaluateSegmentationPolarity(pred, truth, foregroundWhite)
+}
+
+// evaluateSegmentationPolarity conta TP/FP/FN/TN pixel a pixel e deriva as
+// métricas. As imagens devem ter as mesmas dimensões (só a área comum é
+// avaliada). Quando um denominador é zero a métrica vale 1 se nem a predição
+// nem a verdade têm primeiro plano (concordam que não há nada) e 0 caso
+// contrário; nunca há divisão por zero.
+func evaluateSegmentationPolarity(pred, truth *image.Gray, fg polarity) Metrics {
+	var m Metrics
+	width := min(pred.Bounds().Dx(), truth.Bounds().Dx())
+	height := min(pred.Bounds().Dy(), truth.Bounds().Dy())
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := fg.isForeground(pred.Pix[y*pred.Stride+x])
+			t := fg.isForeground(truth.Pix[y*truth.Stride+x])
+			switch {
+			case p && t:
+				m.TP++
+			case p && !t:
+				m.FP++
+			case !p && t:
+				m.FN++
+			default:
+				m.TN++
+			}
+		}
+	}
+
+	empty := 0.0
+	if m.TP+m.FP+m.FN == 0 {
+		empty = 1
+	}
+	ratio := func(num, den int) float64 {
+		if den == 0 {
+			return empty
+		}
+		return float64(num) / float64(den)
+	}
+
+	m.Accuracy = ratio(m.TP+m.TN, m.TP+m.TN+m.FP+m.FN)
+	m.Precision = ratio(m.TP, m.TP+m.FP)
+	m.Recall = ratio(m.TP, m.TP+m.FN)
+	m.IoU = ratio(m.TP, m.TP+m.FP+m.FN)
+	m.Dice = ratio(2*m.TP, 2*m.TP+m.FP+m.FN)
+	// F1 é igual ao Dice para máscaras binárias
+	m.F1 = m.Dice
+
+	return m
+}
+
+// segmentationOverlay pinta TP de verde, FP de vermelho e FN de azul.
+func segmentationOverlay(pred, truth *image.Gray, fg polarity) *image.RGBA {
+	width := min(pred.Bounds().Dx(), truth.Bounds().Dx())
+	height := min(pred.Bounds().Dy(), truth.Bounds().Dy())
+	result := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := fg.isForeground(pred.Pix[y*pred.Stride+x])
+			t := fg.isForeground(truth.Pix[y*truth.Stride+x])
+			c := color.RGBA{0, 0, 0, 255}
+			switch {
+			case p && t:
+				c = color.RGBA{0, 255, 0, 255}
+			case p && !t:
+				c = color.RGBA{255, 0, 0, 255}
+			case !p && t:
+				c = color.RGBA{0, 0, 255, 255}
+			}
+			result.SetRGBA(x, y, c)
+		}
+	}
+	return result
+}
+
+func printMetrics(name string, m Metrics) {
+	fmt.Printf("%-20s acc=%.4f prec=%.4f rec=%.4f f1=%.4f iou=%.4f dice=%.4f\n",
+		name, m.Accuracy, m.Precision, m.Recall, m.F1, m.IoU, m.Dice)
+}
+
+// gotoshop eval -truth gt.png pred.png...
+// gotoshop eval -truth-dir gt/ -pred-dir out/   (out/<método>/<arquivo do gt>)
+func runEval(args []string) int {
+	fs := newFlagSet("eval", "-truth gt.png pred.png... | -truth-dir gt/ -pred-dir out/")
+	truthPath := fs.String("truth", "", "máscara de verdade de campo")
+	truthDir := fs.String("truth-dir", "", "diretório com as máscaras de verdade (modo lote)")
+	predDir := fs.String("pred-dir", "", "diretório com um subdiretório por método (modo lote)")
+	fgName := fs.String("fg", "white", "cor do primeiro plano: white ou black")
+	viz := fs.String("viz", "", "salva a visualização TP/FP/FN (verde/vermelho/azul); só com uma predição")
+
+	preds, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+	fg, err := parsePolarity(*fgName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if *truthDir != "" || *predDir != "" {
+		if *truthDir == "" || *predDir == "" {
+			fs.Usage()
+			return 2
+		}
+		if err := evalBatch(*truthDir, *predDir, fg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	if *truthPath == "" || len(preds) == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	truth, err := loadImage(*truthPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	for _, path := range preds {
+		pred, err := loadImage(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if pred.Bounds().Size() != truth.Bounds().Size() {
+			fmt.Fprintf(os.Stderr, "Erro: %s tem dimensões diferentes da verdade de campo\n", path)
+			return 1
+		}
+		printMetrics(filepath.Base(path), evaluateSegmentationPolarity(pred, truth, fg))
+		if *viz != "" && len(preds) == 1 {
+			if err := saveImage(*viz, segmentationOverlay(pred, truth, fg)); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+		}
+	}
+	return 0
+}
+
+// evalBatch compara cada máscara de truthDir com o arquivo de mesmo nome em
+// cada subdiretório de predDir e imprime a média das métricas por método.
+func evalBatch(truthDir, predDir string, fg polarity) error {
+	truths, err := filepath.Glob(filepath.Join(truthDir, "*.png"))
+	if err != nil || len(truths) == 0 {
+		return fmt.Errorf("nenhuma máscara PNG em %s", truthDir)
+	}
+	entries, err := os.ReadDir(predDir)
+	if err != nil {
+		return fmt.Errorf("erro ao ler %s: %w", predDir, err)
+	}
+
+	var methods []string
+	for _, e := range entries {
+		if e.IsDir() {
+			methods = append(methods, e.Name())
+		}
+	}
+	sort.Strings(methods)
+
+	for _, method := range methods {
+		var sum Metrics
+		n := 0
+		for _, truthPath := range truths {
+			predPath := filepath.Join(predDir, method, filepath.Base(truthPath))
+			if _, err := os.Stat(predPath); err != nil {
+				continue
+			}
+			pred, err := loadImage(predPath)
+			if err != nil {
+				return err
+			}
+			truth, err := loadImage(truthPath)
+			if err != nil {
+				return err
+			}
+			if pred.Bounds().Size() != truth.Bounds().Size() {
+				return fmt.Errorf("%s tem dimensões diferentes de %s", predPath, truthPath)
+			}
+			m := evaluateSegmentationPolarity(pred, truth, fg)
+			sum.Accuracy += m.Accuracy
+			sum.Precision += m.Precision
+			sum.Recall += m.Recall
+			sum.F1 += m.F1
+			sum.IoU += m.IoU
+			sum.Dice += m.Dice
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		mean := Metrics{
+			Accuracy:  sum.Accuracy / float64(n),
+			Precision: sum.Precision / float64(n),
+			Recall:    sum.Recall / float64(n),
+			F1:        sum.F1 / float64(n),
+			IoU:       sum.IoU / float64(n),
+			Dice:      sum.Dice / float64(n),
+		}
+		printMetrics(fmt.Sprintf("%s (%d)", method, n), mean)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// maskFrom monta uma máscara 10x10 com 255 onde fg é verdadeiro.
+func maskFrom(fg func(x, y int) bool) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			if fg(x, y) {
+				img.Pix[y*img.Stride+x] = 255
+			}
+		}
+	}
+	return img
+}
+
+func TestEvaluateSegmentation(t *testing.T) {
+	left := maskFrom(func(x, y int) bool { return x < 5 })
+	wide := maskFrom(func(x, y int) bool { return x < 7 })
+	right := maskFrom(func(x, y int) bool { return x >= 5 })
+	empty := maskFrom(func(x, y int) bool { return false })
+
+	tests := []struct {
+		name        string
+		pred, truth *image.Gray
+		want        Metrics
+	}{
+		{"idênticas", left, left, Metrics{TP: 50, TN: 50, Accuracy: 1, Precision: 1, Recall: 1, F1: 1, IoU: 1, Dice: 1}},
+		{"predição maior", wide, left, Metrics{TP: 50, FP: 20, TN: 30,
+			Accuracy: 0.8, Precision: 50.0 / 70, Recall: 1, F1: 100.0 / 120, IoU: 50.0 / 70, Dice: 100.0 / 120}},
+		{"predição menor", left, wide, Metrics{TP: 50, FN: 20, TN: 30,
+			Accuracy: 0.8, Precision: 1, Recall: 50.0 / 70, F1: 100.0 / 120, IoU: 50.0 / 70, Dice: 100.0 / 120}},
+		{"disjuntas", right, left, Metrics{FP: 50, FN: 50}},
+		// sem primeiro plano nas duas, elas concordam: tudo vale 1
+		{"tudo fundo", empty, empty, Metrics{TN: 100, Accuracy: 1, Precision: 1, Recall: 1, F1: 1, IoU: 1, Dice: 1}},
+		{"predição vazia", empty, left, Metrics{FN: 50, TN: 50, Accuracy: 0.5}},
+		{"verdade vazia", left, empty, Metrics{FP: 50, TN: 50, Accuracy: 0.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkMetrics(t, evaluateSegmentation(tt.pred, tt.truth), tt.want)
+			// com as máscaras invertidas e primeiro plano preto o resultado é o mesmo
+			checkMetrics(t, evaluateSegmentationPolarity(invertGray(tt.pred), invertGray(tt.truth), foregroundBlack), tt.want)
+		})
+	}
+}
+
+func checkMetrics(t *testing.T, got, want Metrics) {
+	t.Helper()
+	if got.TP != want.TP || got.FP != want.FP || got.FN != want.FN || got.TN != want.TN {
+		t.Fatalf("TP/FP/FN/TN = %d/%d/%d/%d, esperado %d/%d/%d/%d",
+			got.TP, got.FP, got.FN, got.TN, want.TP, want.FP, want.FN, want.TN)
+	}
+	values := []struct {
+		name      string
+		got, want float64
+	}{
+		{"acurácia", got.Accuracy, want.Accuracy},
+		{"precisão", got.Precision, want.Precision},
+		{"revocação", got.Recall, want.Recall},
+		{"F1", got.F1, want.F1},
+		{"IoU", got.IoU, want.IoU},
+		{"Dice", got.Dice, want.Dice},
+	}
+	for _, v := range values {
+		if math.IsNaN(v.got) || math.Abs(v.got-v.want) > 1e-12 {
+			t.Errorf("%s = %g, esperado %g", v.name, v.got, v.want)
+		}
+	}
+}
+
+func invertGray(img *image.Gray) *image.Gray {
+	result := image.NewGray(img.Bounds())
+	for i, v := range img.Pix {
+		result.Pix[i] = 255 - v
+	}
+	return result
+}
+
+func TestSegmentationOverlay(t *testing.T) {
+	pred := maskFrom(func(x, y int) bool { return x < 7 })
+	truth := maskFrom(func(x, y int) bool { return x >= 3 })
+	overlay := segmentationOverlay(pred, truth, foregroundWhite)
+	tests := []struct {
+		x    int
+		want color.RGBA
+	}{
+		{1, color.RGBA{255, 0, 0, 255}}, // FP
+		{5, color.RGBA{0, 255, 0, 255}}, // TP
+		{8, color.RGBA{0, 0, 255, 255}}, // FN
+	}
+	for _, tt := range tests {
+		if c := overlay.RGBAAt(tt.x, 4); c != tt.want {
+			t.Errorf("coluna %d = %v, esperado %v", tt.x, c, tt.want)
+		}
+	}
+}
+
+func TestParsePolarity(t *testing.T) {
+	for _, s := range []string{"white", "black"} {
+		if _, err := parsePolarity(s); err != nil {
+			t.Errorf("parsePolarity(%q): %v", s, err)
+		}
+	}
+	if _, err := parsePolarity("gray"); err == nil {
+		t.Error("esperava erro para polaridade desconhecida")
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/binary"
+	"image"
+)
+
+// exifOrientation procura a tag Orientation (0x0112) no segmento APP1 de um
+// JPEG. Devolve 1 (normal) quando não há EXIF ou o arquivo não é JPEG.
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		if marker == 0xD8 || (marker >= 0xD0 && marker <= 0xD7) || marker == 0x01 {
+			pos += 2
+			continue
+		}
+		// início dos dados comprimidos: não há mais metadados
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if size < 2 || pos+2+size > len(data) {
+			return 1
+		}
+		segment := data[pos+4 : pos+2+size]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		pos += 2 + size
+	}
+
+	return 1
+}
+
+// tiffOrientation lê a IFD0 do cabeçalho TIFF embutido no EXIF.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) != 0x0112 {
+			continue
+		}
+		// tipo SHORT, valor guardado no próprio campo de offset
+		value := int(order.Uint16(tiff[entry+8:]))
+		if value < 1 || value > 8 {
+			return 1
+		}
+		return value
+	}
+
+	return 1
+}
+
+// orientPoint devolve a posição de (x, y) numa imagem w×h depois da
+// transformação da orientação EXIF.
+func orientPoint(x, y, w, h, orientation int) (int, int) {
+	switch orientation {
+	case 2: // espelhamento horizontal
+		return w - 1 - x, y
+	case 3: // 180°
+		return w - 1 - x, h - 1 - y
+	case 4: // espelhamento vertical
+		return x, h - 1 - y
+	case 5: // transposição
+		return y, x
+	case 6: // 90° horário
+		return h - 1 - y, x
+	case 7: // transversa
+		return h - 1 - y, w - 1 - x
+	case 8: // 90° anti-horário
+		return y, w - 1 - x
+	}
+	return x, y
+}
+
+// orientedRect devolve os limites da imagem transformada (5 a 8 trocam
+// largura e altura).
+func orientedRect(w, h, orientation int) image.Rectangle {
+	if orientation >= 5 {
+		return image.Rect(0, 0, h, w)
+	}
+	return image.Rect(0, 0, w, h)
+}
+
+// applyOrientation rotaciona/espelha a imagem para a orientação de exibição
+// indicada pela tag EXIF (1 a 8).
+func applyOrientation(img *image.Gray, orientation int) *image.Gray {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	result := image.NewGray(orientedRect(w, h, orientation))
+	for y := 0; y < h; y++ {
+		row := img.Pix[img.PixOffset(b.Min.X, b.Min.Y+y):]
+		for x := 0; x < w; x++ {
+			dx, dy := orientPoint(x, y, w, h, orientation)
+			result.Pix[dy*result.Stride+dx] = row[x]
+		}
+	}
+
+	return result
+}
+
+// applyOrientationRGBA é a versão colorida de applyOrientation.
+func applyOrientationRGBA(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	result := image.NewRGBA(orientedRect(w, h, orientation))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dx, dy := orientPoint(x, y, w, h, orientation)
+			copy(result.Pix[dy*result.Stride+4*dx:dy*result.Stride+4*dx+4], img.Pix[y*img.Stride+4*x:])
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"testing"
+)
+
+// Os arquivos testdata/orientation-N.jpg guardam uma imagem 16x8 preta com
+// um quadrado branco 4x4 no canto superior esquerdo e o EXIF Orientation N;
+// ao carregar, o quadrado deve ir para o canto que a orientação indica.
+func TestDecodeImageOrientation(t *testing.T) {
+	tests := []struct {
+		orientation   int
+		width, height int
+		corner        image.Point // canto do quadrado na imagem exibida
+	}{
+		{1, 16, 8, image.Pt(0, 0)},
+		{3, 16, 8, image.Pt(12, 4)},
+		{6, 8, 16, image.Pt(4, 0)},
+		{8, 8, 16, image.Pt(0, 12)},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.orientation), func(t *testing.T) {
+			path := fmt.Sprintf("testdata/orientation-%d.jpg", tt.orientation)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := exifOrientation(data); got != tt.orientation {
+				t.Errorf("exifOrientation = %d, esperado %d", got, tt.orientation)
+			}
+			img, _, err := decodeImage(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gray := grayscale(img)
+			if b := gray.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+				t.Fatalf("tamanho %dx%d, esperado %dx%d", b.Dx(), b.Dy(), tt.width, tt.height)
+			}
+			marker := image.Rectangle{tt.corner, tt.corner.Add(image.Pt(4, 4))}
+			for y := 0; y < tt.height; y++ {
+				for x := 0; x < tt.width; x++ {
+					v := gray.GrayAt(x, y).Y
+					inside := image.Pt(x, y).In(marker)
+					if inside && v < 200 || !inside && v > 55 {
+						t.Fatalf("pixel %d,%d = %d; o quadrado devia estar em %v", x, y, v, marker)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeImageNoExifRotate(t *testing.T) {
+	*noExifRotate = true
+	defer func() { *noExifRotate = false }()
+	img, _, err := decodeImage("testdata/orientation-6.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 16 || b.Dy() != 8 {
+		t.Errorf("com -no-exif-rotate o tamanho devia ficar 16x8, não %dx%d", b.Dx(), b.Dy())
+	}
+}
+
+func TestExifOrientationWithoutExif(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"vazio", nil},
+		{"png", pngSignature},
+		{"jpeg sem APP1", []byte{0xFF, 0xD8, 0xFF, 0xDA, 0, 2}},
+		{"segmento truncado", []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x10, 0x00, 'E'}},
+	}
+	for _, tt := range tests {
+		if got := exifOrientation(tt.data); got != 1 {
+			t.Errorf("%s: exifOrientation = %d, esperado 1", tt.name, got)
+		}
+	}
+}
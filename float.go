@@ -0,0 +1,35 @@
+package main
+
+// boxMeanFloat calcula a média de cada janela (2r+1)² por tabela de somas, em
+// O(1) por pixel. Perto das bordas a janela é recortada e a média usa só os
+// pixels dentro da imagem.
+func boxMeanFloat(field [][]float64, radius int) [][]float64 {
+	height := len(field)
+	if height == 0 {
+		return nil
+	}
+	width := len(field[0])
+
+	sat := make([][]float64, height+1)
+	sat[0] = make([]float64, width+1)
+	for y := 0; y < height; y++ {
+		sat[y+1] = make([]float64, width+1)
+		var rowSum float64
+		for x := 0; x < width; x++ {
+			rowSum += field[y][x]
+			sat[y+1][x+1] = sat[y][x+1] + rowSum
+		}
+	}
+
+	result := make([][]float64, height)
+	for y := 0; y < height; y++ {
+		result[y] = make([]float64, width)
+		y0, y1 := max(y-radius, 0), min(y+radius+1, height)
+		for x := 0; x < width; x++ {
+			x0, x1 := max(x-radius, 0), min(x+radius+1, width)
+			sum := sat[y1][x1] - sat[y0][x1] - sat[y1][x0] + sat[y0][x0]
+			result[y][x] = sum / float64((y1-y0)*(x1-x0))
+		}
+	}
+	return result
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// crackImage desenha em fundo branco dois arcos escuros de 2 px de largura e
+// manchas circulares escuras que não os tocam, e devolve as máscaras de cada
+// um.
+func crackImage() (img *image.Gray, line, blob []bool) {
+	const size = 128
+	img = image.NewGray(image.Rect(0, 0, size, size))
+	line = make([]bool, size*size)
+	blob = make([]bool, size*size)
+	for i := range img.Pix {
+		img.Pix[i] = synthetic.Background
+	}
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			r := math.Hypot(float64(x-40), float64(y-40))
+			wave := 96 + 10*math.Sin(float64(x)/8)
+			if (r >= 25 && r < 27) || (float64(y) >= wave && float64(y) < wave+2) {
+				line[y*size+x] = true
+				img.Pix[y*img.Stride+x] = synthetic.Foreground
+			}
+		}
+	}
+	rng := rand.New(rand.NewSource(1))
+	for placed := 0; placed < 6; {
+		cx, cy, radius := 8+rng.Intn(size-16), 8+rng.Intn(size-16), 3+rng.Intn(3)
+		free := true
+		for y := cy - radius - 4; y <= cy+radius+4 && free; y++ {
+			for x := cx - radius - 4; x <= cx+radius+4; x++ {
+				if x >= 0 && y >= 0 && x < size && y < size && (line[y*size+x] || blob[y*size+x]) {
+					free = false
+					break
+				}
+			}
+		}
+		if !free {
+			continue
+		}
+		for y := cy - radius; y <= cy+radius; y++ {
+			for x := cx - radius; x <= cx+radius; x++ {
+				if (x-cx)*(x-cx)+(y-cy)*(y-cy) <= radius*radius {
+					blob[y*size+x] = true
+					img.Pix[y*img.Stride+x] = synthetic.Foreground
+				}
+			}
+		}
+		placed++
+	}
+	return img, line, blob
+}
+
+func TestFrangiRanksLinesAboveBlobs(t *testing.T) {
+	img, line, blob := crackImage()
+	response := frangiVesselness(img, []float64{1, 1.5, 2}, 0.5, 0, true)
+	width := img.Bounds().Dx()
+
+	var lines, blobs []float64
+	for i := range line {
+		v := response[i/width][i%width]
+		if line[i] {
+			lines = append(lines, v)
+		}
+		if blob[i] {
+			blobs = append(blobs, v)
+		}
+	}
+	// AUC: fração dos pares (linha, mancha) em que a linha responde mais
+	var wins float64
+	for _, l := range lines {
+		for _, b := range blobs {
+			switch {
+			case l > b:
+				wins++
+			case l == b:
+				wins += 0.5
+			}
+		}
+	}
+	if auc := wins / float64(len(lines)*len(blobs)); auc < 0.9 {
+		t.Errorf("AUC %.3f entre linhas e manchas, esperado >= 0.9", auc)
+	}
+}
+
+func TestFrangiScalePeak(t *testing.T) {
+	scales := []float64{1, 2, 4}
+	tests := []struct {
+		width int
+		want  float64 // escala com a maior resposta no centro da linha
+	}{
+		{2, 1},
+		{4, 2},
+		{8, 4},
+	}
+	for _, tt := range tests {
+		img := image.NewGray(image.Rect(0, 0, 64, 64))
+		for y := 0; y < 64; y++ {
+			for x := 0; x < 64; x++ {
+				img.Pix[y*img.Stride+x] = synthetic.Background
+				if x >= 32-tt.width/2 && x < 32+tt.width/2 {
+					img.Pix[y*img.Stride+x] = synthetic.Foreground
+				}
+			}
+		}
+		// c fixo e alto para que a resposta não sature e as escalas se
+		// distingam
+		best, bestValue := 0.0, -1.0
+		for _, sigma := range scales {
+			response := frangiVesselness(img, []float64{sigma}, 0.5, 500, true)
+			if v := math.Max(response[32][31], response[32][32]); v > bestValue {
+				best, bestValue = sigma, v
+			}
+		}
+		if best != tt.want {
+			t.Errorf("linha de %d px: pico na escala %v, esperado %v", tt.width, best, tt.want)
+		}
+	}
+}
+
+func TestFrangiPolarity(t *testing.T) {
+	img, line, _ := crackImage()
+	response := frangiVesselness(img, []float64{1}, 0.5, 0, false)
+	width := img.Bounds().Dx()
+	for i := range line {
+		if line[i] && response[i/width][i%width] != 0 {
+			t.Fatalf("linha escura respondeu com darkRidges falso em %d,%d", i%width, i/width)
+		}
+	}
+}
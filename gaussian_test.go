@@ -0,0 +1,150 @@
+package main
+
+import (
+	"image"
+	"math"
+	"sort"
+	"testing"
+
+	"processing-images/imaging"
+	"processing-images/synthetic"
+)
+
+func TestGaussianBoxSizes(t *testing.T) {
+	for _, sigma := range []float64{2.5, 5, 10, 20, 37} {
+		sizes := gaussianBoxSizes(sigma, 3)
+		// a variância de uma caixa de largura w é (w² − 1)/12 e as variâncias
+		// das passadas se somam
+		var variance float64
+		for _, w := range sizes {
+			if w%2 == 0 {
+				t.Errorf("σ=%g: largura par %d", sigma, w)
+			}
+			variance += float64(w*w-1) / 12
+		}
+		if got := math.Sqrt(variance); math.Abs(got-sigma) > 0.1*sigma {
+			t.Errorf("σ=%g: caixas %v dão σ=%.3f", sigma, sizes, got)
+		}
+	}
+}
+
+func TestFastGaussianMatchesExact(t *testing.T) {
+	// quadrados com ruído, como uma foto; em bordas 0/255 sem ruído a
+	// diferença chega a 3–4 níveis
+	squares, _ := synthetic.Squares(256, 256, 10, 30, 1)
+	img, err := synthetic.AddGaussianNoise(squares, 20, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exact := imaging.GaussianBlur(img, 10)
+	fast := fastGaussianBlur(img, 10)
+	diffs := make([]int, len(img.Pix))
+	for i := range diffs {
+		diffs[i] = int(fast.Pix[i]) - int(exact.Pix[i])
+		if diffs[i] < 0 {
+			diffs[i] = -diffs[i]
+		}
+	}
+	sort.Ints(diffs)
+	if p99 := diffs[len(diffs)*99/100]; p99 > 2 {
+		t.Errorf("diferença no percentil 99 de %d níveis, esperado no máximo 2", p99)
+	}
+}
+
+func TestFastGaussianConstant(t *testing.T) {
+	result := fastGaussianBlur(uniformGray(50, 123), 20)
+	for i, v := range result.Pix {
+		if v != 123 {
+			t.Fatalf("pixel %d = %d, esperado 123", i, v)
+		}
+	}
+}
+
+func TestBlurImageMethod(t *testing.T) {
+	img := synthetic.SiemensStar(64, 64, 8)
+	tests := []struct {
+		method string
+		sigma  float64
+		fast   bool
+	}{
+		{"auto", 2, false},
+		{"auto", fastBlurSigma, false},
+		{"auto", 8, true},
+		{"exact", 8, false},
+		{"fast", 2, true},
+	}
+	for _, tt := range tests {
+		got, err := blurImage(img, tt.sigma, tt.method)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := imaging.GaussianBlur(img, tt.sigma)
+		if tt.fast {
+			want = fastGaussianBlur(img, tt.sigma)
+		}
+		for i := range got.Pix {
+			if got.Pix[i] != want.Pix[i] {
+				t.Errorf("%s σ=%g: não usou o método esperado (rápido: %v)", tt.method, tt.sigma, tt.fast)
+				break
+			}
+		}
+	}
+	if _, err := blurImage(img, 2, "box"); err == nil {
+		t.Error("método desconhecido sem erro")
+	}
+}
+
+func benchmarkBlur(b *testing.B, blur func() any) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		blur()
+	}
+}
+
+// go test -bench Blur -run ^$ compara as duas em 2048² com σ=20.
+func BenchmarkGaussianExact(b *testing.B) {
+	img := synthetic.SiemensStar(2048, 2048, 32)
+	benchmarkBlur(b, func() any { return imaging.GaussianBlur(img, 20) })
+}
+
+func BenchmarkGaussianFast(b *testing.B) {
+	img := synthetic.SiemensStar(2048, 2048, 32)
+	benchmarkBlur(b, func() any { return fastGaussianBlur(img, 20) })
+}
+
+func TestOpGaussian(t *testing.T) {
+	old := *gaussianSigma
+	t.Cleanup(func() { *gaussianSigma = old })
+	img := synthetic.SiemensStar(40, 40, 8)
+	tests := []struct {
+		sigma float64
+		ok    bool
+	}{
+		{1.4, true},
+		{3, true},
+		{0, false},
+		{-2, false},
+	}
+	for _, tt := range tests {
+		*gaussianSigma = tt.sigma
+		outputs, err := opGaussian(opInput{img: img})
+		if (err == nil) != tt.ok {
+			t.Errorf("σ=%g: erro %v", tt.sigma, err)
+			continue
+		}
+		if !tt.ok {
+			continue
+		}
+		if len(outputs) != 1 || outputs[0].name != "gaussian.png" {
+			t.Fatalf("σ=%g: saídas %v", tt.sigma, outputs)
+		}
+		want := imaging.GaussianBlur(img, tt.sigma)
+		got := outputs[0].img.(*image.Gray)
+		for i := range want.Pix {
+			if got.Pix[i] != want.Pix[i] {
+				t.Errorf("σ=%g: saída difere de GaussianBlur", tt.sigma)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+
+	"processing-images/synthetic"
+)
+
+// gotoshop gen -kind checkerboard|ramp|circles|squares|noise|siemens-star -size 512x512 -out test.png
+func runGen(args []string) int {
+	fs := newFlagSet("gen", "-kind tipo [-size LxA] [-out arquivo.png]")
+	kind := fs.String("kind", "checkerboard", "checkerboard, ramp, circles, squares, noise ou siemens-star")
+	size := fs.String("size", "512x512", "dimensões da imagem (LxA)")
+	out := fs.String("out", "test.png", "arquivo de saída")
+	square := fs.Int("square", 32, "lado das casas (checkerboard) ou dos quadrados (squares)")
+	count := fs.Int("count", 10, "número de círculos ou quadrados")
+	radius := fs.Int("radius", 20, "raio dos círculos")
+	overlap := fs.Bool("overlap", false, "permite círculos sobrepostos")
+	sigma := fs.Float64("sigma", 30, "desvio padrão do ruído")
+	mean := fs.Float64("mean", 128, "média do ruído")
+	seed := fs.Int64("seed", 1, "semente dos geradores aleatórios")
+	direction := fs.String("dir", "horizontal", "direção do degradê: horizontal, vertical ou diagonal")
+	spokes := fs.Int("spokes", 36, "número de raios da estrela de Siemens")
+
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(rest) != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	width, height, err := parseSize(*size)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	var img *image.Gray
+	switch *kind {
+	case "checkerboard":
+		img = synthetic.Checkerboard(width, height, *square)
+	case "ramp":
+		img, err = synthetic.Ramp(width, height, *direction)
+	case "circles":
+		var circles []synthetic.Circle
+		img, circles = synthetic.Circles(width, height, synthetic.CircleOptions{
+			Count: *count, Radius: *radius, Overlap: *overlap, Seed: *seed,
+		})
+		if len(circles) < *count {
+			fmt.Printf("Aviso: só couberam %d de %d círculos\n", len(circles), *count)
+		}
+	case "squares":
+		var squares []image.Rectangle
+		img, squares = synthetic.Squares(width, height, *count, *square, *seed)
+		if len(squares) < *count {
+			fmt.Printf("Aviso: só couberam %d de %d quadrados\n", len(squares), *count)
+		}
+	case "noise":
+		img = synthetic.Noise(width, height, *mean, *sigma, *seed)
+	case "siemens-star":
+		img = synthetic.SiemensStar(width, height, *spokes)
+	default:
+		err = fmt.Errorf("tipo desconhecido: %q", *kind)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if err := saveImage(*out, img); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Imagem %s (%dx%d) salva em %s\n", *kind, width, height, *out)
+	return 0
+}
+
+// parseSize lê dimensões no formato "LARGURAxALTURA".
+func parseSize(s string) (int, int, error) {
+	var width, height int
+	if _, err := fmt.Sscanf(s, "%dx%d", &width, &height); err != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("tamanho inválido %q, use LxA (ex: 512x512)", s)
+	}
+	return width, height, nil
+}
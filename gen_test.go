@@ -0,0 +1,65 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRunGen(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name          string
+		args          []string
+		code          int
+		width, height int
+	}{
+		{"checkerboard", []string{"-kind", "checkerboard", "-size", "64x32"}, 0, 64, 32},
+		{"ramp", []string{"-kind", "ramp", "-dir", "vertical", "-size", "20x40"}, 0, 20, 40},
+		{"circles", []string{"-kind", "circles", "-count", "3", "-radius", "5", "-size", "80x80"}, 0, 80, 80},
+		{"squares", []string{"-kind", "squares", "-count", "3", "-square", "5", "-size", "80x80"}, 0, 80, 80},
+		{"noise", []string{"-kind", "noise", "-size", "16x16"}, 0, 16, 16},
+		{"siemens-star", []string{"-kind", "siemens-star", "-size", "50x50"}, 0, 50, 50},
+		{"tipo desconhecido", []string{"-kind", "mandelbrot"}, 2, 0, 0},
+		{"tamanho inválido", []string{"-size", "512"}, 2, 0, 0},
+		{"direção inválida", []string{"-kind", "ramp", "-dir", "radial"}, 2, 0, 0},
+		{"argumento sobrando", []string{"extra.png"}, 2, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(dir, tt.name+".png")
+			if code := runGen(append(tt.args, "-out", out)); code != tt.code {
+				t.Fatalf("código de saída %d, esperado %d", code, tt.code)
+			}
+			if tt.code != 0 {
+				return
+			}
+			img, err := loadImage(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := img.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+				t.Errorf("imagem %dx%d, esperado %dx%d", b.Dx(), b.Dy(), tt.width, tt.height)
+			}
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in            string
+		width, height int
+		ok            bool
+	}{
+		{"512x512", 512, 512, true},
+		{"640x480", 640, 480, true},
+		{"0x10", 0, 0, false},
+		{"10x-1", 0, 0, false},
+		{"abc", 0, 0, false},
+	}
+	for _, tt := range tests {
+		w, h, err := parseSize(tt.in)
+		if (err == nil) != tt.ok || w != tt.width || h != tt.height {
+			t.Errorf("parseSize(%q) = %d, %d, %v", tt.in, w, h, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+)
+
+// flipH espelha a imagem na horizontal (a esquerda vira a direita).
+func flipH(img *image.Gray) *image.Gray {
+	return applyOrientation(img, 2)
+}
+
+// flipV espelha a imagem na vertical (o topo vira a base).
+func flipV(img *image.Gray) *image.Gray {
+	return applyOrientation(img, 4)
+}
+
+// crop copia o retângulo rect, nas coordenadas da própria imagem (que não
+// precisam começar em 0,0, como as de um SubImage), para uma imagem nova
+// com origem em 0,0. rect deve estar inteiro dentro da imagem (um
+// retângulo vazio dá uma imagem vazia).
+func crop(img *image.Gray, rect image.Rectangle) (*image.Gray, error) {
+	if !rect.In(img.Bounds()) {
+		return nil, fmt.Errorf("o recorte %v não cabe na imagem %v", rect, img.Bounds())
+	}
+	result := image.NewGray(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	for y := 0; y < rect.Dy(); y++ {
+		start := img.PixOffset(rect.Min.X, rect.Min.Y+y)
+		copy(result.Pix[y*result.Stride:y*result.Stride+rect.Dx()], img.Pix[start:start+rect.Dx()])
+	}
+	return result, nil
+}
+
+// translate desloca o conteúdo dx pixels para a direita e dy para baixo,
+// mantendo o tamanho: o que sai da tela se perde e o que fica descoberto
+// recebe fill.
+func translate(img *image.Gray, dx, dy int, fill uint8) *image.Gray {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range result.Pix {
+		result.Pix[i] = fill
+	}
+	// colunas de destino que recebem algum pixel
+	x0, x1 := max(0, dx), min(width, width+dx)
+	if x0 >= x1 {
+		return result
+	}
+	for y := max(0, dy); y < min(height, height+dy); y++ {
+		src := img.PixOffset(b.Min.X+x0-dx, b.Min.Y+y-dy)
+		copy(result.Pix[y*result.Stride+x0:y*result.Stride+x1], img.Pix[src:src+x1-x0])
+	}
+	return result
+}
+
+// cropImage e flipImage valem também para imagens coloridas, canal a
+// canal, como rotateImage.
+
+func cropImage(img image.Image, rect image.Rectangle) (image.Image, error) {
+	if !rect.In(img.Bounds()) {
+		return nil, fmt.Errorf("o recorte %v não cabe na imagem %v", rect, img.Bounds())
+	}
+	// splitChannels leva a origem para 0,0, então o recorte vai junto
+	// (o de uma imagem em cinza, que chega como está, não)
+	if _, ok := img.(*image.Gray); !ok {
+		rect = rect.Sub(img.Bounds().Min)
+	}
+	return perChannel(img, func(gray *image.Gray) *image.Gray {
+		result, _ := crop(gray, rect)
+		return result
+	}), nil
+}
+
+func flipImage(img image.Image, horizontal bool) image.Image {
+	if horizontal {
+		return perChannel(img, flipH)
+	}
+	return perChannel(img, flipV)
+}
+
+// parseCrop lê o recorte de -crop, "x,y,largura,altura".
+func parseCrop(s string) (image.Rectangle, error) {
+	fields := strings.Split(s, ",")
+	if len(fields) != 4 {
+		return image.Rectangle{}, fmt.Errorf("recorte inválido %q, use x,y,largura,altura", s)
+	}
+	var v [4]int
+	for i, field := range fields {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return image.Rectangle{}, fmt.Errorf("recorte inválido %q, use x,y,largura,altura", s)
+		}
+		v[i] = n
+	}
+	if v[2] <= 0 || v[3] <= 0 {
+		return image.Rectangle{}, fmt.Errorf("o recorte %q precisa de largura e altura positivas", s)
+	}
+	return image.Rect(v[0], v[1], v[0]+v[2], v[1]+v[3]), nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFlip(t *testing.T) {
+	img := randomImage(7, 4, 1)
+	sub := randomImage(20, 20, 2).SubImage(image.Rect(5, 6, 12, 10)).(*image.Gray)
+	for _, src := range []*image.Gray{img, sub} {
+		b := src.Bounds()
+		h, v := flipH(src), flipV(src)
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				want := src.GrayAt(b.Min.X+x, b.Min.Y+y)
+				if h.GrayAt(b.Dx()-1-x, y) != want || v.GrayAt(x, b.Dy()-1-y) != want {
+					t.Fatalf("origem %v: pixel %d,%d espelhado errado", b.Min, x, y)
+				}
+			}
+		}
+		// espelhar duas vezes volta ao original
+		if !sameGray(flipH(flipH(src)), src) || !sameGray(flipV(flipV(src)), src) {
+			t.Errorf("origem %v: espelhar duas vezes não volta", b.Min)
+		}
+	}
+}
+
+func TestCropSubImage(t *testing.T) {
+	// regressão: o recorte usa as coordenadas da própria imagem, que num
+	// SubImage não começam em 0,0
+	full := randomImage(30, 20, 3)
+	sub := full.SubImage(image.Rect(10, 5, 25, 18)).(*image.Gray)
+	tests := []struct {
+		name string
+		img  *image.Gray
+		rect image.Rectangle
+		ok   bool
+	}{
+		{"inteira", full, image.Rect(0, 0, 30, 20), true},
+		{"meio", full, image.Rect(4, 3, 9, 11), true},
+		{"recorte do SubImage", sub, image.Rect(12, 7, 20, 15), true},
+		{"SubImage inteiro", sub, sub.Bounds(), true},
+		{"vazio", full, image.Rect(3, 3, 3, 3), true},
+		{"fora do SubImage", sub, image.Rect(0, 0, 5, 5), false},
+		{"passa da borda", full, image.Rect(25, 15, 31, 20), false},
+		{"negativo", full, image.Rect(-1, 0, 5, 5), false},
+	}
+	for _, tt := range tests {
+		got, err := crop(tt.img, tt.rect)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: erro %v", tt.name, err)
+			continue
+		}
+		if !tt.ok {
+			continue
+		}
+		if got.Bounds() != image.Rect(0, 0, tt.rect.Dx(), tt.rect.Dy()) {
+			t.Errorf("%s: limites %v", tt.name, got.Bounds())
+			continue
+		}
+		if !sameGray(got, full.SubImage(tt.rect).(*image.Gray)) {
+			t.Errorf("%s: pixels diferentes dos da imagem original", tt.name)
+		}
+	}
+}
+
+func TestCropImageColor(t *testing.T) {
+	rgba := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	rgba.Set(6, 7, color.RGBA{0, 200, 0, 255})
+	sub := rgba.SubImage(image.Rect(4, 4, 10, 10))
+	got, err := cropImage(sub, image.Rect(5, 6, 8, 9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Bounds() != image.Rect(0, 0, 3, 3) {
+		t.Fatalf("limites %v", got.Bounds())
+	}
+	if _, g, _, _ := got.At(1, 1).RGBA(); g>>8 != 200 {
+		t.Errorf("pixel verde foi para o lugar errado")
+	}
+	if _, err := cropImage(sub, image.Rect(0, 0, 3, 3)); err == nil {
+		t.Error("recorte fora do SubImage colorido: sem erro")
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	img := randomImage(8, 6, 4)
+	sub := randomImage(20, 20, 5).SubImage(image.Rect(3, 7, 11, 13)).(*image.Gray)
+	tests := []struct {
+		dx, dy int
+	}{
+		{0, 0}, {2, 1}, {-3, 2}, {1, -4}, {8, 0}, {0, -6}, {-20, 3},
+	}
+	for _, src := range []*image.Gray{img, sub} {
+		b := src.Bounds()
+		for _, tt := range tests {
+			got := translate(src, tt.dx, tt.dy, 33)
+			if got.Bounds() != image.Rect(0, 0, 8, 6) {
+				t.Fatalf("desloca %d,%d: limites %v", tt.dx, tt.dy, got.Bounds())
+			}
+			for y := 0; y < 6; y++ {
+				for x := 0; x < 8; x++ {
+					want := uint8(33)
+					if sx, sy := x-tt.dx, y-tt.dy; sx >= 0 && sx < 8 && sy >= 0 && sy < 6 {
+						want = src.GrayAt(b.Min.X+sx, b.Min.Y+sy).Y
+					}
+					if got.GrayAt(x, y).Y != want {
+						t.Fatalf("origem %v, desloca %d,%d: pixel %d,%d vale %d, esperado %d", b.Min, tt.dx, tt.dy, x, y, got.GrayAt(x, y).Y, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestGrayscaleMovesOrigin(t *testing.T) {
+	full := randomImage(12, 9, 6)
+	sub := full.SubImage(image.Rect(4, 2, 10, 8)).(*image.Gray)
+	got := grayscale(sub)
+	if got.Bounds() != image.Rect(0, 0, 6, 6) || !sameGray(got, sub) {
+		t.Errorf("SubImage em cinza com limites %v ou pixels errados", got.Bounds())
+	}
+	if grayscale(full) != full {
+		t.Error("imagem em cinza com origem 0,0 foi copiada")
+	}
+}
+
+func TestParseCrop(t *testing.T) {
+	tests := []struct {
+		in   string
+		want image.Rectangle
+		ok   bool
+	}{
+		{"10,20,30,40", image.Rect(10, 20, 40, 60), true},
+		{" 0, 0, 5, 5", image.Rect(0, 0, 5, 5), true},
+		{"10,20,0,40", image.Rectangle{}, false},
+		{"10,20,30", image.Rectangle{}, false},
+		{"a,20,30,40", image.Rectangle{}, false},
+		{"10,20,-3,40", image.Rectangle{}, false},
+	}
+	for _, tt := range tests {
+		got, err := parseCrop(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%q: %v, %v", tt.in, got, err)
+		}
+	}
+}
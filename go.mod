@@ -1,5 +1,3 @@
 module processing-images
 
 go 1.22.2
-
-require gocv.io/x/gocv v0.40.0
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"processing-images/imaging"
+)
+
+// guidedFilter é o filtro guiado de He et al.: em cada janela de raio radius
+// a saída é um modelo linear da guia, q = a·I + b, com a = cov(I, p) /
+// (var(I) + eps) e b = média(p) − a·média(I). Todas as médias são filtros
+// de caixa por tabela de somas, então o custo é O(N) para qualquer raio.
+// As intensidades são levadas para [0, 1], de modo que eps é comparável a
+// uma variância nessa escala (ex: 0.01 = desvio de ~25 níveis).
+func guidedFilter(p, guide *image.Gray, radius int, eps float64) *image.Gray {
+	guideF := imaging.GrayToFloat(guide)
+	input := imaging.GrayToFloat(p)
+	height := min(len(guideF), len(input))
+	width := 0
+	if height > 0 {
+		width = min(len(guideF[0]), len(input[0]))
+	}
+
+	ii := make([][]float64, height)
+	pp := make([][]float64, height)
+	iI := make([][]float64, height)
+	iP := make([][]float64, height)
+	for y := 0; y < height; y++ {
+		ii[y] = make([]float64, width)
+		pp[y] = make([]float64, width)
+		iI[y] = make([]float64, width)
+		iP[y] = make([]float64, width)
+		for x := 0; x < width; x++ {
+			gv, pv := guideF[y][x]/255, input[y][x]/255
+			ii[y][x], pp[y][x] = gv, pv
+			iI[y][x], iP[y][x] = gv*gv, gv*pv
+		}
+	}
+
+	meanI := boxMeanFloat(ii, radius)
+	meanP := boxMeanFloat(pp, radius)
+	corrI := boxMeanFloat(iI, radius)
+	corrIP := boxMeanFloat(iP, radius)
+
+	a := make([][]float64, height)
+	b := make([][]float64, height)
+	for y := 0; y < height; y++ {
+		a[y] = make([]float64, width)
+		b[y] = make([]float64, width)
+		for x := 0; x < width; x++ {
+			varI := corrI[y][x] - meanI[y][x]*meanI[y][x]
+			covIP := corrIP[y][x] - meanI[y][x]*meanP[y][x]
+			a[y][x] = covIP / (varI + eps)
+			b[y][x] = meanP[y][x] - a[y][x]*meanI[y][x]
+		}
+	}
+
+	meanA := boxMeanFloat(a, radius)
+	meanB := boxMeanFloat(b, radius)
+
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			q := (meanA[y][x]*ii[y][x] + meanB[y][x]) * 255
+			result.SetGray(x, y, color.Gray{uint8(math.Max(0, math.Min(255, math.Round(q))))})
+		}
+	}
+	return result
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// stepImage é 64x64 com 30 à esquerda da coluna edge e 220 dela em diante.
+func stepImage(edge int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.Pix[y*img.Stride+x] = 30
+			if x >= edge {
+				img.Pix[y*img.Stride+x] = 220
+			}
+		}
+	}
+	return img
+}
+
+// regionStd é o desvio padrão dos pixels do retângulo.
+func regionStd(img *image.Gray, r image.Rectangle) float64 {
+	var sum, sumSq, n float64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			v := float64(img.Pix[y*img.Stride+x])
+			sum += v
+			sumSq += v * v
+			n++
+		}
+	}
+	mean := sum / n
+	return math.Sqrt(sumSq/n - mean*mean)
+}
+
+func TestGuidedFilterSelfGuided(t *testing.T) {
+	noisy, err := synthetic.AddGaussianNoise(stepImage(32), 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := guidedFilter(noisy, noisy, 4, 0.01)
+
+	flat := image.Rect(4, 4, 24, 60)
+	if before, after := regionStd(noisy, flat), regionStd(result, flat); after > before/2 {
+		t.Errorf("desvio na região lisa foi de %.2f para %.2f, esperado cair pela metade", before, after)
+	}
+	// o degrau continua com um pixel de transição
+	for y := 8; y < 56; y++ {
+		left, right := result.Pix[y*result.Stride+30], result.Pix[y*result.Stride+33]
+		if int(right)-int(left) < 150 {
+			t.Fatalf("degrau na linha %d caiu para %d..%d", y, left, right)
+		}
+	}
+}
+
+func TestGuidedFilterSnapsMask(t *testing.T) {
+	guide := stepImage(32)
+	tests := []struct {
+		name string
+		mask int // coluna da borda da máscara
+	}{
+		{"máscara à esquerda", 29},
+		{"máscara à direita", 35},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mask := image.NewGray(image.Rect(0, 0, 64, 64))
+			for y := 0; y < 64; y++ {
+				for x := tt.mask; x < 64; x++ {
+					mask.Pix[y*mask.Stride+x] = 255
+				}
+			}
+			result := guidedFilter(mask, guide, 8, 1e-4)
+			// a borda da máscara filtrada, limiarizada em 128, cai na borda da guia
+			for y := 0; y < 64; y++ {
+				edge := 0
+				for edge < 64 && result.Pix[y*result.Stride+edge] < 128 {
+					edge++
+				}
+				if edge != 32 {
+					t.Fatalf("linha %d: borda na coluna %d, esperado 32", y, edge)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"image"
+	"math"
+)
+
+// halftone imita a retícula de impressão: a imagem é dividida numa grade de
+// células de cellSize pixels girada de angle graus e cada célula recebe um
+// ponto preto cuja área é proporcional ao quanto a célula é escura
+// (r = cellSize·√(escuridão/π)) até o ponto encostar nas vizinhas; a partir
+// daí o raio cresce até cellSize/√2, que cobre a célula inteira no preto puro.
+// Branco puro não gera pontos.
+func halftone(img *image.Gray, cellSize int, angle float64) *image.Gray {
+	if cellSize < 1 {
+		cellSize = 1
+	}
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range result.Pix {
+		result.Pix[i] = 255
+	}
+	if width == 0 || height == 0 {
+		return result
+	}
+
+	theta := angle * math.Pi / 180
+	cos, sin := math.Cos(theta), math.Sin(theta)
+	cell := float64(cellSize)
+
+	// coordenadas (u, v) na grade girada
+	toGrid := func(x, y float64) (float64, float64) {
+		return x*cos + y*sin, -x*sin + y*cos
+	}
+	fromGrid := func(u, v float64) (float64, float64) {
+		return u*cos - v*sin, u*sin + v*cos
+	}
+
+	minU, minV := math.Inf(1), math.Inf(1)
+	maxU, maxV := math.Inf(-1), math.Inf(-1)
+	for _, corner := range [][2]float64{{0, 0}, {float64(width), 0}, {0, float64(height)}, {float64(width), float64(height)}} {
+		u, v := toGrid(corner[0], corner[1])
+		minU, maxU = math.Min(minU, u), math.Max(maxU, u)
+		minV, maxV = math.Min(minV, v), math.Max(maxV, v)
+	}
+	i0, j0 := int(math.Floor(minU/cell)), int(math.Floor(minV/cell))
+	cols := int(math.Floor(maxU/cell)) - i0 + 1
+	rows := int(math.Floor(maxV/cell)) - j0 + 1
+
+	sums := make([]float64, cols*rows)
+	counts := make([]int, cols*rows)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			u, v := toGrid(float64(x)+0.5, float64(y)+0.5)
+			c := (int(math.Floor(v/cell))-j0)*cols + int(math.Floor(u/cell)) - i0
+			sums[c] += float64(img.Pix[y*img.Stride+x])
+			counts[c]++
+		}
+	}
+
+	for j := 0; j < rows; j++ {
+		for i := 0; i < cols; i++ {
+			c := j*cols + i
+			if counts[c] == 0 {
+				continue
+			}
+			darkness := 1 - sums[c]/float64(counts[c])/255
+			if darkness <= 0 {
+				continue
+			}
+			r := cell * math.Sqrt(darkness/math.Pi)
+			if darkness > math.Pi/4 {
+				// o ponto já encosta nas vizinhas: cresce até cobrir os cantos
+				t := (darkness - math.Pi/4) / (1 - math.Pi/4)
+				r = cell * (0.5 + t*(1/math.Sqrt2-0.5))
+			}
+			cx, cy := fromGrid((float64(i0+i)+0.5)*cell, (float64(j0+j)+0.5)*cell)
+			// o centro do pixel (x, y) está em (x+0.5, y+0.5)
+			fillCircleGray(result, cx-0.5, cy-0.5, r, 0)
+		}
+	}
+
+	return result
+}
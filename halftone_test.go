@@ -0,0 +1,74 @@
+package main
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// uniformGray é uma imagem size x size toda com o valor v.
+func uniformGray(size int, v uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for i := range img.Pix {
+		img.Pix[i] = v
+	}
+	return img
+}
+
+// coverage é a fração de pixels pretos.
+func coverage(img *image.Gray) float64 {
+	black := 0
+	for _, v := range img.Pix {
+		if v == 0 {
+			black++
+		}
+	}
+	return float64(black) / float64(len(img.Pix))
+}
+
+func TestHalftoneCoverage(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    uint8
+		angle    float64
+		min, max float64
+	}{
+		{"cinza 50%", 128, 0, 0.47, 0.53},
+		{"cinza 50% girado", 128, 45, 0.47, 0.53},
+		{"cinza 50% a 15°", 128, 15, 0.47, 0.53},
+		{"branco", 255, 45, 0, 0},
+		{"preto", 0, 0, 1, 1},
+		{"preto girado", 0, 45, 1, 1},
+	}
+	for _, tt := range tests {
+		c := coverage(halftone(uniformGray(128, tt.value), 8, tt.angle))
+		if c < tt.min || c > tt.max {
+			t.Errorf("%s: cobertura %.3f, esperado entre %.2f e %.2f", tt.name, c, tt.min, tt.max)
+		}
+	}
+}
+
+func TestHalftoneRampMean(t *testing.T) {
+	ramp, err := synthetic.Ramp(256, 64, "horizontal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := halftone(ramp, 8, 45)
+	// faixas verticais de 32 pixels; no ângulo padrão de 45° as células não
+	// se alinham aos pixels e a quantização do raio dos pontos se compensa
+	for x0 := 0; x0 < 256; x0 += 32 {
+		var in, out float64
+		for y := 0; y < 64; y++ {
+			for x := x0; x < x0+32; x++ {
+				in += float64(ramp.Pix[y*ramp.Stride+x])
+				out += float64(result.Pix[y*result.Stride+x])
+			}
+		}
+		n := float64(32 * 64)
+		if d := math.Abs(in-out) / n; d > 0.05*255 {
+			t.Errorf("faixa em x=%d: média %.1f, esperado %.1f ± 5%%", x0, out/n, in/n)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"math/bits"
+	"os"
+	"sort"
+)
+
+// Hashes perceptuais de 64 bits: imagens parecidas têm hashes a poucos bits
+// de distância. Os bits são gravados linha a linha, do mais significativo
+// para o menos.
+
+// averageHash (aHash): reduz a 8x8 e marca os pixels acima da média.
+func averageHash(img *image.Gray) uint64 {
+	small := resizeArea(img, 8, 8)
+	var sum float64
+	for _, v := range small.Pix {
+		sum += float64(v)
+	}
+	mean := sum / 64
+
+	var hash uint64
+	for _, v := range small.Pix {
+		hash <<= 1
+		if float64(v) > mean {
+			hash |= 1
+		}
+	}
+	return hash
+}
+
+// differenceHash (dHash): reduz a 9x8 e marca onde o brilho cai da esquerda
+// para a direita. Só depende do gradiente, então resiste a mudanças de
+// brilho.
+func differenceHash(img *image.Gray) uint64 {
+	small := resizeArea(img, 9, 8)
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		row := small.Pix[y*small.Stride:]
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if row[x] > row[x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// perceptualHash (pHash): reduz a 32x32, aplica a DCT e compara as 8x8
+// frequências mais baixas com a mediana delas (sem o termo DC, que só mede
+// o brilho médio).
+func perceptualHash(img *image.Gray) uint64 {
+	const n = 32
+	small := resizeArea(img, n, n)
+	field := make([][]float64, n)
+	for y := range field {
+		field[y] = make([]float64, n)
+		for x := range field[y] {
+			field[y][x] = float64(small.Pix[y*small.Stride+x])
+		}
+	}
+	coeffs := dct2D(field)
+
+	low := make([]float64, 0, 64)
+	for y := 0; y < 8; y++ {
+		low = append(low, coeffs[y][:8]...)
+	}
+	sorted := append([]float64(nil), low[1:]...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+
+	var hash uint64
+	for _, c := range low {
+		hash <<= 1
+		if c > median {
+			hash |= 1
+		}
+	}
+	return hash
+}
+
+// dct2D calcula a DCT-II bidimensional (sem normalização) de um campo
+// quadrado, linhas e depois colunas. É O(n³), suficiente para 32x32.
+func dct2D(field [][]float64) [][]float64 {
+	n := len(field)
+	cos := make([][]float64, n)
+	for k := range cos {
+		cos[k] = make([]float64, n)
+		for i := range cos[k] {
+			cos[k][i] = math.Cos(math.Pi / float64(n) * (float64(i) + 0.5) * float64(k))
+		}
+	}
+
+	rows := make([][]float64, n)
+	for y := range rows {
+		rows[y] = make([]float64, n)
+		for k := 0; k < n; k++ {
+			var sum float64
+			for x := 0; x < n; x++ {
+				sum += field[y][x] * cos[k][x]
+			}
+			rows[y][k] = sum
+		}
+	}
+
+	result := make([][]float64, n)
+	for k := range result {
+		result[k] = make([]float64, n)
+		for x := 0; x < n; x++ {
+			var sum float64
+			for y := 0; y < n; y++ {
+				sum += rows[y][x] * cos[k][y]
+			}
+			result[k][x] = sum
+		}
+	}
+	return result
+}
+
+// hammingDistance conta os bits diferentes entre dois hashes.
+func hammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// hashMethods são os hashes aceitos por -method.
+var hashMethods = map[string]func(*image.Gray) uint64{
+	"ahash": averageHash,
+	"dhash": differenceHash,
+	"phash": perceptualHash,
+}
+
+// gotoshop hash [-method ahash|dhash|phash|all] arquivo...
+func runHash(args []string) int {
+	fs := newFlagSet("hash", "[-method all] arquivo...")
+	method := fs.String("method", "all", "ahash, dhash, phash ou all")
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	names := []string{"ahash", "dhash", "phash"}
+	if *method != "all" {
+		if _, ok := hashMethods[*method]; !ok {
+			fmt.Fprintf(os.Stderr, "método desconhecido %q, use ahash, dhash, phash ou all\n", *method)
+			return 2
+		}
+		names = []string{*method}
+	}
+
+	status := 0
+	for _, file := range files {
+		img, err := loadImage(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			status = 1
+			continue
+		}
+		fmt.Print(file)
+		for _, name := range names {
+			fmt.Printf(" %s:%016x", name, hashMethods[name](img))
+		}
+		fmt.Println()
+	}
+	return status
+}
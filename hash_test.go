@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"path/filepath"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// hashScene é uma cena com discos e quadrados de tamanhos variados.
+func hashScene(seed int64) *image.Gray {
+	img, _ := synthetic.Squares(128, 128, 6, 18, seed)
+	synthetic.FillCircle(img, image.Pt(40+int(seed%3)*20, 70), 18, 120)
+	return img
+}
+
+func jpegCopy(t *testing.T, img *image.Gray, quality int) *image.Gray {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := jpeg.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grayscale(decoded)
+}
+
+func brighter(img *image.Gray, delta int) *image.Gray {
+	result := image.NewGray(img.Bounds())
+	for i, v := range img.Pix {
+		result.Pix[i] = uint8(min(255, int(v)+delta))
+	}
+	return result
+}
+
+func TestPerceptualHashes(t *testing.T) {
+	original := hashScene(1)
+	recompressed := jpegCopy(t, original, 70)
+	shifted := brighter(original, 30)
+	unrelated := synthetic.SiemensStar(128, 128, 16)
+
+	tests := []struct {
+		method        string
+		maxJPEG       int
+		maxBrightness int // -1 quando o hash não promete resistir
+	}{
+		{"ahash", 5, -1},
+		{"dhash", 5, 5},
+		{"phash", 5, 5},
+	}
+	for _, tt := range tests {
+		hash := hashMethods[tt.method]
+		h := hash(original)
+		if d := hammingDistance(h, hash(recompressed)); d > tt.maxJPEG {
+			t.Errorf("%s: distância %d para a cópia em JPEG, esperado <= %d", tt.method, d, tt.maxJPEG)
+		}
+		if d := hammingDistance(h, hash(shifted)); tt.maxBrightness >= 0 && d > tt.maxBrightness {
+			t.Errorf("%s: distância %d para a cópia mais clara, esperado <= %d", tt.method, d, tt.maxBrightness)
+		}
+		if d := hammingDistance(h, hash(unrelated)); d < 20 {
+			t.Errorf("%s: distância %d para uma imagem sem relação, esperado >= 20", tt.method, d)
+		}
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	tests := []struct {
+		a, b uint64
+		want int
+	}{
+		{0, 0, 0},
+		{0, ^uint64(0), 64},
+		{0xF0, 0x0F, 8},
+		{1 << 63, 1, 2},
+	}
+	for _, tt := range tests {
+		if got := hammingDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("hammingDistance(%x, %x) = %d, esperado %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRunHash(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.png")
+	if err := saveImage(path, hashScene(1)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"todos", []string{path}, 0},
+		{"um método", []string{"-method", "dhash", path}, 0},
+		{"método desconhecido", []string{"-method", "xhash", path}, 2},
+		{"sem arquivos", nil, 2},
+		{"arquivo que não existe", []string{path, filepath.Join(dir, "nada.png")}, 1},
+	}
+	for _, tt := range tests {
+		if code := runHash(tt.args); code != tt.code {
+			t.Errorf("%s: código de saída %d, esperado %d", tt.name, code, tt.code)
+		}
+	}
+}
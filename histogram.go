@@ -0,0 +1,58 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// computeHistogramRGB conta os níveis de cada canal (R, G, B) separadamente.
+func computeHistogramRGB(img *image.RGBA) [3][256]int {
+	var histograms [3][256]int
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(b.Min.X, y):img.PixOffset(b.Max.X, y)]
+		for i := 0; i < len(row); i += 4 {
+			histograms[0][row[i]]++
+			histograms[1][row[i+1]]++
+			histograms[2][row[i+2]]++
+		}
+	}
+	return histograms
+}
+
+// histogramPlot desenha os histogramas num gráfico 256 x height, uma coluna
+// por nível, com a altura proporcional ao maior valor de todos. Cada
+// histograma é pintado na sua cor com transparência, somando as cores onde
+// as barras se sobrepõem (vermelho + verde = amarelo, os três = branco).
+func histogramPlot(histograms [][256]int, colors []color.RGBA, height int) *image.RGBA {
+	result := image.NewRGBA(image.Rect(0, 0, 256, height))
+	for i := 3; i < len(result.Pix); i += 4 {
+		result.Pix[i] = 255
+	}
+
+	peak := 0
+	for _, h := range histograms {
+		for _, c := range h {
+			peak = max(peak, c)
+		}
+	}
+	if peak == 0 {
+		return result
+	}
+
+	for i, h := range histograms {
+		c := colors[i]
+		for v, count := range h {
+			bar := int(math.Round(float64(count) / float64(peak) * float64(height)))
+			for y := height - bar; y < height; y++ {
+				p := result.Pix[result.PixOffset(v, y):]
+				// mistura aditiva com alfa da cor
+				p[0] = uint8(min(int(p[0])+int(c.R)*int(c.A)/255, 255))
+				p[1] = uint8(min(int(p[1])+int(c.G)*int(c.A)/255, 255))
+				p[2] = uint8(min(int(p[2])+int(c.B)*int(c.A)/255, 255))
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+// solidRGBA é uma imagem w x h toda na cor c.
+func solidRGBA(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestComputeHistogramRGB(t *testing.T) {
+	tests := []struct {
+		name string
+		c    color.RGBA
+	}{
+		{"vermelho", color.RGBA{255, 0, 0, 255}},
+		{"verde", color.RGBA{0, 255, 0, 255}},
+		{"cinza médio", color.RGBA{128, 128, 128, 255}},
+		{"misto", color.RGBA{10, 200, 77, 255}},
+	}
+	for _, tt := range tests {
+		h := computeHistogramRGB(solidRGBA(6, 5, tt.c))
+		for ch, v := range []uint8{tt.c.R, tt.c.G, tt.c.B} {
+			if h[ch][v] != 30 {
+				t.Errorf("%s: canal %d tem %d pixels em %d, esperado 30", tt.name, ch, h[ch][v], v)
+			}
+		}
+	}
+
+	// SubImage conta só a região
+	sub := solidRGBA(10, 10, color.RGBA{255, 0, 0, 255}).SubImage(image.Rect(2, 3, 5, 7)).(*image.RGBA)
+	if h := computeHistogramRGB(sub); h[0][255] != 12 || h[1][0] != 12 {
+		t.Errorf("SubImage: %d e %d pixels, esperado 12", h[0][255], h[1][0])
+	}
+}
+
+func TestHistogramPlotRed(t *testing.T) {
+	plot := statsPlot(solidRGBA(8, 8, color.RGBA{255, 0, 0, 255}))
+	if b := plot.Bounds(); b.Dx() != 256 || b.Dy() != 200 {
+		t.Fatalf("gráfico %v, esperado 256x200", b)
+	}
+	// todo o vermelho está em 255 e todo o verde e o azul em 0; com 50% de
+	// opacidade as barras ficam em meia intensidade
+	tests := []struct {
+		x    int
+		want color.RGBA
+	}{
+		{255, color.RGBA{128, 0, 0, 255}},
+		{0, color.RGBA{0, 128, 128, 255}},
+		{1, color.RGBA{0, 0, 0, 255}},
+		{128, color.RGBA{0, 0, 0, 255}},
+		{254, color.RGBA{0, 0, 0, 255}},
+	}
+	for _, tt := range tests {
+		for _, y := range []int{0, 100, 199} {
+			if c := plot.RGBAAt(tt.x, y); c != tt.want {
+				t.Errorf("coluna %d, linha %d: %v, esperado %v", tt.x, y, c, tt.want)
+			}
+		}
+	}
+}
+
+func TestHistogramPlotScale(t *testing.T) {
+	var h [256]int
+	h[10], h[20] = 100, 50
+	plot := histogramPlot([][256]int{h}, []color.RGBA{{255, 255, 255, 255}}, 100)
+	tests := []struct{ x, height int }{{10, 100}, {20, 50}, {30, 0}}
+	for _, tt := range tests {
+		height := 0
+		for y := 0; y < 100; y++ {
+			if plot.RGBAAt(tt.x, y).R == 255 {
+				height++
+			}
+		}
+		if height != tt.height {
+			t.Errorf("coluna %d: barra de %d, esperado %d", tt.x, height, tt.height)
+		}
+	}
+}
+
+func TestChannelOtsu(t *testing.T) {
+	// metade esquerda (200, 40, 90), metade direita (20, 40, 250)
+	img := solidRGBA(20, 10, color.RGBA{200, 40, 90, 255})
+	for y := 0; y < 10; y++ {
+		for x := 10; x < 20; x++ {
+			img.SetRGBA(x, y, color.RGBA{20, 40, 250, 255})
+		}
+	}
+	s := imageStats(img)
+	tests := []struct {
+		channel string
+		lo, hi  uint8
+	}{
+		{"red", 20, 200},
+		{"blue", 90, 250},
+	}
+	for _, tt := range tests {
+		if otsu := s.Channels[tt.channel].Otsu; otsu < tt.lo || otsu >= tt.hi {
+			t.Errorf("canal %s: limiar %d, esperado em [%d, %d)", tt.channel, otsu, tt.lo, tt.hi)
+		}
+	}
+}
+
+func TestRunStatsPlot(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "vermelho.png")
+	if err := saveImage(path, solidRGBA(8, 8, color.RGBA{255, 0, 0, 255})); err != nil {
+		t.Fatal(err)
+	}
+	plotPath := filepath.Join(dir, "hist.png")
+	if code := runStats([]string{"-plot", plotPath, path}); code != 0 {
+		t.Fatalf("código de saída %d", code)
+	}
+	plot, _, err := decodeImage(plotPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, g, b, _ := plot.At(255, 199).RGBA(); r == 0 || g != 0 || b != 0 {
+		t.Errorf("coluna 255 sem barra só vermelha: %v", plot.At(255, 199))
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"processing-images/imaging"
+)
+
+// hogCells calcula os histogramas de orientação por célula (cellSize x
+// cellSize pixels, só células inteiras). A orientação é sem sinal, em
+// [0, π), com o bin b centrado em b·π/bins, e cada pixel vota com a magnitude do gradiente, dividida
+// linearmente entre os dois bins mais próximos e bilinearmente entre as
+// quatro células cujos centros o cercam.
+func hogCells(img *image.Gray, cellSize, bins int) [][][]float64 {
+	mag, dir := imaging.SobelGradient(img)
+	cellsX, cellsY := img.Bounds().Dx()/cellSize, img.Bounds().Dy()/cellSize
+	cells := make([][][]float64, cellsY)
+	for cy := range cells {
+		cells[cy] = make([][]float64, cellsX)
+		for cx := range cells[cy] {
+			cells[cy][cx] = make([]float64, bins)
+		}
+	}
+	binWidth := math.Pi / float64(bins)
+
+	for y := 0; y < cellsY*cellSize; y++ {
+		fy := (float64(y)+0.5)/float64(cellSize) - 0.5
+		cy0 := int(math.Floor(fy))
+		wy := fy - float64(cy0)
+		for x := 0; x < cellsX*cellSize; x++ {
+			m := mag[y][x]
+			if m == 0 {
+				continue
+			}
+			theta := math.Mod(dir[y][x]+math.Pi, math.Pi)
+			fb := theta / binWidth
+			b0 := int(math.Floor(fb))
+			wb := fb - float64(b0)
+			b1 := (b0 + 1) % bins
+			b0 = (b0 + bins) % bins
+
+			fx := (float64(x)+0.5)/float64(cellSize) - 0.5
+			cx0 := int(math.Floor(fx))
+			wx := fx - float64(cx0)
+			for _, c := range [4]struct {
+				x, y int
+				w    float64
+			}{
+				{cx0, cy0, (1 - wx) * (1 - wy)},
+				{cx0 + 1, cy0, wx * (1 - wy)},
+				{cx0, cy0 + 1, (1 - wx) * wy},
+				{cx0 + 1, cy0 + 1, wx * wy},
+			} {
+				if c.x < 0 || c.y < 0 || c.x >= cellsX || c.y >= cellsY || c.w == 0 {
+					continue
+				}
+				hist := cells[c.y][c.x]
+				hist[b0] += m * c.w * (1 - wb)
+				hist[b1] += m * c.w * wb
+			}
+		}
+	}
+	return cells
+}
+
+// hog devolve o descritor HOG: blocos de blockSize x blockSize células,
+// deslizando de uma em uma célula, cada um normalizado por L2-Hys (L2,
+// corte em 0.2 e L2 de novo) e concatenados linha a linha. O tamanho é
+// (cellsX−blockSize+1)·(cellsY−blockSize+1)·blockSize²·bins.
+func hog(img *image.Gray, cellSize, blockSize, bins int) []float64 {
+	cells := hogCells(img, cellSize, bins)
+	var descriptor []float64
+	for by := 0; by+blockSize <= len(cells); by++ {
+		for bx := 0; len(cells) > 0 && bx+blockSize <= len(cells[0]); bx++ {
+			block := make([]float64, 0, blockSize*blockSize*bins)
+			for y := by; y < by+blockSize; y++ {
+				for x := bx; x < bx+blockSize; x++ {
+					block = append(block, cells[y][x]...)
+				}
+			}
+			normalizeL2(block)
+			for i, v := range block {
+				block[i] = math.Min(v, 0.2)
+			}
+			normalizeL2(block)
+			descriptor = append(descriptor, block...)
+		}
+	}
+	return descriptor
+}
+
+// normalizeL2 divide o vetor pela sua norma L2 (com ε para blocos vazios).
+func normalizeL2(v []float64) {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	norm := math.Sqrt(sum + 1e-12)
+	for i := range v {
+		v[i] /= norm
+	}
+}
+
+// hogVisualization desenha, em cada célula, um traço na direção da borda
+// do bin dominante (perpendicular ao gradiente), com brilho proporcional à
+// força do bin em relação à maior de todas.
+func hogVisualization(img *image.Gray, cellSize, bins int) *image.RGBA {
+	cells := hogCells(img, cellSize, bins)
+	result := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	for i := 3; i < len(result.Pix); i += 4 {
+		result.Pix[i] = 255
+	}
+
+	var strongest float64
+	for _, row := range cells {
+		for _, hist := range row {
+			for _, v := range hist {
+				strongest = math.Max(strongest, v)
+			}
+		}
+	}
+	if strongest == 0 {
+		return result
+	}
+
+	half := float64(cellSize-1) / 2
+	for cy, row := range cells {
+		for cx, hist := range row {
+			best := 0
+			for b, v := range hist {
+				if v > hist[best] {
+					best = b
+				}
+			}
+			v := uint8(255 * hist[best] / strongest)
+			if v == 0 {
+				continue
+			}
+			edge := float64(best)*math.Pi/float64(bins) + math.Pi/2
+			dx, dy := half*math.Cos(edge), half*math.Sin(edge)
+			centerX := float64(cx*cellSize) + half
+			centerY := float64(cy*cellSize) + half
+			drawLine(result,
+				int(math.Round(centerX-dx)), int(math.Round(centerY-dy)),
+				int(math.Round(centerX+dx)), int(math.Round(centerY+dy)),
+				color.RGBA{v, v, v, 255})
+		}
+	}
+	return result
+}
+
+// writeVectorCSV grava o vetor numa linha de valores separados por vírgula.
+func writeVectorCSV(path string, v []float64) error {
+	fields := make([]string, len(v))
+	for i, x := range v {
+		fields[i] = strconv.FormatFloat(x, 'g', 6, 64)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(fields, ",")+"\n"), 0o644); err != nil {
+		return fmt.Errorf("erro ao gravar %s: %w", path, err)
+	}
+	return nil
+}
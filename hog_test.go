@@ -0,0 +1,101 @@
+package main
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+func TestHOGLength(t *testing.T) {
+	tests := []struct {
+		width, height             int
+		cellSize, blockSize, bins int
+	}{
+		{64, 128, 8, 2, 9},
+		{64, 64, 8, 2, 9},
+		{70, 45, 8, 2, 9}, // sobras de célula são ignoradas
+		{96, 32, 16, 1, 6},
+		{40, 40, 4, 3, 12},
+		{8, 8, 8, 2, 9}, // uma célula só: nenhum bloco
+	}
+	for _, tt := range tests {
+		cellsX, cellsY := tt.width/tt.cellSize, tt.height/tt.cellSize
+		want := max(0, cellsX-tt.blockSize+1) * max(0, cellsY-tt.blockSize+1) * tt.blockSize * tt.blockSize * tt.bins
+		img := synthetic.Noise(tt.width, tt.height, 128, 40, 1)
+		if got := len(hog(img, tt.cellSize, tt.blockSize, tt.bins)); got != want {
+			t.Errorf("%dx%d, célula %d, bloco %d, %d bins: %d valores, esperado %d",
+				tt.width, tt.height, tt.cellSize, tt.blockSize, tt.bins, got, want)
+		}
+	}
+}
+
+func TestHOGStripes(t *testing.T) {
+	const bins = 9
+	tests := []struct {
+		name string
+		img  *image.Gray
+		want []int // bins que devem concentrar os votos
+	}{
+		// listras verticais: gradiente horizontal, orientação 0
+		{"verticais", stripes(64, 0), []int{0}},
+		// listras horizontais: gradiente vertical, orientação π/2, entre os
+		// bins 4 (80°) e 5 (100°)
+		{"horizontais", stripes(64, math.Pi/2), []int{4, 5}},
+	}
+	for _, tt := range tests {
+		cells := hogCells(tt.img, 8, bins)
+		var total [bins]float64
+		for _, row := range cells {
+			for _, hist := range row {
+				for b, v := range hist {
+					total[b] += v
+				}
+			}
+		}
+		var sum float64
+		for _, v := range total {
+			sum += v
+		}
+		var share float64
+		for _, b := range tt.want {
+			share += total[b]
+		}
+		if share/sum < 0.9 {
+			t.Errorf("listras %s: só %.0f%% dos votos nos bins %v: %v", tt.name, 100*share/sum, tt.want, total)
+		}
+	}
+}
+
+func TestHOGIntensityInvariance(t *testing.T) {
+	// valores pares até 254: metade e dobro são exatos em 8 bits
+	base := synthetic.Noise(64, 64, 128, 40, 2)
+	for i, v := range base.Pix {
+		base.Pix[i] = v &^ 1
+	}
+	half := image.NewGray(base.Bounds())
+	for i, v := range base.Pix {
+		half.Pix[i] = v / 2
+	}
+
+	a, b := hog(base, 8, 2, 9), hog(half, 8, 2, 9)
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9 {
+			t.Fatalf("descritor muda com a escala de intensidade: %v != %v na posição %d", a[i], b[i], i)
+		}
+	}
+}
+
+func TestNormalizeL2(t *testing.T) {
+	v := []float64{3, 4}
+	normalizeL2(v)
+	if math.Abs(v[0]-0.6) > 1e-9 || math.Abs(v[1]-0.8) > 1e-9 {
+		t.Errorf("normalizado para %v, esperado [0.6 0.8]", v)
+	}
+	zero := []float64{0, 0}
+	normalizeL2(zero)
+	if zero[0] != 0 || zero[1] != 0 {
+		t.Errorf("vetor nulo virou %v", zero)
+	}
+}
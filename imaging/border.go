@@ -0,0 +1,68 @@
+package imaging
+
+import "fmt"
+
+// BorderMode define como os filtros leem pixels fora da imagem.
+type BorderMode int
+
+const (
+	BorderReplicate BorderMode = iota // repete o pixel da borda: aaa|abcd|ddd
+	BorderReflect                     // espelha sem repetir a borda: cb|abcd|cb
+	BorderWrap                        // volta pelo outro lado: cd|abcd|ab
+	BorderZero                        // completa com zero
+)
+
+// ParseBorderMode lê "replicate", "reflect", "wrap" ou "zero".
+func ParseBorderMode(s string) (BorderMode, error) {
+	switch s {
+	case "replicate":
+		return BorderReplicate, nil
+	case "reflect":
+		return BorderReflect, nil
+	case "wrap":
+		return BorderWrap, nil
+	case "zero":
+		return BorderZero, nil
+	}
+	return 0, fmt.Errorf("modo de borda desconhecido %q, use replicate, reflect, wrap ou zero", s)
+}
+
+// index leva a coordenada i (talvez fora de [0, n)) para dentro da imagem;
+// ok é falso quando o pixel não existe (BorderZero).
+func (b BorderMode) index(i, n int) (idx int, ok bool) {
+	if i >= 0 && i < n {
+		return i, true
+	}
+	switch b {
+	case BorderReplicate:
+		return min(max(i, 0), n-1), true
+	case BorderReflect:
+		if n == 1 {
+			return 0, true
+		}
+		period := 2 * (n - 1)
+		i = ((i % period) + period) % period
+		if i >= n {
+			i = period - i
+		}
+		return i, true
+	case BorderWrap:
+		return ((i % n) + n) % n, true
+	}
+	return 0, false
+}
+
+// table devolve, para as coordenadas -before..n-1+after, o índice dentro da
+// imagem (ou -1 em BorderZero), para os laços internos não testarem a
+// borda a cada pixel: table[k] corresponde à coordenada k-before.
+func (b BorderMode) table(n, before, after int) []int {
+	t := make([]int, n+before+after)
+	for k := range t {
+		if idx, ok := b.index(k-before, n); ok {
+			t[k] = idx
+		} else {
+			t[k] = -1
+		}
+	}
+	return t
+}
@@ -0,0 +1,108 @@
+package imaging
+
+import (
+	"image"
+	"testing"
+)
+
+func TestBorderIndex(t *testing.T) {
+	// coordenadas −3..6 numa linha de 4 pixels (abcd)
+	tests := []struct {
+		mode BorderMode
+		want []int
+	}{
+		{BorderReplicate, []int{0, 0, 0, 0, 1, 2, 3, 3, 3, 3}},
+		{BorderReflect, []int{3, 2, 1, 0, 1, 2, 3, 2, 1, 0}},
+		{BorderWrap, []int{1, 2, 3, 0, 1, 2, 3, 0, 1, 2}},
+		{BorderZero, []int{-1, -1, -1, 0, 1, 2, 3, -1, -1, -1}},
+	}
+	for _, tt := range tests {
+		got := tt.mode.table(4, 3, 3)
+		for k := range tt.want {
+			if got[k] != tt.want[k] {
+				t.Errorf("modo %d: tabela %v, esperado %v", tt.mode, got, tt.want)
+				break
+			}
+		}
+	}
+	// uma linha de 1 pixel não pode refletir para fora dela
+	if idx, ok := BorderReflect.index(-2, 1); idx != 0 || !ok {
+		t.Errorf("reflect em n=1: %d, %v", idx, ok)
+	}
+}
+
+func TestConvolveOuterRing(t *testing.T) {
+	img := uniformGray(5, 90)
+	box := onesKernel(3, 3)
+	tests := []struct {
+		mode   BorderMode
+		corner uint8 // canto (0,0)
+		edge   uint8 // meio da borda de cima (2,0)
+	}{
+		{BorderReplicate, 90, 90},
+		{BorderReflect, 90, 90},
+		{BorderWrap, 90, 90},
+		// o zero entra na média: 4/9 no canto e 6/9 na borda
+		{BorderZero, 40, 60},
+	}
+	for _, tt := range tests {
+		got, err := Convolve(img, box, KernelNormalization{Mode: "auto"}, ResponseClamp, tt.mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c, e := got.GrayAt(0, 0).Y, got.GrayAt(2, 0).Y; c != tt.corner || e != tt.edge {
+			t.Errorf("modo %d: canto %d e borda %d, esperado %d e %d", tt.mode, c, e, tt.corner, tt.edge)
+		}
+		for i := 0; i < 5; i++ {
+			for _, p := range []image.Point{{i, 0}, {i, 4}, {0, i}, {4, i}} {
+				if got.GrayAt(p.X, p.Y).Y == 0 {
+					t.Errorf("modo %d: anel externo zerado em %v", tt.mode, p)
+				}
+			}
+		}
+	}
+}
+
+func TestFiltersAgreeOnBorders(t *testing.T) {
+	img := randomGray(9, 7, 4)
+	for _, mode := range []BorderMode{BorderReplicate, BorderReflect, BorderWrap, BorderZero} {
+		box, err := BoxFilter(img, 3, mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conv, err := Convolve(img, onesKernel(3, 3), KernelNormalization{Mode: "auto"}, ResponseClamp, mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range box.Pix {
+			if d := int(box.Pix[i]) - int(conv.Pix[i]); d < -1 || d > 1 {
+				t.Errorf("modo %d: pixel %d, box %d e convolução %d", mode, i, box.Pix[i], conv.Pix[i])
+				break
+			}
+		}
+
+		gx, _ := GradientComponents(GrayToFloat(img), Sobel, mode)
+		sobel, err := ConvolveFloat(img, [][]float64{{-1, 0, 1}, {-2, 0, 2}, {-1, 0, 1}}, image.Pt(1, 1), 1, mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for y := range gx {
+			for x := range gx[y] {
+				if gx[y][x] != sobel[y][x] {
+					t.Fatalf("modo %d: sobel (%d,%d) = %g, convolução %g", mode, x, y, gx[y][x], sobel[y][x])
+				}
+			}
+		}
+	}
+}
+
+func TestParseBorderMode(t *testing.T) {
+	for s, want := range map[string]BorderMode{"replicate": BorderReplicate, "reflect": BorderReflect, "wrap": BorderWrap, "zero": BorderZero} {
+		if got, err := ParseBorderMode(s); err != nil || got != want {
+			t.Errorf("%q: %d, %v", s, got, err)
+		}
+	}
+	if _, err := ParseBorderMode("mirror"); err == nil {
+		t.Error("modo desconhecido sem erro")
+	}
+}
@@ -0,0 +1,54 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+)
+
+// BoxFilter faz a média (truncada) de cada janela size×size, com os pixels
+// de fora da imagem vindos de border (em BorderZero contam como zero). Em
+// tamanhos pares a âncora é a de KernelAnchor, logo depois do meio: a
+// janela 2x2 do pixel (x, y) vai de (x−1, y−1) a (x, y). As somas saem de
+// uma tabela de somas acumuladas (imagem integral), então o custo por
+// pixel não depende do tamanho da janela.
+func BoxFilter(img *image.Gray, size int, border BorderMode) (*image.Gray, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("tamanho do filtro box deve ser positivo, não %d", size)
+	}
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	filteredImg := image.NewGray(image.Rect(0, 0, width, height))
+	if width == 0 || height == 0 {
+		return filteredImg, nil
+	}
+
+	// sat[y][x] (linhas de stride sw+1) é a soma do retângulo [0, x)×[0, y)
+	// da imagem estendida com before pixels antes e after depois
+	before, after := size/2, size-1-size/2
+	xs, ys := border.table(width, before, after), border.table(height, before, after)
+	sw, sh := width+size-1, height+size-1
+	sat := make([]int, (sw+1)*(sh+1))
+	for y := 0; y < sh; y++ {
+		row := sat[(y+1)*(sw+1):]
+		above := sat[y*(sw+1):]
+		run := 0
+		for x := 0; x < sw; x++ {
+			if ys[y] >= 0 && xs[x] >= 0 {
+				run += int(img.Pix[ys[y]*img.Stride+xs[x]])
+			}
+			row[x+1] = above[x+1] + run
+		}
+	}
+
+	count := size * size
+	parallelRows(height, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			top, bottom := sat[y*(sw+1):], sat[(y+size)*(sw+1):]
+			for x := 0; x < width; x++ {
+				sum := bottom[x+size] - top[x+size] - bottom[x] + top[x]
+				filteredImg.Pix[y*filteredImg.Stride+x] = uint8(sum / count)
+			}
+		}
+	})
+
+	return filteredImg, nil
+}
@@ -0,0 +1,112 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"testing"
+)
+
+// referenceBox é o filtro box direto: soma a janela inteira de cada pixel,
+// com a âncora de KernelAnchor nos tamanhos pares.
+func referenceBox(img *image.Gray, size int, border BorderMode) *image.Gray {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	result := image.NewGray(image.Rect(0, 0, w, h))
+	before := size / 2
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sum := 0
+			for j := 0; j < size; j++ {
+				for i := 0; i < size; i++ {
+					sx, okx := border.index(x-before+i, w)
+					sy, oky := border.index(y-before+j, h)
+					if okx && oky {
+						sum += int(img.GrayAt(b.Min.X+sx, b.Min.Y+sy).Y)
+					}
+				}
+			}
+			result.Pix[y*result.Stride+x] = uint8(sum / (size * size))
+		}
+	}
+	return result
+}
+
+func TestBoxFilterMatchesReference(t *testing.T) {
+	images := []struct {
+		name string
+		img  *image.Gray
+	}{
+		{"aleatória", randomGray(23, 17, 1)},
+		{"recorte", randomGray(40, 40, 2).SubImage(image.Rect(5, 9, 26, 22)).(*image.Gray)},
+		{"uma linha", randomGray(12, 1, 3)},
+		{"um pixel", randomGray(1, 1, 4)},
+	}
+	for _, im := range images {
+		for _, border := range []BorderMode{BorderReplicate, BorderReflect, BorderWrap, BorderZero} {
+			for _, size := range []int{1, 2, 3, 4, 7, 8, 15, 30} {
+				name := fmt.Sprintf("%s, borda %d, %dx%d", im.name, border, size, size)
+				got, err := BoxFilter(im.img, size, border)
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				want := referenceBox(im.img, size, border)
+				if !got.Bounds().Eq(want.Bounds()) {
+					t.Fatalf("%s: limites %v, esperado %v", name, got.Bounds(), want.Bounds())
+				}
+				for i := range want.Pix {
+					if got.Pix[i] != want.Pix[i] {
+						t.Fatalf("%s: pixel %d vale %d, esperado %d", name, i, got.Pix[i], want.Pix[i])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestBoxFilterEvenAnchor(t *testing.T) {
+	// um único pixel aceso em (2, 2): a janela 2x2 de (x, y) cobre
+	// (x−1, y−1)..(x, y), então a média aparece em (2..3, 2..3)
+	img := image.NewGray(image.Rect(0, 0, 6, 6))
+	img.Pix[2*img.Stride+2] = 200
+	got, err := BoxFilter(img, 2, BorderZero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 6; x++ {
+			want := uint8(0)
+			if (x == 2 || x == 3) && (y == 2 || y == 3) {
+				want = 50
+			}
+			if got.GrayAt(x, y).Y != want {
+				t.Errorf("pixel %d,%d vale %d, esperado %d", x, y, got.GrayAt(x, y).Y, want)
+			}
+		}
+	}
+}
+
+func TestBoxFilterErrors(t *testing.T) {
+	for _, size := range []int{0, -3} {
+		if _, err := BoxFilter(uniformGray(4, 1), size, BorderReplicate); err == nil {
+			t.Errorf("tamanho %d: sem erro", size)
+		}
+	}
+	got, err := BoxFilter(image.NewGray(image.Rect(0, 0, 0, 3)), 3, BorderReplicate)
+	if err != nil || !got.Bounds().Empty() {
+		t.Errorf("imagem vazia: %v, %v", got.Bounds(), err)
+	}
+}
+
+func BenchmarkBoxFilter(b *testing.B) {
+	// o tempo deve ser o mesmo em todos os tamanhos
+	img := randomGray(1024, 1024, 1)
+	for _, size := range []int{3, 7, 31, 101} {
+		b.Run(fmt.Sprintf("%dx%d", size, size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := BoxFilter(img, size, BorderReplicate); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// CannyOptions controla o Canny. Os limiares são na escala da magnitude do
+// operador (0 a Operator.MaxMagnitude(), SobelMaxMagnitude no sobel);
+// pixels acima de High são bordas fortes e os entre Low e High só ficam se
+// ligados (vizinhança 8) a uma forte.
+type CannyOptions struct {
+	Sigma float64 // suavização gaussiana antes do gradiente; 0 desliga
+	Low   float64
+	High  float64
+	// Border define os pixels fora da imagem no gradiente
+	Border BorderMode
+	// Operator são os kernels do gradiente; o zero é o sobel
+	Operator GradientOperator
+}
+
+// DefaultCannyOptions são os parâmetros usados pela linha de comando.
+var DefaultCannyOptions = CannyOptions{Sigma: 1.4, Low: 40, High: 100, Border: BorderReplicate}
+
+// StageFunc recebe as etapas intermediárias de um algoritmo (para
+// depuração): o nome da etapa, a imagem e os parâmetros relevantes.
+type StageFunc func(stage string, img image.Image, params map[string]any)
+
+// Canny detecta bordas; veja CannyStages.
+func Canny(img *image.Gray, opts CannyOptions) (*image.Gray, error) {
+	return CannyStages(img, opts, nil)
+}
+
+// CannyStages é o Canny completo: suavização gaussiana, gradiente, supressão de
+// não-máximos na direção do gradiente (4 setores) e limiar duplo com
+// histerese. O resultado é binário (borda 255, fundo 0) com bordas de 1
+// pixel. Cada etapa é passada para stage (pode ser nil). Limiares negativos
+// ou Low > High são erro.
+func CannyStages(img *image.Gray, opts CannyOptions, stage StageFunc) (*image.Gray, error) {
+	if opts.Low < 0 || opts.High < opts.Low || opts.Sigma < 0 {
+		return nil, fmt.Errorf("parâmetros do Canny inválidos: σ=%g, limiares %g e %g (exige 0 <= low <= high)", opts.Sigma, opts.Low, opts.High)
+	}
+	record := func(name string, mk func() image.Image, params map[string]any) {
+		if stage != nil {
+			stage(name, mk(), params)
+		}
+	}
+
+	field := GrayToFloat(img)
+	if opts.Sigma > 0 {
+		field = SmoothFloat(field, opts.Sigma)
+	}
+	record("smoothed", func() image.Image { return FloatToGray(field, 255) }, map[string]any{"sigma": opts.Sigma})
+
+	mag, dir := GradientFromComponents(GradientComponents(field, opts.Operator, opts.Border))
+	record("magnitude", func() image.Image { return NormalizeToGray(mag) }, nil)
+	sectors := quantizeSectors(dir)
+	record("direction", func() image.Image { return sectorMap(sectors, mag, opts.Low) }, map[string]any{"bins": 4})
+
+	thin := nonMaxSuppression(mag, sectors)
+	record("nms", func() image.Image { return NormalizeToGray(thin) }, nil)
+
+	classes := classifyEdges(thin, opts.Low, opts.High)
+	record("strong_weak", func() image.Image { return classes }, map[string]any{"low": opts.Low, "high": opts.High})
+
+	edges := hysteresis(classes)
+	record("edges", func() image.Image { return edges }, nil)
+	return edges, nil
+}
+
+func quantizeSectors(dir [][]float64) [][]int {
+	sectors := make([][]int, len(dir))
+	for y, row := range dir {
+		sectors[y] = make([]int, len(row))
+		for x, theta := range row {
+			sectors[y][x] = QuantizeDirection(theta, 4)
+		}
+	}
+	return sectors
+}
+
+// sectorMap colore os setores dos pixels com magnitude acima do limiar.
+func sectorMap(sectors [][]int, mag [][]float64, threshold float64) *image.RGBA {
+	height := len(sectors)
+	width := 0
+	if height > 0 {
+		width = len(sectors[0])
+	}
+	result := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{0, 0, 0, 255}
+			if mag[y][x] > threshold {
+				c = DirectionColors[sectors[y][x]]
+			}
+			result.SetRGBA(x, y, c)
+		}
+	}
+	return result
+}
+
+// vizinhos na direção do gradiente de cada setor (0°, 45°, 90°, 135°, com y
+// para baixo como no atan2 do sobel)
+var sectorOffsets = [4][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}}
+
+// nonMaxSuppression zera os pixels que não são máximos locais ao longo do
+// gradiente. Num platô de dois pixels iguais só o primeiro no sentido do
+// gradiente sobrevive (> de um lado, >= do outro), senão a borda fica dupla.
+func nonMaxSuppression(mag [][]float64, sectors [][]int) [][]float64 {
+	height := len(mag)
+	result := make([][]float64, height)
+	for y := range result {
+		result[y] = make([]float64, len(mag[y]))
+	}
+	for y := 1; y < height-1; y++ {
+		for x := 1; x < len(mag[y])-1; x++ {
+			m := mag[y][x]
+			if m == 0 {
+				continue
+			}
+			d := sectorOffsets[sectors[y][x]]
+			ahead := mag[y+d[1]][x+d[0]]
+			behind := mag[y-d[1]][x-d[0]]
+			if m > ahead && m >= behind {
+				result[y][x] = m
+			}
+		}
+	}
+	return result
+}
+
+// níveis de classifyEdges
+const (
+	edgeWeak   = 128
+	edgeStrong = 255
+)
+
+// classifyEdges aplica o limiar duplo: fortes em 255, fracas em 128.
+func classifyEdges(mag [][]float64, low, high float64) *image.Gray {
+	height := len(mag)
+	width := 0
+	if height > 0 {
+		width = len(mag[0])
+	}
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			switch m := mag[y][x]; {
+			case m >= high:
+				result.Pix[y*result.Stride+x] = edgeStrong
+			case m >= low && m > 0:
+				result.Pix[y*result.Stride+x] = edgeWeak
+			}
+		}
+	}
+	return result
+}
+
+// hysteresis mantém as bordas fracas ligadas a uma forte pela vizinhança 8
+// (busca em largura a partir das fortes) e descarta as outras.
+func hysteresis(classes *image.Gray) *image.Gray {
+	width, height := classes.Bounds().Dx(), classes.Bounds().Dy()
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	var queue []image.Point
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if classes.Pix[y*classes.Stride+x] == edgeStrong {
+				result.Pix[y*result.Stride+x] = 255
+				queue = append(queue, image.Pt(x, y))
+			}
+		}
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				x, y := p.X+dx, p.Y+dy
+				if x < 0 || x >= width || y < 0 || y >= height {
+					continue
+				}
+				if classes.Pix[y*classes.Stride+x] == edgeWeak && result.Pix[y*result.Stride+x] == 0 {
+					result.Pix[y*result.Stride+x] = 255
+					queue = append(queue, image.Pt(x, y))
+				}
+			}
+		}
+	}
+	return result
+}
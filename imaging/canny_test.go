@@ -0,0 +1,141 @@
+package imaging
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+// diskImage é uma imagem size×size preta com um disco de valor v.
+func diskImage(size int, c image.Point, r float64, v uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if math.Hypot(float64(x-c.X), float64(y-c.Y)) <= r {
+				img.Pix[y*img.Stride+x] = v
+			}
+		}
+	}
+	return img
+}
+
+// reachesBorder diz se a inundação em vizinhança 4 a partir de start, sem
+// passar por pixels de borda, chega à margem da imagem.
+func reachesBorder(edges *image.Gray, start image.Point) bool {
+	w, h := edges.Bounds().Dx(), edges.Bounds().Dy()
+	seen := make([]bool, w*h)
+	queue := []image.Point{start}
+	seen[start.Y*w+start.X] = true
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if p.X == 0 || p.Y == 0 || p.X == w-1 || p.Y == h-1 {
+			return true
+		}
+		for _, d := range []image.Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			q := p.Add(d)
+			if i := q.Y*w + q.X; !seen[i] && edges.Pix[q.Y*edges.Stride+q.X] == 0 {
+				seen[i] = true
+				queue = append(queue, q)
+			}
+		}
+	}
+	return false
+}
+
+func TestCannyClosedCircle(t *testing.T) {
+	tests := []struct {
+		name   string
+		radius float64
+		value  uint8
+	}{
+		{"raio 10", 10, 255},
+		{"raio 25", 25, 255},
+		{"baixo contraste", 20, 60},
+	}
+	for _, tt := range tests {
+		center := image.Pt(40, 40)
+		edges, err := Canny(diskImage(80, center, tt.radius, tt.value), DefaultCannyOptions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reachesBorder(edges, center) {
+			t.Errorf("%s: contorno aberto", tt.name)
+		}
+		labels, n, err := LabelComponents(edges, 8, WhiteObjects)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 {
+			t.Errorf("%s: %d contornos, esperado 1", tt.name, n)
+		}
+		for y := 0; y < 79; y++ {
+			for x := 0; x < 79; x++ {
+				if labels[y][x] > 0 && labels[y][x+1] > 0 && labels[y+1][x] > 0 && labels[y+1][x+1] > 0 {
+					t.Errorf("%s: bloco 2x2 de borda em (%d,%d), esperado 1 pixel de largura", tt.name, x, y)
+				}
+				if edges.Pix[y*edges.Stride+x] == 0 {
+					continue
+				}
+				if d := math.Hypot(float64(x-center.X), float64(y-center.Y)); math.Abs(d-tt.radius) > 1.5 {
+					t.Errorf("%s: borda em (%d,%d) a %.1f do centro, esperado ≈ %g", tt.name, x, y, d, tt.radius)
+				}
+			}
+		}
+	}
+}
+
+func TestCannyUniform(t *testing.T) {
+	edges, err := Canny(uniformGray(32, 90), DefaultCannyOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range edges.Pix {
+		if v != 0 {
+			t.Fatalf("pixel %d de borda numa imagem uniforme", i)
+		}
+	}
+}
+
+func TestHysteresis(t *testing.T) {
+	classes := image.NewGray(image.Rect(0, 0, 10, 3))
+	// linha 0: forte em 0 seguida de fracas até 5 e uma fraca solta em 8
+	// linha 2: só fracas
+	for x := 1; x <= 5; x++ {
+		classes.Pix[x] = edgeWeak
+	}
+	classes.Pix[0], classes.Pix[8] = edgeStrong, edgeWeak
+	for x := 0; x < 10; x++ {
+		classes.Pix[2*classes.Stride+x] = edgeWeak
+	}
+	// ligação diagonal da fraca (6,1) com (5,0)
+	classes.Pix[classes.Stride+6] = edgeWeak
+
+	result := hysteresis(classes)
+	tests := []struct {
+		x, y int
+		want uint8
+	}{
+		{0, 0, 255}, {5, 0, 255}, {6, 1, 255}, {8, 0, 0},
+		// a linha 2 encosta em (6,1) pela diagonal e sobrevive toda
+		{0, 2, 255}, {9, 2, 255},
+	}
+	for _, tt := range tests {
+		if v := result.Pix[tt.y*result.Stride+tt.x]; v != tt.want {
+			t.Errorf("(%d,%d) = %d, esperado %d", tt.x, tt.y, v, tt.want)
+		}
+	}
+}
+
+func TestCannyInvalidOptions(t *testing.T) {
+	tests := []CannyOptions{
+		{Sigma: 1, Low: -1, High: 10},
+		{Sigma: 1, Low: 50, High: 10},
+		{Sigma: -1, Low: 10, High: 50},
+	}
+	for _, opts := range tests {
+		if _, err := Canny(uniformGray(8, 0), opts); err == nil {
+			t.Errorf("%+v: sem erro", opts)
+		}
+	}
+}
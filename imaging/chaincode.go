@@ -0,0 +1,132 @@
+package imaging
+
+import "image"
+
+// chainDirections são os deslocamentos de cada código de Freeman: 0 =
+// direita e, no sentido anti-horário, até 7 = diagonal inferior direita.
+var chainDirections = [8]image.Point{
+	{1, 0},   // 0: Direita
+	{1, -1},  // 1: Diagonal superior direita
+	{0, -1},  // 2: Cima
+	{-1, -1}, // 3: Diagonal superior esquerda
+	{-1, 0},  // 4: Esquerda
+	{-1, 1},  // 5: Diagonal inferior esquerda
+	{0, 1},   // 6: Baixo
+	{1, 1},   // 7: Diagonal inferior direita
+}
+
+// ChainCode é o código de cadeia do contorno de um objeto rotulado.
+type ChainCode struct {
+	Label int
+	Start image.Point
+	Code  []int
+}
+
+// FreemanChainCode contorna o primeiro objeto preto (0) da imagem, o que
+// tem o pixel mais acima e à esquerda; veja traceBoundary. Devolve os
+// códigos (0 = direita, anti-horário até 7), o pixel inicial e ok falso se
+// não há objeto; um objeto de 1 pixel tem cadeia vazia.
+func FreemanChainCode(img *image.Gray) (chain []int, start image.Point, ok bool) {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	object := func(p image.Point) bool {
+		return p.X >= 0 && p.Y >= 0 && p.X < width && p.Y < height && img.Pix[p.Y*img.Stride+p.X] == 0
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if start := image.Pt(x, y); object(start) {
+				return traceBoundary(start, object), start, true
+			}
+		}
+	}
+	return nil, image.Point{}, false
+}
+
+// FreemanChainCodes devolve o código de cadeia de cada objeto preto (0),
+// rotulados com LabelComponents (vizinhança 8) e na ordem dos rótulos. Sem
+// objetos a lista é vazia.
+func FreemanChainCodes(img *image.Gray) []ChainCode {
+	labels, n, _ := LabelComponents(img, 8, BlackObjects)
+	chains := make([]ChainCode, 0, n)
+	for y, row := range labels {
+		for x, l := range row {
+			// os rótulos seguem a ordem da varredura, então o primeiro
+			// pixel de cada um é o mais acima e à esquerda
+			if l != len(chains)+1 {
+				continue
+			}
+			object := func(p image.Point) bool {
+				return p.X >= 0 && p.Y >= 0 && p.Y < len(labels) && p.X < len(row) && labels[p.Y][p.X] == l
+			}
+			start := image.Pt(x, y)
+			chains = append(chains, ChainCode{l, start, traceBoundary(start, object)})
+		}
+	}
+	return chains
+}
+
+// traceBoundary contorna o objeto a partir de start, o pixel dele mais
+// acima e à esquerda, pelo algoritmo de vizinhança de Moore: anda pelo
+// contorno externo no sentido anti-horário (descendo pela esquerda, com o
+// objeto à esquerda de quem anda) e para quando volta ao início prestes a
+// repetir o primeiro passo (critério de Jacob).
+func traceBoundary(start image.Point, object func(image.Point) bool) []int {
+	var chain []int
+	// a busca começa logo depois do vizinho de onde se veio; 7 no início
+	// porque acima e à esquerda do pixel inicial só há fundo
+	current, dir := start, 7
+	for {
+		next := -1
+		first := (dir + 7) % 8
+		if dir%2 == 1 {
+			first = (dir + 6) % 8
+		}
+		for i := 0; i < 8; i++ {
+			d := (first + i) % 8
+			if object(current.Add(chainDirections[d])) {
+				next = d
+				break
+			}
+		}
+		if next == -1 || (current == start && len(chain) > 0 && next == chain[0]) {
+			return chain
+		}
+		chain = append(chain, next)
+		current = current.Add(chainDirections[next])
+		dir = next
+	}
+}
+
+// ChainToImage desenha o contorno de uma cadeia em preto (0) sobre uma
+// imagem branca que cobre bounds; a saída tem origem em (0, 0), com
+// bounds.Min no canto. Passos fora de bounds não são desenhados.
+func ChainToImage(start image.Point, chain []int, bounds image.Rectangle) *image.Gray {
+	result := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for i := range result.Pix {
+		result.Pix[i] = 255
+	}
+	DrawChain(result, start.Sub(bounds.Min), chain)
+	return result
+}
+
+// DrawChain pinta de preto (0) em dst os pixels da cadeia, recortando o que
+// cair fora da imagem.
+func DrawChain(dst *image.Gray, start image.Point, chain []int) {
+	for _, p := range ChainPoints(start, chain) {
+		if p.In(dst.Bounds()) {
+			dst.Pix[dst.PixOffset(p.X, p.Y)] = 0
+		}
+	}
+}
+
+// ChainPoints devolve os pixels por onde a cadeia passa, a começar por
+// start (len(chain)+1 pontos). Os códigos vão de 0 a 7.
+func ChainPoints(start image.Point, chain []int) []image.Point {
+	points := make([]image.Point, 0, len(chain)+1)
+	p := start
+	points = append(points, p)
+	for _, d := range chain {
+		p = p.Add(chainDirections[d])
+		points = append(points, p)
+	}
+	return points
+}
@@ -0,0 +1,207 @@
+package imaging
+
+import (
+	"image"
+	"slices"
+	"testing"
+)
+
+func TestFreemanChainCode(t *testing.T) {
+	tests := []struct {
+		name  string
+		art   string
+		start image.Point
+		chain []int
+	}{
+		{
+			"quadrado 5x5",
+			`.......
+			 .#####.
+			 .#####.
+			 .#####.
+			 .#####.
+			 .#####.
+			 .......`,
+			image.Pt(1, 1),
+			[]int{6, 6, 6, 6, 0, 0, 0, 0, 2, 2, 2, 2, 4, 4, 4, 4},
+		},
+		{"pixel isolado", "... .#. ...", image.Pt(1, 1), nil},
+		{"linha horizontal", "..... .###. .....", image.Pt(1, 1), []int{0, 0, 4, 4}},
+		{"linha vertical", ".#. .#. .#.", image.Pt(1, 0), []int{6, 6, 2, 2}},
+		{"diagonal", "#.. .#. ..#", image.Pt(0, 0), []int{7, 7, 3, 3}},
+		{"na borda da imagem", "## ##", image.Pt(0, 0), []int{6, 0, 2, 4}},
+		{
+			// o buraco não entra: só o contorno externo
+			"anel",
+			`###
+			 #.#
+			 ###`,
+			image.Pt(0, 0),
+			[]int{6, 6, 0, 0, 2, 2, 4, 4},
+		},
+		{
+			// o pixel inicial é ponte entre duas partes: passa-se por ele
+			// duas vezes e só se para ao repetir o primeiro passo
+			"início no meio de um oito",
+			`..#..
+			 .#.#.
+			 #...#
+			 .#.#.
+			 ..#..
+			 .#.#.
+			 ..#..`,
+			image.Pt(2, 0),
+			[]int{5, 5, 7, 7, 5, 7, 1, 3, 1, 1, 3, 3},
+		},
+		{"outro objeto não entra", "##..# ##... ....#", image.Pt(0, 0), []int{6, 0, 2, 4}},
+	}
+	for _, tt := range tests {
+		img := parseMask(tt.art, 0)
+		chain, start, ok := FreemanChainCode(img)
+		if !ok || start != tt.start || !slices.Equal(chain, tt.chain) {
+			t.Errorf("%s: cadeia %v a partir de %v (ok %v), esperado %v a partir de %v", tt.name, chain, start, ok, tt.chain, tt.start)
+			continue
+		}
+		// a cadeia é fechada e só passa por pixels do objeto
+		points := ChainPoints(start, chain)
+		if points[len(points)-1] != start {
+			t.Errorf("%s: cadeia termina em %v, não no início", tt.name, points[len(points)-1])
+		}
+		for _, p := range points {
+			if img.Pix[p.Y*img.Stride+p.X] != 0 {
+				t.Errorf("%s: passa pelo fundo em %v", tt.name, p)
+			}
+		}
+	}
+
+	if _, _, ok := FreemanChainCode(parseMask("... ...", 0)); ok {
+		t.Error("imagem sem objetos com ok")
+	}
+}
+
+func TestChainToImage(t *testing.T) {
+	img := parseMask(`......
+	                  .####.
+	                  .####.
+	                  .####.
+	                  ......`, 0)
+	chain, start, _ := FreemanChainCode(img)
+	drawn := ChainToImage(start, chain, img.Bounds())
+	// o contorno de um retângulo cheio é tudo menos o miolo
+	want := parseMask(`......
+	                   .####.
+	                   .#..#.
+	                   .####.
+	                   ......`, 0)
+	if !slices.Equal(drawn.Pix, want.Pix) {
+		t.Errorf("contorno desenhado %v", drawn.Pix)
+	}
+
+	// deslocar bounds desloca o desenho, e o que cai fora é recortado
+	shifted := ChainToImage(start, chain, image.Rect(2, 1, 5, 3))
+	if shifted.Bounds() != image.Rect(0, 0, 3, 2) || shifted.Pix[0] != 0 || shifted.Pix[shifted.Stride+1] != 255 {
+		t.Errorf("recorte %v com %v", shifted.Bounds(), shifted.Pix)
+	}
+}
+
+func TestFreemanChainCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		art    string
+		chains []ChainCode
+	}{
+		{"vazia", "... ...", []ChainCode{}},
+		{
+			"três objetos",
+			`##...#
+			 ##....
+			 ....##
+			 ......`,
+			[]ChainCode{
+				{1, image.Pt(0, 0), []int{6, 0, 2, 4}},
+				{2, image.Pt(5, 0), nil},
+				{3, image.Pt(4, 2), []int{0, 4}},
+			},
+		},
+		{
+			// em U: o objeto aparece duas vezes na primeira linha, mas é
+			// um rótulo só e uma cadeia só
+			"U",
+			`#.#
+			 ###`,
+			[]ChainCode{{1, image.Pt(0, 0), []int{6, 0, 0, 2, 5, 3}}},
+		},
+	}
+	for _, tt := range tests {
+		got := FreemanChainCodes(parseMask(tt.art, 0))
+		if got == nil {
+			t.Errorf("%s: lista nil", tt.name)
+		}
+		if len(got) != len(tt.chains) {
+			t.Errorf("%s: %d cadeias, esperado %d", tt.name, len(got), len(tt.chains))
+			continue
+		}
+		for i, want := range tt.chains {
+			if got[i].Label != want.Label || got[i].Start != want.Start || !slices.Equal(got[i].Code, want.Code) {
+				t.Errorf("%s: cadeia %d = %+v, esperado %+v", tt.name, i, got[i], want)
+			}
+		}
+	}
+}
+
+func TestChainRoundTrip(t *testing.T) {
+	blobs := []struct {
+		name   string
+		inside func(x, y int) bool
+	}{
+		{"disco", func(x, y int) bool { return (x-20)*(x-20)+(y-18)*(y-18) <= 12*12 }},
+		{"elipse", func(x, y int) bool { return (x-20)*(x-20)*4+(y-18)*(y-18) <= 15*15 }},
+		{"losango", func(x, y int) bool { return max(x-20, 20-x)+max(y-18, 18-y) <= 10 }},
+		{"cruz grossa", func(x, y int) bool {
+			return (x >= 15 && x < 25 && y >= 5 && y < 32) || (y >= 13 && y < 23 && x >= 6 && x < 34)
+		}},
+	}
+	for _, b := range blobs {
+		img := uniformGray(40, 255)
+		for y := 0; y < 40; y++ {
+			for x := 0; x < 40; x++ {
+				if b.inside(x, y) {
+					img.Pix[y*img.Stride+x] = 0
+				}
+			}
+		}
+		chain, start, ok := FreemanChainCode(img)
+		if !ok {
+			t.Fatalf("%s: sem objeto", b.name)
+		}
+		drawn := ChainToImage(start, chain, img.Bounds())
+		// o contorno são os pixels do objeto com um vizinho de lado no fundo
+		object := func(x, y int) bool {
+			return x >= 0 && y >= 0 && x < 40 && y < 40 && img.Pix[y*img.Stride+x] == 0
+		}
+		for y := 0; y < 40; y++ {
+			for x := 0; x < 40; x++ {
+				boundary := object(x, y) && (!object(x-1, y) || !object(x+1, y) || !object(x, y-1) || !object(x, y+1))
+				if onChain := drawn.Pix[y*drawn.Stride+x] == 0; onChain != boundary {
+					t.Errorf("%s: pixel %d,%d na cadeia %v, no contorno %v", b.name, x, y, onChain, boundary)
+				}
+			}
+		}
+	}
+}
+
+func TestChainToImageClips(t *testing.T) {
+	// uma cadeia que sai da imagem pelos quatro lados
+	chain := []int{4, 4, 4, 6, 6, 6, 6, 0, 0, 0, 0, 0, 0, 2, 2, 2, 2, 2, 2, 2}
+	img := ChainToImage(image.Pt(1, 1), chain, image.Rect(0, 0, 3, 3))
+	black := 0
+	for _, v := range img.Pix {
+		if v == 0 {
+			black++
+		}
+	}
+	// dentro da imagem só passam o início (1,1) e o primeiro passo (0,1)
+	if img.Bounds() != image.Rect(0, 0, 3, 3) || black != 2 || img.Pix[3] != 0 || img.Pix[4] != 0 {
+		t.Errorf("limites %v com os pixels %v", img.Bounds(), img.Pix)
+	}
+}
@@ -0,0 +1,134 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// CLAHE faz a equalização de histograma adaptativa com limite de contraste:
+// a imagem é dividida numa grade tiles×tiles (menos quando ela tem menos
+// pixels que isso), o histograma de cada bloco é cortado em clipLimit vezes
+// a altura média de um nível (o excesso é espalhado por todos os níveis) e
+// equalizado, e cada pixel interpola bilinearmente os mapeamentos dos
+// quatro blocos cujos centros o cercam, para não aparecerem os degraus
+// entre blocos. Fora dos centros dos blocos da borda vale o mapeamento do
+// bloco mais próximo. Quando a imagem não divide certo, os blocos diferem
+// em 1 pixel. clipLimit <= 0 desliga o corte (equalização adaptativa comum).
+func CLAHE(img *image.Gray, tiles int, clipLimit float64) (*image.Gray, error) {
+	if tiles < 1 {
+		return nil, fmt.Errorf("a grade do CLAHE deve ter pelo menos 1 bloco, não %d", tiles)
+	}
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	if width == 0 || height == 0 {
+		return result, nil
+	}
+	xs := tileEdges(width, min(tiles, width))
+	ys := tileEdges(height, min(tiles, height))
+	tilesX, tilesY := len(xs)-1, len(ys)-1
+
+	luts := make([][256]uint8, tilesX*tilesY)
+	for ty := 0; ty < tilesY; ty++ {
+		for tx := 0; tx < tilesX; tx++ {
+			var hist [256]int
+			for y := ys[ty]; y < ys[ty+1]; y++ {
+				for _, v := range img.Pix[y*img.Stride+xs[tx] : y*img.Stride+xs[tx+1]] {
+					hist[v]++
+				}
+			}
+			luts[ty*tilesX+tx] = clippedEqualization(hist, (xs[tx+1]-xs[tx])*(ys[ty+1]-ys[ty]), clipLimit)
+		}
+	}
+
+	colX := interpolationWeights(xs, width)
+	rowY := interpolationWeights(ys, height)
+	parallelRows(height, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			ry := rowY[y]
+			for x := 0; x < width; x++ {
+				cx := colX[x]
+				v := img.Pix[y*img.Stride+x]
+				top := (1-cx.w)*float64(luts[ry.i0*tilesX+cx.i0][v]) + cx.w*float64(luts[ry.i0*tilesX+cx.i1][v])
+				bottom := (1-cx.w)*float64(luts[ry.i1*tilesX+cx.i0][v]) + cx.w*float64(luts[ry.i1*tilesX+cx.i1][v])
+				result.Pix[y*result.Stride+x] = uint8(math.Round((1-ry.w)*top + ry.w*bottom))
+			}
+		}
+	})
+	return result, nil
+}
+
+// tileEdges divide [0, n) em count blocos quase iguais e devolve as count+1
+// bordas.
+func tileEdges(n, count int) []int {
+	edges := make([]int, count+1)
+	for i := range edges {
+		edges[i] = i * n / count
+	}
+	return edges
+}
+
+// tileWeight diz entre quais blocos uma coluna (ou linha) está e o peso do
+// segundo.
+type tileWeight struct {
+	i0, i1 int
+	w      float64
+}
+
+// interpolationWeights calcula, para cada coordenada de [0, n), os dois
+// blocos cujos centros a cercam e o peso da interpolação linear.
+func interpolationWeights(edges []int, n int) []tileWeight {
+	count := len(edges) - 1
+	center := func(i int) float64 { return float64(edges[i]+edges[i+1]-1) / 2 }
+	weights := make([]tileWeight, n)
+	i := 0
+	for p := range weights {
+		for i+1 < count && center(i+1) <= float64(p) {
+			i++
+		}
+		switch {
+		case float64(p) <= center(0):
+			weights[p] = tileWeight{0, 0, 0}
+		case i == count-1:
+			weights[p] = tileWeight{i, i, 0}
+		default:
+			weights[p] = tileWeight{i, i + 1, (float64(p) - center(i)) / (center(i+1) - center(i))}
+		}
+	}
+	return weights
+}
+
+// clippedEqualization corta o histograma de um bloco de n pixels, espalha o
+// excesso e devolve o mapeamento da equalização.
+func clippedEqualization(hist [256]int, n int, clipLimit float64) [256]uint8 {
+	if clipLimit > 0 {
+		limit := max(1, int(clipLimit*float64(n)/256))
+		excess := 0
+		for v, c := range hist {
+			if c > limit {
+				excess += c - limit
+				hist[v] = limit
+			}
+		}
+		// partes iguais para todos os níveis e o resto, um a um, espaçado
+		// pelos níveis
+		for v := range hist {
+			hist[v] += excess / 256
+		}
+		if rest := excess % 256; rest > 0 {
+			step := 256 / rest
+			for v := 0; v < 256 && rest > 0; v += step {
+				hist[v]++
+				rest--
+			}
+		}
+	}
+
+	var lut [256]uint8
+	sum := 0
+	for v, c := range hist {
+		sum += c
+		lut[v] = uint8(math.Round(float64(sum) * 255 / float64(n)))
+	}
+	return lut
+}
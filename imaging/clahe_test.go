@@ -0,0 +1,164 @@
+package imaging
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+var update = flag.Bool("update", false, "regrava as imagens de referência em testdata")
+
+// claheInput é uma imagem escura e de pouco contraste, como uma radiografia:
+// fundo em degradê, discos um pouco mais claros e ruído. 100x70 não divide
+// pela grade 8x8.
+func claheInput() *image.Gray {
+	img := synthetic.Noise(100, 70, 0, 3, 7)
+	for y := 0; y < 70; y++ {
+		for x := 0; x < 100; x++ {
+			v := 30 + 40*x/99 + int(img.Pix[y*img.Stride+x])
+			if dx, dy := x%25-12, y%35-17; dx*dx+dy*dy < 64 {
+				v += 12
+			}
+			img.Pix[y*img.Stride+x] = uint8(v)
+		}
+	}
+	return img
+}
+
+func TestCLAHEGolden(t *testing.T) {
+	got, err := CLAHE(claheInput(), 8, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareGolden(t, "clahe.png", got)
+}
+
+// compareGolden compara got com a imagem de referência testdata/name, que
+// go test -update regrava.
+func compareGolden(t *testing.T, name string, got *image.Gray) {
+	t.Helper()
+	golden := filepath.Join("testdata", name)
+	if *update {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, got); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file, err := os.Open(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	decoded, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, ok := decoded.(*image.Gray)
+	if !ok || want.Bounds() != got.Bounds() {
+		t.Fatalf("%s: referência %T com limites %v", name, decoded, decoded.Bounds())
+	}
+	for y := 0; y < want.Bounds().Dy(); y++ {
+		for x := 0; x < want.Bounds().Dx(); x++ {
+			if g, w := got.GrayAt(x, y).Y, want.GrayAt(x, y).Y; g != w {
+				t.Fatalf("%s: pixel %d,%d = %d, esperado %d (go test -update regrava a referência)", name, x, y, g, w)
+			}
+		}
+	}
+}
+
+func TestCLAHEProperties(t *testing.T) {
+	img := claheInput()
+
+	// um bloco só, sem corte, é a equalização global
+	global, err := CLAHE(img, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lut := clippedEqualization(Histogram(img), 100*70, 0)
+	for i, v := range img.Pix {
+		if global.Pix[i] != lut[v] {
+			t.Fatalf("pixel %d = %d, esperado %d da equalização global", i, global.Pix[i], lut[v])
+		}
+	}
+
+	// o corte limita o ganho de contraste: com limite 1 quase não muda a
+	// faixa, sem limite ela se abre
+	spread := func(g *image.Gray) int {
+		lo, hi := 255, 0
+		for _, v := range g.Pix {
+			lo, hi = min(lo, int(v)), max(hi, int(v))
+		}
+		return hi - lo
+	}
+	clipped, _ := CLAHE(img, 8, 1)
+	free, _ := CLAHE(img, 8, 0)
+	if spread(clipped) >= spread(free) {
+		t.Errorf("faixa %d com corte e %d sem: o corte não limitou o contraste", spread(clipped), spread(free))
+	}
+
+	// sem degraus entre os blocos: num degradê suave vizinhos diferem pouco
+	ramp, _ := synthetic.Ramp(123, 45, "horizontal")
+	smooth, _ := CLAHE(ramp, 8, 2)
+	for y := 0; y < 45; y++ {
+		for x := 1; x < 123; x++ {
+			if d := math.Abs(float64(smooth.Pix[y*smooth.Stride+x]) - float64(smooth.Pix[y*smooth.Stride+x-1])); d > 12 {
+				t.Fatalf("salto de %g entre %d e %d na linha %d", d, x-1, x, y)
+			}
+		}
+	}
+}
+
+func TestCLAHESizes(t *testing.T) {
+	tests := []struct {
+		name  string
+		img   *image.Gray
+		tiles int
+		ok    bool
+	}{
+		{"menor que a grade", randomGray(3, 2, 1), 8, true},
+		{"uma linha", randomGray(50, 1, 2), 8, true},
+		{"vazia", image.NewGray(image.Rect(0, 0, 0, 0)), 8, true},
+		{"recorte", randomGray(40, 40, 3).SubImage(image.Rect(5, 7, 37, 30)).(*image.Gray), 4, true},
+		{"grade zero", randomGray(8, 8, 4), 0, false},
+	}
+	for _, tt := range tests {
+		got, err := CLAHE(tt.img, tt.tiles, 2)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: erro %v", tt.name, err)
+			continue
+		}
+		if tt.ok && got.Bounds().Size() != tt.img.Bounds().Size() {
+			t.Errorf("%s: tamanho %v, esperado %v", tt.name, got.Bounds().Size(), tt.img.Bounds().Size())
+		}
+	}
+}
+
+func TestTileEdges(t *testing.T) {
+	tests := []struct {
+		n, count int
+		want     []int
+	}{
+		{16, 4, []int{0, 4, 8, 12, 16}},
+		{10, 3, []int{0, 3, 6, 10}},
+		{5, 5, []int{0, 1, 2, 3, 4, 5}},
+	}
+	for _, tt := range tests {
+		got := tileEdges(tt.n, tt.count)
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Errorf("%d em %d: %v, esperado %v", tt.n, tt.count, got, tt.want)
+				break
+			}
+		}
+	}
+}
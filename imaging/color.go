@@ -0,0 +1,52 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+)
+
+// GrayWeights são os pesos de R, G e B na conversão para tons de cinza.
+type GrayWeights struct {
+	R, G, B float64
+}
+
+var (
+	LumaBT601   = GrayWeights{0.299, 0.587, 0.114} // TV analógica/JPEG, o padrão
+	LumaBT709   = GrayWeights{0.2126, 0.7152, 0.0722}
+	GrayAverage = GrayWeights{1.0 / 3, 1.0 / 3, 1.0 / 3}
+)
+
+// ParseGrayWeights lê "bt601", "bt709" ou "average".
+func ParseGrayWeights(s string) (GrayWeights, error) {
+	switch s {
+	case "bt601":
+		return LumaBT601, nil
+	case "bt709":
+		return LumaBT709, nil
+	case "average":
+		return GrayAverage, nil
+	}
+	return GrayWeights{}, fmt.Errorf("conversão para cinza desconhecida %q, use bt601, bt709 ou average", s)
+}
+
+// ToGray converte para tons de cinza com os pesos da ITU-R BT.601
+// (0.299R + 0.587G + 0.114B).
+func ToGray(img image.Image) *image.Gray {
+	return ToGrayWeights(img, LumaBT601)
+}
+
+// ToGrayWeights converte para tons de cinza com origem em (0, 0), calculando
+// a luminância sobre os valores de 16 bits de RGBA() e arredondando só no
+// fim.
+func ToGrayWeights(img image.Image, w GrayWeights) *image.Gray {
+	b := img.Bounds()
+	result := image.NewGray(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			r, g, bl, _ := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			v := (w.R*float64(r) + w.G*float64(g) + w.B*float64(bl)) / 257
+			result.Pix[y*result.Stride+x] = uint8(min(255, v+0.5))
+		}
+	}
+	return result
+}
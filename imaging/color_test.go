@@ -0,0 +1,60 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestToGrayWeights(t *testing.T) {
+	tests := []struct {
+		name                  string
+		c                     color.Color
+		bt601, bt709, average uint8
+	}{
+		{"vermelho", color.RGBA{255, 0, 0, 255}, 76, 54, 85},
+		{"verde", color.RGBA{0, 255, 0, 255}, 150, 182, 85},
+		{"azul", color.RGBA{0, 0, 255, 255}, 29, 18, 85},
+		{"branco", color.RGBA{255, 255, 255, 255}, 255, 255, 255},
+		{"preto", color.RGBA{0, 0, 0, 255}, 0, 0, 0},
+		// 16 bits: o meio da escala vira 128 sem passar por 8 bits antes
+		{"cinza de 16 bits", color.RGBA64{0x8000, 0x8000, 0x8000, 0xffff}, 128, 128, 128},
+	}
+	for _, tt := range tests {
+		img := image.NewRGBA64(image.Rect(0, 0, 2, 2))
+		for i := 0; i < 4; i++ {
+			img.Set(i%2, i/2, tt.c)
+		}
+		got := [3]uint8{
+			ToGray(img).Pix[0],
+			ToGrayWeights(img, LumaBT709).Pix[0],
+			ToGrayWeights(img, GrayAverage).Pix[0],
+		}
+		if want := [3]uint8{tt.bt601, tt.bt709, tt.average}; got != want {
+			t.Errorf("%s: bt601/bt709/average %v, esperado %v", tt.name, got, want)
+		}
+	}
+}
+
+func TestToGraySubImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	img.SetRGBA(6, 7, color.RGBA{255, 0, 0, 255})
+	gray := ToGray(img.SubImage(image.Rect(5, 5, 9, 9)))
+	if b := gray.Bounds(); b != image.Rect(0, 0, 4, 4) {
+		t.Fatalf("limites %v, esperado origem em 0,0", b)
+	}
+	if v := gray.GrayAt(1, 2).Y; v != 76 {
+		t.Errorf("pixel vermelho %d, esperado 76", v)
+	}
+}
+
+func TestParseGrayWeights(t *testing.T) {
+	for s, want := range map[string]GrayWeights{"bt601": LumaBT601, "bt709": LumaBT709, "average": GrayAverage} {
+		if got, err := ParseGrayWeights(s); err != nil || got != want {
+			t.Errorf("%q: %v, %v", s, got, err)
+		}
+	}
+	if _, err := ParseGrayWeights("luma"); err == nil {
+		t.Error("conversão desconhecida sem erro")
+	}
+}
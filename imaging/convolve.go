@@ -0,0 +1,195 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strconv"
+)
+
+// KernelNormalization define o divisor da resposta de um kernel: Mode
+// "none" não divide, "auto" divide pela soma dos coeficientes (1 se a soma
+// for zero, como no laplaciano) e "divide" usa Divisor.
+type KernelNormalization struct {
+	Mode    string
+	Divisor float64
+}
+
+// DivisorFor é o divisor efetivo para o kernel.
+func (n KernelNormalization) DivisorFor(kernel [][]float64) float64 {
+	switch n.Mode {
+	case "auto":
+		var sum float64
+		for _, row := range kernel {
+			for _, v := range row {
+				sum += v
+			}
+		}
+		if math.Abs(sum) > 1e-12 {
+			return sum
+		}
+	case "divide":
+		if n.Divisor != 0 {
+			return n.Divisor
+		}
+	}
+	return 1
+}
+
+// ParseKernelNormalization lê "auto", "none" ou um divisor numérico.
+func ParseKernelNormalization(s string) (KernelNormalization, error) {
+	switch s {
+	case "auto", "none":
+		return KernelNormalization{Mode: s}, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v == 0 {
+		return KernelNormalization{}, fmt.Errorf("normalização inválida %q, use auto, none ou um divisor diferente de zero", s)
+	}
+	return KernelNormalization{Mode: "divide", Divisor: v}, nil
+}
+
+// KernelAnchor é a âncora padrão: o centro do kernel, ou a posição logo
+// depois do meio em dimensões pares (como no OpenCV).
+func KernelAnchor(kernel [][]float64) image.Point {
+	return image.Pt(len(kernel[0])/2, len(kernel)/2)
+}
+
+// ConvolveFloat aplica um kernel KxL qualquer (linha = deslocamento
+// em y, coluna = em x, sem espelhar, como o sobel) e devolve a resposta sem
+// quantizar, já dividida por divisor. A âncora é a posição do kernel que
+// cai sobre o pixel de saída, então os raios acima/abaixo e à
+// esquerda/direita são independentes. Onde o kernel passa da borda os
+// pixels vêm de border. Kernels vazios ou não retangulares, âncoras fora do
+// kernel e divisor zero são erro.
+func ConvolveFloat(img *image.Gray, kernel [][]float64, anchor image.Point, divisor float64, border BorderMode) ([][]float64, error) {
+	if err := checkKernel(kernel, anchor); err != nil {
+		return nil, err
+	}
+	if divisor == 0 {
+		return nil, fmt.Errorf("divisor zero")
+	}
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	rows, cols := len(kernel), len(kernel[0])
+	top, left := anchor.Y, anchor.X
+	bottom, right := rows-1-top, cols-1-left
+	xs, ys := border.table(width, left, right), border.table(height, top, bottom)
+
+	result := make([][]float64, height)
+	for y := range result {
+		result[y] = make([]float64, width)
+	}
+	if width == 0 {
+		return result, nil
+	}
+	parallelRows(height, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := 0; x < width; x++ {
+				var sum float64
+				for j, row := range kernel {
+					sy := ys[y+j]
+					if sy < 0 {
+						continue
+					}
+					src := img.Pix[sy*img.Stride:]
+					for i, k := range row {
+						if sx := xs[x+i]; sx >= 0 {
+							sum += float64(src[sx]) * k
+						}
+					}
+				}
+				result[y][x] = sum / divisor
+			}
+		}
+	})
+	return result, nil
+}
+
+func checkKernel(kernel [][]float64, anchor image.Point) error {
+	if len(kernel) == 0 || len(kernel[0]) == 0 {
+		return fmt.Errorf("kernel vazio")
+	}
+	for i, row := range kernel {
+		if len(row) != len(kernel[0]) {
+			return fmt.Errorf("linha %d do kernel tem %d valores, esperado %d", i+1, len(row), len(kernel[0]))
+		}
+	}
+	if anchor.X < 0 || anchor.Y < 0 || anchor.X >= len(kernel[0]) || anchor.Y >= len(kernel) {
+		return fmt.Errorf("âncora (%d,%d) fora do kernel %dx%d", anchor.X, anchor.Y, len(kernel[0]), len(kernel))
+	}
+	return nil
+}
+
+// Convolve aplica o kernel com a âncora no centro, dividindo a soma pelo
+// divisor que norm define para ele e levando a resposta para 0–255 segundo
+// mode. Quem precisa da resposta sem quantizar (ex: cruzamentos de zero) usa
+// ConvolveFloat.
+func Convolve(img *image.Gray, kernel [][]float64, norm KernelNormalization, mode ResponseMode, border BorderMode) (*image.Gray, error) {
+	if len(kernel) == 0 {
+		return nil, fmt.Errorf("kernel vazio")
+	}
+	return ConvolveAnchor(img, kernel, KernelAnchor(kernel), norm, mode, border)
+}
+
+// ConvolveAnchor é Convolve com a âncora (a posição do kernel que cai sobre
+// o pixel de saída) explícita, útil em kernels pares.
+func ConvolveAnchor(img *image.Gray, kernel [][]float64, anchor image.Point, norm KernelNormalization, mode ResponseMode, border BorderMode) (*image.Gray, error) {
+	if len(kernel) == 0 {
+		return nil, fmt.Errorf("kernel vazio")
+	}
+	response, err := ConvolveFloat(img, kernel, anchor, norm.DivisorFor(kernel), border)
+	if err != nil {
+		return nil, err
+	}
+	return ResponseToGray(response, mode), nil
+}
+
+// ResponseMode define como a resposta (com sinal) de um filtro vira 0–255.
+type ResponseMode int
+
+const (
+	ResponseClamp  ResponseMode = iota // corta fora de [0, 255]
+	ResponseAbs                        // valor absoluto: bordas nos dois sentidos
+	ResponseOffset                     // soma 128: respostas com sinal, 128 = zero
+)
+
+// ParseResponseMode lê "clamp", "abs" ou "offset".
+func ParseResponseMode(s string) (ResponseMode, error) {
+	switch s {
+	case "clamp":
+		return ResponseClamp, nil
+	case "abs":
+		return ResponseAbs, nil
+	case "offset":
+		return ResponseOffset, nil
+	}
+	return 0, fmt.Errorf("modo de saída desconhecido %q, use clamp, abs ou offset", s)
+}
+
+// quantize leva um valor da resposta para 0–255 sem dar a volta: a
+// conversão direta de um float negativo para uint8 dá lixo.
+func (m ResponseMode) quantize(v float64) uint8 {
+	switch m {
+	case ResponseAbs:
+		v = math.Abs(v)
+	case ResponseOffset:
+		v += 128
+	}
+	return uint8(math.Round(math.Min(math.Max(v, 0), 255)))
+}
+
+// ResponseToGray converte a resposta do filtro para 0–255 segundo mode.
+func ResponseToGray(field [][]float64, mode ResponseMode) *image.Gray {
+	height := len(field)
+	width := 0
+	if height > 0 {
+		width = len(field[0])
+	}
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for y, row := range field {
+		for x, v := range row {
+			result.Pix[y*result.Stride+x] = mode.quantize(v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,279 @@
+package imaging
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+)
+
+// randomGray é uma imagem w×h de ruído uniforme com semente fixa.
+func randomGray(w, h int, seed int64) *image.Gray {
+	rng := rand.New(rand.NewSource(seed))
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(rng.Intn(256))
+	}
+	return img
+}
+
+func onesKernel(rows, cols int) [][]float64 {
+	kernel := make([][]float64, rows)
+	for i := range kernel {
+		kernel[i] = make([]float64, cols)
+		for j := range kernel[i] {
+			kernel[i][j] = 1
+		}
+	}
+	return kernel
+}
+
+func TestKernelNormalizationDivisor(t *testing.T) {
+	laplacian := [][]float64{{0, 1, 0}, {1, -4, 1}, {0, 1, 0}}
+	tests := []struct {
+		name   string
+		norm   string
+		kernel [][]float64
+		want   float64
+	}{
+		{"auto 5x5 de uns", "auto", onesKernel(5, 5), 25},
+		{"auto laplaciano de soma zero", "auto", laplacian, 1},
+		{"auto soma negativa", "auto", [][]float64{{-1, -1, -1}}, -3},
+		{"none", "none", onesKernel(5, 5), 1},
+		{"divisor explícito", "16", onesKernel(3, 3), 16},
+	}
+	for _, tt := range tests {
+		norm, err := ParseKernelNormalization(tt.norm)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if d := norm.DivisorFor(tt.kernel); d != tt.want {
+			t.Errorf("%s: divisor %g, esperado %g", tt.name, d, tt.want)
+		}
+	}
+	for _, s := range []string{"", "0", "soma"} {
+		if _, err := ParseKernelNormalization(s); err == nil {
+			t.Errorf("%q: sem erro", s)
+		}
+	}
+}
+
+func TestConvolveAutoMatchesExplicit(t *testing.T) {
+	img := randomGray(40, 30, 1)
+	auto, err := Convolve(img, onesKernel(5, 5), KernelNormalization{Mode: "auto"}, ResponseClamp, BorderReplicate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	explicit, err := Convolve(img, onesKernel(5, 5), KernelNormalization{Mode: "divide", Divisor: 25}, ResponseClamp, BorderReplicate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range auto.Pix {
+		if auto.Pix[i] != explicit.Pix[i] {
+			t.Fatalf("pixel %d: auto %d, /25 %d", i, auto.Pix[i], explicit.Pix[i])
+		}
+	}
+}
+
+func TestConvolveAutoKeepsConstant(t *testing.T) {
+	kernels := map[string][][]float64{
+		"box 3x3":       onesKernel(3, 3),
+		"binomial 1x5":  {{1, 4, 6, 4, 1}},
+		"gaussiano 3x3": {{1, 2, 1}, {2, 4, 2}, {1, 2, 1}},
+		"assimétrico":   {{0.5, 2}, {3, 0.25}},
+		"soma negativa": {{-1, -2, -1}},
+		"gaussiano σ=2": GaussianKernel(0, 2),
+	}
+	for _, border := range []BorderMode{BorderReplicate, BorderReflect, BorderWrap} {
+		for name, kernel := range kernels {
+			result, err := Convolve(uniformGray(16, 77), kernel, KernelNormalization{Mode: "auto"}, ResponseClamp, border)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			for i, v := range result.Pix {
+				if v != 77 {
+					t.Errorf("%s (borda %d): pixel %d = %d, esperado 77", name, border, i, v)
+					break
+				}
+			}
+		}
+	}
+}
+
+// referenceConvolve é a convolução direta, pixel a pixel, com a borda
+// replicada por clamp das coordenadas.
+func referenceConvolve(img *image.Gray, kernel [][]float64, anchor image.Point) [][]float64 {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	clamp := func(v, n int) int { return min(max(v, 0), n-1) }
+	result := make([][]float64, h)
+	for y := range result {
+		result[y] = make([]float64, w)
+		for x := range result[y] {
+			for j, row := range kernel {
+				for i, k := range row {
+					sx, sy := clamp(x+i-anchor.X, w), clamp(y+j-anchor.Y, h)
+					result[y][x] += k * float64(img.Pix[sy*img.Stride+sx])
+				}
+			}
+		}
+	}
+	return result
+}
+
+func TestConvolveRectangularKernels(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	random := func(rows, cols int) [][]float64 {
+		kernel := make([][]float64, rows)
+		for i := range kernel {
+			kernel[i] = make([]float64, cols)
+			for j := range kernel[i] {
+				kernel[i][j] = rng.Float64()*2 - 1
+			}
+		}
+		return kernel
+	}
+	img := randomGray(23, 17, 3)
+	tests := []struct {
+		name   string
+		kernel [][]float64
+		anchor image.Point
+	}{
+		{"1x3 só na linha", [][]float64{{1, 2, 3}}, image.Pt(1, 0)},
+		{"3x1 só na coluna", [][]float64{{1}, {2}, {3}}, image.Pt(0, 1)},
+		{"1x5 horizontal", onesKernel(1, 5), image.Pt(2, 0)},
+		{"3x5 aleatório", random(3, 5), image.Pt(2, 1)},
+		{"5x3 aleatório", random(5, 3), image.Pt(1, 2)},
+		{"3x5 âncora no canto", random(3, 5), image.Pt(4, 0)},
+		{"4x2 par", random(4, 2), image.Pt(1, 2)},
+	}
+	for _, tt := range tests {
+		got, err := ConvolveFloat(img, tt.kernel, tt.anchor, 1, BorderReplicate)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		want := referenceConvolve(img, tt.kernel, tt.anchor)
+	compare:
+		for y := range want {
+			for x := range want[y] {
+				if d := got[y][x] - want[y][x]; d > 1e-9 || d < -1e-9 {
+					t.Errorf("%s: (%d,%d) = %g, esperado %g", tt.name, x, y, got[y][x], want[y][x])
+					break compare
+				}
+			}
+		}
+	}
+}
+
+func TestConvolveEvenBoxAnchor(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 3, 3))
+	copy(img.Pix, []uint8{
+		10, 20, 30,
+		40, 50, 60,
+		70, 80, 90,
+	})
+	// com a âncora em (0,0) cada saída é a média do bloco 2x2 à direita e
+	// abaixo, replicando a última linha e coluna
+	want := [][]float64{
+		{30, 40, 45},
+		{60, 70, 75},
+		{75, 85, 90},
+	}
+	got, err := ConvolveFloat(img, onesKernel(2, 2), image.Pt(0, 0), 4, BorderReplicate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := range want {
+		for x := range want[y] {
+			if got[y][x] != want[y][x] {
+				t.Errorf("(%d,%d) = %g, esperado %g", x, y, got[y][x], want[y][x])
+			}
+		}
+	}
+	if a := KernelAnchor(onesKernel(2, 2)); a != image.Pt(1, 1) {
+		t.Errorf("âncora padrão do 2x2 %v, esperado (1,1)", a)
+	}
+}
+
+func TestConvolveKernelErrors(t *testing.T) {
+	img := uniformGray(4, 0)
+	tests := []struct {
+		name    string
+		kernel  [][]float64
+		anchor  image.Point
+		divisor float64
+	}{
+		{"vazio", nil, image.Pt(0, 0), 1},
+		{"não retangular", [][]float64{{1, 2}, {3}}, image.Pt(0, 0), 1},
+		{"âncora fora", onesKernel(3, 5), image.Pt(5, 0), 1},
+		{"âncora negativa", onesKernel(3, 3), image.Pt(0, -1), 1},
+		{"divisor zero", onesKernel(3, 3), image.Pt(1, 1), 0},
+	}
+	for _, tt := range tests {
+		if _, err := ConvolveFloat(img, tt.kernel, tt.anchor, tt.divisor, BorderReplicate); err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+		}
+	}
+}
+
+func TestConvolveNegativeKernel(t *testing.T) {
+	// kernel todo negativo: a resposta é −(soma dos vizinhos) e nunca pode
+	// dar a volta para valores altos
+	kernel := [][]float64{{-1, -1, -1}, {-1, -1, -1}, {-1, -1, -1}}
+	img := uniformGray(8, 100)
+	tests := []struct {
+		mode ResponseMode
+		want uint8
+	}{
+		{ResponseClamp, 0},
+		{ResponseAbs, 255},  // |−900| satura
+		{ResponseOffset, 0}, // −900 + 128 corta em 0
+	}
+	for _, tt := range tests {
+		got, err := Convolve(img, kernel, KernelNormalization{Mode: "none"}, tt.mode, BorderReplicate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, v := range got.Pix {
+			if v != tt.want {
+				t.Fatalf("modo %d: pixel %d = %d, esperado %d", tt.mode, i, v, tt.want)
+			}
+		}
+	}
+
+	// dividido pela soma (−9) volta a ser a média, positiva
+	got, err := Convolve(img, kernel, KernelNormalization{Mode: "auto"}, ResponseClamp, BorderReplicate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Pix[0] != 100 {
+		t.Errorf("auto: %d, esperado 100", got.Pix[0])
+	}
+
+	field, err := ConvolveFloat(img, kernel, image.Pt(1, 1), 1, BorderReplicate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field[3][3] != -900 {
+		t.Errorf("resposta sem quantizar %g, esperado −900", field[3][3])
+	}
+}
+
+func TestResponseQuantize(t *testing.T) {
+	tests := []struct {
+		v                  float64
+		clamp, abs, offset uint8
+	}{
+		{-300, 0, 255, 0},
+		{-100, 0, 100, 28},
+		{-0.4, 0, 0, 128},
+		{0, 0, 0, 128},
+		{50.5, 51, 51, 179},
+		{127, 127, 127, 255},
+		{400, 255, 255, 255},
+	}
+	for _, tt := range tests {
+		got := [3]uint8{ResponseClamp.quantize(tt.v), ResponseAbs.quantize(tt.v), ResponseOffset.quantize(tt.v)}
+		if want := [3]uint8{tt.clamp, tt.abs, tt.offset}; got != want {
+			t.Errorf("%g: clamp/abs/offset %v, esperado %v", tt.v, got, want)
+		}
+	}
+}
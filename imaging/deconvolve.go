@@ -0,0 +1,151 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"math/cmplx"
+
+	"processing-images/dft"
+)
+
+// WienerDeconvolve desfaz o borrão do kernel psf no domínio da frequência
+// pelo filtro de Wiener: F = H*·G / (|H|² + k), com G a transformada da
+// imagem e H a do psf. k é a razão ruído/sinal: 0 é o filtro inverso puro,
+// que explode o ruído onde H é pequeno; valores como 0.001–0.01 seguram
+// isso. psf segue a convenção de Convolve (sem espelhar, âncora de
+// KernelAnchor) e é normalizado pela soma, então devolve a imagem de antes
+// de Convolve(img, psf, ...). A imagem é estendida por reflexão até uma
+// potência de 2 (a FFT mais rápida do pacote dft) maior que ela mais o psf,
+// para a transformada (periódica) não juntar lados opostos, e recortada no
+// fim.
+func WienerDeconvolve(img *image.Gray, psf [][]float64, k float64) (*image.Gray, error) {
+	if !(k >= 0) {
+		return nil, fmt.Errorf("a razão ruído/sinal do Wiener deve ser >= 0, não %g", k)
+	}
+	return deconvolve(img, psf, func(g, h complex128) complex128 {
+		den := real(h)*real(h) + imag(h)*imag(h) + k
+		if den == 0 {
+			return 0
+		}
+		return cmplx.Conj(h) * g / complex(den, 0)
+	})
+}
+
+// InverseFilter é a deconvolução pelo filtro inverso G/H com corte: as
+// frequências em que |H| < threshold (em relação a |H| na frequência zero,
+// que é 1) são zeradas em vez de divididas, senão o ruído (até o da
+// quantização em 8 bits) explode. 0.05 é um bom começo; com ruído, prefira
+// WienerDeconvolve.
+func InverseFilter(img *image.Gray, psf [][]float64, threshold float64) (*image.Gray, error) {
+	if !(threshold > 0) {
+		return nil, fmt.Errorf("o corte do filtro inverso deve ser positivo, não %g", threshold)
+	}
+	return deconvolve(img, psf, func(g, h complex128) complex128 {
+		if cmplx.Abs(h) < threshold {
+			return 0
+		}
+		return g / h
+	})
+}
+
+// deconvolve transforma a imagem estendida e o psf, troca cada frequência
+// por restore(G, H) e volta.
+func deconvolve(img *image.Gray, psf [][]float64, restore func(g, h complex128) complex128) (*image.Gray, error) {
+	if len(psf) == 0 || len(psf[0]) == 0 {
+		return nil, fmt.Errorf("psf vazio")
+	}
+	var sum float64
+	for _, row := range psf {
+		if len(row) != len(psf[0]) {
+			return nil, fmt.Errorf("psf com linhas de tamanhos diferentes")
+		}
+		for _, v := range row {
+			sum += v
+		}
+	}
+	if math.Abs(sum) < 1e-12 {
+		return nil, fmt.Errorf("psf com soma zero não é um borrão")
+	}
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if width == 0 || height == 0 {
+		return image.NewGray(image.Rect(0, 0, width, height)), nil
+	}
+
+	pw, ph := nextPow2(width+len(psf[0])), nextPow2(height+len(psf))
+	g := reflectPadded(img, pw, ph)
+	h := make([][]complex128, ph)
+	for y := range h {
+		h[y] = make([]complex128, pw)
+	}
+	// Convolve soma psf[j][i]·f(x+i−ax, y+j−ay): é a convolução com o psf
+	// espelhado, então cada peso vai para a posição −(i−ax), −(j−ay)
+	anchor := KernelAnchor(psf)
+	for j, row := range psf {
+		for i, v := range row {
+			x := ((anchor.X-i)%pw + pw) % pw
+			y := ((anchor.Y-j)%ph + ph) % ph
+			h[y][x] += complex(v/sum, 0)
+		}
+	}
+	dft.FFT2(g)
+	dft.FFT2(h)
+	for y := range g {
+		for x := range g[y] {
+			g[y][x] = restore(g[y][x], h[y][x])
+		}
+	}
+	dft.IFFT2(g)
+	return realToGray(g, width, height), nil
+}
+
+// reflectPadded copia a imagem para uma matriz complexa pw×ph, estendida
+// por periodicReflect.
+func reflectPadded(img *image.Gray, pw, ph int) [][]complex128 {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	g := make([][]complex128, ph)
+	for y := range g {
+		g[y] = make([]complex128, pw)
+		sy := periodicReflect(y, height, ph)
+		for x := range g[y] {
+			g[y][x] = complex(float64(img.Pix[sy*img.Stride+periodicReflect(x, width, pw)]), 0)
+		}
+	}
+	return g
+}
+
+// realToGray recorta o canto width×height da parte real, saturando em
+// 0..255.
+func realToGray(g [][]complex128, width, height int) *image.Gray {
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			result.Pix[y*result.Stride+x] = uint8(math.Max(0, math.Min(255, math.Round(real(g[y][x])))))
+		}
+	}
+	return result
+}
+
+// nextPow2 é a menor potência de 2 >= n.
+func nextPow2(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// periodicReflect leva a coordenada i de [0, padded) para dentro de [0, n):
+// depois do fim a imagem é refletida pelo lado mais próximo (o direito, ou
+// o esquerdo dando a volta), então a extensão periódica não tem degraus.
+func periodicReflect(i, n, padded int) int {
+	if i < n {
+		return i
+	}
+	if i-n < padded-i {
+		idx, _ := BorderReflect.index(i, n)
+		return idx
+	}
+	idx, _ := BorderReflect.index(i-padded, n)
+	return idx
+}
@@ -0,0 +1,176 @@
+package imaging
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// blurred borra img com psf como a linha de comando faz (-ops convolve) e
+// soma ruído gaussiano de desvio sigma.
+func blurred(t *testing.T, img *image.Gray, psf [][]float64, sigma float64) *image.Gray {
+	t.Helper()
+	out, err := Convolve(img, psf, KernelNormalization{Mode: "auto"}, ResponseClamp, BorderReflect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sigma > 0 {
+		if out, err = synthetic.AddGaussianNoise(out, sigma, 12); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return out
+}
+
+func TestDeconvolveImprovesPSNR(t *testing.T) {
+	sharp := synthetic.SiemensStar(96, 80, 12)
+	for i, v := range sharp.Pix {
+		sharp.Pix[i] = 40 + v/255*170 // sem saturar no borrão nem no ruído
+	}
+	box := [][]float64{{1, 1, 1, 1, 1}, {1, 1, 1, 1, 1}, {1, 1, 1, 1, 1}}
+	tests := []struct {
+		name    string
+		psf     [][]float64
+		noise   float64
+		restore func(*image.Gray, [][]float64) (*image.Gray, error)
+		gain    float64 // ganho mínimo de PSNR sobre a imagem borrada, em dB
+	}{
+		{"wiener σ=1.5", GaussianKernel(0, 1.5), 1, func(img *image.Gray, psf [][]float64) (*image.Gray, error) {
+			return WienerDeconvolve(img, psf, 0.001)
+		}, 3},
+		{"wiener σ=2.5", GaussianKernel(0, 2.5), 1, func(img *image.Gray, psf [][]float64) (*image.Gray, error) {
+			return WienerDeconvolve(img, psf, 0.001)
+		}, 2.5},
+		{"wiener box 5x3", box, 1, func(img *image.Gray, psf [][]float64) (*image.Gray, error) {
+			return WienerDeconvolve(img, psf, 0.005)
+		}, 3},
+		{"wiener sem ruído", GaussianKernel(0, 1), 0, func(img *image.Gray, psf [][]float64) (*image.Gray, error) {
+			return WienerDeconvolve(img, psf, 0.0001)
+		}, 6},
+		{"inverso sem ruído", GaussianKernel(0, 1.5), 0, func(img *image.Gray, psf [][]float64) (*image.Gray, error) {
+			return InverseFilter(img, psf, 0.05)
+		}, 1.5},
+	}
+	for _, tt := range tests {
+		degraded := blurred(t, sharp, tt.psf, tt.noise)
+		restored, err := tt.restore(degraded, tt.psf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !restored.Bounds().Eq(sharp.Bounds()) {
+			t.Fatalf("%s: limites %v", tt.name, restored.Bounds())
+		}
+		before, after := grayPSNR(degraded, sharp), grayPSNR(restored, sharp)
+		if after < before+tt.gain {
+			t.Errorf("%s: PSNR %.1f dB restaurada, %.1f dB borrada (esperado ganho >= %g dB)", tt.name, after, before, tt.gain)
+		}
+	}
+}
+
+func TestWienerNoiseRegularizes(t *testing.T) {
+	// com ruído, k = 0 (o filtro inverso puro) explode o ruído onde o
+	// borrão é pequeno; um k pequeno segura
+	sharp := synthetic.Checkerboard(64, 64, 8)
+	psf := GaussianKernel(0, 2)
+	degraded := blurred(t, sharp, psf, 3)
+	pure, err := WienerDeconvolve(degraded, psf, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	regularized, err := WienerDeconvolve(degraded, psf, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, r := grayPSNR(pure, sharp), grayPSNR(regularized, sharp); r < p+5 {
+		t.Errorf("PSNR com k=0.01 %.1f dB, com k=0 %.1f dB", r, p)
+	}
+}
+
+func TestDeconvolveIdentity(t *testing.T) {
+	img := randomGray(23, 17, 3)
+	shift := [][]float64{{0, 0, 0}, {0, 0, 0}, {0, 0, 1}}
+	tests := []struct {
+		name string
+		img  *image.Gray
+		psf  [][]float64
+	}{
+		{"delta", img, [][]float64{{1}}},
+		{"delta escalado", img, [][]float64{{4}}},
+		{"recorte", randomGray(40, 30, 4).SubImage(image.Rect(6, 3, 29, 20)).(*image.Gray), [][]float64{{1}}},
+		// o deslocamento de Convolve é desfeito, menos na primeira linha e
+		// coluna, que ele jogou fora
+		{"deslocamento", blurred(t, img, shift, 0), shift},
+	}
+	for _, tt := range tests {
+		for _, restore := range []func(*image.Gray, [][]float64) (*image.Gray, error){
+			func(img *image.Gray, psf [][]float64) (*image.Gray, error) { return WienerDeconvolve(img, psf, 0) },
+			func(img *image.Gray, psf [][]float64) (*image.Gray, error) { return InverseFilter(img, psf, 0.05) },
+		} {
+			got, err := restore(tt.img, tt.psf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := tt.img
+			if tt.name == "deslocamento" {
+				want = img
+			}
+			b := want.Bounds()
+			for y := 1; y < b.Dy(); y++ {
+				for x := 1; x < b.Dx(); x++ {
+					if got.GrayAt(x, y) != want.GrayAt(b.Min.X+x, b.Min.Y+y) {
+						t.Fatalf("%s: pixel %d,%d vale %d, esperado %d", tt.name, x, y, got.GrayAt(x, y).Y, want.GrayAt(b.Min.X+x, b.Min.Y+y).Y)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDeconvolveErrors(t *testing.T) {
+	img := uniformGray(8, 50)
+	psfs := []struct {
+		name string
+		psf  [][]float64
+	}{
+		{"vazio", nil},
+		{"linha vazia", [][]float64{{}}},
+		{"irregular", [][]float64{{1, 1}, {1}}},
+		{"soma zero", [][]float64{{-1, 0, 1}}},
+	}
+	for _, tt := range psfs {
+		if _, err := WienerDeconvolve(img, tt.psf, 0.01); err == nil {
+			t.Errorf("wiener, psf %s: sem erro", tt.name)
+		}
+		if _, err := InverseFilter(img, tt.psf, 0.05); err == nil {
+			t.Errorf("inverso, psf %s: sem erro", tt.name)
+		}
+	}
+	for _, k := range []float64{-0.1, math.NaN()} {
+		if _, err := WienerDeconvolve(img, [][]float64{{1}}, k); err == nil {
+			t.Errorf("k=%g: sem erro", k)
+		}
+	}
+	for _, threshold := range []float64{0, -1, math.NaN()} {
+		if _, err := InverseFilter(img, [][]float64{{1}}, threshold); err == nil {
+			t.Errorf("corte %g: sem erro", threshold)
+		}
+	}
+}
+
+func TestPeriodicReflect(t *testing.T) {
+	// linha abcd estendida até 8: as posições 4 e 5 refletem o fim (c, b)
+	// e as 6 e 7 o começo dando a volta (c, b antes do a), sem degraus
+	want := []int{0, 1, 2, 3, 2, 1, 2, 1}
+	for i, w := range want {
+		if got := periodicReflect(i, 4, 8); got != w {
+			t.Errorf("posição %d: %d, esperado %d", i, got, w)
+		}
+	}
+	for _, tt := range [][2]int{{1, 1}, {2, 2}, {5, 8}, {8, 8}, {9, 16}} {
+		if got := nextPow2(tt[0]); got != tt[1] {
+			t.Errorf("nextPow2(%d) = %d, esperado %d", tt[0], got, tt[1])
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package imaging
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// noisyRegions é um degrau de 60 para 180 em x = 32 com ruído gaussiano de
+// desvio sigma.
+func noisyRegions(t *testing.T, sigma float64) *image.Gray {
+	t.Helper()
+	img := verticalStep(64, 32, 32)
+	for i, v := range img.Pix {
+		img.Pix[i] = 60 + v/255*120
+	}
+	noisy, err := synthetic.AddGaussianNoise(img, sigma, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return noisy
+}
+
+// regionVariance é a variância dos pixels com x em [x0, x1).
+func regionVariance(img *image.Gray, x0, x1 int) float64 {
+	var sum, sq, n float64
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := x0; x < x1; x++ {
+			v := float64(img.Pix[y*img.Stride+x])
+			sum += v
+			sq += v * v
+			n++
+		}
+	}
+	mean := sum / n
+	return sq/n - mean*mean
+}
+
+func TestAnisotropicDiffusionTwoRegions(t *testing.T) {
+	noisy := noisyRegions(t, 10)
+	tests := []struct {
+		conduction Conduction
+		kappa      float64
+	}{
+		{ConductionExponential, 20},
+		{ConductionExponential, 30},
+		{ConductionQuadratic, 15},
+	}
+	for _, tt := range tests {
+		got, err := AnisotropicDiffusion(noisy, 50, tt.kappa, 0.25, tt.conduction)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range [][2]int{{0, 29}, {35, 64}} {
+			before, after := regionVariance(noisy, r[0], r[1]), regionVariance(got, r[0], r[1])
+			if after > before/10 {
+				t.Errorf("condução %d, κ=%g: variância em x %d..%d caiu de %.1f para %.1f, esperado 10x menos", tt.conduction, tt.kappa, r[0], r[1], before, after)
+			}
+		}
+		for y := 0; y < 32; y++ {
+			edge := 0
+			for edge < 64 && got.Pix[y*got.Stride+edge] < 120 {
+				edge++
+			}
+			if edge < 31 || edge > 33 {
+				t.Errorf("condução %d, κ=%g: borda da linha %d em x=%d, esperado 32±1", tt.conduction, tt.kappa, y, edge)
+			}
+		}
+	}
+}
+
+func TestAnisotropicDiffusionConservesMean(t *testing.T) {
+	// sem fluxo na borda da imagem o brilho total não muda
+	noisy := noisyRegions(t, 20)
+	mean := func(img *image.Gray) float64 {
+		var sum float64
+		for _, v := range img.Pix {
+			sum += float64(v)
+		}
+		return sum / float64(len(img.Pix))
+	}
+	got, err := AnisotropicDiffusion(noisy, 30, 25, 0.2, ConductionQuadratic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := math.Abs(mean(got) - mean(noisy)); d > 0.5 {
+		t.Errorf("média mudou %.2f", d)
+	}
+}
+
+func TestAnisotropicDiffusionIdentity(t *testing.T) {
+	tests := []struct {
+		name       string
+		img        *image.Gray
+		iterations int
+	}{
+		{"zero iterações", randomGray(13, 9, 1), 0},
+		{"uniforme", uniformGray(12, 140), 40},
+		{"recorte sem iterações", randomGray(20, 20, 2).SubImage(image.Rect(4, 5, 15, 16)).(*image.Gray), 0},
+	}
+	for _, tt := range tests {
+		got, err := AnisotropicDiffusion(tt.img, tt.iterations, 20, 0.25, ConductionExponential)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := tt.img.Bounds()
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				if got.GrayAt(x, y) != tt.img.GrayAt(b.Min.X+x, b.Min.Y+y) {
+					t.Fatalf("%s: pixel %d,%d mudou", tt.name, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestAnisotropicDiffusionInvalidOptions(t *testing.T) {
+	img := uniformGray(8, 10)
+	tests := []struct {
+		name          string
+		iterations    int
+		kappa, lambda float64
+	}{
+		{"iterações negativas", -1, 20, 0.25},
+		{"κ zero", 10, 0, 0.25},
+		{"λ zero", 10, 20, 0},
+		{"λ instável", 10, 20, 0.26},
+		{"λ NaN", 10, 20, math.NaN()},
+	}
+	for _, tt := range tests {
+		if _, err := AnisotropicDiffusion(img, tt.iterations, tt.kappa, tt.lambda, ConductionExponential); err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+		}
+	}
+}
+
+func TestParseConduction(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Conduction
+		ok   bool
+	}{
+		{"exponential", ConductionExponential, true},
+		{"quadratic", ConductionQuadratic, true},
+		{"linear", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseConduction(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%q: %d, %v", tt.in, got, err)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// DistanceMetric escolhe a distância de DistanceTransform.
+type DistanceMetric int
+
+const (
+	DistanceEuclidean DistanceMetric = iota // exata (Felzenszwalb)
+	DistanceChamfer                         // chanfro 3-4, dividida por 3
+)
+
+// ParseDistanceMetric lê "euclidean" ou "chamfer".
+func ParseDistanceMetric(s string) (DistanceMetric, error) {
+	switch s {
+	case "euclidean":
+		return DistanceEuclidean, nil
+	case "chamfer":
+		return DistanceChamfer, nil
+	}
+	return 0, fmt.Errorf("métrica desconhecida %q, use euclidean ou chamfer", s)
+}
+
+// DistanceTransform devolve, para cada pixel de objeto, a distância até o
+// pixel de fundo mais próximo ([y][x], 0 no fundo). Como em CountObjects,
+// objeto é 0 e fundo o resto; invert troca os dois. Fora da imagem não
+// conta como fundo, então sem nenhum pixel de fundo tudo fica +Inf.
+// NormalizeToGray converte o resultado para exibição.
+func DistanceTransform(img *image.Gray, metric DistanceMetric, invert bool) [][]float64 {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	object := func(x, y int) bool {
+		return (img.Pix[img.PixOffset(img.Bounds().Min.X+x, img.Bounds().Min.Y+y)] == 0) != invert
+	}
+	if metric == DistanceChamfer {
+		return chamferDistance(width, height, object)
+	}
+	return euclideanDistance(width, height, object)
+}
+
+// chamferDistance faz as duas passadas do chanfro 3-4 (3 na horizontal e
+// vertical, 4 na diagonal) e divide por 3 para ficar em pixels.
+func chamferDistance(width, height int, object func(x, y int) bool) [][]float64 {
+	const inf = math.MaxInt32 / 2
+	d := make([][]int, height)
+	for y := range d {
+		d[y] = make([]int, width)
+		for x := range d[y] {
+			if object(x, y) {
+				d[y][x] = inf
+			}
+		}
+	}
+	relax := func(x, y, nx, ny, cost int) {
+		if nx >= 0 && ny >= 0 && nx < width && ny < height {
+			d[y][x] = min(d[y][x], d[ny][nx]+cost)
+		}
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			relax(x, y, x-1, y, 3)
+			relax(x, y, x-1, y-1, 4)
+			relax(x, y, x, y-1, 3)
+			relax(x, y, x+1, y-1, 4)
+		}
+	}
+	for y := height - 1; y >= 0; y-- {
+		for x := width - 1; x >= 0; x-- {
+			relax(x, y, x+1, y, 3)
+			relax(x, y, x+1, y+1, 4)
+			relax(x, y, x, y+1, 3)
+			relax(x, y, x-1, y+1, 4)
+		}
+	}
+
+	result := make([][]float64, height)
+	for y := range result {
+		result[y] = make([]float64, width)
+		for x, v := range d[y] {
+			if v >= inf {
+				result[y][x] = math.Inf(1)
+			} else {
+				result[y][x] = float64(v) / 3
+			}
+		}
+	}
+	return result
+}
+
+// euclideanDistance é a transformada exata de Felzenszwalb e Huttenlocher:
+// a distância ao quadrado é separável, então roda a envoltória inferior de
+// parábolas em cada coluna e depois em cada linha, O(n) no total.
+func euclideanDistance(width, height int, object func(x, y int) bool) [][]float64 {
+	// um valor finito no lugar de +Inf mantém a aritmética das parábolas
+	const far = 1e20
+	f := make([][]float64, height)
+	for y := range f {
+		f[y] = make([]float64, width)
+		for x := range f[y] {
+			if object(x, y) {
+				f[y][x] = far
+			}
+		}
+	}
+
+	n := max(width, height)
+	in, out := make([]float64, n), make([]float64, n)
+	v, z := make([]int, n), make([]float64, n+1)
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			in[y] = f[y][x]
+		}
+		squaredDistance1D(in[:height], out[:height], v, z)
+		for y := 0; y < height; y++ {
+			f[y][x] = out[y]
+		}
+	}
+	for y := 0; y < height; y++ {
+		copy(in, f[y])
+		squaredDistance1D(in[:width], f[y], v, z)
+		for x, d := range f[y] {
+			if d >= far/2 {
+				f[y][x] = math.Inf(1)
+			} else {
+				f[y][x] = math.Sqrt(d)
+			}
+		}
+	}
+	return f
+}
+
+// squaredDistance1D calcula out[q] = min_p (q−p)² + f[p] pela envoltória
+// inferior das parábolas; v e z são memória de trabalho.
+func squaredDistance1D(f, out []float64, v []int, z []float64) {
+	if len(f) == 0 {
+		return
+	}
+	k := 0
+	v[0] = 0
+	z[0], z[1] = math.Inf(-1), math.Inf(1)
+	for q := 1; q < len(f); q++ {
+		// z[0] = −Inf, então k nunca passa de 0 para baixo
+		s := intersection(f, q, v[k])
+		for s <= z[k] {
+			k--
+			s = intersection(f, q, v[k])
+		}
+		k++
+		v[k] = q
+		z[k], z[k+1] = s, math.Inf(1)
+	}
+	k = 0
+	for q := range out {
+		for z[k+1] < float64(q) {
+			k++
+		}
+		p := v[k]
+		out[q] = float64((q-p)*(q-p)) + f[p]
+	}
+}
+
+// intersection é onde a parábola de q cruza a de p.
+func intersection(f []float64, q, p int) float64 {
+	return ((f[q] + float64(q*q)) - (f[p] + float64(p*p))) / float64(2*(q-p))
+}
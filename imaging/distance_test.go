@@ -0,0 +1,142 @@
+package imaging
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// squareMask devolve uma imagem 20x20 de fundo 255 com o quadrado 10x10
+// [5,15) de objeto (0).
+func squareMask() *image.Gray {
+	img := uniformGray(20, 255)
+	for y := 5; y < 15; y++ {
+		for x := 5; x < 15; x++ {
+			img.Pix[y*img.Stride+x] = 0
+		}
+	}
+	return img
+}
+
+func TestDistanceTransformSquare(t *testing.T) {
+	// dentro do quadrado o fundo mais próximo está sempre na mesma linha ou
+	// coluna, então as duas métricas dão a distância até a borda mais perto
+	for _, metric := range []DistanceMetric{DistanceEuclidean, DistanceChamfer} {
+		d := DistanceTransform(squareMask(), metric, false)
+		for y := 0; y < 20; y++ {
+			for x := 0; x < 20; x++ {
+				want := 0.0
+				if x >= 5 && x < 15 && y >= 5 && y < 15 {
+					want = float64(min(x-4, 15-x, y-4, 15-y))
+				}
+				if math.Abs(d[y][x]-want) > 1e-9 {
+					t.Errorf("métrica %d em %d,%d: %g, esperado %g", metric, x, y, d[y][x], want)
+				}
+			}
+		}
+	}
+}
+
+func TestDistanceTransformPoint(t *testing.T) {
+	// um único pixel de fundo no canto: distâncias até ele
+	img := image.NewGray(image.Rect(0, 0, 10, 10))
+	img.Pix[0] = 255
+	tests := []struct {
+		x, y      int
+		euclidean float64
+		chamfer   float64
+	}{
+		{0, 0, 0, 0},
+		{1, 0, 1, 1},
+		{1, 1, math.Sqrt2, 4.0 / 3},
+		{3, 4, 5, 5},
+		{9, 9, 9 * math.Sqrt2, 12},
+		{9, 0, 9, 9},
+	}
+	euclidean := DistanceTransform(img, DistanceEuclidean, false)
+	chamfer := DistanceTransform(img, DistanceChamfer, false)
+	for _, tt := range tests {
+		if got := euclidean[tt.y][tt.x]; math.Abs(got-tt.euclidean) > 1e-9 {
+			t.Errorf("euclidiana em %d,%d: %g, esperado %g", tt.x, tt.y, got, tt.euclidean)
+		}
+		if got := chamfer[tt.y][tt.x]; math.Abs(got-tt.chamfer) > 1e-9 {
+			t.Errorf("chanfro em %d,%d: %g, esperado %g", tt.x, tt.y, got, tt.chamfer)
+		}
+	}
+}
+
+func TestDistanceTransformBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for n := 0; n < 10; n++ {
+		img := image.NewGray(image.Rect(0, 0, 17, 13))
+		for i := range img.Pix {
+			if rng.Intn(12) == 0 {
+				img.Pix[i] = 255
+			}
+		}
+		d := DistanceTransform(img, DistanceEuclidean, false)
+		for y := 0; y < 13; y++ {
+			for x := 0; x < 17; x++ {
+				want := math.Inf(1)
+				for by := 0; by < 13; by++ {
+					for bx := 0; bx < 17; bx++ {
+						if img.Pix[by*img.Stride+bx] != 0 {
+							want = math.Min(want, math.Hypot(float64(x-bx), float64(y-by)))
+						}
+					}
+				}
+				if d[y][x] != want && math.Abs(d[y][x]-want) > 1e-9 {
+					t.Fatalf("imagem %d em %d,%d: %g, esperado %g", n, x, y, d[y][x], want)
+				}
+			}
+		}
+	}
+}
+
+func TestDistanceTransformOptions(t *testing.T) {
+	square := squareMask()
+	inverted := DistanceTransform(square, DistanceEuclidean, true)
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"invertida: dentro do quadrado", inverted[9][9], 0},
+		{"invertida: vizinho do quadrado", inverted[9][4], 1},
+		{"invertida: canto", inverted[0][0], 5 * math.Sqrt2},
+		{"recorte: fora da imagem não é fundo", DistanceTransform(square.SubImage(image.Rect(5, 5, 20, 20)).(*image.Gray), DistanceEuclidean, false)[0][0], 10},
+		{"sem fundo", DistanceTransform(uniformGray(4, 0), DistanceChamfer, false)[2][2], math.Inf(1)},
+		{"sem fundo, euclidiana", DistanceTransform(uniformGray(4, 0), DistanceEuclidean, false)[0][3], math.Inf(1)},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want && math.Abs(tt.got-tt.want) > 1e-9 {
+			t.Errorf("%s: %g, esperado %g", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// para exibir, +Inf satura em 255 sem achatar o resto
+	shown := NormalizeToGray([][]float64{{0, 2, 4, math.Inf(1)}})
+	if got := shown.Pix[:4]; got[0] != 0 || got[1] != 128 || got[2] != 255 || got[3] != 255 {
+		t.Errorf("normalização %v", got)
+	}
+}
+
+func TestParseDistanceMetric(t *testing.T) {
+	tests := []struct {
+		s    string
+		want DistanceMetric
+		ok   bool
+	}{
+		{"euclidean", DistanceEuclidean, true},
+		{"chamfer", DistanceChamfer, true},
+		{"manhattan", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseDistanceMetric(tt.s)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%q: %v, %v", tt.s, got, err)
+		}
+	}
+}
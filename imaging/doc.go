@@ -0,0 +1,16 @@
+// Package imaging reúne os algoritmos do gotoshop que não dependem da linha
+// de comando: Canny, Otsu, Marr-Hildreth, watershed, contagem de objetos,
+// código de cadeia de Freeman, morfologia, filtros box, da mediana e de
+// ordem, convolução com kernels arbitrários, a transformada de distância,
+// transformações de intensidade, remoção de ruído, deconvolução no domínio
+// da frequência e a segmentação por faixas de intensidade.
+//
+// Tudo trabalha com *image.Gray e devolve imagens novas com origem em (0, 0).
+// A entrada pode começar em outro ponto, como a de um SubImage: o
+// resultado é o mesmo do recorte processado sozinho.
+// As funções que podem falhar por parâmetros inválidos devolvem erro em vez
+// de encerrar o processo.
+//
+//	binary := imaging.Otsu(img)
+//	edges, err := imaging.Canny(img, imaging.DefaultCannyOptions)
+package imaging
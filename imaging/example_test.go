@@ -0,0 +1,89 @@
+package imaging_test
+
+import (
+	"fmt"
+	"image"
+
+	"processing-images/imaging"
+)
+
+// squares é uma imagem 100x60 branca com dois quadrados pretos de 24 pixels.
+func squares() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 100, 60))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	for _, r := range []image.Rectangle{image.Rect(10, 10, 34, 34), image.Rect(60, 20, 84, 44)} {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				img.Pix[y*img.Stride+x] = 0
+			}
+		}
+	}
+	return img
+}
+
+func ExampleOtsu() {
+	binary := imaging.Otsu(squares())
+	dark := 0
+	for _, v := range binary.Pix {
+		if v == 0 {
+			dark++
+		}
+	}
+	fmt.Println("pixels pretos:", dark)
+	// Output: pixels pretos: 1152
+}
+
+func ExampleCountObjects() {
+	// sem a abertura e o fechamento os componentes são contados como estão
+	opts := imaging.CountObjectsOptions{MinArea: 10, Size: 1, Connectivity: 8}
+	n, err := imaging.CountObjects(imaging.Otsu(squares()), opts)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("objetos:", n)
+	// Output: objetos: 2
+}
+
+func ExampleCanny() {
+	edges, err := imaging.Canny(squares(), imaging.DefaultCannyOptions)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(edges.Bounds())
+	// Output: (0,0)-(100,60)
+}
+
+func ExampleFreemanChainCode() {
+	img := image.NewGray(image.Rect(0, 0, 6, 6))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	for y := 2; y < 4; y++ {
+		for x := 2; x < 4; x++ {
+			img.Pix[y*img.Stride+x] = 0
+		}
+	}
+	// 0 é para a direita, 2 para cima, 4 para a esquerda e 6 para baixo
+	chain, start, ok := imaging.FreemanChainCode(img)
+	fmt.Println(chain, start, ok)
+	// Output: [6 0 2 4] (2,2) true
+}
+
+func ExampleConvolve() {
+	// a resposta negativa do laplaciano vira 128 + resposta em vez de dar a
+	// volta em 8 bits
+	laplacian := [][]float64{{0, 1, 0}, {1, -4, 1}, {0, 1, 0}}
+	img := image.NewGray(image.Rect(0, 0, 3, 3))
+	img.Pix[4] = 10
+	out, err := imaging.Convolve(img, laplacian, imaging.KernelNormalization{Mode: "auto"}, imaging.ResponseOffset, imaging.BorderZero)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(out.Pix)
+	// Output: [128 138 128 138 88 138 128 138 128]
+}
@@ -0,0 +1,55 @@
+package imaging
+
+import (
+	"image"
+	"math"
+)
+
+// GrayToFloat copia os pixels para uma matriz [y][x] de ponto flutuante.
+func GrayToFloat(img *image.Gray) [][]float64 {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	field := make([][]float64, height)
+	for y := 0; y < height; y++ {
+		field[y] = make([]float64, width)
+		src := img.Pix[img.PixOffset(img.Bounds().Min.X, img.Bounds().Min.Y+y):]
+		for x := 0; x < width; x++ {
+			field[y][x] = float64(src[x])
+		}
+	}
+	return field
+}
+
+// FloatToGray mapeia [0, maxValue] para 0..255, cortando o que sai da faixa.
+func FloatToGray(field [][]float64, maxValue float64) *image.Gray {
+	height := len(field)
+	width := 0
+	if height > 0 {
+		width = len(field[0])
+	}
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := field[y][x] / maxValue * 255
+			result.Pix[y*result.Stride+x] = uint8(math.Max(0, math.Min(255, math.Round(v))))
+		}
+	}
+	return result
+}
+
+// NormalizeToGray mapeia [0, máximo do campo] para 0..255. Valores
+// infinitos (ex: DistanceTransform sem fundo) ficam fora do máximo e saem 255.
+func NormalizeToGray(field [][]float64) *image.Gray {
+	var maxValue float64
+	for _, row := range field {
+		for _, v := range row {
+			if math.IsInf(v, 1) {
+				continue
+			}
+			maxValue = math.Max(maxValue, v)
+		}
+	}
+	if maxValue == 0 {
+		maxValue = 1
+	}
+	return FloatToGray(field, maxValue)
+}
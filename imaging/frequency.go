@@ -0,0 +1,89 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"processing-images/dft"
+)
+
+// FrequencyFilterKind escolhe a função de transferência de FrequencyFilter.
+type FrequencyFilterKind int
+
+const (
+	FilterIdeal       FrequencyFilterKind = iota // corte seco: 1 até o raio, 0 depois (dá anéis)
+	FilterButterworth                            // 1/(1 + (D/D0)^2n): a ordem n controla a inclinação
+	FilterGaussian                               // exp(−D²/2D0²): sem anéis
+)
+
+// ParseFrequencyFilterKind lê "ideal", "butterworth" ou "gaussian".
+func ParseFrequencyFilterKind(s string) (FrequencyFilterKind, error) {
+	switch s {
+	case "ideal":
+		return FilterIdeal, nil
+	case "butterworth":
+		return FilterButterworth, nil
+	case "gaussian":
+		return FilterGaussian, nil
+	}
+	return 0, fmt.Errorf("filtro de frequência desconhecido %q, use ideal, butterworth ou gaussian", s)
+}
+
+// FrequencyFilter filtra a imagem no domínio da frequência com o passa-
+// baixas kind de raio cutoff (ou o passa-altas, 1 − passa-baixas, com
+// highpass); order só vale para o Butterworth. A distância D de cada
+// frequência ao centro é medida como no espectro da imagem original
+// (dft.SpectrumImage): cutoff 30 mantém o que está a até 30 pixels do
+// centro de spectrum.png. A imagem é estendida por reflexão até o dobro
+// (em potência de 2) antes da transformada, para os lados opostos não se
+// misturarem, e recortada no fim; o resultado satura em 0..255, então o
+// passa-altas, que tira a média, sai escuro com só as bordas claras.
+func FrequencyFilter(img *image.Gray, kind FrequencyFilterKind, cutoff float64, order int, highpass bool) (*image.Gray, error) {
+	if !(cutoff > 0) {
+		return nil, fmt.Errorf("o raio de corte deve ser positivo, não %g", cutoff)
+	}
+	if kind == FilterButterworth && order < 1 {
+		return nil, fmt.Errorf("a ordem do Butterworth deve ser pelo menos 1, não %d", order)
+	}
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if width == 0 || height == 0 {
+		return image.NewGray(image.Rect(0, 0, width, height)), nil
+	}
+	pw, ph := nextPow2(2*width), nextPow2(2*height)
+	g := reflectPadded(img, pw, ph)
+	dft.FFT2(g)
+	for v, row := range g {
+		fv := spectrumFrequency(v, ph, height)
+		for u := range row {
+			d := math.Hypot(spectrumFrequency(u, pw, width), fv)
+			var h float64
+			switch kind {
+			case FilterIdeal:
+				if d <= cutoff {
+					h = 1
+				}
+			case FilterButterworth:
+				h = 1 / (1 + math.Pow(d/cutoff, 2*float64(order)))
+			default:
+				h = math.Exp(-d * d / (2 * cutoff * cutoff))
+			}
+			if highpass {
+				h = 1 - h
+			}
+			row[u] *= complex(h, 0)
+		}
+	}
+	dft.IFFT2(g)
+	return realToGray(g, width, height), nil
+}
+
+// spectrumFrequency converte o índice i de uma transformada de tamanho
+// padded (de uma imagem de tamanho n estendida) na frequência com sinal em
+// pixels do espectro da imagem original.
+func spectrumFrequency(i, padded, n int) float64 {
+	if i > padded/2 {
+		i -= padded
+	}
+	return float64(i) * float64(n) / float64(padded)
+}
@@ -0,0 +1,174 @@
+package imaging
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+// sinusoids soma a 128 senoides verticais de c ciclos na largura e
+// amplitude a, {c, a} em waves.
+func sinusoids(width, height int, waves ...[2]float64) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := 128.0
+			for _, w := range waves {
+				v += w[1] * math.Cos(2*math.Pi*w[0]*float64(x)/float64(width))
+			}
+			img.Pix[y*img.Stride+x] = uint8(math.Max(0, math.Min(255, math.Round(v))))
+		}
+	}
+	return img
+}
+
+// maxDiff é a maior diferença absoluta entre os pixels de a e b a pelo
+// menos margin pixels da borda.
+func maxDiff(a, b *image.Gray, margin int) int {
+	worst := 0
+	for y := margin; y < a.Bounds().Dy()-margin; y++ {
+		for x := margin; x < a.Bounds().Dx()-margin; x++ {
+			d := int(a.GrayAt(x, y).Y) - int(b.GrayAt(x, y).Y)
+			worst = max(worst, d, -d)
+		}
+	}
+	return worst
+}
+
+func TestFrequencyFilterSeparatesSinusoids(t *testing.T) {
+	// 4 ciclos (baixa) mais 40 ciclos (alta) em 128 pixels: no espectro elas
+	// ficam a 4 e a 40 pixels do centro
+	low, high := [2]float64{4, 40}, [2]float64{40, 30}
+	img := sinusoids(128, 64, low, high)
+	tests := []struct {
+		name     string
+		kind     FrequencyFilterKind
+		cutoff   float64
+		order    int
+		highpass bool
+		want     *image.Gray
+		tol      int
+	}{
+		{"ideal passa-baixas", FilterIdeal, 20, 0, false, sinusoids(128, 64, low), 2},
+		{"butterworth passa-baixas", FilterButterworth, 15, 2, false, sinusoids(128, 64, low), 2},
+		{"gaussiano passa-baixas", FilterGaussian, 15, 0, false, sinusoids(128, 64, low), 3},
+		// sem a média sobra só a alta, com os vales cortados em 0
+		{"ideal passa-altas", FilterIdeal, 20, 0, true, sinusoids(128, 64, [2]float64{0, -128}, high), 2},
+	}
+	for _, tt := range tests {
+		got, err := FrequencyFilter(img, tt.kind, tt.cutoff, tt.order, tt.highpass)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// a reflexão quebra a senoide alta na borda, então as 4 colunas de
+		// cada lado ficam de fora
+		if d := maxDiff(got, tt.want, 4); d > tt.tol {
+			t.Errorf("%s: diferença %d da senoide esperada (tolerância %d)", tt.name, d, tt.tol)
+		}
+	}
+
+	// com o corte abaixo das duas, o passa-baixas deixa só a média (a
+	// extensão espalha um pouco da baixa para dentro do raio)
+	flat, err := FrequencyFilter(img, FilterIdeal, 2, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := maxDiff(flat, sinusoids(128, 64), 4); d > 4 {
+		t.Errorf("corte 2: diferença %d da média", d)
+	}
+}
+
+func TestFrequencyFilterUniform(t *testing.T) {
+	img := uniformGray(24, 90)
+	for _, kind := range []FrequencyFilterKind{FilterIdeal, FilterButterworth, FilterGaussian} {
+		low, err := FrequencyFilter(img, kind, 5, 1, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		highpass, err := FrequencyFilter(img, kind, 5, 1, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range img.Pix {
+			if low.Pix[i] != 90 || highpass.Pix[i] != 0 {
+				t.Fatalf("filtro %d: pixel %d passa-baixas %d e passa-altas %d, esperado 90 e 0", kind, i, low.Pix[i], highpass.Pix[i])
+			}
+		}
+	}
+}
+
+func TestFrequencyFilterSubImage(t *testing.T) {
+	img := randomGray(50, 40, 6)
+	sub := img.SubImage(image.Rect(10, 5, 42, 37)).(*image.Gray)
+	copied := image.NewGray(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		copy(copied.Pix[y*copied.Stride:], sub.Pix[y*sub.Stride:y*sub.Stride+32])
+	}
+	a, err := FrequencyFilter(sub, FilterGaussian, 8, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := FrequencyFilter(copied, FilterGaussian, 8, 0, false)
+	if a.Bounds() != b.Bounds() || maxDiff(a, b, 0) != 0 {
+		t.Error("recorte filtrado diferente da cópia")
+	}
+}
+
+func TestFrequencyFilterErrors(t *testing.T) {
+	img := uniformGray(8, 1)
+	tests := []struct {
+		name   string
+		kind   FrequencyFilterKind
+		cutoff float64
+		order  int
+	}{
+		{"corte zero", FilterIdeal, 0, 1},
+		{"corte negativo", FilterGaussian, -3, 1},
+		{"corte NaN", FilterIdeal, math.NaN(), 1},
+		{"ordem zero", FilterButterworth, 10, 0},
+	}
+	for _, tt := range tests {
+		if _, err := FrequencyFilter(img, tt.kind, tt.cutoff, tt.order, false); err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+		}
+	}
+}
+
+func TestParseFrequencyFilterKind(t *testing.T) {
+	tests := []struct {
+		in   string
+		want FrequencyFilterKind
+		ok   bool
+	}{
+		{"ideal", FilterIdeal, true},
+		{"butterworth", FilterButterworth, true},
+		{"gaussian", FilterGaussian, true},
+		{"box", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseFrequencyFilterKind(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%q: %d, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestSpectrumFrequency(t *testing.T) {
+	// transformada de 256 de uma imagem de 100: o índice 64 é um quarto da
+	// banda, 25 pixels no espectro de 100; depois da metade vira negativo
+	tests := []struct {
+		i    int
+		want float64
+	}{
+		{0, 0},
+		{64, 25},
+		{128, 50},
+		{192, -25},
+		{255, -100.0 / 256},
+	}
+	for _, tt := range tests {
+		if got := spectrumFrequency(tt.i, 256, 100); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("índice %d: %g, esperado %g", tt.i, got, tt.want)
+		}
+	}
+}
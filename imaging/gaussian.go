@@ -0,0 +1,75 @@
+package imaging
+
+import (
+	"image"
+	"math"
+)
+
+// gaussianKernel1D devolve um kernel gaussiano normalizado de raio ceil(3σ).
+func gaussianKernel1D(sigma float64) []float64 {
+	radius := int(math.Ceil(3 * sigma))
+	if radius < 1 {
+		radius = 1
+	}
+	kernel := make([]float64, 2*radius+1)
+	var sum float64
+	for i := -radius; i <= radius; i++ {
+		v := math.Exp(-float64(i*i) / (2 * sigma * sigma))
+		kernel[i+radius] = v
+		sum += v
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+	return kernel
+}
+
+// GaussianBlur borra a imagem com a gaussiana de desvio sigma: um kernel 1D
+// de raio ceil(3σ) na horizontal e depois na vertical, O(σ) por pixel em vez
+// de O(σ²) da convolução 2D, replicando as bordas. sigma <= 0 devolve uma
+// cópia.
+func GaussianBlur(img *image.Gray, sigma float64) *image.Gray {
+	return FloatToGray(SmoothFloat(GrayToFloat(img), sigma), 255)
+}
+
+// SmoothFloat aplica a gaussiana separável num campo de ponto flutuante,
+// replicando as bordas.
+func SmoothFloat(field [][]float64, sigma float64) [][]float64 {
+	height := len(field)
+	if height == 0 || sigma <= 0 {
+		return field
+	}
+	width := len(field[0])
+	kernel := gaussianKernel1D(sigma)
+	radius := len(kernel) / 2
+
+	clamp := func(v, n int) int {
+		return min(max(v, 0), n-1)
+	}
+
+	tmp := make([][]float64, height)
+	for y := 0; y < height; y++ {
+		tmp[y] = make([]float64, width)
+		for x := 0; x < width; x++ {
+			var sum float64
+			for k := -radius; k <= radius; k++ {
+				sum += field[y][clamp(x+k, width)] * kernel[k+radius]
+			}
+			tmp[y][x] = sum
+		}
+	}
+
+	result := make([][]float64, height)
+	for y := 0; y < height; y++ {
+		result[y] = make([]float64, width)
+		for x := 0; x < width; x++ {
+			var sum float64
+			for k := -radius; k <= radius; k++ {
+				sum += tmp[clamp(y+k, height)][x] * kernel[k+radius]
+			}
+			result[y][x] = sum
+		}
+	}
+
+	return result
+}
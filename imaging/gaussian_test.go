@@ -0,0 +1,127 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// referenceGaussian2D convolui com o kernel gaussiano 2D inteiro de raio
+// ceil(3σ), replicando as bordas.
+func referenceGaussian2D(img *image.Gray, sigma float64) *image.Gray {
+	field := GrayToFloat(img)
+	height, width := len(field), len(field[0])
+	k := gaussianKernel1D(sigma)
+	radius := len(k) / 2
+	result := make([][]float64, height)
+	for y := range result {
+		result[y] = make([]float64, width)
+		for x := range result[y] {
+			var sum float64
+			for j := -radius; j <= radius; j++ {
+				for i := -radius; i <= radius; i++ {
+					yy := min(max(y+j, 0), height-1)
+					xx := min(max(x+i, 0), width-1)
+					sum += field[yy][xx] * k[i+radius] * k[j+radius]
+				}
+			}
+			result[y][x] = sum
+		}
+	}
+	return FloatToGray(result, 255)
+}
+
+func TestGaussianKernel1D(t *testing.T) {
+	tests := []struct {
+		sigma  float64
+		radius int
+	}{
+		{0.1, 1},
+		{0.5, 2},
+		{1, 3},
+		{1.4, 5},
+		{3.7, 12},
+	}
+	for _, tt := range tests {
+		k := gaussianKernel1D(tt.sigma)
+		if len(k) != 2*tt.radius+1 {
+			t.Errorf("σ=%g: raio %d, esperado %d", tt.sigma, len(k)/2, tt.radius)
+			continue
+		}
+		var sum float64
+		for i, v := range k {
+			sum += v
+			if v != k[len(k)-1-i] {
+				t.Errorf("σ=%g: kernel não simétrico", tt.sigma)
+			}
+		}
+		if math.Abs(sum-1) > 1e-12 {
+			t.Errorf("σ=%g: soma %g", tt.sigma, sum)
+		}
+	}
+}
+
+func TestGaussianBlurMatches2D(t *testing.T) {
+	star := synthetic.SiemensStar(90, 70, 12)
+	for _, sigma := range []float64{0.5, 1, 1.4, 2, 3.7} {
+		got := GaussianBlur(star, sigma)
+		want := referenceGaussian2D(star, sigma)
+		for i := range got.Pix {
+			if d := int(got.Pix[i]) - int(want.Pix[i]); d < -1 || d > 1 {
+				t.Errorf("σ=%g: pixel %d = %d, esperado %d±1", sigma, i, got.Pix[i], want.Pix[i])
+				break
+			}
+		}
+	}
+}
+
+func TestGaussianBlurEdgeCases(t *testing.T) {
+	star := synthetic.SiemensStar(60, 40, 8)
+	sub := star.SubImage(image.Rect(10, 5, 50, 35)).(*image.Gray)
+	tests := []struct {
+		name  string
+		img   *image.Gray
+		sigma float64
+		want  *image.Gray
+	}{
+		{"constante", uniformGray(20, 77), 3, uniformGray(20, 77)},
+		{"σ zero copia", star, 0, star},
+		{"σ negativo copia", star, -1, star},
+		{"recorte", sub, 1.5, referenceGaussian2D(sub, 1.5)},
+	}
+	for _, tt := range tests {
+		got := GaussianBlur(tt.img, tt.sigma)
+		if got.Bounds() != image.Rect(0, 0, tt.want.Bounds().Dx(), tt.want.Bounds().Dy()) {
+			t.Errorf("%s: limites %v", tt.name, got.Bounds())
+			continue
+		}
+		for y := 0; y < got.Bounds().Dy(); y++ {
+			for x := 0; x < got.Bounds().Dx(); x++ {
+				w := tt.want.GrayAt(tt.want.Bounds().Min.X+x, tt.want.Bounds().Min.Y+y).Y
+				if d := int(got.GrayAt(x, y).Y) - int(w); d < -1 || d > 1 {
+					t.Fatalf("%s: pixel %d,%d = %d, esperado %d", tt.name, x, y, got.GrayAt(x, y).Y, w)
+				}
+			}
+		}
+	}
+}
+
+// go test -bench Gaussian -run ^$ compara a versão separável com o kernel 2D.
+func BenchmarkGaussianBlur(b *testing.B) {
+	img := synthetic.SiemensStar(400, 300, 24)
+	for _, sigma := range []float64{2, 4} {
+		b.Run(fmt.Sprintf("separavel/sigma=%g", sigma), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				GaussianBlur(img, sigma)
+			}
+		})
+		b.Run(fmt.Sprintf("2d/sigma=%g", sigma), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				referenceGaussian2D(img, sigma)
+			}
+		})
+	}
+}
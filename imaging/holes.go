@@ -0,0 +1,66 @@
+package imaging
+
+import "image"
+
+// FillHoles preenche os buracos dos objetos (o furo de uma arruela, o miolo
+// de um "O"): o fundo é inundado a partir da borda da imagem, com
+// vizinhança 4, e todo pixel de fundo que a inundação não alcança vira
+// objeto. Um "buraco" aberto para a borda é fundo e fica como está.
+// polarity diz qual cor é objeto, como em Erode.
+func FillHoles(img *image.Gray, polarity Polarity) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	fill := uint8(0)
+	if polarity == WhiteObjects {
+		fill = 255
+	}
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	background := make([]bool, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := img.Pix[y*img.Stride+x]
+			result.Pix[y*result.Stride+x] = v
+			background[y*width+x] = (v == 0) == (polarity == WhiteObjects)
+		}
+	}
+
+	reached := make([]bool, width*height)
+	var stack []int
+	push := func(x, y int) {
+		if p := y*width + x; background[p] && !reached[p] {
+			reached[p] = true
+			stack = append(stack, p)
+		}
+	}
+	for x := 0; x < width; x++ {
+		push(x, 0)
+		push(x, height-1)
+	}
+	for y := 0; y < height; y++ {
+		push(0, y)
+		push(width-1, y)
+	}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		x, y := p%width, p/width
+		if x > 0 {
+			push(x-1, y)
+		}
+		if x < width-1 {
+			push(x+1, y)
+		}
+		if y > 0 {
+			push(x, y-1)
+		}
+		if y < height-1 {
+			push(x, y+1)
+		}
+	}
+
+	for p, bg := range background {
+		if bg && !reached[p] {
+			result.Pix[(p/width)*result.Stride+p%width] = fill
+		}
+	}
+	return result
+}
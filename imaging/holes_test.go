@@ -0,0 +1,128 @@
+package imaging
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+// parseMask lê uma máscara desenhada com '#' (objeto) e '.' (fundo); o
+// objeto sai com o valor object e o fundo com o outro extremo.
+func parseMask(art string, object uint8) *image.Gray {
+	lines := strings.Fields(art)
+	img := image.NewGray(image.Rect(0, 0, len(lines[0]), len(lines)))
+	for y, line := range lines {
+		for x, c := range line {
+			v := 255 - object
+			if c == '#' {
+				v = object
+			}
+			img.Pix[y*img.Stride+x] = v
+		}
+	}
+	return img
+}
+
+func TestFillHoles(t *testing.T) {
+	tests := []struct {
+		name     string
+		in, want string
+	}{
+		{
+			"anel",
+			`.......
+			 .#####.
+			 .#...#.
+			 .#...#.
+			 .#####.
+			 .......`,
+			`.......
+			 .#####.
+			 .#####.
+			 .#####.
+			 .#####.
+			 .......`,
+		},
+		{
+			"aberto para a borda",
+			`#####
+			 #...#
+			 #....
+			 #####`,
+			`#####
+			 #...#
+			 #....
+			 #####`,
+		},
+		{
+			// só a diagonal liga o miolo ao lado de fora: com vizinhança 4
+			// o fundo não passa
+			"fresta diagonal",
+			`......
+			 .###..
+			 .#.#..
+			 .##.#.
+			 ....#.`,
+			`......
+			 .###..
+			 .###..
+			 .##.#.
+			 ....#.`,
+		},
+		{
+			"anéis encaixados",
+			`.........
+			 .#######.
+			 .#.....#.
+			 .#.###.#.
+			 .#.#.#.#.
+			 .#.###.#.
+			 .#.....#.
+			 .#######.
+			 .........`,
+			`.........
+			 .#######.
+			 .#######.
+			 .#######.
+			 .#######.
+			 .#######.
+			 .#######.
+			 .#######.
+			 .........`,
+		},
+		{"sem objetos", "....", "...."},
+		{"só objeto", "## ##", "## ##"},
+	}
+	for _, tt := range tests {
+		for _, polarity := range []Polarity{BlackObjects, WhiteObjects} {
+			object := uint8(0)
+			if polarity == WhiteObjects {
+				object = 255
+			}
+			got := FillHoles(parseMask(tt.in, object), polarity)
+			want := parseMask(tt.want, object)
+			for i := range want.Pix {
+				if got.Pix[i] != want.Pix[i] {
+					t.Errorf("%s, polaridade %d: pixel %d,%d = %d, esperado %d", tt.name, polarity,
+						i%want.Stride, i/want.Stride, got.Pix[i], want.Pix[i])
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestFillHolesKeepsGrayBackground(t *testing.T) {
+	// com objetos pretos, qualquer nível diferente de 0 é fundo e o que a
+	// inundação alcança fica com o valor original
+	img := parseMask(`.....
+	                  .###.
+	                  .#.#.
+	                  .###.
+	                  .....`, 0)
+	img.Pix[0] = 128
+	sub := FillHoles(img.SubImage(image.Rect(0, 0, 5, 5)).(*image.Gray), BlackObjects)
+	if sub.Pix[0] != 128 || sub.Pix[2*sub.Stride+2] != 0 {
+		t.Errorf("canto %d e miolo %d, esperado 128 e 0", sub.Pix[0], sub.Pix[2*sub.Stride+2])
+	}
+}
@@ -0,0 +1,87 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// As transformações de intensidade abaixo são pontuais: cada nível de cinza
+// vira outro, sempre o mesmo, então a conta é feita uma vez para os 256
+// níveis numa tabela e a imagem só consulta a tabela.
+
+// GammaCorrect aplica a lei de potência 255·(v/255)^(1/gamma): gamma > 1
+// clareia os tons médios (2.0 leva 128 a 181), gamma < 1 escurece e 1 não
+// muda nada.
+func GammaCorrect(img *image.Gray, gamma float64) (*image.Gray, error) {
+	if gamma <= 0 || math.IsNaN(gamma) || math.IsInf(gamma, 0) {
+		return nil, fmt.Errorf("gamma deve ser positivo, não %g", gamma)
+	}
+	var lut [256]uint8
+	for v := range lut {
+		lut[v] = uint8(math.Round(255 * math.Pow(float64(v)/255, 1/gamma)))
+	}
+	return applyLUT(img, lut), nil
+}
+
+// LogTransform aplica c·log(1 + v), com c escolhido para 255 continuar 255:
+// abre os tons escuros e comprime os claros (ex: espectros de Fourier).
+func LogTransform(img *image.Gray) *image.Gray {
+	var lut [256]uint8
+	c := 255 / math.Log(256)
+	for v := range lut {
+		lut[v] = uint8(math.Round(c * math.Log1p(float64(v))))
+	}
+	return applyLUT(img, lut)
+}
+
+// Negative devolve o negativo, 255 − v.
+func Negative(img *image.Gray) *image.Gray {
+	var lut [256]uint8
+	for v := range lut {
+		lut[v] = uint8(255 - v)
+	}
+	return applyLUT(img, lut)
+}
+
+// applyLUT troca cada pixel pelo seu valor na tabela.
+func applyLUT(img *image.Gray, lut [256]uint8) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		dst := result.Pix[y*result.Stride : y*result.Stride+width]
+		for x, v := range img.Pix[y*img.Stride : y*img.Stride+width] {
+			dst[x] = lut[v]
+		}
+	}
+	return result
+}
+
+// ContrastStretch estica o contraste linearmente: os níveis nos percentis
+// lowPct e highPct do histograma (em %, ex: 1 e 99) vão para 0 e 255 e o
+// que está fora deles satura. Ignorar as pontas evita que uns poucos
+// pixels extremos segurem o esticamento. Se os dois percentis caem no
+// mesmo nível (imagem constante, lowPct == highPct) não há o que esticar e
+// a imagem volta como está.
+func ContrastStretch(img *image.Gray, lowPct, highPct float64) (*image.Gray, error) {
+	if !(lowPct >= 0 && highPct <= 100 && lowPct <= highPct) {
+		return nil, fmt.Errorf("percentis inválidos %g e %g, use 0 ≤ baixo ≤ alto ≤ 100", lowPct, highPct)
+	}
+	histogram := Histogram(img)
+	low := int(HistogramPercentile(histogram, lowPct/100))
+	high := int(HistogramPercentile(histogram, highPct/100))
+	var lut [256]uint8
+	for v := range lut {
+		switch {
+		case high <= low:
+			lut[v] = uint8(v)
+		case v <= low:
+			lut[v] = 0
+		case v >= high:
+			lut[v] = 255
+		default:
+			lut[v] = uint8(math.Round(float64(v-low) * 255 / float64(high-low)))
+		}
+	}
+	return applyLUT(img, lut), nil
+}
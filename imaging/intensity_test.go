@@ -0,0 +1,165 @@
+package imaging
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+// levels devolve uma imagem 256x1 com os níveis 0..255 em ordem.
+func levels() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 256, 1))
+	for v := range img.Pix {
+		img.Pix[v] = uint8(v)
+	}
+	return img
+}
+
+func TestPointTransforms(t *testing.T) {
+	gamma := func(g float64) func(*image.Gray) *image.Gray {
+		return func(img *image.Gray) *image.Gray {
+			result, err := GammaCorrect(img, g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return result
+		}
+	}
+	tests := []struct {
+		name  string
+		op    func(*image.Gray) *image.Gray
+		pairs [][2]uint8
+	}{
+		{"gamma 2", gamma(2), [][2]uint8{{0, 0}, {64, 128}, {128, 181}, {255, 255}}},
+		{"gamma 0.5", gamma(0.5), [][2]uint8{{0, 0}, {128, 64}, {200, 157}, {255, 255}}},
+		{"gamma 2.2", gamma(2.2), [][2]uint8{{50, 122}, {128, 186}}},
+		{"gamma 1", gamma(1), [][2]uint8{{0, 0}, {77, 77}, {255, 255}}},
+		{"log", LogTransform, [][2]uint8{{0, 0}, {1, 32}, {3, 64}, {255, 255}}},
+		{"negativo", Negative, [][2]uint8{{0, 255}, {100, 155}, {255, 0}}},
+	}
+	img := levels()
+	for _, tt := range tests {
+		result := tt.op(img)
+		for _, p := range tt.pairs {
+			if got := result.Pix[p[0]]; got != p[1] {
+				t.Errorf("%s: %d → %d, esperado %d", tt.name, p[0], got, p[1])
+			}
+		}
+		// todas são monótonas (o negativo ao contrário)
+		for v := 1; v < 256; v++ {
+			a, b := result.Pix[v-1], result.Pix[v]
+			if tt.name == "negativo" {
+				a, b = b, a
+			}
+			if b < a {
+				t.Errorf("%s: %d → %d depois de %d → %d", tt.name, v, result.Pix[v], v-1, result.Pix[v-1])
+				break
+			}
+		}
+	}
+}
+
+func TestNegativeInvolution(t *testing.T) {
+	img := randomGray(33, 17, 8)
+	sub := img.SubImage(image.Rect(3, 2, 30, 15)).(*image.Gray)
+	for _, in := range []*image.Gray{img, sub} {
+		twice := Negative(Negative(in))
+		b := in.Bounds()
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				if twice.GrayAt(x, y) != in.GrayAt(b.Min.X+x, b.Min.Y+y) {
+					t.Fatalf("negativo do negativo muda o pixel %d,%d", x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestGammaCorrectInvalid(t *testing.T) {
+	for _, g := range []float64{0, -2.2, math.NaN(), math.Inf(1)} {
+		if _, err := GammaCorrect(levels(), g); err == nil {
+			t.Errorf("gamma %g sem erro", g)
+		}
+	}
+}
+
+// scanLevels é uma imagem 151x10 que só usa os níveis 30 a 180, cada um em
+// uma coluna.
+func scanLevels() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 151, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 151; x++ {
+			img.Pix[y*img.Stride+x] = uint8(30 + x)
+		}
+	}
+	return img
+}
+
+func TestContrastStretch(t *testing.T) {
+	tests := []struct {
+		name            string
+		img             *image.Gray
+		low, high       float64
+		min, max        uint8
+		saturatedAtEach int // pixels em 0 e em 255
+	}{
+		{"faixa inteira", scanLevels(), 0, 100, 0, 255, 10},
+		{"1% e 99%", scanLevels(), 1, 99, 0, 255, 20},
+		{"10% e 90%", scanLevels(), 10, 90, 0, 255, 160},
+		{"constante", uniformGray(8, 90), 1, 99, 90, 90, 0},
+		{"percentis iguais", scanLevels(), 50, 50, 30, 180, 0},
+	}
+	for _, tt := range tests {
+		result, err := ContrastStretch(tt.img, tt.low, tt.high)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lo, hi := uint8(255), uint8(0)
+		var zeros, full int
+		for _, v := range result.Pix {
+			lo, hi = min(lo, v), max(hi, v)
+			if v == 0 {
+				zeros++
+			}
+			if v == 255 {
+				full++
+			}
+		}
+		if lo != tt.min || hi != tt.max {
+			t.Errorf("%s: faixa %d..%d, esperado %d..%d", tt.name, lo, hi, tt.min, tt.max)
+		}
+		if tt.saturatedAtEach > 0 && (zeros != tt.saturatedAtEach || full != tt.saturatedAtEach) {
+			t.Errorf("%s: %d pixels em 0 e %d em 255, esperado %d", tt.name, zeros, full, tt.saturatedAtEach)
+		}
+	}
+
+	for _, p := range [][2]float64{{-1, 99}, {1, 101}, {60, 40}, {math.NaN(), 99}} {
+		if _, err := ContrastStretch(scanLevels(), p[0], p[1]); err == nil {
+			t.Errorf("percentis %v sem erro", p)
+		}
+	}
+}
+
+func TestHistogramPercentile(t *testing.T) {
+	var histogram [256]int
+	histogram[10], histogram[20], histogram[30], histogram[40] = 25, 25, 25, 25
+	tests := []struct {
+		p    float64
+		want uint8
+	}{
+		{0, 10},
+		{0.25, 10},
+		{0.26, 20},
+		{0.5, 20},
+		{0.99, 40},
+		{1, 40},
+	}
+	for _, tt := range tests {
+		if got := HistogramPercentile(histogram, tt.p); got != tt.want {
+			t.Errorf("percentil %g: %d, esperado %d", tt.p, got, tt.want)
+		}
+	}
+	if got := HistogramPercentile([256]int{}, 0.5); got != 255 {
+		t.Errorf("histograma vazio: %d, esperado 255", got)
+	}
+}
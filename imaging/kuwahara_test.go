@@ -0,0 +1,142 @@
+package imaging
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// referenceKuwahara calcula média e variância de cada quadrante direto,
+// com as bordas replicadas.
+func referenceKuwahara(img *image.Gray, size int) *image.Gray {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	r := size / 2
+	at := func(x, y int) float64 {
+		return float64(img.GrayAt(b.Min.X+min(max(x, 0), width-1), b.Min.Y+min(max(y, 0), height-1)).Y)
+	}
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			bestVar, bestMean := math.Inf(1), 0.0
+			for _, q := range [4]image.Point{{x - r, y - r}, {x, y - r}, {x - r, y}, {x, y}} {
+				var sum, sq float64
+				for j := 0; j <= r; j++ {
+					for i := 0; i <= r; i++ {
+						v := at(q.X+i, q.Y+j)
+						sum += v
+						sq += v * v
+					}
+				}
+				n := float64((r + 1) * (r + 1))
+				mean := sum / n
+				if variance := sq/n - mean*mean; variance < bestVar {
+					bestVar, bestMean = variance, mean
+				}
+			}
+			result.Pix[y*result.Stride+x] = uint8(math.Round(bestMean))
+		}
+	}
+	return result
+}
+
+// kuwaharaInput é a estrela de Siemens com ruído gaussiano.
+func kuwaharaInput(t *testing.T) *image.Gray {
+	t.Helper()
+	img, err := synthetic.AddGaussianNoise(synthetic.SiemensStar(80, 60, 12), 25, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestKuwaharaGolden(t *testing.T) {
+	got, err := Kuwahara(kuwaharaInput(t), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareGolden(t, "kuwahara.png", got)
+}
+
+func TestKuwaharaMatchesReference(t *testing.T) {
+	img := kuwaharaInput(t)
+	tests := []struct {
+		name string
+		img  *image.Gray
+		size int
+	}{
+		{"1x1", img, 1},
+		{"3x3", img, 3},
+		{"5x5", img, 5},
+		{"par", img, 4},
+		{"9x9", img, 9},
+		{"maior que a imagem", randomGray(5, 4, 1), 11},
+		{"recorte", img.SubImage(image.Rect(13, 8, 51, 40)).(*image.Gray), 5},
+	}
+	for _, tt := range tests {
+		got, err := Kuwahara(tt.img, tt.size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := referenceKuwahara(tt.img, tt.size)
+		for i := range want.Pix {
+			if got.Pix[i] != want.Pix[i] {
+				t.Fatalf("%s: pixel %d vale %d, esperado %d", tt.name, i, got.Pix[i], want.Pix[i])
+			}
+		}
+	}
+}
+
+func TestKuwaharaPreservesEdge(t *testing.T) {
+	// degrau ruidoso de 70 para 190: dentro das regiões o ruído cai e a
+	// borda continua de um pixel, sem valores intermediários
+	step := verticalStep(48, 24, 24)
+	for i, v := range step.Pix {
+		step.Pix[i] = 70 + v/255*120
+	}
+	noisy, err := synthetic.AddGaussianNoise(step, 8, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Kuwahara(noisy, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 24; y++ {
+		for x := 0; x < 48; x++ {
+			want := 70
+			if x >= 24 {
+				want = 190
+			}
+			if d := int(got.GrayAt(x, y).Y) - want; d < -12 || d > 12 {
+				t.Fatalf("pixel %d,%d vale %d, esperado %d±12", x, y, got.GrayAt(x, y).Y, want)
+			}
+		}
+	}
+	if before, after := regionVariance(noisy, 0, 20), regionVariance(got, 0, 20); after > before/4 {
+		t.Errorf("variância da região caiu de %.1f para %.1f, esperado 4x menos", before, after)
+	}
+}
+
+func TestKuwaharaErrors(t *testing.T) {
+	for _, size := range []int{0, -5} {
+		if _, err := Kuwahara(uniformGray(4, 1), size); err == nil {
+			t.Errorf("tamanho %d: sem erro", size)
+		}
+	}
+	got, err := Kuwahara(image.NewGray(image.Rect(0, 0, 3, 0)), 5)
+	if err != nil || !got.Bounds().Empty() {
+		t.Errorf("imagem vazia: %v, %v", got.Bounds(), err)
+	}
+}
+
+func BenchmarkKuwahara(b *testing.B) {
+	img := randomGray(512, 512, 1)
+	for i := 0; i < b.N; i++ {
+		if _, err := Kuwahara(img, 9); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+)
+
+// LabelComponents rotula os objetos de uma imagem binária: devolve os
+// rótulos [y][x] (0 no fundo, 1..n nos objetos, na ordem em que aparecem
+// de cima para baixo) e n. connectivity é 4 ou 8; polarity diz qual cor é
+// objeto, como em Erode.
+func LabelComponents(img *image.Gray, connectivity int, polarity Polarity) ([][]int, int, error) {
+	if connectivity != 4 && connectivity != 8 {
+		return nil, 0, fmt.Errorf("conectividade deve ser 4 ou 8, não %d", connectivity)
+	}
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	mask := make([]bool, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			mask[y*width+x] = (img.Pix[y*img.Stride+x] == 0) == (polarity == BlackObjects)
+		}
+	}
+	labels := make([]int, width*height)
+	n := labelComponents(mask, width, height, connectivity, labels, 1) - 1
+	return rows(labels, width, height), n, nil
+}
+
+// labelComponents rotula os componentes da máscara a partir de first e
+// devolve o próximo rótulo livre. São duas passadas com union-find: a
+// primeira dá rótulos provisórios olhando os vizinhos já visitados e junta
+// os que se encostam, a segunda troca cada um pelo rótulo final. Não usa
+// pilha, então objetos enormes não pesam na memória.
+func labelComponents(mask []bool, width, height, connectivity int, labels []int, first int) int {
+	// vizinhos anteriores na varredura: oeste e norte, mais as diagonais de
+	// cima com vizinhança 8
+	before := [][2]int{{-1, 0}, {0, -1}}
+	if connectivity == 8 {
+		before = append(before, [2]int{-1, -1}, [2]int{1, -1})
+	}
+
+	// parent[0] não é usado; a raiz de cada classe é o menor rótulo dela
+	parent := []int{0}
+	find := func(a int) int {
+		for parent[a] != a {
+			parent[a] = parent[parent[a]]
+			a = parent[a]
+		}
+		return a
+	}
+	provisional := make([]int, len(mask))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := y*width + x
+			if !mask[p] {
+				continue
+			}
+			label := 0
+			for _, d := range before {
+				nx, ny := x+d[0], y+d[1]
+				if nx < 0 || ny < 0 || nx >= width {
+					continue
+				}
+				other := provisional[ny*width+nx]
+				if other == 0 {
+					continue
+				}
+				if label == 0 {
+					label = find(other)
+					continue
+				}
+				a, b := find(label), find(other)
+				label = min(a, b)
+				parent[max(a, b)] = label
+			}
+			if label == 0 {
+				label = len(parent)
+				parent = append(parent, label)
+			}
+			provisional[p] = label
+		}
+	}
+
+	final := make([]int, len(parent))
+	next := first
+	for p, l := range provisional {
+		if l == 0 {
+			continue
+		}
+		root := find(l)
+		if final[root] == 0 {
+			final[root] = next
+			next++
+		}
+		labels[p] = final[root]
+	}
+	return next
+}
@@ -0,0 +1,146 @@
+package imaging
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+)
+
+func TestLabelComponentsCounts(t *testing.T) {
+	tests := []struct {
+		name        string
+		art         string
+		four, eight int
+	}{
+		{"vazia", "..... .....", 0, 0},
+		{"encostados pelo lado", "##.... ###... ..#... ......", 1, 1},
+		{"só pela diagonal", "##.. ##.. ..## ..##", 2, 1},
+		{"xadrez", "#.#. .#.# #.#. .#.#", 8, 1},
+		{"na borda", "#...# ..... ..... #...#", 4, 4},
+		{"borda inteira", "##### #...# #...# #####", 1, 1},
+		{"U junta dois rótulos provisórios", "#.#.# #.#.# #####", 1, 1},
+		{"escada que junta tudo no fim", "#.#.#. #.#.#. #.#.#. ######", 1, 1},
+		{"separados", "##..## ##..## ...... ##..##", 4, 4},
+	}
+	for _, tt := range tests {
+		for _, c := range []struct {
+			connectivity, want int
+		}{{4, tt.four}, {8, tt.eight}} {
+			for _, polarity := range []Polarity{BlackObjects, WhiteObjects} {
+				object := uint8(0)
+				if polarity == WhiteObjects {
+					object = 255
+				}
+				_, n, err := LabelComponents(parseMask(tt.art, object), c.connectivity, polarity)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if n != c.want {
+					t.Errorf("%s, vizinhança %d, polaridade %d: %d componentes, esperado %d", tt.name, c.connectivity, polarity, n, c.want)
+				}
+			}
+		}
+	}
+}
+
+// floodLabels rotula por busca em largura, na ordem da varredura.
+func floodLabels(mask *image.Gray, connectivity int) ([][]int, int) {
+	w, h := mask.Bounds().Dx(), mask.Bounds().Dy()
+	labels := make([][]int, h)
+	for y := range labels {
+		labels[y] = make([]int, w)
+	}
+	n := 0
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if mask.Pix[y*mask.Stride+x] != 0 || labels[y][x] != 0 {
+				continue
+			}
+			n++
+			labels[y][x] = n
+			queue := []image.Point{{x, y}}
+			for len(queue) > 0 {
+				p := queue[0]
+				queue = queue[1:]
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						if dx == 0 && dy == 0 || connectivity == 4 && dx != 0 && dy != 0 {
+							continue
+						}
+						q := image.Pt(p.X+dx, p.Y+dy)
+						if q.X < 0 || q.Y < 0 || q.X >= w || q.Y >= h || labels[q.Y][q.X] != 0 || mask.Pix[q.Y*mask.Stride+q.X] != 0 {
+							continue
+						}
+						labels[q.Y][q.X] = n
+						queue = append(queue, q)
+					}
+				}
+			}
+		}
+	}
+	return labels, n
+}
+
+func TestLabelComponentsMatchesFlood(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	for i := 0; i < 20; i++ {
+		img := image.NewGray(image.Rect(0, 0, 30, 20))
+		for p := range img.Pix {
+			if rng.Intn(5) < 2 {
+				img.Pix[p] = 255
+			}
+		}
+		for _, connectivity := range []int{4, 8} {
+			got, n, err := LabelComponents(img, connectivity, BlackObjects)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, m := floodLabels(img, connectivity)
+			if n != m {
+				t.Fatalf("imagem %d, vizinhança %d: %d componentes, esperado %d", i, connectivity, n, m)
+			}
+			for y := range want {
+				for x := range want[y] {
+					if got[y][x] != want[y][x] {
+						t.Fatalf("imagem %d, vizinhança %d: rótulo %d em %d,%d, esperado %d", i, connectivity, got[y][x], x, y, want[y][x])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestLabelComponentsErrors(t *testing.T) {
+	for _, connectivity := range []int{0, 6, -4} {
+		if _, _, err := LabelComponents(parseMask("#.", 0), connectivity, BlackObjects); err == nil {
+			t.Errorf("vizinhança %d sem erro", connectivity)
+		}
+	}
+}
+
+func TestCountObjectsFiltersByArea(t *testing.T) {
+	img := parseMask(`
+		##.......#
+		##..###...
+		....###...
+		#...###..#
+		#.........`, 0)
+	tests := []struct {
+		minArea, connectivity, want int
+	}{
+		{1, 8, 5},
+		{2, 8, 3},
+		{4, 8, 2},
+		{5, 8, 1},
+		{10, 8, 0},
+	}
+	for _, tt := range tests {
+		n, err := CountObjects(img, CountObjectsOptions{MinArea: tt.minArea, Size: 1, Connectivity: tt.connectivity})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != tt.want {
+			t.Errorf("área mínima %d: %d objetos, esperado %d", tt.minArea, n, tt.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package imaging
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestGaussianKernel(t *testing.T) {
+	tests := []struct {
+		size     int
+		sigma    float64
+		wantSize int
+	}{
+		{0, 1, 7},
+		{0, 1.4, 11},
+		{5, 1, 5},
+		{4, 1, 5}, // par vira o ímpar seguinte
+	}
+	for _, tt := range tests {
+		kernel := GaussianKernel(tt.size, tt.sigma)
+		if len(kernel) != tt.wantSize || len(kernel[0]) != tt.wantSize {
+			t.Errorf("size %d σ=%g: kernel %dx%d, esperado %d", tt.size, tt.sigma, len(kernel[0]), len(kernel), tt.wantSize)
+			continue
+		}
+		var sum float64
+		n := len(kernel) - 1
+		for i, row := range kernel {
+			for j, v := range row {
+				sum += v
+				if v != kernel[j][i] || v != kernel[n-i][n-j] {
+					t.Errorf("size %d σ=%g: kernel não simétrico em (%d,%d)", tt.size, tt.sigma, j, i)
+				}
+				if v > kernel[n/2][n/2] {
+					t.Errorf("size %d σ=%g: (%d,%d) maior que o centro", tt.size, tt.sigma, j, i)
+				}
+			}
+		}
+		if math.Abs(sum-1) > 1e-12 {
+			t.Errorf("size %d σ=%g: soma %g, esperado 1", tt.size, tt.sigma, sum)
+		}
+	}
+}
+
+// verticalStep é preta à esquerda da coluna edge e branca dela em diante.
+func verticalStep(w, h, edge int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := edge; x < w; x++ {
+			img.Pix[y*img.Stride+x] = 255
+		}
+	}
+	return img
+}
+
+func TestMarrHildrethStep(t *testing.T) {
+	for _, sigma := range []float64{1, 2, 3} {
+		edges, err := MarrHildreth(verticalStep(40, 20, 20), sigma, 0, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for y := 0; y < 20; y++ {
+			var cols []int
+			for x := 0; x < 40; x++ {
+				switch edges.Pix[y*edges.Stride+x] {
+				case 255:
+					cols = append(cols, x)
+				case 0:
+				default:
+					t.Fatalf("σ=%g: valor %d, esperado saída binária", sigma, edges.Pix[y*edges.Stride+x])
+				}
+			}
+			if len(cols) != 1 || cols[0] < 19 || cols[0] > 20 {
+				t.Errorf("σ=%g, linha %d: bordas nas colunas %v, esperado uma só em 19 ou 20", sigma, y, cols)
+			}
+		}
+	}
+}
+
+func TestMarrHildrethCircle(t *testing.T) {
+	center := image.Pt(32, 32)
+	edges, err := MarrHildreth(diskImage(64, center, 15, 255), 1.5, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reachesBorder(edges, center) {
+		t.Error("contorno do disco aberto")
+	}
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			if edges.Pix[y*edges.Stride+x] == 255 {
+				if d := math.Hypot(float64(x-center.X), float64(y-center.Y)); math.Abs(d-15) > 2 {
+					t.Errorf("borda em (%d,%d) a %.1f do centro, esperado ≈ 15", x, y, d)
+				}
+			}
+		}
+	}
+}
+
+func TestMarrHildrethSlope(t *testing.T) {
+	// ruído fraco sobre um cinza liso: sem limiar cruza zero em todo canto
+	rng := rand.New(rand.NewSource(1))
+	img := uniformGray(48, 128)
+	for i := range img.Pix {
+		img.Pix[i] = uint8(128 + rng.Intn(5) - 2)
+	}
+	count := func(slope float64) int {
+		edges, err := MarrHildreth(img, 1, 0, slope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for _, v := range edges.Pix {
+			if v == 255 {
+				n++
+			}
+		}
+		return n
+	}
+	if loose, strict := count(0), count(4); loose < 100 || strict != 0 {
+		t.Errorf("%d cruzamentos sem limiar e %d com slope 4, esperado muitos e nenhum", loose, strict)
+	}
+}
+
+func TestMarrHildrethInvalid(t *testing.T) {
+	if _, err := MarrHildreth(uniformGray(8, 0), -1, 0, 0); err == nil {
+		t.Error("σ negativo sem erro")
+	}
+	if _, err := MarrHildreth(uniformGray(8, 0), 1, 0, -1); err == nil {
+		t.Error("slope negativo sem erro")
+	}
+}
@@ -0,0 +1,127 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"sort"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// referenceMedian ordena cada janela, com as bordas replicadas.
+func referenceMedian(img *image.Gray, size int) *image.Gray {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	radius := size / 2
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	window := make([]int, 0, (2*radius+1)*(2*radius+1))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			window = window[:0]
+			for j := -radius; j <= radius; j++ {
+				for i := -radius; i <= radius; i++ {
+					xx := min(max(x+i, 0), width-1)
+					yy := min(max(y+j, 0), height-1)
+					window = append(window, int(img.GrayAt(b.Min.X+xx, b.Min.Y+yy).Y))
+				}
+			}
+			sort.Ints(window)
+			result.Pix[y*result.Stride+x] = uint8(window[len(window)/2])
+		}
+	}
+	return result
+}
+
+func TestMedianFilterMatchesSort(t *testing.T) {
+	img := randomGray(37, 23, 4)
+	tests := []struct {
+		name string
+		img  *image.Gray
+		size int
+	}{
+		{"1x1", img, 1},
+		{"par vira 3x3", img, 2},
+		{"3x3", img, 3},
+		{"5x5", img, 5},
+		{"9x9", img, 9},
+		{"janela maior que a imagem", randomGray(4, 3, 5), 7},
+		{"recorte", img.SubImage(image.Rect(5, 3, 30, 20)).(*image.Gray), 5},
+		{"uma linha", randomGray(20, 1, 6), 3},
+	}
+	for _, tt := range tests {
+		got, err := MedianFilter(tt.img, tt.size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := referenceMedian(tt.img, tt.size|1)
+		if got.Bounds() != want.Bounds() {
+			t.Errorf("%s: limites %v, esperado %v", tt.name, got.Bounds(), want.Bounds())
+			continue
+		}
+		for i := range want.Pix {
+			if got.Pix[i] != want.Pix[i] {
+				t.Errorf("%s: pixel %d = %d, esperado %d", tt.name, i, got.Pix[i], want.Pix[i])
+				break
+			}
+		}
+	}
+}
+
+func TestMedianFilterSaltPepper(t *testing.T) {
+	clean := verticalStep(100, 80, 50)
+	for i, v := range clean.Pix {
+		clean.Pix[i] = 60 + v/255*140
+	}
+	noisy, err := synthetic.AddSaltPepperNoise(clean, 0.05, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered, err := MedianFilter(noisy, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noise, left := 0, 0
+	for i := range clean.Pix {
+		if noisy.Pix[i] != clean.Pix[i] {
+			noise++
+		}
+		if filtered.Pix[i] != clean.Pix[i] {
+			left++
+		}
+	}
+	if noise < 300 {
+		t.Fatalf("só %d pixels com ruído: o teste não distingue", noise)
+	}
+	if left > len(clean.Pix)/1000 {
+		t.Errorf("%d de %d pixels ruidosos continuam diferentes da imagem limpa", left, noise)
+	}
+	// o degrau continua de um pixel: nenhum valor intermediário em volta
+	for y := 0; y < 80; y++ {
+		row := filtered.Pix[y*filtered.Stride:]
+		if row[49] != 60 && row[49] != 200 || row[50] != 60 && row[50] != 200 {
+			t.Fatalf("linha %d: degrau borrado (%d, %d)", y, row[49], row[50])
+		}
+	}
+}
+
+func TestMedianFilterInvalidSize(t *testing.T) {
+	for _, size := range []int{0, -3} {
+		if _, err := MedianFilter(randomGray(5, 5, 1), size); err == nil {
+			t.Errorf("tamanho %d sem erro", size)
+		}
+	}
+}
+
+func BenchmarkMedianFilter(b *testing.B) {
+	img := randomGray(1000, 1000, 1)
+	for _, size := range []int{3, 7, 15} {
+		b.Run(fmt.Sprintf("%dx%d", size, size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := MedianFilter(img, size); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package imaging
+
+import "math"
+
+// Moments são os momentos de um objeto até a terceira ordem: os brutos
+// (M), os centrais (Mu, em torno do centroide) e os centrais normalizados
+// pela escala (Nu = Mu / M00^(1+(p+q)/2)). Mu00 é M00 e Mu10 = Mu01 = 0,
+// então não aparecem.
+type Moments struct {
+	M00, M10, M01, M20, M11, M02, M30, M21, M12, M03 float64
+	Mu20, Mu11, Mu02, Mu30, Mu21, Mu12, Mu03         float64
+	Nu20, Nu11, Nu02, Nu30, Nu21, Nu12, Nu03         float64
+}
+
+// RegionMoments calcula os momentos dos pixels com o rótulo label em labels
+// ([y][x], como os de LabelComponents). Sem nenhum pixel tudo fica 0.
+func RegionMoments(labels [][]int, label int) Moments {
+	var m Moments
+	for y, row := range labels {
+		for x, l := range row {
+			if l == label {
+				m.addRaw(float64(x), float64(y))
+			}
+		}
+	}
+	if m.M00 == 0 {
+		return m
+	}
+	cx, cy := m.M10/m.M00, m.M01/m.M00
+	for y, row := range labels {
+		for x, l := range row {
+			if l == label {
+				m.addCentral(float64(x)-cx, float64(y)-cy)
+			}
+		}
+	}
+	m.normalize()
+	return m
+}
+
+// Os momentos centrais são somados numa segunda passada, já em torno do
+// centroide, em vez de saírem dos brutos: com coordenadas grandes a
+// subtração dos brutos perde quase toda a precisão.
+
+func (m *Moments) addRaw(x, y float64) {
+	m.M00++
+	m.M10 += x
+	m.M01 += y
+	m.M20 += x * x
+	m.M11 += x * y
+	m.M02 += y * y
+	m.M30 += x * x * x
+	m.M21 += x * x * y
+	m.M12 += x * y * y
+	m.M03 += y * y * y
+}
+
+func (m *Moments) addCentral(dx, dy float64) {
+	m.Mu20 += dx * dx
+	m.Mu11 += dx * dy
+	m.Mu02 += dy * dy
+	m.Mu30 += dx * dx * dx
+	m.Mu21 += dx * dx * dy
+	m.Mu12 += dx * dy * dy
+	m.Mu03 += dy * dy * dy
+}
+
+func (m *Moments) normalize() {
+	if m.M00 == 0 {
+		return
+	}
+	second := m.M00 * m.M00       // M00^(1+2/2)
+	third := math.Pow(m.M00, 2.5) // M00^(1+3/2)
+	m.Nu20, m.Nu11, m.Nu02 = m.Mu20/second, m.Mu11/second, m.Mu02/second
+	m.Nu30, m.Nu21, m.Nu12, m.Nu03 = m.Mu30/third, m.Mu21/third, m.Mu12/third, m.Mu03/third
+}
+
+// Orientation é o ângulo, em radianos em (−π/2, π/2], do eixo maior da
+// elipse com os mesmos momentos de segunda ordem, medido a partir do eixo
+// x. Como y cresce para baixo, ângulos positivos giram no sentido horário
+// na tela.
+func (m Moments) Orientation() float64 {
+	return 0.5 * math.Atan2(2*m.Mu11, m.Mu20-m.Mu02)
+}
+
+// Eccentricity é a excentricidade dessa elipse, √(1 − λ₂/λ₁) com λ₁ ≥ λ₂
+// os autovalores da matriz de covariância: 0 num círculo, perto de 1 numa
+// linha.
+func (m Moments) Eccentricity() float64 {
+	common := math.Sqrt(4*m.Mu11*m.Mu11 + (m.Mu20-m.Mu02)*(m.Mu20-m.Mu02))
+	l1 := (m.Mu20 + m.Mu02 + common) / 2
+	l2 := (m.Mu20 + m.Mu02 - common) / 2
+	if l1 <= 0 {
+		return 0
+	}
+	return math.Sqrt(max(0, 1-l2/l1))
+}
+
+// HuMoments devolve os sete invariantes de Hu (1962), que não mudam com
+// translação, escala e rotação; o sétimo troca de sinal no espelhamento.
+func HuMoments(m Moments) [7]float64 {
+	n20, n11, n02 := m.Nu20, m.Nu11, m.Nu02
+	n30, n21, n12, n03 := m.Nu30, m.Nu21, m.Nu12, m.Nu03
+	a, b := n30+n12, n21+n03
+	return [7]float64{
+		n20 + n02,
+		(n20-n02)*(n20-n02) + 4*n11*n11,
+		(n30-3*n12)*(n30-3*n12) + (3*n21-n03)*(3*n21-n03),
+		a*a + b*b,
+		(n30-3*n12)*a*(a*a-3*b*b) + (3*n21-n03)*b*(3*a*a-b*b),
+		(n20-n02)*(a*a-b*b) + 4*n11*a*b,
+		(3*n21-n03)*a*(a*a-3*b*b) - (n30-3*n12)*b*(3*a*a-b*b),
+	}
+}
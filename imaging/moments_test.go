@@ -0,0 +1,145 @@
+package imaging
+
+import (
+	"math"
+	"testing"
+)
+
+// shapeLabels põe o rótulo 1 nos pontos (x, y) de um campo w×h.
+func shapeLabels(w, h int, points [][2]int) [][]int {
+	labels := make([][]int, h)
+	for y := range labels {
+		labels[y] = make([]int, w)
+	}
+	for _, p := range points {
+		labels[p[1]][p[0]] = 1
+	}
+	return labels
+}
+
+// arrow é uma seta assimétrica que não coincide com nenhuma rotação dela.
+func arrow() [][2]int {
+	var points [][2]int
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 22; x++ {
+			shaft := x >= 8 && x < 13 && y >= 10
+			head := y < 10 && x >= y/2 && x < 22-y && x >= 2
+			foot := y >= 25 && x >= 13 && x < 20
+			if shaft || head || foot {
+				points = append(points, [2]int{x + 5, y + 3})
+			}
+		}
+	}
+	return points
+}
+
+func TestHuMomentsRotation(t *testing.T) {
+	points := arrow()
+	transforms := []struct {
+		name string
+		f    func(p [2]int) [2]int
+		sign float64 // o sétimo troca de sinal no espelhamento
+	}{
+		{"90°", func(p [2]int) [2]int { return [2]int{39 - p[1], p[0]} }, 1},
+		{"180°", func(p [2]int) [2]int { return [2]int{39 - p[0], 39 - p[1]} }, 1},
+		{"270°", func(p [2]int) [2]int { return [2]int{p[1], 39 - p[0]} }, 1},
+		{"translação", func(p [2]int) [2]int { return [2]int{p[0] + 7, p[1] + 4} }, 1},
+		{"espelho", func(p [2]int) [2]int { return [2]int{39 - p[0], p[1]} }, -1},
+	}
+	want := HuMoments(RegionMoments(shapeLabels(40, 40, points), 1))
+	for i, v := range want {
+		if v == 0 {
+			t.Fatalf("invariante %d nulo: a forma não distingue", i+1)
+		}
+	}
+	for _, tt := range transforms {
+		moved := make([][2]int, len(points))
+		for i, p := range points {
+			moved[i] = tt.f(p)
+		}
+		got := HuMoments(RegionMoments(shapeLabels(50, 50, moved), 1))
+		for i := range got {
+			w := want[i]
+			if i == 6 {
+				w *= tt.sign
+			}
+			if math.Abs(got[i]-w) > 1e-9*math.Abs(w) {
+				t.Errorf("%s: invariante %d = %.12g, esperado %.12g", tt.name, i+1, got[i], w)
+			}
+		}
+	}
+}
+
+func TestHuMomentsScale(t *testing.T) {
+	points := arrow()
+	var doubled [][2]int
+	for _, p := range points {
+		for dy := 0; dy < 2; dy++ {
+			for dx := 0; dx < 2; dx++ {
+				doubled = append(doubled, [2]int{2*p[0] + dx, 2*p[1] + dy})
+			}
+		}
+	}
+	small := HuMoments(RegionMoments(shapeLabels(40, 40, points), 1))
+	large := HuMoments(RegionMoments(shapeLabels(80, 80, doubled), 1))
+	// os pixels são quadrados e não pontos, então a escala só é aproximada
+	for i := 0; i < 2; i++ {
+		if math.Abs(large[i]-small[i]) > 0.01*math.Abs(small[i]) {
+			t.Errorf("invariante %d: %g na escala 2, %g na original", i+1, large[i], small[i])
+		}
+	}
+}
+
+func TestRegionMomentsRectangle(t *testing.T) {
+	var horizontal, vertical, diagonal [][2]int
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			horizontal = append(horizontal, [2]int{x + 3, y + 1})
+			vertical = append(vertical, [2]int{y + 1, x + 3})
+		}
+	}
+	for i := 0; i < 8; i++ {
+		diagonal = append(diagonal, [2]int{i, i})
+	}
+	tests := []struct {
+		name                   string
+		m                      Moments
+		m00, cx, cy            float64
+		mu20, mu11, mu02       float64
+		orientation, eccentric float64
+	}{
+		{"retângulo 4x2", RegionMoments(shapeLabels(10, 10, horizontal), 1), 8, 4.5, 1.5, 10, 0, 2, 0, math.Sqrt(0.8)},
+		{"retângulo 2x4", RegionMoments(shapeLabels(10, 10, vertical), 1), 8, 1.5, 4.5, 2, 0, 10, math.Pi / 2, math.Sqrt(0.8)},
+		{"diagonal descendo", RegionMoments(shapeLabels(10, 10, diagonal), 1), 8, 3.5, 3.5, 42, 42, 42, math.Pi / 4, 1},
+		{"sem pixels", RegionMoments(shapeLabels(10, 10, nil), 1), 0, 0, 0, 0, 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		m := tt.m
+		var cx, cy float64
+		if m.M00 > 0 {
+			cx, cy = m.M10/m.M00, m.M01/m.M00
+		}
+		got := []float64{m.M00, cx, cy, m.Mu20, m.Mu11, m.Mu02, m.Orientation(), m.Eccentricity()}
+		want := []float64{tt.m00, tt.cx, tt.cy, tt.mu20, tt.mu11, tt.mu02, tt.orientation, tt.eccentric}
+		for i := range got {
+			if math.Abs(got[i]-want[i]) > 1e-9 {
+				t.Errorf("%s: %v, esperado %v", tt.name, got, want)
+				break
+			}
+		}
+		if m.M00 > 0 && math.Abs(m.Nu20-m.Mu20/(m.M00*m.M00)) > 1e-12 {
+			t.Errorf("%s: Nu20 %g fora da normalização", tt.name, m.Nu20)
+		}
+	}
+}
+
+func TestRegionPropsMoments(t *testing.T) {
+	labels := shapeLabels(40, 40, arrow())
+	regions := RegionProps(labels)
+	if len(regions) != 1 {
+		t.Fatalf("%d regiões", len(regions))
+	}
+	if got, want := regions[0].Moments, RegionMoments(labels, 1); got != want {
+		t.Errorf("momentos de RegionProps %+v, esperado %+v", got, want)
+	}
+}
@@ -0,0 +1,231 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+)
+
+// Polarity diz qual cor é objeto na morfologia.
+type Polarity int
+
+const (
+	BlackObjects Polarity = iota // objeto é 0, como em CountObjects
+	WhiteObjects                 // objeto é claro: a morfologia em tons de cinza usual
+)
+
+// StructuringElement é o elemento estruturante de Erode e Dilate: Mask[y][x]
+// marca as posições que participam e (AnchorX, AnchorY) é a posição da
+// máscara que fica sobre o pixel calculado.
+type StructuringElement struct {
+	Mask             [][]bool
+	AnchorX, AnchorY int
+}
+
+// NewStructuringElement monta um elemento com uma máscara retangular
+// qualquer e a âncora dentro dela.
+func NewStructuringElement(mask [][]bool, anchorX, anchorY int) (StructuringElement, error) {
+	if len(mask) == 0 || len(mask[0]) == 0 {
+		return StructuringElement{}, fmt.Errorf("elemento estruturante vazio")
+	}
+	for _, row := range mask {
+		if len(row) != len(mask[0]) {
+			return StructuringElement{}, fmt.Errorf("elemento estruturante com linhas de tamanhos diferentes")
+		}
+	}
+	if anchorX < 0 || anchorY < 0 || anchorX >= len(mask[0]) || anchorY >= len(mask) {
+		return StructuringElement{}, fmt.Errorf("âncora (%d, %d) fora do elemento %dx%d", anchorX, anchorY, len(mask[0]), len(mask))
+	}
+	return StructuringElement{mask, anchorX, anchorY}, nil
+}
+
+// SquareSE devolve um quadrado size×size centrado.
+func SquareSE(size int) StructuringElement {
+	return centeredSE(max(1, size), func(dx, dy, r int) bool { return true })
+}
+
+// DiskSE devolve um disco de raio radius ((2r+1)×(2r+1), dx² + dy² ≤ r²).
+func DiskSE(radius int) StructuringElement {
+	return centeredSE(2*max(0, radius)+1, func(dx, dy, r int) bool { return dx*dx+dy*dy <= r*r })
+}
+
+// CrossSE devolve uma cruz size×size: a linha e a coluna do centro.
+func CrossSE(size int) StructuringElement {
+	return centeredSE(max(1, size), func(dx, dy, r int) bool { return dx == 0 || dy == 0 })
+}
+
+// centeredSE monta um elemento size×size com a âncora no centro; on recebe
+// a posição relativa à âncora e o raio size/2.
+func centeredSE(size int, on func(dx, dy, r int) bool) StructuringElement {
+	r := size / 2
+	mask := make([][]bool, size)
+	for y := range mask {
+		mask[y] = make([]bool, size)
+		for x := range mask[y] {
+			mask[y][x] = on(x-r, y-r, r)
+		}
+	}
+	return StructuringElement{mask, r, r}
+}
+
+// offsets lista as posições ativas relativas à âncora; reflect espelha o
+// elemento em torno dela.
+func (se StructuringElement) offsets(reflect bool) []image.Point {
+	var points []image.Point
+	for y, row := range se.Mask {
+		for x, on := range row {
+			if !on {
+				continue
+			}
+			p := image.Pt(x-se.AnchorX, y-se.AnchorY)
+			if reflect {
+				p = image.Pt(-p.X, -p.Y)
+			}
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+// Erode faz a erosão: os objetos encolhem onde o elemento não cabe neles.
+// Com BlackObjects (objeto 0, a convenção de CountObjects) é o máximo sob o
+// elemento; com WhiteObjects, o mínimo, que em tons de cinza é a erosão
+// usual. Em máscaras 0/255 dá a erosão binária. Pixels fora da imagem são
+// ignorados.
+func Erode(img *image.Gray, se StructuringElement, polarity Polarity) *image.Gray {
+	if se.rectangular() {
+		w, h := len(se.Mask[0]), len(se.Mask)
+		return rectRankFilter(img, se.AnchorX, w-1-se.AnchorX, se.AnchorY, h-1-se.AnchorY, polarity == BlackObjects)
+	}
+	return rankFilter(img, se.offsets(false), polarity == BlackObjects)
+}
+
+// Dilate faz a dilatação, o dual de Erode. Usa o elemento refletido em
+// torno da âncora, então Erode seguido de Dilate é a abertura também para
+// elementos assimétricos.
+func Dilate(img *image.Gray, se StructuringElement, polarity Polarity) *image.Gray {
+	if se.rectangular() {
+		w, h := len(se.Mask[0]), len(se.Mask)
+		return rectRankFilter(img, w-1-se.AnchorX, se.AnchorX, h-1-se.AnchorY, se.AnchorY, polarity == WhiteObjects)
+	}
+	return rankFilter(img, se.offsets(true), polarity == WhiteObjects)
+}
+
+// rectangular diz se a máscara é toda marcada, como em SquareSE: aí Erode e
+// Dilate usam os filtros de mínimo e máximo separáveis (van Herk), O(1) por
+// pixel em vez de O(tamanho do elemento).
+func (se StructuringElement) rectangular() bool {
+	for _, row := range se.Mask {
+		for _, on := range row {
+			if !on {
+				return false
+			}
+		}
+	}
+	return len(se.Mask) > 0 && len(se.Mask[0]) > 0
+}
+
+// rankFilter devolve, em cada pixel, o máximo (ou o mínimo) de img nas
+// posições offsets em volta dele. As linhas são divididas entre goroutines.
+func rankFilter(img *image.Gray, offsets []image.Point, takeMax bool) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	// valor inicial e o que encerra a busca
+	start, stop := uint8(255), uint8(0)
+	if takeMax {
+		start, stop = 0, 255
+	}
+	parallelRows(height, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := 0; x < width; x++ {
+				best := start
+				for _, d := range offsets {
+					xx, yy := x+d.X, y+d.Y
+					if xx < 0 || yy < 0 || xx >= width || yy >= height {
+						continue
+					}
+					v := img.Pix[yy*img.Stride+xx]
+					if takeMax {
+						best = max(best, v)
+					} else {
+						best = min(best, v)
+					}
+					if best == stop {
+						break
+					}
+				}
+				result.Pix[y*result.Stride+x] = best
+			}
+		}
+	})
+	return result
+}
+
+// ParseStructuringElement lê "square:N", "disk:R" ou "cross:N".
+func ParseStructuringElement(s string) (StructuringElement, error) {
+	shape, sizeText, _ := strings.Cut(s, ":")
+	size, err := strconv.Atoi(sizeText)
+	if err != nil || size < 1 {
+		return StructuringElement{}, fmt.Errorf("elemento estruturante inválido %q, use square:N, disk:R ou cross:N", s)
+	}
+	switch shape {
+	case "square":
+		return SquareSE(size), nil
+	case "disk":
+		return DiskSE(size), nil
+	case "cross":
+		return CrossSE(size), nil
+	}
+	return StructuringElement{}, fmt.Errorf("forma de elemento estruturante desconhecida %q, use square, disk ou cross", shape)
+}
+
+// Opening é a abertura, Dilate(Erode(img)): some o que é menor que o
+// elemento e o resto mantém o tamanho.
+func Opening(img *image.Gray, se StructuringElement, polarity Polarity) *image.Gray {
+	return Dilate(Erode(img, se, polarity), se, polarity)
+}
+
+// Closing é o fechamento, Erode(Dilate(img)): fecha buracos e frestas
+// menores que o elemento.
+func Closing(img *image.Gray, se StructuringElement, polarity Polarity) *image.Gray {
+	return Erode(Dilate(img, se, polarity), se, polarity)
+}
+
+// As três abaixo são definidas em tons de cinza, com o claro como objeto
+// (WhiteObjects), e as subtrações saturam em 0.
+
+// MorphGradient é a dilatação menos a erosão: realça os contornos.
+func MorphGradient(img *image.Gray, se StructuringElement) *image.Gray {
+	return subtract(Dilate(img, se, WhiteObjects), Erode(img, se, WhiteObjects))
+}
+
+// TopHat é a imagem menos a sua abertura: sobram os detalhes claros menores
+// que o elemento e some o fundo que varia devagar (ex: iluminação desigual
+// em microscopia).
+func TopHat(img *image.Gray, se StructuringElement) *image.Gray {
+	return subtract(img, Opening(img, se, WhiteObjects))
+}
+
+// BlackHat é o fechamento menos a imagem: os detalhes escuros menores que o
+// elemento.
+func BlackHat(img *image.Gray, se StructuringElement) *image.Gray {
+	return subtract(Closing(img, se, WhiteObjects), img)
+}
+
+// subtract devolve a − b pixel a pixel, saturando em 0.
+func subtract(a, b *image.Gray) *image.Gray {
+	width, height := a.Bounds().Dx(), a.Bounds().Dy()
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		rowA := a.Pix[y*a.Stride : y*a.Stride+width]
+		rowB := b.Pix[y*b.Stride : y*b.Stride+width]
+		dst := result.Pix[y*result.Stride:]
+		for x, v := range rowA {
+			if v > rowB[x] {
+				dst[x] = v - rowB[x]
+			}
+		}
+	}
+	return result
+}
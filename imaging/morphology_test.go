@@ -0,0 +1,327 @@
+package imaging
+
+import (
+	"image"
+	"testing"
+)
+
+// referenceMorph calcula a erosão ou a dilatação pixel a pixel pela
+// definição, com GrayAt e ignorando o que cai fora da imagem.
+func referenceMorph(img *image.Gray, se StructuringElement, polarity Polarity, dilate bool) *image.Gray {
+	b := img.Bounds()
+	result := image.NewGray(image.Rect(0, 0, b.Dx(), b.Dy()))
+	// a erosão de objetos brancos é o mínimo; o resto sai por dualidade
+	takeMin := (polarity == WhiteObjects) != dilate
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			best := 0
+			if takeMin {
+				best = 255
+			}
+			for j, row := range se.Mask {
+				for i, on := range row {
+					dx, dy := i-se.AnchorX, j-se.AnchorY
+					if dilate {
+						dx, dy = -dx, -dy
+					}
+					xx, yy := x+dx, y+dy
+					if !on || xx < 0 || yy < 0 || xx >= b.Dx() || yy >= b.Dy() {
+						continue
+					}
+					v := int(img.GrayAt(b.Min.X+xx, b.Min.Y+yy).Y)
+					if takeMin {
+						best = min(best, v)
+					} else {
+						best = max(best, v)
+					}
+				}
+			}
+			result.Pix[y*result.Stride+x] = uint8(best)
+		}
+	}
+	return result
+}
+
+func TestErodeDilateGolden(t *testing.T) {
+	img := randomGray(31, 23, 7)
+	binary := Otsu(img)
+	lShape, err := NewStructuringElement([][]bool{
+		{true, false, false},
+		{true, false, false},
+		{true, true, true},
+	}, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rect, err := NewStructuringElement([][]bool{{true, true, true, true}, {true, true, true, true}}, 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	elements := []struct {
+		name string
+		se   StructuringElement
+	}{
+		{"quadrado 1", SquareSE(1)},
+		{"quadrado 3", SquareSE(3)},
+		{"quadrado 7", SquareSE(7)},
+		{"disco 2", DiskSE(2)},
+		{"cruz 5", CrossSE(5)},
+		{"L com âncora no canto", lShape},
+		{"retângulo 4x2 com âncora deslocada", rect},
+	}
+	inputs := []struct {
+		name string
+		img  *image.Gray
+	}{
+		{"cinza", img},
+		{"binária", binary},
+		{"recorte", img.SubImage(image.Rect(4, 3, 27, 20)).(*image.Gray)},
+	}
+	for _, e := range elements {
+		for _, in := range inputs {
+			for _, polarity := range []Polarity{BlackObjects, WhiteObjects} {
+				for _, dilate := range []bool{false, true} {
+					got := Erode(in.img, e.se, polarity)
+					if dilate {
+						got = Dilate(in.img, e.se, polarity)
+					}
+					want := referenceMorph(in.img, e.se, polarity, dilate)
+					if got.Bounds() != want.Bounds() {
+						t.Fatalf("%s, %s: limites %v", e.name, in.name, got.Bounds())
+					}
+					for i := range want.Pix {
+						if got.Pix[i] != want.Pix[i] {
+							t.Errorf("%s, %s, polaridade %d, dilatação %v: pixel %d = %d, esperado %d",
+								e.name, in.name, polarity, dilate, i, got.Pix[i], want.Pix[i])
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestErodePolarity(t *testing.T) {
+	// um quadrado preto 5x5 num fundo branco: com BlackObjects ele encolhe
+	// para 3x3; com WhiteObjects é o fundo que encolhe e o quadrado cresce
+	img := uniformGray(11, 255)
+	for y := 3; y < 8; y++ {
+		for x := 3; x < 8; x++ {
+			img.Pix[y*img.Stride+x] = 0
+		}
+	}
+	black := func(g *image.Gray) int {
+		n := 0
+		for _, v := range g.Pix {
+			if v == 0 {
+				n++
+			}
+		}
+		return n
+	}
+	tests := []struct {
+		name string
+		got  *image.Gray
+		want int
+	}{
+		{"erosão de objetos pretos", Erode(img, SquareSE(3), BlackObjects), 9},
+		{"dilatação de objetos pretos", Dilate(img, SquareSE(3), BlackObjects), 49},
+		{"erosão de objetos brancos", Erode(img, SquareSE(3), WhiteObjects), 49},
+		{"dilatação de objetos brancos", Dilate(img, SquareSE(3), WhiteObjects), 9},
+		{"cruz", Dilate(img, CrossSE(3), BlackObjects), 45},
+	}
+	for _, tt := range tests {
+		if n := black(tt.got); n != tt.want {
+			t.Errorf("%s: %d pixels pretos, esperado %d", tt.name, n, tt.want)
+		}
+	}
+}
+
+func TestStructuringElements(t *testing.T) {
+	count := func(se StructuringElement) int {
+		n := 0
+		for _, row := range se.Mask {
+			for _, on := range row {
+				if on {
+					n++
+				}
+			}
+		}
+		return n
+	}
+	tests := []struct {
+		name     string
+		se       StructuringElement
+		size, on int
+		anchor   int
+	}{
+		{"quadrado 3", SquareSE(3), 3, 9, 1},
+		{"quadrado 0 vira 1", SquareSE(0), 1, 1, 0},
+		{"disco 1", DiskSE(1), 3, 5, 1},
+		{"disco 2", DiskSE(2), 5, 13, 2},
+		{"disco 3", DiskSE(3), 7, 29, 3},
+		{"cruz 5", CrossSE(5), 5, 9, 2},
+	}
+	for _, tt := range tests {
+		if len(tt.se.Mask) != tt.size || len(tt.se.Mask[0]) != tt.size || count(tt.se) != tt.on ||
+			tt.se.AnchorX != tt.anchor || tt.se.AnchorY != tt.anchor {
+			t.Errorf("%s: %dx%d com %d posições e âncora (%d, %d)", tt.name,
+				len(tt.se.Mask[0]), len(tt.se.Mask), count(tt.se), tt.se.AnchorX, tt.se.AnchorY)
+		}
+	}
+
+	invalid := []struct {
+		name   string
+		mask   [][]bool
+		ax, ay int
+	}{
+		{"vazio", nil, 0, 0},
+		{"linhas desiguais", [][]bool{{true, true}, {true}}, 0, 0},
+		{"âncora fora", [][]bool{{true, true}}, 2, 0},
+		{"âncora negativa", [][]bool{{true}}, 0, -1},
+	}
+	for _, tt := range invalid {
+		if _, err := NewStructuringElement(tt.mask, tt.ax, tt.ay); err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+		}
+	}
+}
+
+func TestParseStructuringElement(t *testing.T) {
+	tests := []struct {
+		s    string
+		size int
+		ok   bool
+	}{
+		{"square:5", 5, true},
+		{"disk:2", 5, true},
+		{"cross:3", 3, true},
+		{"square:0", 0, false},
+		{"square", 0, false},
+		{"hexagon:3", 0, false},
+	}
+	for _, tt := range tests {
+		se, err := ParseStructuringElement(tt.s)
+		if (err == nil) != tt.ok {
+			t.Errorf("%q: erro %v", tt.s, err)
+			continue
+		}
+		if tt.ok && len(se.Mask) != tt.size {
+			t.Errorf("%q: tamanho %d, esperado %d", tt.s, len(se.Mask), tt.size)
+		}
+	}
+}
+
+// litBlobs devolve um fundo em degradê linear (20 a 200 na horizontal) com
+// discos de raio 3 que sobem blob níveis acima do fundo (para baixo se blob
+// for negativo) e os centros dos discos.
+func litBlobs(blob int) (*image.Gray, []image.Point) {
+	img := image.NewGray(image.Rect(0, 0, 120, 60))
+	centers := []image.Point{{15, 15}, {50, 40}, {90, 20}, {105, 45}}
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 120; x++ {
+			v := 20 + 180*x/119
+			for _, c := range centers {
+				if dx, dy := x-c.X, y-c.Y; dx*dx+dy*dy <= 9 {
+					v += blob
+				}
+			}
+			img.Pix[y*img.Stride+x] = uint8(v)
+		}
+	}
+	return img, centers
+}
+
+func TestTopHatRemovesGradient(t *testing.T) {
+	tests := []struct {
+		name string
+		blob int
+		op   func(*image.Gray, StructuringElement) *image.Gray
+	}{
+		{"top-hat", 50, TopHat},
+		{"black-hat", -15, BlackHat},
+	}
+	for _, tt := range tests {
+		img, centers := litBlobs(tt.blob)
+		result := tt.op(img, DiskSE(7))
+		want := tt.blob
+		if want < 0 {
+			want = -want
+		}
+		for _, c := range centers {
+			if v := int(result.Pix[c.Y*result.Stride+c.X]); v < want-2 || v > want+2 {
+				t.Errorf("%s: disco em %v com %d, esperado %d±2", tt.name, c, v, want)
+			}
+		}
+		// longe dos discos o degradê some; na borda o elemento é cortado e
+		// sobram alguns degraus da rampa
+		for y := 0; y < 60; y++ {
+			for x := 0; x < 120; x++ {
+				near := false
+				for _, c := range centers {
+					if dx, dy := x-c.X, y-c.Y; dx*dx+dy*dy <= 25 {
+						near = true
+					}
+				}
+				limit := uint8(2)
+				if x < 7 || y < 7 || x >= 113 || y >= 53 {
+					limit = 12
+				}
+				if v := result.Pix[y*result.Stride+x]; !near && v > limit {
+					t.Fatalf("%s: fundo em %d,%d com %d, esperado perto de 0", tt.name, x, y, v)
+				}
+			}
+		}
+	}
+}
+
+func TestDerivedOperators(t *testing.T) {
+	img := randomGray(25, 19, 3)
+	se := DiskSE(2)
+	equal := func(a, b *image.Gray) bool {
+		for i := range a.Pix {
+			if a.Pix[i] != b.Pix[i] {
+				return false
+			}
+		}
+		return true
+	}
+	opened, closed := Opening(img, se, WhiteObjects), Closing(img, se, WhiteObjects)
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"abertura é erosão e dilatação", equal(opened, Dilate(Erode(img, se, WhiteObjects), se, WhiteObjects))},
+		{"fechamento é dilatação e erosão", equal(closed, Erode(Dilate(img, se, WhiteObjects), se, WhiteObjects))},
+		{"abertura idempotente", equal(Opening(opened, se, WhiteObjects), opened)},
+		{"fechamento idempotente", equal(Closing(closed, se, WhiteObjects), closed)},
+		{"gradiente", equal(MorphGradient(img, se), subtract(Dilate(img, se, WhiteObjects), Erode(img, se, WhiteObjects)))},
+		{"top-hat", equal(TopHat(img, se), subtract(img, opened))},
+		{"black-hat", equal(BlackHat(img, se), subtract(closed, img))},
+		{"abertura preta é fechamento branco", equal(Opening(img, se, BlackObjects), closed)},
+	}
+	for _, tt := range tests {
+		if !tt.ok {
+			t.Error(tt.name)
+		}
+	}
+	for i := range img.Pix {
+		if opened.Pix[i] > img.Pix[i] || closed.Pix[i] < img.Pix[i] {
+			t.Fatalf("pixel %d: abertura %d e fechamento %d em volta de %d", i, opened.Pix[i], closed.Pix[i], img.Pix[i])
+		}
+	}
+}
+
+func TestSubtractSaturates(t *testing.T) {
+	a := image.NewGray(image.Rect(0, 0, 4, 1))
+	b := image.NewGray(image.Rect(0, 0, 4, 1))
+	copy(a.Pix, []uint8{10, 200, 0, 255})
+	copy(b.Pix, []uint8{20, 50, 0, 0})
+	got := subtract(a, b)
+	for i, want := range []uint8{0, 150, 0, 255} {
+		if got.Pix[i] != want {
+			t.Errorf("pixel %d = %d, esperado %d", i, got.Pix[i], want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// NLMeansOptions controla NLMeans.
+type NLMeansOptions struct {
+	H      float64 // decaimento dos pesos: perto do desvio do ruído; maior borra mais
+	Patch  int     // lado (ímpar) do patch comparado
+	Search int     // lado (ímpar) da janela onde os patches são procurados
+}
+
+// DefaultNLMeansOptions são os parâmetros usados pela linha de comando.
+var DefaultNLMeansOptions = NLMeansOptions{H: 10, Patch: 7, Search: 21}
+
+// NLMeans tira o ruído pelas médias não locais (Buades, Coll e Morel,
+// 2005): cada pixel vira a média dos pixels da janela de busca, pesados por
+// exp(−d/h²), em que d é a diferença quadrática média entre o patch em
+// volta de cada um e o patch em volta dele. Como patches parecidos se
+// repetem ao longo das bordas e texturas, elas ficam no lugar e só o ruído
+// é alisado. Para cada deslocamento da janela as diferenças dos patches
+// saem de uma imagem integral, O(1) por pixel em vez de O(patch²). Fora da
+// imagem a borda é replicada. As linhas são divididas entre goroutines.
+func NLMeans(img *image.Gray, opts NLMeansOptions) (*image.Gray, error) {
+	if !(opts.H > 0) || opts.Patch < 1 || opts.Patch%2 == 0 || opts.Search < 1 || opts.Search%2 == 0 {
+		return nil, fmt.Errorf("parâmetros do non-local means inválidos: h=%g, patch %d, busca %d (exige h > 0 e lados ímpares)", opts.H, opts.Patch, opts.Search)
+	}
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	if width == 0 || height == 0 {
+		return result, nil
+	}
+
+	// imagem com uma moldura replicada que cobre patch e busca
+	pr, sr := opts.Patch/2, opts.Search/2
+	pad := pr + sr
+	pw, ph := width+2*pad, height+2*pad
+	padded := make([]int32, pw*ph)
+	for y := 0; y < ph; y++ {
+		src := img.Pix[min(max(y-pad, 0), height-1)*img.Stride:]
+		for x := 0; x < pw; x++ {
+			padded[y*pw+x] = int32(src[min(max(x-pad, 0), width-1)])
+		}
+	}
+	scale := 1 / (opts.H * opts.H * float64(opts.Patch*opts.Patch))
+
+	parallelRows(height, func(y0, y1 int) {
+		rows := y1 - y0
+		// a região coberta pelos patches da faixa começa na linha y0+sr e
+		// na coluna sr de padded; o patch do pixel (x, y0+y) tem o canto
+		// em (x, y) da região
+		iw, ih := width+2*pr+1, rows+2*pr+1
+		integral := make([]int64, iw*ih)
+		weights := make([]float64, rows*width)
+		sums := make([]float64, rows*width)
+		for dy := -sr; dy <= sr; dy++ {
+			for dx := -sr; dx <= sr; dx++ {
+				for y := 0; y < ih-1; y++ {
+					here := padded[(y0+sr+y)*pw+sr:]
+					there := padded[(y0+sr+y+dy)*pw+sr+dx:]
+					var run int64
+					for x := 0; x < iw-1; x++ {
+						d := here[x] - there[x]
+						run += int64(d * d)
+						integral[(y+1)*iw+x+1] = integral[y*iw+x+1] + run
+					}
+				}
+				for y := 0; y < rows; y++ {
+					top, bottom := integral[y*iw:], integral[(y+opts.Patch)*iw:]
+					shifted := padded[(y0+y+pad+dy)*pw+pad+dx:]
+					for x := 0; x < width; x++ {
+						d := bottom[x+opts.Patch] - top[x+opts.Patch] - bottom[x] + top[x]
+						w := math.Exp(-float64(d) * scale)
+						weights[y*width+x] += w
+						sums[y*width+x] += w * float64(shifted[x])
+					}
+				}
+			}
+		}
+		for y := 0; y < rows; y++ {
+			for x := 0; x < width; x++ {
+				// o próprio pixel tem peso 1, então weights nunca é zero
+				result.Pix[(y0+y)*result.Stride+x] = uint8(math.Round(sums[y*width+x] / weights[y*width+x]))
+			}
+		}
+	})
+	return result, nil
+}
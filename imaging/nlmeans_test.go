@@ -0,0 +1,164 @@
+package imaging
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// grayPSNR é a relação sinal-ruído de pico, em dB, entre duas imagens do
+// mesmo tamanho.
+func grayPSNR(a, b *image.Gray) float64 {
+	var sum float64
+	w, h := a.Bounds().Dx(), a.Bounds().Dy()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			d := float64(a.Pix[y*a.Stride+x]) - float64(b.Pix[y*b.Stride+x])
+			sum += d * d
+		}
+	}
+	mse := sum / float64(w*h)
+	if mse == 0 {
+		return math.Inf(1)
+	}
+	return 10 * math.Log10(255*255/mse)
+}
+
+// referenceNLMeans é o non-local means direto, O(patch²) por par de
+// pixels, com a borda replicada.
+func referenceNLMeans(img *image.Gray, opts NLMeansOptions) *image.Gray {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	at := func(x, y int) float64 {
+		return float64(img.Pix[min(max(y, 0), h-1)*img.Stride+min(max(x, 0), w-1)])
+	}
+	pr, sr := opts.Patch/2, opts.Search/2
+	result := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var weights, sum float64
+			for dy := -sr; dy <= sr; dy++ {
+				for dx := -sr; dx <= sr; dx++ {
+					var d float64
+					for j := -pr; j <= pr; j++ {
+						for i := -pr; i <= pr; i++ {
+							diff := at(x+i, y+j) - at(x+dx+i, y+dy+j)
+							d += diff * diff
+						}
+					}
+					weight := math.Exp(-d / (opts.H * opts.H * float64(opts.Patch*opts.Patch)))
+					weights += weight
+					sum += weight * at(x+dx, y+dy)
+				}
+			}
+			result.Pix[y*result.Stride+x] = uint8(math.Round(sum / weights))
+		}
+	}
+	return result
+}
+
+func TestNLMeansMatchesReference(t *testing.T) {
+	tests := []struct {
+		name string
+		img  *image.Gray
+		opts NLMeansOptions
+	}{
+		{"aleatória 3/5", randomGray(15, 12, 1), NLMeansOptions{H: 30, Patch: 3, Search: 5}},
+		{"aleatória 5/7", randomGray(11, 14, 2), NLMeansOptions{H: 60, Patch: 5, Search: 7}},
+		{"patch 1", randomGray(9, 9, 3), NLMeansOptions{H: 20, Patch: 1, Search: 3}},
+		{"busca maior que a imagem", randomGray(4, 3, 4), NLMeansOptions{H: 40, Patch: 3, Search: 9}},
+		{"recorte", randomGray(30, 30, 5).SubImage(image.Rect(7, 9, 20, 21)).(*image.Gray), NLMeansOptions{H: 25, Patch: 3, Search: 5}},
+	}
+	for _, tt := range tests {
+		got, err := NLMeans(tt.img, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := referenceNLMeans(tt.img, tt.opts)
+		if !got.Bounds().Eq(want.Bounds()) {
+			t.Fatalf("%s: limites %v, esperado %v", tt.name, got.Bounds(), want.Bounds())
+		}
+		for i := range want.Pix {
+			if d := int(got.Pix[i]) - int(want.Pix[i]); d < -1 || d > 1 {
+				t.Fatalf("%s: pixel %d vale %d, esperado %d", tt.name, i, got.Pix[i], want.Pix[i])
+			}
+		}
+	}
+}
+
+func TestNLMeansPSNR(t *testing.T) {
+	clean, _ := synthetic.Squares(128, 128, 6, 24, 7)
+	for i, v := range clean.Pix {
+		clean.Pix[i] = 60 + v/255*130
+	}
+	tests := []struct {
+		sigma, h float64
+		gain     float64 // ganho mínimo de PSNR sobre a entrada ruidosa, em dB
+	}{
+		{10, 10, 6},
+		{20, 20, 6},
+		{30, 30, 6},
+	}
+	for _, tt := range tests {
+		noisy, err := synthetic.AddGaussianNoise(clean, tt.sigma, 11)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts := DefaultNLMeansOptions
+		opts.H = tt.h
+		denoised, err := NLMeans(noisy, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before, after := grayPSNR(noisy, clean), grayPSNR(denoised, clean)
+		if after < before+tt.gain {
+			t.Errorf("σ=%g: PSNR %.1f dB depois, %.1f dB antes (esperado ganho >= %g dB)", tt.sigma, after, before, tt.gain)
+		}
+	}
+}
+
+func TestNLMeansUniform(t *testing.T) {
+	got, err := NLMeans(uniformGray(20, 77), DefaultNLMeansOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range got.Pix {
+		if v != 77 {
+			t.Fatalf("pixel %d mudou para %d", i, v)
+		}
+	}
+	empty, err := NLMeans(image.NewGray(image.Rect(0, 0, 0, 5)), DefaultNLMeansOptions)
+	if err != nil || !empty.Bounds().Empty() {
+		t.Errorf("imagem vazia: %v, %v", empty.Bounds(), err)
+	}
+}
+
+func TestNLMeansInvalidOptions(t *testing.T) {
+	img := uniformGray(8, 10)
+	for _, opts := range []NLMeansOptions{
+		{H: 0, Patch: 7, Search: 21},
+		{H: math.NaN(), Patch: 7, Search: 21},
+		{H: 10, Patch: 6, Search: 21},
+		{H: 10, Patch: 0, Search: 21},
+		{H: 10, Patch: 7, Search: 20},
+		{H: 10, Patch: 7, Search: -1},
+	} {
+		if _, err := NLMeans(img, opts); err == nil {
+			t.Errorf("%+v: sem erro", opts)
+		}
+	}
+}
+
+func BenchmarkNLMeans(b *testing.B) {
+	img, err := synthetic.AddGaussianNoise(synthetic.SiemensStar(256, 256, 16), 15, 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NLMeans(img, DefaultNLMeansOptions); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
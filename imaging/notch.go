@@ -0,0 +1,141 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"math/cmplx"
+	"sort"
+
+	"processing-images/dft"
+)
+
+// NotchFilter tira ruído periódico (como a trama de uma imagem impressa
+// escaneada) zerando no espectro um disco de raio radius em volta de cada
+// frequência de centers e da sua simétrica, e voltando pela transformada
+// inversa. Cada centro é o deslocamento (u, v) da frequência zero em
+// pixels do espectro da imagem original: um pico em (x, y) de
+// spectrum.png é o centro (x − w/2, y − h/2). Ao contrário de
+// FrequencyFilter, a transformada é da imagem sem extensão: a reflexão
+// espelharia o padrão e criaria picos que os notches não pegam.
+func NotchFilter(img *image.Gray, centers []image.Point, radius float64) (*image.Gray, error) {
+	if !(radius > 0) {
+		return nil, fmt.Errorf("o raio do notch deve ser positivo, não %g", radius)
+	}
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if width == 0 || height == 0 {
+		return image.NewGray(image.Rect(0, 0, width, height)), nil
+	}
+	g := dft.FFTOfGray(img)
+	for v, row := range g {
+		fv := spectrumFrequency(v, height, height)
+		for u := range row {
+			fu := spectrumFrequency(u, width, width)
+			for _, c := range centers {
+				if math.Hypot(fu-float64(c.X), fv-float64(c.Y)) <= radius ||
+					math.Hypot(fu+float64(c.X), fv+float64(c.Y)) <= radius {
+					row[u] = 0
+					break
+				}
+			}
+		}
+	}
+	dft.IFFT2(g)
+	return realToGray(g, width, height), nil
+}
+
+// FindSpectralPeaks procura os n picos mais fortes do espectro fora do
+// centro, para NotchFilter: máximos locais cuja magnitude passa threshold
+// vezes a média do anel à mesma distância da frequência zero (o espectro
+// de uma imagem natural cai com a frequência, então a comparação é com os
+// vizinhos de anel e não com o espectro todo). Frequências a menos de
+// minRadius do centro, onde fica a maior parte da imagem, são ignoradas.
+// De cada par simétrico só um entra, com v > 0 (ou v = 0 e u > 0), mais
+// forte primeiro, nas coordenadas de NotchFilter.
+func FindSpectralPeaks(img *image.Gray, n int, threshold, minRadius float64) []image.Point {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if width == 0 || height == 0 || n <= 0 {
+		return nil
+	}
+	// a janela de Hann tira a cruz nos eixos que as bordas opostas
+	// diferentes deixariam no espectro; é só para achar os picos
+	g := make([][]complex128, height)
+	for y := range g {
+		g[y] = make([]complex128, width)
+		wy := hann(y, height)
+		for x := range g[y] {
+			g[y][x] = complex(float64(img.Pix[y*img.Stride+x])*wy*hann(x, width), 0)
+		}
+	}
+	dft.FFT2(g)
+
+	mag := make([][]float64, height)
+	var ringSum []float64
+	var ringCount []int
+	signed := func(i, n int) int {
+		if i > n/2 {
+			return i - n
+		}
+		return i
+	}
+	ring := func(u, v int) int {
+		return int(math.Round(math.Hypot(float64(signed(u, width)), float64(signed(v, height)))))
+	}
+	for v := range g {
+		mag[v] = make([]float64, width)
+		for u, c := range g[v] {
+			mag[v][u] = cmplx.Abs(c)
+			r := ring(u, v)
+			for len(ringSum) <= r {
+				ringSum = append(ringSum, 0)
+				ringCount = append(ringCount, 0)
+			}
+			ringSum[r] += mag[v][u]
+			ringCount[r]++
+		}
+	}
+
+	type peak struct {
+		at    image.Point
+		score float64
+	}
+	var peaks []peak
+	for v := 0; v <= height/2; v++ {
+		for u := 0; u < width; u++ {
+			fu, fv := signed(u, width), signed(v, height)
+			if (v == 0 || v == height/2) && fu <= 0 || math.Hypot(float64(fu), float64(fv)) < minRadius {
+				continue
+			}
+			m := mag[v][u]
+			local := true
+			for dv := -1; dv <= 1 && local; dv++ {
+				for du := -1; du <= 1; du++ {
+					if (du != 0 || dv != 0) && mag[(v+dv+height)%height][(u+du+width)%width] > m {
+						local = false
+						break
+					}
+				}
+			}
+			r := ring(u, v)
+			mean := ringSum[r] / float64(ringCount[r])
+			if local && mean > 0 && m >= threshold*mean {
+				peaks = append(peaks, peak{image.Pt(fu, fv), m / mean})
+			}
+		}
+	}
+	sort.SliceStable(peaks, func(i, j int) bool { return peaks[i].score > peaks[j].score })
+
+	points := make([]image.Point, 0, min(n, len(peaks)))
+	for _, p := range peaks[:cap(points)] {
+		points = append(points, p.at)
+	}
+	return points
+}
+
+// hann é o peso da janela de Hann na posição i de n.
+func hann(i, n int) float64 {
+	if n < 2 {
+		return 1
+	}
+	return 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1))
+}
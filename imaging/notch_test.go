@@ -0,0 +1,157 @@
+package imaging
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// rmsDiff é a raiz da diferença quadrática média entre a e b.
+func rmsDiff(a, b *image.Gray) float64 {
+	var sum float64
+	w, h := a.Bounds().Dx(), a.Bounds().Dy()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			d := float64(a.Pix[y*a.Stride+x]) - float64(b.Pix[y*b.Stride+x])
+			sum += d * d
+		}
+	}
+	return math.Sqrt(sum / float64(w*h))
+}
+
+// halftoneScene é a imagem limpa, quadrados claros suavizados em fundo
+// escuro, e ela com uma trama de cossenos de amplitude 25 em cada
+// frequência (u, v) de patterns e um pouco de ruído, como um scanner. Sem a
+// suavização as bordas retas dos quadrados deixariam picos nos eixos do
+// espectro, e sem o ruído os harmônicos do arredondamento da trama
+// passariam o espectro quase vazio da imagem lisa.
+func halftoneScene(patterns ...image.Point) (clean, noisy *image.Gray) {
+	const width, height = 128, 96
+	squares, _ := synthetic.Squares(width, height, 5, 20, 4)
+	for i, v := range squares.Pix {
+		squares.Pix[i] = 190 - v/255*120
+	}
+	clean = GaussianBlur(squares, 2)
+	noisy = image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := float64(clean.Pix[y*clean.Stride+x])
+			for _, p := range patterns {
+				v += 25 * math.Cos(2*math.Pi*(float64(p.X*x)/width+float64(p.Y*y)/height))
+			}
+			noisy.Pix[y*noisy.Stride+x] = uint8(math.Max(0, math.Min(255, math.Round(v))))
+		}
+	}
+	noisy, _ = synthetic.AddGaussianNoise(noisy, 2, 8)
+	return clean, noisy
+}
+
+func TestNotchFilterRemovesPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []image.Point
+		centers  []image.Point
+	}{
+		{"diagonal", []image.Point{{12, 9}}, []image.Point{{12, 9}}},
+		// o simétrico também vale
+		{"diagonal simétrico", []image.Point{{12, 9}}, []image.Point{{-12, -9}}},
+		{"duas tramas", []image.Point{{20, 0}, {0, 15}}, []image.Point{{20, 0}, {0, 15}}},
+		{"inclinada para o outro lado", []image.Point{{-16, 10}}, []image.Point{{16, -10}}},
+	}
+	for _, tt := range tests {
+		clean, noisy := halftoneScene(tt.patterns...)
+		got, err := NotchFilter(noisy, tt.centers, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before, after := rmsDiff(noisy, clean), rmsDiff(got, clean)
+		if before < 15 || after > 5 {
+			t.Errorf("%s: RMS %.1f com a trama, %.1f depois do notch (esperado <= 5)", tt.name, before, after)
+		}
+	}
+
+	// o notch no lugar errado não tira a trama
+	clean, noisy := halftoneScene(image.Pt(12, 9))
+	got, _ := NotchFilter(noisy, []image.Point{{12, -9}}, 3)
+	if rmsDiff(got, clean) < 15 {
+		t.Error("notch fora do pico tirou a trama")
+	}
+}
+
+func TestFindSpectralPeaks(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []image.Point
+		want     []image.Point // em qualquer ordem, com v > 0 ou v = 0 e u > 0
+	}{
+		{"uma trama", []image.Point{{12, 9}}, []image.Point{{12, 9}}},
+		{"simétrico normalizado", []image.Point{{-12, -9}}, []image.Point{{12, 9}}},
+		{"duas tramas", []image.Point{{20, 0}, {0, 15}}, []image.Point{{20, 0}, {0, 15}}},
+		{"sem trama", nil, nil},
+	}
+	for _, tt := range tests {
+		_, noisy := halftoneScene(tt.patterns...)
+		got := FindSpectralPeaks(noisy, 4, 10, 8)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: picos %v, esperado %v", tt.name, got, tt.want)
+			continue
+		}
+		for _, w := range tt.want {
+			found := false
+			for _, g := range got {
+				found = found || g == w
+			}
+			if !found {
+				t.Errorf("%s: picos %v sem %v", tt.name, got, w)
+			}
+		}
+	}
+
+	// n limita a quantidade e o mais forte vem primeiro
+	_, noisy := halftoneScene(image.Pt(20, 0), image.Pt(0, 15))
+	if got := FindSpectralPeaks(noisy, 1, 10, 8); len(got) != 1 {
+		t.Errorf("n=1: picos %v", got)
+	}
+	if got := FindSpectralPeaks(noisy, 0, 10, 8); got != nil {
+		t.Errorf("n=0: picos %v", got)
+	}
+	// minRadius acima das tramas não acha nada perto do centro
+	if got := FindSpectralPeaks(noisy, 4, 10, 30); len(got) != 0 {
+		t.Errorf("raio mínimo 30: picos %v", got)
+	}
+}
+
+func TestNotchFilterAuto(t *testing.T) {
+	clean, noisy := halftoneScene(image.Pt(24, 6), image.Pt(-10, 20))
+	got, err := NotchFilter(noisy, FindSpectralPeaks(noisy, 4, 10, 8), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rms := rmsDiff(got, clean); rms > 5 {
+		t.Errorf("RMS %.1f depois do notch automático", rms)
+	}
+}
+
+func TestNotchFilterEdgeCases(t *testing.T) {
+	img := randomGray(30, 20, 2)
+	same, err := NotchFilter(img, nil, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range img.Pix {
+		if same.Pix[i] != img.Pix[i] {
+			t.Fatalf("sem centros o pixel %d mudou", i)
+		}
+	}
+	for _, radius := range []float64{0, -1, math.NaN()} {
+		if _, err := NotchFilter(img, []image.Point{{3, 3}}, radius); err == nil {
+			t.Errorf("raio %g: sem erro", radius)
+		}
+	}
+	empty, err := NotchFilter(image.NewGray(image.Rect(0, 0, 0, 4)), nil, 3)
+	if err != nil || !empty.Bounds().Empty() {
+		t.Errorf("imagem vazia: %v, %v", empty.Bounds(), err)
+	}
+}
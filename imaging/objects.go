@@ -0,0 +1,158 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+)
+
+// CountObjectsOptions ajusta a limpeza e a contagem de CountObjects.
+type CountObjectsOptions struct {
+	// MinArea é a área mínima, em pixels, de um componente contado
+	MinArea int
+	// Size é o lado do elemento quadrado da abertura e do fechamento
+	Size int
+	// Iterations é o número de dilatações da abertura e de passadas do
+	// fechamento. A abertura faz uma erosão a menos que dilatações, como
+	// sempre fez, o que devolve aos objetos parte do que a suavização come.
+	// 0 desliga a limpeza toda (nem a suavização) e conta os componentes
+	// como estão.
+	Iterations int
+	// Connectivity é a vizinhança dos componentes, 4 ou 8
+	Connectivity int
+}
+
+// DefaultCountObjectsOptions são os parâmetros usados pela linha de comando.
+var DefaultCountObjectsOptions = CountObjectsOptions{MinArea: 10, Size: 7, Iterations: 3, Connectivity: 8}
+
+// CountObjects conta os objetos pretos (0) de uma imagem binária depois de
+// suavizar e passar uma abertura e um fechamento com um quadrado
+// opts.Size×opts.Size; componentes com menos de opts.MinArea pixels são
+// ignoradas.
+func CountObjects(img *image.Gray, opts CountObjectsOptions) (int, error) {
+	return CountObjectsMask(img, nil, opts)
+}
+
+// CountObjectsMask conta só os objetos dentro da máscara (pixels != 0);
+// mask nil considera a imagem toda. A máscara deve ter as dimensões da
+// imagem.
+func CountObjectsMask(img *image.Gray, mask *image.Gray, opts CountObjectsOptions) (int, error) {
+	if err := checkMask(img, mask); err != nil {
+		return 0, err
+	}
+	if opts.Size < 1 {
+		return 0, fmt.Errorf("elemento da contagem de objetos deve ser positivo, não %d", opts.Size)
+	}
+	if opts.Iterations < 0 {
+		return 0, fmt.Errorf("iterações da contagem de objetos não podem ser negativas: %d", opts.Iterations)
+	}
+	closed := img
+	if opts.Iterations > 0 {
+		closed = cleanObjects(img, opts)
+	}
+
+	// fora da região de interesse tudo é fundo
+	if mask != nil {
+		closed = ApplyMask(closed, mask, 255)
+	}
+
+	labels, n, err := LabelComponents(closed, opts.Connectivity, BlackObjects)
+	if err != nil {
+		return 0, err
+	}
+	area := make([]int, n+1)
+	for _, row := range labels {
+		for _, l := range row {
+			area[l]++
+		}
+	}
+
+	var count int
+	for _, a := range area[1:] {
+		if a >= opts.MinArea {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// cleanObjects suaviza a imagem com uma média 3x3 e passa a abertura e o
+// fechamento de CountObjects; devolve uma máscara 0/255.
+func cleanObjects(img *image.Gray, opts CountObjectsOptions) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	smoothImg := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 1; y < height-1; y++ {
+		for x := 1; x < width-1; x++ {
+			var sum int
+			for j := -1; j <= 1; j++ {
+				row := img.Pix[img.PixOffset(img.Bounds().Min.X+x-1, img.Bounds().Min.Y+y+j):]
+				sum += int(row[0]) + int(row[1]) + int(row[2])
+			}
+			smoothImg.Pix[y*smoothImg.Stride+x] = uint8(sum / 9)
+		}
+	}
+
+	// a morfologia só distingue o objeto (0) do resto
+	for i, v := range smoothImg.Pix {
+		if v != 0 {
+			smoothImg.Pix[i] = 255
+		}
+	}
+
+	se := SquareSE(opts.Size)
+	offset := se.AnchorX
+	// a borda de offset pixels fica 0 (preto), como sempre foi
+	erode := func(src *image.Gray) *image.Gray {
+		return blackBorder(Erode(src, se, BlackObjects), offset)
+	}
+	dilate := func(src *image.Gray) *image.Gray {
+		return blackBorder(Dilate(src, se, BlackObjects), offset)
+	}
+
+	opened := smoothImg
+	for i := 0; i < opts.Iterations-1; i++ {
+		opened = erode(opened)
+	}
+	for i := 0; i < opts.Iterations; i++ {
+		opened = dilate(opened)
+	}
+	closed := opened
+	for i := 0; i < opts.Iterations; i++ {
+		closed = dilate(closed)
+	}
+	for i := 0; i < opts.Iterations; i++ {
+		closed = erode(closed)
+	}
+	return closed
+}
+
+// blackBorder zera a moldura de n pixels da imagem e a devolve.
+func blackBorder(img *image.Gray, n int) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	for y := 0; y < height; y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+width]
+		if y < n || y >= height-n {
+			clear(row)
+			continue
+		}
+		clear(row[:min(n, width)])
+		clear(row[max(width-n, 0):])
+	}
+	return img
+}
+
+// ApplyMask devolve uma cópia da imagem com os pixels fora da máscara
+// trocados por fill.
+func ApplyMask(img, mask *image.Gray, fill uint8) *image.Gray {
+	b := img.Bounds()
+	result := image.NewGray(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			v := img.Pix[img.PixOffset(b.Min.X+x, b.Min.Y+y)]
+			if mask.Pix[y*mask.Stride+x] == 0 {
+				v = fill
+			}
+			result.Pix[y*result.Stride+x] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,221 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// referenceCleanObjects é a limpeza de CountObjects como era escrita antes,
+// com GrayAt e SetGray pixel a pixel: média 3x3, três erosões e dilatações
+// 7x7 e a borda de 3 pixels preta.
+func referenceCleanObjects(img *image.Gray) *image.Gray {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	smooth := image.NewGray(image.Rect(0, 0, width, height))
+	for x := 1; x < width-1; x++ {
+		for y := 1; y < height-1; y++ {
+			sum := 0
+			for i := -1; i <= 1; i++ {
+				for j := -1; j <= 1; j++ {
+					sum += int(img.GrayAt(b.Min.X+x+i, b.Min.Y+y+j).Y)
+				}
+			}
+			smooth.SetGray(x, y, color.Gray{uint8(sum / 9)})
+		}
+	}
+
+	const offset = 3
+	morph := func(src *image.Gray, erode bool) *image.Gray {
+		result := image.NewGray(src.Bounds())
+		for y := offset; y < height-offset; y++ {
+			for x := offset; x < width-offset; x++ {
+				blacks := 0
+				for i := -offset; i <= offset; i++ {
+					for j := -offset; j <= offset; j++ {
+						if src.GrayAt(x+i, y+j).Y == 0 {
+							blacks++
+						}
+					}
+				}
+				black := blacks > 0
+				if erode {
+					black = blacks == (2*offset+1)*(2*offset+1)
+				}
+				if black {
+					result.SetGray(x, y, color.Gray{0})
+				} else {
+					result.SetGray(x, y, color.Gray{255})
+				}
+			}
+		}
+		return result
+	}
+
+	opened := morph(morph(smooth, true), true)
+	for i := 0; i < 3; i++ {
+		opened = morph(opened, false)
+	}
+	closed := opened
+	for i := 0; i < 3; i++ {
+		closed = morph(closed, false)
+	}
+	for i := 0; i < 3; i++ {
+		closed = morph(closed, true)
+	}
+	return closed
+}
+
+// countFixtures são as imagens de referência da contagem de objetos.
+func countFixtures() map[string]*image.Gray {
+	circles, _ := synthetic.Circles(320, 240, synthetic.CircleOptions{Count: 8, Radius: 18, Seed: 3})
+	squares, _ := synthetic.Squares(320, 240, 6, 30, 4)
+	noisy := synthetic.Noise(160, 120, 128, 60, 5)
+	offset := image.NewGray(image.Rect(0, 0, 200, 150))
+	copy(offset.Pix, synthetic.SiemensStar(200, 150, 16).Pix)
+	return map[string]*image.Gray{
+		"círculos":  circles,
+		"quadrados": squares,
+		"ruído":     Otsu(noisy),
+		"recorte":   Otsu(offset).SubImage(image.Rect(30, 20, 170, 130)).(*image.Gray),
+	}
+}
+
+func TestCleanObjectsGolden(t *testing.T) {
+	for name, img := range countFixtures() {
+		got := cleanObjects(img, DefaultCountObjectsOptions)
+		want := referenceCleanObjects(img)
+		if !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("%s: máscara limpa difere da versão com GrayAt", name)
+		}
+	}
+}
+
+func TestCountObjectsFixtures(t *testing.T) {
+	tests := []struct {
+		name string
+		opts CountObjectsOptions
+	}{
+		{"padrão", DefaultCountObjectsOptions},
+		{"sem limpeza", CountObjectsOptions{MinArea: 10, Size: 1, Connectivity: 8}},
+		{"4-vizinhança", CountObjectsOptions{MinArea: 1, Size: 3, Iterations: 1, Connectivity: 4}},
+	}
+	fixtures := countFixtures()
+	for _, tt := range tests {
+		for name, img := range fixtures {
+			got, err := CountObjects(img, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// a cópia compacta da imagem tem de dar a mesma contagem
+			compact := image.NewGray(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+			for y := 0; y < compact.Bounds().Dy(); y++ {
+				for x := 0; x < compact.Bounds().Dx(); x++ {
+					compact.SetGray(x, y, img.GrayAt(img.Bounds().Min.X+x, img.Bounds().Min.Y+y))
+				}
+			}
+			want, err := CountObjects(compact, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("%s, %s: %d objetos, esperado %d", tt.name, name, got, want)
+			}
+		}
+	}
+}
+
+func BenchmarkCountObjects(b *testing.B) {
+	img, _ := synthetic.Circles(1000, 750, synthetic.CircleOptions{Count: 60, Radius: 20, Seed: 1})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := CountObjects(img, DefaultCountObjectsOptions); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// specksImage devolve uma imagem branca 200x180 com dois quadrados pretos
+// 20x20, três quadrados 4x4 e quatro pontos de 1 pixel, a pelo menos 40
+// pixels da borda (a limpeza padrão faz a moldura crescer até 21).
+func specksImage() *image.Gray {
+	img := uniformGray(200, 255).SubImage(image.Rect(0, 0, 200, 180)).(*image.Gray)
+	fill := func(r image.Rectangle) {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				img.Pix[y*img.Stride+x] = 0
+			}
+		}
+	}
+	fill(image.Rect(60, 60, 80, 80))
+	fill(image.Rect(110, 90, 130, 110))
+	for _, p := range []image.Point{{100, 60}, {65, 105}, {135, 65}} {
+		fill(image.Rect(p.X, p.Y, p.X+4, p.Y+4))
+	}
+	for _, p := range []image.Point{{90, 90}, {70, 120}, {140, 120}, {120, 55}} {
+		fill(image.Rect(p.X, p.Y, p.X+1, p.Y+1))
+	}
+	return img
+}
+
+func TestCountObjectsOptions(t *testing.T) {
+	img := specksImage()
+	tests := []struct {
+		name string
+		opts CountObjectsOptions
+		want int
+	}{
+		{"área mínima 1 conta os pontos", CountObjectsOptions{MinArea: 1, Size: 1, Connectivity: 8}, 9},
+		{"área mínima 10 ignora os pontos", CountObjectsOptions{MinArea: 10, Size: 1, Connectivity: 4}, 5},
+		{"área mínima 17 só os grandes", CountObjectsOptions{MinArea: 17, Size: 1, Connectivity: 8}, 2},
+		// com a limpeza ligada a moldura preta de Size/2 pixels que ela
+		// deixa conta como mais um objeto, como sempre foi
+		{"elemento 3 mantém os pequenos", CountObjectsOptions{MinArea: 1, Size: 3, Iterations: 1, Connectivity: 8}, 1 + 5},
+		{"elemento 7 apaga os pequenos", DefaultCountObjectsOptions, 1 + 2},
+	}
+	for _, tt := range tests {
+		n, err := CountObjects(img, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != tt.want {
+			t.Errorf("%s: %d objetos, esperado %d", tt.name, n, tt.want)
+		}
+	}
+
+	invalid := []CountObjectsOptions{
+		{MinArea: 1, Size: 0, Connectivity: 8},
+		{MinArea: 1, Size: 3, Iterations: -1, Connectivity: 8},
+		{MinArea: 1, Size: 3, Connectivity: 6},
+	}
+	for _, opts := range invalid {
+		if _, err := CountObjects(img, opts); err == nil {
+			t.Errorf("%+v: sem erro", opts)
+		}
+	}
+	if _, err := CountObjectsMask(img, uniformGray(10, 255), DefaultCountObjectsOptions); err == nil {
+		t.Error("máscara de outro tamanho sem erro")
+	}
+}
+
+func TestCountObjectsMask(t *testing.T) {
+	img := specksImage()
+	mask := image.NewGray(img.Bounds())
+	for y := 0; y < 90; y++ {
+		for x := 0; x < 100; x++ {
+			mask.Pix[y*mask.Stride+x] = 255
+		}
+	}
+	// só o quadrado grande de cima fica dentro; o ponto em 90,90 e o
+	// quadrado 4x4 em 100,60 ficam logo fora
+	n, err := CountObjectsMask(img, mask, CountObjectsOptions{MinArea: 1, Size: 1, Connectivity: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("%d objetos dentro da máscara, esperado 1", n)
+	}
+}
@@ -0,0 +1,124 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// Otsu binariza a imagem pelo limiar de Otsu: 255 acima do limiar, 0 no
+// resto.
+func Otsu(img *image.Gray) *image.Gray {
+	binary, _ := OtsuMask(img, nil)
+	return binary
+}
+
+// OtsuMask escolhe o limiar só com os pixels dentro da máscara (mask nil
+// usa todos) e binariza a imagem inteira. A máscara deve ter as dimensões
+// da imagem.
+func OtsuMask(img *image.Gray, mask *image.Gray) (*image.Gray, error) {
+	if err := checkMask(img, mask); err != nil {
+		return nil, err
+	}
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	threshold := OtsuLevel(MaskedHistogram(img, mask))
+
+	newImg := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		src := img.Pix[img.PixOffset(img.Bounds().Min.X, img.Bounds().Min.Y+y):]
+		dst := newImg.Pix[y*newImg.Stride:]
+		for x := 0; x < width; x++ {
+			if src[x] > threshold {
+				dst[x] = 255
+			}
+		}
+	}
+
+	return newImg, nil
+}
+
+// checkMask exige uma máscara (se houver) do tamanho da imagem.
+func checkMask(img, mask *image.Gray) error {
+	if mask != nil && mask.Bounds().Size() != img.Bounds().Size() {
+		return fmt.Errorf("máscara %v com dimensões diferentes da imagem %v", mask.Bounds().Size(), img.Bounds().Size())
+	}
+	return nil
+}
+
+// OtsuLevel é o limiar de Otsu do histograma: o nível que maximiza a
+// variância entre as classes.
+func OtsuLevel(histogram [256]int) uint8 {
+	totalPixels := 0
+	for _, c := range histogram {
+		totalPixels += c
+	}
+
+	var sum, sumB, wB, wF, varMax float64
+	for i := 0; i < 256; i++ {
+		sum += float64(i * histogram[i])
+	}
+
+	var threshold uint8
+	for t := 0; t < 256; t++ {
+		wB += float64(histogram[t])
+		if wB == 0 {
+			continue
+		}
+		wF = float64(totalPixels) - wB
+		if wF == 0 {
+			break
+		}
+
+		sumB += float64(t * histogram[t])
+		mB := sumB / wB
+		mF := (sum - sumB) / wF
+
+		varBetween := wB * wF * math.Pow(mB-mF, 2)
+		if varBetween > varMax {
+			varMax = varBetween
+			threshold = uint8(t)
+		}
+	}
+
+	return threshold
+}
+
+// Histogram conta os pixels de cada nível de cinza.
+func Histogram(img *image.Gray) [256]int {
+	return MaskedHistogram(img, nil)
+}
+
+// MaskedHistogram conta só os pixels em que a máscara (mesmas dimensões,
+// alinhada pelo canto) não é zero; mask nil conta todos.
+func MaskedHistogram(img *image.Gray, mask *image.Gray) [256]int {
+	var histogram [256]int
+	b := img.Bounds()
+	for y := 0; y < b.Dy(); y++ {
+		row := img.Pix[img.PixOffset(b.Min.X, b.Min.Y+y):img.PixOffset(b.Max.X, b.Min.Y+y)]
+		for x, v := range row {
+			if mask != nil && mask.Pix[y*mask.Stride+x] == 0 {
+				continue
+			}
+			histogram[v]++
+		}
+	}
+	return histogram
+}
+
+// HistogramPercentile devolve o menor nível com pelo menos a fração p dos
+// pixels abaixo ou nele.
+func HistogramPercentile(histogram [256]int, p float64) uint8 {
+	total := 0
+	for _, c := range histogram {
+		total += c
+	}
+	target := int(math.Ceil(p * float64(total)))
+	sum := 0
+	for v, c := range histogram {
+		sum += c
+		if sum >= target && sum > 0 {
+			return uint8(v)
+		}
+	}
+	return 255
+}
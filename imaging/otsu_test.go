@@ -0,0 +1,89 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// referenceOtsu binariza com GrayAt e SetGray, como antes da indexação
+// direta de Pix.
+func referenceOtsu(img *image.Gray) *image.Gray {
+	b := img.Bounds()
+	threshold := OtsuLevel(Histogram(img))
+	result := image.NewGray(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			if img.GrayAt(b.Min.X+x, b.Min.Y+y).Y > threshold {
+				result.SetGray(x, y, color.Gray{255})
+			} else {
+				result.SetGray(x, y, color.Gray{0})
+			}
+		}
+	}
+	return result
+}
+
+func TestOtsuGolden(t *testing.T) {
+	star := synthetic.SiemensStar(200, 150, 16)
+	tests := []struct {
+		name string
+		img  *image.Gray
+	}{
+		{"estrela", star},
+		{"recorte", star.SubImage(image.Rect(40, 30, 170, 120)).(*image.Gray)},
+		{"ruído", synthetic.Noise(123, 77, 120, 40, 2)},
+		{"xadrez", synthetic.Checkerboard(64, 48, 8)},
+	}
+	for _, tt := range tests {
+		got, want := Otsu(tt.img), referenceOtsu(tt.img)
+		if !bytes.Equal(got.Pix, want.Pix) || got.Bounds() != want.Bounds() {
+			t.Errorf("%s: binarização difere da versão com GrayAt", tt.name)
+		}
+	}
+}
+
+func TestGrayFloatRoundTrip(t *testing.T) {
+	img := synthetic.Noise(40, 30, 128, 50, 9)
+	sub := img.SubImage(image.Rect(5, 7, 35, 25)).(*image.Gray)
+	tests := []struct {
+		name string
+		img  *image.Gray
+	}{
+		{"inteira", img},
+		{"recorte", sub},
+	}
+	for _, tt := range tests {
+		b := tt.img.Bounds()
+		field := GrayToFloat(tt.img)
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				if field[y][x] != float64(tt.img.GrayAt(b.Min.X+x, b.Min.Y+y).Y) {
+					t.Fatalf("%s: campo em %d,%d = %v", tt.name, x, y, field[y][x])
+				}
+			}
+		}
+		back := FloatToGray(field, 255)
+		if back.Bounds() != image.Rect(0, 0, b.Dx(), b.Dy()) {
+			t.Fatalf("%s: limites %v", tt.name, back.Bounds())
+		}
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				if back.GrayAt(x, y) != tt.img.GrayAt(b.Min.X+x, b.Min.Y+y) {
+					t.Fatalf("%s: ida e volta muda o pixel %d,%d", tt.name, x, y)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkOtsu(b *testing.B) {
+	img := synthetic.Noise(1000, 750, 128, 40, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Otsu(img)
+	}
+}
@@ -0,0 +1,41 @@
+package imaging
+
+import (
+	"runtime"
+	"sync"
+)
+
+// workers é o número máximo de goroutines dos laços paralelos.
+var workers = runtime.NumCPU()
+
+// SetWorkers limita o paralelismo da convolução, do filtro box, da
+// morfologia (Erode, Dilate e a contagem de objetos), do CLAHE e do
+// NLMeans; n < 1 volta ao padrão, o número de CPUs. Deve ser chamada antes
+// do processamento, não durante.
+func SetWorkers(n int) {
+	if n < 1 {
+		n = runtime.NumCPU()
+	}
+	workers = n
+}
+
+// parallelRows divide as linhas [0, height) em faixas contíguas, uma por
+// goroutine, e espera todas terminarem. fn só pode escrever nas linhas da
+// própria faixa, então o resultado é o mesmo da versão sequencial.
+func parallelRows(height int, fn func(y0, y1 int)) {
+	n := min(workers, height)
+	if n <= 1 {
+		fn(0, height)
+		return
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		y0, y1 := i*height/n, (i+1)*height/n
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(y0, y1)
+		}()
+	}
+	wg.Wait()
+}
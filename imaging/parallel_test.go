@@ -0,0 +1,89 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+// withWorkers roda f com o paralelismo n e restaura o padrão no fim.
+func withWorkers(t testing.TB, n int, f func()) {
+	t.Helper()
+	SetWorkers(n)
+	defer SetWorkers(0)
+	f()
+}
+
+func TestParallelRowsCoverage(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 7, 64} {
+		for _, height := range []int{0, 1, 5, 100} {
+			counts := make([]int32, height)
+			withWorkers(t, n, func() {
+				parallelRows(height, func(y0, y1 int) {
+					for y := y0; y < y1; y++ {
+						atomic.AddInt32(&counts[y], 1)
+					}
+				})
+			})
+			for y, c := range counts {
+				if c != 1 {
+					t.Errorf("%d workers, altura %d: linha %d visitada %d vezes", n, height, y, c)
+				}
+			}
+		}
+	}
+}
+
+func TestParallelMatchesSequential(t *testing.T) {
+	img := randomGray(97, 61, 5)
+	binary := Otsu(img)
+	laplacian := [][]float64{{0, 1, 0}, {1, -4, 1}, {0, 1, 0}}
+	ops := []struct {
+		name string
+		run  func() []byte
+	}{
+		{"convolução", func() []byte {
+			out, _ := Convolve(img, laplacian, KernelNormalization{Mode: "auto"}, ResponseAbs, BorderReflect)
+			return out.Pix
+		}},
+		{"box 5x5", func() []byte {
+			out, _ := BoxFilter(img, 5, BorderReplicate)
+			return out.Pix
+		}},
+		{"erosão", func() []byte { return Erode(binary, SquareSE(5), BlackObjects).Pix }},
+		{"dilatação", func() []byte { return Dilate(binary, DiskSE(3), WhiteObjects).Pix }},
+		{"contagem de objetos", func() []byte {
+			n, _ := CountObjects(binary, DefaultCountObjectsOptions)
+			return []byte{byte(n)}
+		}},
+	}
+	for _, op := range ops {
+		var want []byte
+		withWorkers(t, 1, func() { want = op.run() })
+		for _, n := range []int{2, 3, 8, 200} {
+			var got []byte
+			withWorkers(t, n, func() { got = op.run() })
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s: %d workers diferem da versão sequencial", op.name, n)
+			}
+		}
+	}
+}
+
+// go test -bench Convolve3x3 ./imaging mostra o ganho com as goroutines.
+func BenchmarkConvolve3x3(b *testing.B) {
+	img := randomGray(2000, 1500, 1)
+	kernel := [][]float64{{1, 2, 1}, {2, 4, 2}, {1, 2, 1}}
+	for _, n := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", n), func(b *testing.B) {
+			withWorkers(b, n, func() {
+				for i := 0; i < b.N; i++ {
+					if _, err := Convolve(img, kernel, KernelNormalization{Mode: "auto"}, ResponseClamp, BorderReplicate); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
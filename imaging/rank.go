@@ -0,0 +1,110 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+)
+
+// MinFilter troca cada pixel pelo mínimo da janela size×size (em tamanhos
+// pares a âncora é a de KernelAnchor, logo depois do meio). Pixels fora da
+// imagem são ignorados, então é a erosão em tons de cinza com um quadrado,
+// Erode(img, SquareSE(size), WhiteObjects). Usa o algoritmo de van Herk e
+// Gil-Werman, O(1) por pixel para qualquer tamanho.
+func MinFilter(img *image.Gray, size int) (*image.Gray, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("tamanho do filtro de mínimo deve ser positivo, não %d", size)
+	}
+	return rectRankFilter(img, size/2, size-1-size/2, size/2, size-1-size/2, false), nil
+}
+
+// MaxFilter é o máximo da janela, a dilatação com um quadrado (nos
+// tamanhos pares, com o quadrado refletido); veja MinFilter.
+func MaxFilter(img *image.Gray, size int) (*image.Gray, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("tamanho do filtro de máximo deve ser positivo, não %d", size)
+	}
+	return rectRankFilter(img, size/2, size-1-size/2, size/2, size-1-size/2, true), nil
+}
+
+// MidpointFilter é o ponto médio da janela, (mínimo + máximo)/2
+// arredondado para cima: bom contra ruído uniforme ou gaussiano, ruim
+// contra sal e pimenta.
+func MidpointFilter(img *image.Gray, size int) (*image.Gray, error) {
+	low, err := MinFilter(img, size)
+	if err != nil {
+		return nil, err
+	}
+	high, _ := MaxFilter(img, size)
+	for i, v := range low.Pix {
+		low.Pix[i] = uint8((int(v) + int(high.Pix[i]) + 1) / 2)
+	}
+	return low, nil
+}
+
+// rectRankFilter devolve o máximo (ou o mínimo) do retângulo que vai de
+// left pixels à esquerda até right à direita e de up acima até down abaixo
+// de cada pixel, ignorando o que está fora da imagem. É separável: uma
+// passada nas linhas e outra nas colunas, cada uma por vanHerk.
+func rectRankFilter(img *image.Gray, left, right, up, down int, takeMax bool) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	rows := image.NewGray(image.Rect(0, 0, width, height))
+	parallelRows(height, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			vanHerk(rows.Pix[y*rows.Stride:y*rows.Stride+width], img.Pix[y*img.Stride:y*img.Stride+width], left, right, takeMax)
+		}
+	})
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	// as colunas são copiadas para uma linha contígua, filtradas e devolvidas
+	parallelRows(width, func(x0, x1 int) {
+		column := make([]uint8, height)
+		filtered := make([]uint8, height)
+		for x := x0; x < x1; x++ {
+			for y := range column {
+				column[y] = rows.Pix[y*rows.Stride+x]
+			}
+			vanHerk(filtered, column, up, down, takeMax)
+			for y, v := range filtered {
+				result.Pix[y*result.Stride+x] = v
+			}
+		}
+	})
+	return result
+}
+
+// vanHerk escreve em dst[i] o máximo (ou o mínimo) de src[i-before ..
+// i+after], ignorando as posições fora de src. O sinal é completado com o
+// elemento neutro e cortado em blocos do tamanho k da janela; dentro de
+// cada bloco guardam-se o acumulado da esquerda (g) e o da direita (h), e
+// toda janela cobre o fim de um bloco e o começo do seguinte:
+// dst[i] = op(h[i], g[i+k−1]). São 3 comparações por pixel, seja qual for k.
+func vanHerk(dst, src []uint8, before, after int, takeMax bool) {
+	k := before + after + 1
+	neutral := uint8(255)
+	op := func(a, b uint8) uint8 { return min(a, b) }
+	if takeMax {
+		neutral = 0
+		op = func(a, b uint8) uint8 { return max(a, b) }
+	}
+	n := len(src) + k - 1
+	n += (k - n%k) % k
+	padded := make([]uint8, n)
+	for i := range padded {
+		padded[i] = neutral
+	}
+	copy(padded[before:], src)
+
+	g, h := make([]uint8, n), make([]uint8, n)
+	for start := 0; start < n; start += k {
+		g[start] = padded[start]
+		for i := start + 1; i < start+k; i++ {
+			g[i] = op(g[i-1], padded[i])
+		}
+		h[start+k-1] = padded[start+k-1]
+		for i := start + k - 2; i >= start; i-- {
+			h[i] = op(h[i+1], padded[i])
+		}
+	}
+	for i := range dst {
+		dst[i] = op(h[i], g[i+k-1])
+	}
+}
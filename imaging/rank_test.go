@@ -0,0 +1,140 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"testing"
+)
+
+// referenceRank devolve o mínimo e o máximo diretos da janela size×size de
+// cada pixel, ignorando o que está fora da imagem.
+func referenceRank(img *image.Gray, size int) (low, high *image.Gray) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	low = image.NewGray(image.Rect(0, 0, w, h))
+	high = image.NewGray(image.Rect(0, 0, w, h))
+	before := size / 2
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			lo, hi := uint8(255), uint8(0)
+			for j := y - before; j < y-before+size; j++ {
+				for i := x - before; i < x-before+size; i++ {
+					if i < 0 || j < 0 || i >= w || j >= h {
+						continue
+					}
+					v := img.GrayAt(b.Min.X+i, b.Min.Y+j).Y
+					lo, hi = min(lo, v), max(hi, v)
+				}
+			}
+			low.Pix[y*low.Stride+x] = lo
+			high.Pix[y*high.Stride+x] = hi
+		}
+	}
+	return low, high
+}
+
+func TestRankFiltersMatchReference(t *testing.T) {
+	images := []struct {
+		name string
+		img  *image.Gray
+	}{
+		{"aleatória", randomGray(29, 21, 1)},
+		{"recorte", randomGray(50, 40, 2).SubImage(image.Rect(11, 7, 37, 30)).(*image.Gray)},
+		{"uma coluna", randomGray(1, 17, 3)},
+	}
+	for _, im := range images {
+		for _, size := range []int{1, 2, 3, 4, 5, 8, 11, 40} {
+			name := fmt.Sprintf("%s, %dx%d", im.name, size, size)
+			low, high := referenceRank(im.img, size)
+			tests := []struct {
+				filter string
+				apply  func(*image.Gray, int) (*image.Gray, error)
+				want   func(i int) uint8
+			}{
+				{"mínimo", MinFilter, func(i int) uint8 { return low.Pix[i] }},
+				{"máximo", MaxFilter, func(i int) uint8 { return high.Pix[i] }},
+				{"ponto médio", MidpointFilter, func(i int) uint8 { return uint8((int(low.Pix[i]) + int(high.Pix[i]) + 1) / 2) }},
+			}
+			for _, tt := range tests {
+				got, err := tt.apply(im.img, size)
+				if err != nil {
+					t.Fatalf("%s, %s: %v", name, tt.filter, err)
+				}
+				for i := range got.Pix {
+					if got.Pix[i] != tt.want(i) {
+						t.Fatalf("%s, %s: pixel %d vale %d, esperado %d", name, tt.filter, i, got.Pix[i], tt.want(i))
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRankFiltersAreSquareMorphology(t *testing.T) {
+	// nos tamanhos pares a dilatação usa o elemento refletido, então a
+	// janela do máximo só coincide com a dela nos ímpares
+	img := randomGray(33, 25, 4)
+	for _, size := range []int{1, 3, 6, 9} {
+		low, _ := MinFilter(img, size)
+		high, _ := MaxFilter(img, size)
+		erode, dilate := Erode(img, SquareSE(size), WhiteObjects), Dilate(img, SquareSE(size), WhiteObjects)
+		for i := range low.Pix {
+			if low.Pix[i] != erode.Pix[i] {
+				t.Fatalf("%dx%d: pixel %d difere da erosão", size, size, i)
+			}
+			if size%2 == 1 && high.Pix[i] != dilate.Pix[i] {
+				t.Fatalf("%dx%d: pixel %d difere da dilatação", size, size, i)
+			}
+		}
+	}
+}
+
+func TestVanHerk(t *testing.T) {
+	src := []uint8{5, 1, 9, 3, 7, 2, 8}
+	tests := []struct {
+		before, after int
+		takeMax       bool
+		want          []uint8
+	}{
+		{1, 1, true, []uint8{5, 9, 9, 9, 7, 8, 8}},
+		{1, 1, false, []uint8{1, 1, 1, 3, 2, 2, 2}},
+		{0, 2, true, []uint8{9, 9, 9, 7, 8, 8, 8}},
+		{2, 0, false, []uint8{5, 1, 1, 1, 3, 2, 2}},
+		{0, 0, true, []uint8{5, 1, 9, 3, 7, 2, 8}},
+		{10, 10, true, []uint8{9, 9, 9, 9, 9, 9, 9}},
+	}
+	for _, tt := range tests {
+		got := make([]uint8, len(src))
+		vanHerk(got, src, tt.before, tt.after, tt.takeMax)
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("antes %d, depois %d, máximo %v: %v, esperado %v", tt.before, tt.after, tt.takeMax, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestRankFilterErrors(t *testing.T) {
+	img := uniformGray(4, 1)
+	for _, apply := range []func(*image.Gray, int) (*image.Gray, error){MinFilter, MaxFilter, MidpointFilter} {
+		for _, size := range []int{0, -2} {
+			if _, err := apply(img, size); err == nil {
+				t.Errorf("tamanho %d: sem erro", size)
+			}
+		}
+	}
+}
+
+func BenchmarkMaxFilter(b *testing.B) {
+	img := randomGray(1024, 1024, 1)
+	for _, size := range []int{3, 15, 61} {
+		b.Run(fmt.Sprintf("%dx%d", size, size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := MaxFilter(img, size); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
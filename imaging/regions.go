@@ -0,0 +1,90 @@
+package imaging
+
+import (
+	"image"
+	"math"
+)
+
+// Region são as medidas de um objeto rotulado.
+type Region struct {
+	Label    int
+	Area     int
+	Centroid [2]float64 // x, y
+	// Bounds é o retângulo envolvente, com Max exclusivo como em image.Rectangle
+	Bounds image.Rectangle
+	// Perimeter conta os pixels de contorno: os do objeto com algum vizinho
+	// de lado (vizinhança 4) fora dele ou fora da imagem. Eles formam o
+	// contorno 8-conectado; um objeto de 1 pixel tem perímetro 1.
+	Perimeter int
+	// EquivalentDiameter é o diâmetro do círculo com a mesma área, √(4A/π).
+	EquivalentDiameter float64
+	// Moments são os momentos do objeto, os mesmos de RegionMoments
+	Moments Moments
+}
+
+// RegionProps mede cada rótulo > 0 de labels ([y][x], como os de
+// LabelComponents) e devolve as regiões em ordem de rótulo; rótulos sem
+// nenhum pixel não aparecem.
+func RegionProps(labels [][]int) []Region {
+	height := len(labels)
+	width := 0
+	if height > 0 {
+		width = len(labels[0])
+	}
+	maxLabel := 0
+	for _, row := range labels {
+		for _, l := range row {
+			maxLabel = max(maxLabel, l)
+		}
+	}
+
+	regions := make([]Region, maxLabel+1)
+	same := func(x, y, l int) bool {
+		return x >= 0 && y >= 0 && x < width && y < height && labels[y][x] == l
+	}
+	for y, row := range labels {
+		for x, l := range row {
+			if l <= 0 {
+				continue
+			}
+			r := &regions[l]
+			pixel := image.Rect(x, y, x+1, y+1)
+			if r.Area == 0 {
+				r.Bounds = pixel
+			} else {
+				r.Bounds = r.Bounds.Union(pixel)
+			}
+			r.Area++
+			r.Moments.addRaw(float64(x), float64(y))
+			if !same(x-1, y, l) || !same(x+1, y, l) || !same(x, y-1, l) || !same(x, y+1, l) {
+				r.Perimeter++
+			}
+		}
+	}
+
+	for l := range regions {
+		if r := &regions[l]; r.Area > 0 {
+			r.Centroid = [2]float64{r.Moments.M10 / r.Moments.M00, r.Moments.M01 / r.Moments.M00}
+		}
+	}
+	for y, row := range labels {
+		for x, l := range row {
+			if l > 0 {
+				c := regions[l].Centroid
+				regions[l].Moments.addCentral(float64(x)-c[0], float64(y)-c[1])
+			}
+		}
+	}
+
+	var result []Region
+	for l, r := range regions {
+		if r.Area == 0 {
+			continue
+		}
+		r.Label = l
+		r.EquivalentDiameter = math.Sqrt(4 * float64(r.Area) / math.Pi)
+		r.Moments.normalize()
+		result = append(result, r)
+	}
+	return result
+}
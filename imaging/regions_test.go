@@ -0,0 +1,108 @@
+package imaging
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+// labelRects devolve rótulos 20x20 com o rótulo i+1 no retângulo i.
+func labelRects(rects ...image.Rectangle) [][]int {
+	labels := make([][]int, 20)
+	for y := range labels {
+		labels[y] = make([]int, 20)
+	}
+	for i, r := range rects {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				labels[y][x] = i + 1
+			}
+		}
+	}
+	return labels
+}
+
+func TestRegionProps(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels [][]int
+		want   []Region
+	}{
+		{"vazia", labelRects(), nil},
+		{
+			"quadrado 5x5",
+			labelRects(image.Rect(2, 3, 7, 8)),
+			[]Region{{Label: 1, Area: 25, Centroid: [2]float64{4, 5}, Bounds: image.Rect(2, 3, 7, 8), Perimeter: 16}},
+		},
+		{
+			"pixel isolado",
+			labelRects(image.Rect(10, 10, 11, 11)),
+			[]Region{{Label: 1, Area: 1, Centroid: [2]float64{10, 10}, Bounds: image.Rect(10, 10, 11, 11), Perimeter: 1}},
+		},
+		{
+			// fora da imagem conta como fundo: a coluna do meio também é
+			// contorno nas linhas 0 e 19
+			"faixa na borda",
+			labelRects(image.Rect(0, 0, 3, 20)),
+			[]Region{{Label: 1, Area: 60, Centroid: [2]float64{1, 9.5}, Bounds: image.Rect(0, 0, 3, 20), Perimeter: 42}},
+		},
+		{
+			"dois objetos e um rótulo sem pixels",
+			labelRects(image.Rect(0, 0, 2, 2), image.Rect(5, 5, 5, 5), image.Rect(12, 14, 20, 16)),
+			[]Region{
+				{Label: 1, Area: 4, Centroid: [2]float64{0.5, 0.5}, Bounds: image.Rect(0, 0, 2, 2), Perimeter: 4},
+				{Label: 3, Area: 16, Centroid: [2]float64{15.5, 14.5}, Bounds: image.Rect(12, 14, 20, 16), Perimeter: 16},
+			},
+		},
+		{
+			// um anel 5x5: todos os 16 pixels tocam o fundo de fora ou o furo
+			"anel",
+			func() [][]int {
+				labels := labelRects(image.Rect(5, 5, 10, 10))
+				labels[7][7] = 0
+				return labels
+			}(),
+			[]Region{{Label: 1, Area: 24, Centroid: [2]float64{7, 7}, Bounds: image.Rect(5, 5, 10, 10), Perimeter: 20}},
+		},
+	}
+	for _, tt := range tests {
+		got := RegionProps(tt.labels)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: %d regiões, esperado %d", tt.name, len(got), len(tt.want))
+			continue
+		}
+		for i, w := range tt.want {
+			g := got[i]
+			if g.Label != w.Label || g.Area != w.Area || g.Bounds != w.Bounds || g.Perimeter != w.Perimeter ||
+				math.Abs(g.Centroid[0]-w.Centroid[0]) > 1e-9 || math.Abs(g.Centroid[1]-w.Centroid[1]) > 1e-9 {
+				t.Errorf("%s: região %+v, esperado rótulo %d, área %d, centróide %v, limites %v, perímetro %d",
+					tt.name, g, w.Label, w.Area, w.Centroid, w.Bounds, w.Perimeter)
+			}
+			if d := math.Sqrt(4 * float64(w.Area) / math.Pi); math.Abs(g.EquivalentDiameter-d) > 1e-9 {
+				t.Errorf("%s: diâmetro equivalente %g, esperado %g", tt.name, g.EquivalentDiameter, d)
+			}
+		}
+	}
+}
+
+func TestRegionPropsFromLabels(t *testing.T) {
+	img := parseMask(`
+		#..........#
+		#...####....
+		....####...#
+		....####....`, 0)
+	labels, n, err := LabelComponents(img, 8, BlackObjects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	regions := RegionProps(labels)
+	if len(regions) != n {
+		t.Fatalf("%d regiões para %d rótulos", len(regions), n)
+	}
+	areas := []int{2, 1, 12, 1}
+	for i, r := range regions {
+		if r.Label != i+1 || r.Area != areas[i] {
+			t.Errorf("região %d: rótulo %d com área %d, esperado %d", i, r.Label, r.Area, areas[i])
+		}
+	}
+}
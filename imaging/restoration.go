@@ -0,0 +1,120 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// Os filtros de restauração abaixo (Gonzalez e Woods, cap. 5) usam a
+// janela size×size de MedianFilter (pares usam a janela ímpar seguinte,
+// bordas replicadas) e o mesmo histograma deslizante.
+
+// AlphaTrimmedMean troca cada pixel pela média da janela sem os d menores
+// e os d maiores valores: d = 0 é a média comum e o maior d possível, a
+// mediana. Bom para ruído gaussiano misturado com sal e pimenta. Exige
+// 2d < size².
+func AlphaTrimmedMean(img *image.Gray, size, d int) (*image.Gray, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("tamanho da média alfa-aparada deve ser positivo, não %d", size)
+	}
+	n := (size/2*2 + 1) * (size/2*2 + 1)
+	if d < 0 || 2*d >= n {
+		return nil, fmt.Errorf("d = %d inválido na janela %dx%d, exige 0 <= 2d < %d", d, size, size, n)
+	}
+	return slidingHistogram(img, size/2, func(hist *[256]int) uint8 {
+		// soma tudo e tira os d valores de cada ponta
+		sum := 0
+		for v, c := range hist {
+			sum += v * c
+		}
+		for v, low := 0, d; low > 0; v++ {
+			drop := min(low, hist[v])
+			sum -= v * drop
+			low -= drop
+		}
+		for v, high := 255, d; high > 0; v-- {
+			drop := min(high, hist[v])
+			sum -= v * drop
+			high -= drop
+		}
+		return uint8(math.Round(float64(sum) / float64(n-2*d)))
+	}), nil
+}
+
+// ContraharmonicMean é a média contra-harmônica de ordem q da janela,
+// Σg^(q+1) / Σg^q: q > 0 tira a pimenta (pixels 0), q < 0 tira o sal
+// (pixels 255), q = 0 é a média aritmética e q = −1 a harmônica. Com q < 0
+// um pixel 0 domina a janela (g^q vai ao infinito) e o resultado é 0; o
+// sinal errado de q espalha o ruído em vez de tirá-lo.
+func ContraharmonicMean(img *image.Gray, size int, q float64) (*image.Gray, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("tamanho da média contra-harmônica deve ser positivo, não %d", size)
+	}
+	if math.IsNaN(q) || math.IsInf(q, 0) {
+		return nil, fmt.Errorf("ordem da média contra-harmônica inválida: %g", q)
+	}
+	var num, den [256]float64
+	for v := range num {
+		num[v] = math.Pow(float64(v), q+1)
+		den[v] = math.Pow(float64(v), q)
+	}
+	return slidingHistogram(img, size/2, func(hist *[256]int) uint8 {
+		if q < 0 && hist[0] > 0 {
+			return 0
+		}
+		var sumNum, sumDen float64
+		for v, c := range hist {
+			if c > 0 {
+				sumNum += float64(c) * num[v]
+				sumDen += float64(c) * den[v]
+			}
+		}
+		if sumDen == 0 {
+			return 0
+		}
+		return uint8(math.Round(min(255, sumNum/sumDen)))
+	}), nil
+}
+
+// slidingHistogram passa por reduce o histograma da janela
+// (2·radius+1)² em volta de cada pixel, com as bordas replicadas, e monta
+// a imagem com os valores devolvidos. O histograma é atualizado ao andar
+// na linha (sai uma coluna, entra outra), como em MedianFilter; as linhas
+// são divididas entre goroutines.
+func slidingHistogram(img *image.Gray, radius int, reduce func(hist *[256]int) uint8) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	if width == 0 || height == 0 {
+		return result
+	}
+	xs := BorderReplicate.table(width, radius, radius)
+	ys := BorderReplicate.table(height, radius, radius)
+	rows := make([]int, len(ys))
+	for k, sy := range ys {
+		rows[k] = sy * img.Stride
+	}
+	parallelRows(height, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			window := rows[y : y+2*radius+1]
+			var hist [256]int
+			for _, row := range window {
+				for _, sx := range xs[:2*radius+1] {
+					hist[img.Pix[row+sx]]++
+				}
+			}
+			dst := result.Pix[y*result.Stride:]
+			for x := 0; x < width; x++ {
+				if x > 0 {
+					out, in := xs[x-1], xs[x+2*radius]
+					for _, row := range window {
+						hist[img.Pix[row+out]]--
+						hist[img.Pix[row+in]]++
+					}
+				}
+				dst[x] = reduce(&hist)
+			}
+		}
+	})
+	return result
+}
@@ -0,0 +1,230 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// referenceWindow aplica reduce aos valores ordenados da janela
+// (size/2·2+1)² de cada pixel, com as bordas replicadas.
+func referenceWindow(img *image.Gray, size int, reduce func(window []int) uint8) *image.Gray {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	radius := size / 2
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var window []int
+			for j := -radius; j <= radius; j++ {
+				for i := -radius; i <= radius; i++ {
+					xx := min(max(x+i, 0), width-1)
+					yy := min(max(y+j, 0), height-1)
+					window = append(window, int(img.GrayAt(b.Min.X+xx, b.Min.Y+yy).Y))
+				}
+			}
+			sort.Ints(window)
+			result.Pix[y*result.Stride+x] = reduce(window)
+		}
+	}
+	return result
+}
+
+// impulseNoise troca cada pixel de img, com probabilidade density, por
+// value: só sal (255) ou só pimenta (0).
+func impulseNoise(img *image.Gray, density float64, value uint8, seed int64) *image.Gray {
+	rng := rand.New(rand.NewSource(seed))
+	noisy := image.NewGray(img.Bounds())
+	for i, v := range img.Pix {
+		if rng.Float64() < density {
+			v = value
+		}
+		noisy.Pix[i] = v
+	}
+	return noisy
+}
+
+// restorationScene tem dois quadrados de 80 e 170 num fundo de 120.
+func restorationScene() *image.Gray {
+	img := uniformGray(64, 120)
+	for y := 10; y < 30; y++ {
+		for x := 10; x < 30; x++ {
+			img.Pix[y*img.Stride+x] = 80
+			img.Pix[(y+24)*img.Stride+x+24] = 170
+		}
+	}
+	return img
+}
+
+func TestAlphaTrimmedMeanMatchesReference(t *testing.T) {
+	img := randomGray(21, 16, 7)
+	tests := []struct {
+		img     *image.Gray
+		size, d int
+	}{
+		{img, 1, 0},
+		{img, 3, 0},
+		{img, 3, 2},
+		{img, 3, 4},
+		{img, 4, 6}, // par: janela 5x5
+		{img, 5, 12},
+		{img.SubImage(image.Rect(3, 2, 17, 13)).(*image.Gray), 5, 5},
+	}
+	for _, tt := range tests {
+		got, err := AlphaTrimmedMean(tt.img, tt.size, tt.d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := referenceWindow(tt.img, tt.size, func(window []int) uint8 {
+			sum := 0
+			for _, v := range window[tt.d : len(window)-tt.d] {
+				sum += v
+			}
+			return uint8(math.Round(float64(sum) / float64(len(window)-2*tt.d)))
+		})
+		for i := range want.Pix {
+			if got.Pix[i] != want.Pix[i] {
+				t.Fatalf("%dx%d, d=%d: pixel %d vale %d, esperado %d", tt.size, tt.size, tt.d, i, got.Pix[i], want.Pix[i])
+			}
+		}
+	}
+
+	// o maior d é a mediana
+	trimmed, _ := AlphaTrimmedMean(img, 5, 12)
+	median := referenceMedian(img, 5)
+	for i := range median.Pix {
+		if trimmed.Pix[i] != median.Pix[i] {
+			t.Fatalf("d máximo difere da mediana no pixel %d", i)
+		}
+	}
+}
+
+func TestContraharmonicMeanMatchesReference(t *testing.T) {
+	img := randomGray(19, 14, 8)
+	for i := 0; i < len(img.Pix); i += 13 {
+		img.Pix[i] = 0 // zeros na janela testam o caso q < 0
+	}
+	for _, q := range []float64{0, 1.5, 3, -1, -1.5} {
+		for _, size := range []int{1, 3, 5} {
+			got, err := ContraharmonicMean(img, size, q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := referenceWindow(img, size, func(window []int) uint8 {
+				var num, den float64
+				for _, v := range window {
+					if v == 0 && q < 0 {
+						return 0
+					}
+					num += math.Pow(float64(v), q+1)
+					den += math.Pow(float64(v), q)
+				}
+				if den == 0 {
+					return 0
+				}
+				return uint8(math.Round(min(255, num/den)))
+			})
+			for i := range want.Pix {
+				if d := int(got.Pix[i]) - int(want.Pix[i]); d < -1 || d > 1 {
+					t.Fatalf("q=%g, %dx%d: pixel %d vale %d, esperado %d", q, size, size, i, got.Pix[i], want.Pix[i])
+				}
+			}
+		}
+	}
+}
+
+func TestContraharmonicImpulseNoise(t *testing.T) {
+	// o sinal certo de Q limpa o ruído; o errado o espalha pela janela
+	clean := restorationScene()
+	tests := []struct {
+		name  string
+		value uint8
+		q     float64
+		clean bool
+	}{
+		{"pimenta com Q > 0", 0, 1.5, true},
+		{"sal com Q < 0", 255, -1.5, true},
+		{"pimenta com Q < 0", 0, -1.5, false},
+		{"sal com Q > 0", 255, 1.5, false},
+	}
+	for _, tt := range tests {
+		noisy := impulseNoise(clean, 0.1, tt.value, 3)
+		got, err := ContraharmonicMean(noisy, 3, tt.q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		left := 0
+		for _, v := range got.Pix {
+			if v == tt.value {
+				left++
+			}
+		}
+		before, after := grayPSNR(noisy, clean), grayPSNR(got, clean)
+		if tt.clean && (left > 0 || after < before+10) {
+			t.Errorf("%s: %d impulsos restantes, PSNR %.1f dB, antes %.1f dB", tt.name, left, after, before)
+		}
+		if !tt.clean && after > before {
+			t.Errorf("%s: PSNR subiu de %.1f para %.1f dB", tt.name, before, after)
+		}
+	}
+}
+
+func TestAlphaTrimmedMixedNoise(t *testing.T) {
+	// ruído gaussiano com sal e pimenta: aparar as pontas vence a média
+	// comum (d = 0)
+	clean := restorationScene()
+	noisy := impulseNoise(impulseNoise(clean, 0.05, 0, 1), 0.05, 255, 2)
+	rng := rand.New(rand.NewSource(4))
+	for i, v := range noisy.Pix {
+		if v != 0 && v != 255 {
+			noisy.Pix[i] = uint8(math.Max(0, math.Min(255, math.Round(float64(v)+rng.NormFloat64()*8))))
+		}
+	}
+	mean, _ := AlphaTrimmedMean(noisy, 5, 0)
+	trimmed, _ := AlphaTrimmedMean(noisy, 5, 5)
+	if m, tr := grayPSNR(mean, clean), grayPSNR(trimmed, clean); tr < m+5 {
+		t.Errorf("PSNR aparada %.1f dB, média comum %.1f dB", tr, m)
+	}
+}
+
+func TestRestorationInvalidOptions(t *testing.T) {
+	img := uniformGray(6, 50)
+	tests := []struct {
+		name    string
+		size, d int
+	}{
+		{"tamanho zero", 0, 0},
+		{"d negativo", 3, -1},
+		{"2d igual à janela", 3, 5},
+		{"2d acima da janela par", 2, 5},
+	}
+	for _, tt := range tests {
+		if _, err := AlphaTrimmedMean(img, tt.size, tt.d); err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+		}
+	}
+	for _, q := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := ContraharmonicMean(img, 3, q); err == nil {
+			t.Errorf("q=%g: sem erro", q)
+		}
+	}
+	if _, err := ContraharmonicMean(img, 0, 1); err == nil {
+		t.Error("tamanho zero: sem erro")
+	}
+}
+
+func BenchmarkAlphaTrimmedMean(b *testing.B) {
+	img := randomGray(512, 512, 1)
+	for _, size := range []int{3, 9} {
+		b.Run(fmt.Sprintf("%dx%d", size, size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := AlphaTrimmedMean(img, size, size); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+)
+
+// DefaultSliceTable é a tabela de SegmentIntensity no formato de
+// ParseSliceTable.
+const DefaultSliceTable = "50:25,100:75,150:125,200:175,255:255"
+
+// SegmentIntensity troca cada faixa de intensidade pelo seu nível: até 50
+// vira 25, até 100 vira 75, até 150 vira 125, até 200 vira 175 e o resto 255.
+func SegmentIntensity(img *image.Gray) *image.Gray {
+	result, _ := IntensitySlice(img, []uint8{50, 100, 150, 200}, []uint8{25, 75, 125, 175, 255})
+	return result
+}
+
+// IntensitySlice fatia as intensidades: até breakpoints[0] vira outputs[0],
+// até breakpoints[1] vira outputs[1] e assim por diante; acima do último
+// ponto vale o último de outputs. Os pontos precisam ser estritamente
+// crescentes e outputs ter um nível a mais que eles.
+func IntensitySlice(img *image.Gray, breakpoints, outputs []uint8) (*image.Gray, error) {
+	if len(outputs) != len(breakpoints)+1 {
+		return nil, fmt.Errorf("a tabela precisa de um nível a mais que os pontos de corte: %d pontos e %d níveis", len(breakpoints), len(outputs))
+	}
+	for i := 1; i < len(breakpoints); i++ {
+		if breakpoints[i] <= breakpoints[i-1] {
+			return nil, fmt.Errorf("pontos de corte fora de ordem: %d depois de %d", breakpoints[i], breakpoints[i-1])
+		}
+	}
+	var lut [256]uint8
+	slice := 0
+	for v := range lut {
+		for slice < len(breakpoints) && v > int(breakpoints[slice]) {
+			slice++
+		}
+		lut[v] = outputs[slice]
+	}
+	return applyLUT(img, lut), nil
+}
+
+// ParseSliceTable lê a tabela de IntensitySlice como "limite:nível,...",
+// ex: "50:25,100:75,255:255": até 50 vira 25, até 100 vira 75 e o resto
+// 255. Os limites precisam crescer e o último ser 255, para a tabela
+// cobrir todos os níveis.
+func ParseSliceTable(s string) (breakpoints, outputs []uint8, err error) {
+	fields := strings.Split(s, ",")
+	previous := -1
+	for i, field := range fields {
+		limitText, levelText, ok := strings.Cut(strings.TrimSpace(field), ":")
+		limit, errLimit := strconv.ParseUint(limitText, 10, 8)
+		level, errLevel := strconv.ParseUint(levelText, 10, 8)
+		if !ok || errLimit != nil || errLevel != nil {
+			return nil, nil, fmt.Errorf("faixa inválida %q em %q, use limite:nível com valores de 0 a 255", field, s)
+		}
+		if int(limit) <= previous {
+			return nil, nil, fmt.Errorf("limites fora de ordem em %q: %d depois de %d", s, limit, previous)
+		}
+		previous = int(limit)
+		if i < len(fields)-1 {
+			breakpoints = append(breakpoints, uint8(limit))
+		} else if limit != 255 {
+			return nil, nil, fmt.Errorf("a última faixa de %q precisa ir até 255, não %d", s, limit)
+		}
+		outputs = append(outputs, uint8(level))
+	}
+	return breakpoints, outputs, nil
+}
@@ -0,0 +1,111 @@
+package imaging
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSegmentIntensityDefault(t *testing.T) {
+	// a tabela padrão reproduz a segmentação antiga, nível a nível
+	breakpoints, outputs, err := ParseSliceTable(DefaultSliceTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromTable, err := IntensitySlice(levels(), breakpoints, outputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := SegmentIntensity(levels())
+	for v := 0; v < 256; v++ {
+		var want uint8
+		switch {
+		case v <= 50:
+			want = 25
+		case v <= 100:
+			want = 75
+		case v <= 150:
+			want = 125
+		case v <= 200:
+			want = 175
+		default:
+			want = 255
+		}
+		if legacy.Pix[v] != want || fromTable.Pix[v] != want {
+			t.Errorf("nível %d: %d e %d pela tabela, esperado %d", v, legacy.Pix[v], fromTable.Pix[v], want)
+		}
+	}
+}
+
+func TestIntensitySlice(t *testing.T) {
+	tests := []struct {
+		name        string
+		breakpoints []uint8
+		outputs     []uint8
+		pairs       [][2]uint8
+	}{
+		{"2 níveis", []uint8{127}, []uint8{0, 255}, [][2]uint8{{0, 0}, {127, 0}, {128, 255}, {255, 255}}},
+		{
+			"10 níveis",
+			[]uint8{25, 50, 75, 100, 125, 150, 175, 200, 225},
+			[]uint8{0, 28, 57, 85, 113, 142, 170, 198, 227, 255},
+			[][2]uint8{{0, 0}, {25, 0}, {26, 28}, {100, 85}, {101, 113}, {225, 227}, {226, 255}, {255, 255}},
+		},
+		{"1 nível", nil, []uint8{9}, [][2]uint8{{0, 9}, {255, 9}}},
+		{"níveis invertidos", []uint8{0, 254}, []uint8{255, 128, 0}, [][2]uint8{{0, 255}, {1, 128}, {254, 128}, {255, 0}}},
+	}
+	for _, tt := range tests {
+		result, err := IntensitySlice(levels(), tt.breakpoints, tt.outputs)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		for _, p := range tt.pairs {
+			if got := result.Pix[p[0]]; got != p[1] {
+				t.Errorf("%s: %d → %d, esperado %d", tt.name, p[0], got, p[1])
+			}
+		}
+	}
+
+	invalid := []struct {
+		name                 string
+		breakpoints, outputs []uint8
+	}{
+		{"níveis a menos", []uint8{50, 100}, []uint8{0, 1}},
+		{"níveis a mais", []uint8{50}, []uint8{0, 1, 2}},
+		{"pontos repetidos", []uint8{50, 50}, []uint8{0, 1, 2}},
+		{"pontos decrescentes", []uint8{100, 50}, []uint8{0, 1, 2}},
+	}
+	for _, tt := range invalid {
+		if _, err := IntensitySlice(levels(), tt.breakpoints, tt.outputs); err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+		}
+	}
+}
+
+func TestParseSliceTable(t *testing.T) {
+	tests := []struct {
+		s                    string
+		breakpoints, outputs []uint8
+		ok                   bool
+	}{
+		{"50:25,100:75,150:125,200:175,255:255", []uint8{50, 100, 150, 200}, []uint8{25, 75, 125, 175, 255}, true},
+		{"127:0, 255:255", []uint8{127}, []uint8{0, 255}, true},
+		{"255:40", nil, []uint8{40}, true},
+		{"100:0,200:255", nil, nil, false},
+		{"100:0,50:10,255:255", nil, nil, false},
+		{"100:0,100:10,255:255", nil, nil, false},
+		{"100,255:255", nil, nil, false},
+		{"100:300,255:255", nil, nil, false},
+		{"-1:0,255:255", nil, nil, false},
+		{"", nil, nil, false},
+	}
+	for _, tt := range tests {
+		breakpoints, outputs, err := ParseSliceTable(tt.s)
+		if (err == nil) != tt.ok {
+			t.Errorf("%q: erro %v", tt.s, err)
+			continue
+		}
+		if tt.ok && (!slices.Equal(breakpoints, tt.breakpoints) || !slices.Equal(outputs, tt.outputs)) {
+			t.Errorf("%q: %v e %v, esperado %v e %v", tt.s, breakpoints, outputs, tt.breakpoints, tt.outputs)
+		}
+	}
+}
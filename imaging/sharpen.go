@@ -0,0 +1,47 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+)
+
+// UnsharpMask realça a imagem pela máscara de nitidez: img + amount·(img −
+// borrada), com a gaussiana de desvio sigma, saturando em 0 e 255. Nas
+// regiões lisas a diferença é zero e nada muda; perto das bordas aparece o
+// sobressinal (um halo) dos dois lados, maior quanto maior amount.
+func UnsharpMask(img *image.Gray, sigma, amount float64) (*image.Gray, error) {
+	if sigma <= 0 || amount < 0 {
+		return nil, fmt.Errorf("parâmetros da máscara de nitidez inválidos: σ=%g, quantidade %g (exige σ > 0 e quantidade >= 0)", sigma, amount)
+	}
+	field := GrayToFloat(img)
+	blurred := SmoothFloat(field, sigma)
+	sharp := make([][]float64, len(field))
+	for y, row := range field {
+		sharp[y] = make([]float64, len(row))
+		for x, v := range row {
+			sharp[y][x] = v + amount*(v-blurred[y][x])
+		}
+	}
+	return FloatToGray(sharp, 255), nil
+}
+
+// LaplacianSharpen realça com o laplaciano: img − amount·∇²img, que é uma
+// convolução só com o kernel composto
+//
+//	 0   −a    0
+//	−a  1+4a  −a
+//	 0   −a    0
+//
+// saturando em 0 e 255. Mais barato que UnsharpMask, mas também realça o
+// ruído.
+func LaplacianSharpen(img *image.Gray, amount float64, border BorderMode) (*image.Gray, error) {
+	if amount < 0 {
+		return nil, fmt.Errorf("a quantidade do realce deve ser >= 0, não %g", amount)
+	}
+	kernel := [][]float64{
+		{0, -amount, 0},
+		{-amount, 1 + 4*amount, -amount},
+		{0, -amount, 0},
+	}
+	return Convolve(img, kernel, KernelNormalization{Mode: "none"}, ResponseClamp, border)
+}
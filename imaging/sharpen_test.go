@@ -0,0 +1,108 @@
+package imaging
+
+import (
+	"image"
+	"testing"
+)
+
+// blurredStep é um degrau de 60 para 200 em x = 30, suavizado com σ = 2.
+func blurredStep() *image.Gray {
+	img := verticalStep(60, 10, 30)
+	for i, v := range img.Pix {
+		img.Pix[i] = 60 + v/255*140
+	}
+	return GaussianBlur(img, 2)
+}
+
+func TestSharpenBlurredStep(t *testing.T) {
+	src := blurredStep()
+	unsharp := func(amount float64) func(*image.Gray) (*image.Gray, error) {
+		return func(img *image.Gray) (*image.Gray, error) { return UnsharpMask(img, 2, amount) }
+	}
+	laplacian := func(amount float64) func(*image.Gray) (*image.Gray, error) {
+		return func(img *image.Gray) (*image.Gray, error) { return LaplacianSharpen(img, amount, BorderReplicate) }
+	}
+	tests := []struct {
+		name    string
+		sharpen func(*image.Gray) (*image.Gray, error)
+		reach   int // até onde, a partir da borda, o realce pode mexer
+		over    int // sobressinal máximo além de 60 e 200
+	}{
+		{"unsharp 1", unsharp(1), 16, 70},
+		{"unsharp 2", unsharp(2), 16, 140},
+		{"laplaciano 1", laplacian(1), 10, 70},
+		{"laplaciano 0", laplacian(0), 0, 0},
+	}
+	for _, tt := range tests {
+		got, err := tt.sharpen(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := int(src.GrayAt(32, 5).Y) - int(src.GrayAt(27, 5).Y)
+		after := int(got.GrayAt(32, 5).Y) - int(got.GrayAt(27, 5).Y)
+		if tt.reach > 0 && after <= before {
+			t.Errorf("%s: contraste local %d, antes %d", tt.name, after, before)
+		}
+		for y := 0; y < 10; y++ {
+			for x := 0; x < 60; x++ {
+				v, s := got.GrayAt(x, y).Y, src.GrayAt(x, y).Y
+				if (x < 30-tt.reach || x >= 30+tt.reach) && v != s {
+					t.Fatalf("%s: região lisa mudou em %d,%d: %d, antes %d", tt.name, x, y, v, s)
+				}
+				if int(v) < 60-tt.over || int(v) > 200+tt.over {
+					t.Fatalf("%s: sobressinal %d em %d,%d", tt.name, v, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestSharpenUniform(t *testing.T) {
+	img := uniformGray(16, 90)
+	for _, amount := range []float64{0, 0.5, 3} {
+		unsharp, err := UnsharpMask(img, 1.5, amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		laplacian, err := LaplacianSharpen(img, amount, BorderReplicate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range img.Pix {
+			if unsharp.Pix[i] != 90 || laplacian.Pix[i] != 90 {
+				t.Fatalf("quantidade %g: imagem uniforme mudou (%d, %d)", amount, unsharp.Pix[i], laplacian.Pix[i])
+			}
+		}
+	}
+}
+
+func TestSharpenSaturates(t *testing.T) {
+	img := verticalStep(20, 4, 10)
+	got, err := UnsharpMask(img, 1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GrayAt(9, 0).Y != 0 || got.GrayAt(10, 0).Y != 255 {
+		t.Errorf("degrau 0/255 realçado para %d/%d, esperado 0/255", got.GrayAt(9, 0).Y, got.GrayAt(10, 0).Y)
+	}
+}
+
+func TestSharpenInvalidOptions(t *testing.T) {
+	img := uniformGray(8, 10)
+	tests := []struct {
+		name          string
+		sigma, amount float64
+	}{
+		{"σ zero", 0, 1},
+		{"σ negativo", -1, 1},
+		{"quantidade negativa", 1, -0.5},
+	}
+	for _, tt := range tests {
+		if _, err := UnsharpMask(img, tt.sigma, tt.amount); err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+		}
+	}
+	if _, err := LaplacianSharpen(img, -1, BorderReplicate); err == nil {
+		t.Error("laplaciano com quantidade negativa: sem erro")
+	}
+}
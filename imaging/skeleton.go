@@ -0,0 +1,119 @@
+package imaging
+
+import "image"
+
+// Skeletonize afina os objetos até um esqueleto de 1 pixel de largura pelo
+// algoritmo de Zhang-Suen (1984). Aqui o objeto é o branco (≠ 0), ao
+// contrário de CountObjects; a saída é 0/255. As duas subiterações se
+// repetem até nada mudar e depois uma passada sequencial tira os degraus de
+// 2 pixels que o Zhang-Suen deixa nas diagonais, só onde o pixel é simples
+// (tirá-lo não separa nem junta componentes), então a conectividade 8 se
+// mantém. No original um bloco 2x2 isolado some; aqui sobra um pixel.
+func Skeletonize(img *image.Gray) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	// grade com uma moldura de fundo para não checar as bordas
+	stride := width + 2
+	on := make([]bool, stride*(height+2))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			on[(y+1)*stride+x+1] = img.Pix[y*img.Stride+x] != 0
+		}
+	}
+
+	// vizinhos P2..P9 de Zhang-Suen: N, NE, E, SE, S, SW, W, NW
+	ring := [8]int{-stride, -stride + 1, 1, stride + 1, stride, stride - 1, -1, -stride - 1}
+	neighbors := func(p int) (n [8]bool) {
+		for i, d := range ring {
+			n[i] = on[p+d]
+		}
+		return n
+	}
+
+	var remove []int
+	for changed := true; changed; {
+		changed = false
+		for step := 0; step < 2; step++ {
+			remove = remove[:0]
+			for y := 1; y <= height; y++ {
+				for x := 1; x <= width; x++ {
+					p := y*stride + x
+					if on[p] && zhangSuenDeletable(neighbors(p), step) {
+						remove = append(remove, p)
+					}
+				}
+			}
+			for _, p := range remove {
+				// só um bloco 2x2 tem todos os pixels marcados de uma vez;
+				// o último fica para o objeto não sumir
+				if neighbors(p) != ([8]bool{}) {
+					on[p] = false
+					changed = true
+				}
+			}
+		}
+	}
+
+	for y := 1; y <= height; y++ {
+		for x := 1; x <= width; x++ {
+			p := y*stride + x
+			if !on[p] {
+				continue
+			}
+			n := neighbors(p)
+			north, east, south, west := n[0], n[2], n[4], n[6]
+			staircase := north && east || east && south || south && west || west && north
+			if staircase && connectivity8(n) == 1 {
+				on[p] = false
+			}
+		}
+	}
+
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if on[(y+1)*stride+x+1] {
+				result.Pix[y*result.Stride+x] = 255
+			}
+		}
+	}
+	return result
+}
+
+// zhangSuenDeletable testa as condições de Zhang-Suen para o pixel com os
+// vizinhos n (P2..P9): entre 2 e 6 vizinhos, uma única transição fundo →
+// objeto em volta e, na primeira subiteração, P2·P4·P6 = P4·P6·P8 = 0 (na
+// segunda, P2·P4·P8 = P2·P6·P8 = 0).
+func zhangSuenDeletable(n [8]bool, step int) bool {
+	var count, transitions int
+	for i, v := range n {
+		if v {
+			count++
+		}
+		if !v && n[(i+1)%8] {
+			transitions++
+		}
+	}
+	if count < 2 || count > 6 || transitions != 1 {
+		return false
+	}
+	p2, p4, p6, p8 := n[0], n[2], n[4], n[6]
+	if step == 0 {
+		return !(p2 && p4 && p6) && !(p4 && p6 && p8)
+	}
+	return !(p2 && p4 && p8) && !(p2 && p6 && p8)
+}
+
+// connectivity8 é o número de conectividade de Yokoi para vizinhança 8:
+// vale 1 exatamente quando o pixel é simples.
+func connectivity8(n [8]bool) int {
+	// x̄ = fundo, a partir do leste no sentido anti-horário: E, NE, N, NW, W, SW, S, SE
+	ccw := [8]bool{n[2], n[1], n[0], n[7], n[6], n[5], n[4], n[3]}
+	var c int
+	for k := 0; k < 8; k += 2 {
+		a, b, d := !ccw[k], !ccw[k+1], !ccw[(k+2)%8]
+		if a && !(b && d) {
+			c++
+		}
+	}
+	return c
+}
@@ -0,0 +1,119 @@
+package imaging
+
+import (
+	"image"
+	"testing"
+)
+
+// fillRects devolve uma imagem preta w×h com os retângulos em branco.
+func fillRects(w, h int, rects ...image.Rectangle) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for _, r := range rects {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				img.Pix[y*img.Stride+x] = 255
+			}
+		}
+	}
+	return img
+}
+
+// letterH é um "H" de traços com 7 pixels de largura.
+func letterH() *image.Gray {
+	return fillRects(60, 70,
+		image.Rect(8, 5, 15, 65),
+		image.Rect(45, 5, 52, 65),
+		image.Rect(15, 31, 45, 38),
+	)
+}
+
+// skeletonStats devolve o número de pixels, de componentes 8-conexos, de
+// blocos 2x2 e de pontas (pixels com um único vizinho) do esqueleto.
+func skeletonStats(t *testing.T, skel *image.Gray) (pixels, components, blocks, ends int) {
+	t.Helper()
+	_, components, err := LabelComponents(skel, 8, WhiteObjects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, h := skel.Bounds().Dx(), skel.Bounds().Dy()
+	on := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < w && y < h && skel.Pix[y*skel.Stride+x] != 0
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if !on(x, y) {
+				continue
+			}
+			pixels++
+			if on(x+1, y) && on(x, y+1) && on(x+1, y+1) {
+				blocks++
+			}
+			neighbors := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if (dx != 0 || dy != 0) && on(x+dx, y+dy) {
+						neighbors++
+					}
+				}
+			}
+			if neighbors == 1 {
+				ends++
+			}
+		}
+	}
+	return pixels, components, blocks, ends
+}
+
+func TestSkeletonizeH(t *testing.T) {
+	img := letterH()
+	skel := Skeletonize(img)
+	pixels, components, blocks, ends := skeletonStats(t, skel)
+	if components != 1 {
+		t.Errorf("%d componentes, esperado 1", components)
+	}
+	if blocks != 0 {
+		t.Errorf("%d blocos 2x2: o esqueleto não tem 1 pixel de largura", blocks)
+	}
+	if ends != 4 {
+		t.Errorf("%d pontas, esperado 4", ends)
+	}
+	// as duas hastes de 60 e a barra de 30 dão perto de 140 pixels
+	if pixels < 110 || pixels > 150 {
+		t.Errorf("%d pixels no esqueleto, esperado perto de 140", pixels)
+	}
+	for i, v := range skel.Pix {
+		if v != 0 && img.Pix[i] == 0 {
+			t.Fatalf("pixel %d fora do objeto", i)
+		}
+	}
+	// a barra fica no meio da altura dela
+	if skel.Pix[34*skel.Stride+30] != 255 {
+		t.Error("barra do H fora da linha média")
+	}
+}
+
+func TestSkeletonizeShapes(t *testing.T) {
+	tests := []struct {
+		name                        string
+		img                         *image.Gray
+		pixels, components, maxEnds int
+	}{
+		{"vazia", fillRects(10, 10), 0, 0, 0},
+		{"ponto", fillRects(10, 10, image.Rect(4, 4, 5, 5)), 1, 1, 0},
+		{"bloco 2x2 deixa um pixel", fillRects(10, 10, image.Rect(4, 4, 6, 6)), 1, 1, 0},
+		{"linha fina fica igual", fillRects(20, 5, image.Rect(2, 2, 18, 3)), 16, 1, 2},
+		{"retângulo vira linha", fillRects(40, 20, image.Rect(5, 5, 35, 14)), 0, 1, 2},
+		{"objeto na borda", fillRects(20, 9, image.Rect(0, 0, 20, 9)), 0, 1, 2},
+		{"dois objetos", fillRects(40, 20, image.Rect(2, 2, 15, 8), image.Rect(20, 10, 38, 18)), 0, 2, 4},
+	}
+	for _, tt := range tests {
+		skel := Skeletonize(tt.img)
+		pixels, components, blocks, ends := skeletonStats(t, skel)
+		if tt.pixels > 0 && pixels != tt.pixels {
+			t.Errorf("%s: %d pixels, esperado %d", tt.name, pixels, tt.pixels)
+		}
+		if components != tt.components || blocks != 0 || ends > tt.maxEnds {
+			t.Errorf("%s: %d componentes, %d blocos 2x2 e %d pontas", tt.name, components, blocks, ends)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// SobelMaxMagnitude é a maior magnitude possível do sobel em 8 bits:
+// sqrt(1020² + 1020²).
+const SobelMaxMagnitude = 1442.5
+
+// SobelComponents devolve as derivadas horizontal e vertical do sobel em
+// ponto flutuante, replicando a borda.
+func SobelComponents(img *image.Gray) (gx, gy [][]float64) {
+	return SobelComponentsFloat(GrayToFloat(img), BorderReplicate)
+}
+
+// SobelComponentsFloat é o sobel sobre um campo [y][x] já em ponto
+// flutuante (ex: suavizado), sem quantizar para 8 bits. Fora do campo os
+// valores vêm de border.
+func SobelComponentsFloat(field [][]float64, border BorderMode) (gx, gy [][]float64) {
+	return GradientComponents(field, Sobel, border)
+}
+
+// GradientOperator escolhe os kernels das derivadas.
+type GradientOperator int
+
+const (
+	Sobel   GradientOperator = iota // 3x3, pesos 1-2-1 na suavização
+	Prewitt                         // 3x3, pesos 1-1-1
+	Scharr                          // 3x3, pesos 3-10-3: direção mais precisa
+	Roberts                         // 2x2 cruzado: derivadas nas diagonais
+)
+
+// ParseGradientOperator lê "sobel", "prewitt", "scharr" ou "roberts".
+func ParseGradientOperator(s string) (GradientOperator, error) {
+	switch s {
+	case "sobel":
+		return Sobel, nil
+	case "prewitt":
+		return Prewitt, nil
+	case "scharr":
+		return Scharr, nil
+	case "roberts":
+		return Roberts, nil
+	}
+	return 0, fmt.Errorf("operador de gradiente desconhecido %q, use sobel, prewitt, scharr ou roberts", s)
+}
+
+// kernels devolve os kernels [linha = y][coluna = x] 3x3 centrados no
+// pixel; o de Roberts ocupa o canto de baixo à direita, então gx é a
+// derivada na diagonal ↘ e gy na ↙.
+func (op GradientOperator) kernels() (kx, ky [3][3]float64) {
+	switch op {
+	case Prewitt:
+		return [3][3]float64{{-1, 0, 1}, {-1, 0, 1}, {-1, 0, 1}},
+			[3][3]float64{{-1, -1, -1}, {0, 0, 0}, {1, 1, 1}}
+	case Scharr:
+		return [3][3]float64{{-3, 0, 3}, {-10, 0, 10}, {-3, 0, 3}},
+			[3][3]float64{{-3, -10, -3}, {0, 0, 0}, {3, 10, 3}}
+	case Roberts:
+		return [3][3]float64{{0, 0, 0}, {0, -1, 0}, {0, 0, 1}},
+			[3][3]float64{{0, 0, 0}, {0, 0, -1}, {0, 1, 0}}
+	}
+	return [3][3]float64{{-1, 0, 1}, {-2, 0, 2}, {-1, 0, 1}},
+		[3][3]float64{{-1, -2, -1}, {0, 0, 0}, {1, 2, 1}}
+}
+
+// MaxMagnitude é a maior magnitude possível do operador em 8 bits (a de
+// Sobel é SobelMaxMagnitude).
+func (op GradientOperator) MaxMagnitude() float64 {
+	kx, _ := op.kernels()
+	var positive float64
+	for _, row := range kx {
+		for _, k := range row {
+			positive += max(k, 0)
+		}
+	}
+	return math.Sqrt2 * 255 * positive
+}
+
+// GradientComponents devolve as derivadas horizontal e vertical do campo
+// [y][x] com os kernels do operador. Fora do campo os valores vêm de
+// border.
+func GradientComponents(field [][]float64, op GradientOperator, border BorderMode) (gx, gy [][]float64) {
+	kx, ky := op.kernels()
+
+	height := len(field)
+	width := 0
+	if height > 0 {
+		width = len(field[0])
+	}
+	gx = make([][]float64, height)
+	gy = make([][]float64, height)
+	for y := range gx {
+		gx[y] = make([]float64, width)
+		gy[y] = make([]float64, width)
+	}
+
+	xs, ys := border.table(width, 1, 1), border.table(height, 1, 1)
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			for i := -1; i <= 1; i++ {
+				for j := -1; j <= 1; j++ {
+					sx, sy := xs[x+i+1], ys[y+j+1]
+					if sx < 0 || sy < 0 {
+						continue
+					}
+					gray := field[sy][sx]
+					// linha do kernel = deslocamento em y, coluna = em x
+					gx[y][x] += gray * kx[j+1][i+1]
+					gy[y][x] += gray * ky[j+1][i+1]
+				}
+			}
+		}
+	}
+
+	return gx, gy
+}
+
+// SobelGradient devolve a magnitude e a direção (radianos, atan2(gy, gx)) do
+// gradiente em ponto flutuante, sem quantizar.
+func SobelGradient(img *image.Gray) (mag, dir [][]float64) {
+	return GradientFromComponents(SobelComponents(img))
+}
+
+// Gradient é SobelGradient com qualquer operador, replicando a borda.
+func Gradient(img *image.Gray, op GradientOperator) (mag, dir [][]float64) {
+	return GradientFromComponents(GradientComponents(GrayToFloat(img), op, BorderReplicate))
+}
+
+// DirectionToGray leva a direção do gradiente de (−π, π] para 0..255 (−π
+// é 0, 0 rad é 128). Onde não há gradiente atan2 dá 0, então o fundo
+// liso sai cinza médio.
+func DirectionToGray(dir [][]float64) *image.Gray {
+	height := len(dir)
+	width := 0
+	if height > 0 {
+		width = len(dir[0])
+	}
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for y, row := range dir {
+		for x, theta := range row {
+			result.Pix[y*result.Stride+x] = uint8(math.Round((theta + math.Pi) / (2 * math.Pi) * 255))
+		}
+	}
+	return result
+}
+
+// GradientFromComponents combina as derivadas em magnitude e direção.
+func GradientFromComponents(gx, gy [][]float64) (mag, dir [][]float64) {
+	mag = make([][]float64, len(gx))
+	dir = make([][]float64, len(gx))
+	for y := range gx {
+		mag[y] = make([]float64, len(gx[y]))
+		dir[y] = make([]float64, len(gx[y]))
+		for x := range gx[y] {
+			mag[y][x] = math.Hypot(gx[y][x], gy[y][x])
+			dir[y][x] = math.Atan2(gy[y][x], gx[y][x])
+		}
+	}
+	return mag, dir
+}
+
+// DirectionColors são as cores dos setores de direção; nos 4 setores usamos
+// as quatro primeiras.
+var DirectionColors = []color.RGBA{
+	{255, 0, 0, 255},     // 0°: gradiente horizontal (borda vertical)
+	{255, 255, 0, 255},   // 45°
+	{0, 255, 0, 255},     // 90°: gradiente vertical (borda horizontal)
+	{0, 255, 255, 255},   // 135°
+	{0, 0, 255, 255},     // 180°
+	{255, 0, 255, 255},   // 225°
+	{255, 128, 0, 255},   // 270°
+	{128, 128, 255, 255}, // 315°
+}
+
+// QuantizeDirection agrupa o ângulo do gradiente em setores de 45°.
+// Com 4 setores o sentido é ignorado (0°, 45°, 90°, 135°), que são os setores
+// da supressão de não-máximos; com 8 o sentido é mantido.
+func QuantizeDirection(theta float64, bins int) int {
+	period := 2 * math.Pi
+	if bins == 4 {
+		period = math.Pi
+	}
+	theta = math.Mod(theta, period)
+	if theta < 0 {
+		theta += period
+	}
+	return int(math.Round(theta/(math.Pi/4))) % bins
+}
@@ -0,0 +1,116 @@
+package imaging
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGradientVerticalStep(t *testing.T) {
+	// degrau de 0 para 255 em x = 10: só gx responde, nas duas colunas
+	// vizinhas da borda; Roberts só enxerga a coluna da esquerda e responde
+	// nas duas diagonais
+	img := verticalStep(20, 8, 10)
+	tests := []struct {
+		op         GradientOperator
+		left, edge float64 // magnitude em x = 9 e x = 10
+		dir        float64 // direção em x = 9
+	}{
+		{Sobel, 4 * 255, 4 * 255, 0},
+		{Prewitt, 3 * 255, 3 * 255, 0},
+		{Scharr, 16 * 255, 16 * 255, 0},
+		{Roberts, math.Sqrt2 * 255, 0, -math.Pi / 4},
+	}
+	for _, tt := range tests {
+		mag, dir := Gradient(img, tt.op)
+		for y := range mag {
+			for x := range mag[y] {
+				want := 0.0
+				switch x {
+				case 9:
+					want = tt.left
+				case 10:
+					want = tt.edge
+				}
+				if math.Abs(mag[y][x]-want) > 1e-9 {
+					t.Fatalf("operador %d: magnitude %.2f em %d,%d, esperado %.2f", tt.op, mag[y][x], x, y, want)
+				}
+			}
+			if math.Abs(dir[y][9]-tt.dir) > 1e-9 {
+				t.Errorf("operador %d: direção %.3f em x=9, esperado %.3f", tt.op, dir[y][9], tt.dir)
+			}
+		}
+		if tt.left > tt.op.MaxMagnitude() {
+			t.Errorf("operador %d: degrau %.1f acima da magnitude máxima %.1f", tt.op, tt.left, tt.op.MaxMagnitude())
+		}
+	}
+}
+
+func TestGradientComponentsTransposed(t *testing.T) {
+	// a borda horizontal é a vertical transposta: gy de uma é gx da outra
+	vertical := GrayToFloat(verticalStep(12, 12, 6))
+	horizontal := make([][]float64, len(vertical))
+	for y := range horizontal {
+		horizontal[y] = make([]float64, len(vertical))
+		for x := range horizontal[y] {
+			horizontal[y][x] = vertical[x][y]
+		}
+	}
+	for _, op := range []GradientOperator{Sobel, Prewitt, Scharr} {
+		vx, vy := GradientComponents(vertical, op, BorderReplicate)
+		hx, hy := GradientComponents(horizontal, op, BorderReplicate)
+		for y := range vx {
+			for x := range vx[y] {
+				if vx[y][x] != hy[x][y] || vy[y][x] != hx[x][y] {
+					t.Fatalf("operador %d: componentes não transpostas em %d,%d", op, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestSobelMatchesGradient(t *testing.T) {
+	img := randomGray(17, 13, 3)
+	mag, dir := SobelGradient(img)
+	gmag, gdir := Gradient(img, Sobel)
+	for y := range mag {
+		for x := range mag[y] {
+			if mag[y][x] != gmag[y][x] || dir[y][x] != gdir[y][x] {
+				t.Fatalf("SobelGradient difere de Gradient(Sobel) em %d,%d", x, y)
+			}
+		}
+	}
+	if got := Sobel.MaxMagnitude(); math.Abs(got-SobelMaxMagnitude) > 0.1 {
+		t.Errorf("magnitude máxima do sobel %.2f, esperado %.1f", got, SobelMaxMagnitude)
+	}
+}
+
+func TestParseGradientOperator(t *testing.T) {
+	tests := []struct {
+		in   string
+		want GradientOperator
+		ok   bool
+	}{
+		{"sobel", Sobel, true},
+		{"prewitt", Prewitt, true},
+		{"scharr", Scharr, true},
+		{"roberts", Roberts, true},
+		{"Sobel", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseGradientOperator(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%q: %d, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestDirectionToGray(t *testing.T) {
+	got := DirectionToGray([][]float64{{-math.Pi, 0, math.Pi / 2, math.Pi}})
+	want := []uint8{0, 128, 191, 255}
+	for x, w := range want {
+		if got.Pix[x] != w {
+			t.Errorf("coluna %d: %d, esperado %d", x, got.Pix[x], w)
+		}
+	}
+}
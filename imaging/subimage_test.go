@@ -0,0 +1,86 @@
+package imaging
+
+import (
+	"image"
+	"image/draw"
+	"reflect"
+	"testing"
+)
+
+// offsetCopy põe img num SubImage que começa em (100, 50), cercado de
+// pixels alternados 0 e 255 que mudariam qualquer resultado calculado
+// sobre a região errada
+func offsetCopy(img *image.Gray) *image.Gray {
+	size := img.Bounds().Size()
+	full := image.NewGray(image.Rect(0, 0, size.X+200, size.Y+100))
+	for i := range full.Pix {
+		full.Pix[i] = uint8(255 * (i % 2))
+	}
+	r := image.Rectangle{image.Pt(100, 50), image.Pt(100, 50).Add(size)}
+	draw.Draw(full, r, img, img.Bounds().Min, draw.Src)
+	return full.SubImage(r).(*image.Gray)
+}
+
+func TestSubImageMatchesStandalone(t *testing.T) {
+	// quadrados escuros e borrados num fundo claro com textura
+	standalone := GaussianBlur(fillRects(120, 80, image.Rect(10, 10, 40, 40), image.Rect(60, 25, 100, 65)), 1.5)
+	for i, v := range randomGray(120, 80, 4).Pix {
+		standalone.Pix[i] = uint8(220 - int(standalone.Pix[i])*3/4 + int(v)/16)
+	}
+	sub := offsetCopy(standalone)
+	mustGray := func(img *image.Gray, err error) *image.Gray {
+		if err != nil {
+			t.Fatal(err)
+		}
+		return img
+	}
+	tests := []struct {
+		name string
+		run  func(*image.Gray) *image.Gray
+	}{
+		{"Convolve", func(img *image.Gray) *image.Gray {
+			return mustGray(Convolve(img, onesKernel(5, 5), KernelNormalization{Mode: "auto"}, ResponseClamp, BorderReplicate))
+		}},
+		{"Otsu", Otsu},
+		{"Canny", func(img *image.Gray) *image.Gray { return mustGray(Canny(img, DefaultCannyOptions)) }},
+		{"SegmentIntensity", SegmentIntensity},
+		{"BackgroundThreshold", func(img *image.Gray) *image.Gray { return mustGray(BackgroundThreshold(img, 0.6)) }},
+		{"Watershed", func(img *image.Gray) *image.Gray {
+			result, err := Watershed(img, DefaultWatershedOptions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return result.Lines
+		}},
+		{"MarrHildreth", func(img *image.Gray) *image.Gray { return mustGray(MarrHildreth(img, 2, 13, 0)) }},
+		{"FillHoles", func(img *image.Gray) *image.Gray { return FillHoles(Otsu(img), BlackObjects) }},
+	}
+	for _, tt := range tests {
+		got, want := tt.run(sub), tt.run(standalone)
+		if got.Bounds() != want.Bounds() || !reflect.DeepEqual(got.Pix, want.Pix) {
+			t.Errorf("%s: o SubImage em %v difere do recorte processado sozinho", tt.name, sub.Bounds().Min)
+		}
+	}
+
+	mask := Otsu(standalone)
+	maskSub := offsetCopy(mask)
+	count := func(img *image.Gray) int {
+		n, err := CountObjects(img, DefaultCountObjectsOptions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if got, want := count(maskSub), count(mask); got != want {
+		t.Errorf("CountObjects: %d objetos no SubImage, %d no recorte", got, want)
+	}
+
+	chain, start, _ := FreemanChainCode(maskSub)
+	wantChain, wantStart, ok := FreemanChainCode(mask)
+	if !ok || start != wantStart || !reflect.DeepEqual(chain, wantChain) {
+		t.Errorf("FreemanChainCode: início %v no SubImage, %v no recorte", start, wantStart)
+	}
+	if got, want := FreemanChainCodes(maskSub), FreemanChainCodes(mask); !reflect.DeepEqual(got, want) {
+		t.Errorf("FreemanChainCodes: %d cadeias no SubImage, %d no recorte", len(got), len(want))
+	}
+}
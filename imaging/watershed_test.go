@@ -0,0 +1,215 @@
+package imaging
+
+import (
+	"image"
+	"testing"
+	"time"
+)
+
+// uniformGray devolve uma imagem size×size toda com o valor v.
+func uniformGray(size int, v uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for i := range img.Pix {
+		img.Pix[i] = v
+	}
+	return img
+}
+
+// Imagens em que a frente do Otsu cobre tudo não podem travar a erosão
+// última, que replica as bordas.
+func TestWatershedUniform(t *testing.T) {
+	tests := []struct {
+		name   string
+		img    *image.Gray
+		bright bool
+	}{
+		{"preta", uniformGray(64, 0), false},
+		{"1x1", uniformGray(1, 0), false},
+		{"cinza clara", uniformGray(64, 128), true},
+		{"cinza escura", uniformGray(64, 128), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultWatershedOptions
+			opts.BrightObjects = tt.bright
+			done := make(chan *WatershedResult, 1)
+			go func() {
+				result, err := Watershed(tt.img, opts)
+				if err != nil {
+					t.Error(err)
+				}
+				done <- result
+			}()
+			select {
+			case result := <-done:
+				if result == nil {
+					return
+				}
+				size := tt.img.Bounds().Dx()
+				if len(result.Labels) != size || len(result.Labels[0]) != size {
+					t.Fatalf("rótulos %dx%d, esperado %dx%d", len(result.Labels[0]), len(result.Labels), size, size)
+				}
+				if result.Regions < 1 {
+					t.Errorf("%d regiões, esperado pelo menos 1", result.Regions)
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("Watershed não terminou")
+			}
+		})
+	}
+}
+
+// touchingCoins desenha três moedas escuras encostadas num fundo claro.
+func touchingCoins() (*image.Gray, []image.Point) {
+	img := uniformGray(140, 230)
+	centers := []image.Point{{35, 70}, {68, 70}, {101, 70}}
+	for y := 0; y < 140; y++ {
+		for x := 0; x < 140; x++ {
+			for _, c := range centers {
+				if dx, dy := x-c.X, y-c.Y; dx*dx+dy*dy <= 18*18 {
+					img.Pix[y*img.Stride+x] = 40
+				}
+			}
+		}
+	}
+	return img, centers
+}
+
+func TestWatershedTouchingCoins(t *testing.T) {
+	img, centers := touchingCoins()
+	tests := []struct {
+		name string
+		opts WatershedOptions
+	}{
+		{"erosão última", DefaultWatershedOptions},
+		{"erosões fixas", WatershedOptions{Markers: "otsu", Erode: 10, Sigma: 1}},
+	}
+	for _, tt := range tests {
+		result, err := Watershed(img, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		background := result.Labels[2][2]
+		seen := map[int]bool{background: true}
+		for _, c := range centers {
+			l := result.Labels[c.Y][c.X]
+			if seen[l] || l == 0 {
+				t.Errorf("%s: moeda em %v com o rótulo %d repetido", tt.name, c, l)
+			}
+			seen[l] = true
+		}
+		// entre duas moedas há uma linha de divisor cortando o pescoço
+		for _, x0 := range []int{centers[0].X, centers[1].X} {
+			crossed := false
+			for x := x0; x < x0+33; x++ {
+				if result.Lines.Pix[70*result.Lines.Stride+x] == 255 {
+					crossed = true
+				}
+			}
+			if !crossed {
+				t.Errorf("%s: sem linha de divisor depois de x=%d", tt.name, x0)
+			}
+		}
+		if result.Regions != 4 {
+			t.Errorf("%s: %d regiões, esperado 4 (fundo e três moedas)", tt.name, result.Regions)
+		}
+	}
+}
+
+func TestWatershedMarkers(t *testing.T) {
+	// dois vales separados por uma crista na coluna 5
+	relief := image.NewGray(image.Rect(0, 0, 11, 3))
+	markers := make([][]int, 3)
+	for y := range markers {
+		markers[y] = make([]int, 11)
+		for x := 0; x < 11; x++ {
+			d := x - 5
+			if d < 0 {
+				d = -d
+			}
+			relief.Pix[y*relief.Stride+x] = uint8(100 - 20*d)
+		}
+	}
+	markers[1][0], markers[1][10] = 1, 2
+
+	labels, lines, err := WatershedMarkers(relief, markers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 11; x++ {
+			want := 1
+			switch {
+			case x == 5:
+				want = 0
+			case x > 5:
+				want = 2
+			}
+			if labels[y][x] != want {
+				t.Errorf("%d,%d: rótulo %d, esperado %d", x, y, labels[y][x], want)
+			}
+			if ridge := lines.Pix[y*lines.Stride+x] == 255; ridge != (want == 0) {
+				t.Errorf("%d,%d: linha %v", x, y, ridge)
+			}
+		}
+	}
+
+	if _, _, err := WatershedMarkers(relief, markers[:2]); err == nil {
+		t.Error("marcadores de outro tamanho sem erro")
+	}
+}
+
+func TestWatershedOptions(t *testing.T) {
+	img, _ := touchingCoins()
+	for _, opts := range []WatershedOptions{{Markers: "otsu", Erode: -1}, {Markers: "picos"}} {
+		if _, err := Watershed(img, opts); err == nil {
+			t.Errorf("%+v: sem erro", opts)
+		}
+	}
+	// as moedas lisas se tocam num platô só do gradiente: os mínimos
+	// separam o fundo das moedas, mas não as moedas entre si
+	result, err := Watershed(img, WatershedOptions{Markers: "minima", Sigma: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Regions != 2 || result.Labels[70][35] == result.Labels[2][2] {
+		t.Errorf("mínimos regionais deram %d regiões, esperado 2", result.Regions)
+	}
+}
+
+func TestBackgroundThreshold(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 10, 10))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i)
+	}
+	tests := []struct {
+		fraction float64
+		white    int
+		ok       bool
+	}{
+		{0, 0, true},
+		{0.3, 29, true},
+		{1, 99, true},
+		{-0.1, 0, false},
+		{1.5, 0, false},
+	}
+	for _, tt := range tests {
+		result, err := BackgroundThreshold(img, tt.fraction)
+		if (err == nil) != tt.ok {
+			t.Errorf("%g: erro %v", tt.fraction, err)
+			continue
+		}
+		if !tt.ok {
+			continue
+		}
+		white := 0
+		for _, v := range result.Pix {
+			if v == 255 {
+				white++
+			}
+		}
+		if white != tt.white {
+			t.Errorf("%g: %d pixels brancos, esperado %d", tt.fraction, white, tt.white)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import "image"
+
+// As tabelas de soma (summed-area tables) têm uma linha e uma coluna a mais
+// que a imagem: ii[y][x] é a soma dos pixels no retângulo [0, x) × [0, y).
+// Tudo é uint64, então nem uma imagem 65536² toda em 255 transborda
+// (255² · 2³² < 2⁶⁴).
+
+// integralImage devolve a tabela de somas da imagem.
+func integralImage(img *image.Gray) [][]uint64 {
+	return buildIntegral(img, func(v uint64) uint64 { return v })
+}
+
+// integralImageSquared devolve a tabela de somas dos quadrados, usada junto
+// com integralImage para variâncias locais.
+func integralImageSquared(img *image.Gray) [][]uint64 {
+	return buildIntegral(img, func(v uint64) uint64 { return v * v })
+}
+
+func buildIntegral(img *image.Gray, f func(uint64) uint64) [][]uint64 {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	ii := make([][]uint64, height+1)
+	ii[0] = make([]uint64, width+1)
+	for y := 0; y < height; y++ {
+		ii[y+1] = make([]uint64, width+1)
+		row := img.Pix[y*img.Stride : y*img.Stride+width]
+		var rowSum uint64
+		for x := 0; x < width; x++ {
+			rowSum += f(uint64(row[x]))
+			ii[y+1][x+1] = ii[y][x+1] + rowSum
+		}
+	}
+	return ii
+}
+
+// windowSum soma os pixels do retângulo r (coordenadas da imagem, Max
+// exclusivo) usando a tabela ii. O retângulo é recortado às bordas; fora da
+// imagem a soma é zero.
+func windowSum(ii [][]uint64, r image.Rectangle) uint64 {
+	height := len(ii) - 1
+	if height < 0 {
+		return 0
+	}
+	width := len(ii[0]) - 1
+	r = r.Intersect(image.Rect(0, 0, width, height))
+	if r.Empty() {
+		return 0
+	}
+	return ii[r.Max.Y][r.Max.X] - ii[r.Min.Y][r.Max.X] - ii[r.Max.Y][r.Min.X] + ii[r.Min.Y][r.Min.X]
+}
+
+// TiltedIntegral é a tabela de somas girada 45° (Lienhart): T(x, y) é a soma
+// dos pixels (x', y') com y' <= y e |x − x'| <= y − y', um triângulo com o
+// vértice em (x, y) que se abre para cima. Como o triângulo passa das bordas
+// laterais, a tabela guarda colunas extras dos dois lados.
+type TiltedIntegral struct {
+	t      [][]uint64
+	height int
+	pad    int
+}
+
+// tiltedIntegralImage monta a tabela girada pela recorrência
+// T(x,y) = I(x,y) + I(x,y−1) + T(x−1,y−1) + T(x+1,y−1) − T(x,y−2).
+func tiltedIntegralImage(img *image.Gray) *TiltedIntegral {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	pad := height + 1
+	cols := width + 2*pad
+	t := make([][]uint64, height)
+
+	pixel := func(x, y int) uint64 {
+		if x < 0 || y < 0 || x >= width || y >= height {
+			return 0
+		}
+		return uint64(img.Pix[y*img.Stride+x])
+	}
+	at := func(x, y int) uint64 {
+		if y < 0 || x+pad < 0 || x+pad >= cols {
+			return 0
+		}
+		return t[y][x+pad]
+	}
+
+	for y := 0; y < height; y++ {
+		t[y] = make([]uint64, cols)
+		for c := 0; c < cols; c++ {
+			x := c - pad
+			// a aritmética módulo 2⁶⁴ garante o resultado exato mesmo se a
+			// subtração passar por um valor "negativo" no meio
+			t[y][c] = pixel(x, y) + pixel(x, y-1) + at(x-1, y-1) + at(x+1, y-1) - at(x, y-2)
+		}
+	}
+
+	return &TiltedIntegral{t: t, height: height, pad: pad}
+}
+
+// at devolve T(x, y); colunas fora da tabela cobrem só pixels fora da
+// imagem e valem zero.
+func (ti *TiltedIntegral) at(x, y int) uint64 {
+	c := x + ti.pad
+	if y < 0 || c < 0 || c >= len(ti.t[y]) {
+		return 0
+	}
+	return ti.t[y][c]
+}
+
+// tiltedWindowSum soma os pixels do retângulo girado 45° com vértice superior
+// em (x, y), w pixels na diagonal para baixo-direita e h para baixo-esquerda.
+// Colunas fora da imagem contam como zero; o vértice inferior (linha
+// y+w+h−1) precisa estar dentro da imagem, senão a soma devolvida é 0.
+func tiltedWindowSum(ti *TiltedIntegral, x, y, w, h int) uint64 {
+	if w <= 0 || h <= 0 || y < 0 || y+w+h-1 >= ti.height {
+		return 0
+	}
+	return ti.at(x-h+w, y+w+h-1) + ti.at(x, y-1) - ti.at(x-h, y+h-1) - ti.at(x+w, y+w-1)
+}
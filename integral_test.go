@@ -0,0 +1,152 @@
+package main
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+func TestWindowSum(t *testing.T) {
+	img := synthetic.Noise(37, 23, 128, 60, 1)
+	ii, sq := integralImage(img), integralImageSquared(img)
+	brute := func(r image.Rectangle) (sum, sumSq uint64) {
+		r = r.Intersect(img.Bounds())
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				v := uint64(img.Pix[y*img.Stride+x])
+				sum += v
+				sumSq += v * v
+			}
+		}
+		return sum, sumSq
+	}
+
+	rects := []image.Rectangle{
+		image.Rect(0, 0, 1, 1),
+		image.Rect(36, 22, 37, 23),
+		image.Rect(10, 5, 11, 6),
+		img.Bounds(),
+		image.Rect(-5, -5, 50, 50), // recortado à imagem inteira
+		image.Rect(-3, 4, 2, 9),    // metade fora
+		image.Rect(40, 0, 45, 5),   // todo fora
+		image.Rect(5, 5, 5, 10),    // vazio
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		x0, y0 := rng.Intn(37), rng.Intn(23)
+		rects = append(rects, image.Rect(x0, y0, x0+1+rng.Intn(37-x0), y0+1+rng.Intn(23-y0)))
+	}
+	for _, r := range rects {
+		wantSum, wantSq := brute(r)
+		if got := windowSum(ii, r); got != wantSum {
+			t.Errorf("soma em %v = %d, esperado %d", r, got, wantSum)
+		}
+		if got := windowSum(sq, r); got != wantSq {
+			t.Errorf("soma dos quadrados em %v = %d, esperado %d", r, got, wantSq)
+		}
+	}
+}
+
+func TestWindowSumEmptyImage(t *testing.T) {
+	ii := integralImage(image.NewGray(image.Rect(0, 0, 0, 0)))
+	if got := windowSum(ii, image.Rect(0, 0, 5, 5)); got != 0 {
+		t.Errorf("soma %d numa imagem vazia, esperado 0", got)
+	}
+}
+
+func TestIntegralNoOverflow(t *testing.T) {
+	if testing.Short() {
+		t.Skip("imagem 4096² não roda com -short")
+	}
+	const size = 4096
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	full := img.Bounds()
+	// 255² · 4096² passa de 2³², então uma tabela de 32 bits transbordaria
+	tests := []struct {
+		name string
+		ii   [][]uint64
+		want uint64
+	}{
+		{"soma", integralImage(img), 255 * size * size},
+		{"soma dos quadrados", integralImageSquared(img), 255 * 255 * size * size},
+	}
+	for _, tt := range tests {
+		if got := windowSum(tt.ii, full); got != tt.want {
+			t.Errorf("%s = %d, esperado %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+// inTriangle diz se (px, py) está no triângulo que se abre para cima a partir
+// de (x, y), a definição da tabela girada.
+func inTriangle(px, py, x, y int) bool {
+	return py <= y && abs(x-px) <= y-py
+}
+
+func TestTiltedWindowSum(t *testing.T) {
+	img := synthetic.Noise(31, 29, 128, 60, 2)
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	ti := tiltedIntegralImage(img)
+
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 300; i++ {
+		w, h := 1+rng.Intn(8), 1+rng.Intn(8)
+		x, y := rng.Intn(width+10)-5, rng.Intn(height-w-h+1)
+		if i == 0 {
+			w, h, x, y = 1, 1, 0, 0
+		}
+		// mesma combinação de triângulos, somada pixel a pixel
+		var want uint64
+		for py := 0; py < height; py++ {
+			for px := 0; px < width; px++ {
+				n := 0
+				for _, c := range []struct {
+					x, y, sign int
+				}{
+					{x - h + w, y + w + h - 1, 1},
+					{x, y - 1, 1},
+					{x - h, y + h - 1, -1},
+					{x + w, y + w - 1, -1},
+				} {
+					if inTriangle(px, py, c.x, c.y) {
+						n += c.sign
+					}
+				}
+				if n != 0 && n != 1 {
+					t.Fatalf("pixel %d,%d contado %d vezes no retângulo %d,%d,%d,%d", px, py, n, x, y, w, h)
+				}
+				want += uint64(n) * uint64(img.Pix[py*img.Stride+px])
+			}
+		}
+		if got := tiltedWindowSum(ti, x, y, w, h); got != want {
+			t.Errorf("retângulo girado %d,%d,%d,%d = %d, esperado %d", x, y, w, h, got, want)
+		}
+	}
+}
+
+func TestTiltedWindowSumArea(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 40, 40))
+	for i := range img.Pix {
+		img.Pix[i] = 1
+	}
+	ti := tiltedIntegralImage(img)
+	tests := []struct {
+		x, y, w, h int
+		want       uint64
+	}{
+		{20, 5, 1, 1, 2},
+		{20, 5, 3, 2, 12},
+		{20, 5, 4, 4, 32},
+		{20, 35, 4, 4, 0}, // passa da última linha
+	}
+	for _, tt := range tests {
+		if got := tiltedWindowSum(ti, tt.x, tt.y, tt.w, tt.h); got != tt.want {
+			t.Errorf("retângulo %d,%d,%d,%d cobre %d pixels, esperado %d", tt.x, tt.y, tt.w, tt.h, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"image"
+	"math"
+)
+
+// sampleBilinear interpola a imagem na posição (x, y) em coordenadas de pixel
+// (centros em inteiros). Fora da imagem devolve fill.
+func sampleBilinear(img *image.Gray, x, y float64, fill uint8) uint8 {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if x < -0.5 || y < -0.5 || x > float64(width)-0.5 || y > float64(height)-0.5 {
+		return fill
+	}
+
+	// dentro da margem de meio pixel a borda é replicada
+	x = math.Max(0, math.Min(float64(width-1), x))
+	y = math.Max(0, math.Min(float64(height-1), y))
+	x0, y0 := int(x), int(y)
+	x1, y1 := min(x0+1, width-1), min(y0+1, height-1)
+	fx, fy := x-float64(x0), y-float64(y0)
+
+	at := func(px, py int) float64 { return float64(img.Pix[py*img.Stride+px]) }
+	top := at(x0, y0)*(1-fx) + at(x1, y0)*fx
+	bottom := at(x0, y1)*(1-fx) + at(x1, y1)*fx
+	return uint8(math.Round(top*(1-fy) + bottom*fy))
+}
+
+// sampleNearest devolve o pixel mais próximo de (x, y), com a mesma margem
+// de meio pixel de sampleBilinear; fora dela devolve fill.
+func sampleNearest(img *image.Gray, x, y float64, fill uint8) uint8 {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if x < -0.5 || y < -0.5 || x > float64(width)-0.5 || y > float64(height)-0.5 {
+		return fill
+	}
+	px := min(max(int(math.Floor(x+0.5)), 0), width-1)
+	py := min(max(int(math.Floor(y+0.5)), 0), height-1)
+	return img.Pix[py*img.Stride+px]
+}
+
+// sampleBicubic interpola (x, y) pelo Catmull-Rom nos 16 vizinhos, com a
+// borda replicada e a mesma margem de sampleBilinear; fora dela devolve
+// fill.
+func sampleBicubic(img *image.Gray, x, y float64, fill uint8) uint8 {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if x < -0.5 || y < -0.5 || x > float64(width)-0.5 || y > float64(height)-0.5 {
+		return fill
+	}
+	x0, y0 := int(math.Floor(x)), int(math.Floor(y))
+	fx, fy := x-float64(x0), y-float64(y0)
+	var sum float64
+	for j := -1; j <= 2; j++ {
+		py := min(max(y0+j, 0), height-1)
+		var row float64
+		for i := -1; i <= 2; i++ {
+			px := min(max(x0+i, 0), width-1)
+			row += float64(img.Pix[py*img.Stride+px]) * catmullRom(float64(i)-fx)
+		}
+		sum += row * catmullRom(float64(j)-fy)
+	}
+	return uint8(math.Round(math.Min(math.Max(sum, 0), 255)))
+}
+
+// sample interpola (x, y) pelo método (ver sampleBilinear).
+func sample(img *image.Gray, x, y float64, fill uint8, method interpolation) uint8 {
+	switch method {
+	case interpNearest:
+		return sampleNearest(img, x, y, fill)
+	case interpBicubic:
+		return sampleBicubic(img, x, y, fill)
+	}
+	return sampleBilinear(img, x, y, fill)
+}
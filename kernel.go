@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"strconv"
+	"strings"
+
+	"processing-images/imaging"
+)
+
+// parseKernel lê um kernel em texto (uma linha por linha do kernel, valores
+// separados por espaço) ou em JSON (lista de listas). Os erros dizem a
+// linha e a coluna do problema, contando a partir de 1.
+func parseKernel(data []byte) ([][]float64, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return parseKernelJSON(trimmed)
+	}
+
+	var kernel [][]float64
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var row []float64
+		for col, field := range strings.Fields(text) {
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, fmt.Errorf("linha %d, coluna %d: %q não é um número", line, col+1, field)
+			}
+			row = append(row, v)
+		}
+		if len(kernel) > 0 && len(row) != len(kernel[0]) {
+			return nil, fmt.Errorf("linha %d tem %d valores, esperado %d como na primeira", line, len(row), len(kernel[0]))
+		}
+		kernel = append(kernel, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(kernel) == 0 {
+		return nil, fmt.Errorf("kernel vazio")
+	}
+	return kernel, nil
+}
+
+func parseKernelJSON(data []byte) ([][]float64, error) {
+	var raw [][]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("JSON inválido: %w", err)
+	}
+	if len(raw) == 0 || len(raw[0]) == 0 {
+		return nil, fmt.Errorf("kernel vazio")
+	}
+	kernel := make([][]float64, len(raw))
+	for i, row := range raw {
+		if len(row) != len(raw[0]) {
+			return nil, fmt.Errorf("linha %d tem %d valores, esperado %d como na primeira", i+1, len(row), len(raw[0]))
+		}
+		kernel[i] = make([]float64, len(row))
+		for j, v := range row {
+			f, ok := v.(float64)
+			if !ok {
+				return nil, fmt.Errorf("linha %d, coluna %d: %v não é um número", i+1, j+1, v)
+			}
+			kernel[i][j] = f
+		}
+	}
+	return kernel, nil
+}
+
+// loadKernel lê e valida o kernel de um arquivo.
+func loadKernel(path string, allowEven bool) ([][]float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o kernel: %w", err)
+	}
+	kernel, err := parseKernel(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateKernel(kernel, allowEven); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return kernel, nil
+}
+
+// validateKernel exige um kernel não vazio, retangular e, a menos que
+// allowEven, com dimensões ímpares (para ter um centro).
+func validateKernel(kernel [][]float64, allowEven bool) error {
+	if len(kernel) == 0 || len(kernel[0]) == 0 {
+		return fmt.Errorf("kernel vazio")
+	}
+	for i, row := range kernel {
+		if len(row) != len(kernel[0]) {
+			return fmt.Errorf("linha %d tem %d valores, esperado %d", i+1, len(row), len(kernel[0]))
+		}
+	}
+	if !allowEven && (len(kernel)%2 == 0 || len(kernel[0])%2 == 0) {
+		return fmt.Errorf("kernel %dx%d tem dimensão par; use -allow-even para aceitar", len(kernel[0]), len(kernel))
+	}
+	return nil
+}
+
+// parseAnchor lê a âncora "x,y"; vazio usa o padrão do kernel.
+func parseAnchor(s string, kernel [][]float64) (image.Point, error) {
+	if s == "" {
+		return imaging.KernelAnchor(kernel), nil
+	}
+	var p image.Point
+	if _, err := fmt.Sscanf(s, "%d,%d", &p.X, &p.Y); err != nil {
+		return p, fmt.Errorf("âncora inválida %q, use x,y", s)
+	}
+	if p.X < 0 || p.Y < 0 || p.X >= len(kernel[0]) || p.Y >= len(kernel) {
+		return p, fmt.Errorf("âncora (%d,%d) fora do kernel %dx%d", p.X, p.Y, len(kernel[0]), len(kernel))
+	}
+	return p, nil
+}
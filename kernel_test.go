@@ -0,0 +1,124 @@
+package main
+
+import (
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"processing-images/imaging"
+	"processing-images/synthetic"
+)
+
+// writeKernel grava o conteúdo num arquivo temporário e devolve o caminho.
+func writeKernel(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestKernelIdentity(t *testing.T) {
+	img := synthetic.SiemensStar(40, 30, 8)
+	for name, content := range map[string]string{
+		"identity.txt":  "# identidade\n0 0 0\n0 1 0\n0 0 0\n",
+		"identity.json": "[[0, 0, 0], [0, 1, 0], [0, 0, 0]]",
+	} {
+		kernel, err := loadKernel(writeKernel(t, name, content), false)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		result, err := imaging.Convolve(img, kernel, imaging.KernelNormalization{Mode: "auto"}, imaging.ResponseClamp, imaging.BorderReplicate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range img.Pix {
+			if result.Pix[i] != img.Pix[i] {
+				t.Errorf("%s: pixel %d = %d, esperado %d", name, i, result.Pix[i], img.Pix[i])
+				break
+			}
+		}
+	}
+}
+
+func TestKernelSobelFile(t *testing.T) {
+	kernel, err := loadKernel(writeKernel(t, "sobel.txt", "-1 0 1\n-2 0 2\n-1 0 1\n"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := synthetic.SiemensStar(40, 30, 8)
+	got, err := imaging.ConvolveFloat(img, kernel, imaging.KernelAnchor(kernel), 1, imaging.BorderReplicate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gx, _ := imaging.SobelComponents(img)
+	for y := range gx {
+		for x := range gx[y] {
+			if math.Abs(got[y][x]-gx[y][x]) > 1e-9 {
+				t.Fatalf("(%d,%d): %g, esperado %g do sobel", x, y, got[y][x], gx[y][x])
+			}
+		}
+	}
+}
+
+func TestKernelErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		allowEven bool
+		want      string
+	}{
+		{"linhas desiguais", "1 2 3\n4 5\n6 7 8\n", false, "linha 2 tem 2 valores, esperado 3"},
+		{"token não numérico", "1 2 3\n4 x 6\n7 8 9\n", false, `linha 2, coluna 2: "x" não é um número`},
+		{"linha depois de comentário", "# kernel\n1 1 1\n1 1 1\n1 1\n", false, "linha 4 tem 2 valores"},
+		{"vazio", "# nada\n\n", false, "kernel vazio"},
+		{"par", "1 1\n1 1\n", false, "dimensão par"},
+		{"JSON desigual", "[[1, 2, 3], [4, 5]]", false, "linha 2 tem 2 valores, esperado 3"},
+		{"JSON não numérico", `[[1, 2, 3], [4, "a", 6], [7, 8, 9]]`, false, "linha 2, coluna 2: a não é um número"},
+		{"JSON inválido", "[[1, 2", false, "JSON inválido"},
+	}
+	for _, tt := range tests {
+		_, err := loadKernel(writeKernel(t, "k.txt", tt.content), tt.allowEven)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: erro %v, esperado conter %q", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := loadKernel(writeKernel(t, "k.txt", "1 1\n1 1\n"), true); err != nil {
+		t.Errorf("kernel par com allowEven: %v", err)
+	}
+	if _, err := loadKernel(filepath.Join(t.TempDir(), "nada.txt"), false); err == nil {
+		t.Error("arquivo que não existe sem erro")
+	}
+}
+
+func TestParseAnchor(t *testing.T) {
+	kernel := [][]float64{{1, 1, 1, 1}, {1, 1, 1, 1}} // 4x2
+	tests := []struct {
+		s       string
+		want    image.Point
+		wantErr bool
+	}{
+		{"", image.Pt(2, 1), false},
+		{"0,0", image.Pt(0, 0), false},
+		{"3,1", image.Pt(3, 1), false},
+		{"4,0", image.Point{}, true},
+		{"0,2", image.Point{}, true},
+		{"-1,0", image.Point{}, true},
+		{"centro", image.Point{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseAnchor(tt.s, kernel)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: erro %v", tt.s, err)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("%q: %v, esperado %v", tt.s, got, tt.want)
+		}
+	}
+}
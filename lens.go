@@ -0,0 +1,67 @@
+package main
+
+import (
+	"image"
+	"math"
+)
+
+// Modelo radial: um ponto ideal p, a distância normalizada r do centro
+// (cx, cy), aparece na foto em c + (p − c)·(1 + k1·r² + k2·r⁴). As distâncias
+// são normalizadas pela meia diagonal da imagem, então k1 = −0.1 é um barril
+// leve independente da resolução. cx, cy < 0 usam o centro da imagem.
+
+func lensCenter(width, height int, cx, cy float64) (float64, float64, float64) {
+	if cx < 0 {
+		cx = float64(width-1) / 2
+	}
+	if cy < 0 {
+		cy = float64(height-1) / 2
+	}
+	norm := math.Hypot(float64(width)/2, float64(height)/2)
+	return cx, cy, norm
+}
+
+// undistort remove a distorção: cada pixel da saída (posição ideal) busca na
+// foto a posição dada pelo modelo direto e interpola bilinearmente.
+func undistort(img *image.Gray, k1, k2 float64, cx, cy float64) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	cx, cy, norm := lensCenter(width, height, cx, cy)
+	result := image.NewGray(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dx, dy := (float64(x)-cx)/norm, (float64(y)-cy)/norm
+			r2 := dx*dx + dy*dy
+			factor := 1 + k1*r2 + k2*r2*r2
+			sx, sy := cx+dx*factor*norm, cy+dy*factor*norm
+			result.Pix[y*result.Stride+x] = sampleBilinear(img, sx, sy, 0)
+		}
+	}
+	return result
+}
+
+// distort aplica a distorção, para gerar casos de teste. Cada pixel da saída
+// (posição na foto) precisa da posição ideal correspondente, que é obtida
+// invertendo o modelo direto pelo método de ponto fixo r = rd/(1+k1r²+k2r⁴).
+func distort(img *image.Gray, k1, k2 float64, cx, cy float64) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	cx, cy, norm := lensCenter(width, height, cx, cy)
+	result := image.NewGray(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dx, dy := (float64(x)-cx)/norm, (float64(y)-cy)/norm
+			ux, uy := dx, dy
+			for i := 0; i < 20; i++ {
+				r2 := ux*ux + uy*uy
+				factor := 1 + k1*r2 + k2*r2*r2
+				if factor <= 0 {
+					break
+				}
+				ux, uy = dx/factor, dy/factor
+			}
+			result.Pix[y*result.Stride+x] = sampleBilinear(img, cx+ux*norm, cy+uy*norm, 0)
+		}
+	}
+	return result
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"math"
+	"testing"
+)
+
+// gridImage é branca com linhas pretas de 2 pixels a cada 32, nas duas
+// direções.
+func gridImage(size int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if x%32 < 2 || y%32 < 2 {
+				continue
+			}
+			img.Pix[y*img.Stride+x] = 255
+		}
+	}
+	return img
+}
+
+// lineDeviation acha, em cada coluna, o centro de massa da escuridão da
+// linha horizontal perto de y0, ajusta uma reta a esses centros por mínimos
+// quadrados e devolve o maior resíduo. As colunas perto das linhas verticais
+// da grade ficam de fora.
+func lineDeviation(img *image.Gray, y0, x0, x1 int) float64 {
+	var xs, ys []float64
+	for x := x0; x < x1; x++ {
+		if m := x % 32; m < 4 || m > 29 {
+			continue
+		}
+		var sum, weight float64
+		for y := y0 - 12; y <= y0+12; y++ {
+			w := 255 - float64(img.Pix[y*img.Stride+x])
+			sum += w * float64(y)
+			weight += w
+		}
+		if weight == 0 {
+			continue
+		}
+		xs = append(xs, float64(x))
+		ys = append(ys, sum/weight)
+	}
+
+	n := float64(len(xs))
+	var sx, sy, sxx, sxy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+		sxx += xs[i] * xs[i]
+		sxy += xs[i] * ys[i]
+	}
+	slope := (n*sxy - sx*sy) / (n*sxx - sx*sx)
+	intercept := (sy - slope*sx) / n
+
+	var worst float64
+	for i := range xs {
+		worst = math.Max(worst, math.Abs(ys[i]-(intercept+slope*xs[i])))
+	}
+	return worst
+}
+
+func TestUndistortStraightensLines(t *testing.T) {
+	grid := gridImage(193)
+	tests := []struct {
+		name   string
+		k1, k2 float64
+	}{
+		{"barril", -0.15, 0},
+		{"almofada", 0.1, 0},
+		{"barril com k2", -0.1, -0.05},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			distorted := distort(grid, tt.k1, tt.k2, -1, -1)
+			restored := undistort(distorted, tt.k1, tt.k2, -1, -1)
+			// linhas fora do centro, onde a distorção mais as curva
+			for _, y0 := range []int{32, 64, 128, 160} {
+				if d := lineDeviation(distorted, y0, 20, 173); d < 1 {
+					t.Fatalf("linha %d já reta na imagem distorcida (%.2f px)", y0, d)
+				}
+				if d := lineDeviation(restored, y0, 20, 173); d >= 1 {
+					t.Errorf("linha %d desvia %.2f px da reta após corrigir", y0, d)
+				}
+			}
+		})
+	}
+}
+
+func TestLensIdentity(t *testing.T) {
+	grid := gridImage(65)
+	tests := []struct {
+		name string
+		fn   func(*image.Gray, float64, float64, float64, float64) *image.Gray
+	}{
+		{"distort", distort},
+		{"undistort", undistort},
+	}
+	for _, tt := range tests {
+		if got := tt.fn(grid, 0, 0, -1, -1); !bytes.Equal(got.Pix, grid.Pix) {
+			t.Errorf("%s com k1=k2=0 mudou a imagem", tt.name)
+		}
+	}
+}
@@ -1,14 +1,22 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"flag"
 	"fmt"
 	"image"
 	"image/color"
-	_ "image/jpeg"
+	"image/gif"
+	"image/jpeg"
 	"image/png"
 	"log"
 	"math"
 	"os"
+	"path/filepath"
+	"strings"
+
+	"processing-images/imaging"
 )
 
 // explicação dos algoritmos
@@ -24,548 +32,1100 @@ import (
 // calcula gradientes usando operadores (sobel)
 // mantém apenas os pixels onde tem a magnitude máxima.
 
-func loadImage(filename string) *image.Gray {
-	file, err := os.Open(filename)
-	if err != nil {
-		fmt.Println("Erro ao abrir a imagem!")
-		log.Fatal(err)
-	}
-	defer file.Close()
+// loadImage carrega a imagem em tons de cinza. Os erros embrulham os do
+// sistema (errors.Is(err, os.ErrNotExist) funciona).
+func loadImage(filename string) (*image.Gray, error) {
+	img, _, err := loadImageMeta(filename)
+	return img, err
+}
 
-	img, _, err := image.Decode(file)
+// loadImageMeta carrega a imagem junto com os metadados que devem ser
+// preservados nas saídas.
+func loadImageMeta(filename string) (*image.Gray, imageMeta, error) {
+	img, meta, err := decodeImage(filename)
 	if err != nil {
-		log.Fatalf("Erro ao decodificar a imagem: %v", err)
+		return nil, imageMeta{}, err
 	}
+	return grayscale(img), meta, nil
+}
 
-	gray := image.NewGray(img.Bounds())
-	for x := 0; x < img.Bounds().Dx(); x++ {
-		for y := 0; y < img.Bounds().Dy(); y++ {
-			gray.Set(x, y, img.At(x, y))
+// grayscale converte a imagem decodificada para tons de cinza com os pesos
+// de -gray (BT.601 por padrão); imagens já em cinza voltam como estão, só
+// copiadas para a origem 0,0 quando começam em outro ponto.
+func grayscale(img image.Image) *image.Gray {
+	if gray, ok := img.(*image.Gray); ok {
+		// o resto do programa indexa Pix a partir de 0,0
+		if gray.Bounds().Min != (image.Point{}) {
+			gray, _ = crop(gray, gray.Bounds())
 		}
+		return gray
 	}
-
-	return gray
+	weights, err := imaging.ParseGrayWeights(*grayMode)
+	if err != nil {
+		weights = imaging.LumaBT601
+	}
+	return imaging.ToGrayWeights(img, weights)
 }
 
-func saveImage(path string, img image.Image) {
-	file, err := os.Create(path)
+// errDecode marca os erros de arquivos que existem mas não são imagens
+// legíveis.
+var errDecode = errors.New("erro ao decodificar a imagem")
+
+// maxDecodePixels limita o tamanho declarado no cabeçalho dos formatos que
+// decodificamos aqui, para um arquivo pequeno não pedir gigabytes.
+const maxDecodePixels = 1 << 27
+
+// decodeImage decodifica a imagem (colorida ou não) já na orientação de
+// exibição.
+func decodeImage(filename string) (image.Image, imageMeta, error) {
+	data, err := os.ReadFile(filename)
 	if err != nil {
-		log.Fatal(err)
+		return nil, imageMeta{}, fmt.Errorf("erro ao abrir a imagem: %w", err)
 	}
-	defer file.Close()
-	err = png.Encode(file, img)
+
+	if isRLEPath(filename) {
+		mask, err := parseRLE(data)
+		if err != nil {
+			return nil, imageMeta{}, fmt.Errorf("erro ao decodificar a máscara %s: %w", filename, err)
+		}
+		return mask, imageMeta{}, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
 	if err != nil {
-		log.Fatal(err)
+		return nil, imageMeta{}, fmt.Errorf("%w %s: %w", errDecode, filename, err)
 	}
-}
 
-func applyConvolution(img *image.Gray, kernel [][]float64, normalize float64) *image.Gray {
-	width, height := img.Bounds().Dx(), img.Bounds().Dy()
-	newImg := image.NewGray(img.Bounds())
-
-	offset := len(kernel) / 2
-	for x := offset; x < width-offset; x++ {
-		for y := offset; y < height-offset; y++ {
-			var sum float64
-			for i := -offset; i <= offset; i++ {
-				for j := -offset; j <= offset; j++ {
-					sum += float64(img.GrayAt(x+i, y+j).Y) * kernel[i+offset][j+offset]
-				}
-			}
-			newImg.SetGray(x, y, color.Gray{uint8(math.Min(255, sum/normalize))})
+	// fotos de celular vêm "deitadas" se a orientação EXIF for ignorada
+	if orientation := exifOrientation(data); !*noExifRotate && orientation > 1 {
+		if gray, ok := img.(*image.Gray); ok {
+			img = applyOrientation(gray, orientation)
+		} else {
+			img = applyOrientationRGBA(toRGBA(img), orientation)
 		}
 	}
 
-	return newImg
+	var meta imageMeta
+	meta.ppmX, meta.ppmY, _ = readPNGPhys(data)
+
+	return img, meta, nil
 }
 
-func cannyEdgeDetection(img *image.Gray) *image.Gray {
-	sobelX := [][]float64{
-		{-1, 0, 1},
-		{-2, 0, 2},
-		{-1, 0, 1},
-	}
-	sobelY := [][]float64{
-		{-1, -2, -1},
-		{0, 0, 0},
-		{1, 2, 1},
-	}
-
-	width, height := img.Bounds().Dx(), img.Bounds().Dy()
-	newImg := image.NewGray(img.Bounds())
-
-	for x := 1; x < width-1; x++ {
-		for y := 1; y < height-1; y++ {
-			var gx, gy float64
-			for i := -1; i <= 1; i++ {
-				for j := -1; j <= 1; j++ {
-					gray := float64(img.GrayAt(x+i, y+j).Y)
-					gx += gray * sobelX[i+1][j+1]
-					gy += gray * sobelY[i+1][j+1]
-				}
-			}
-			magnitude := math.Sqrt(gx*gx + gy*gy)
-			newImg.SetGray(x, y, color.Gray{uint8(math.Min(255, magnitude))})
-		}
+func saveImage(path string, img image.Image) error {
+	return saveImageMeta(path, img, imageMeta{})
+}
+
+// encodeOptions são os parâmetros dos codificadores com perdas ou
+// compressão ajustável.
+type encodeOptions struct {
+	Quality     int                  // qualidade do JPEG, 1 a 100
+	Compression png.CompressionLevel // nível de compressão do PNG
+}
+
+// defaultEncodeOptions são as opções de saveImageMeta sem as flags.
+var defaultEncodeOptions = encodeOptions{Quality: 90, Compression: png.DefaultCompression}
+
+// imageFormats são os formatos de saída, pela extensão.
+var imageFormats = map[string]string{
+	".png": "png", ".jpg": "jpeg", ".jpeg": "jpeg", ".gif": "gif", ".bmp": "bmp", ".pgm": "pgm",
+}
+
+// imageFormat escolhe o formato de saída pela extensão do caminho.
+func imageFormat(path string) (string, error) {
+	if format, ok := imageFormats[strings.ToLower(filepath.Ext(path))]; ok {
+		return format, nil
 	}
+	return "", fmt.Errorf("formato de saída desconhecido para %s, use .png, .jpg, .jpeg, .gif, .bmp, .pgm ou .rle.json", path)
+}
 
-	return newImg
+// saveImageMeta grava a imagem com as opções de -quality e
+// -png-compression (ver saveImageOptions).
+func saveImageMeta(path string, img image.Image, meta imageMeta) error {
+	opts := defaultEncodeOptions
+	opts.Quality = *jpegQuality
+	opts.Compression, _ = parsePNGCompression(*pngCompression)
+	return saveImageOptions(path, img, meta, opts)
 }
-func otsuThreshold(img *image.Gray) *image.Gray {
-	histogram := make([]int, 256)
-	width, height := img.Bounds().Dx(), img.Bounds().Dy()
-	totalPixels := width * height
 
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			grayValue := img.GrayAt(x, y).Y
-			histogram[grayValue]++
+// saveImageOptions grava a imagem no formato da extensão do caminho: PNG
+// (repassando a resolução física da entrada no pHYs), JPEG, GIF, BMP ou PGM
+// (em tons de cinza).
+// Caminhos .rle.json gravam a máscara em RLE.
+func saveImageOptions(path string, img image.Image, meta imageMeta, opts encodeOptions) error {
+	if isRLEPath(path) {
+		fg, err := parsePolarity(*rleForeground)
+		if err != nil {
+			return err
+		}
+		data, err := marshalRLE(encodeRLEPolarity(grayscale(img), fg))
+		if err != nil {
+			return fmt.Errorf("erro ao codificar %s: %w", path, err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return fmt.Errorf("erro ao gravar %s: %w", path, err)
 		}
+		return nil
 	}
-
-	var sum, sumB, wB, wF, varMax float64
-	for i := 0; i < 256; i++ {
-		sum += float64(i * histogram[i])
+	format, err := imageFormat(path)
+	if err != nil {
+		return err
 	}
 
-	var threshold uint8
-	for t := 0; t < 256; t++ {
-		wB += float64(histogram[t])
-		if wB == 0 {
-			continue
+	switch *bitDepth {
+	case 8:
+		// só imagens em tons de cinza recebem a marca; máscaras em 1 bit e
+		// RLE não têm onde guardá-la, e o JPEG a apagaria
+		if gray, ok := img.(*image.Gray); ok && *watermarkText != "" && format != "jpeg" {
+			marked, err := embedWatermarkSeed(gray, []byte(*watermarkText), *watermarkSeed)
+			if err != nil {
+				return fmt.Errorf("erro ao marcar %s: %w", path, err)
+			}
+			img = marked
+		}
+	case 1:
+		if format != "png" {
+			return fmt.Errorf("erro ao gravar %s com 1 bit: só PNG tem essa profundidade", path)
 		}
-		wF = float64(totalPixels) - wB
-		if wF == 0 {
-			break
+		paletted, err := toPaletted1(img)
+		if err != nil {
+			return fmt.Errorf("erro ao gravar %s com 1 bit: %w", path, err)
+		}
+		img = paletted
+	default:
+		return fmt.Errorf("profundidade inválida %d, use 1 ou 8", *bitDepth)
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "png":
+		encoder := png.Encoder{CompressionLevel: opts.Compression}
+		err = encoder.Encode(&buf, img)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: opts.Quality})
+	case "gif":
+		err = gif.Encode(&buf, grayToPaletted(img), nil)
+	case "bmp":
+		err = encodeBMP(&buf, img)
+	case "pgm":
+		err = encodePGM(&buf, grayscale(img))
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao codificar %s: %w", path, err)
+	}
+
+	if format != "png" {
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			return fmt.Errorf("erro ao gravar %s: %w", path, err)
 		}
+		return nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar %s: %w", path, err)
+	}
+	if err := writePNGWithPhys(file, buf.Bytes(), meta); err != nil {
+		file.Close()
+		return fmt.Errorf("erro ao gravar %s: %w", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("erro ao gravar %s: %w", path, err)
+	}
+	return nil
+}
+
+// grayToPaletted passa imagens em tons de cinza para uma paleta com os 256
+// cinzas, para o GIF não reduzi-las à paleta padrão dele (que tem poucos
+// cinzas e pontilha o resto). As coloridas passam como estão.
+func grayToPaletted(img image.Image) image.Image {
+	gray, ok := img.(*image.Gray)
+	if !ok {
+		return img
+	}
+	palette := make(color.Palette, 256)
+	for v := range palette {
+		palette[v] = color.Gray{uint8(v)}
+	}
+	b := gray.Bounds()
+	result := image.NewPaletted(image.Rect(0, 0, b.Dx(), b.Dy()), palette)
+	for y := 0; y < b.Dy(); y++ {
+		copy(result.Pix[y*result.Stride:y*result.Stride+b.Dx()], gray.Pix[gray.PixOffset(b.Min.X, b.Min.Y+y):])
+	}
+	return result
+}
+
+// parsePNGCompression lê o nível de -png-compression.
+func parsePNGCompression(s string) (png.CompressionLevel, error) {
+	switch s {
+	case "default":
+		return png.DefaultCompression, nil
+	case "none":
+		return png.NoCompression, nil
+	case "fast":
+		return png.BestSpeed, nil
+	case "best":
+		return png.BestCompression, nil
+	}
+	return 0, fmt.Errorf("compressão desconhecida %q, use default, none, fast ou best", s)
+}
 
-		sumB += float64(t * histogram[t])
-		mB := sumB / wB
-		mF := (sum - sumB) / wF
+// magnitudeScale define como a magnitude vira 0..255:
+// "clamp" satura em 255, "normalize" divide pelo máximo da imagem e
+// "divide" divide por um valor fixo (ex: 4, que leva a borda horizontal ou
+// vertical mais forte, 1020, a 255, ou imaging.SobelMaxMagnitude/255, que
+// nunca satura).
+type magnitudeScale struct {
+	mode    string
+	divisor float64
+}
 
-		varBetween := wB * wF * math.Pow(mB-mF, 2)
-		if varBetween > varMax {
-			varMax = varBetween
-			threshold = uint8(t)
+func scaleMagnitude(mag [][]float64, scale magnitudeScale) *image.Gray {
+	height := len(mag)
+	width := 0
+	if height > 0 {
+		width = len(mag[0])
+	}
+	newImg := image.NewGray(image.Rect(0, 0, width, height))
+
+	factor := 1.0
+	switch scale.mode {
+	case "normalize":
+		var maxMag float64
+		for _, row := range mag {
+			for _, m := range row {
+				maxMag = math.Max(maxMag, m)
+			}
+		}
+		if maxMag > 0 {
+			factor = 255 / maxMag
+		}
+	case "divide":
+		if scale.divisor > 0 {
+			factor = 1 / scale.divisor
 		}
 	}
 
-	newImg := image.NewGray(img.Bounds())
 	for y := 0; y < height; y++ {
 		for x := 0; x < width; x++ {
-			if img.GrayAt(x, y).Y > threshold {
-				newImg.SetGray(x, y, color.Gray{255})
-			} else {
-				newImg.SetGray(x, y, color.Gray{0})
-			}
+			newImg.SetGray(x, y, color.Gray{uint8(math.Min(255, mag[y][x]*factor))})
 		}
 	}
 
 	return newImg
 }
 
-func marrHildreth(img *image.Gray) *image.Gray {
-	laplacianKernel := [][]float64{
-		{0, 1, 0},
-		{1, -4, 1},
-		{0, 1, 0},
-	}
-	return applyConvolution(img, laplacianKernel, 1)
+// resultado de uma etapa, salvo só depois do processamento
+type output struct {
+	name string
+	img  image.Image
 }
 
-func watershed(img *image.Gray, bgPercentage float64) *image.Gray {
-	if bgPercentage < 0 || bgPercentage > 1 {
-		panic("bgPercentage deve estar entre 0 e 1")
-	}
+var (
+	cpuProfile = flag.String("cpuprofile", "", "grava o perfil de CPU do processamento neste arquivo")
+	memProfile = flag.String("memprofile", "", "grava o perfil de memória do processamento neste arquivo")
+	httpProf   = flag.String("httpprof", "", "serve net/http/pprof neste endereço (ex: :6060)")
+
+	borderFlag  = flag.String("border", "replicate", "pixels fora da imagem no Canny, no -kernel e nos filtros box: replicate, reflect, wrap ou zero")
+	mhSigma     = flag.Float64("mh-sigma", 2, "σ da gaussiana do Marr-Hildreth")
+	mhSize      = flag.Int("mh-size", 0, "tamanho do kernel gaussiano do Marr-Hildreth (0: 2·ceil(3σ)+1)")
+	mhSlope     = flag.Float64("mh-slope", 1, "diferença mínima do laplaciano num cruzamento de zero do Marr-Hildreth")
+	cannySigma  = flag.Float64("canny-sigma", imaging.DefaultCannyOptions.Sigma, "σ da suavização antes do Canny (0 desliga)")
+	cannyLow    = flag.Float64("canny-low", imaging.DefaultCannyOptions.Low, "limiar fraco da histerese do Canny (magnitude do sobel)")
+	cannyHigh   = flag.Float64("canny-high", imaging.DefaultCannyOptions.High, "limiar forte da histerese do Canny (magnitude do sobel)")
+	gradScale   = flag.String("grad-scale", "clamp", "escala da magnitude do gradiente (gradient_mag.png): clamp, normalize ou divide")
+	gradDivisor = flag.Float64("grad-divisor", 4, "divisor da magnitude no modo -grad-scale divide")
+
+	gradOperator = flag.String("operator", "sobel", "operador do gradiente (gradient_mag.png, gradient_dir.png): sobel, prewitt, scharr ou roberts")
+
+	directionViz       = flag.Bool("direction-viz", false, "salva direction.png com a direção do gradiente por setor")
+	directionBins      = flag.Int("direction-bins", 4, "número de setores da direção: 4 ou 8")
+	directionThreshold = flag.Float64("direction-threshold", 50, "magnitude mínima para colorir um pixel")
+
+	structureTensorOut = flag.Bool("structure-tensor", false, "salva os mapas de coerência e orientação do tensor de estrutura")
+	structureSigma     = flag.Float64("st-sigma", 2, "desvio da gaussiana que suaviza o tensor de estrutura")
+	structureMask      = flag.String("st-mask", "", "máscara (branco = dentro) para a coerência média")
+
+	frangi       = flag.Bool("frangi", false, "salva frangi.png com o realce de cristas (vesselness)")
+	frangiScales = flag.String("frangi-scales", "1,2,3", "escalas σ do filtro de Frangi")
+	frangiBeta   = flag.Float64("frangi-beta", 0.5, "parâmetro β (sensibilidade a blobs) do filtro de Frangi")
+	frangiC      = flag.Float64("frangi-c", 0, "parâmetro c (sensibilidade a contraste); 0 = automático")
+	frangiBright = flag.Bool("frangi-bright", false, "realça cristas claras em fundo escuro em vez de rachaduras escuras")
+
+	blobs         = flag.Bool("blobs", false, "detecta blobs no espaço de escalas LoG (blobs.json e blobs.png)")
+	blobMinSigma  = flag.Float64("blob-min-sigma", 2, "menor σ da detecção de blobs")
+	blobMaxSigma  = flag.Float64("blob-max-sigma", 16, "maior σ da detecção de blobs")
+	blobSteps     = flag.Int("blob-steps", 12, "número de escalas entre o menor e o maior σ")
+	blobThreshold = flag.Float64("blob-threshold", 10, "resposta LoG normalizada mínima")
+	blobBright    = flag.Bool("blob-bright", false, "procura blobs claros em fundo escuro")
+
+	mser             = flag.Bool("mser", false, "detecta regiões MSER (mser.json e mser.png)")
+	mserDelta        = flag.Int("mser-delta", 5, "Δ de níveis usado na estabilidade das regiões MSER")
+	mserMinArea      = flag.Int("mser-min-area", 30, "área mínima das regiões MSER")
+	mserMaxArea      = flag.Int("mser-max-area", 0, "área máxima das regiões MSER (0 = imagem inteira)")
+	mserMaxVariation = flag.Float64("mser-max-variation", 0.25, "variação máxima de área das regiões MSER")
+	mserBright       = flag.Bool("mser-bright", false, "procura regiões claras em vez de escuras")
+	mserKeepBorder   = flag.Bool("mser-keep-border", false, "mantém regiões MSER que tocam a borda da imagem")
+
+	guided       = flag.Bool("guided", false, "salva guided.png, a imagem suavizada pelo filtro guiado com ela mesma como guia")
+	guidedMask   = flag.String("guided-mask", "", "refina esta máscara usando a imagem de entrada como guia (guided_mask.png)")
+	guidedRadius = flag.Int("guided-radius", 8, "raio da janela do filtro guiado")
+	guidedEps    = flag.Float64("guided-eps", 0.01, "regularização do filtro guiado (variância em [0,1])")
+
+	cartoonOut   = flag.Bool("cartoon", false, "salva cartoon.png com o efeito de desenho animado")
+	cartoonBands = flag.Int("cartoon-bands", defaultCartoonOptions.Bands, "níveis por canal no efeito cartoon")
+	cartoonEdge  = flag.Float64("cartoon-edge", defaultCartoonOptions.EdgeStrength, "força do limiar dos traços do cartoon (maior = menos traços)")
+	cartoonIter  = flag.Int("cartoon-iter", defaultCartoonOptions.Iterations, "passadas de suavização do cartoon")
+
+	sketch        = flag.Bool("sketch", false, "salva sketch.png com o efeito de desenho a lápis")
+	sketchSigma   = flag.Float64("sketch-sigma", 8, "suavidade dos traços do desenho a lápis")
+	sketchTexture = flag.Bool("sketch-texture", false, "adiciona riscos de textura ao desenho a lápis")
+	sketchSeed    = flag.Int64("sketch-seed", 1, "semente da textura do desenho a lápis")
+
+	halftoneOut   = flag.Bool("halftone", false, "salva halftone.png com a retícula de pontos")
+	halftoneCell  = flag.Int("halftone-cell", 8, "tamanho da célula da retícula em pixels")
+	halftoneAngle = flag.Float64("halftone-angle", 45, "ângulo da retícula em graus")
+
+	devignette     = flag.Bool("devignette", false, "salva devignette.png com a vinheta corrigida")
+	vignetteOrder  = flag.Int("vignette-order", 2, "ordem do polinômio radial da vinheta")
+	vignette       = flag.Float64("vignette", 0, "aplica uma vinheta com esta força (vignette.png)")
+	vignetteRadius = flag.Float64("vignette-radius", 1, "raio normalizado em que a vinheta atinge a força total")
+
+	undistortOut = flag.Bool("undistort", false, "salva undistort.png com a distorção radial corrigida")
+	distortOut   = flag.Bool("distort", false, "salva distort.png com a distorção radial aplicada")
+	lensK1       = flag.Float64("lens-k1", -0.1, "coeficiente radial k1 (negativo = barril)")
+	lensK2       = flag.Float64("lens-k2", 0, "coeficiente radial k2")
+	lensCX       = flag.Float64("lens-cx", -1, "centro x da distorção (-1 = centro da imagem)")
+	lensCY       = flag.Float64("lens-cy", -1, "centro y da distorção (-1 = centro da imagem)")
+
+	deskewOut = flag.Bool("deskew", false, "salva deskew.png com o documento endireitado")
+	deskewMax = flag.Float64("deskew-max", 10, "maior inclinação procurada, em graus")
+
+	ops         = flag.String("ops", "", "roda só estas operações (separadas por vírgula); \"list\" mostra as disponíveis")
+	outDir      = flag.String("out", "", "diretório das saídas, criado se não existir (padrão: o atual)")
+	watershedBg = flag.Float64("bg", 0.7, "fração (0 a 1) dos pixels mais escuros tratada como fundo em -ops background")
+	boxSizes    = flag.String("box", "2,3,5,7", "tamanhos dos filtros box (filtered_NxN.png), separados por vírgula")
+
+	docbinSigma  = flag.Float64("docbin-sigma", 25, "σ do borrão que estima o fundo no docbin")
+	docbinWindow = flag.Int("docbin-window", 25, "janela do Sauvola no docbin")
+	docbinK      = flag.Float64("docbin-k", 0.2, "parâmetro k do Sauvola no docbin")
+	docbinSpeck  = flag.Int("docbin-speck", 8, "componentes com menos pixels são apagados no docbin")
+	docbinDeskew = flag.Bool("docbin-deskew", false, "endireita o documento no docbin")
+
+	textLines = flag.Bool("textlines", false, "segmenta linhas e palavras de um documento binarizado (textlines.json e textlines.png)")
+	wordGap   = flag.Int("word-gap", 0, "colunas vazias que separam palavras (0 = automático)")
+
+	quadtree    = flag.Bool("quadtree", false, "decompõe a imagem em quadtree (quadtree.png e quadtree_blocks.png)")
+	quadtreeStd = flag.Float64("quadtree-std", 10, "desvio padrão máximo de um bloco da quadtree")
+	quadtreeMin = flag.Int("quadtree-min", 4, "menor lado de bloco da quadtree")
+
+	thumb = flag.Int("thumb", 0, "salva thumb.png (colorida) cabendo num quadrado deste lado")
+
+	hogOut   = flag.Bool("hog", false, "extrai o descritor HOG (hog.csv e hog.png)")
+	hogCell  = flag.Int("hog-cell", 8, "lado das células do HOG, em pixels")
+	hogBlock = flag.Int("hog-block", 2, "lado dos blocos do HOG, em células")
+	hogBins  = flag.Int("hog-bins", 9, "bins de orientação do HOG")
+
+	roiPoly = flag.String("roi-poly", "", "região de interesse poligonal: \"x1,y1 x2,y2 ...\" ou arquivo .json com [[x, y], ...]")
+	roiFill = flag.Int("roi-fill", -1, "valor dos pixels fora da região de interesse (-1 = mantém a entrada)")
+
+	debugStages = flag.String("debug-stages", "", "salva as etapas intermediárias (Canny, -ops) neste diretório, com stages.json")
+
+	kernelPath       = flag.String("kernel", "", "aplica o kernel do arquivo (texto ou JSON) e salva convolved.png")
+	kernelNorm       = flag.String("kernel-norm", "auto", "divisor do -kernel: auto (soma dos coeficientes), none ou um número")
+	kernelOutput     = flag.String("kernel-output", "clamp", "saída do -kernel: clamp, abs ou offset (+128)")
+	kernelAnchorFlag = flag.String("kernel-anchor", "", "âncora x,y do -kernel (padrão: centro)")
+	allowEven        = flag.Bool("allow-even", false, "aceita kernels com dimensão par")
+
+	openRec = flag.Int("open-rec", 0, "abertura por reconstrução com um quadrado deste tamanho (opened_rec.png)")
+
+	medianSize = flag.Int("median", 0, "filtro da mediana com uma janela deste tamanho (median.png), remove ruído sal e pimenta")
+
+	wsMarkers = flag.String("ws-markers", imaging.DefaultWatershedOptions.Markers, "marcadores do watershed: otsu (frente erodida) ou minima (mínimos do gradiente)")
+	wsErode   = flag.Int("ws-erode", imaging.DefaultWatershedOptions.Erode, "erosões 3x3 dos marcadores otsu (0 = até cada objeto sumir)")
+	wsBright  = flag.Bool("ws-bright", false, "no watershed, os objetos são claros em fundo escuro (ex: moedas)")
+	wsSigma   = flag.Float64("ws-sigma", imaging.DefaultWatershedOptions.Sigma, "σ da suavização antes do gradiente do watershed")
+
+	connectivity = flag.Int("connectivity", imaging.DefaultCountObjectsOptions.Connectivity, "vizinhança da contagem de objetos, de -ops label e de -objects: 4 ou 8")
+	minArea      = flag.Int("min-area", imaging.DefaultCountObjectsOptions.MinArea, "área mínima, em pixels, de um objeto contado")
+	countSize    = flag.Int("count-size", imaging.DefaultCountObjectsOptions.Size, "lado do quadrado da abertura e do fechamento da contagem de objetos")
+	countIter    = flag.Int("count-iter", imaging.DefaultCountObjectsOptions.Iterations, "passadas da abertura e do fechamento da contagem (0 conta sem limpar)")
+	objectsCSV   = flag.Bool("objects", false, "mede cada objeto do Otsu: área, centroide, retângulo, perímetro e diâmetro equivalente (objects.csv)")
+
+	rankSize = flag.Int("rank-size", 3, "lado da janela de -ops min, max e midpoint")
+
+	morphSE = flag.String("se", "disk:7", "elemento estruturante de -ops opening, closing, morphgradient, tophat e blackhat: square:N, disk:R ou cross:N")
+
+	chainFormat = flag.String("format", "text", "formato dos códigos de cadeia: text (freeman_chain.txt) ou json (freeman_chain.json); um formato de imagem (png, jpg...) vale como -out-format")
+	skeleton    = flag.Bool("skeleton", false, "afina os objetos do Otsu (Zhang-Suen) antes do código de cadeia e salva skeleton.png")
+
+	distanceMetric = flag.String("distance-metric", "euclidean", "métrica de -ops distance: euclidean ou chamfer")
+	distanceInvert = flag.Bool("distance-invert", false, "em -ops distance, mede a distância no fundo (pixels claros) em vez dos objetos")
+
+	sliceTable = flag.String("slice", imaging.DefaultSliceTable, "tabela da segmentação de intensidade, limite:nível separados por vírgula; o último limite é 255")
+
+	nlmH      = flag.Float64("nlm-h", imaging.DefaultNLMeansOptions.H, "decaimento dos pesos de -ops nlmeans, perto do desvio do ruído: maior borra mais")
+	nlmPatch  = flag.Int("nlm-patch", imaging.DefaultNLMeansOptions.Patch, "lado (ímpar) dos patches comparados em -ops nlmeans")
+	nlmSearch = flag.Int("nlm-search", imaging.DefaultNLMeansOptions.Search, "lado (ímpar) da janela de busca de -ops nlmeans")
+
+	diffusionIter       = flag.Int("diffusion-iter", 20, "iterações de -ops diffusion")
+	diffusionKappa      = flag.Float64("diffusion-kappa", 20, "diferença de brilho a partir da qual -ops diffusion para de alisar (preserva a borda)")
+	diffusionLambda     = flag.Float64("diffusion-lambda", 0.25, "passo de cada iteração de -ops diffusion, até 0.25")
+	diffusionConduction = flag.String("diffusion-conduction", "exponential", "função de condução de -ops diffusion: exponential ou quadratic")
+
+	restoreSize     = flag.Int("restore-size", 3, "lado da janela de -ops alphatrim e contraharm")
+	trimD           = flag.Int("trim-d", 2, "valores descartados em cada ponta da janela por -ops alphatrim (2d < tamanho²)")
+	contraharmonicQ = flag.Float64("q", 1.5, "ordem de -ops contraharm: positiva tira a pimenta (pixels pretos), negativa o sal (brancos)")
+
+	kuwaharaSize = flag.Int("kuwahara-size", 5, "lado da janela de -ops kuwahara")
+
+	noiseType    = flag.String("noise-type", "gaussian", "ruído de -ops noise: gaussian, sp (sal e pimenta), speckle ou poisson")
+	noiseSigma   = flag.Float64("noise-sigma", 20, "desvio do ruído gaussiano de -ops noise")
+	noiseDensity = flag.Float64("density", 0.05, "fração dos pixels trocados por sal ou pimenta em -ops noise -noise-type sp")
+	noiseSeed    = flag.Int64("noise-seed", 1, "semente do ruído de -ops noise")
+	speckleSigma = flag.Float64("speckle-sigma", 0.2, "desvio relativo do ruído multiplicativo de -ops noise -noise-type speckle")
+
+	psfPath          = flag.String("psf", "", "kernel do borrão (texto ou JSON, como -kernel) que -ops wiener e inverse desfazem")
+	psfSigma         = flag.Float64("psf-sigma", 2, "sem -psf, σ do borrão gaussiano que -ops wiener e inverse desfazem")
+	wienerK          = flag.Float64("wiener-k", 0.001, "razão ruído/sinal de -ops wiener (0 = filtro inverso puro)")
+	inverseThreshold = flag.Float64("inverse-threshold", 0.05, "em -ops inverse, frequências em que o borrão fica abaixo disto são zeradas")
+
+	freqKind     = flag.String("kind", "butterworth", "filtro de -ops freqfilter: ideal, butterworth ou gaussian")
+	freqCutoff   = flag.Float64("cutoff", 30, "raio de corte de -ops freqfilter, em pixels do espectro (como em spectrum.png)")
+	freqOrder    = flag.Int("order", 2, "ordem do Butterworth de -ops freqfilter")
+	freqHighpass = flag.Bool("highpass", false, "faz de -ops freqfilter um passa-altas")
+
+	notchCenters   = flag.String("notch", "", "frequências que -ops notch zera, \"u1,v1 u2,v2 ...\" a partir do centro de spectrum.png")
+	notchAuto      = flag.Bool("auto", false, "em -ops notch, acha sozinho os picos do espectro em vez de usar -notch")
+	notchCount     = flag.Int("n", 4, "quantos picos -ops notch -auto zera, no máximo")
+	notchThreshold = flag.Float64("notch-threshold", 10, "em -ops notch -auto, quantas vezes o pico deve passar a média do seu anel no espectro")
+	notchMinRadius = flag.Float64("notch-min-radius", 8, "em -ops notch -auto, distância do centro do espectro abaixo da qual não se procuram picos")
+	notchRadius    = flag.Float64("notch-radius", 3, "raio de cada notch de -ops notch, em pixels do espectro")
+
+	sharpenSigma  = flag.Float64("sharpen-sigma", 1, "σ da gaussiana de -ops sharpen")
+	sharpenAmount = flag.Float64("sharpen-amount", 1, "quantidade do realce de -ops sharpen e lapsharpen")
+
+	stretchClip = flag.String("clip", "1,99", "percentis baixo e alto, em %, que -ops stretch leva a 0 e 255")
+
+	claheTiles = flag.Int("clahe-tiles", 8, "blocos por lado da grade de -ops clahe")
+	claheClip  = flag.Float64("clahe-clip", 2, "limite de contraste de -ops clahe, em vezes a altura média do histograma de um bloco (0 = sem corte)")
+
+	gamma = flag.Float64("gamma", 0, "correção gama com este valor (gamma.png): acima de 1 clareia os tons médios, abaixo escurece; 0 desliga")
+
+	blurSigma  = flag.Float64("blur", 0, "borrão gaussiano com este σ (blurred.png)")
+	blurMethod = flag.String("blur-method", "auto", "exact, fast (três caixas) ou auto (fast acima de σ 5)")
+
+	gaussianSigma = flag.Float64("sigma", 1.4, "σ do borrão de -ops gaussian")
+
+	adaptiveWindow = flag.Int("window", 31, "janela do limiar de -ops adaptive, sauvola e niblack")
+	adaptiveC      = flag.Float64("c", 10, "quanto abaixo da média local um pixel precisa estar para ser primeiro plano em -ops adaptive")
+	adaptiveMethod = flag.String("adaptive-method", "mean", "média local de -ops adaptive: mean ou gaussian")
+	sauvolaK       = flag.Float64("sauvola-k", 0.2, "parâmetro k de -ops sauvola")
+	niblackK       = flag.Float64("niblack-k", -0.2, "parâmetro k de -ops niblack (negativo para texto escuro)")
 
-	var histogram [256]int
-	totalPixels := img.Bounds().Dx() * img.Bounds().Dy()
+	watermarkText = flag.String("watermark", "", "grava este texto como marca d'água invisível (LSB) nas saídas em tons de cinza")
+	watermarkSeed = flag.Int64("watermark-seed", defaultWatermarkSeed, "semente da ordem dos pixels da marca d'água")
 
-	for y := 0; y < img.Bounds().Dy(); y++ {
-		for x := 0; x < img.Bounds().Dx(); x++ {
-			grayValue := img.GrayAt(x, y).Y
-			histogram[grayValue]++
+	workers = flag.Int("workers", 0, "máximo de goroutines na convolução, nos filtros box e na morfologia (0 = número de CPUs)")
+
+	grayMode = flag.String("gray", "bt601", "conversão de imagens coloridas para cinza: bt601, bt709 ou average")
+
+	maxMegapixels = flag.Float64("max-megapixels", 0, "reduz (média de área) imagens maiores que isto antes de processar; 0 desliga")
+
+	resizeTo     = flag.String("resize", "", "redimensiona a entrada para LxA antes de processar (0 numa dimensão mantém a proporção, ex: 800x0)")
+	resizeInterp = flag.String("interp", "bilinear", "interpolação de -resize, -rotate e -perspective: nearest, bilinear ou bicubic")
+	rotateAngle  = flag.Float64("rotate", 0, "gira a entrada estes graus (horário) antes de processar, depois de -resize")
+	rotateExpand = flag.Bool("expand", false, "em -rotate, aumenta a tela para caber a imagem girada inteira em vez de cortar os cantos")
+	rotateFill   = flag.Int("rotate-fill", 0, "valor (0 a 255) das áreas que -rotate e -perspective deixam descobertas")
+	cropRect     = flag.String("crop", "", "recorta a entrada em x,y,largura,altura antes de processar, depois de -resize e -rotate")
+	flipAxis     = flag.String("flip", "", "espelha a entrada antes de processar, depois de -crop: h (horizontal) ou v (vertical)")
+	perspective  = flag.String("perspective", "", "endireita o quadrilátero \"x1,y1 x2,y2 x3,y3 x4,y4\" (cantos em sentido horário a partir do superior esquerdo) esticando-o sobre a imagem, depois de -flip")
+
+	dpi           = flag.Float64("dpi", 0, "resolução física em DPI, sobrepõe o pHYs da entrada")
+	bitDepth      = flag.Int("bitdepth", 8, "bits por pixel dos PNGs: 8 ou 1 (só para máscaras binárias)")
+	rleMasks      = flag.Bool("rle", false, "grava as máscaras binárias como .rle.json (RLE do COCO)")
+	rleForeground = flag.String("rle-fg", "white", "primeiro plano das máscaras RLE: white ou black")
+	noExifRotate  = flag.Bool("no-exif-rotate", false, "não aplica a orientação EXIF ao carregar JPEGs")
+
+	outFormat      = flag.String("out-format", "png", "formato das imagens geradas: png, jpg, jpeg, gif, bmp ou pgm (-format é o dos códigos de cadeia)")
+	jpegQuality    = flag.Int("quality", defaultEncodeOptions.Quality, "qualidade das saídas JPEG, de 1 a 100")
+	pngCompression = flag.String("png-compression", "default", "compressão das saídas PNG: default, none, fast ou best")
+)
+
+func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := subcommands[os.Args[1]]; ok {
+			os.Exit(cmd(os.Args[2:]))
 		}
 	}
 
-	targetPixels := int(float64(totalPixels) * bgPercentage)
-	sum := 0
-	bgThreshold := 0
+	flag.Usage = usage
+	flag.Parse()
 
-	for i := 0; i < 256; i++ {
-		sum += histogram[i]
-		if sum >= targetPixels {
-			bgThreshold = i
-			break
+	if *ops == "list" {
+		printOps()
+		return
+	}
+
+	path, err := inputPath(flag.Args())
+	if err != nil {
+		if errors.Is(err, errUsage) {
+			flag.Usage()
+		} else {
+			fmt.Fprintln(os.Stderr, err)
 		}
+		os.Exit(exitUsage)
 	}
 
-	inverted := image.NewGray(img.Bounds())
+	if *httpProf != "" {
+		serveProfiling(*httpProf)
+	}
 
-	for y := 0; y < img.Bounds().Dy(); y++ {
-		for x := 0; x < img.Bounds().Dx(); x++ {
-			if img.GrayAt(x, y).Y >= uint8(bgThreshold) {
-				inverted.SetGray(x, y, color.Gray{0})
-			} else {
-				inverted.SetGray(x, y, color.Gray{255})
-			}
+	if err := run(path); err != nil {
+		log.Print(err)
+		if errors.Is(err, errDecode) {
+			os.Exit(exitDecode)
 		}
+		os.Exit(exitFailure)
 	}
-
-	return inverted
 }
 
-// questao 3
-func countObjects(img *image.Gray) int {
-	smoothImg := image.NewGray(img.Bounds())
-	for x := 1; x < img.Bounds().Dx()-1; x++ {
-		for y := 1; y < img.Bounds().Dy()-1; y++ {
-			var sum int
-			count := 0
-			for i := -1; i <= 1; i++ {
-				for j := -1; j <= 1; j++ {
-					sum += int(img.GrayAt(x+i, y+j).Y)
-					count++
-				}
-			}
-			smoothImg.SetGray(x, y, color.Gray{uint8(sum / count)})
-		}
-	}
-
-	kernel := [][]int{
-		{1, 1, 1, 1, 1, 1, 1},
-		{1, 1, 1, 1, 1, 1, 1},
-		{1, 1, 1, 1, 1, 1, 1},
-		{1, 1, 1, 1, 1, 1, 1},
-		{1, 1, 1, 1, 1, 1, 1},
-		{1, 1, 1, 1, 1, 1, 1},
-		{1, 1, 1, 1, 1, 1, 1},
-	}
-
-	erode := func(src *image.Gray) *image.Gray {
-		result := image.NewGray(src.Bounds())
-		offset := len(kernel) / 2
-		for x := offset; x < src.Bounds().Dx()-offset; x++ {
-			for y := offset; y < src.Bounds().Dy()-offset; y++ {
-				fits := true
-				for i := -offset; i <= offset && fits; i++ {
-					for j := -offset; j <= offset && fits; j++ {
-						if kernel[i+offset][j+offset] == 1 && src.GrayAt(x+i, y+j).Y != 0 {
-							fits = false
-						}
-					}
-				}
-				if fits {
-					result.SetGray(x, y, color.Gray{0})
-				} else {
-					result.SetGray(x, y, color.Gray{255})
-				}
-			}
+func run(path string) error {
+	fmt.Println("Bem vindo ao Gotoshop!")
+	if _, err := imaging.ParseGrayWeights(*grayMode); err != nil {
+		return err
+	}
+	imaging.SetWorkers(*workers)
+	// flags inválidas falham antes de decodificar a imagem
+	var selected []operation
+	if *ops != "" {
+		var err error
+		if selected, err = parseOps(*ops); err != nil {
+			return err
 		}
-		return result
-	}
-
-	dilate := func(src *image.Gray) *image.Gray {
-		result := image.NewGray(src.Bounds())
-		offset := len(kernel) / 2
-		for x := offset; x < src.Bounds().Dx()-offset; x++ {
-			for y := offset; y < src.Bounds().Dy()-offset; y++ {
-				hasBlack := false
-				for i := -offset; i <= offset && !hasBlack; i++ {
-					for j := -offset; j <= offset && !hasBlack; j++ {
-						if kernel[i+offset][j+offset] == 1 && src.GrayAt(x+i, y+j).Y == 0 {
-							hasBlack = true
-						}
-					}
-				}
-				if hasBlack {
-					result.SetGray(x, y, color.Gray{0})
-				} else {
-					result.SetGray(x, y, color.Gray{255})
-				}
-			}
+	}
+	if _, err := parseBoxSizes(*boxSizes); err != nil {
+		return err
+	}
+	if _, err := imaging.ParseStructuringElement(*morphSE); err != nil {
+		return err
+	}
+	if _, err := imaging.ParseGradientOperator(*gradOperator); err != nil {
+		return err
+	}
+	if _, _, err := imaging.ParseSliceTable(*sliceTable); err != nil {
+		return err
+	}
+	var resizeW, resizeH int
+	if *resizeTo != "" {
+		var err error
+		if resizeW, resizeH, err = parseResize(*resizeTo); err != nil {
+			return err
 		}
-		return result
 	}
-
-	temp := erode(smoothImg)
-	eroded := erode(temp)
-	temp = dilate(eroded)
-	temp = dilate(temp)
-	opened := dilate(temp)
-
-	temp = dilate(opened)
-	temp = dilate(temp)
-	dilated := dilate(temp)
-	temp = erode(dilated)
-	temp = erode(temp)
-	closed := erode(temp)
-
-	width, height := closed.Bounds().Dx(), closed.Bounds().Dy()
-	visited := make([][]bool, height)
-	for i := range visited {
-		visited[i] = make([]bool, width)
+	method, err := parseInterpolation(*resizeInterp)
+	if err != nil {
+		return err
 	}
-
-	var directions = [][2]int{
-		{-1, 0}, {1, 0}, {0, -1}, {0, 1},
-		{-1, -1}, {-1, 1}, {1, -1}, {1, 1},
+	// -format com um formato de imagem vale como -out-format; text e json
+	// continuam sendo os dos códigos de cadeia
+	if _, err := imageFormat("." + *chainFormat); err == nil {
+		*outFormat, *chainFormat = *chainFormat, "text"
+	}
+	if _, err := imageFormat("." + *outFormat); err != nil {
+		return fmt.Errorf("-out-format deve ser png, jpg, jpeg, gif, bmp ou pgm, não %q", *outFormat)
+	}
+	if *jpegQuality < 1 || *jpegQuality > 100 {
+		return fmt.Errorf("-quality deve estar entre 1 e 100, não %d", *jpegQuality)
+	}
+	if _, err := parsePNGCompression(*pngCompression); err != nil {
+		return err
+	}
+	if *rotateFill < 0 || *rotateFill > 255 {
+		return fmt.Errorf("-rotate-fill deve estar entre 0 e 255, não %d", *rotateFill)
+	}
+	var cropTo image.Rectangle
+	if *cropRect != "" {
+		var err error
+		if cropTo, err = parseCrop(*cropRect); err != nil {
+			return err
+		}
+	}
+	if *flipAxis != "" && *flipAxis != "h" && *flipAxis != "v" {
+		return fmt.Errorf("-flip deve ser h ou v, não %q", *flipAxis)
+	}
+	var quad [4]image.Point
+	if *perspective != "" {
+		var err error
+		if quad, err = parseQuad(*perspective); err != nil {
+			return err
+		}
+	}
+	if *chainFormat != "text" && *chainFormat != "json" {
+		return fmt.Errorf("-format deve ser text ou json, não %q", *chainFormat)
+	}
+	if *connectivity != 4 && *connectivity != 8 {
+		return fmt.Errorf("-connectivity deve ser 4 ou 8, não %d", *connectivity)
+	}
+	if *watershedBg < 0 || *watershedBg > 1 {
+		return fmt.Errorf("-bg deve estar entre 0 e 1, não %g", *watershedBg)
+	}
+	if *outDir != "" {
+		if err := os.MkdirAll(*outDir, 0o755); err != nil {
+			return fmt.Errorf("erro ao criar %s: %w", *outDir, err)
+		}
 	}
 
-	const minArea = 10
-	var count int
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			if visited[y][x] || closed.GrayAt(x, y).Y == 255 {
-				continue
-			}
-
-			area := 0
-			stack := [][2]int{{x, y}}
+	src, meta, err := decodeImage(path)
+	if err != nil {
+		return err
+	}
+	if *dpi > 0 {
+		meta.ppmX, meta.ppmY = dpiToPPM(*dpi), dpiToPPM(*dpi)
+	}
+	if *resizeTo != "" {
+		w, h := src.Bounds().Dx(), src.Bounds().Dy()
+		src = resize(src, resizeW, resizeH, method)
+		fmt.Printf("Imagem redimensionada de %dx%d para %dx%d (%s)\n", w, h, src.Bounds().Dx(), src.Bounds().Dy(), *resizeInterp)
+		// a resolução física acompanha: mais pixels no mesmo papel
+		if w > 0 && h > 0 {
+			meta.ppmX = uint32(math.Round(float64(meta.ppmX) * float64(src.Bounds().Dx()) / float64(w)))
+			meta.ppmY = uint32(math.Round(float64(meta.ppmY) * float64(src.Bounds().Dy()) / float64(h)))
+		}
+	}
+	if *rotateAngle != 0 {
+		src = rotateImage(src, *rotateAngle, method, *rotateExpand, uint8(*rotateFill))
+		fmt.Printf("Imagem girada %g° (%dx%d)\n", *rotateAngle, src.Bounds().Dx(), src.Bounds().Dy())
+	}
+	if *cropRect != "" {
+		if src, err = cropImage(src, cropTo.Add(src.Bounds().Min)); err != nil {
+			return err
+		}
+		fmt.Printf("Imagem recortada em %v\n", cropTo)
+	}
+	if *flipAxis != "" {
+		src = flipImage(src, *flipAxis == "h")
+	}
+	if *perspective != "" {
+		if src, err = rectifyQuad(src, quad, method, uint8(*rotateFill)); err != nil {
+			return err
+		}
+	}
+	src, factor := fitMegapixels(src, *maxMegapixels)
+	if factor > 1 {
+		fmt.Fprintf(os.Stderr, "Aviso: imagem acima de %g megapixels, reduzida por um fator de %.3f (%dx%d); "+
+			"as coordenadas dos relatórios JSON são convertidas de volta, o código de cadeia e as imagens ficam na escala reduzida\n",
+			*maxMegapixels, factor, src.Bounds().Dx(), src.Bounds().Dy())
+		meta.ppmX = uint32(math.Round(float64(meta.ppmX) / factor))
+		meta.ppmY = uint32(math.Round(float64(meta.ppmY) / factor))
+	}
+	img := grayscale(src)
+	if meta.hasPhysical() {
+		umX, umY := meta.micronsPerPixel()
+		fmt.Printf("Resolução física: %.2f x %.2f µm/pixel\n", umX, umY)
+	}
 
-			for len(stack) > 0 {
-				px, py := stack[len(stack)-1][0], stack[len(stack)-1][1]
-				stack = stack[:len(stack)-1]
+	roi, err := loadROI(img, factor)
+	if err != nil {
+		return err
+	}
 
-				if visited[py][px] {
-					continue
-				}
+	var rec *stageRecorder
+	if *debugStages != "" {
+		if rec, err = newStageRecorder(*debugStages); err != nil {
+			return err
+		}
+	}
 
-				visited[py][px] = true
-				area++
+	// o perfil cobre só o processamento, sem decodificar/codificar
+	stop, err := startProfiling(*cpuProfile, *memProfile)
+	if err != nil {
+		return err
+	}
+	defer stop()
 
-				for _, d := range directions {
-					nx, ny := px+d[0], py+d[1]
-					if nx >= 0 && ny >= 0 && nx < width && ny < height {
-						if !visited[ny][nx] && closed.GrayAt(nx, ny).Y == 0 {
-							stack = append(stack, [2]int{nx, ny})
-						}
-					}
-				}
+	if selected != nil {
+		border, err := imaging.ParseBorderMode(*borderFlag)
+		if err != nil {
+			return err
+		}
+		in := opInput{img: img, roi: roi, rec: rec, border: border}
+		var outputs []output
+		for _, op := range selected {
+			outs, err := op.run(in)
+			if err != nil {
+				return err
 			}
-
-			if area >= minArea {
-				count++
+			outputs = append(outputs, outs...)
+		}
+		if err := stop(); err != nil {
+			return err
+		}
+		if roi != nil {
+			restrictToROI(outputs, src, roi, *roiFill)
+		}
+		if err := rec.writeReport(); err != nil {
+			return err
+		}
+		for _, out := range outputs {
+			out.name = imagePath(rlePath(out.name, out.img))
+			if err := saveImageMeta(out.name, out.img, meta); err != nil {
+				return err
 			}
+			fmt.Println("-", out.name)
 		}
+		return nil
 	}
 
-	return count
-}
+	outputs, chains, err := process(src, img, roi, rec, meta, factor)
+	if err != nil {
+		return err
+	}
+	if roi != nil {
+		restrictToROI(outputs, src, roi, *roiFill)
+	}
+	if err := stop(); err != nil {
+		return err
+	}
+	if err := rec.writeReport(); err != nil {
+		return err
+	}
 
-// QUESTAO CADEIA DE FREEMAN
-func freemanChainCode(img *image.Gray) string {
-	width, height := img.Bounds().Dx(), img.Bounds().Dy()
-	visited := make([][]bool, height)
-	for i := range visited {
-		visited[i] = make([]bool, width)
-	}
-
-	directions := [][2]int{
-		{1, 0},   // 0: Direita
-		{1, -1},  // 1: Diagonal superior direita
-		{0, -1},  // 2: Cima
-		{-1, -1}, // 3: Diagonal superior esquerda
-		{-1, 0},  // 4: Esquerda
-		{-1, 1},  // 5: Diagonal inferior esquerda
-		{0, 1},   // 6: Baixo
-		{1, 1},   // 7: Diagonal inferior direita
-	}
-
-	var startX, startY int
-	found := false
-	for y := 0; y < height && !found; y++ {
-		for x := 0; x < width && !found; x++ {
-			if img.GrayAt(x, y).Y == 0 { // Pixel preto
-				startX, startY = x, y
-				found = true
-			}
+	for i, out := range outputs {
+		outputs[i].name = imagePath(rlePath(out.name, out.img))
+		if err := saveImageMeta(outputs[i].name, out.img, meta); err != nil {
+			return err
 		}
 	}
 
-	if !found {
-		return "Nenhum objeto encontrado"
+	fmt.Println("Processamento concluído! Imagens geradas:")
+	for _, out := range outputs {
+		fmt.Println("-", out.name)
 	}
 
-	var chain []int
-	currentX, currentY := startX, startY
-	visited[currentY][currentX] = true
+	chainPath, err := writeChains(chains, *chainFormat)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Códigos de cadeia de %d objetos salvos em %s\n", len(chains), chainPath)
+	return nil
+}
 
-	prevDir := 0
+// outPath põe o arquivo de saída no diretório de -out.
+func outPath(name string) string {
+	return filepath.Join(*outDir, name)
+}
 
-	for {
-		nextDir := -1
-		nextX, nextY := 0, 0
+// imagePath é outPath para as imagens geradas, trocando o .png do nome
+// pela extensão de -out-format (menos nas máscaras .rle.json).
+func imagePath(name string) string {
+	if !isRLEPath(name) {
+		name = strings.TrimSuffix(name, ".png") + "." + *outFormat
+	}
+	return outPath(name)
+}
 
-		for i := 0; i < 8; i++ {
-			dir := (prevDir + i) % 8 // Explorar em ordem a partir da direção anterior
-			nx := currentX + directions[dir][0]
-			ny := currentY + directions[dir][1]
+// process roda as etapas sobre a imagem em tons de cinza e devolve as
+// saídas e os códigos de cadeia de cada objeto; src é a imagem original,
+// usada pelos efeitos coloridos. roi (pode ser nil) restringe as
+// estatísticas de Otsu e a contagem de objetos aos pixels da região de
+// interesse, e rec (pode ser nil) recebe as etapas intermediárias de
+// -debug-stages. factor é a redução aplicada por -max-megapixels; os
+// relatórios são convertidos de volta às coordenadas originais. meta traz
+// a resolução física da imagem processada, para as medidas em unidades
+// físicas.
+func process(src image.Image, img *image.Gray, roi *image.Gray, rec *stageRecorder, meta imageMeta, factor float64) ([]output, []imaging.ChainCode, error) {
+	var outputs []output
+
+	border, err := imaging.ParseBorderMode(*borderFlag)
+	if err != nil {
+		return nil, nil, err
+	}
 
-			if nx >= 0 && nx < width && ny >= 0 && ny < height && !visited[ny][nx] && img.GrayAt(nx, ny).Y == 0 {
-				nextDir = dir
-				nextX, nextY = nx, ny
-				break
-			}
+	in := opInput{img: img, roi: roi, rec: rec, border: border}
+	for _, op := range []func(opInput) ([]output, error){opCanny, opGradient} {
+		outs, err := op(in)
+		if err != nil {
+			return nil, nil, err
 		}
+		outputs = append(outputs, outs...)
+	}
 
-		if nextDir == -1 {
-			break
+	if *directionViz {
+		fmt.Println("Calculando direções do gradiente...")
+		outputs = append(outputs, output{"direction.png", directionMap(img, *directionBins, *directionThreshold)})
+	}
+
+	if *structureTensorOut {
+		fmt.Println("Calculando o tensor de estrutura...")
+		orientation, coherence := structureTensor(img, *structureSigma)
+		var mask *image.Gray
+		if *structureMask != "" {
+			if mask, err = loadImage(*structureMask); err != nil {
+				return nil, nil, err
+			}
 		}
+		fmt.Printf("Coerência média: %.4f\n", meanCoherence(coherence, mask))
+		outputs = append(outputs,
+			output{"coherence.png", imaging.FloatToGray(coherence, 1)},
+			output{"orientation.png", imaging.FloatToGray(orientation, math.Pi)},
+			output{"orientation_hsv.png", orientationHSV(orientation, coherence)},
+		)
+	}
 
-		chain = append(chain, nextDir)
-		visited[nextY][nextX] = true
-		currentX, currentY = nextX, nextY
-		prevDir = (nextDir + 4) % 8 // Direção oposta para manter continuidade
+	if *frangi {
+		fmt.Println("Aplicando Frangi...")
+		scales, err := parseFloatList(*frangiScales)
+		if err != nil {
+			return nil, nil, err
+		}
+		vesselness := frangiVesselness(img, scales, *frangiBeta, *frangiC, !*frangiBright)
+		outputs = append(outputs, output{"frangi.png", imaging.NormalizeToGray(vesselness)})
 	}
 
-	chainStr := ""
-	for _, dir := range chain {
-		chainStr += fmt.Sprintf("%d", dir)
+	if *objectsCSV {
+		fmt.Println("Medindo os objetos...")
+		mask, err := imaging.OtsuMask(img, roi)
+		if err != nil {
+			return nil, nil, err
+		}
+		labels, _, err := imaging.LabelComponents(mask, *connectivity, imaging.BlackObjects)
+		if err != nil {
+			return nil, nil, err
+		}
+		regions := scaleRegions(imaging.RegionProps(labels), factor)
+		printRegionsSummary(regions)
+		// as regiões voltaram à escala original, onde o pixel é menor
+		umX, umY := meta.micronsPerPixel()
+		if err := writeRegionsCSV(outPath("objects.csv"), regions, umX/factor, umY/factor); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	return chainStr
-}
+	if *blobs {
+		fmt.Println("Detectando blobs...")
+		found := detectBlobs(img, blobSigmas(*blobMinSigma, *blobMaxSigma, *blobSteps), *blobThreshold, *blobBright)
+		fmt.Printf("Blobs encontrados: %d\n", len(found))
+		if err := writeJSON(outPath("blobs.json"), scaleBlobs(found, factor)); err != nil {
+			return nil, nil, err
+		}
+		outputs = append(outputs, output{"blobs.png", blobOverlay(img, found)})
+	}
 
-// QUESTAO FILTRO BOX
-func applyBoxFilter(img image.Image, size int) image.Image {
-	bounds := img.Bounds()
-	width := bounds.Dx()
-	height := bounds.Dy()
-	filteredImg := image.NewGray(bounds)
-
-	average := func(x, y, size int) uint8 {
-		var sum int
-		var count int
-		halfSize := size / 2
-		for i := -halfSize; i <= halfSize; i++ {
-			for j := -halfSize; j <= halfSize; j++ {
-				nx, ny := x+i, y+j
-				if nx >= 0 && nx < width && ny >= 0 && ny < height {
-					r, _, _, _ := img.At(nx, ny).RGBA()
-					// Convertendo para escala de cinza (simples média)
-					gray := uint8((r + r + r) / 3)
-					sum += int(gray)
-					count++
-				}
-			}
+	if *mser {
+		fmt.Println("Detectando regiões MSER...")
+		regions := detectMSER(img, MSEROptions{
+			Delta:        *mserDelta,
+			MinArea:      *mserMinArea,
+			MaxArea:      *mserMaxArea,
+			MaxVariation: *mserMaxVariation,
+			Bright:       *mserBright,
+			KeepBorder:   *mserKeepBorder,
+		})
+		fmt.Printf("Regiões estáveis: %d\n", len(regions))
+		if err := writeJSON(outPath("mser.json"), scaleMSER(regions, factor)); err != nil {
+			return nil, nil, err
 		}
-		return uint8(sum / count)
+		outputs = append(outputs, output{"mser.png", mserOverlay(img, regions, *mserBright)})
 	}
 
-	for x := 0; x < width; x++ {
-		for y := 0; y < height; y++ {
-			avg := average(x, y, size)
-			filteredImg.Set(x, y, color.Gray{Y: avg})
+	if *guided {
+		fmt.Println("Aplicando o filtro guiado...")
+		outputs = append(outputs, output{"guided.png", guidedFilter(img, img, *guidedRadius, *guidedEps)})
+	}
+	if *guidedMask != "" {
+		fmt.Println("Refinando a máscara com o filtro guiado...")
+		mask, err := loadImage(*guidedMask)
+		if err != nil {
+			return nil, nil, err
 		}
+		outputs = append(outputs, output{"guided_mask.png", guidedFilter(mask, img, *guidedRadius, *guidedEps)})
 	}
 
-	return filteredImg
-}
+	if *cartoonOut {
+		fmt.Println("Aplicando o efeito cartoon...")
+		opts := defaultCartoonOptions
+		opts.Bands, opts.EdgeStrength, opts.Iterations = *cartoonBands, *cartoonEdge, *cartoonIter
+		outputs = append(outputs, output{"cartoon.png", cartoon(src, opts)})
+	}
 
-// QUESTAO 6:
-func segmentIntensity(img *image.Gray) *image.Gray {
-	width, height := img.Bounds().Dx(), img.Bounds().Dy()
-	segmented := image.NewGray(img.Bounds())
-
-	for x := 0; x < width; x++ {
-		for y := 0; y < height; y++ {
-			grayValue := img.GrayAt(x, y).Y
-			var newValue uint8
-
-			// Aplicar a transformação conforme a tabela
-			switch {
-			case grayValue <= 50:
-				newValue = 25
-			case grayValue <= 100:
-				newValue = 75
-			case grayValue <= 150:
-				newValue = 125
-			case grayValue <= 200:
-				newValue = 175
-			default: // 201 a 255
-				newValue = 255
-			}
+	if *sketch {
+		fmt.Println("Aplicando o efeito de lápis...")
+		outputs = append(outputs, output{"sketch.png", pencilSketch(img, *sketchSigma, *sketchTexture, *sketchSeed)})
+	}
 
-			segmented.SetGray(x, y, color.Gray{newValue})
-		}
+	if *halftoneOut {
+		fmt.Println("Aplicando a retícula...")
+		outputs = append(outputs, output{"halftone.png", halftone(img, *halftoneCell, *halftoneAngle)})
 	}
 
-	return segmented
-}
+	if *devignette {
+		fmt.Println("Corrigindo a vinheta...")
+		outputs = append(outputs, output{"devignette.png", correctVignette(img, estimateVignette(img, *vignetteOrder))})
+	}
+	if *vignette > 0 {
+		fmt.Println("Aplicando vinheta...")
+		outputs = append(outputs, output{"vignette.png", applyVignette(img, *vignette, *vignetteRadius)})
+	}
 
-func main() {
-	path := os.Args[1]
+	if *undistortOut {
+		fmt.Println("Corrigindo a distorção da lente...")
+		outputs = append(outputs, output{"undistort.png", undistort(img, *lensK1, *lensK2, *lensCX, *lensCY)})
+	}
+	if *distortOut {
+		fmt.Println("Aplicando distorção de lente...")
+		outputs = append(outputs, output{"distort.png", distort(img, *lensK1, *lensK2, *lensCX, *lensCY)})
+	}
 
-	// var options int
-	fmt.Println("Bem vindo ao Gotoshop!")
-	img := loadImage(path)
+	if *deskewOut {
+		fmt.Println("Endireitando o documento...")
+		straight, angle := deskew(img, *deskewMax)
+		fmt.Printf("Inclinação estimada: %.2f°\n", angle)
+		outputs = append(outputs, output{"deskew.png", straight})
+	}
 
-	fmt.Println("Aplicando Canny...")
-	canny := cannyEdgeDetection(img)
-	saveImage("canny.png", canny)
+	if *textLines {
+		fmt.Println("Segmentando linhas e palavras...")
+		lines := segmentText(img, *wordGap)
+		words := 0
+		for _, line := range lines {
+			words += len(line.Words)
+		}
+		fmt.Printf("Linhas: %d, palavras: %d\n", len(lines), words)
+		if err := writeJSON(outPath("textlines.json"), scaleTextLines(lines, factor)); err != nil {
+			return nil, nil, err
+		}
+		outputs = append(outputs, output{"textlines.png", textOverlay(img, lines)})
+	}
 
-	fmt.Println("Aplicando Otsu...")
-	otsu := otsuThreshold(img)
-	saveImage("otsu.png", otsu)
+	if *quadtree {
+		fmt.Println("Decompondo em quadtree...")
+		tree := quadtreeDecompose(img, *quadtreeStd, *quadtreeMin)
+		fmt.Printf("Folhas: %d\n", tree.leafCount())
+		outputs = append(outputs,
+			output{"quadtree.png", tree.reconstruct()},
+			output{"quadtree_blocks.png", tree.overlay(img)})
+	}
 
-	fmt.Println("Aplicando Marr-Hildreth...")
-	marr := marrHildreth(img)
-	saveImage("marr_hildreth.png", marr)
+	if *thumb > 0 {
+		fmt.Println("Gerando miniatura...")
+		outputs = append(outputs, output{"thumb.png", thumbnail(src, *thumb, *thumb)})
+	}
 
-	objectCount := countObjects(otsu)
-	fmt.Printf("Número de objetos na imagem: %d\n", objectCount)
+	if *hogOut {
+		fmt.Println("Extraindo HOG...")
+		if *hogCell <= 0 || *hogBlock <= 0 || *hogBins <= 0 {
+			return nil, nil, fmt.Errorf("-hog-cell, -hog-block e -hog-bins devem ser positivos")
+		}
+		descriptor := hog(img, *hogCell, *hogBlock, *hogBins)
+		fmt.Printf("Descritor HOG: %d valores\n", len(descriptor))
+		if err := writeVectorCSV(outPath("hog.csv"), descriptor); err != nil {
+			return nil, nil, err
+		}
+		outputs = append(outputs, output{"hog.png", hogVisualization(img, *hogCell, *hogBins)})
+	}
 
-	fmt.Println("Aplicando Watershed...")
+	if *kernelPath != "" {
+		fmt.Println("Aplicando o kernel...")
+		kernel, err := loadKernel(*kernelPath, *allowEven)
+		if err != nil {
+			return nil, nil, err
+		}
+		norm, err := imaging.ParseKernelNormalization(*kernelNorm)
+		if err != nil {
+			return nil, nil, err
+		}
+		anchor, err := parseAnchor(*kernelAnchorFlag, kernel)
+		if err != nil {
+			return nil, nil, err
+		}
+		mode, err := imaging.ParseResponseMode(*kernelOutput)
+		if err != nil {
+			return nil, nil, err
+		}
+		divisor := norm.DivisorFor(kernel)
+		fmt.Printf("Kernel %dx%d, divisor efetivo %g\n", len(kernel[0]), len(kernel), divisor)
+		convolved, err := imaging.ConvolveAnchor(img, kernel, anchor, norm, mode, border)
+		if err != nil {
+			return nil, nil, err
+		}
+		rec.record("kernel", "convolved", convolved, map[string]any{
+			"kernel": *kernelPath, "normalization": norm.Mode, "divisor": divisor, "output": *kernelOutput,
+		})
+		outputs = append(outputs, output{"convolved.png", convolved})
+	}
 
-	watershedImg := watershed(img, 0.7)
-	saveImage("watershed.png", watershedImg)
+	if *gamma != 0 {
+		fmt.Printf("Aplicando a correção gama %g...\n", *gamma)
+		corrected, err := imaging.GammaCorrect(img, *gamma)
+		if err != nil {
+			return nil, nil, err
+		}
+		outputs = append(outputs, output{"gamma.png", corrected})
+	}
 
-	fmt.Println("Processamento concluído! Imagens geradas:")
-	fmt.Println("- canny.png")
-	fmt.Println("- otsu.png")
-	fmt.Println("- marr_hildreth.png")
-	fmt.Println("- watershed.png")
+	if *blurSigma > 0 {
+		fmt.Println("Aplicando o borrão gaussiano...")
+		blurred, err := blurImage(img, *blurSigma, *blurMethod)
+		if err != nil {
+			return nil, nil, err
+		}
+		outputs = append(outputs, output{"blurred.png", blurred})
+	}
 
-	// Gerar o código de cadeia de Freeman
-	chainCode := freemanChainCode(otsu)
+	if *openRec > 0 {
+		fmt.Println("Aplicando a abertura por reconstrução...")
+		outputs = append(outputs, output{"opened_rec.png", openingByReconstruction(img, imaging.SquareSE(*openRec))})
+	}
 
-	file, err := os.Create("freeman_chain.txt")
-	if err != nil {
-		log.Fatalf("Erro ao criar o arquivo: %v", err)
+	if *medianSize > 0 {
+		fmt.Println("Aplicando o filtro da mediana...")
+		filtered, err := imaging.MedianFilter(img, *medianSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		outputs = append(outputs, output{"median.png", filtered})
 	}
-	defer file.Close()
 
-	_, err = file.WriteString(chainCode)
+	outs, err := opOtsu(in)
 	if err != nil {
-		log.Fatalf("Erro ao escrever no arquivo: %v", err)
+		return nil, nil, err
 	}
+	otsu := outs[0].img.(*image.Gray)
+	outputs = append(outputs, outs...)
 
-	fmt.Println("Código de cadeia salvo em freeman_chain.txt")
-
-	// Aplicar os filtros Box 2x2, 3x3, 5x5, 7x7
-	filtered2x2 := applyBoxFilter(img, 2)
-	filtered3x3 := applyBoxFilter(img, 3)
-	filtered5x5 := applyBoxFilter(img, 5)
-	filtered7x7 := applyBoxFilter(img, 7)
-
-	// Salvar as imagens filtradas
-	saveImage("filtered_2x2.png", filtered2x2)
-	saveImage("filtered_3x3.png", filtered3x3)
-	saveImage("filtered_5x5.png", filtered5x5)
-	saveImage("filtered_7x7.png", filtered7x7)
+	if outs, err = opMarrHildreth(in); err != nil {
+		return nil, nil, err
+	}
+	outputs = append(outputs, outs...)
 
-	// Indicar que o processamento foi concluído
-	fmt.Println("Processamento concluído! Imagens geradas:")
-	fmt.Println("- filtered_2x2.png")
-	fmt.Println("- filtered_3x3.png")
-	fmt.Println("- filtered_5x5.png")
-	fmt.Println("- filtered_7x7.png")
+	countOpts := imaging.CountObjectsOptions{MinArea: *minArea, Size: *countSize, Iterations: *countIter, Connectivity: *connectivity}
+	objectCount, err := imaging.CountObjectsMask(otsu, roi, countOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	fmt.Printf("Número de objetos na imagem: %d\n", objectCount)
 
-	fmt.Println("Aplicando segmentação de intensidade...")
-	segmentedImg := segmentIntensity(img)
+	if outs, err = opWatershed(in); err != nil {
+		return nil, nil, err
+	}
+	outputs = append(outputs, outs...)
+
+	// Gerar o código de cadeia de Freeman, no esqueleto com -skeleton
+	chainImg := otsu
+	if *skeleton {
+		fmt.Println("Afinando os objetos...")
+		skel := skeletonize(otsu)
+		outputs = append(outputs, output{"skeleton.png", skel})
+		chainImg = imaging.Negative(skel)
+	}
+	chains := imaging.FreemanChainCodes(chainImg)
 
-	fmt.Println("Salvando a imagem segmentada...")
-	saveImage("segmented.png", segmentedImg)
+	// Aplicar os filtros Box de -box
+	for _, op := range []func(opInput) ([]output, error){opBox, opSegment} {
+		if outs, err = op(in); err != nil {
+			return nil, nil, err
+		}
+		outputs = append(outputs, outs...)
+	}
 
+	return outputs, chains, nil
 }
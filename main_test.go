@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"processing-images/imaging"
+)
+
+// stepEdge é uma borda vertical de 0 a 255 com um pixel de transição (128)
+// na coluna 10.
+func stepEdge() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 21, 5))
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 21; x++ {
+			switch {
+			case x == 10:
+				img.Pix[y*img.Stride+x] = 128
+			case x > 10:
+				img.Pix[y*img.Stride+x] = 255
+			}
+		}
+	}
+	return img
+}
+
+func TestScaleMagnitude(t *testing.T) {
+	mag, _ := imaging.Gradient(stepEdge(), imaging.Sobel)
+	tests := []struct {
+		name      string
+		scale     magnitudeScale
+		saturated int   // colunas em 255
+		row       []int // valores esperados nas colunas 8 a 12
+	}{
+		// a magnitude passa de 255 nas três colunas da borda
+		{"clamp", magnitudeScale{"clamp", 0}, 3, []int{0, 255, 255, 255, 0}},
+		// a resposta é graduada e o máximo fica só no meio
+		{"normalize", magnitudeScale{"normalize", 0}, 1, []int{0, 128, 255, 127, 0}},
+		{"divide 4", magnitudeScale{"divide", 4}, 1, []int{0, 128, 255, 127, 0}},
+		{"divide pelo máximo teórico", magnitudeScale{"divide", imaging.SobelMaxMagnitude / 255}, 0, []int{0, 90, 180, 89, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := scaleMagnitude(mag, tt.scale)
+			saturated := 0
+			for x := 0; x < 21; x++ {
+				if img.GrayAt(x, 2).Y == 255 {
+					saturated++
+				}
+			}
+			if saturated != tt.saturated {
+				t.Errorf("%d colunas saturadas, esperado %d", saturated, tt.saturated)
+			}
+			for i, want := range tt.row {
+				if v := int(img.GrayAt(8+i, 2).Y); v < want-1 || v > want+1 {
+					t.Errorf("coluna %d = %d, esperado %d", 8+i, v, want)
+				}
+			}
+		})
+	}
+}
+
+func TestScaleMagnitudeFlat(t *testing.T) {
+	// sem gradiente, normalize não divide por zero
+	mag := [][]float64{{0, 0}, {0, 0}}
+	img := scaleMagnitude(mag, magnitudeScale{"normalize", 0})
+	for _, v := range img.Pix {
+		if v != 0 {
+			t.Fatalf("imagem lisa deu %d", v)
+		}
+	}
+}
+
+func TestLoadImageRedPNG(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vermelho.png")
+	if err := saveImage(path, solidRGBA(4, 4, color.RGBA{255, 0, 0, 255})); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		mode string
+		want uint8
+	}{
+		{"bt601", 76},
+		{"bt709", 54},
+		{"average", 85},
+	}
+	old := *grayMode
+	t.Cleanup(func() { *grayMode = old })
+	for _, tt := range tests {
+		*grayMode = tt.mode
+		img, err := loadImage(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, v := range img.Pix {
+			if v != tt.want {
+				t.Fatalf("%s: pixel %d = %d, esperado %d", tt.mode, i, v, tt.want)
+			}
+		}
+	}
+}
+
+func TestLoadImageErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := saveImage(filepath.Join(dir, "ok.png"), uniformGray(16, 50)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "ok.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{
+		"vazio.png":      {},
+		"texto.png":      []byte("não sou uma imagem"),
+		"truncado.png":   data[:len(data)/2],
+		"corrompido.png": append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0xAB}, 64)...),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		notExist bool
+	}{
+		{"não existe", "nada.png", true},
+		{"vazio", "vazio.png", false},
+		{"texto", "texto.png", false},
+		{"truncado", "truncado.png", false},
+		{"corrompido", "corrompido.png", false},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.file)
+		_, err := loadImage(path)
+		if err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+			continue
+		}
+		if errors.Is(err, os.ErrNotExist) != tt.notExist {
+			t.Errorf("%s: errors.Is(ErrNotExist) = %v: %v", tt.name, !tt.notExist, err)
+		}
+		if !tt.notExist && !errors.Is(err, errDecode) {
+			t.Errorf("%s: esperado errDecode: %v", tt.name, err)
+		}
+		if !strings.Contains(err.Error(), tt.file) {
+			t.Errorf("%s: a mensagem não cita o arquivo: %v", tt.name, err)
+		}
+	}
+}
+
+func TestSaveImageErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"diretório que não existe", filepath.Join(dir, "nada", "saida.png")},
+		{"extensão desconhecida", filepath.Join(dir, "saida.xyz")},
+		{"sem extensão", filepath.Join(dir, "saida")},
+	}
+	for _, tt := range tests {
+		if err := saveImage(tt.path, uniformGray(4, 0)); err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+		}
+	}
+	if err := saveImage(filepath.Join(dir, "nada", "saida.png"), uniformGray(4, 0)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("diretório que não existe: errors.Is(ErrNotExist) falso: %v", err)
+	}
+}
+
+func TestSaveImageFormatsRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	gray := gradientImage(37, 23) // largura ímpar: linhas do BMP com enchimento
+	rgba := image.NewRGBA(image.Rect(0, 0, 13, 9))
+	for i := range rgba.Pix {
+		rgba.Pix[i] = uint8(i * 37)
+		if i%4 == 3 {
+			rgba.Pix[i] = 255
+		}
+	}
+	tests := []struct {
+		file    string
+		img     image.Image
+		format  string
+		maxDiff int // diferença máxima por canal depois de decodificar
+	}{
+		{"cinza.png", gray, "png", 0},
+		{"cinza.jpg", gray, "jpeg", 3},
+		{"cinza.jpeg", gray, "jpeg", 3},
+		{"cinza.gif", gray, "gif", 0},
+		{"cinza.bmp", gray, "bmp", 0},
+		{"maiúscula.PNG", gray, "png", 0},
+		{"cor.png", rgba, "png", 0},
+		{"cor.bmp", rgba, "bmp", 0},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.file)
+		if err := saveImageOptions(path, tt.img, imageMeta{}, defaultEncodeOptions); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, format, err := image.DecodeConfig(bytes.NewReader(data)); err != nil || format != tt.format {
+			t.Errorf("%s: gravado como %q (%v), esperado %s", tt.file, format, err, tt.format)
+		}
+		got, _, err := decodeImage(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Bounds().Size() != tt.img.Bounds().Size() {
+			t.Fatalf("%s: tamanho %v, esperado %v", tt.file, got.Bounds().Size(), tt.img.Bounds().Size())
+		}
+		if d := maxChannelDiff(got, tt.img); d > tt.maxDiff {
+			t.Errorf("%s: diferença %d depois de decodificar, máximo %d", tt.file, d, tt.maxDiff)
+		}
+	}
+}
+
+// maxChannelDiff é a maior diferença, em 8 bits, entre canais de a e b
+func maxChannelDiff(a, b image.Image) int {
+	worst := 0
+	for y := 0; y < a.Bounds().Dy(); y++ {
+		for x := 0; x < a.Bounds().Dx(); x++ {
+			c1 := color.RGBAModel.Convert(a.At(a.Bounds().Min.X+x, a.Bounds().Min.Y+y)).(color.RGBA)
+			c2 := color.RGBAModel.Convert(b.At(b.Bounds().Min.X+x, b.Bounds().Min.Y+y)).(color.RGBA)
+			for _, d := range []int{int(c1.R) - int(c2.R), int(c1.G) - int(c2.G), int(c1.B) - int(c2.B)} {
+				worst = max(worst, d, -d)
+			}
+		}
+	}
+	return worst
+}
+
+func TestSaveImageEncodeOptions(t *testing.T) {
+	dir := t.TempDir()
+	var img *image.Gray
+	size := func(name string, opts encodeOptions) (int, image.Image) {
+		path := filepath.Join(dir, name)
+		if err := saveImageOptions(path, img, imageMeta{}, opts); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, _, err := decodeImage(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return int(info.Size()), decoded
+	}
+
+	// a qualidade do JPEG troca tamanho por fidelidade
+	img = randomImage(64, 64, 3)
+	low, lowImg := size("baixa.jpg", encodeOptions{Quality: 20})
+	high, highImg := size("alta.jpg", encodeOptions{Quality: 95})
+	if low >= high {
+		t.Errorf("JPEG com qualidade 20 tem %d bytes, com 95 tem %d", low, high)
+	}
+	if imageMSE(grayscale(lowImg), img) <= imageMSE(grayscale(highImg), img) {
+		t.Error("qualidade 95 não ficou mais fiel que 20")
+	}
+
+	// a compressão do PNG muda só o tamanho; ruído não comprime
+	img = gradientImage(64, 64)
+	none, noneImg := size("sem.png", encodeOptions{Compression: png.NoCompression})
+	best, bestImg := size("melhor.png", encodeOptions{Compression: png.BestCompression})
+	if best >= none {
+		t.Errorf("PNG com best tem %d bytes, sem compressão %d", best, none)
+	}
+	for _, decoded := range []image.Image{noneImg, bestImg} {
+		if !sameGray(grayscale(decoded), img) {
+			t.Error("o PNG comprimido mudou os pixels")
+		}
+	}
+}
+
+func TestImageFormat(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"a.png", "png", true},
+		{"dir.x/a.JPG", "jpeg", true},
+		{"a.jpeg", "jpeg", true},
+		{"a.gif", "gif", true},
+		{"a.bmp", "bmp", true},
+		{"a.tiff", "", false},
+		{"a.png.txt", "", false},
+		{"png", "", false},
+	}
+	for _, tt := range tests {
+		got, err := imageFormat(tt.path)
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("%s: %q, %v; esperado %q", tt.path, got, err, tt.want)
+		}
+		if err != nil && !strings.Contains(err.Error(), ".jpg") {
+			t.Errorf("%s: o erro não lista os formatos: %v", tt.path, err)
+		}
+	}
+}
+
+func TestImagePathOutFormat(t *testing.T) {
+	withFlag(t, outDir, "saida")
+	tests := []struct {
+		format, name, want string
+	}{
+		{"png", "otsu.png", filepath.Join("saida", "otsu.png")},
+		{"jpg", "otsu.png", filepath.Join("saida", "otsu.jpg")},
+		{"bmp", "filtered_3x3.png", filepath.Join("saida", "filtered_3x3.bmp")},
+		{"jpg", "mask.rle.json", filepath.Join("saida", "mask.rle.json")},
+	}
+	for _, tt := range tests {
+		withFlag(t, outFormat, tt.format)
+		if got := imagePath(tt.name); got != tt.want {
+			t.Errorf("-out-format %s, %s: %s, esperado %s", tt.format, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParsePNGCompression(t *testing.T) {
+	tests := []struct {
+		in   string
+		want png.CompressionLevel
+		ok   bool
+	}{
+		{"default", png.DefaultCompression, true},
+		{"none", png.NoCompression, true},
+		{"fast", png.BestSpeed, true},
+		{"best", png.BestCompression, true},
+		{"9", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parsePNGCompression(tt.in)
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("%q: %v, %v", tt.in, got, err)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// MSERRegion é uma região extrema maximamente estável. O conjunto de pixels é
+// a componente conectada de Seed com intensidade <= Level (>= para regiões
+// claras).
+type MSERRegion struct {
+	Seed      image.Point `json:"seed"`
+	Level     int         `json:"level"`
+	Area      int         `json:"area"`
+	Variation float64     `json:"variation"`
+	Ellipse   Ellipse     `json:"ellipse"`
+}
+
+// Ellipse é a elipse com os mesmos momentos de segunda ordem da região.
+type Ellipse struct {
+	CX    float64 `json:"cx"`
+	CY    float64 `json:"cy"`
+	Major float64 `json:"major"` // semi-eixo maior
+	Minor float64 `json:"minor"` // semi-eixo menor
+	Angle float64 `json:"angle"` // radianos, eixo maior em relação ao eixo x
+}
+
+// MSEROptions controla a detecção.
+type MSEROptions struct {
+	Delta        int
+	MinArea      int
+	MaxArea      int
+	MaxVariation float64
+	Bright       bool // procura regiões claras em vez de escuras
+	// KeepBorder mantém regiões que tocam a borda; por padrão elas são
+	// descartadas, pois em degradês de fundo toda região cresce a partir da
+	// borda e parece estável
+	KeepBorder bool
+}
+
+// nó da árvore de componentes: a componente existe de level até o nível do pai
+type mserNode struct {
+	level  int
+	area   int
+	seed   int
+	parent int
+	border bool
+}
+
+// detectMSER varre os limiares em ordem de intensidade, juntando os pixels com
+// union-find (4-conectividade), e guarda a área de cada componente em cada
+// nível. A variação de uma região é (|R(l+Δ)| − |R(l)|) / |R(l)|; são
+// estáveis as regiões com variação mínima local ao longo da árvore, dentro
+// dos limites de área e abaixo de MaxVariation.
+func detectMSER(img *image.Gray, opts MSEROptions) []MSERRegion {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	n := width * height
+	if n == 0 {
+		return nil
+	}
+
+	value := func(i int) int {
+		v := int(img.Pix[(i/width)*img.Stride+i%width])
+		if opts.Bright {
+			return 255 - v
+		}
+		return v
+	}
+
+	// ordenação por contagem
+	var counts [257]int
+	for i := 0; i < n; i++ {
+		counts[value(i)+1]++
+	}
+	for l := 1; l <= 256; l++ {
+		counts[l] += counts[l-1]
+	}
+	order := make([]int, n)
+	start := counts
+	for i := 0; i < n; i++ {
+		order[start[value(i)]] = i
+		start[value(i)]++
+	}
+
+	parent := make([]int, n)
+	size := make([]int, n)
+	border := make([]bool, n)
+	rootNode := make([]int, n)
+	pending := make(map[int][]int)
+	for i := range parent {
+		parent[i] = -1 // inativo
+		rootNode[i] = -1
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+
+	var nodes []mserNode
+	idx := 0
+	for level := 0; level < 256; level++ {
+		var touched []int
+		for ; idx < n && value(order[idx]) == level; idx++ {
+			p := order[idx]
+			parent[p] = p
+			size[p] = 1
+			touched = append(touched, p)
+
+			x, y := p%width, p/width
+			border[p] = x == 0 || y == 0 || x == width-1 || y == height-1
+			for _, d := range [4][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+				nx, ny := x+d[0], y+d[1]
+				if nx < 0 || ny < 0 || nx >= width || ny >= height {
+					continue
+				}
+				q := ny*width + nx
+				if parent[q] < 0 {
+					continue
+				}
+				a, b := find(p), find(q)
+				if a == b {
+					continue
+				}
+				if size[a] < size[b] {
+					a, b = b, a
+				}
+				// b é absorvida por a: o nó atual de b vira filho do próximo nó de a
+				parent[b] = a
+				size[a] += size[b]
+				border[a] = border[a] || border[b]
+				if rootNode[b] >= 0 {
+					pending[a] = append(pending[a], rootNode[b])
+				}
+				pending[a] = append(pending[a], pending[b]...)
+				delete(pending, b)
+				rootNode[b] = -1
+			}
+		}
+
+		seen := make(map[int]bool)
+		for _, p := range touched {
+			r := find(p)
+			if seen[r] {
+				continue
+			}
+			seen[r] = true
+
+			node := len(nodes)
+			nodes = append(nodes, mserNode{level: level, area: size[r], seed: r, parent: -1, border: border[r]})
+			children := pending[r]
+			if rootNode[r] >= 0 {
+				children = append(children, rootNode[r])
+			}
+			for _, c := range children {
+				nodes[c].parent = node
+			}
+			delete(pending, r)
+			rootNode[r] = node
+		}
+	}
+
+	// variação de cada nó
+	variation := make([]float64, len(nodes))
+	for i, node := range nodes {
+		anc := i
+		for nodes[anc].parent >= 0 && nodes[nodes[anc].parent].level <= node.level+opts.Delta {
+			anc = nodes[anc].parent
+		}
+		variation[i] = float64(nodes[anc].area-node.area) / float64(node.area)
+	}
+
+	// mínimo local: menor que o pai e que todos os filhos
+	childMin := make([]float64, len(nodes))
+	for i := range childMin {
+		childMin[i] = math.Inf(1)
+	}
+	for i, node := range nodes {
+		if node.parent >= 0 {
+			childMin[node.parent] = math.Min(childMin[node.parent], variation[i])
+		}
+	}
+
+	maxArea := opts.MaxArea
+	if maxArea <= 0 || maxArea >= n {
+		// a imagem inteira nunca é uma região
+		maxArea = n - 1
+	}
+
+	selected := make([]bool, len(nodes))
+	for i, node := range nodes {
+		if node.area < opts.MinArea || node.area > maxArea || variation[i] > opts.MaxVariation {
+			continue
+		}
+		if node.border && !opts.KeepBorder {
+			continue
+		}
+		if node.parent >= 0 && variation[node.parent] < variation[i] {
+			continue
+		}
+		if variation[i] > childMin[i] {
+			continue
+		}
+		selected[i] = true
+	}
+
+	// descarta regiões quase iguais a uma região estável que as contém
+	var regions []MSERRegion
+	for i, node := range nodes {
+		if !selected[i] {
+			continue
+		}
+		duplicate := false
+		for anc := node.parent; anc >= 0; anc = nodes[anc].parent {
+			if float64(nodes[anc].area) > 1.2*float64(node.area) {
+				break
+			}
+			if selected[anc] {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+
+		seed := image.Pt(node.seed%width, node.seed/width)
+		mask := mserMask(img, seed, node.level, opts.Bright)
+		regions = append(regions, MSERRegion{
+			Seed:      seed,
+			Level:     node.level,
+			Area:      node.area,
+			Variation: variation[i],
+			Ellipse:   fitEllipse(mask),
+		})
+	}
+
+	return regions
+}
+
+// mserMask reconstrói os pixels de uma região por inundação a partir da semente.
+func mserMask(img *image.Gray, seed image.Point, level int, bright bool) []image.Point {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	inside := func(x, y int) bool {
+		v := int(img.Pix[y*img.Stride+x])
+		if bright {
+			v = 255 - v
+		}
+		return v <= level
+	}
+
+	visited := make([]bool, width*height)
+	var points []image.Point
+	stack := []image.Point{seed}
+	visited[seed.Y*width+seed.X] = true
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		points = append(points, p)
+		for _, d := range [4][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			nx, ny := p.X+d[0], p.Y+d[1]
+			if nx < 0 || ny < 0 || nx >= width || ny >= height || visited[ny*width+nx] || !inside(nx, ny) {
+				continue
+			}
+			visited[ny*width+nx] = true
+			stack = append(stack, image.Pt(nx, ny))
+		}
+	}
+	return points
+}
+
+// fitEllipse ajusta a elipse de mesmos momentos de segunda ordem.
+func fitEllipse(points []image.Point) Ellipse {
+	if len(points) == 0 {
+		return Ellipse{}
+	}
+	var sx, sy float64
+	for _, p := range points {
+		sx += float64(p.X)
+		sy += float64(p.Y)
+	}
+	n := float64(len(points))
+	cx, cy := sx/n, sy/n
+
+	var mxx, myy, mxy float64
+	for _, p := range points {
+		dx, dy := float64(p.X)-cx, float64(p.Y)-cy
+		mxx += dx * dx
+		myy += dy * dy
+		mxy += dx * dy
+	}
+	mxx, myy, mxy = mxx/n, myy/n, mxy/n
+
+	common := math.Sqrt((mxx-myy)*(mxx-myy)/4 + mxy*mxy)
+	l1 := (mxx+myy)/2 + common
+	l2 := (mxx+myy)/2 - common
+	return Ellipse{
+		CX:    cx,
+		CY:    cy,
+		Major: 2 * math.Sqrt(math.Max(l1, 0)),
+		Minor: 2 * math.Sqrt(math.Max(l2, 0)),
+		Angle: 0.5 * math.Atan2(2*mxy, mxx-myy),
+	}
+}
+
+// drawEllipse desenha o contorno da elipse por amostragem do perímetro.
+func drawEllipse(img *image.RGBA, e Ellipse, col color.RGBA) {
+	steps := int(math.Max(16, 2*math.Pi*math.Max(e.Major, 1)))
+	cos, sin := math.Cos(e.Angle), math.Sin(e.Angle)
+	for i := 0; i < steps; i++ {
+		t := 2 * math.Pi * float64(i) / float64(steps)
+		px, py := e.Major*math.Cos(t), e.Minor*math.Sin(t)
+		x := int(math.Round(e.CX + px*cos - py*sin))
+		y := int(math.Round(e.CY + px*sin + py*cos))
+		if image.Pt(x, y).In(img.Bounds()) {
+			img.SetRGBA(x, y, col)
+		}
+	}
+}
+
+// mserOverlay pinta as regiões em verde e contorna as elipses em vermelho.
+func mserOverlay(img *image.Gray, regions []MSERRegion, bright bool) *image.RGBA {
+	result := grayToRGBA(img)
+	for _, r := range regions {
+		for _, p := range mserMask(img, r.Seed, r.Level, bright) {
+			c := result.RGBAAt(p.X, p.Y)
+			result.SetRGBA(p.X, p.Y, color.RGBA{c.R / 2, c.G/2 + 128, c.B / 2, 255})
+		}
+	}
+	for _, r := range regions {
+		drawEllipse(result, r.Ellipse, color.RGBA{255, 0, 0, 255})
+	}
+	return result
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// gradientImage é um degradê horizontal de 80 a 240.
+func gradientImage(width, height int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Pix[y*img.Stride+x] = uint8(80 + 160*x/(width-1))
+		}
+	}
+	return img
+}
+
+func TestMSERText(t *testing.T) {
+	const text, scale, x0, y0 = "MSER OK", 4, 12, 20
+	img := gradientImage(200, 68)
+	canvas := grayToRGBA(img)
+	drawText(canvas, x0, y0, text, scale, color.RGBA{30, 30, 30, 255})
+	for i := range img.Pix {
+		img.Pix[i] = canvas.Pix[4*i]
+	}
+
+	regions := detectMSER(img, MSEROptions{Delta: 5, MinArea: 30, MaxVariation: 0.25})
+	for i, r := range text {
+		if r == ' ' {
+			continue
+		}
+		box := image.Rect(x0+i*glyphAdvance*scale, y0, x0+(i*glyphAdvance+glyphWidth)*scale, y0+glyphHeight*scale)
+		found := false
+		for _, region := range regions {
+			center := image.Pt(int(math.Round(region.Ellipse.CX)), int(math.Round(region.Ellipse.CY)))
+			if center.In(box) && region.Area <= box.Dx()*box.Dy() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("caractere %q em %v sem região estável", r, box)
+		}
+	}
+}
+
+func TestMSERGradient(t *testing.T) {
+	tests := []struct {
+		name string
+		opts MSEROptions
+	}{
+		{"escuras", MSEROptions{Delta: 5, MinArea: 30, MaxVariation: 0.25}},
+		{"claras", MSEROptions{Delta: 5, MinArea: 30, MaxVariation: 0.25, Bright: true}},
+	}
+	for _, tt := range tests {
+		if regions := detectMSER(gradientImage(200, 68), tt.opts); len(regions) != 0 {
+			t.Errorf("%s: %d regiões num degradê, esperado nenhuma", tt.name, len(regions))
+		}
+	}
+}
+
+func TestFitEllipse(t *testing.T) {
+	// retângulo 20x4 deitado: eixo maior horizontal
+	var points []image.Point
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 20; x++ {
+			points = append(points, image.Pt(x, y))
+		}
+	}
+	e := fitEllipse(points)
+	if e.CX != 9.5 || e.CY != 1.5 {
+		t.Errorf("centro %v,%v, esperado 9.5,1.5", e.CX, e.CY)
+	}
+	if e.Major <= e.Minor || math.Abs(e.Angle) > 1e-9 {
+		t.Errorf("elipse %+v, esperado eixo maior horizontal", e)
+	}
+}
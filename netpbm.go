@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+)
+
+// Netpbm: PGM (P2 em texto, P5 binário) e PPM (P3, P6) na leitura, P5 na
+// escrita. Valores com maxval diferente de 255 (até 65535, em 2 bytes no
+// binário) são levados para 0..255.
+
+func init() {
+	for _, magic := range []string{"P2", "P5"} {
+		image.RegisterFormat("pgm", magic, decodeNetpbm, decodeNetpbmConfig)
+	}
+	for _, magic := range []string{"P3", "P6"} {
+		image.RegisterFormat("ppm", magic, decodeNetpbm, decodeNetpbmConfig)
+	}
+}
+
+var errNetpbmTruncated = errors.New("arquivo Netpbm truncado")
+
+// netpbmHeader é o cabeçalho de um PGM ou PPM.
+type netpbmHeader struct {
+	magic         string
+	width, height int
+	maxval        int
+}
+
+// netpbmToken lê o próximo campo do cabeçalho (ou amostra em texto),
+// pulando espaços e comentários (# até o fim da linha).
+func netpbmToken(r *bufio.Reader) (string, error) {
+	var token []byte
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(token) > 0 {
+				return string(token), nil
+			}
+			return "", err
+		}
+		switch {
+		case c == '#' && len(token) == 0:
+			if _, err := r.ReadString('\n'); err != nil {
+				return "", err
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f':
+			if len(token) > 0 {
+				return string(token), nil
+			}
+		default:
+			token = append(token, c)
+		}
+	}
+}
+
+// netpbmInt lê um campo numérico não negativo; what diz qual, para os erros.
+func netpbmInt(r *bufio.Reader, what string) (int, error) {
+	token, err := netpbmToken(r)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return 0, fmt.Errorf("%w: falta %s", errNetpbmTruncated, what)
+	}
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.Atoi(token)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("valor inválido %q para %s no arquivo Netpbm", token, what)
+	}
+	return v, nil
+}
+
+func readNetpbmHeader(r *bufio.Reader) (netpbmHeader, error) {
+	var h netpbmHeader
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return h, fmt.Errorf("cabeçalho Netpbm incompleto: %w", err)
+	}
+	h.magic = string(magic)
+	if h.magic != "P2" && h.magic != "P3" && h.magic != "P5" && h.magic != "P6" {
+		return h, fmt.Errorf("formato Netpbm %q não suportado, só P2, P3, P5 e P6", h.magic)
+	}
+	var err error
+	if h.width, err = netpbmInt(r, "a largura"); err != nil {
+		return h, err
+	}
+	if h.height, err = netpbmInt(r, "a altura"); err != nil {
+		return h, err
+	}
+	if h.maxval, err = netpbmInt(r, "o maxval"); err != nil {
+		return h, err
+	}
+	if h.width == 0 || h.height == 0 || h.maxval == 0 || h.maxval > 65535 {
+		return h, fmt.Errorf("cabeçalho Netpbm inválido: %dx%d, maxval %d", h.width, h.height, h.maxval)
+	}
+	if h.width > maxDecodePixels/h.height {
+		return h, fmt.Errorf("imagem Netpbm grande demais: %dx%d", h.width, h.height)
+	}
+	// netpbmToken já consumiu o único espaço que separa o cabeçalho dos
+	// dados binários
+	return h, nil
+}
+
+func decodeNetpbmConfig(r io.Reader) (image.Config, error) {
+	h, err := readNetpbmHeader(bufio.NewReader(r))
+	if err != nil {
+		return image.Config{}, err
+	}
+	config := image.Config{ColorModel: color.RGBAModel, Width: h.width, Height: h.height}
+	if h.magic == "P2" || h.magic == "P5" {
+		config.ColorModel = color.GrayModel
+	}
+	return config, nil
+}
+
+// decodeNetpbm lê um PGM (vira *image.Gray) ou PPM (*image.RGBA).
+func decodeNetpbm(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+	h, err := readNetpbmHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	channels := 1
+	if h.magic == "P3" || h.magic == "P6" {
+		channels = 3
+	}
+	// os buffers crescem com os dados lidos, não com o cabeçalho: um
+	// arquivo truncado dá erro sem alocar a imagem inteira
+	n := h.width * h.height * channels
+	samples := make([]uint8, 0, min(n, 1<<16))
+	scale := func(v int) (uint8, error) {
+		if v > h.maxval {
+			return 0, fmt.Errorf("amostra %d acima do maxval %d", v, h.maxval)
+		}
+		return uint8((v*255 + h.maxval/2) / h.maxval), nil
+	}
+
+	switch h.magic {
+	case "P2", "P3":
+		for len(samples) < n {
+			v, err := netpbmInt(br, "a amostra de um pixel")
+			if err != nil {
+				return nil, err
+			}
+			s, err := scale(v)
+			if err != nil {
+				return nil, err
+			}
+			samples = append(samples, s)
+		}
+	default:
+		size := 1
+		if h.maxval > 255 {
+			size = 2
+		}
+		data, err := io.ReadAll(io.LimitReader(br, int64(n*size)))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < n*size {
+			return nil, fmt.Errorf("%w: esperava %d bytes de pixels, há %d", errNetpbmTruncated, n*size, len(data))
+		}
+		for i := 0; i < n; i++ {
+			v := int(data[i])
+			if size == 2 {
+				v = int(data[2*i])<<8 | int(data[2*i+1])
+			}
+			s, err := scale(v)
+			if err != nil {
+				return nil, err
+			}
+			samples = append(samples, s)
+		}
+	}
+
+	rect := image.Rect(0, 0, h.width, h.height)
+	if channels == 1 {
+		gray := image.NewGray(rect)
+		copy(gray.Pix, samples)
+		return gray, nil
+	}
+	rgba := image.NewRGBA(rect)
+	for i := 0; i < h.width*h.height; i++ {
+		copy(rgba.Pix[4*i:4*i+3], samples[3*i:3*i+3])
+		rgba.Pix[4*i+3] = 255
+	}
+	return rgba, nil
+}
+
+// encodePGM grava a imagem como PGM binário (P5, maxval 255).
+func encodePGM(w io.Writer, img *image.Gray) error {
+	b := img.Bounds()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P5\n%d %d\n255\n", b.Dx(), b.Dy())
+	for y := 0; y < b.Dy(); y++ {
+		start := img.PixOffset(b.Min.X, b.Min.Y+y)
+		if _, err := bw.Write(img.Pix[start : start+b.Dx()]); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// asciiPGM escreve a imagem como P2, uma linha de texto por linha de pixels
+func asciiPGM(img *image.Gray) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "P2\n# gerado pelo teste\n%d %d\n255\n", img.Bounds().Dx(), img.Bounds().Dy())
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			fmt.Fprintf(&buf, "%d ", img.Pix[y*img.Stride+x])
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func TestNetpbmRoundTrip(t *testing.T) {
+	img := randomImage(17, 9, 6)
+	var binary bytes.Buffer
+	if err := encodePGM(&binary, img); err != nil {
+		t.Fatal(err)
+	}
+	var sub bytes.Buffer
+	if err := encodePGM(&sub, randomImage(30, 30, 6).SubImage(image.Rect(5, 7, 22, 16)).(*image.Gray)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want *image.Gray
+	}{
+		{"binário (P5)", binary.Bytes(), img},
+		{"texto (P2)", asciiPGM(img), img},
+		{"SubImage em P5", sub.Bytes(), randomImage(30, 30, 6).SubImage(image.Rect(5, 7, 22, 16)).(*image.Gray)},
+	}
+	for _, tt := range tests {
+		got, format, err := image.Decode(bytes.NewReader(tt.data))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if format != "pgm" {
+			t.Errorf("%s: formato %q, esperado pgm", tt.name, format)
+		}
+		gray, ok := got.(*image.Gray)
+		if !ok || !sameGray(gray, tt.want) {
+			t.Errorf("%s: a imagem decodificada difere da gravada", tt.name)
+		}
+	}
+}
+
+func TestDecodeNetpbm(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want image.Image
+	}{
+		{"comentários e espaços", "P2 # largura e altura\n\t3\r\n# meio\n 1 255\n0\n\n128   255", grayRow(0, 128, 255)},
+		{"maxval 15", "P2 3 1 15 0 8 15", grayRow(0, 136, 255)},
+		{"maxval 1000", "P2 2 1 1000 500 1000", grayRow(128, 255)},
+		{"binário com 2 bytes", "P5 2 1 65535\n\x80\x00\xff\xff", grayRow(128, 255)},
+		{"PPM em texto", "P3 2 1 255 255 0 0 0 0 255", rgbaRow(color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255})},
+		{"PPM binário", "P6\n2 1\n255\n\x00\xff\x00\x10\x20\x30", rgbaRow(color.RGBA{0, 255, 0, 255}, color.RGBA{16, 32, 48, 255})},
+	}
+	for _, tt := range tests {
+		got, err := decodeNetpbm(strings.NewReader(tt.data))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: %v, esperado %v", tt.name, got, tt.want)
+		}
+		config, err := decodeNetpbmConfig(strings.NewReader(tt.data))
+		if err != nil || config.Width != tt.want.Bounds().Dx() || config.Height != tt.want.Bounds().Dy() {
+			t.Errorf("%s: config %+v, %v", tt.name, config, err)
+		}
+	}
+}
+
+func grayRow(values ...uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, len(values), 1))
+	copy(img.Pix, values)
+	return img
+}
+
+func rgbaRow(colors ...color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, len(colors), 1))
+	for x, c := range colors {
+		img.SetRGBA(x, 0, c)
+	}
+	return img
+}
+
+func TestDecodeNetpbmErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		truncated bool
+	}{
+		{"só a assinatura", "P5", true},
+		{"sem a altura", "P2 4", true},
+		{"sem o maxval", "P5 4 4\n", true},
+		{"binário curto", "P5 4 4 255\n" + strings.Repeat("\x01", 15), true},
+		{"binário de 2 bytes curto", "P5 2 1 1000\n\x01\x00\x02", true},
+		{"texto curto", "P2 2 2 255 1 2 3", true},
+		{"PPM curto", "P6 1 1 255\n\x01\x02", true},
+		{"assinatura desconhecida", "P4 1 1\n\x00", false},
+		{"largura zero", "P2 0 1 255", false},
+		{"maxval zero", "P2 1 1 0 0", false},
+		{"maxval grande demais", "P2 1 1 70000 0", false},
+		{"amostra acima do maxval", "P2 1 1 15 16", false},
+		{"número inválido", "P2 1 x 255 0", false},
+		{"negativo", "P2 1 1 255 -1", false},
+	}
+	for _, tt := range tests {
+		_, err := decodeNetpbm(strings.NewReader(tt.data))
+		if err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+			continue
+		}
+		if errors.Is(err, errNetpbmTruncated) != tt.truncated {
+			t.Errorf("%s: errors.Is(errNetpbmTruncated) = %v: %v", tt.name, !tt.truncated, err)
+		}
+	}
+}
+
+func TestLoadAndSavePGM(t *testing.T) {
+	dir := t.TempDir()
+	img := gradientImage(20, 12)
+	binary := filepath.Join(dir, "saida.pgm")
+	if err := saveImage(binary, img); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(binary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("P5\n20 12\n255\n")) {
+		t.Errorf("cabeçalho %q, esperado P5", data[:min(len(data), 16)])
+	}
+	ascii := filepath.Join(dir, "aula.pgm")
+	if err := os.WriteFile(ascii, asciiPGM(img), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{binary, ascii} {
+		got, err := loadImage(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameGray(got, img) {
+			t.Errorf("%s: loadImage não devolveu a imagem gravada", filepath.Base(path))
+		}
+	}
+
+	truncated := filepath.Join(dir, "truncado.pgm")
+	if err := os.WriteFile(truncated, data[:len(data)-5], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadImage(truncated); !errors.Is(err, errNetpbmTruncated) || !strings.Contains(err.Error(), "truncado") {
+		t.Errorf("arquivo truncado: %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+)
+
+// maskOverlap compara duas máscaras binárias (binarizadas pelo meio da escala
+// segundo fg) e devolve IoU, Dice e as contagens de interseção e união. Por
+// convenção, duas máscaras vazias concordam totalmente: IoU = Dice = 1.
+// Dimensões diferentes são erro.
+func maskOverlap(a, b *image.Gray, fg polarity) (iou, dice float64, intersection, union int, err error) {
+	if a.Bounds().Size() != b.Bounds().Size() {
+		return 0, 0, 0, 0, fmt.Errorf("máscaras com dimensões diferentes: %v e %v", a.Bounds().Size(), b.Bounds().Size())
+	}
+	width, height := a.Bounds().Dx(), a.Bounds().Dy()
+	areaA, areaB := 0, 0
+	for y := 0; y < height; y++ {
+		rowA := a.Pix[a.PixOffset(a.Bounds().Min.X, a.Bounds().Min.Y+y):]
+		rowB := b.Pix[b.PixOffset(b.Bounds().Min.X, b.Bounds().Min.Y+y):]
+		for x := 0; x < width; x++ {
+			inA, inB := fg.isForeground(rowA[x]), fg.isForeground(rowB[x])
+			if inA {
+				areaA++
+			}
+			if inB {
+				areaB++
+			}
+			if inA && inB {
+				intersection++
+			}
+		}
+	}
+	union = areaA + areaB - intersection
+	if union == 0 {
+		return 1, 1, 0, 0, nil
+	}
+	iou = float64(intersection) / float64(union)
+	dice = 2 * float64(intersection) / float64(areaA+areaB)
+	return iou, dice, intersection, union, nil
+}
+
+// gotoshop overlap [-fg white|black] [-min-iou 0.9] a.png b.png
+func runOverlap(args []string) int {
+	fs := newFlagSet("overlap", "[-min-iou 0.9] a.png b.png")
+	fgName := fs.String("fg", "white", "cor do primeiro plano: white ou black")
+	minIoU := fs.Float64("min-iou", 0, "sai com 1 se o IoU ficar abaixo disto")
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+	fg, err := parsePolarity(*fgName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if len(files) != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	a, err := loadImage(files[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	b, err := loadImage(files[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	iou, dice, intersection, union, err := maskOverlap(a, b, fg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro ao comparar %s e %s: %v\n", files[0], files[1], err)
+		return 2
+	}
+	fmt.Printf("IoU: %.4f\nDice: %.4f\nInterseção: %d\nUnião: %d\n", iou, dice, intersection, union)
+	if iou < *minIoU {
+		fmt.Fprintf(os.Stderr, "IoU %.4f abaixo do mínimo %.4f\n", iou, *minIoU)
+		return 1
+	}
+	return 0
+}
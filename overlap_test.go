@@ -0,0 +1,95 @@
+package main
+
+import (
+	"image"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+// rectMask é uma máscara 20x20 com os retângulos em 255.
+func rectMask(rects ...image.Rectangle) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 20, 20))
+	for _, r := range rects {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				img.Pix[y*img.Stride+x] = 255
+			}
+		}
+	}
+	return img
+}
+
+func TestMaskOverlap(t *testing.T) {
+	tests := []struct {
+		name                string
+		a, b                *image.Gray
+		iou, dice           float64
+		intersection, union int
+	}{
+		{"disjuntas", rectMask(image.Rect(0, 0, 5, 5)), rectMask(image.Rect(10, 10, 15, 15)), 0, 0, 0, 50},
+		{"idênticas", rectMask(image.Rect(2, 2, 12, 8)), rectMask(image.Rect(2, 2, 12, 8)), 1, 1, 60, 60},
+		// 4x4 dentro de 8x8: 16 / 64 e 2·16 / 80
+		{"aninhadas", rectMask(image.Rect(0, 0, 8, 8)), rectMask(image.Rect(2, 2, 6, 6)), 0.25, 0.4, 16, 64},
+		// metade sobreposta: 50 / 150 e 100 / 200
+		{"deslocadas", rectMask(image.Rect(0, 0, 10, 10)), rectMask(image.Rect(5, 0, 15, 10)), 1.0 / 3, 0.5, 50, 150},
+		{"vazias", rectMask(), rectMask(), 1, 1, 0, 0},
+		{"uma vazia", rectMask(image.Rect(0, 0, 3, 3)), rectMask(), 0, 0, 0, 9},
+	}
+	for _, tt := range tests {
+		iou, dice, intersection, union, err := maskOverlap(tt.a, tt.b, foregroundWhite)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if math.Abs(iou-tt.iou) > 1e-12 || math.Abs(dice-tt.dice) > 1e-12 || intersection != tt.intersection || union != tt.union {
+			t.Errorf("%s: IoU %g, Dice %g, %d/%d; esperado %g, %g, %d/%d",
+				tt.name, iou, dice, intersection, union, tt.iou, tt.dice, tt.intersection, tt.union)
+		}
+	}
+}
+
+func TestMaskOverlapPolarity(t *testing.T) {
+	a, b := rectMask(image.Rect(0, 0, 10, 20)), rectMask(image.Rect(0, 0, 15, 20))
+	// com fg preto as máscaras são os complementos: 10x20 e 5x20
+	iou, _, intersection, union, err := maskOverlap(a, b, foregroundBlack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if intersection != 100 || union != 200 || iou != 0.5 {
+		t.Errorf("fg preto: IoU %g, %d/%d; esperado 0.5, 100/200", iou, intersection, union)
+	}
+}
+
+func TestMaskOverlapErrors(t *testing.T) {
+	if _, _, _, _, err := maskOverlap(rectMask(), uniformGray(10, 0), foregroundWhite); err == nil {
+		t.Error("dimensões diferentes sem erro")
+	}
+}
+
+func TestRunOverlap(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.png"), filepath.Join(dir, "b.png")
+	if err := saveImage(a, rectMask(image.Rect(0, 0, 10, 10))); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveImage(b, rectMask(image.Rect(5, 0, 15, 10))); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"sem limite", []string{a, b}, 0},
+		{"acima do mínimo", []string{"-min-iou", "0.3", a, b}, 0},
+		{"abaixo do mínimo", []string{"-min-iou", "0.5", a, b}, 1},
+		{"um arquivo só", []string{a}, 2},
+		{"polaridade inválida", []string{"-fg", "cinza", a, b}, 2},
+		{"arquivo que não existe", []string{a, filepath.Join(dir, "nada.png")}, 2},
+	}
+	for _, tt := range tests {
+		if code := runOverlap(tt.args); code != tt.code {
+			t.Errorf("%s: código %d, esperado %d", tt.name, code, tt.code)
+		}
+	}
+}
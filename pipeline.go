@@ -0,0 +1,613 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"sort"
+	"strconv"
+	"strings"
+
+	"processing-images/dft"
+	"processing-images/imaging"
+	"processing-images/synthetic"
+)
+
+// pipelineStage é uma etapa de um pipeline: recebe a saída da anterior.
+type pipelineStage struct {
+	name  string
+	apply func(*image.Gray) (*image.Gray, error)
+}
+
+// pipeline é uma sequência nomeada de etapas que pode ser escolhida em -ops.
+// As etapas são montadas na hora de rodar para que leiam os valores atuais
+// das flags.
+type pipeline struct {
+	name        string
+	description string
+	output      string
+	stages      func() []pipelineStage
+}
+
+var pipelines = map[string]pipeline{
+	"docbin": {
+		name:        "docbin",
+		description: "binarização de documentos para OCR (fundo, Sauvola, sujeira, endireitar)",
+		output:      "docbin.png",
+		stages:      docbinStages,
+	},
+}
+
+// run aplica as etapas em ordem, registrando cada saída em rec (pode ser
+// nil).
+func (p pipeline) run(img *image.Gray, rec *stageRecorder) (*image.Gray, error) {
+	for _, stage := range p.stages() {
+		fmt.Printf("[%s] %s...\n", p.name, stage.name)
+		var err error
+		img, err = stage.apply(img)
+		if err != nil {
+			return nil, fmt.Errorf("%s: etapa %s: %w", p.name, stage.name, err)
+		}
+		rec.record(p.name, stage.name, img, nil)
+	}
+	return img, nil
+}
+
+// operation é uma operação que pode ser escolhida em -ops; run devolve as
+// saídas sem gravá-las.
+type operation struct {
+	description string
+	run         func(in opInput) ([]output, error)
+}
+
+// opInput é o que as operações recebem: a imagem em cinza, a região de
+// interesse (pode ser nil) e o registro de etapas (pode ser nil).
+type opInput struct {
+	img    *image.Gray
+	roi    *image.Gray
+	rec    *stageRecorder
+	border imaging.BorderMode
+}
+
+var operations = map[string]operation{
+	"canny":         {"bordas de Canny (canny.png)", opCanny},
+	"gradient":      {"magnitude e direção do gradiente com o operador de -operator (gradient_mag.png, gradient_dir.png)", opGradient},
+	"otsu":          {"limiarização de Otsu (otsu.png)", opOtsu},
+	"marrhildreth":  {"bordas de Marr-Hildreth (marr_hildreth.png)", opMarrHildreth},
+	"watershed":     {"watershed por marcadores: linhas de divisor e rótulos (watershed.png, watershed_labels.png)", opWatershed},
+	"background":    {"separação do fundo pela fração -bg, o antigo watershed (background.png)", opBackground},
+	"box":           {"filtros box nos tamanhos de -box (filtered_NxN.png)", opBox},
+	"segment":       {"fatiamento de intensidade pela tabela de -slice (segmented.png)", opSegment},
+	"gaussian":      {"borrão gaussiano separável com σ de -sigma (gaussian.png)", opGaussian},
+	"adaptive":      {"limiar adaptativo: média local de -window menos -c (adaptive.png)", opAdaptive},
+	"sauvola":       {"binarização de Sauvola na janela -window com -sauvola-k (sauvola.png)", opSauvola},
+	"niblack":       {"binarização de Niblack na janela -window com -niblack-k (niblack.png)", opNiblack},
+	"opening":       {"abertura em tons de cinza com o elemento de -se (opening.png)", morphOp("opening.png", whiteObjects(imaging.Opening))},
+	"closing":       {"fechamento em tons de cinza com o elemento de -se (closing.png)", morphOp("closing.png", whiteObjects(imaging.Closing))},
+	"morphgradient": {"gradiente morfológico, dilatação menos erosão (morphgradient.png)", morphOp("morphgradient.png", imaging.MorphGradient)},
+	"tophat":        {"top-hat, a imagem menos a abertura: tira o fundo desigual (tophat.png)", morphOp("tophat.png", imaging.TopHat)},
+	"blackhat":      {"black-hat, o fechamento menos a imagem: detalhes escuros (blackhat.png)", morphOp("blackhat.png", imaging.BlackHat)},
+	"label":         {"rótulos dos objetos do Otsu com vizinhança -connectivity, um por cor (labels.png)", opLabel},
+	"fillholes":     {"máscara de Otsu com os buracos dos objetos preenchidos (filled.png)", opFillHoles},
+	"skeleton":      {"esqueleto de Zhang-Suen dos objetos do Otsu (skeleton.png)", opSkeleton},
+	"distance":      {"transformada de distância da máscara de Otsu com -distance-metric (distance.png)", opDistance},
+	"negative":      {"negativo da imagem, 255 menos o nível (negative.png)", pointOp("negative.png", imaging.Negative)},
+	"log":           {"transformação logarítmica: abre os tons escuros (log.png)", pointOp("log.png", imaging.LogTransform)},
+	"stretch":       {"esticamento linear do contraste entre os percentis de -clip (stretched.png)", opStretch},
+	"sharpen":       {"máscara de nitidez com -sharpen-sigma e -sharpen-amount (sharpened.png)", opSharpen},
+	"lapsharpen":    {"realce pelo laplaciano com -sharpen-amount (sharpened_laplacian.png)", opLaplacianSharpen},
+	"nlmeans":       {"remoção de ruído por médias não locais com -nlm-h, -nlm-patch e -nlm-search (nlmeans.png)", opNLMeans},
+	"diffusion":     {"difusão anisotrópica de Perona-Malik com -diffusion-iter, -diffusion-kappa e -diffusion-lambda (diffused.png)", opDiffusion},
+	"min":           {"filtro de mínimo na janela -rank-size, a erosão com um quadrado (min.png)", rankOp("min.png", imaging.MinFilter)},
+	"max":           {"filtro de máximo na janela -rank-size, a dilatação com um quadrado (max.png)", rankOp("max.png", imaging.MaxFilter)},
+	"midpoint":      {"ponto médio entre o mínimo e o máximo da janela -rank-size (midpoint.png)", rankOp("midpoint.png", imaging.MidpointFilter)},
+	"alphatrim":     {"média alfa-aparada na janela -restore-size sem os -trim-d menores e maiores (alphatrim.png)", opAlphaTrim},
+	"contraharm":    {"média contra-harmônica de ordem -q na janela -restore-size (contraharmonic.png)", opContraharmonic},
+	"kuwahara":      {"filtro de Kuwahara na janela -kuwahara-size: alisa sem borrar as bordas (kuwahara.png)", opKuwahara},
+	"noise":         {"ruído -noise-type gaussian (-noise-sigma), sp (-density), speckle (-speckle-sigma) ou poisson com a semente -noise-seed (noisy.png)", opNoise},
+	"wiener":        {"deconvolução de Wiener do borrão -psf (ou gaussiano de -psf-sigma) com -wiener-k (wiener.png)", opWiener},
+	"inverse":       {"filtro inverso do borrão -psf (ou gaussiano de -psf-sigma) com o corte -inverse-threshold (inverse.png)", opInverse},
+	"spectrum":      {"espectro de Fourier: log da magnitude com a frequência zero no centro (spectrum.png)", opSpectrum},
+	"freqfilter":    {"filtro passa-baixas (ou -highpass) -kind no domínio da frequência, raio -cutoff (freqfilter.png)", opFreqFilter},
+	"notch":         {"tira ruído periódico zerando os picos -notch do espectro (ou os -n mais fortes com -auto) (notch.png)", opNotch},
+	"clahe":         {"equalização adaptativa com limite de contraste, grade -clahe-tiles e corte -clahe-clip (clahe.png)", opCLAHE},
+}
+
+// os pipelines entram em -ops como uma operação com uma saída só
+func init() {
+	for name, p := range pipelines {
+		operations[name] = p.operation()
+	}
+}
+
+func (p pipeline) operation() operation {
+	return operation{p.description, func(in opInput) ([]output, error) {
+		result, err := p.run(in.img, in.rec)
+		if err != nil {
+			return nil, err
+		}
+		return []output{{p.output, result}}, nil
+	}}
+}
+
+// parseOps valida a lista de -ops separada por vírgulas; nomes repetidos
+// rodam uma vez só.
+func parseOps(list string) ([]operation, error) {
+	var selected []operation
+	seen := map[string]bool{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		op, ok := operations[name]
+		if !ok {
+			return nil, fmt.Errorf("operação desconhecida %q; válidas: %s", name, strings.Join(opNames(), ", "))
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		selected = append(selected, op)
+	}
+	return selected, nil
+}
+
+func opNames() []string {
+	var names []string
+	for name := range operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// printOps lista as operações disponíveis (-ops list).
+func printOps() {
+	fmt.Println("Operações disponíveis:")
+	for _, name := range opNames() {
+		fmt.Printf("  %-13s %s\n", name, operations[name].description)
+	}
+}
+
+// parseBoxSizes lê os tamanhos de -box, ex: "3,5".
+func parseBoxSizes(s string) ([]int, error) {
+	var sizes []int
+	for _, field := range strings.Split(s, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || size < 1 {
+			return nil, fmt.Errorf("tamanho de filtro box inválido %q em %q", field, s)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// As operações abaixo são as mesmas que process roda sem -ops.
+
+func opCanny(in opInput) ([]output, error) {
+	fmt.Println("Aplicando Canny...")
+	opts := imaging.CannyOptions{Sigma: *cannySigma, Low: *cannyLow, High: *cannyHigh, Border: in.border}
+	canny, err := imaging.CannyStages(in.img, opts, in.rec.stageFunc("canny"))
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"canny.png", canny}}, nil
+}
+
+func opGradient(in opInput) ([]output, error) {
+	op, err := imaging.ParseGradientOperator(*gradOperator)
+	if err != nil {
+		return nil, err
+	}
+	mag, dir := imaging.Gradient(in.img, op)
+	return []output{
+		{"gradient_mag.png", scaleMagnitude(mag, magnitudeScale{*gradScale, *gradDivisor})},
+		{"gradient_dir.png", imaging.DirectionToGray(dir)},
+	}, nil
+}
+
+func opOtsu(in opInput) ([]output, error) {
+	fmt.Println("Aplicando Otsu...")
+	otsu, err := imaging.OtsuMask(in.img, in.roi)
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"otsu.png", otsu}}, nil
+}
+
+func opMarrHildreth(in opInput) ([]output, error) {
+	fmt.Println("Aplicando Marr-Hildreth...")
+	edges, err := imaging.MarrHildreth(in.img, *mhSigma, *mhSize, *mhSlope)
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"marr_hildreth.png", edges}}, nil
+}
+
+func opWatershed(in opInput) ([]output, error) {
+	fmt.Println("Aplicando Watershed...")
+	opts := imaging.WatershedOptions{Markers: *wsMarkers, Erode: *wsErode, BrightObjects: *wsBright, Sigma: *wsSigma}
+	result, err := imaging.Watershed(in.img, opts)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Regiões do watershed: %d\n", result.Regions)
+	return []output{{"watershed.png", result.Lines}, {"watershed_labels.png", labelImage(result.Labels)}}, nil
+}
+
+func opBackground(in opInput) ([]output, error) {
+	fmt.Println("Separando o fundo...")
+	background, err := imaging.BackgroundThreshold(in.img, *watershedBg)
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"background.png", background}}, nil
+}
+
+func opBox(in opInput) ([]output, error) {
+	sizes, err := parseBoxSizes(*boxSizes)
+	if err != nil {
+		return nil, err
+	}
+	var outputs []output
+	for _, size := range sizes {
+		filtered, err := imaging.BoxFilter(in.img, size, in.border)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, output{fmt.Sprintf("filtered_%dx%d.png", size, size), filtered})
+	}
+	return outputs, nil
+}
+
+func opGaussian(in opInput) ([]output, error) {
+	if *gaussianSigma <= 0 {
+		return nil, fmt.Errorf("-sigma deve ser positivo, não %g", *gaussianSigma)
+	}
+	fmt.Println("Aplicando o borrão gaussiano...")
+	return []output{{"gaussian.png", imaging.GaussianBlur(in.img, *gaussianSigma)}}, nil
+}
+
+func opAdaptive(in opInput) ([]output, error) {
+	fmt.Println("Aplicando o limiar adaptativo...")
+	binary, err := adaptiveThreshold(in.img, *adaptiveWindow, *adaptiveC, *adaptiveMethod)
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"adaptive.png", binary}}, nil
+}
+
+func opSauvola(in opInput) ([]output, error) {
+	if *adaptiveWindow < 1 {
+		return nil, fmt.Errorf("-window deve ser positiva, não %d", *adaptiveWindow)
+	}
+	fmt.Println("Aplicando Sauvola...")
+	return []output{{"sauvola.png", sauvolaThreshold(in.img, *adaptiveWindow, *sauvolaK)}}, nil
+}
+
+func opNiblack(in opInput) ([]output, error) {
+	if *adaptiveWindow < 1 {
+		return nil, fmt.Errorf("-window deve ser positiva, não %d", *adaptiveWindow)
+	}
+	fmt.Println("Aplicando Niblack...")
+	return []output{{"niblack.png", niblackThreshold(in.img, *adaptiveWindow, *niblackK)}}, nil
+}
+
+func opDistance(in opInput) ([]output, error) {
+	metric, err := imaging.ParseDistanceMetric(*distanceMetric)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("Calculando a transformada de distância...")
+	mask, err := imaging.OtsuMask(in.img, in.roi)
+	if err != nil {
+		return nil, err
+	}
+	dist := imaging.DistanceTransform(mask, metric, *distanceInvert)
+	return []output{{"distance.png", imaging.NormalizeToGray(dist)}}, nil
+}
+
+func opStretch(in opInput) ([]output, error) {
+	clip, err := parseFloatList(*stretchClip)
+	if err != nil {
+		return nil, err
+	}
+	if len(clip) != 2 {
+		return nil, fmt.Errorf("-clip precisa de dois percentis, ex: 1,99, não %q", *stretchClip)
+	}
+	fmt.Printf("Esticando o contraste entre os percentis %g e %g...\n", clip[0], clip[1])
+	result, err := imaging.ContrastStretch(in.img, clip[0], clip[1])
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"stretched.png", result}}, nil
+}
+
+func opSharpen(in opInput) ([]output, error) {
+	fmt.Println("Aplicando a máscara de nitidez...")
+	result, err := imaging.UnsharpMask(in.img, *sharpenSigma, *sharpenAmount)
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"sharpened.png", result}}, nil
+}
+
+func opLaplacianSharpen(in opInput) ([]output, error) {
+	fmt.Println("Aplicando o realce pelo laplaciano...")
+	result, err := imaging.LaplacianSharpen(in.img, *sharpenAmount, in.border)
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"sharpened_laplacian.png", result}}, nil
+}
+
+func opNLMeans(in opInput) ([]output, error) {
+	fmt.Println("Aplicando o non-local means...")
+	result, err := imaging.NLMeans(in.img, imaging.NLMeansOptions{H: *nlmH, Patch: *nlmPatch, Search: *nlmSearch})
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"nlmeans.png", result}}, nil
+}
+
+func opDiffusion(in opInput) ([]output, error) {
+	conduction, err := imaging.ParseConduction(*diffusionConduction)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Aplicando a difusão anisotrópica (%d iterações)...\n", *diffusionIter)
+	result, err := imaging.AnisotropicDiffusion(in.img, *diffusionIter, *diffusionKappa, *diffusionLambda, conduction)
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"diffused.png", result}}, nil
+}
+
+func opAlphaTrim(in opInput) ([]output, error) {
+	fmt.Printf("Aplicando a média alfa-aparada (d = %d)...\n", *trimD)
+	result, err := imaging.AlphaTrimmedMean(in.img, *restoreSize, *trimD)
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"alphatrim.png", result}}, nil
+}
+
+func opContraharmonic(in opInput) ([]output, error) {
+	fmt.Printf("Aplicando a média contra-harmônica (Q = %g)...\n", *contraharmonicQ)
+	result, err := imaging.ContraharmonicMean(in.img, *restoreSize, *contraharmonicQ)
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"contraharmonic.png", result}}, nil
+}
+
+func opKuwahara(in opInput) ([]output, error) {
+	fmt.Printf("Aplicando o filtro de Kuwahara %dx%d...\n", *kuwaharaSize, *kuwaharaSize)
+	result, err := imaging.Kuwahara(in.img, *kuwaharaSize)
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"kuwahara.png", result}}, nil
+}
+
+func opNoise(in opInput) ([]output, error) {
+	var noisy *image.Gray
+	var err error
+	switch *noiseType {
+	case "gaussian":
+		fmt.Printf("Somando ruído gaussiano (σ = %g)...\n", *noiseSigma)
+		noisy, err = synthetic.AddGaussianNoise(in.img, *noiseSigma, *noiseSeed)
+	case "sp":
+		fmt.Printf("Somando ruído sal e pimenta (densidade %g)...\n", *noiseDensity)
+		noisy, err = synthetic.AddSaltPepperNoise(in.img, *noiseDensity, *noiseSeed)
+	case "speckle":
+		fmt.Printf("Aplicando ruído speckle (σ = %g)...\n", *speckleSigma)
+		noisy, err = synthetic.AddSpeckleNoise(in.img, *speckleSigma, *noiseSeed)
+	case "poisson":
+		fmt.Println("Aplicando ruído de Poisson...")
+		noisy = synthetic.AddPoissonNoise(in.img, *noiseSeed)
+	default:
+		err = fmt.Errorf("tipo de ruído desconhecido %q, use gaussian, sp, speckle ou poisson", *noiseType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"noisy.png", noisy}}, nil
+}
+
+func opWiener(in opInput) ([]output, error) {
+	psf, err := loadPSF()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Aplicando a deconvolução de Wiener (K = %g)...\n", *wienerK)
+	result, err := imaging.WienerDeconvolve(in.img, psf, *wienerK)
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"wiener.png", result}}, nil
+}
+
+func opInverse(in opInput) ([]output, error) {
+	psf, err := loadPSF()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Aplicando o filtro inverso (corte %g)...\n", *inverseThreshold)
+	result, err := imaging.InverseFilter(in.img, psf, *inverseThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"inverse.png", result}}, nil
+}
+
+// loadPSF lê o borrão a desfazer de -psf ou, sem arquivo, monta a
+// gaussiana de -psf-sigma.
+func loadPSF() ([][]float64, error) {
+	if *psfPath != "" {
+		return loadKernel(*psfPath, true)
+	}
+	if *psfSigma <= 0 {
+		return nil, fmt.Errorf("-psf-sigma deve ser positivo, não %g", *psfSigma)
+	}
+	return imaging.GaussianKernel(0, *psfSigma), nil
+}
+
+func opSpectrum(in opInput) ([]output, error) {
+	fmt.Println("Calculando o espectro de Fourier...")
+	return []output{{"spectrum.png", dft.SpectrumImage(dft.FFTOfGray(in.img))}}, nil
+}
+
+func opFreqFilter(in opInput) ([]output, error) {
+	kind, err := imaging.ParseFrequencyFilterKind(*freqKind)
+	if err != nil {
+		return nil, err
+	}
+	pass := "passa-baixas"
+	if *freqHighpass {
+		pass = "passa-altas"
+	}
+	fmt.Printf("Aplicando o filtro %s %s (corte %g)...\n", pass, *freqKind, *freqCutoff)
+	result, err := imaging.FrequencyFilter(in.img, kind, *freqCutoff, *freqOrder, *freqHighpass)
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"freqfilter.png", result}}, nil
+}
+
+func opNotch(in opInput) ([]output, error) {
+	var centers []image.Point
+	if *notchAuto {
+		centers = imaging.FindSpectralPeaks(in.img, *notchCount, *notchThreshold, *notchMinRadius)
+		fmt.Printf("Picos encontrados no espectro: %v\n", centers)
+	} else {
+		var err error
+		if centers, err = parseNotchCenters(*notchCenters); err != nil {
+			return nil, err
+		}
+	}
+	fmt.Printf("Aplicando o filtro notch (%d pares, raio %g)...\n", len(centers), *notchRadius)
+	result, err := imaging.NotchFilter(in.img, centers, *notchRadius)
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"notch.png", result}}, nil
+}
+
+// parseNotchCenters lê os centros de -notch no formato "u1,v1 u2,v2 ...".
+func parseNotchCenters(s string) ([]image.Point, error) {
+	var centers []image.Point
+	for _, field := range strings.Fields(s) {
+		uv := strings.Split(field, ",")
+		if len(uv) != 2 {
+			return nil, fmt.Errorf("centro de notch inválido %q, use u,v", field)
+		}
+		u, errU := strconv.Atoi(uv[0])
+		v, errV := strconv.Atoi(uv[1])
+		if errU != nil || errV != nil {
+			return nil, fmt.Errorf("centro de notch inválido %q", field)
+		}
+		centers = append(centers, image.Pt(u, v))
+	}
+	if len(centers) == 0 {
+		return nil, fmt.Errorf("-ops notch precisa dos centros em -notch ou de -auto")
+	}
+	return centers, nil
+}
+
+func opCLAHE(in opInput) ([]output, error) {
+	fmt.Printf("Aplicando CLAHE (%dx%d blocos, corte %g)...\n", *claheTiles, *claheTiles, *claheClip)
+	result, err := imaging.CLAHE(in.img, *claheTiles, *claheClip)
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"clahe.png", result}}, nil
+}
+
+// rankOp monta um filtro de ordem com a janela de -rank-size.
+func rankOp(name string, apply func(*image.Gray, int) (*image.Gray, error)) func(in opInput) ([]output, error) {
+	return func(in opInput) ([]output, error) {
+		fmt.Printf("Aplicando o filtro %s %dx%d...\n", strings.TrimSuffix(name, ".png"), *rankSize, *rankSize)
+		result, err := apply(in.img, *rankSize)
+		if err != nil {
+			return nil, err
+		}
+		return []output{{name, result}}, nil
+	}
+}
+
+// pointOp monta uma transformação de intensidade sem parâmetros.
+func pointOp(name string, apply func(*image.Gray) *image.Gray) func(in opInput) ([]output, error) {
+	return func(in opInput) ([]output, error) {
+		fmt.Printf("Aplicando %s...\n", strings.TrimSuffix(name, ".png"))
+		return []output{{name, apply(in.img)}}, nil
+	}
+}
+
+// morphOp monta uma operação morfológica com o elemento de -se.
+func morphOp(name string, apply func(*image.Gray, imaging.StructuringElement) *image.Gray) func(in opInput) ([]output, error) {
+	return func(in opInput) ([]output, error) {
+		se, err := imaging.ParseStructuringElement(*morphSE)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("Aplicando %s com %s...\n", strings.TrimSuffix(name, ".png"), *morphSE)
+		return []output{{name, apply(in.img, se)}}, nil
+	}
+}
+
+// whiteObjects fixa a polaridade da morfologia em tons de cinza usual
+// (claro é objeto), a mesma do top-hat.
+func whiteObjects(fn func(*image.Gray, imaging.StructuringElement, imaging.Polarity) *image.Gray) func(*image.Gray, imaging.StructuringElement) *image.Gray {
+	return func(img *image.Gray, se imaging.StructuringElement) *image.Gray {
+		return fn(img, se, imaging.WhiteObjects)
+	}
+}
+
+func opLabel(in opInput) ([]output, error) {
+	mask, err := imaging.OtsuMask(in.img, in.roi)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("Rotulando os objetos...")
+	labels, n, err := imaging.LabelComponents(mask, *connectivity, imaging.BlackObjects)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Componentes: %d\n", n)
+	return []output{{"labels.png", labelImage(labels)}}, nil
+}
+
+func opFillHoles(in opInput) ([]output, error) {
+	mask, err := imaging.OtsuMask(in.img, in.roi)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("Preenchendo os buracos...")
+	return []output{{"filled.png", imaging.FillHoles(mask, imaging.BlackObjects)}}, nil
+}
+
+func opSkeleton(in opInput) ([]output, error) {
+	mask, err := imaging.OtsuMask(in.img, in.roi)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("Afinando os objetos...")
+	return []output{{"skeleton.png", skeletonize(mask)}}, nil
+}
+
+// skeletonize afina os objetos escuros (0) de uma máscara do Otsu; o
+// esqueleto sai branco em fundo preto, a convenção de imaging.Skeletonize.
+func skeletonize(mask *image.Gray) *image.Gray {
+	return imaging.Skeletonize(imaging.Negative(mask))
+}
+
+func opSegment(in opInput) ([]output, error) {
+	breakpoints, levels, err := imaging.ParseSliceTable(*sliceTable)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("Aplicando segmentação de intensidade...")
+	result, err := imaging.IntensitySlice(in.img, breakpoints, levels)
+	if err != nil {
+		return nil, err
+	}
+	return []output{{"segmented.png", result}}, nil
+}
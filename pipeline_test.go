@@ -0,0 +1,479 @@
+package main
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"processing-images/imaging"
+)
+
+// withFlag troca o valor de uma flag de texto durante o teste.
+func withFlag(t *testing.T, flag *string, value string) {
+	t.Helper()
+	old := *flag
+	*flag = value
+	t.Cleanup(func() { *flag = old })
+}
+
+func TestParseOps(t *testing.T) {
+	tests := []struct {
+		list    string
+		n       int
+		wantErr string
+	}{
+		{"otsu", 1, ""},
+		{"canny, otsu ,watershed", 3, ""},
+		{"otsu,otsu", 1, ""},
+		{"otsu,sobel", 0, `operação desconhecida "sobel"`},
+		{"", 0, `operação desconhecida ""`},
+	}
+	for _, tt := range tests {
+		ops, err := parseOps(tt.list)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%q: erro %v, esperado %q", tt.list, err, tt.wantErr)
+			}
+			// a mensagem lista as operações válidas
+			if err != nil && !strings.Contains(err.Error(), "canny, ") {
+				t.Errorf("%q: a mensagem não lista as operações: %v", tt.list, err)
+			}
+			continue
+		}
+		if err != nil || len(ops) != tt.n {
+			t.Errorf("%q: %d operações, %v; esperado %d", tt.list, len(ops), err, tt.n)
+		}
+	}
+}
+
+func TestParseBoxSizes(t *testing.T) {
+	if sizes, err := parseBoxSizes("3, 5,7"); err != nil || len(sizes) != 3 || sizes[1] != 5 {
+		t.Errorf("3, 5,7: %v, %v", sizes, err)
+	}
+	for _, s := range []string{"", "3,x", "0", "-3"} {
+		if _, err := parseBoxSizes(s); err == nil {
+			t.Errorf("%q: sem erro", s)
+		}
+	}
+}
+
+func TestRunSelectedOps(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "entrada.png")
+	if err := saveImage(input, stepImage(32)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		ops  string
+		want []string
+	}{
+		{"otsu", []string{"otsu.png"}},
+		{"canny,otsu", []string{"canny.png", "otsu.png"}},
+	}
+	for i, tt := range tests {
+		out := filepath.Join(dir, "saidas", strconv.Itoa(i), "novo")
+		withFlag(t, ops, tt.ops)
+		withFlag(t, outDir, out)
+		if err := run(input); err != nil {
+			t.Fatalf("%s: %v", tt.ops, err)
+		}
+		entries, err := os.ReadDir(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, e := range entries {
+			got = append(got, e.Name())
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("-ops %s: arquivos %v, esperado %v", tt.ops, got, tt.want)
+		}
+	}
+
+	withFlag(t, ops, "otsu,nada")
+	if err := run(input); err == nil || !strings.Contains(err.Error(), "nada") {
+		t.Errorf("operação desconhecida: %v", err)
+	}
+}
+
+func TestOpSkeleton(t *testing.T) {
+	// um "H" escuro em papel claro: o esqueleto sai branco, numa peça só
+	img := uniformGray(40, 220)
+	for y := 5; y < 35; y++ {
+		for x := 5; x < 35; x++ {
+			if x < 11 || x >= 29 || (y >= 17 && y < 23) {
+				img.Pix[y*img.Stride+x] = 30
+			}
+		}
+	}
+	outputs, err := opSkeleton(opInput{img: img})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outputs) != 1 || outputs[0].name != "skeleton.png" {
+		t.Fatalf("saídas %v", outputs)
+	}
+	skel := outputs[0].img.(*image.Gray)
+	_, n, err := imaging.LabelComponents(skel, 8, imaging.WhiteObjects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a barra tem 6 linhas, então a linha média é a 19 ou a 20
+	bar := max(skel.GrayAt(20, 19).Y, skel.GrayAt(20, 20).Y)
+	if n != 1 || bar != 255 || skel.GrayAt(0, 0).Y != 0 {
+		t.Errorf("%d componentes no esqueleto, barra %d e canto %d", n, bar, skel.GrayAt(0, 0).Y)
+	}
+}
+
+func TestOpStretch(t *testing.T) {
+	img := gradientImage(64, 32)
+	tests := []struct {
+		clip string
+		ok   bool
+	}{
+		{"1,99", true},
+		{"0,100", true},
+		{"5", false},
+		{"1,50,99", false},
+		{"a,b", false},
+		{"99,1", false},
+	}
+	for _, tt := range tests {
+		withFlag(t, stretchClip, tt.clip)
+		outputs, err := opStretch(opInput{img: img})
+		if (err == nil) != tt.ok {
+			t.Errorf("-clip %s: erro %v", tt.clip, err)
+			continue
+		}
+		if tt.ok && (len(outputs) != 1 || outputs[0].name != "stretched.png") {
+			t.Errorf("-clip %s: saídas %v", tt.clip, outputs)
+		}
+	}
+}
+
+func TestOpGradient(t *testing.T) {
+	img := gradientImage(32, 16)
+	tests := []struct {
+		operator string
+		ok       bool
+	}{
+		{"sobel", true},
+		{"prewitt", true},
+		{"scharr", true},
+		{"roberts", true},
+		{"laplace", false},
+	}
+	for _, tt := range tests {
+		withFlag(t, gradOperator, tt.operator)
+		outputs, err := opGradient(opInput{img: img})
+		if (err == nil) != tt.ok {
+			t.Errorf("-operator %s: erro %v", tt.operator, err)
+			continue
+		}
+		if tt.ok && (len(outputs) != 2 || outputs[0].name != "gradient_mag.png" || outputs[1].name != "gradient_dir.png") {
+			t.Errorf("-operator %s: saídas %v", tt.operator, outputs)
+		}
+	}
+}
+
+func TestOpSharpen(t *testing.T) {
+	oldSigma, oldAmount := *sharpenSigma, *sharpenAmount
+	t.Cleanup(func() { *sharpenSigma, *sharpenAmount = oldSigma, oldAmount })
+	img := gradientImage(32, 16)
+	tests := []struct {
+		sigma, amount float64
+		ok            bool
+	}{
+		{1, 1, true},
+		{2.5, 0, true},
+		{0, 1, false},
+		{1, -1, false},
+	}
+	for _, tt := range tests {
+		*sharpenSigma, *sharpenAmount = tt.sigma, tt.amount
+		outputs, err := opSharpen(opInput{img: img})
+		if (err == nil) != tt.ok {
+			t.Errorf("σ=%g, quantidade %g: erro %v", tt.sigma, tt.amount, err)
+			continue
+		}
+		if tt.ok && (len(outputs) != 1 || outputs[0].name != "sharpened.png") {
+			t.Errorf("σ=%g, quantidade %g: saídas %v", tt.sigma, tt.amount, outputs)
+		}
+		outputs, err = opLaplacianSharpen(opInput{img: img, border: imaging.BorderReplicate})
+		if (err == nil) != (tt.amount >= 0) {
+			t.Errorf("laplaciano com quantidade %g: erro %v", tt.amount, err)
+		} else if err == nil && outputs[0].name != "sharpened_laplacian.png" {
+			t.Errorf("laplaciano: saídas %v", outputs)
+		}
+	}
+}
+
+func TestOpNLMeans(t *testing.T) {
+	oldH, oldPatch, oldSearch := *nlmH, *nlmPatch, *nlmSearch
+	t.Cleanup(func() { *nlmH, *nlmPatch, *nlmSearch = oldH, oldPatch, oldSearch })
+	img := gradientImage(24, 16)
+	tests := []struct {
+		h             float64
+		patch, search int
+		ok            bool
+	}{
+		{10, 7, 21, true},
+		{5, 3, 5, true},
+		{0, 7, 21, false},
+		{10, 4, 21, false},
+		{10, 7, 0, false},
+	}
+	for _, tt := range tests {
+		*nlmH, *nlmPatch, *nlmSearch = tt.h, tt.patch, tt.search
+		outputs, err := opNLMeans(opInput{img: img})
+		if (err == nil) != tt.ok {
+			t.Errorf("h=%g, patch %d, busca %d: erro %v", tt.h, tt.patch, tt.search, err)
+			continue
+		}
+		if tt.ok && (len(outputs) != 1 || outputs[0].name != "nlmeans.png") {
+			t.Errorf("h=%g: saídas %v", tt.h, outputs)
+		}
+	}
+}
+
+func TestOpRestoration(t *testing.T) {
+	oldSize, oldD, oldQ := *restoreSize, *trimD, *contraharmonicQ
+	t.Cleanup(func() { *restoreSize, *trimD, *contraharmonicQ = oldSize, oldD, oldQ })
+	img := gradientImage(24, 16)
+	tests := []struct {
+		size, d  int
+		q        float64
+		trimOK   bool
+		contraOK bool
+	}{
+		{3, 2, 1.5, true, true},
+		{5, 12, -1.5, true, true},
+		{3, 5, 0, false, true},
+		{0, 0, 1, false, false},
+	}
+	for _, tt := range tests {
+		*restoreSize, *trimD, *contraharmonicQ = tt.size, tt.d, tt.q
+		outputs, err := opAlphaTrim(opInput{img: img})
+		if (err == nil) != tt.trimOK {
+			t.Errorf("alphatrim %d, d=%d: erro %v", tt.size, tt.d, err)
+		} else if err == nil && outputs[0].name != "alphatrim.png" {
+			t.Errorf("alphatrim: saídas %v", outputs)
+		}
+		outputs, err = opContraharmonic(opInput{img: img})
+		if (err == nil) != tt.contraOK {
+			t.Errorf("contraharm %d, q=%g: erro %v", tt.size, tt.q, err)
+		} else if err == nil && outputs[0].name != "contraharmonic.png" {
+			t.Errorf("contraharm: saídas %v", outputs)
+		}
+	}
+}
+
+func TestOpKuwahara(t *testing.T) {
+	old := *kuwaharaSize
+	t.Cleanup(func() { *kuwaharaSize = old })
+	img := gradientImage(24, 16)
+	for _, tt := range []struct {
+		size int
+		ok   bool
+	}{{5, true}, {2, true}, {0, false}} {
+		*kuwaharaSize = tt.size
+		outputs, err := opKuwahara(opInput{img: img})
+		if (err == nil) != tt.ok {
+			t.Errorf("tamanho %d: erro %v", tt.size, err)
+			continue
+		}
+		if tt.ok && (len(outputs) != 1 || outputs[0].name != "kuwahara.png") {
+			t.Errorf("tamanho %d: saídas %v", tt.size, outputs)
+		}
+	}
+}
+
+func TestOpNoise(t *testing.T) {
+	oldSigma, oldDensity, oldSeed := *noiseSigma, *noiseDensity, *noiseSeed
+	t.Cleanup(func() { *noiseSigma, *noiseDensity, *noiseSeed = oldSigma, oldDensity, oldSeed })
+	img := gradientImage(32, 16)
+	tests := []struct {
+		kind           string
+		sigma, density float64
+		ok             bool
+	}{
+		{"gaussian", 10, 0.05, true},
+		{"sp", -1, 0.2, true},
+		{"gaussian", -1, 0.05, false},
+		{"sp", 10, 2, false},
+		{"speckle", 10, 0.05, true},
+		{"poisson", 10, 0.05, true},
+		{"uniform", 10, 0.05, false},
+	}
+	for _, tt := range tests {
+		withFlag(t, noiseType, tt.kind)
+		*noiseSigma, *noiseDensity, *noiseSeed = tt.sigma, tt.density, 3
+		outputs, err := opNoise(opInput{img: img})
+		if (err == nil) != tt.ok {
+			t.Errorf("%s, σ=%g, densidade %g: erro %v", tt.kind, tt.sigma, tt.density, err)
+			continue
+		}
+		if !tt.ok {
+			continue
+		}
+		if len(outputs) != 1 || outputs[0].name != "noisy.png" {
+			t.Fatalf("%s: saídas %v", tt.kind, outputs)
+		}
+		again, _ := opNoise(opInput{img: img})
+		if string(again[0].img.(*image.Gray).Pix) != string(outputs[0].img.(*image.Gray).Pix) {
+			t.Errorf("%s: a mesma -noise-seed gerou ruídos diferentes", tt.kind)
+		}
+	}
+}
+
+func TestOpDeconvolve(t *testing.T) {
+	oldSigma, oldK, oldThreshold := *psfSigma, *wienerK, *inverseThreshold
+	t.Cleanup(func() { *psfSigma, *wienerK, *inverseThreshold = oldSigma, oldK, oldThreshold })
+	img := gradientImage(32, 16)
+	dir := t.TempDir()
+	kernelPath := filepath.Join(dir, "psf.txt")
+	if err := os.WriteFile(kernelPath, []byte("1 1 1\n1 1 1\n1 1 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		psf       string
+		sigma, k  float64
+		threshold float64
+		wienerOK  bool
+		inverseOK bool
+	}{
+		{"gaussiana", "", 2, 0.001, 0.05, true, true},
+		{"arquivo", kernelPath, 0, 0.01, 0.1, true, true},
+		{"σ zero", "", 0, 0.001, 0.05, false, false},
+		{"k negativo", "", 2, -1, 0.05, false, true},
+		{"corte zero", "", 2, 0.001, 0, true, false},
+		{"arquivo ausente", filepath.Join(dir, "nada.txt"), 2, 0.001, 0.05, false, false},
+	}
+	for _, tt := range tests {
+		withFlag(t, psfPath, tt.psf)
+		*psfSigma, *wienerK, *inverseThreshold = tt.sigma, tt.k, tt.threshold
+		outputs, err := opWiener(opInput{img: img})
+		if (err == nil) != tt.wienerOK {
+			t.Errorf("%s: wiener com erro %v", tt.name, err)
+		} else if err == nil && outputs[0].name != "wiener.png" {
+			t.Errorf("%s: saídas %v", tt.name, outputs)
+		}
+		outputs, err = opInverse(opInput{img: img})
+		if (err == nil) != tt.inverseOK {
+			t.Errorf("%s: inverso com erro %v", tt.name, err)
+		} else if err == nil && outputs[0].name != "inverse.png" {
+			t.Errorf("%s: saídas %v", tt.name, outputs)
+		}
+	}
+}
+
+func TestOpSpectrum(t *testing.T) {
+	for _, size := range []image.Point{{32, 32}, {30, 21}} {
+		img := gradientImage(size.X, size.Y)
+		outputs, err := opSpectrum(opInput{img: img})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(outputs) != 1 || outputs[0].name != "spectrum.png" {
+			t.Fatalf("%v: saídas %v", size, outputs)
+		}
+		// a frequência zero, no centro, é o maior valor
+		spectrum := outputs[0].img.(*image.Gray)
+		if spectrum.Bounds().Size() != size || spectrum.GrayAt(size.X/2, size.Y/2).Y != 255 {
+			t.Errorf("%v: limites %v e centro %d", size, spectrum.Bounds(), spectrum.GrayAt(size.X/2, size.Y/2).Y)
+		}
+	}
+}
+
+func TestOpFreqFilter(t *testing.T) {
+	oldCutoff, oldOrder, oldHighpass := *freqCutoff, *freqOrder, *freqHighpass
+	t.Cleanup(func() { *freqCutoff, *freqOrder, *freqHighpass = oldCutoff, oldOrder, oldHighpass })
+	img := gradientImage(32, 16)
+	tests := []struct {
+		kind     string
+		cutoff   float64
+		order    int
+		highpass bool
+		ok       bool
+	}{
+		{"butterworth", 30, 2, false, true},
+		{"ideal", 5, 0, true, true},
+		{"gaussian", 10, 0, false, true},
+		{"butterworth", 10, 0, false, false},
+		{"gaussian", 0, 2, false, false},
+		{"box", 10, 2, false, false},
+	}
+	for _, tt := range tests {
+		withFlag(t, freqKind, tt.kind)
+		*freqCutoff, *freqOrder, *freqHighpass = tt.cutoff, tt.order, tt.highpass
+		outputs, err := opFreqFilter(opInput{img: img})
+		if (err == nil) != tt.ok {
+			t.Errorf("%s, corte %g, ordem %d: erro %v", tt.kind, tt.cutoff, tt.order, err)
+			continue
+		}
+		if tt.ok && (len(outputs) != 1 || outputs[0].name != "freqfilter.png") {
+			t.Errorf("%s: saídas %v", tt.kind, outputs)
+		}
+	}
+}
+
+func TestParseNotchCenters(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []image.Point
+		ok   bool
+	}{
+		{"12,9", []image.Point{{12, 9}}, true},
+		{"20,0  -3,15", []image.Point{{20, 0}, {-3, 15}}, true},
+		{"", nil, false},
+		{"12", nil, false},
+		{"1,2,3", nil, false},
+		{"a,9", nil, false},
+		{"1.5,2", nil, false},
+	}
+	for _, tt := range tests {
+		got, err := parseNotchCenters(tt.in)
+		if (err == nil) != tt.ok || len(got) != len(tt.want) {
+			t.Errorf("%q: %v, %v", tt.in, got, err)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%q: %v, esperado %v", tt.in, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestOpNotch(t *testing.T) {
+	oldAuto, oldRadius := *notchAuto, *notchRadius
+	t.Cleanup(func() { *notchAuto, *notchRadius = oldAuto, oldRadius })
+	img := gradientImage(32, 16)
+	tests := []struct {
+		centers string
+		auto    bool
+		radius  float64
+		ok      bool
+	}{
+		{"4,2", false, 3, true},
+		{"", true, 3, true},
+		{"", false, 3, false},
+		{"4,2", false, 0, false},
+	}
+	for _, tt := range tests {
+		withFlag(t, notchCenters, tt.centers)
+		*notchAuto, *notchRadius = tt.auto, tt.radius
+		outputs, err := opNotch(opInput{img: img})
+		if (err == nil) != tt.ok {
+			t.Errorf("-notch %q, -auto %v, raio %g: erro %v", tt.centers, tt.auto, tt.radius, err)
+			continue
+		}
+		if tt.ok && (len(outputs) != 1 || outputs[0].name != "notch.png") {
+			t.Errorf("-notch %q: saídas %v", tt.centers, outputs)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// imageMeta guarda os metadados da imagem de entrada que devem acompanhar as
+// saídas pelo pipeline.
+type imageMeta struct {
+	// pixels por metro (chunk pHYs); 0 quando desconhecido
+	ppmX, ppmY uint32
+}
+
+// hasPhysical informa se a resolução física é conhecida.
+func (m imageMeta) hasPhysical() bool {
+	return m.ppmX > 0 && m.ppmY > 0
+}
+
+// micronsPerPixel devolve o tamanho de um pixel em µm nos dois eixos.
+func (m imageMeta) micronsPerPixel() (float64, float64) {
+	if !m.hasPhysical() {
+		return 0, 0
+	}
+	return 1e6 / float64(m.ppmX), 1e6 / float64(m.ppmY)
+}
+
+// dpiToPPM converte pontos por polegada em pixels por metro.
+func dpiToPPM(dpi float64) uint32 {
+	return uint32(math.Round(dpi / 0.0254))
+}
+
+// readPNGPhys lê o chunk pHYs de um PNG sem decodificar os pixels. Só a
+// unidade "metro" é aceita; a unidade desconhecida indica apenas proporção.
+func readPNGPhys(data []byte) (ppmX, ppmY uint32, ok bool) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return 0, 0, false
+	}
+
+	pos := len(pngSignature)
+	for pos+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		kind := string(data[pos+4 : pos+8])
+		if length < 0 || pos+12+length > len(data) {
+			return 0, 0, false
+		}
+		chunk := data[pos+8 : pos+8+length]
+
+		switch kind {
+		case "pHYs":
+			if length != 9 || chunk[8] != 1 {
+				return 0, 0, false
+			}
+			return binary.BigEndian.Uint32(chunk), binary.BigEndian.Uint32(chunk[4:]), true
+		case "IDAT", "IEND":
+			// o pHYs precisa vir antes dos dados
+			return 0, 0, false
+		}
+		pos += 12 + length
+	}
+
+	return 0, 0, false
+}
+
+// writePNGWithPhys copia um PNG já codificado inserindo o chunk pHYs logo
+// depois do IHDR.
+func writePNGWithPhys(w io.Writer, encoded []byte, meta imageMeta) error {
+	const ihdrEnd = 8 + 4 + 4 + 13 + 4
+	if !meta.hasPhysical() || len(encoded) < ihdrEnd {
+		_, err := w.Write(encoded)
+		return err
+	}
+
+	payload := make([]byte, 9)
+	binary.BigEndian.PutUint32(payload, meta.ppmX)
+	binary.BigEndian.PutUint32(payload[4:], meta.ppmY)
+	payload[8] = 1 // metro
+
+	chunk := make([]byte, 0, 12+len(payload))
+	chunk = binary.BigEndian.AppendUint32(chunk, uint32(len(payload)))
+	chunk = append(chunk, "pHYs"...)
+	chunk = append(chunk, payload...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	for _, part := range [][]byte{encoded[:ihdrEnd], chunk, encoded[ihdrEnd:]} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
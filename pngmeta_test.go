@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/csv"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"processing-images/imaging"
+)
+
+// testdata/dpi300.png é um PNG 64x64 com pHYs de 300 DPI (11811 pixels por
+// metro) e um quadrado preto de 20x20 pixels.
+const dpi300PPM = 11811
+
+func TestPNGPhysRoundTrip(t *testing.T) {
+	img, meta, err := decodeImage("testdata/dpi300.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.ppmX != dpi300PPM || meta.ppmY != dpi300PPM {
+		t.Fatalf("pHYs lido %dx%d, esperado %d", meta.ppmX, meta.ppmY, dpi300PPM)
+	}
+
+	tests := []struct {
+		name string
+		img  image.Image
+		meta imageMeta
+		ok   bool
+	}{
+		{"mesma resolução", grayscale(img), meta, true},
+		{"anisotrópica", grayscale(img), imageMeta{ppmX: 11811, ppmY: 5906}, true},
+		{"sem resolução", grayscale(img), imageMeta{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.png")
+			if err := saveImageOptions(path, tt.img, tt.meta, defaultEncodeOptions); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ppmX, ppmY, ok := readPNGPhys(data)
+			if ok != tt.ok || ppmX != tt.meta.ppmX || ppmY != tt.meta.ppmY {
+				t.Errorf("pHYs gravado %dx%d (%v), esperado %dx%d (%v)", ppmX, ppmY, ok, tt.meta.ppmX, tt.meta.ppmY, tt.ok)
+			}
+			// o PNG com o chunk inserido continua decodificável
+			if _, _, err := decodeImage(path); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestDPIConversion(t *testing.T) {
+	tests := []struct {
+		dpi float64
+		ppm uint32
+		um  float64
+	}{
+		{300, 11811, 84.667},
+		{72, 2835, 352.734},
+		{600, 23622, 42.333},
+	}
+	for _, tt := range tests {
+		ppm := dpiToPPM(tt.dpi)
+		if ppm != tt.ppm {
+			t.Errorf("dpiToPPM(%g) = %d, esperado %d", tt.dpi, ppm, tt.ppm)
+		}
+		umX, umY := imageMeta{ppmX: ppm, ppmY: ppm}.micronsPerPixel()
+		if math.Abs(umX-tt.um) > 1e-3 || umY != umX {
+			t.Errorf("%g DPI: %g x %g µm/pixel, esperado %g", tt.dpi, umX, umY, tt.um)
+		}
+	}
+}
+
+func TestRegionsCSVPhysicalUnits(t *testing.T) {
+	img, meta, err := decodeImage("testdata/dpi300.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mask, err := imaging.OtsuMask(grayscale(img), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels, _, err := imaging.LabelComponents(mask, 8, imaging.BlackObjects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	regions := imaging.RegionProps(labels)
+	umX, umY := meta.micronsPerPixel()
+
+	tests := []struct {
+		name     string
+		umX, umY float64
+		columns  map[string]float64 // coluna -> valor esperado na linha do quadrado
+	}{
+		{"sem resolução", 0, 0, map[string]float64{"area": 400}},
+		{"300 DPI", umX, umY, map[string]float64{
+			"area":          400,
+			"area_mm2":      400 * 0.0254 / 300 * 0.0254 / 300 * 1e6,
+			"centroid_x_um": 19.5 * 25400 / 300,
+			"centroid_y_um": 19.5 * 25400 / 300,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "objects.csv")
+			if err := writeRegionsCSV(path, regions, tt.umX, tt.umY); err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			records, err := csv.NewReader(f).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != 2 {
+				t.Fatalf("%d linhas, esperado o cabeçalho e um objeto", len(records))
+			}
+			header := map[string]int{}
+			for i, name := range records[0] {
+				header[name] = i
+			}
+			if _, physical := header["area_mm2"]; physical != (tt.umX > 0) {
+				t.Errorf("coluna area_mm2 presente = %v com %g µm/pixel", physical, tt.umX)
+			}
+			for column, want := range tt.columns {
+				i, ok := header[column]
+				if !ok {
+					t.Fatalf("falta a coluna %s", column)
+				}
+				got, err := strconv.ParseFloat(records[1][i], 64)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if math.Abs(got-want) > want*1e-3 {
+					t.Errorf("%s = %g, esperado %g", column, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	_ "net/http/pprof"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// startProfiling inicia o perfil de CPU (se pedido) e devolve a função que
+// encerra os perfis. A função deve ser chamada com defer para que os
+// arquivos sejam gravados mesmo quando o processamento falha.
+func startProfiling(cpuPath, memPath string) (func() error, error) {
+	var cpuFile *os.File
+	if cpuPath != "" {
+		f, err := os.Create(cpuPath)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao criar o perfil de CPU: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("erro ao iniciar o perfil de CPU: %w", err)
+		}
+		cpuFile = f
+	}
+
+	stopped := false
+	stop := func() error {
+		if stopped {
+			return nil
+		}
+		stopped = true
+
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			if err := cpuFile.Close(); err != nil {
+				return fmt.Errorf("erro ao gravar o perfil de CPU: %w", err)
+			}
+		}
+
+		if memPath != "" {
+			f, err := os.Create(memPath)
+			if err != nil {
+				return fmt.Errorf("erro ao criar o perfil de memória: %w", err)
+			}
+			defer f.Close()
+			runtime.GC() // atualiza as estatísticas de alocação
+			if err := pprof.WriteHeapProfile(f); err != nil {
+				return fmt.Errorf("erro ao gravar o perfil de memória: %w", err)
+			}
+		}
+		return nil
+	}
+
+	return stop, nil
+}
+
+// serveProfiling expõe net/http/pprof em segundo plano.
+func serveProfiling(addr string) {
+	go func() {
+		log.Printf("pprof em http://%s/debug/pprof/", addr)
+		if err := http.ListenAndServe(addr, nil); err != nil {
+			log.Printf("erro no servidor pprof: %v", err)
+		}
+	}()
+}
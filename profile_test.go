@@ -0,0 +1,95 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"processing-images/imaging"
+	"processing-images/synthetic"
+)
+
+// Os perfis do pprof são protobuf comprimidos com gzip: basta conseguir
+// descomprimir um conteúdo não vazio.
+func TestStartProfiling(t *testing.T) {
+	tests := []struct {
+		name     string
+		cpu, mem bool
+	}{
+		{"cpu", true, false},
+		{"memória", false, true},
+		{"os dois", true, true},
+		{"nenhum", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var cpuPath, memPath string
+			if tt.cpu {
+				cpuPath = filepath.Join(dir, "cpu.prof")
+			}
+			if tt.mem {
+				memPath = filepath.Join(dir, "mem.prof")
+			}
+			stop, err := startProfiling(cpuPath, memPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			img := synthetic.Noise(256, 256, 128, 40, 1)
+			for i := 0; i < 5; i++ {
+				imaging.GaussianBlur(img, 3)
+			}
+			if err := stop(); err != nil {
+				t.Fatal(err)
+			}
+			// a segunda chamada (o defer de run) não faz nada
+			if err := stop(); err != nil {
+				t.Fatal(err)
+			}
+			for _, path := range []string{cpuPath, memPath} {
+				if path != "" {
+					checkProfile(t, path)
+				}
+			}
+			entries, _ := os.ReadDir(dir)
+			if want := btoi(tt.cpu) + btoi(tt.mem); len(entries) != want {
+				t.Errorf("%d arquivos gravados, esperado %d", len(entries), want)
+			}
+		})
+	}
+}
+
+func TestStartProfilingBadPath(t *testing.T) {
+	if _, err := startProfiling(filepath.Join(t.TempDir(), "nao", "existe", "cpu.prof"), ""); err == nil {
+		t.Error("esperava erro ao criar o perfil num diretório que não existe")
+	}
+}
+
+func checkProfile(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("%s não é um perfil do pprof: %v", path, err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	if len(data) == 0 {
+		t.Errorf("%s está vazio", path)
+	}
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
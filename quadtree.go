@@ -0,0 +1,103 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// QuadTree é um bloco da decomposição: folhas não têm filhos e são
+// representadas pela média dos seus pixels.
+type QuadTree struct {
+	Bounds   image.Rectangle
+	Mean     float64
+	Std      float64
+	Children []*QuadTree
+}
+
+// quadtreeDecompose divide a imagem em quadrantes até o desvio padrão do
+// bloco ficar em maxStd ou abaixo, ou até o bloco não poder mais ser dividido
+// sem ficar menor que minBlock. Médias e desvios saem das tabelas de somas,
+// então cada bloco custa O(1). Um lado que ainda comporta dois blocos
+// mínimos é dividido mesmo que o outro não comporte.
+func quadtreeDecompose(img *image.Gray, maxStd float64, minBlock int) *QuadTree {
+	minBlock = max(minBlock, 1)
+	sum, sq := integralImage(img), integralImageSquared(img)
+	var build func(r image.Rectangle) *QuadTree
+	build = func(r image.Rectangle) *QuadTree {
+		n := float64(r.Dx() * r.Dy())
+		node := &QuadTree{Bounds: r.Add(img.Bounds().Min)}
+		if n == 0 {
+			return node
+		}
+		node.Mean = float64(windowSum(sum, r)) / n
+		node.Std = math.Sqrt(math.Max(float64(windowSum(sq, r))/n-node.Mean*node.Mean, 0))
+		if node.Std <= maxStd {
+			return node
+		}
+
+		xs := []int{r.Min.X, r.Max.X}
+		if r.Dx() >= 2*minBlock {
+			xs = []int{r.Min.X, r.Min.X + r.Dx()/2, r.Max.X}
+		}
+		ys := []int{r.Min.Y, r.Max.Y}
+		if r.Dy() >= 2*minBlock {
+			ys = []int{r.Min.Y, r.Min.Y + r.Dy()/2, r.Max.Y}
+		}
+		if len(xs) == 2 && len(ys) == 2 {
+			return node
+		}
+		for j := 0; j+1 < len(ys); j++ {
+			for i := 0; i+1 < len(xs); i++ {
+				node.Children = append(node.Children, build(image.Rect(xs[i], ys[j], xs[i+1], ys[j+1])))
+			}
+		}
+		return node
+	}
+	b := img.Bounds()
+	return build(image.Rect(0, 0, b.Dx(), b.Dy()))
+}
+
+// leaves percorre as folhas em profundidade.
+func (q *QuadTree) leaves(visit func(*QuadTree)) {
+	if len(q.Children) == 0 {
+		visit(q)
+		return
+	}
+	for _, c := range q.Children {
+		c.leaves(visit)
+	}
+}
+
+// leafCount conta as folhas da árvore.
+func (q *QuadTree) leafCount() int {
+	n := 0
+	q.leaves(func(*QuadTree) { n++ })
+	return n
+}
+
+// reconstruct pinta cada folha com a sua média, numa imagem com origem em
+// (0, 0).
+func (q *QuadTree) reconstruct() *image.Gray {
+	result := image.NewGray(image.Rect(0, 0, q.Bounds.Dx(), q.Bounds.Dy()))
+	q.leaves(func(leaf *QuadTree) {
+		v := uint8(math.Round(leaf.Mean))
+		r := leaf.Bounds.Sub(q.Bounds.Min)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				result.Pix[y*result.Stride+x] = v
+			}
+		}
+	})
+	return result
+}
+
+// overlay desenha o contorno das folhas sobre a imagem.
+func (q *QuadTree) overlay(img *image.Gray) *image.RGBA {
+	result := grayToRGBA(img)
+	offset := img.Bounds().Min
+	q.leaves(func(leaf *QuadTree) {
+		drawRect(result, leaf.Bounds.Sub(offset), color.RGBA{255, 0, 0, 255})
+	})
+	return result
+}
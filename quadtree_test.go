@@ -0,0 +1,111 @@
+package main
+
+import (
+	"image"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// splitImage é 64x64, preta à esquerda da coluna edge e branca dela em diante.
+func splitImage(edge int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := edge; x < 64; x++ {
+			img.Pix[y*img.Stride+x] = 255
+		}
+	}
+	return img
+}
+
+func TestQuadtreeUniform(t *testing.T) {
+	q := quadtreeDecompose(uniformGray(64, 90), 0, 1)
+	if n := q.leafCount(); n != 1 {
+		t.Errorf("%d folhas numa imagem uniforme, esperado 1", n)
+	}
+	if q.Mean != 90 || q.Std != 0 {
+		t.Errorf("média %v e desvio %v, esperado 90 e 0", q.Mean, q.Std)
+	}
+}
+
+func TestQuadtreeBoundary(t *testing.T) {
+	const minBlock = 4
+	tests := []struct {
+		name       string
+		edge       int
+		wantLeaves int // 0 quando só as propriedades são verificadas
+	}{
+		{"borda na divisão do meio", 32, 4},
+		{"borda fora da grade", 22, 0},
+		{"borda em múltiplo do bloco mínimo", 20, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := quadtreeDecompose(splitImage(tt.edge), 1, minBlock)
+			if tt.wantLeaves > 0 && q.leafCount() != tt.wantLeaves {
+				t.Errorf("%d folhas, esperado %d", q.leafCount(), tt.wantLeaves)
+			}
+			smallLeaves := 0
+			q.leaves(func(leaf *QuadTree) {
+				small := leaf.Bounds.Dx() == minBlock && leaf.Bounds.Dy() == minBlock
+				if small {
+					smallLeaves++
+				}
+				if leaf.Std > 1 && !small {
+					t.Errorf("folha %v com desvio %.1f acima do tamanho mínimo", leaf.Bounds, leaf.Std)
+				}
+				// blocos mínimos só aparecem encostados na borda
+				if small && (leaf.Bounds.Max.X < tt.edge-minBlock || leaf.Bounds.Min.X > tt.edge+minBlock) {
+					t.Errorf("folha mínima %v longe da coluna %d", leaf.Bounds, tt.edge)
+				}
+			})
+			if tt.wantLeaves == 0 && smallLeaves == 0 {
+				t.Error("nenhuma folha de tamanho mínimo ao longo da borda")
+			}
+		})
+	}
+}
+
+func TestQuadtreeReconstructionError(t *testing.T) {
+	img := synthetic.SiemensStar(128, 128, 12)
+	mse := func(a, b *image.Gray) float64 {
+		var sum float64
+		for i := range a.Pix {
+			d := float64(a.Pix[i]) - float64(b.Pix[i])
+			sum += d * d
+		}
+		return sum / float64(len(a.Pix))
+	}
+
+	last, lastLeaves := -1.0, 0
+	for _, maxStd := range []float64{100, 64, 32, 16, 8, 4, 0} {
+		q := quadtreeDecompose(img, maxStd, 1)
+		e := mse(q.reconstruct(), img)
+		if last >= 0 && e > last {
+			t.Errorf("maxStd %v: erro %.2f maior que o anterior %.2f", maxStd, e, last)
+		}
+		if q.leafCount() < lastLeaves {
+			t.Errorf("maxStd %v: %d folhas, menos que as %d anteriores", maxStd, q.leafCount(), lastLeaves)
+		}
+		last, lastLeaves = e, q.leafCount()
+	}
+	if last != 0 {
+		t.Errorf("com maxStd 0 e blocos de 1 pixel o erro é %.2f, esperado 0", last)
+	}
+}
+
+func TestQuadtreeSubImage(t *testing.T) {
+	img := splitImage(22).SubImage(image.Rect(10, 10, 42, 42)).(*image.Gray)
+	q := quadtreeDecompose(img, 0, 1)
+	if q.Bounds != img.Bounds() {
+		t.Errorf("raiz em %v, esperado %v", q.Bounds, img.Bounds())
+	}
+	got := q.reconstruct()
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			if got.GrayAt(x, y) != img.GrayAt(10+x, 10+y) {
+				t.Fatalf("pixel %d,%d difere na reconstrução", x, y)
+			}
+		}
+	}
+}
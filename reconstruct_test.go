@@ -0,0 +1,153 @@
+package main
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+
+	"processing-images/imaging"
+	"processing-images/synthetic"
+)
+
+// naiveReconstruct repete a dilatação geodésica (máximo dos 8 vizinhos,
+// limitado pela máscara) até nada mudar.
+func naiveReconstruct(marker, mask *image.Gray) *image.Gray {
+	b := mask.Bounds()
+	cur := image.NewGray(b)
+	for i := range cur.Pix {
+		cur.Pix[i] = min(marker.Pix[i], mask.Pix[i])
+	}
+	for changed := true; changed; {
+		changed = false
+		next := image.NewGray(b)
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				v := cur.Pix[y*cur.Stride+x]
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						if nx, ny := x+dx, y+dy; nx >= 0 && nx < b.Dx() && ny >= 0 && ny < b.Dy() {
+							v = max(v, cur.Pix[ny*cur.Stride+nx])
+						}
+					}
+				}
+				v = min(v, mask.Pix[y*mask.Stride+x])
+				next.Pix[y*next.Stride+x] = v
+				changed = changed || v != cur.Pix[y*cur.Stride+x]
+			}
+		}
+		cur = next
+	}
+	return cur
+}
+
+func TestReconstructSingleComponent(t *testing.T) {
+	mask, circles := synthetic.Circles(120, 120, synthetic.CircleOptions{Count: 5, Radius: 12, Seed: 3})
+	for i := range mask.Pix {
+		mask.Pix[i] = 255 - mask.Pix[i] // discos brancos sobre preto
+	}
+	labels, _, err := imaging.LabelComponents(mask, 8, imaging.WhiteObjects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range circles {
+		marker := image.NewGray(mask.Bounds())
+		marker.Pix[marker.PixOffset(c.Center.X, c.Center.Y)] = 255
+		result := reconstruct(marker, mask)
+		want := labels[c.Center.Y][c.Center.X]
+		for y, row := range labels {
+			for x, l := range row {
+				inside := l == want
+				if got := result.Pix[y*result.Stride+x] == 255; got != inside {
+					t.Fatalf("disco %d: pixel (%d,%d) = %v, esperado %v", i, x, y, got, inside)
+				}
+			}
+		}
+	}
+}
+
+func TestReconstructMatchesNaive(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for seed := int64(0); seed < 20; seed++ {
+		w, h := 5+rng.Intn(20), 5+rng.Intn(20)
+		mask, marker := image.NewGray(image.Rect(0, 0, w, h)), image.NewGray(image.Rect(0, 0, w, h))
+		binary := seed%2 == 0
+		for i := range mask.Pix {
+			if binary {
+				if rng.Intn(3) > 0 {
+					mask.Pix[i] = 255
+				}
+				if rng.Intn(40) == 0 {
+					marker.Pix[i] = 255
+				}
+			} else {
+				mask.Pix[i] = uint8(rng.Intn(256))
+				marker.Pix[i] = uint8(rng.Intn(256)) / 2
+			}
+		}
+		got, want := reconstruct(marker, mask), naiveReconstruct(marker, mask)
+		for i := range want.Pix {
+			if got.Pix[i] != want.Pix[i] {
+				t.Fatalf("semente %d (%dx%d, binária %v): pixel %d = %d, esperado %d", seed, w, h, binary, i, got.Pix[i], want.Pix[i])
+			}
+			if got.Pix[i] > mask.Pix[i] {
+				t.Fatalf("semente %d: pixel %d = %d passa da máscara %d", seed, i, got.Pix[i], mask.Pix[i])
+			}
+		}
+	}
+}
+
+func TestReconstructSubImage(t *testing.T) {
+	mask := synthetic.Checkerboard(40, 40, 10)
+	marker := image.NewGray(mask.Bounds())
+	marker.Pix[0] = 255
+	want := reconstruct(marker, mask)
+
+	bigMask, bigMarker := image.NewGray(image.Rect(0, 0, 60, 60)), image.NewGray(image.Rect(0, 0, 60, 60))
+	r := image.Rect(10, 15, 50, 55)
+	for y := 0; y < 40; y++ {
+		copy(bigMask.Pix[bigMask.PixOffset(r.Min.X, r.Min.Y+y):], mask.Pix[y*mask.Stride:(y+1)*mask.Stride])
+		copy(bigMarker.Pix[bigMarker.PixOffset(r.Min.X, r.Min.Y+y):], marker.Pix[y*marker.Stride:(y+1)*marker.Stride])
+	}
+	got := reconstruct(bigMarker.SubImage(r).(*image.Gray), bigMask.SubImage(r).(*image.Gray))
+	for i := range want.Pix {
+		if got.Pix[i] != want.Pix[i] {
+			t.Fatalf("SubImage: pixel %d = %d, esperado %d", i, got.Pix[i], want.Pix[i])
+		}
+	}
+}
+
+func TestOpeningByReconstruction(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 60, 40))
+	// um retângulo com uma "antena" fina e um ponto solto de 2x2
+	for y := 10; y < 30; y++ {
+		for x := 10; x < 30; x++ {
+			img.Pix[y*img.Stride+x] = 255
+		}
+	}
+	for x := 30; x < 45; x++ {
+		img.Pix[20*img.Stride+x] = 255
+	}
+	img.Pix[5*img.Stride+50], img.Pix[5*img.Stride+51] = 255, 255
+	img.Pix[6*img.Stride+50], img.Pix[6*img.Stride+51] = 255, 255
+
+	result := openingByReconstruction(img, imaging.SquareSE(5))
+	tests := []struct {
+		name string
+		p    image.Point
+		want uint8
+	}{
+		{"canto do retângulo", image.Pt(10, 10), 255},
+		{"antena ligada ao retângulo", image.Pt(44, 20), 255},
+		{"ponto solto", image.Pt(50, 5), 0},
+		{"fundo", image.Pt(5, 35), 0},
+	}
+	for _, tt := range tests {
+		if v := result.GrayAt(tt.p.X, tt.p.Y).Y; v != tt.want {
+			t.Errorf("%s: %d, esperado %d", tt.name, v, tt.want)
+		}
+	}
+	// a abertura comum arredonda: perde a antena
+	if v := imaging.Dilate(imaging.Erode(img, imaging.SquareSE(5), imaging.WhiteObjects), imaging.SquareSE(5), imaging.WhiteObjects).GrayAt(44, 20).Y; v != 0 {
+		t.Errorf("abertura comum manteve a antena (%d)", v)
+	}
+}
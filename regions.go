@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+
+	"processing-images/imaging"
+)
+
+// writeRegionsCSV grava uma linha por objeto; sem objetos sai só o
+// cabeçalho. umX e umY são o tamanho do pixel em µm: quando conhecidos
+// (> 0), a área também sai em mm² e o centróide, o perímetro e o diâmetro
+// em µm (os comprimentos pela média geométrica dos dois eixos).
+func writeRegionsCSV(path string, regions []imaging.Region, umX, umY float64) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("erro ao criar %s: %w", path, err)
+	}
+	defer file.Close()
+
+	physical := umX > 0 && umY > 0
+	umLength := math.Sqrt(umX * umY)
+	w := csv.NewWriter(file)
+	header := []string{"label", "area", "centroid_x", "centroid_y", "min_x", "min_y", "max_x", "max_y", "perimeter", "equivalent_diameter",
+		"orientation_deg", "eccentricity", "hu1", "hu2", "hu3", "hu4", "hu5", "hu6", "hu7"}
+	if physical {
+		header = append(header, "area_mm2", "centroid_x_um", "centroid_y_um", "perimeter_um", "equivalent_diameter_um")
+	}
+	w.Write(header)
+	for _, r := range regions {
+		row := []string{
+			strconv.Itoa(r.Label),
+			strconv.Itoa(r.Area),
+			strconv.FormatFloat(r.Centroid[0], 'f', 2, 64),
+			strconv.FormatFloat(r.Centroid[1], 'f', 2, 64),
+			strconv.Itoa(r.Bounds.Min.X),
+			strconv.Itoa(r.Bounds.Min.Y),
+			strconv.Itoa(r.Bounds.Max.X),
+			strconv.Itoa(r.Bounds.Max.Y),
+			strconv.Itoa(r.Perimeter),
+			strconv.FormatFloat(r.EquivalentDiameter, 'f', 2, 64),
+			strconv.FormatFloat(r.Moments.Orientation()*180/math.Pi, 'f', 2, 64),
+			strconv.FormatFloat(r.Moments.Eccentricity(), 'f', 4, 64),
+		}
+		for _, hu := range imaging.HuMoments(r.Moments) {
+			row = append(row, strconv.FormatFloat(hu, 'g', 6, 64))
+		}
+		if physical {
+			row = append(row,
+				strconv.FormatFloat(float64(r.Area)*umX*umY/1e6, 'g', 6, 64),
+				strconv.FormatFloat(r.Centroid[0]*umX, 'f', 2, 64),
+				strconv.FormatFloat(r.Centroid[1]*umY, 'f', 2, 64),
+				strconv.FormatFloat(float64(r.Perimeter)*umLength, 'f', 2, 64),
+				strconv.FormatFloat(r.EquivalentDiameter*umLength, 'f', 2, 64))
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// printRegionsSummary mostra o número de objetos e as áreas.
+func printRegionsSummary(regions []imaging.Region) {
+	fmt.Printf("Objetos medidos: %d\n", len(regions))
+	if len(regions) == 0 {
+		return
+	}
+	total, largest := 0, regions[0]
+	for _, r := range regions {
+		total += r.Area
+		if r.Area > largest.Area {
+			largest = r
+		}
+	}
+	fmt.Printf("Área total: %d, média: %.1f, maior: %d (objeto %d em %.0f,%.0f)\n",
+		total, float64(total)/float64(len(regions)), largest.Area, largest.Label, largest.Centroid[0], largest.Centroid[1])
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"processing-images/imaging"
+)
+
+// readCSV lê todas as linhas de um CSV gravado no teste.
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestWriteRegionsCSV(t *testing.T) {
+	square := make([][]int, 20)
+	for y := range square {
+		square[y] = make([]int, 20)
+	}
+	for y := 3; y < 8; y++ {
+		for x := 2; x < 7; x++ {
+			square[y][x] = 1
+		}
+	}
+	square[15][15] = 2
+
+	tests := []struct {
+		name    string
+		regions []imaging.Region
+		rows    [][]string
+	}{
+		{"sem objetos", nil, nil},
+		{
+			"quadrado e pixel",
+			imaging.RegionProps(square),
+			[][]string{
+				{"1", "25", "4.00", "5.00", "2", "3", "7", "8", "16", "5.64"},
+				{"2", "1", "15.00", "15.00", "15", "15", "16", "16", "1", "1.13"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "objects.csv")
+		if err := writeRegionsCSV(path, tt.regions, 0, 0); err != nil {
+			t.Fatal(err)
+		}
+		records := readCSV(t, path)
+		if len(records) != len(tt.rows)+1 || records[0][0] != "label" || len(records[0]) != 19 {
+			t.Fatalf("%s: %d linhas, cabeçalho %v", tt.name, len(records), records[0])
+		}
+		for i, want := range tt.rows {
+			got := records[i+1]
+			if len(got) != len(records[0]) {
+				t.Errorf("%s: linha %d com %d colunas", tt.name, i+1, len(got))
+				continue
+			}
+			for j, v := range want {
+				if got[j] != v {
+					t.Errorf("%s: linha %d, coluna %s = %s, esperado %s", tt.name, i+1, records[0][j], got[j], v)
+				}
+			}
+		}
+	}
+
+	if err := writeRegionsCSV(filepath.Join(t.TempDir(), "nada", "objects.csv"), nil, 0, 0); err == nil {
+		t.Error("diretório inexistente sem erro")
+	}
+}
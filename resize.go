@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// resizeArea redimensiona pela média de área: cada pixel de saída é a média
+// dos pixels de entrada que ele cobre, com peso proporcional à fração
+// coberta. Em reduções grandes isso não serrilha como a amostragem pontual.
+func resizeArea(img *image.Gray, width, height int) *image.Gray {
+	b := img.Bounds()
+	if b.Empty() || width <= 0 || height <= 0 {
+		return image.NewGray(image.Rect(0, 0, max(width, 0), max(height, 0)))
+	}
+	return resample(img, areaWeights(b.Dx(), width), areaWeights(b.Dy(), height))
+}
+
+// resample monta a imagem de saída a partir dos pesos de cada coluna (wx) e
+// de cada linha (wy), em duas passadas separáveis (horizontal e vertical),
+// saturando em 0..255.
+func resample(img *image.Gray, wx, wy [][]areaWeight) *image.Gray {
+	b := img.Bounds()
+	width, height := len(wx), len(wy)
+	result := image.NewGray(image.Rect(0, 0, width, height))
+
+	rows := make([][]float64, b.Dy())
+	for y := range rows {
+		rows[y] = make([]float64, width)
+		src := img.Pix[img.PixOffset(b.Min.X, b.Min.Y+y):]
+		for x, weights := range wx {
+			var sum float64
+			for _, w := range weights {
+				sum += float64(src[w.index]) * w.weight
+			}
+			rows[y][x] = sum
+		}
+	}
+
+	for y, weights := range wy {
+		for x := 0; x < width; x++ {
+			var sum float64
+			for _, w := range weights {
+				sum += rows[w.index][x] * w.weight
+			}
+			result.Pix[y*result.Stride+x] = uint8(math.Round(math.Min(math.Max(sum, 0), 255)))
+		}
+	}
+	return result
+}
+
+type areaWeight struct {
+	index  int
+	weight float64
+}
+
+// areaWeights calcula, para cada pixel de saída, os pixels de entrada que
+// ele cobre e o peso (normalizado) de cada um.
+func areaWeights(in, out int) [][]areaWeight {
+	scale := float64(in) / float64(out)
+	weights := make([][]areaWeight, out)
+	for i := range weights {
+		start, end := float64(i)*scale, float64(i+1)*scale
+		for j := int(start); j < in && float64(j) < end; j++ {
+			cover := math.Min(end, float64(j+1)) - math.Max(start, float64(j))
+			if cover > 0 {
+				weights[i] = append(weights[i], areaWeight{j, cover / scale})
+			}
+		}
+	}
+	return weights
+}
+
+// interpolation é o método de resize.
+type interpolation int
+
+const (
+	interpNearest  interpolation = iota // vizinho mais próximo: não cria tons novos
+	interpBilinear                      // média dos 4 vizinhos
+	interpBicubic                       // Catmull-Rom nos 16 vizinhos: mais nítido, pode passar um pouco das bordas
+)
+
+func parseInterpolation(s string) (interpolation, error) {
+	switch s {
+	case "nearest":
+		return interpNearest, nil
+	case "bilinear":
+		return interpBilinear, nil
+	case "bicubic":
+		return interpBicubic, nil
+	}
+	return 0, fmt.Errorf("interpolação desconhecida %q, use nearest, bilinear ou bicubic", s)
+}
+
+// resize redimensiona a imagem para width x height interpolando pelo
+// método; com uma das dimensões 0 ela sai da outra mantendo a proporção.
+// Imagens coloridas são interpoladas canal a canal. Os centros dos pixels
+// de saída caem sobre a entrada como em areaWeights, e fora dela a borda é
+// replicada. Para reduções grandes a interpolação serrilha (usa só os
+// vizinhos do ponto); aí a média de área de -max-megapixels é melhor.
+func resize(img image.Image, width, height int, method interpolation) image.Image {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	width, height = resizeDimensions(w, h, width, height)
+	if w == 0 || h == 0 {
+		return image.NewGray(image.Rect(0, 0, width, height))
+	}
+	wx := interpolationWeights(w, width, method)
+	wy := interpolationWeights(h, height, method)
+	return perChannel(img, func(gray *image.Gray) *image.Gray { return resample(gray, wx, wy) })
+}
+
+// resizeDimensions completa uma dimensão 0 pela proporção de w x h (com
+// pelo menos 1 pixel).
+func resizeDimensions(w, h, width, height int) (int, int) {
+	switch {
+	case width == 0 && h > 0:
+		width = max(1, int(math.Round(float64(w)*float64(height)/float64(h))))
+	case height == 0 && w > 0:
+		height = max(1, int(math.Round(float64(h)*float64(width)/float64(w))))
+	}
+	return width, height
+}
+
+// parseResize lê o tamanho de -resize, "LARGURAxALTURA", em que uma das
+// dimensões pode ser 0.
+func parseResize(s string) (int, int, error) {
+	var width, height int
+	if _, err := fmt.Sscanf(s, "%dx%d", &width, &height); err != nil || width < 0 || height < 0 || width == 0 && height == 0 {
+		return 0, 0, fmt.Errorf("tamanho inválido %q, use LxA com uma das dimensões 0 para manter a proporção (ex: 800x0)", s)
+	}
+	return width, height, nil
+}
+
+// interpolationWeights calcula, para cada pixel de saída, os pixels de
+// entrada que a interpolação usa e o peso de cada um.
+func interpolationWeights(in, out int, method interpolation) [][]areaWeight {
+	scale := float64(in) / float64(out)
+	clamp := func(j int) int { return min(max(j, 0), in-1) }
+	weights := make([][]areaWeight, out)
+	for i := range weights {
+		// centro do pixel de saída nas coordenadas da entrada
+		x := (float64(i)+0.5)*scale - 0.5
+		switch method {
+		case interpNearest:
+			weights[i] = []areaWeight{{clamp(int(math.Floor(x + 0.5))), 1}}
+		case interpBilinear:
+			j := int(math.Floor(x))
+			f := x - float64(j)
+			weights[i] = []areaWeight{{clamp(j), 1 - f}, {clamp(j + 1), f}}
+		default:
+			j := int(math.Floor(x))
+			f := x - float64(j)
+			for k := -1; k <= 2; k++ {
+				weights[i] = append(weights[i], areaWeight{clamp(j + k), catmullRom(float64(k) - f)})
+			}
+		}
+	}
+	return weights
+}
+
+// catmullRom é o kernel cúbico de Catmull-Rom (a = −0.5): 1 em 0, 0 nos
+// outros inteiros e soma 1 em qualquer deslocamento.
+func catmullRom(t float64) float64 {
+	t = math.Abs(t)
+	switch {
+	case t < 1:
+		return 1.5*t*t*t - 2.5*t*t + 1
+	case t < 2:
+		return -0.5*t*t*t + 2.5*t*t - 4*t + 2
+	}
+	return 0
+}
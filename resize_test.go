@@ -0,0 +1,166 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// checker2x2 é o tabuleiro 2x2: preto em cima à esquerda e embaixo à
+// direita.
+func checker2x2() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 2, 2))
+	img.Pix[1], img.Pix[2] = 255, 255
+	return img
+}
+
+func TestResizeChecker(t *testing.T) {
+	tests := []struct {
+		method  interpolation
+		binary  bool // só 0 e 255
+		blocks  bool // cada quadrante 4x4 com um valor só
+		corners bool // os cantos mantêm o valor da entrada
+	}{
+		{interpNearest, true, true, true},
+		{interpBilinear, false, false, true},
+		{interpBicubic, false, false, true},
+	}
+	for _, tt := range tests {
+		got := resize(checker2x2(), 8, 8, tt.method).(*image.Gray)
+		if got.Bounds() != image.Rect(0, 0, 8, 8) {
+			t.Fatalf("método %d: limites %v", tt.method, got.Bounds())
+		}
+		binary := true
+		for _, v := range got.Pix {
+			binary = binary && (v == 0 || v == 255)
+		}
+		if binary != tt.binary {
+			t.Errorf("método %d: só preto e branco = %v, esperado %v", tt.method, binary, tt.binary)
+		}
+		blocks := true
+		for y := 0; y < 8; y++ {
+			for x := 0; x < 8; x++ {
+				blocks = blocks && got.GrayAt(x, y) == checker2x2().GrayAt(x/4, y/4)
+			}
+		}
+		if blocks != tt.blocks {
+			t.Errorf("método %d: blocos = %v, esperado %v", tt.method, blocks, tt.blocks)
+		}
+		if tt.corners && (got.GrayAt(0, 0).Y != 0 || got.GrayAt(7, 0).Y != 255 || got.GrayAt(0, 7).Y != 255 || got.GrayAt(7, 7).Y != 0) {
+			t.Errorf("método %d: cantos %d %d %d %d", tt.method, got.GrayAt(0, 0).Y, got.GrayAt(7, 0).Y, got.GrayAt(0, 7).Y, got.GrayAt(7, 7).Y)
+		}
+	}
+
+	// o bilinear vira um degradê: a primeira linha sobe sem voltar
+	row := resize(checker2x2(), 8, 8, interpBilinear).(*image.Gray).Pix[:8]
+	for x := 1; x < 8; x++ {
+		if row[x] < row[x-1] {
+			t.Fatalf("linha bilinear %v não é crescente", row)
+		}
+	}
+	if row[3] == 0 || row[3] == 255 || row[4] == 0 || row[4] == 255 {
+		t.Errorf("linha bilinear %v sem tons intermediários no meio", row)
+	}
+}
+
+func TestResizeDimensions(t *testing.T) {
+	tests := []struct {
+		w, h, width, height int
+		wantW, wantH        int
+	}{
+		{400, 300, 200, 0, 200, 150},
+		{400, 300, 0, 50, 67, 50},
+		{400, 300, 120, 90, 120, 90},
+		{400, 300, 100, 500, 100, 500}, // as duas dadas: sem manter a proporção
+		{1000, 10, 50, 0, 50, 1},       // pelo menos 1 pixel
+		{3, 4000, 0, 100, 1, 100},
+	}
+	for _, tt := range tests {
+		w, h := resizeDimensions(tt.w, tt.h, tt.width, tt.height)
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("%dx%d para %dx%d: %dx%d, esperado %dx%d", tt.w, tt.h, tt.width, tt.height, w, h, tt.wantW, tt.wantH)
+		}
+		got := resize(image.NewGray(image.Rect(0, 0, tt.w, tt.h)), tt.width, tt.height, interpBilinear)
+		if got.Bounds().Dx() != tt.wantW || got.Bounds().Dy() != tt.wantH {
+			t.Errorf("resize de %dx%d para %dx%d: limites %v", tt.w, tt.h, tt.width, tt.height, got.Bounds())
+		}
+	}
+}
+
+func TestResizeIdentityAndColor(t *testing.T) {
+	img := gradientImage(17, 11)
+	sub := img.SubImage(image.Rect(3, 2, 15, 9)).(*image.Gray)
+	for _, method := range []interpolation{interpNearest, interpBilinear, interpBicubic} {
+		// no mesmo tamanho os centros caem nos pixels e nada muda
+		got := resize(sub, 12, 7, method).(*image.Gray)
+		for y := 0; y < 7; y++ {
+			for x := 0; x < 12; x++ {
+				if got.GrayAt(x, y) != sub.GrayAt(3+x, 2+y) {
+					t.Fatalf("método %d: pixel %d,%d mudou", method, x, y)
+				}
+			}
+		}
+	}
+
+	// a imagem colorida é interpolada por canal
+	rgba := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	rgba.Set(0, 0, color.RGBA{200, 0, 40, 255})
+	rgba.Set(1, 0, color.RGBA{0, 100, 40, 255})
+	got := resize(rgba, 4, 1, interpBilinear)
+	r, g, b, _ := got.At(0, 0).RGBA()
+	if r>>8 != 200 || g>>8 != 0 || b>>8 != 40 {
+		t.Errorf("canto colorido (%d, %d, %d), esperado (200, 0, 40)", r>>8, g>>8, b>>8)
+	}
+	r, g, _, _ = got.At(1, 0).RGBA()
+	if r>>8 <= 100 || r>>8 >= 200 || g>>8 == 0 {
+		t.Errorf("pixel interpolado (%d, %d), esperado entre as cores", r>>8, g>>8)
+	}
+}
+
+func TestCatmullRom(t *testing.T) {
+	for _, f := range []float64{0, 0.25, 0.5, 0.9} {
+		var sum float64
+		for k := -1; k <= 2; k++ {
+			sum += catmullRom(float64(k) - f)
+		}
+		if math.Abs(sum-1) > 1e-12 {
+			t.Errorf("deslocamento %g: pesos somam %g", f, sum)
+		}
+	}
+	for _, tt := range [][2]float64{{0, 1}, {1, 0}, {-1, 0}, {2, 0}, {2.5, 0}} {
+		if got := catmullRom(tt[0]); math.Abs(got-tt[1]) > 1e-12 {
+			t.Errorf("catmullRom(%g) = %g, esperado %g", tt[0], got, tt[1])
+		}
+	}
+}
+
+func TestParseResize(t *testing.T) {
+	tests := []struct {
+		in   string
+		w, h int
+		ok   bool
+	}{
+		{"800x0", 800, 0, true},
+		{"0x600", 0, 600, true},
+		{"640x480", 640, 480, true},
+		{"0x0", 0, 0, false},
+		{"-5x10", 0, 0, false},
+		{"800", 0, 0, false},
+		{"axb", 0, 0, false},
+	}
+	for _, tt := range tests {
+		w, h, err := parseResize(tt.in)
+		if (err == nil) != tt.ok || w != tt.w || h != tt.h {
+			t.Errorf("%q: %dx%d, %v", tt.in, w, h, err)
+		}
+	}
+	for _, s := range []string{"nearest", "bilinear", "bicubic"} {
+		if _, err := parseInterpolation(s); err != nil {
+			t.Errorf("%s: %v", s, err)
+		}
+	}
+	if _, err := parseInterpolation("lanczos"); err == nil {
+		t.Error("lanczos: sem erro")
+	}
+}
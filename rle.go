@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"strings"
+)
+
+// extensão das máscaras em RLE
+const rleExt = ".rle.json"
+
+// RLE é uma máscara binária no formato RLE não comprimido do COCO: size é
+// [altura, largura] e counts alterna fundo/primeiro plano em ordem de
+// colunas, sempre começando pelo fundo (o primeiro valor pode ser 0).
+// Foreground registra a polaridade da máscara de origem; vazio é "white", o
+// padrão, e fica fora do JSON para manter compatibilidade com o COCO.
+type RLE struct {
+	Size       [2]int `json:"size"`
+	Counts     []int  `json:"counts"`
+	Foreground string `json:"foreground,omitempty"`
+}
+
+// encodeRLE codifica a máscara com o primeiro plano em branco.
+func encodeRLE(mask *image.Gray) RLE {
+	return encodeRLEPolarity(mask, foregroundWhite)
+}
+
+// encodeRLEPolarity codifica a máscara com a polaridade dada.
+func encodeRLEPolarity(mask *image.Gray, fg polarity) RLE {
+	b := mask.Bounds()
+	r := RLE{Size: [2]int{b.Dy(), b.Dx()}, Counts: []int{}}
+	if fg == foregroundBlack {
+		r.Foreground = "black"
+	}
+
+	current, run := false, 0
+	for x := b.Min.X; x < b.Max.X; x++ {
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			v := fg.isForeground(mask.GrayAt(x, y).Y)
+			if v != current {
+				r.Counts = append(r.Counts, run)
+				current, run = v, 0
+			}
+			run++
+		}
+	}
+	r.Counts = append(r.Counts, run)
+	return r
+}
+
+// decodeRLE reconstrói a máscara em 0/255 respeitando a polaridade.
+func decodeRLE(r RLE) (*image.Gray, error) {
+	h, w := r.Size[0], r.Size[1]
+	if h < 0 || w < 0 {
+		return nil, fmt.Errorf("tamanho inválido %dx%d", w, h)
+	}
+	if h > 0 && w > maxDecodePixels/h {
+		return nil, fmt.Errorf("máscara RLE grande demais: %dx%d", w, h)
+	}
+	// os counts são conferidos antes de alocar a máscara
+	total := 0
+	for _, count := range r.Counts {
+		if count < 0 || count > w*h-total {
+			return nil, fmt.Errorf("counts não somam %d pixels", w*h)
+		}
+		total += count
+	}
+	if total != w*h {
+		return nil, fmt.Errorf("counts somam %d pixels, esperado %d", total, w*h)
+	}
+	fg, err := parsePolarity(r.Foreground)
+	if r.Foreground == "" {
+		fg, err = foregroundWhite, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	on, off := uint8(255), uint8(0)
+	if fg == foregroundBlack {
+		on, off = 0, 255
+	}
+
+	mask := image.NewGray(image.Rect(0, 0, w, h))
+	i, current := 0, false
+	for _, count := range r.Counts {
+		value := off
+		if current {
+			value = on
+		}
+		for end := i + count; i < end; i++ {
+			mask.Pix[(i%h)*mask.Stride+i/h] = value
+		}
+		current = !current
+	}
+	return mask, nil
+}
+
+// isRLEPath diz se o caminho é de uma máscara em RLE.
+func isRLEPath(path string) bool {
+	return strings.HasSuffix(path, rleExt)
+}
+
+// parseRLE lê uma máscara RLE em JSON.
+func parseRLE(data []byte) (*image.Gray, error) {
+	var r RLE
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&r); err != nil {
+		return nil, fmt.Errorf("RLE inválido: %w", err)
+	}
+	return decodeRLE(r)
+}
+
+// marshalRLE serializa no formato compacto do COCO.
+func marshalRLE(r RLE) ([]byte, error) {
+	return json.Marshal(r)
+}
+
+// isBinary diz se a imagem só tem pixels 0 e 255.
+func isBinary(img *image.Gray) bool {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(b.Min.X, y):img.PixOffset(b.Max.X, y)]
+		for _, v := range row {
+			if v != 0 && v != 255 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// rlePath troca a extensão .png das máscaras binárias por .rle.json quando
+// -rle está ligado; as demais saídas continuam em PNG.
+func rlePath(name string, img image.Image) string {
+	gray, ok := img.(*image.Gray)
+	if !*rleMasks || !ok || !isBinary(gray) {
+		return name
+	}
+	return strings.TrimSuffix(name, ".png") + rleExt
+}
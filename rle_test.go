@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+// randomMask gera uma máscara 0/255 com a fração density de brancos.
+func randomMask(width, height int, density float64, seed int64) *image.Gray {
+	rng := rand.New(rand.NewSource(seed))
+	mask := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range mask.Pix {
+		if rng.Float64() < density {
+			mask.Pix[i] = 255
+		}
+	}
+	return mask
+}
+
+func TestRLERoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		mask *image.Gray
+		fg   polarity
+	}{
+		{"aleatória", randomMask(37, 23, 0.3, 1), foregroundWhite},
+		{"aleatória densa", randomMask(16, 40, 0.9, 2), foregroundWhite},
+		{"aleatória, objetos pretos", randomMask(29, 31, 0.5, 3), foregroundBlack},
+		{"toda fundo", uniformGray(8, 0), foregroundWhite},
+		{"toda primeiro plano", uniformGray(8, 255), foregroundWhite},
+		{"toda primeiro plano, objetos pretos", uniformGray(8, 0), foregroundBlack},
+		{"vazia", image.NewGray(image.Rect(0, 0, 0, 0)), foregroundWhite},
+		// recorte com origem fora de (0, 0)
+		{"recorte", randomMask(40, 40, 0.5, 4).SubImage(image.Rect(5, 7, 30, 21)).(*image.Gray), foregroundWhite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := marshalRLE(encodeRLEPolarity(tt.mask, tt.fg))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := parseRLE(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b := tt.mask.Bounds()
+			if got.Bounds().Dx() != b.Dx() || got.Bounds().Dy() != b.Dy() {
+				t.Fatalf("tamanho %v, esperado %v", got.Bounds(), b)
+			}
+			for y := 0; y < b.Dy(); y++ {
+				for x := 0; x < b.Dx(); x++ {
+					if got.GrayAt(x, y) != tt.mask.GrayAt(b.Min.X+x, b.Min.Y+y) {
+						t.Fatalf("pixel %d,%d difere após ida e volta", x, y)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRLECounts(t *testing.T) {
+	tests := []struct {
+		name string
+		mask *image.Gray
+		want []int
+	}{
+		{"toda fundo", uniformGray(3, 0), []int{9}},
+		// começa pelo fundo: o primeiro count é 0
+		{"toda primeiro plano", uniformGray(3, 255), []int{0, 9}},
+	}
+	for _, tt := range tests {
+		got := encodeRLE(tt.mask).Counts
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: counts %v, esperado %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: counts %v, esperado %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestRLECOCOFixture(t *testing.T) {
+	// 3x4, em ordem de linhas:
+	//   0 1 1 0
+	//   0 1 1 0
+	//   0 0 0 1
+	fixture, err := os.ReadFile("testdata/coco-mask.rle.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mask := image.NewGray(image.Rect(0, 0, 4, 3))
+	for _, p := range []image.Point{{1, 0}, {2, 0}, {1, 1}, {2, 1}, {3, 2}} {
+		mask.Pix[p.Y*mask.Stride+p.X] = 255
+	}
+
+	data, err := marshalRLE(encodeRLE(mask))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, bytes.TrimSpace(fixture)) {
+		t.Errorf("JSON %s, esperado %s", data, bytes.TrimSpace(fixture))
+	}
+
+	decoded, err := parseRLE(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.Pix, mask.Pix) {
+		t.Errorf("máscara decodificada %v, esperado %v", decoded.Pix, mask.Pix)
+	}
+}
+
+func TestDecodeRLEErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		r    RLE
+	}{
+		{"tamanho negativo", RLE{Size: [2]int{-1, 3}}},
+		{"counts curtos", RLE{Size: [2]int{2, 2}, Counts: []int{3}}},
+		{"counts longos", RLE{Size: [2]int{2, 2}, Counts: []int{3, 2}}},
+		{"count negativo", RLE{Size: [2]int{2, 2}, Counts: []int{5, -1}}},
+		{"polaridade desconhecida", RLE{Size: [2]int{1, 1}, Counts: []int{1}, Foreground: "red"}},
+		{"grande demais", RLE{Size: [2]int{1 << 32, 1 << 32}, Counts: []int{}}},
+		{"grande demais com counts", RLE{Size: [2]int{1 << 16, 1 << 16}, Counts: []int{1 << 32}}},
+		{"counts que não fecham", RLE{Size: [2]int{1000, 1000}, Counts: []int{10, 20}}},
+	}
+	for _, tt := range tests {
+		if _, err := decodeRLE(tt.r); err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+		}
+	}
+
+	// pela linha de comando, o JSON com tamanho enorme dá erro em vez de
+	// pânico
+	if _, err := parseRLE([]byte(`{"size":[4294967296,4294967296],"counts":[]}`)); err == nil {
+		t.Error("JSON com tamanho enorme: sem erro")
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// parsePolygon lê os vértices no formato "x1,y1 x2,y2 ...".
+func parsePolygon(s string) ([][2]float64, error) {
+	var points [][2]float64
+	for _, field := range strings.Fields(s) {
+		xy := strings.Split(field, ",")
+		if len(xy) != 2 {
+			return nil, fmt.Errorf("vértice inválido %q, use x,y", field)
+		}
+		x, errX := strconv.ParseFloat(xy[0], 64)
+		y, errY := strconv.ParseFloat(xy[1], 64)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("vértice inválido %q", field)
+		}
+		points = append(points, [2]float64{x, y})
+	}
+	if len(points) < 3 {
+		return nil, fmt.Errorf("o polígono precisa de pelo menos 3 vértices")
+	}
+	return points, nil
+}
+
+// loadPolygon lê o polígono de -roi-poly: um arquivo .json com a lista de
+// vértices ([[x, y], ...]) ou os vértices na própria flag.
+func loadPolygon(s string) ([][2]float64, error) {
+	if !strings.HasSuffix(s, ".json") {
+		return parsePolygon(s)
+	}
+	data, err := os.ReadFile(s)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", s, err)
+	}
+	var points [][2]float64
+	if err := json.Unmarshal(data, &points); err != nil {
+		return nil, fmt.Errorf("polígono inválido em %s: %w", s, err)
+	}
+	if len(points) < 3 {
+		return nil, fmt.Errorf("o polígono em %s precisa de pelo menos 3 vértices", s)
+	}
+	return points, nil
+}
+
+// rasterizePolygon preenche o polígono por linhas de varredura (regra
+// par-ímpar): um pixel está dentro se o seu centro está. A máscara tem 255
+// dentro e 0 fora.
+func rasterizePolygon(points [][2]float64, width, height int) *image.Gray {
+	mask := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		cy := float64(y) + 0.5
+		var crossings []float64
+		for i := range points {
+			a, b := points[i], points[(i+1)%len(points)]
+			// aresta meio-aberta, para vértices não contarem duas vezes
+			if (a[1] <= cy) == (b[1] <= cy) {
+				continue
+			}
+			crossings = append(crossings, a[0]+(cy-a[1])*(b[0]-a[0])/(b[1]-a[1]))
+		}
+		sort.Float64s(crossings)
+		for i := 0; i+1 < len(crossings); i += 2 {
+			// pixels com centro em [início, fim)
+			x0 := max(int(math.Ceil(crossings[i]-0.5)), 0)
+			x1 := min(int(math.Ceil(crossings[i+1]-0.5)), width)
+			for x := x0; x < x1; x++ {
+				mask.Pix[y*mask.Stride+x] = 255
+			}
+		}
+	}
+	return mask
+}
+
+// restrictToROI limita as saídas à região de interesse: fora dela os
+// pixels voltam a ser os da entrada (fill < 0) ou recebem o valor fill.
+// Saídas de tamanho diferente da entrada ficam como estão.
+func restrictToROI(outputs []output, src image.Image, mask *image.Gray, fill int) {
+	b := src.Bounds()
+	for i, out := range outputs {
+		ob := out.img.Bounds()
+		if ob.Size() != b.Size() {
+			continue
+		}
+		var result draw.Image = image.NewRGBA(image.Rect(0, 0, ob.Dx(), ob.Dy()))
+		if _, ok := out.img.(*image.Gray); ok {
+			result = image.NewGray(result.Bounds())
+		}
+		for y := 0; y < ob.Dy(); y++ {
+			for x := 0; x < ob.Dx(); x++ {
+				var c color.Color = out.img.At(ob.Min.X+x, ob.Min.Y+y)
+				if mask.Pix[y*mask.Stride+x] == 0 {
+					if fill < 0 {
+						c = src.At(b.Min.X+x, b.Min.Y+y)
+					} else {
+						c = color.Gray{uint8(fill)}
+					}
+				}
+				result.Set(x, y, c)
+			}
+		}
+		outputs[i].img = result
+	}
+}
+
+// loadROI rasteriza o polígono de -roi-poly no tamanho da imagem (reduzida
+// por factor, veja -max-megapixels); sem a flag devolve nil (imagem toda).
+func loadROI(img image.Image, factor float64) (*image.Gray, error) {
+	if *roiPoly == "" {
+		return nil, nil
+	}
+	points, err := loadPolygon(*roiPoly)
+	if err != nil {
+		return nil, err
+	}
+	// o polígono vem em coordenadas da imagem original
+	for i := range points {
+		points[i][0] /= factor
+		points[i][1] /= factor
+	}
+	return rasterizePolygon(points, img.Bounds().Dx(), img.Bounds().Dy()), nil
+}
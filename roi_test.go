@@ -0,0 +1,153 @@
+package main
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"processing-images/imaging"
+	"processing-images/synthetic"
+)
+
+func TestOtsuROIMatchesCrop(t *testing.T) {
+	// dentro da ROI, conteúdo bimodal em torno de 90 e 170; fora, pixels
+	// extremos que puxariam o limiar
+	img := synthetic.Noise(120, 100, 90, 12, 1)
+	bright := synthetic.Noise(120, 100, 170, 12, 2)
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 120; x++ {
+			i := y*img.Stride + x
+			switch {
+			case x < 20 || x >= 100 || y < 15 || y >= 85:
+				img.Pix[i] = uint8(255 * ((x + y) % 2))
+			case x >= 60:
+				img.Pix[i] = bright.Pix[i]
+			}
+		}
+	}
+	roi := image.Rect(20, 15, 100, 85)
+	points, err := parsePolygon("20,15 100,15 100,85 20,85")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mask := rasterizePolygon(points, 120, 100)
+
+	masked, err := imaging.OtsuMask(img, mask)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cropped := imaging.Otsu(img.SubImage(roi).(*image.Gray))
+	maskedLevel := imaging.OtsuLevel(imaging.MaskedHistogram(img, mask))
+	croppedLevel := imaging.OtsuLevel(imaging.Histogram(img.SubImage(roi).(*image.Gray)))
+	if maskedLevel != croppedLevel {
+		t.Fatalf("limiar %d com a ROI, %d no recorte", maskedLevel, croppedLevel)
+	}
+	if whole := imaging.OtsuLevel(imaging.Histogram(img)); whole == croppedLevel {
+		t.Errorf("os pixels de fora não mudam o limiar (%d): o teste não distingue", whole)
+	}
+	for y := roi.Min.Y; y < roi.Max.Y; y++ {
+		for x := roi.Min.X; x < roi.Max.X; x++ {
+			if masked.GrayAt(x, y) != cropped.GrayAt(x-roi.Min.X, y-roi.Min.Y) {
+				t.Fatalf("pixel %d,%d binarizado diferente do recorte", x, y)
+			}
+		}
+	}
+}
+
+func TestRasterizePolygon(t *testing.T) {
+	tests := []struct {
+		name string
+		poly string
+		area int
+	}{
+		{"quadrado alinhado", "2,2 10,2 10,10 2,10", 64},
+		{"sentido anti-horário", "2,2 2,10 10,10 10,2", 64},
+		{"triângulo retângulo", "0,0 16,0 0,16", 15 * 16 / 2}, // centros na hipotenusa ficam fora
+		{"fora da imagem", "-10,-10 40,-10 40,40 -10,40", 20 * 20},
+		// laço em oito: os lóbulos da esquerda e da direita ficam dentro
+		{"laço", "0,0 10,10 10,0 0,10", 50},
+	}
+	for _, tt := range tests {
+		points, err := parsePolygon(tt.poly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mask := rasterizePolygon(points, 20, 20)
+		area := 0
+		for _, v := range mask.Pix {
+			if v == 255 {
+				area++
+			}
+		}
+		if area != tt.area {
+			t.Errorf("%s: área %d, esperado %d", tt.name, area, tt.area)
+		}
+	}
+
+	bowtie, _ := parsePolygon("0,0 10,10 10,0 0,10")
+	if mask := rasterizePolygon(bowtie, 20, 20); mask.GrayAt(2, 5).Y != 255 || mask.GrayAt(5, 2).Y != 0 {
+		t.Error("laço em oito sem os lóbulos laterais")
+	}
+}
+
+func TestParsePolygonErrors(t *testing.T) {
+	for _, s := range []string{"", "1,1 2,2", "1,1 2 3,3", "a,1 2,2 3,3", "1,1,1 2,2 3,3"} {
+		if _, err := parsePolygon(s); err == nil {
+			t.Errorf("%q: sem erro", s)
+		}
+	}
+}
+
+func TestLoadPolygonJSON(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "poço.json")
+	if err := os.WriteFile(good, []byte("[[1, 2], [30, 2], [15.5, 20]]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	short := filepath.Join(dir, "curto.json")
+	if err := os.WriteFile(short, []byte("[[1, 2], [3, 4]]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	points, err := loadPolygon(good)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 3 || points[2] != [2]float64{15.5, 20} {
+		t.Errorf("vértices %v", points)
+	}
+	for _, path := range []string{short, filepath.Join(dir, "nada.json")} {
+		if _, err := loadPolygon(path); err == nil {
+			t.Errorf("%s: sem erro", path)
+		}
+	}
+}
+
+func TestRestrictToROI(t *testing.T) {
+	src := uniformGray(8, 50)
+	mask := image.NewGray(image.Rect(0, 0, 8, 8))
+	for y := 2; y < 6; y++ {
+		for x := 2; x < 6; x++ {
+			mask.Pix[y*mask.Stride+x] = 255
+		}
+	}
+	tests := []struct {
+		name    string
+		fill    int
+		outside uint8
+	}{
+		{"mantém a entrada", -1, 50},
+		{"preenche", 7, 7},
+	}
+	for _, tt := range tests {
+		outputs := []output{{"a.png", uniformGray(8, 200)}, {"menor.png", uniformGray(4, 200)}}
+		restrictToROI(outputs, src, mask, tt.fill)
+		got := outputs[0].img.(*image.Gray)
+		if got.GrayAt(3, 3).Y != 200 || got.GrayAt(0, 0).Y != tt.outside {
+			t.Errorf("%s: dentro %d e fora %d, esperado 200 e %d", tt.name, got.GrayAt(3, 3).Y, got.GrayAt(0, 0).Y, tt.outside)
+		}
+		if small := outputs[1].img.(*image.Gray); small.GrayAt(0, 0).Y != 200 {
+			t.Errorf("%s: saída de outro tamanho foi alterada", tt.name)
+		}
+	}
+}
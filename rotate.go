@@ -0,0 +1,80 @@
+package main
+
+import (
+	"image"
+	"math"
+)
+
+// rotate gira a imagem degrees graus em torno do centro (positivo =
+// horário, pois y cresce para baixo) pelo mapeamento inverso: cada pixel
+// de saída procura a sua origem girando de −θ e interpola por method; o
+// que fica descoberto recebe fill. Com expand a tela cresce até caber a
+// imagem girada inteira; sem ele mantém o tamanho e corta os cantos.
+// Múltiplos de 90° não interpolam: os pixels só trocam de lugar.
+func rotate(img *image.Gray, degrees float64, method interpolation, expand bool, fill uint8) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if turns := degrees / 90; turns == math.Trunc(turns) {
+		return rotateQuarter(img, int(turns), expand, fill)
+	}
+	theta := degrees * math.Pi / 180
+	sin, cos := math.Sin(theta), math.Cos(theta)
+
+	outW, outH := width, height
+	if expand {
+		// a folga tira o erro de arredondamento de casos como 45°
+		outW = int(math.Ceil(float64(width)*math.Abs(cos) + float64(height)*math.Abs(sin) - 1e-9))
+		outH = int(math.Ceil(float64(width)*math.Abs(sin) + float64(height)*math.Abs(cos) - 1e-9))
+	}
+	cx, cy := float64(width-1)/2, float64(height-1)/2
+	ox, oy := float64(outW-1)/2, float64(outH-1)/2
+
+	result := image.NewGray(image.Rect(0, 0, outW, outH))
+	for y := 0; y < outH; y++ {
+		for x := 0; x < outW; x++ {
+			// mapeamento inverso: gira o destino de −θ para achar a origem
+			dx, dy := float64(x)-ox, float64(y)-oy
+			sx := cx + dx*cos + dy*sin
+			sy := cy - dx*sin + dy*cos
+			result.Pix[y*result.Stride+x] = sample(img, sx, sy, fill, method)
+		}
+	}
+	return result
+}
+
+// rotateQuarter gira turns quartos de volta no sentido horário trocando os
+// pixels de lugar (as orientações EXIF 6, 3 e 8). Sem expand, a imagem de
+// 90° ou 270° de um retângulo é centralizada na tela original, cortada e
+// completada com fill; quando a diferença dos lados é ímpar, a sobra de um
+// pixel fica à direita (ou embaixo).
+func rotateQuarter(img *image.Gray, turns int, expand bool, fill uint8) *image.Gray {
+	orientation := [4]int{1, 6, 3, 8}[(turns%4+4)%4]
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	var rotated *image.Gray
+	if orientation == 1 {
+		rotated, _ = crop(img, img.Bounds())
+	} else {
+		rotated = applyOrientation(img, orientation)
+	}
+	if expand || rotated.Bounds().Size() == img.Bounds().Size() {
+		return rotated
+	}
+
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range result.Pix {
+		result.Pix[i] = fill
+	}
+	rw, rh := rotated.Bounds().Dx(), rotated.Bounds().Dy()
+	offX := int(math.Floor(float64(width-rw) / 2))
+	offY := int(math.Floor(float64(height-rh) / 2))
+	for y := max(0, offY); y < min(height, offY+rh); y++ {
+		for x := max(0, offX); x < min(width, offX+rw); x++ {
+			result.Pix[y*result.Stride+x] = rotated.Pix[(y-offY)*rotated.Stride+x-offX]
+		}
+	}
+	return result
+}
+
+// rotateImage é rotate para imagens coloridas também, canal a canal.
+func rotateImage(img image.Image, degrees float64, method interpolation, expand bool, fill uint8) image.Image {
+	return perChannel(img, func(gray *image.Gray) *image.Gray { return rotate(gray, degrees, method, expand, fill) })
+}
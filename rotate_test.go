@@ -0,0 +1,141 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+// randomImage é uma imagem w×h de ruído uniforme.
+func randomImage(w, h int, seed int64) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	rand.New(rand.NewSource(seed)).Read(img.Pix)
+	return img
+}
+
+func sameGray(a, b *image.Gray) bool {
+	if a.Bounds().Size() != b.Bounds().Size() {
+		return false
+	}
+	for y := 0; y < a.Bounds().Dy(); y++ {
+		for x := 0; x < a.Bounds().Dx(); x++ {
+			if a.GrayAt(a.Bounds().Min.X+x, a.Bounds().Min.Y+y) != b.GrayAt(b.Bounds().Min.X+x, b.Bounds().Min.Y+y) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestRotateQuarterTurnsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		img    *image.Gray
+		steps  []float64
+		expand bool
+	}{
+		{"quadrada, 4x90°", randomImage(9, 9, 1), []float64{90, 90, 90, 90}, false},
+		{"retângulo, 4x90° expandindo", randomImage(13, 7, 2), []float64{90, 90, 90, 90}, true},
+		{"retângulo, 4x−90°", randomImage(13, 7, 3), []float64{-90, -90, -90, -90}, true},
+		{"retângulo, 2x180°", randomImage(12, 5, 4), []float64{180, 180}, false},
+		{"270° e 90°", randomImage(6, 10, 5), []float64{270, 90}, true},
+		{"360°", randomImage(8, 3, 6), []float64{360}, false},
+		{"recorte", randomImage(20, 20, 7).SubImage(image.Rect(3, 4, 14, 10)).(*image.Gray), []float64{90, 90, 90, 90}, true},
+	}
+	for _, method := range []interpolation{interpNearest, interpBilinear, interpBicubic} {
+		for _, tt := range tests {
+			got := tt.img
+			for _, step := range tt.steps {
+				got = rotate(got, step, method, tt.expand, 0)
+			}
+			if !sameGray(got, tt.img) {
+				t.Errorf("método %d, %s: a imagem não voltou igual", method, tt.name)
+			}
+		}
+	}
+}
+
+func TestRotateQuarterDirection(t *testing.T) {
+	// horário: o canto de cima à esquerda vai para cima à direita
+	img := randomImage(5, 3, 8)
+	got := rotate(img, 90, interpBilinear, true, 0)
+	if got.Bounds() != image.Rect(0, 0, 3, 5) || got.GrayAt(2, 0) != img.GrayAt(0, 0) || got.GrayAt(0, 4) != img.GrayAt(4, 2) {
+		t.Errorf("90°: limites %v ou cantos errados", got.Bounds())
+	}
+
+	// sem expandir, a imagem em pé fica no meio da tela deitada
+	img = randomImage(6, 4, 9)
+	got = rotate(img, 90, interpBilinear, false, 7)
+	if got.Bounds() != img.Bounds() {
+		t.Fatalf("90° sem expandir: limites %v", got.Bounds())
+	}
+	upright := rotate(img, 90, interpBilinear, true, 0) // 4x6
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 6; x++ {
+			want := uint8(7)
+			if x >= 1 && x < 5 {
+				want = upright.GrayAt(x-1, y+1).Y
+			}
+			if got.GrayAt(x, y).Y != want {
+				t.Fatalf("90° sem expandir: pixel %d,%d vale %d, esperado %d", x, y, got.GrayAt(x, y).Y, want)
+			}
+		}
+	}
+}
+
+func TestRotateExpandDimensions(t *testing.T) {
+	tests := []struct {
+		w, h         int
+		degrees      float64
+		wantW, wantH int
+	}{
+		{10, 10, 45, 15, 15},    // 10·√2 = 14.14
+		{100, 60, 45, 114, 114}, // 160/√2 = 113.14
+		{100, 60, -45, 114, 114},
+		{100, 60, 30, 117, 102}, // 100·cos + 60·sen, 100·sen + 60·cos
+		{100, 60, 90, 60, 100},
+		{100, 60, 135, 114, 114},
+		{100, 60, 37.5, 116, 109},
+	}
+	for _, tt := range tests {
+		got := rotate(image.NewGray(image.Rect(0, 0, tt.w, tt.h)), tt.degrees, interpBilinear, true, 0)
+		if got.Bounds().Dx() != tt.wantW || got.Bounds().Dy() != tt.wantH {
+			t.Errorf("%dx%d a %g°: %v, esperado %dx%d", tt.w, tt.h, tt.degrees, got.Bounds().Size(), tt.wantW, tt.wantH)
+		}
+		same := rotate(image.NewGray(image.Rect(0, 0, tt.w, tt.h)), tt.degrees, interpBilinear, false, 0)
+		if same.Bounds() != image.Rect(0, 0, tt.w, tt.h) {
+			t.Errorf("%dx%d a %g° sem expandir: %v", tt.w, tt.h, tt.degrees, same.Bounds())
+		}
+	}
+}
+
+func TestRotateFillAndInterior(t *testing.T) {
+	img := uniformGray(41, 120)
+	for _, method := range []interpolation{interpNearest, interpBilinear, interpBicubic} {
+		for _, expand := range []bool{false, true} {
+			got := rotate(img, 45, method, expand, 9)
+			w, h := got.Bounds().Dx(), got.Bounds().Dy()
+			// os cantos ficam descobertos e o meio continua uniforme
+			if got.GrayAt(0, 0).Y != 9 || got.GrayAt(w-1, h-1).Y != 9 {
+				t.Errorf("método %d, expandir %v: cantos %d e %d, esperado 9", method, expand, got.GrayAt(0, 0).Y, got.GrayAt(w-1, h-1).Y)
+			}
+			for y := h/2 - 10; y <= h/2+10; y++ {
+				for x := w/2 - 10; x <= w/2+10; x++ {
+					if got.GrayAt(x, y).Y != 120 {
+						t.Fatalf("método %d, expandir %v: pixel %d,%d vale %d", method, expand, x, y, got.GrayAt(x, y).Y)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRotateImageColor(t *testing.T) {
+	rgba := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	rgba.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	got := rotateImage(rgba, 180, interpBilinear, false, 0)
+	if r, g, _, _ := got.At(3, 1).RGBA(); r>>8 != 255 || g != 0 {
+		t.Errorf("180° colorido: canto (%d, %d), esperado vermelho", r>>8, g>>8)
+	}
+}
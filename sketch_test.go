@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func TestPencilSketch(t *testing.T) {
+	result := pencilSketch(stepImage(32), 4, false, 0)
+	tests := []struct {
+		name string
+		x    int
+		min  uint8
+		max  uint8
+	}{
+		{"região escura lisa", 4, 250, 255},
+		{"região clara lisa", 60, 250, 255},
+		{"traço no lado escuro da borda", 31, 0, 100},
+	}
+	for _, tt := range tests {
+		for y := 0; y < 64; y++ {
+			if v := result.Pix[y*result.Stride+tt.x]; v < tt.min || v > tt.max {
+				t.Fatalf("%s: pixel %d,%d = %d, esperado entre %d e %d", tt.name, tt.x, y, v, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestPencilSketchBlack(t *testing.T) {
+	// imagem preta: a invertida borrada é 255 e o dodge divide por zero
+	img := image.NewGray(image.Rect(0, 0, 16, 16))
+	for _, v := range pencilSketch(img, 3, false, 0).Pix {
+		if v != 255 {
+			t.Fatalf("pixel %d numa imagem preta, esperado 255", v)
+		}
+	}
+}
+
+func TestPencilSketchTexture(t *testing.T) {
+	img := stepImage(32)
+	plain := pencilSketch(img, 4, false, 0)
+	a := pencilSketch(img, 4, true, 7)
+	b := pencilSketch(img, 4, true, 7)
+	if !bytes.Equal(a.Pix, b.Pix) {
+		t.Error("mesma semente gerou texturas diferentes")
+	}
+	if bytes.Equal(a.Pix, plain.Pix) {
+		t.Error("textura não mudou a imagem")
+	}
+	for i := range a.Pix {
+		if a.Pix[i] > plain.Pix[i] {
+			t.Fatalf("textura clareou o pixel %d: %d > %d", i, a.Pix[i], plain.Pix[i])
+		}
+	}
+}
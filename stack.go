@@ -0,0 +1,298 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// checkStack valida que a pilha não é vazia e que todas as imagens têm as
+// dimensões da primeira.
+func checkStack(imgs []*image.Gray) (int, int, error) {
+	if len(imgs) == 0 {
+		return 0, 0, errors.New("pilha vazia")
+	}
+	size := imgs[0].Bounds().Size()
+	for i, img := range imgs[1:] {
+		if s := img.Bounds().Size(); s != size {
+			return 0, 0, fmt.Errorf("imagem %d tem %dx%d, esperado %dx%d", i+1, s.X, s.Y, size.X, size.Y)
+		}
+	}
+	return size.X, size.Y, nil
+}
+
+// stackPixels chama f para cada pixel com os valores de todas as imagens
+// naquela posição e grava o resultado.
+func stackPixels(imgs []*image.Gray, f func(values []uint8) uint8) (*image.Gray, error) {
+	width, height, err := checkStack(imgs)
+	if err != nil {
+		return nil, err
+	}
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	values := make([]uint8, len(imgs))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			for i, img := range imgs {
+				b := img.Bounds()
+				values[i] = img.Pix[img.PixOffset(b.Min.X+x, b.Min.Y+y)]
+			}
+			result.Pix[y*result.Stride+x] = f(values)
+		}
+	}
+	return result, nil
+}
+
+// stackAverage faz a média das exposições; o ruído cai com √N. A soma é em
+// uint32, então nem milhões de quadros transbordam.
+func stackAverage(imgs []*image.Gray) (*image.Gray, error) {
+	return stackPixels(imgs, func(values []uint8) uint8 {
+		var sum uint32
+		for _, v := range values {
+			sum += uint32(v)
+		}
+		return uint8((sum + uint32(len(values))/2) / uint32(len(values)))
+	})
+}
+
+// stackMedian usa a mediana por pixel, que descarta artefatos presentes
+// em poucos quadros.
+func stackMedian(imgs []*image.Gray) (*image.Gray, error) {
+	sorted := make([]uint8, len(imgs))
+	return stackPixels(imgs, func(values []uint8) uint8 {
+		copy(sorted, values)
+		sort.Slice(sorted, func(a, b int) bool { return sorted[a] < sorted[b] })
+		n := len(sorted)
+		if n%2 == 1 {
+			return sorted[n/2]
+		}
+		return uint8((int(sorted[n/2-1]) + int(sorted[n/2]) + 1) / 2)
+	})
+}
+
+// stackSigmaClip é a média com rejeição sigma por pixel: valores a mais de
+// kappa desvios da média (raios cósmicos, reflexos) são descartados e a
+// média é refeita, até não haver mais rejeições (no máximo 5 passadas).
+func stackSigmaClip(imgs []*image.Gray, kappa float64) (*image.Gray, error) {
+	keep := make([]bool, len(imgs))
+	return stackPixels(imgs, func(values []uint8) uint8 {
+		for i := range keep {
+			keep[i] = true
+		}
+		var mean float64
+		for iter := 0; iter < 5; iter++ {
+			var sum, sumSq, n float64
+			for i, v := range values {
+				if keep[i] {
+					sum += float64(v)
+					sumSq += float64(v) * float64(v)
+					n++
+				}
+			}
+			mean = sum / n
+			std := math.Sqrt(math.Max(sumSq/n-mean*mean, 0))
+			rejected := false
+			for i, v := range values {
+				if keep[i] && math.Abs(float64(v)-mean) > kappa*std && n > 2 {
+					keep[i] = false
+					rejected = true
+				}
+			}
+			if !rejected {
+				break
+			}
+		}
+		return uint8(math.Round(mean))
+	})
+}
+
+// projection acumula a projeção de máximo ou mínimo uma fatia por vez,
+// para pilhas que não cabem inteiras na memória. depth guarda o índice da
+// fatia vencedora de cada pixel (em empate fica a primeira).
+type projection struct {
+	takeMax bool
+	extreme *image.Gray
+	depth   [][]int
+	n       int
+}
+
+func newProjection(takeMax bool) *projection {
+	return &projection{takeMax: takeMax}
+}
+
+// add acrescenta a próxima fatia.
+func (p *projection) add(img *image.Gray) error {
+	b := img.Bounds()
+	if p.extreme == nil {
+		p.extreme = image.NewGray(image.Rect(0, 0, b.Dx(), b.Dy()))
+		p.depth = make([][]int, b.Dy())
+		for y := range p.depth {
+			p.depth[y] = make([]int, b.Dx())
+			copy(p.extreme.Pix[y*p.extreme.Stride:], img.Pix[img.PixOffset(b.Min.X, b.Min.Y+y):img.PixOffset(b.Max.X, b.Min.Y+y)])
+		}
+		p.n = 1
+		return nil
+	}
+	if b.Size() != p.extreme.Bounds().Size() {
+		return fmt.Errorf("fatia %d tem %dx%d, esperado %dx%d", p.n, b.Dx(), b.Dy(), p.extreme.Bounds().Dx(), p.extreme.Bounds().Dy())
+	}
+	for y := 0; y < b.Dy(); y++ {
+		row := img.Pix[img.PixOffset(b.Min.X, b.Min.Y+y):]
+		out := p.extreme.Pix[y*p.extreme.Stride:]
+		for x := 0; x < b.Dx(); x++ {
+			if p.takeMax && row[x] > out[x] || !p.takeMax && row[x] < out[x] {
+				out[x] = row[x]
+				p.depth[y][x] = p.n
+			}
+		}
+	}
+	p.n++
+	return nil
+}
+
+// depthImage mostra o índice da fatia vencedora escalado para 0–255.
+func (p *projection) depthImage() *image.Gray {
+	result := image.NewGray(p.extreme.Bounds())
+	scale := 255 / float64(max(p.n-1, 1))
+	for y, row := range p.depth {
+		for x, i := range row {
+			result.Pix[y*result.Stride+x] = uint8(math.Round(float64(i) * scale))
+		}
+	}
+	return result
+}
+
+// stackProject é a projeção de máximo (takeMax) ou mínimo da pilha, com o
+// mapa de profundidade.
+func stackProject(imgs []*image.Gray, takeMax bool) (*image.Gray, [][]int, error) {
+	if len(imgs) == 0 {
+		return nil, nil, errors.New("pilha vazia")
+	}
+	p := newProjection(takeMax)
+	for _, img := range imgs {
+		if err := p.add(img); err != nil {
+			return nil, nil, err
+		}
+	}
+	return p.extreme, p.depth, nil
+}
+
+// stackMax é a projeção de intensidade máxima (MIP).
+func stackMax(imgs []*image.Gray) (*image.Gray, error) {
+	result, _, err := stackProject(imgs, true)
+	return result, err
+}
+
+// stackMin é a projeção de intensidade mínima.
+func stackMin(imgs []*image.Gray) (*image.Gray, error) {
+	result, _, err := stackProject(imgs, false)
+	return result, err
+}
+
+// stackFiles expande os padrões (globs) em arquivos, em ordem alfabética
+// dentro de cada padrão.
+func stackFiles(patterns []string) ([]string, error) {
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("padrão inválido %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("nenhum arquivo em %q", pattern)
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// gotoshop stack [-stack mean|median|sigclip | -project max|min] [-out stacked.png] "quadros/*.png"
+func runStack(args []string) int {
+	fs := newFlagSet("stack", "[-stack mean|median|sigclip | -project max|min [-depth depth.png]] [-out stacked.png] \"quadros/*.png\"")
+	mode := fs.String("stack", "mean", "combinação: mean, median ou sigclip")
+	project := fs.String("project", "", "projeção de intensidade: max ou min (lê uma fatia por vez)")
+	depthPath := fs.String("depth", "", "com -project, salva o índice da fatia vencedora de cada pixel")
+	kappa := fs.Float64("kappa", 3, "desvios para a rejeição do sigclip")
+	out := fs.String("out", "stacked.png", "imagem combinada")
+
+	patterns, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(patterns) == 0 {
+		fs.Usage()
+		return 2
+	}
+	files, err := stackFiles(patterns)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if *project != "" {
+		if *project != "max" && *project != "min" {
+			fmt.Fprintf(os.Stderr, "projeção desconhecida %q, use max ou min\n", *project)
+			return 2
+		}
+		p := newProjection(*project == "max")
+		for _, file := range files {
+			img, err := loadImage(file)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			if err := p.add(img); err != nil {
+				fmt.Fprintf(os.Stderr, "Erro em %s: %v\n", file, err)
+				return 1
+			}
+		}
+		if err := saveImage(*out, p.extreme); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if *depthPath != "" {
+			if err := saveImage(*depthPath, p.depthImage()); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+		}
+		fmt.Printf("Projeção %s de %d fatias em %s\n", *project, len(files), *out)
+		return 0
+	}
+
+	imgs := make([]*image.Gray, len(files))
+	for i, file := range files {
+		if imgs[i], err = loadImage(file); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	var result *image.Gray
+	switch *mode {
+	case "mean":
+		result, err = stackAverage(imgs)
+	case "median":
+		result, err = stackMedian(imgs)
+	case "sigclip":
+		result, err = stackSigmaClip(imgs, *kappa)
+	default:
+		fmt.Fprintf(os.Stderr, "combinação desconhecida %q, use mean, median ou sigclip\n", *mode)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Erro ao combinar:", err)
+		return 1
+	}
+
+	if err := saveImage(*out, result); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("%d imagens combinadas (%s) em %s\n", len(files), *mode, *out)
+	return 0
+}
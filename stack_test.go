@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// noisyFrames gera n cópias de base com ruído gaussiano de sementes distintas.
+func noisyFrames(t *testing.T, base *image.Gray, n int, sigma float64) []*image.Gray {
+	t.Helper()
+	frames := make([]*image.Gray, n)
+	for i := range frames {
+		var err error
+		if frames[i], err = synthetic.AddGaussianNoise(base, sigma, int64(i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return frames
+}
+
+func TestStackAverageReducesNoise(t *testing.T) {
+	const sigma = 16
+	base := uniformGray(128, 128)
+	whole := base.Bounds()
+	for _, n := range []int{1, 4, 16} {
+		result, err := stackAverage(noisyFrames(t, base, n, sigma))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := sigma / math.Sqrt(float64(n))
+		if got := regionStd(result, whole); math.Abs(got-want) > 0.15*want {
+			t.Errorf("%d quadros: desvio residual %.2f, esperado ≈ %.2f", n, got, want)
+		}
+	}
+}
+
+func TestStackRejectsArtifact(t *testing.T) {
+	frames := noisyFrames(t, uniformGray(32, 100), 9, 3)
+	// um "raio cósmico" saturado em um único quadro
+	for y := 10; y < 14; y++ {
+		for x := 10; x < 14; x++ {
+			frames[4].Pix[y*frames[4].Stride+x] = 255
+		}
+	}
+	artifact := image.Rect(10, 10, 14, 14)
+
+	tests := []struct {
+		name  string
+		stack func([]*image.Gray) (*image.Gray, error)
+	}{
+		{"median", stackMedian},
+		{"sigclip", func(imgs []*image.Gray) (*image.Gray, error) { return stackSigmaClip(imgs, 2) }},
+	}
+	for _, tt := range tests {
+		result, err := tt.stack(frames)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m := meanIn(result, artifact); math.Abs(m-100) > 3 {
+			t.Errorf("%s: média %.1f no artefato, esperado ≈ 100", tt.name, m)
+		}
+	}
+
+	mean, err := stackAverage(frames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := meanIn(mean, artifact); m < 110 {
+		t.Errorf("mean: média %.1f no artefato, esperado que o artefato vazasse", m)
+	}
+}
+
+func TestStackMedianEven(t *testing.T) {
+	frames := []*image.Gray{uniformGray(2, 10), uniformGray(2, 20), uniformGray(2, 31), uniformGray(2, 200)}
+	result, err := stackMedian(frames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := result.Pix[0]; v != 26 {
+		t.Errorf("mediana %d, esperado 26 (média arredondada de 20 e 31)", v)
+	}
+}
+
+func TestStackValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		imgs []*image.Gray
+	}{
+		{"vazia", nil},
+		{"dimensões diferentes", []*image.Gray{uniformGray(8, 0), uniformGray(9, 0)}},
+	}
+	for _, tt := range tests {
+		for mode, stack := range map[string]func([]*image.Gray) (*image.Gray, error){
+			"mean": stackAverage, "median": stackMedian,
+		} {
+			if _, err := stack(tt.imgs); err == nil {
+				t.Errorf("%s/%s: sem erro", tt.name, mode)
+			}
+		}
+	}
+}
+
+// diskSlices gera uma pilha de fatias escuras, cada uma com um disco claro
+// num ponto diferente.
+func diskSlices(centers []image.Point) []*image.Gray {
+	slices := make([]*image.Gray, len(centers))
+	for i, c := range centers {
+		slices[i] = uniformGray(64, 20)
+		synthetic.FillCircle(slices[i], c, 5, 200)
+	}
+	return slices
+}
+
+func TestStackProjection(t *testing.T) {
+	centers := []image.Point{{12, 12}, {50, 12}, {12, 50}, {50, 50}, {32, 32}}
+	slices := diskSlices(centers)
+
+	mip, depth, err := stackProject(slices, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range centers {
+		if v := mip.GrayAt(c.X, c.Y).Y; v != 200 {
+			t.Errorf("disco %d: MIP %d no centro, esperado 200", i, v)
+		}
+		if d := depth[c.Y][c.X]; d != i {
+			t.Errorf("disco %d: profundidade %d, esperado %d", i, d, i)
+		}
+	}
+	// fora dos discos todas as fatias empatam e vence a primeira
+	if v, d := mip.GrayAt(32, 5).Y, depth[5][32]; v != 20 || d != 0 {
+		t.Errorf("fundo: MIP %d e profundidade %d, esperado 20 e 0", v, d)
+	}
+
+	lowest, err := stackMin(slices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range centers {
+		if v := lowest.GrayAt(c.X, c.Y).Y; v != 20 {
+			t.Errorf("disco %d: mínimo %d no centro, esperado 20", i, v)
+		}
+	}
+
+	if _, err := stackMax(append(slices, uniformGray(32, 0))); err == nil {
+		t.Error("fatia de tamanho diferente sem erro")
+	}
+	if _, err := stackMax(nil); err == nil {
+		t.Error("pilha vazia sem erro")
+	}
+}
+
+func TestRunStackProject(t *testing.T) {
+	dir := t.TempDir()
+	centers := []image.Point{{12, 12}, {50, 50}, {32, 32}}
+	for i, slice := range diskSlices(centers) {
+		if err := saveImage(filepath.Join(dir, fmt.Sprintf("z%d.png", i)), slice); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out, depthPath := filepath.Join(dir, "mip.png"), filepath.Join(dir, "depth.png")
+	if code := runStack([]string{"-project", "max", "-out", out, "-depth", depthPath, filepath.Join(dir, "z*.png")}); code != 0 {
+		t.Fatalf("código %d, esperado 0", code)
+	}
+	mip, err := loadImage(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	depth, err := loadImage(depthPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// com 3 fatias a profundidade é escalada para 0, 128 e 255
+	for i, want := range []uint8{0, 128, 255} {
+		c := centers[i]
+		if v := mip.GrayAt(c.X, c.Y).Y; v != 200 {
+			t.Errorf("fatia %d: MIP %d, esperado 200", i, v)
+		}
+		if v := depth.GrayAt(c.X, c.Y).Y; v != want {
+			t.Errorf("fatia %d: profundidade %d, esperado %d", i, v, want)
+		}
+	}
+
+	if code := runStack([]string{"-project", "mean", filepath.Join(dir, "z*.png")}); code != 2 {
+		t.Errorf("projeção inválida: código %d, esperado 2", code)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"os"
+
+	"processing-images/imaging"
+)
+
+// ChannelStats são as estatísticas de intensidade de um canal, todas
+// tiradas do histograma (mediana e percentis sem ordenar os pixels).
+type ChannelStats struct {
+	Min     uint8   `json:"min"`
+	Max     uint8   `json:"max"`
+	Mean    float64 `json:"mean"`
+	Median  uint8   `json:"median"`
+	StdDev  float64 `json:"stddev"`
+	P1      uint8   `json:"p1"`
+	P5      uint8   `json:"p5"`
+	P50     uint8   `json:"p50"`
+	P95     uint8   `json:"p95"`
+	P99     uint8   `json:"p99"`
+	Entropy float64 `json:"entropy"` // bits por pixel
+	Zero    float64 `json:"saturated_0"`
+	Full    float64 `json:"saturated_255"`
+	Otsu    uint8   `json:"otsu"` // limiar de Otsu do canal
+}
+
+// ImageStats resume a imagem; Channels tem "gray" e, em imagens coloridas,
+// também "red", "green" e "blue".
+type ImageStats struct {
+	Width    int                     `json:"width"`
+	Height   int                     `json:"height"`
+	BitDepth int                     `json:"bit_depth"`
+	Color    bool                    `json:"color"`
+	Channels map[string]ChannelStats `json:"channels"`
+}
+
+// histogramStats calcula as estatísticas a partir do histograma.
+func histogramStats(histogram [256]int) ChannelStats {
+	var s ChannelStats
+	total := 0
+	for _, c := range histogram {
+		total += c
+	}
+	if total == 0 {
+		return s
+	}
+	n := float64(total)
+
+	s.Min, s.Max = 255, 0
+	var sum, sumSq float64
+	for v, c := range histogram {
+		if c == 0 {
+			continue
+		}
+		s.Min, s.Max = min(s.Min, uint8(v)), max(s.Max, uint8(v))
+		sum += float64(v) * float64(c)
+		sumSq += float64(v) * float64(v) * float64(c)
+		p := float64(c) / n
+		s.Entropy -= p * math.Log2(p)
+	}
+	s.Mean = sum / n
+	s.StdDev = math.Sqrt(math.Max(sumSq/n-s.Mean*s.Mean, 0))
+	s.Median = imaging.HistogramPercentile(histogram, 0.5)
+	s.P1 = imaging.HistogramPercentile(histogram, 0.01)
+	s.P5 = imaging.HistogramPercentile(histogram, 0.05)
+	s.P50 = s.Median
+	s.P95 = imaging.HistogramPercentile(histogram, 0.95)
+	s.P99 = imaging.HistogramPercentile(histogram, 0.99)
+	s.Zero = float64(histogram[0]) / n
+	s.Full = float64(histogram[255]) / n
+	s.Otsu = imaging.OtsuLevel(histogram)
+	return s
+}
+
+// imageStats calcula as estatísticas da imagem decodificada.
+func imageStats(img image.Image) ImageStats {
+	stats := ImageStats{
+		Width:    img.Bounds().Dx(),
+		Height:   img.Bounds().Dy(),
+		BitDepth: 8,
+		Channels: map[string]ChannelStats{},
+	}
+	stats.Channels["gray"] = histogramStats(imaging.Histogram(grayscale(img)))
+
+	if _, ok := img.(*image.Gray); !ok {
+		stats.Color = true
+		histograms := computeHistogramRGB(toRGBA(img))
+		for i, name := range []string{"red", "green", "blue"} {
+			stats.Channels[name] = histogramStats(histograms[i])
+		}
+	}
+	return stats
+}
+
+// printStats mostra as estatísticas de forma legível.
+func printStats(path string, s ImageStats) {
+	fmt.Printf("%s: %dx%d, %d bits", path, s.Width, s.Height, s.BitDepth)
+	if s.Color {
+		fmt.Print(", colorida")
+	}
+	fmt.Println()
+	for _, name := range []string{"gray", "red", "green", "blue"} {
+		c, ok := s.Channels[name]
+		if !ok {
+			continue
+		}
+		fmt.Printf("  %-5s min %3d  max %3d  média %7.2f  mediana %3d  desvio %7.2f  entropia %.3f\n",
+			name, c.Min, c.Max, c.Mean, c.Median, c.StdDev, c.Entropy)
+		fmt.Printf("        p1 %d  p5 %d  p50 %d  p95 %d  p99 %d  em 0: %.2f%%  em 255: %.2f%%  otsu %d\n",
+			c.P1, c.P5, c.P50, c.P95, c.P99, 100*c.Zero, 100*c.Full, c.Otsu)
+	}
+}
+
+// gotoshop stats [-json stats.json] imagem...
+func runStats(args []string) int {
+	fs := newFlagSet("stats", "[-json stats.json] imagem...")
+	jsonPath := fs.String("json", "", "exporta as estatísticas em JSON (lista se houver várias imagens)")
+	plotPath := fs.String("plot", "", "salva o gráfico do histograma (canais R, G e B nas suas cores) da primeira imagem")
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	var all []ImageStats
+	for _, file := range files {
+		img, _, err := decodeImage(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		s := imageStats(img)
+		printStats(file, s)
+		all = append(all, s)
+
+		if *plotPath != "" && len(all) == 1 {
+			if err := saveImage(*plotPath, statsPlot(img)); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+		}
+	}
+
+	if *jsonPath != "" {
+		var v any = all
+		if len(all) == 1 {
+			v = all[0]
+		}
+		if err := writeJSON(*jsonPath, v); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// statsPlot desenha o histograma de cada canal (ou só o cinza) com 50% de
+// opacidade.
+func statsPlot(img image.Image) *image.RGBA {
+	if gray, ok := img.(*image.Gray); ok {
+		return histogramPlot([][256]int{imaging.Histogram(gray)}, []color.RGBA{{255, 255, 255, 255}}, 200)
+	}
+	h := computeHistogramRGB(toRGBA(img))
+	return histogramPlot(h[:], []color.RGBA{{255, 0, 0, 128}, {0, 255, 0, 128}, {0, 0, 255, 128}}, 200)
+}
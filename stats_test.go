@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHistogramStats(t *testing.T) {
+	var constant, twoValue, ramp [256]int
+	constant[100] = 64
+	twoValue[0], twoValue[255] = 8, 8
+	for v := range ramp {
+		ramp[v] = 1
+	}
+	tests := []struct {
+		name      string
+		histogram [256]int
+		want      ChannelStats
+	}{
+		{"constante", constant, ChannelStats{
+			Min: 100, Max: 100, Mean: 100, Median: 100, StdDev: 0,
+			P1: 100, P5: 100, P50: 100, P95: 100, P99: 100, Entropy: 0,
+		}},
+		{"dois valores", twoValue, ChannelStats{
+			Min: 0, Max: 255, Mean: 127.5, Median: 0, StdDev: 127.5,
+			P1: 0, P5: 0, P50: 0, P95: 255, P99: 255, Entropy: 1, Zero: 0.5, Full: 0.5,
+		}},
+		// 0..255 uma vez cada: o percentil p é o nível ceil(256p) − 1
+		{"degradê completo", ramp, ChannelStats{
+			Min: 0, Max: 255, Mean: 127.5, Median: 127, StdDev: math.Sqrt((256*256 - 1) / 12.0),
+			P1: 2, P5: 12, P50: 127, P95: 243, P99: 253, Entropy: 8, Zero: 1.0 / 256, Full: 1.0 / 256,
+		}},
+		{"vazio", [256]int{}, ChannelStats{}},
+	}
+	for _, tt := range tests {
+		got := histogramStats(tt.histogram)
+		got.Otsu, tt.want.Otsu = 0, 0 // verificado à parte
+		near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+		if got.Min != tt.want.Min || got.Max != tt.want.Max || got.Median != tt.want.Median ||
+			got.P1 != tt.want.P1 || got.P5 != tt.want.P5 || got.P50 != tt.want.P50 ||
+			got.P95 != tt.want.P95 || got.P99 != tt.want.P99 ||
+			!near(got.Mean, tt.want.Mean) || !near(got.StdDev, tt.want.StdDev) ||
+			!near(got.Entropy, tt.want.Entropy) || !near(got.Zero, tt.want.Zero) || !near(got.Full, tt.want.Full) {
+			t.Errorf("%s:\n %+v\nesperado\n %+v", tt.name, got, tt.want)
+		}
+	}
+
+	// o limiar de Otsu de dois valores separa os dois
+	if otsu := histogramStats(twoValue).Otsu; otsu == 255 {
+		t.Errorf("limiar de Otsu %d não separa 0 de 255", otsu)
+	}
+}
+
+func TestImageStats(t *testing.T) {
+	gray := uniformGray(10, 50)
+	s := imageStats(gray)
+	if s.Width != 10 || s.Height != 10 || s.BitDepth != 8 || s.Color || len(s.Channels) != 1 {
+		t.Errorf("imagem cinza: %+v", s)
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	for i := 0; i < 12; i++ {
+		rgba.SetRGBA(i%4, i/4, color.RGBA{200, 100, 50, 255})
+	}
+	s = imageStats(rgba)
+	if !s.Color || s.Width != 4 || s.Height != 3 {
+		t.Errorf("imagem colorida: %+v", s)
+	}
+	for name, want := range map[string]uint8{"red": 200, "green": 100, "blue": 50} {
+		if c := s.Channels[name]; c.Min != want || c.Max != want || c.Mean != float64(want) {
+			t.Errorf("canal %s: %+v, esperado constante %d", name, c, want)
+		}
+	}
+	if _, ok := s.Channels["gray"]; !ok {
+		t.Error("imagem colorida sem o canal gray")
+	}
+}
+
+func TestRunStatsJSON(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.png")
+	if err := saveImage(path, uniformGray(8, 77)); err != nil {
+		t.Fatal(err)
+	}
+	jsonPath := filepath.Join(dir, "stats.json")
+	if code := runStats([]string{"-json", jsonPath, path}); code != 0 {
+		t.Fatalf("código de saída %d", code)
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s ImageStats
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Channels["gray"].Mean != 77 || s.Width != 8 {
+		t.Errorf("JSON %s", data)
+	}
+	if code := runStats([]string{filepath.Join(dir, "nada.png")}); code != 1 {
+		t.Errorf("arquivo que não existe: código %d, esperado 1", code)
+	}
+}
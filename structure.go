@@ -0,0 +1,120 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"processing-images/imaging"
+)
+
+// structureTensor calcula, por pixel, a orientação da estrutura local
+// (perpendicular ao gradiente dominante, em [0, π)) e a coerência
+// ((λ1−λ2)/(λ1+λ2))², a partir dos produtos Ix², Iy² e IxIy suavizados por
+// uma gaussiana de desvio sigma. Coerência perto de 1 indica fibras
+// alinhadas; perto de 0, textura isotrópica.
+func structureTensor(img *image.Gray, sigma float64) (orientation, coherence [][]float64) {
+	gx, gy := imaging.SobelComponents(img)
+	height := len(gx)
+	jxx := make([][]float64, height)
+	jyy := make([][]float64, height)
+	jxy := make([][]float64, height)
+	for y := range gx {
+		jxx[y] = make([]float64, len(gx[y]))
+		jyy[y] = make([]float64, len(gx[y]))
+		jxy[y] = make([]float64, len(gx[y]))
+		for x := range gx[y] {
+			jxx[y][x] = gx[y][x] * gx[y][x]
+			jyy[y][x] = gy[y][x] * gy[y][x]
+			jxy[y][x] = gx[y][x] * gy[y][x]
+		}
+	}
+	jxx, jyy, jxy = imaging.SmoothFloat(jxx, sigma), imaging.SmoothFloat(jyy, sigma), imaging.SmoothFloat(jxy, sigma)
+
+	orientation = make([][]float64, height)
+	coherence = make([][]float64, height)
+	for y := range jxx {
+		orientation[y] = make([]float64, len(jxx[y]))
+		coherence[y] = make([]float64, len(jxx[y]))
+		for x := range jxx[y] {
+			a, b, c := jxx[y][x], jyy[y][x], jxy[y][x]
+			// direção do gradiente dominante + 90° = direção da estrutura
+			theta := 0.5*math.Atan2(2*c, a-b) + math.Pi/2
+			orientation[y][x] = math.Mod(theta+math.Pi, math.Pi)
+
+			trace := a + b
+			if trace > 1e-9 {
+				// (λ1−λ2)² = (a−b)² + 4c², λ1+λ2 = a+b
+				coherence[y][x] = ((a-b)*(a-b) + 4*c*c) / (trace * trace)
+			}
+		}
+	}
+
+	return orientation, coherence
+}
+
+// meanCoherence faz a média da coerência nos pixels brancos da máscara (ou em
+// toda a imagem quando mask é nil).
+func meanCoherence(coherence [][]float64, mask *image.Gray) float64 {
+	var sum float64
+	n := 0
+	for y := range coherence {
+		for x := range coherence[y] {
+			if mask != nil && (x >= mask.Bounds().Dx() || y >= mask.Bounds().Dy() || mask.Pix[y*mask.Stride+x] < 128) {
+				continue
+			}
+			sum += coherence[y][x]
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// orientationHSV mostra a orientação como matiz e a coerência como brilho.
+func orientationHSV(orientation, coherence [][]float64) *image.RGBA {
+	height := len(orientation)
+	width := 0
+	if height > 0 {
+		width = len(orientation[0])
+	}
+	result := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			hue := orientation[y][x] / math.Pi * 360
+			result.SetRGBA(x, y, hsvToRGB(hue, 1, coherence[y][x]))
+		}
+	}
+	return result
+}
+
+// hsvToRGB converte matiz em graus e saturação/valor em [0, 1].
+func hsvToRGB(h, s, v float64) color.RGBA {
+	h = math.Mod(h, 360)
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := v - c
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+
+	to8 := func(f float64) uint8 {
+		return uint8(math.Round(math.Max(0, math.Min(1, f+m)) * 255))
+	}
+	return color.RGBA{to8(r), to8(g), to8(b), 255}
+}
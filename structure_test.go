@@ -0,0 +1,84 @@
+package main
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// stripes gera listras senoidais de período 8 cuja intensidade varia ao longo
+// do ângulo phi; as listras ficam perpendiculares a ele.
+func stripes(size int, phi float64) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			t := float64(x)*math.Cos(phi) + float64(y)*math.Sin(phi)
+			img.Pix[y*img.Stride+x] = uint8(math.Round(128 + 100*math.Sin(2*math.Pi*t/8)))
+		}
+	}
+	return img
+}
+
+// angleDiff é a distância entre duas orientações módulo π.
+func angleDiff(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), math.Pi)
+	return math.Min(d, math.Pi-d)
+}
+
+func TestStructureTensorStripes(t *testing.T) {
+	tests := []struct {
+		name string
+		phi  float64 // direção em que a intensidade varia
+		want float64 // orientação esperada das listras
+	}{
+		{"listras verticais", 0, math.Pi / 2},
+		{"listras horizontais", math.Pi / 2, 0},
+		{"listras a 45°", math.Pi / 4, 3 * math.Pi / 4},
+		{"listras a 135°", 3 * math.Pi / 4, math.Pi / 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orientation, coherence := structureTensor(stripes(64, tt.phi), 2)
+			// longe da borda, onde a borda replicada entorta o gradiente
+			for y := 8; y < 56; y++ {
+				for x := 8; x < 56; x++ {
+					if coherence[y][x] < 0.9 {
+						t.Fatalf("coerência %.3f em %d,%d, esperado perto de 1", coherence[y][x], x, y)
+					}
+					if d := angleDiff(orientation[y][x], tt.want); d > 0.05 {
+						t.Fatalf("orientação %.3f em %d,%d, esperado %.3f", orientation[y][x], x, y, tt.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestStructureTensorNoise(t *testing.T) {
+	_, coherence := structureTensor(synthetic.Noise(128, 128, 128, 40, 1), 2)
+	if c := meanCoherence(coherence, nil); c > 0.2 {
+		t.Errorf("coerência média %.3f no ruído branco, esperado perto de 0", c)
+	}
+}
+
+func TestMeanCoherenceMask(t *testing.T) {
+	coherence := [][]float64{{1, 0}, {0, 0.5}}
+	mask := image.NewGray(image.Rect(0, 0, 2, 2))
+	mask.Pix[0], mask.Pix[3] = 255, 255
+	tests := []struct {
+		name string
+		mask *image.Gray
+		want float64
+	}{
+		{"sem máscara", nil, 0.375},
+		{"com máscara", mask, 0.75},
+		{"máscara vazia", image.NewGray(image.Rect(0, 0, 2, 2)), 0},
+	}
+	for _, tt := range tests {
+		if got := meanCoherence(coherence, tt.mask); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s: média %v, esperado %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"image"
+	"image/draw"
+	"sort"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// offsetScene devolve a mesma cena como SubImage que começa em (100, 50) e
+// como cópia com origem em (0, 0)
+func offsetScene(t *testing.T) (sub, standalone *image.Gray) {
+	t.Helper()
+	scene, _ := synthetic.Squares(260, 180, 6, 24, 3)
+	noisy, err := synthetic.AddGaussianNoise(scene, 6, 11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// fora do recorte, pixels extremos que mudariam qualquer resultado
+	// calculado sobre a região errada
+	full := image.NewGray(image.Rect(0, 0, 400, 300))
+	for i := range full.Pix {
+		full.Pix[i] = uint8(255 * (i % 2))
+	}
+	r := image.Rect(100, 50, 360, 230)
+	draw.Draw(full, r, noisy, image.Point{}, draw.Src)
+	sub = full.SubImage(r).(*image.Gray)
+	standalone = image.NewGray(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(standalone, standalone.Bounds(), sub, r.Min, draw.Src)
+	return sub, standalone
+}
+
+// sameImage compara pixel a pixel a partir da origem de cada imagem
+func sameImage(a, b image.Image) bool {
+	if a.Bounds().Size() != b.Bounds().Size() {
+		return false
+	}
+	for y := 0; y < a.Bounds().Dy(); y++ {
+		for x := 0; x < a.Bounds().Dx(); x++ {
+			r1, g1, b1, a1 := a.At(a.Bounds().Min.X+x, a.Bounds().Min.Y+y).RGBA()
+			r2, g2, b2, a2 := b.At(b.Bounds().Min.X+x, b.Bounds().Min.Y+y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestOperationsOnSubImage(t *testing.T) {
+	sub, standalone := offsetScene(t)
+	// notch não tem centros padrão: os picos vêm de -auto
+	oldAuto := *notchAuto
+	t.Cleanup(func() { *notchAuto = oldAuto })
+	*notchAuto = true
+
+	names := make([]string, 0, len(operations))
+	for name := range operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			want, err := operations[name].run(opInput{img: standalone})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := operations[name].run(opInput{img: sub})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("%d saídas, esperado %d", len(got), len(want))
+			}
+			for i := range want {
+				if got[i].name != want[i].name {
+					t.Errorf("saída %q, esperado %q", got[i].name, want[i].name)
+				}
+				if min := got[i].img.Bounds().Min; min != (image.Point{}) {
+					t.Errorf("%s começa em %v, esperado a origem", got[i].name, min)
+				}
+				if !sameImage(got[i].img, want[i].img) {
+					t.Errorf("%s difere do recorte processado sozinho", got[i].name)
+				}
+			}
+		})
+	}
+}
+
+func TestHelpersOnSubImage(t *testing.T) {
+	sub, standalone := offsetScene(t)
+	tests := []struct {
+		name string
+		run  func(*image.Gray) image.Image
+	}{
+		{"quantizeBands", func(img *image.Gray) image.Image { return quantizeBands(img, 4) }},
+		{"quadtree", func(img *image.Gray) image.Image { return quadtreeDecompose(img, 8, 4).reconstruct() }},
+	}
+	for _, tt := range tests {
+		if !sameImage(tt.run(sub), tt.run(standalone)) {
+			t.Errorf("%s: o SubImage difere do recorte processado sozinho", tt.name)
+		}
+	}
+
+	coherence := make([][]float64, sub.Bounds().Dy())
+	for y := range coherence {
+		coherence[y] = make([]float64, sub.Bounds().Dx())
+		for x := range coherence[y] {
+			coherence[y][x] = float64(x % 7)
+		}
+	}
+	if got, want := meanCoherence(coherence, sub), meanCoherence(coherence, standalone); got != want {
+		t.Errorf("meanCoherence: %g com o SubImage, %g no recorte", got, want)
+	}
+}
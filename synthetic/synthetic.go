@@ -0,0 +1,303 @@
+// Package synthetic gera imagens de teste determinísticas. As mesmas funções
+// são usadas pelo subcomando "gen" e pelos testes, então as imagens geradas
+// pelo usuário e as de teste saem do mesmo código.
+//
+// Convenção: objetos pretos (0) sobre fundo branco (255), a mesma usada por
+// countObjects.
+package synthetic
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const (
+	Background = 255
+	Foreground = 0
+)
+
+func newFilled(width, height int, value uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = value
+	}
+	return img
+}
+
+// Checkerboard gera um tabuleiro de casas com square pixels de lado,
+// começando com uma casa preta no canto superior esquerdo.
+func Checkerboard(width, height, square int) *image.Gray {
+	if square < 1 {
+		square = 1
+	}
+	img := newFilled(width, height, Background)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if (x/square+y/square)%2 == 0 {
+				img.Pix[y*img.Stride+x] = Foreground
+			}
+		}
+	}
+	return img
+}
+
+// Ramp gera um degradê de 0 a 255 na direção "horizontal", "vertical" ou
+// "diagonal".
+func Ramp(width, height int, direction string) (*image.Gray, error) {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	scale := func(v, n int) uint8 {
+		if n <= 1 {
+			return 0
+		}
+		return uint8(math.Round(float64(v) * 255 / float64(n-1)))
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var v uint8
+			switch direction {
+			case "horizontal":
+				v = scale(x, width)
+			case "vertical":
+				v = scale(y, height)
+			case "diagonal":
+				v = scale(x+y, width+height-1)
+			default:
+				return nil, fmt.Errorf("direção de degradê desconhecida: %q", direction)
+			}
+			img.Pix[y*img.Stride+x] = v
+		}
+	}
+	return img, nil
+}
+
+// Circle descreve um disco gerado.
+type Circle struct {
+	Center image.Point
+	Radius int
+}
+
+// CircleOptions controla a geração de discos.
+type CircleOptions struct {
+	Count  int
+	Radius int
+	// Overlap permite discos sobrepostos; sem ele os discos ficam separados
+	// por pelo menos 2 pixels para que continuem distintos em 8-conectividade.
+	Overlap bool
+	Seed    int64
+}
+
+// Circles espalha discos pretos de raio fixo sem sair da imagem. Devolve também
+// a lista de discos desenhados (pode ter menos que Count se não couberem).
+func Circles(width, height int, opts CircleOptions) (*image.Gray, []Circle) {
+	img := newFilled(width, height, Background)
+	rng := rand.New(rand.NewSource(opts.Seed))
+	r := opts.Radius
+
+	var circles []Circle
+	for attempt := 0; len(circles) < opts.Count && attempt < opts.Count*1000; attempt++ {
+		if width <= 2*r || height <= 2*r {
+			break
+		}
+		c := image.Pt(r+rng.Intn(width-2*r), r+rng.Intn(height-2*r))
+		if !opts.Overlap && collides(c, r, circles) {
+			continue
+		}
+		circles = append(circles, Circle{c, r})
+		FillCircle(img, c, r, Foreground)
+	}
+	return img, circles
+}
+
+func collides(c image.Point, r int, circles []Circle) bool {
+	for _, o := range circles {
+		dx, dy := float64(c.X-o.Center.X), float64(c.Y-o.Center.Y)
+		if math.Hypot(dx, dy) < float64(r+o.Radius+2) {
+			return true
+		}
+	}
+	return false
+}
+
+// FillCircle pinta um disco (pixels com distância ao centro <= r).
+func FillCircle(img *image.Gray, c image.Point, r int, value uint8) {
+	b := img.Bounds()
+	for y := c.Y - r; y <= c.Y+r; y++ {
+		for x := c.X - r; x <= c.X+r; x++ {
+			dx, dy := x-c.X, y-c.Y
+			if dx*dx+dy*dy <= r*r && image.Pt(x, y).In(b) {
+				img.Pix[img.PixOffset(x, y)] = value
+			}
+		}
+	}
+}
+
+// Squares espalha quadrados pretos de lado size que não se tocam.
+func Squares(width, height, count, size int, seed int64) (*image.Gray, []image.Rectangle) {
+	img := newFilled(width, height, Background)
+	rng := rand.New(rand.NewSource(seed))
+
+	var squares []image.Rectangle
+	for attempt := 0; len(squares) < count && attempt < count*1000; attempt++ {
+		if width <= size || height <= size {
+			break
+		}
+		x, y := rng.Intn(width-size), rng.Intn(height-size)
+		sq := image.Rect(x, y, x+size, y+size)
+		margin := sq.Inset(-2)
+		free := true
+		for _, o := range squares {
+			if margin.Overlaps(o) {
+				free = false
+				break
+			}
+		}
+		if !free {
+			continue
+		}
+		squares = append(squares, sq)
+		for py := sq.Min.Y; py < sq.Max.Y; py++ {
+			for px := sq.Min.X; px < sq.Max.X; px++ {
+				img.Pix[py*img.Stride+px] = Foreground
+			}
+		}
+	}
+	return img, squares
+}
+
+// Noise gera ruído gaussiano de média mean e desvio sigma, saturado em 0..255.
+func Noise(width, height int, mean, sigma float64, seed int64) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	rng := rand.New(rand.NewSource(seed))
+	for i := range img.Pix {
+		v := mean + rng.NormFloat64()*sigma
+		img.Pix[i] = uint8(math.Max(0, math.Min(255, math.Round(v))))
+	}
+	return img
+}
+
+// AddGaussianNoise devolve uma cópia de img com ruído gaussiano de média 0 e
+// desvio sigma somado a cada pixel, saturado em 0..255 (perto dos extremos o
+// desvio medido fica menor que sigma).
+func AddGaussianNoise(img *image.Gray, sigma float64, seed int64) (*image.Gray, error) {
+	if !(sigma >= 0) {
+		return nil, fmt.Errorf("desvio do ruído deve ser >= 0, não %g", sigma)
+	}
+	rng := rand.New(rand.NewSource(seed))
+	return mapPixels(img, func(v uint8) uint8 {
+		return uint8(math.Max(0, math.Min(255, math.Round(float64(v)+rng.NormFloat64()*sigma))))
+	}), nil
+}
+
+// AddSaltPepperNoise devolve uma cópia de img em que cada pixel, com
+// probabilidade density, vira sal (255) ou pimenta (0), meio a meio.
+func AddSaltPepperNoise(img *image.Gray, density float64, seed int64) (*image.Gray, error) {
+	if !(density >= 0 && density <= 1) {
+		return nil, fmt.Errorf("densidade do ruído sal e pimenta deve estar entre 0 e 1, não %g", density)
+	}
+	rng := rand.New(rand.NewSource(seed))
+	return mapPixels(img, func(v uint8) uint8 {
+		if rng.Float64() >= density {
+			return v
+		}
+		if rng.Intn(2) == 0 {
+			return Foreground
+		}
+		return Background
+	}), nil
+}
+
+// AddSpeckleNoise devolve uma cópia de img com ruído multiplicativo
+// (speckle, como no ultrassom): v·(1 + n), n gaussiano de média 0 e desvio
+// sigma, saturado em 0..255. A média se mantém e o desvio cresce com a
+// intensidade, σ·v (variância σ²v²): o preto fica preto.
+func AddSpeckleNoise(img *image.Gray, sigma float64, seed int64) (*image.Gray, error) {
+	if !(sigma >= 0) {
+		return nil, fmt.Errorf("desvio do ruído deve ser >= 0, não %g", sigma)
+	}
+	rng := rand.New(rand.NewSource(seed))
+	return mapPixels(img, func(v uint8) uint8 {
+		return uint8(math.Max(0, math.Min(255, math.Round(float64(v)*(1+rng.NormFloat64()*sigma)))))
+	}), nil
+}
+
+// AddPoissonNoise devolve uma cópia de img em que cada pixel é sorteado de
+// uma Poisson com λ igual ao valor dele (ruído de contagem de fótons): a
+// média se mantém e a variância é a própria intensidade (desvio √v), então
+// a relação sinal-ruído melhora nos tons claros. Acima de 255 satura. O
+// sorteio é exato, pela inversa da distribuição acumulada de cada nível.
+func AddPoissonNoise(img *image.Gray, seed int64) *image.Gray {
+	rng := rand.New(rand.NewSource(seed))
+	var cdfs [256][]float64
+	return mapPixels(img, func(v uint8) uint8 {
+		if cdfs[v] == nil {
+			cdfs[v] = poissonCDF(float64(v))
+		}
+		cdf := cdfs[v]
+		k := sort.SearchFloat64s(cdf, rng.Float64())
+		return uint8(min(k, 255))
+	})
+}
+
+// poissonCDF devolve P(X <= k) da Poisson de média lambda para k até bem
+// depois da cauda (λ + 12√λ + 20); o último valor é 1 para a busca nunca
+// passar do fim.
+func poissonCDF(lambda float64) []float64 {
+	if lambda == 0 {
+		return []float64{1}
+	}
+	n := int(lambda+12*math.Sqrt(lambda)) + 20
+	cdf := make([]float64, n)
+	// P(X = k) pelo logaritmo, para e^−λ não dar underflow
+	var sum float64
+	for k := range cdf {
+		lgamma, _ := math.Lgamma(float64(k + 1))
+		sum += math.Exp(float64(k)*math.Log(lambda) - lambda - lgamma)
+		cdf[k] = sum
+	}
+	cdf[n-1] = 1
+	return cdf
+}
+
+// mapPixels copia img para uma imagem com origem em zero trocando cada
+// pixel por fn(pixel), em ordem de leitura (para a semente dar sempre o
+// mesmo ruído).
+func mapPixels(img *image.Gray, fn func(uint8) uint8) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		dst := result.Pix[y*result.Stride : y*result.Stride+width]
+		for x, v := range img.Pix[y*img.Stride : y*img.Stride+width] {
+			dst[x] = fn(v)
+		}
+	}
+	return result
+}
+
+// SiemensStar gera a estrela de Siemens com spokes raios pretos, útil para
+// ver perda de resolução em direção ao centro.
+func SiemensStar(width, height, spokes int) *image.Gray {
+	img := newFilled(width, height, Background)
+	if spokes < 1 {
+		return img
+	}
+	cx, cy := float64(width-1)/2, float64(height-1)/2
+	radius := math.Min(cx, cy)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dx, dy := float64(x)-cx, float64(y)-cy
+			if math.Hypot(dx, dy) > radius {
+				continue
+			}
+			angle := math.Atan2(dy, dx) + math.Pi
+			sector := int(angle / (math.Pi / float64(spokes)))
+			if sector%2 == 0 {
+				img.Pix[y*img.Stride+x] = Foreground
+			}
+		}
+	}
+	return img
+}
@@ -0,0 +1,449 @@
+package synthetic_test
+
+import (
+	"bytes"
+	"image"
+	"math"
+	"testing"
+
+	"processing-images/imaging"
+	"processing-images/synthetic"
+)
+
+func TestSeededGeneratorsAreDeterministic(t *testing.T) {
+	tests := []struct {
+		name string
+		gen  func(seed int64) *image.Gray
+	}{
+		{"circles", func(seed int64) *image.Gray {
+			img, _ := synthetic.Circles(200, 150, synthetic.CircleOptions{Count: 8, Radius: 10, Seed: seed})
+			return img
+		}},
+		{"circles com sobreposição", func(seed int64) *image.Gray {
+			img, _ := synthetic.Circles(200, 150, synthetic.CircleOptions{Count: 8, Radius: 10, Overlap: true, Seed: seed})
+			return img
+		}},
+		{"squares", func(seed int64) *image.Gray {
+			img, _ := synthetic.Squares(200, 150, 8, 12, seed)
+			return img
+		}},
+		{"noise", func(seed int64) *image.Gray { return synthetic.Noise(64, 64, 128, 30, seed) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b, c := tt.gen(7), tt.gen(7), tt.gen(8)
+			if !bytes.Equal(a.Pix, b.Pix) {
+				t.Error("a mesma semente gerou imagens diferentes")
+			}
+			if bytes.Equal(a.Pix, c.Pix) {
+				t.Error("sementes diferentes geraram a mesma imagem")
+			}
+		})
+	}
+}
+
+// Os discos e quadrados gerados devem ser recuperados pela rotulação, com o
+// número, as áreas e as caixas certos.
+func TestCirclesRecoveredByLabeling(t *testing.T) {
+	tests := []struct {
+		count, radius int
+	}{
+		{5, 6},
+		{10, 12},
+		{3, 25},
+	}
+	for _, tt := range tests {
+		img, circles := synthetic.Circles(320, 240, synthetic.CircleOptions{Count: tt.count, Radius: tt.radius, Seed: 3})
+		if len(circles) != tt.count {
+			t.Fatalf("%d círculos de raio %d, esperado %d", len(circles), tt.radius, tt.count)
+		}
+		regions := labelRegions(t, img)
+		if len(regions) != tt.count {
+			t.Fatalf("a rotulação achou %d objetos, esperado %d", len(regions), tt.count)
+		}
+		for _, r := range regions {
+			if radius := r.EquivalentDiameter / 2; math.Abs(radius-float64(tt.radius)) > 0.5 {
+				t.Errorf("raio equivalente %.2f, esperado %d", radius, tt.radius)
+			}
+			if r.Bounds.Dx() != 2*tt.radius+1 || r.Bounds.Dy() != 2*tt.radius+1 {
+				t.Errorf("caixa %v, esperado lado %d", r.Bounds, 2*tt.radius+1)
+			}
+			center := image.Pt(int(math.Round(r.Centroid[0])), int(math.Round(r.Centroid[1])))
+			found := false
+			for _, c := range circles {
+				found = found || c.Center == center
+			}
+			if !found {
+				t.Errorf("centróide %v não é o centro de nenhum círculo", center)
+			}
+		}
+	}
+}
+
+func TestSquaresRecoveredByLabeling(t *testing.T) {
+	img, squares := synthetic.Squares(256, 256, 12, 10, 5)
+	if len(squares) != 12 {
+		t.Fatalf("%d quadrados, esperado 12", len(squares))
+	}
+	regions := labelRegions(t, img)
+	if len(regions) != len(squares) {
+		t.Fatalf("a rotulação achou %d objetos, esperado %d", len(regions), len(squares))
+	}
+	want := map[image.Rectangle]bool{}
+	for _, sq := range squares {
+		want[sq] = true
+	}
+	for _, r := range regions {
+		if !want[r.Bounds] || r.Area != 100 {
+			t.Errorf("objeto %v com área %d não é um dos quadrados gerados", r.Bounds, r.Area)
+		}
+	}
+}
+
+func labelRegions(t *testing.T, img *image.Gray) []imaging.Region {
+	t.Helper()
+	labels, _, err := imaging.LabelComponents(img, 8, imaging.BlackObjects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return imaging.RegionProps(labels)
+}
+
+func TestCheckerboard(t *testing.T) {
+	img := synthetic.Checkerboard(64, 48, 16)
+	tests := []struct {
+		x, y int
+		want uint8
+	}{
+		{0, 0, synthetic.Foreground},
+		{15, 15, synthetic.Foreground},
+		{16, 0, synthetic.Background},
+		{0, 16, synthetic.Background},
+		{16, 16, synthetic.Foreground},
+		{63, 47, synthetic.Background},
+	}
+	for _, tt := range tests {
+		if v := img.GrayAt(tt.x, tt.y).Y; v != tt.want {
+			t.Errorf("casa em %d,%d = %d, esperado %d", tt.x, tt.y, v, tt.want)
+		}
+	}
+	black := 0
+	for _, v := range img.Pix {
+		if v == synthetic.Foreground {
+			black++
+		}
+	}
+	if black != 64*48/2 {
+		t.Errorf("%d pixels pretos, esperado metade (%d)", black, 64*48/2)
+	}
+}
+
+func TestRamp(t *testing.T) {
+	tests := []struct {
+		direction   string
+		first, last image.Point // onde ficam o 0 e o 255
+		step        image.Point // direção em que o valor não pode diminuir
+	}{
+		{"horizontal", image.Pt(0, 10), image.Pt(99, 10), image.Pt(1, 0)},
+		{"vertical", image.Pt(10, 0), image.Pt(10, 49), image.Pt(0, 1)},
+		{"diagonal", image.Pt(0, 0), image.Pt(99, 49), image.Pt(1, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.direction, func(t *testing.T) {
+			img, err := synthetic.Ramp(100, 50, tt.direction)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v := img.GrayAt(tt.first.X, tt.first.Y).Y; v != 0 {
+				t.Errorf("início = %d, esperado 0", v)
+			}
+			if v := img.GrayAt(tt.last.X, tt.last.Y).Y; v != 255 {
+				t.Errorf("fim = %d, esperado 255", v)
+			}
+			for y := 0; y+tt.step.Y < 50; y++ {
+				for x := 0; x+tt.step.X < 100; x++ {
+					if img.GrayAt(x, y).Y > img.GrayAt(x+tt.step.X, y+tt.step.Y).Y {
+						t.Fatalf("o degradê diminui em %d,%d", x, y)
+					}
+				}
+			}
+		})
+	}
+	if _, err := synthetic.Ramp(10, 10, "radial"); err == nil {
+		t.Error("esperava erro para direção desconhecida")
+	}
+}
+
+func TestNoiseStatistics(t *testing.T) {
+	img := synthetic.Noise(256, 256, 100, 20, 1)
+	var sum, sumSq float64
+	for _, v := range img.Pix {
+		sum += float64(v)
+		sumSq += float64(v) * float64(v)
+	}
+	n := float64(len(img.Pix))
+	mean := sum / n
+	sigma := math.Sqrt(sumSq/n - mean*mean)
+	if math.Abs(mean-100) > 0.5 || math.Abs(sigma-20) > 0.5 {
+		t.Errorf("média %.2f e desvio %.2f, esperado 100 e 20", mean, sigma)
+	}
+}
+
+func TestSiemensStar(t *testing.T) {
+	const size, spokes = 201, 18
+	img := synthetic.SiemensStar(size, size, spokes)
+	// metade do disco é preta e fora dele é tudo fundo
+	black, inside := 0, 0
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x-100), float64(y-100)
+			v := img.GrayAt(x, y).Y
+			if math.Hypot(dx, dy) > 100 {
+				if v != synthetic.Background {
+					t.Fatalf("pixel %d,%d fora do disco não é fundo", x, y)
+				}
+				continue
+			}
+			inside++
+			if v == synthetic.Foreground {
+				black++
+			}
+		}
+	}
+	if frac := float64(black) / float64(inside); math.Abs(frac-0.5) > 0.02 {
+		t.Errorf("fração preta no disco %.3f, esperado 0,5", frac)
+	}
+	// num círculo de raio 80 a cor troca 2·spokes vezes
+	changes := 0
+	prev := img.GrayAt(180, 100).Y
+	for i := 1; i <= 720; i++ {
+		a := float64(i) * math.Pi / 360
+		v := img.GrayAt(100+int(math.Round(80*math.Cos(a))), 100+int(math.Round(80*math.Sin(a)))).Y
+		if v != prev {
+			changes++
+			prev = v
+		}
+	}
+	if changes != 2*spokes {
+		t.Errorf("%d trocas de cor no círculo, esperado %d", changes, 2*spokes)
+	}
+}
+
+// sampleStats devolve a média e o desvio amostral dos pixels.
+func sampleStats(img *image.Gray) (mean, sigma float64) {
+	var sum, sumSq float64
+	for _, v := range img.Pix {
+		sum += float64(v)
+		sumSq += float64(v) * float64(v)
+	}
+	n := float64(len(img.Pix))
+	mean = sum / n
+	return mean, math.Sqrt(sumSq/n - mean*mean)
+}
+
+// uniform é uma imagem size×size toda com o valor v.
+func uniform(size int, v uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for i := range img.Pix {
+		img.Pix[i] = v
+	}
+	return img
+}
+
+func TestAddGaussianNoiseStatistics(t *testing.T) {
+	tests := []struct {
+		sigma float64
+	}{{0}, {5}, {20}, {40}}
+	for _, tt := range tests {
+		noisy, err := synthetic.AddGaussianNoise(uniform(256, 128), tt.sigma, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mean, sigma := sampleStats(noisy)
+		if math.Abs(mean-128) > 0.5 || math.Abs(sigma-tt.sigma) > 0.03*tt.sigma+0.3 {
+			t.Errorf("σ=%g: média %.2f e desvio %.2f, esperado 128 e %g", tt.sigma, mean, sigma, tt.sigma)
+		}
+	}
+}
+
+func TestAddGaussianNoiseClamps(t *testing.T) {
+	// perto dos extremos o ruído satura em vez de dar a volta em 8 bits
+	tests := []struct {
+		name       string
+		value      uint8
+		sigma      float64
+		edge       uint8   // valor de saturação
+		saturated  float64 // fração esperada em edge
+		minAllowed uint8
+		maxAllowed uint8
+	}{
+		// P(v + n >= 254.5) com v = 250: P(Z >= 4.5/30) ≈ 0.44
+		{"claro", 250, 30, 255, 0.44, 100, 255},
+		// P(n <= 0.5) ≈ 0.51 no preto
+		{"preto", 0, 20, 0, 0.51, 0, 120},
+	}
+	for _, tt := range tests {
+		noisy, err := synthetic.AddGaussianNoise(uniform(256, tt.value), tt.sigma, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		count := 0
+		for _, v := range noisy.Pix {
+			if v == tt.edge {
+				count++
+			}
+			if v < tt.minAllowed || v > tt.maxAllowed {
+				t.Fatalf("%s: valor %d fora de %d..%d, deu a volta", tt.name, v, tt.minAllowed, tt.maxAllowed)
+			}
+		}
+		if frac := float64(count) / float64(len(noisy.Pix)); math.Abs(frac-tt.saturated) > 0.02 {
+			t.Errorf("%s: fração saturada %.3f, esperado %.2f", tt.name, frac, tt.saturated)
+		}
+	}
+}
+
+func TestAddSaltPepperNoiseStatistics(t *testing.T) {
+	for _, density := range []float64{0, 0.05, 0.3, 1} {
+		noisy, err := synthetic.AddSaltPepperNoise(uniform(256, 128), density, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		salt, pepper := 0, 0
+		for _, v := range noisy.Pix {
+			switch v {
+			case 255:
+				salt++
+			case 0:
+				pepper++
+			case 128:
+			default:
+				t.Fatalf("densidade %g: valor %d não é sal, pimenta nem o original", density, v)
+			}
+		}
+		n := float64(len(noisy.Pix))
+		if frac := float64(salt+pepper) / n; math.Abs(frac-density) > 0.01 {
+			t.Errorf("densidade %g: fração trocada %.3f", density, frac)
+		}
+		if density > 0 && math.Abs(float64(salt-pepper))/float64(salt+pepper) > 0.05 {
+			t.Errorf("densidade %g: %d de sal e %d de pimenta, esperado meio a meio", density, salt, pepper)
+		}
+	}
+}
+
+func TestAddNoiseDeterministicCopies(t *testing.T) {
+	src := synthetic.Checkerboard(64, 48, 8)
+	orig := bytes.Clone(src.Pix)
+	tests := []struct {
+		name string
+		add  func(seed int64) *image.Gray
+	}{
+		{"gaussiano", func(seed int64) *image.Gray {
+			img, _ := synthetic.AddGaussianNoise(src, 15, seed)
+			return img
+		}},
+		{"sal e pimenta", func(seed int64) *image.Gray {
+			img, _ := synthetic.AddSaltPepperNoise(src, 0.2, seed)
+			return img
+		}},
+	}
+	for _, tt := range tests {
+		a, b, c := tt.add(1), tt.add(1), tt.add(2)
+		if !bytes.Equal(a.Pix, b.Pix) || bytes.Equal(a.Pix, c.Pix) {
+			t.Errorf("%s: a semente não determina o ruído", tt.name)
+		}
+		if !bytes.Equal(src.Pix, orig) {
+			t.Fatalf("%s: a entrada foi alterada", tt.name)
+		}
+	}
+
+	// o recorte sai com origem em zero e só com os pixels dele
+	sub := src.SubImage(image.Rect(8, 8, 24, 16)).(*image.Gray)
+	noisy, err := synthetic.AddSaltPepperNoise(sub, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if noisy.Bounds() != image.Rect(0, 0, 16, 8) || noisy.GrayAt(0, 0) != sub.GrayAt(8, 8) || noisy.GrayAt(15, 7) != sub.GrayAt(23, 15) {
+		t.Errorf("recorte com limites %v ou pixels errados", noisy.Bounds())
+	}
+}
+
+func TestAddNoiseInvalid(t *testing.T) {
+	img := uniform(4, 10)
+	for _, sigma := range []float64{-1, math.NaN()} {
+		if _, err := synthetic.AddGaussianNoise(img, sigma, 1); err == nil {
+			t.Errorf("σ=%g: sem erro", sigma)
+		}
+	}
+	for _, density := range []float64{-0.1, 1.5, math.NaN()} {
+		if _, err := synthetic.AddSaltPepperNoise(img, density, 1); err == nil {
+			t.Errorf("densidade %g: sem erro", density)
+		}
+	}
+}
+
+func TestAddSpeckleNoiseStatistics(t *testing.T) {
+	// o desvio é proporcional à intensidade: σ·v
+	const sigma = 0.2
+	for _, v := range []uint8{0, 20, 60, 100} {
+		noisy, err := synthetic.AddSpeckleNoise(uniform(256, v), sigma, 6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mean, std := sampleStats(noisy)
+		want := sigma * float64(v)
+		if math.Abs(mean-float64(v)) > 0.01*float64(v)+0.1 || math.Abs(std-want) > 0.04*want+0.3 {
+			t.Errorf("v=%d: média %.2f e desvio %.2f, esperado %d e %.1f", v, mean, std, v, want)
+		}
+	}
+}
+
+func TestAddPoissonNoiseStatistics(t *testing.T) {
+	// a média se mantém e a variância é a própria intensidade
+	previous := -1.0
+	for _, v := range []uint8{0, 5, 20, 80, 200} {
+		noisy := synthetic.AddPoissonNoise(uniform(256, v), 7)
+		mean, std := sampleStats(noisy)
+		variance := std * std
+		if math.Abs(mean-float64(v)) > 0.01*float64(v)+0.05 {
+			t.Errorf("λ=%d: média %.2f", v, mean)
+		}
+		if math.Abs(variance-float64(v)) > 0.05*float64(v)+0.05 {
+			t.Errorf("λ=%d: variância %.2f", v, variance)
+		}
+		if variance <= previous {
+			t.Errorf("λ=%d: variância %.2f não cresceu (antes %.2f)", v, variance, previous)
+		}
+		previous = variance
+	}
+}
+
+func TestMultiplicativeNoiseDeterministic(t *testing.T) {
+	src := synthetic.SiemensStar(64, 64, 8)
+	tests := []struct {
+		name string
+		add  func(seed int64) *image.Gray
+	}{
+		{"speckle", func(seed int64) *image.Gray {
+			img, _ := synthetic.AddSpeckleNoise(src, 0.3, seed)
+			return img
+		}},
+		{"poisson", func(seed int64) *image.Gray { return synthetic.AddPoissonNoise(src, seed) }},
+	}
+	for _, tt := range tests {
+		a, b, c := tt.add(1), tt.add(1), tt.add(2)
+		if !bytes.Equal(a.Pix, b.Pix) || bytes.Equal(a.Pix, c.Pix) {
+			t.Errorf("%s: a semente não determina o ruído", tt.name)
+		}
+		// os raios pretos continuam pretos
+		for i, v := range src.Pix {
+			if v == 0 && a.Pix[i] != 0 {
+				t.Fatalf("%s: pixel preto %d virou %d", tt.name, i, a.Pix[i])
+			}
+		}
+	}
+	for _, sigma := range []float64{-0.1, math.NaN()} {
+		if _, err := synthetic.AddSpeckleNoise(src, sigma, 1); err == nil {
+			t.Errorf("speckle σ=%g: sem erro", sigma)
+		}
+	}
+}
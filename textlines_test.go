@@ -0,0 +1,92 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+// wordsPage desenha 5 linhas de 3 "palavras"; cada palavra são 5 letras
+// retangulares de 8x14 separadas por 3 pixels, e as palavras são separadas
+// por 27.
+func wordsPage() *image.Gray {
+	page := uniformGray(320, 255)
+	for line := 0; line < 5; line++ {
+		y0 := 40 + line*50
+		for word := 0; word < 3; word++ {
+			x0 := 20 + word*(5*11+24)
+			for letter := 0; letter < 5; letter++ {
+				lx := x0 + letter*11
+				for y := y0; y < y0+14; y++ {
+					for x := lx; x < lx+8; x++ {
+						page.Pix[y*page.Stride+x] = 0
+					}
+				}
+			}
+		}
+	}
+	return page
+}
+
+func TestSegmentText(t *testing.T) {
+	for _, angle := range []float64{0, 1, -1.5, 2} {
+		page := wordsPage()
+		if angle != 0 {
+			page = thresholdAt(rotate(page, angle, interpBilinear, false, 255), 128)
+		}
+		lines := segmentText(page, 0)
+		if len(lines) != 5 {
+			t.Errorf("%.1f°: %d linhas, esperado 5", angle, len(lines))
+			continue
+		}
+		for i, line := range lines {
+			if i > 0 && line.Box.Min.Y <= lines[i-1].Box.Min.Y {
+				t.Errorf("%.1f°: linha %d fora de ordem", angle, i)
+			}
+			if len(line.Words) != 3 {
+				t.Errorf("%.1f°: linha %d com %d palavras, esperado 3", angle, i, len(line.Words))
+				continue
+			}
+			for j, w := range line.Words {
+				if !w.In(line.Box) {
+					t.Errorf("%.1f°: palavra %v fora da linha %v", angle, w, line.Box)
+				}
+				if j > 0 && w.Min.X <= line.Words[j-1].Max.X {
+					t.Errorf("%.1f°: palavra %d da linha %d fora de ordem", angle, j, i)
+				}
+			}
+		}
+	}
+}
+
+func TestSegmentWordsGapThreshold(t *testing.T) {
+	page := wordsPage()
+	line := page.SubImage(image.Rect(0, 40, 320, 54)).(*image.Gray)
+	tests := []struct {
+		gap  int
+		want int
+	}{
+		{0, 3},  // automático
+		{3, 15}, // cada letra vira palavra
+		{27, 3}, // igual ao espaço entre palavras
+		{28, 1}, // nenhuma lacuna basta
+	}
+	for _, tt := range tests {
+		if words := segmentWords(line, tt.gap); len(words) != tt.want {
+			t.Errorf("gapThreshold %d: %d palavras, esperado %d", tt.gap, len(words), tt.want)
+		}
+	}
+}
+
+func TestRunsAbove(t *testing.T) {
+	profile := []float64{0, 3, 3, 0, 0, 5, 1, 4}
+	want := [][2]int{{1, 3}, {5, 6}, {7, 8}}
+	got := runsAbove(profile, 2)
+	if len(got) != len(want) {
+		t.Fatalf("intervalos %v, esperado %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("intervalo %d = %v, esperado %v", i, got[i], want[i])
+		}
+	}
+}
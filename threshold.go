@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"processing-images/imaging"
+)
+
+// sauvolaThreshold binariza pelo limiar local de Sauvola,
+// T = m·(1 + k·(s/R − 1)), com média m e desvio s na janela window×window
+// e R = 128. Segue a convenção do imaging.Otsu: acima do limiar 255, senão
+// 0 (texto escuro vira 0), então o resultado vai direto para
+// imaging.CountObjects.
+func sauvolaThreshold(img *image.Gray, window int, k float64) *image.Gray {
+	const r = 128
+	return localThreshold(img, window, func(mean, s float64) float64 {
+		return mean * (1 + k*(s/r-1))
+	})
+}
+
+// niblackThreshold binariza pelo limiar local de Niblack, T = m + k·s (k
+// costuma ser negativo, ex: −0.2, para o texto escuro). Mais sensível ao
+// ruído do fundo que o Sauvola; mesma convenção de saída.
+func niblackThreshold(img *image.Gray, window int, k float64) *image.Gray {
+	return localThreshold(img, window, func(mean, s float64) float64 {
+		return mean + k*s
+	})
+}
+
+// localThreshold calcula a média e o desvio de cada janela window×window
+// (recortada nas bordas) pelas tabelas de somas e dos quadrados, O(1) por
+// pixel, e marca 255 onde o pixel passa do limiar que threshold dá para
+// elas.
+func localThreshold(img *image.Gray, window int, threshold func(mean, s float64) float64) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	sum := integralImage(img)
+	sumSq := integralImageSquared(img)
+	half := window / 2
+
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			rect := image.Rect(x-half, y-half, x+half+1, y+half+1).Intersect(image.Rect(0, 0, width, height))
+			n := float64(rect.Dx() * rect.Dy())
+			mean := float64(windowSum(sum, rect)) / n
+			variance := float64(windowSum(sumSq, rect))/n - mean*mean
+			s := math.Sqrt(math.Max(variance, 0))
+
+			if float64(img.Pix[y*img.Stride+x]) > threshold(mean, s) {
+				result.Pix[y*result.Stride+x] = 255
+			}
+		}
+	}
+	return result
+}
+
+// adaptiveThreshold binariza comparando cada pixel com a média local da
+// janela window×window menos c: abaixo disso é primeiro plano (0), o resto
+// fica 255, como no Sauvola. method "mean" usa a média simples pelas tabelas
+// de somas (O(1) por pixel, qualquer janela); "gaussian" pondera a janela
+// com uma gaussiana separável de σ = 0.3·((window−1)/2 − 1) + 0.8, a mesma
+// relação do OpenCV. Nas bordas a janela é recortada à imagem.
+func adaptiveThreshold(img *image.Gray, window int, c float64, method string) (*image.Gray, error) {
+	if window < 1 {
+		return nil, fmt.Errorf("janela do limiar adaptativo deve ser positiva, não %d", window)
+	}
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+
+	var localMean func(x, y int) float64
+	switch method {
+	case "mean":
+		sum := integralImage(img)
+		half := window / 2
+		localMean = func(x, y int) float64 {
+			rect := image.Rect(x-half, y-half, x+half+1, y+half+1).Intersect(image.Rect(0, 0, width, height))
+			return float64(windowSum(sum, rect)) / float64(rect.Dx()*rect.Dy())
+		}
+	case "gaussian":
+		sigma := 0.3*(float64(window-1)/2-1) + 0.8
+		smooth := imaging.SmoothFloat(imaging.GrayToFloat(img), sigma)
+		localMean = func(x, y int) float64 {
+			return smooth[y][x]
+		}
+	default:
+		return nil, fmt.Errorf("método de limiar adaptativo desconhecido %q, use mean ou gaussian", method)
+	}
+
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if float64(img.Pix[y*img.Stride+x]) >= localMean(x, y)-c {
+				result.Pix[y*result.Stride+x] = 255
+			}
+		}
+	}
+	return result, nil
+}
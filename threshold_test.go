@@ -0,0 +1,209 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"processing-images/imaging"
+)
+
+// litPage gera uma página iluminada por um degradê forte, do papel 40 à
+// esquerda a 230 à direita, com texto escuro, e devolve a máscara do texto.
+func litPage() (page *image.Gray, text []bool) {
+	const width, height = 320, 160
+	strokes := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i, line := range []string{"SPHINX OF BLACK", "QUARTZ JUDGE MY VOW"} {
+		drawText(strokes, 10, 30+i*60, line, 3, color.RGBA{255, 255, 255, 255})
+	}
+	page = image.NewGray(image.Rect(0, 0, width, height))
+	text = make([]bool, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := 40 + 190*x/(width-1)
+			if strokes.Pix[strokes.PixOffset(x, y)] != 0 {
+				text[y*width+x] = true
+				v = v * 45 / 100
+			}
+			page.Pix[y*page.Stride+x] = uint8(v)
+		}
+	}
+	return page, text
+}
+
+// halfScores devolve, para a metade esquerda (escura) e a direita (clara),
+// a fração do texto que saiu 0 e a fração do papel que saiu 0.
+func halfScores(binary *image.Gray, text []bool) (recall, falsePos [2]float64) {
+	width := binary.Bounds().Dx()
+	var strokes, kept, paper, wrong [2]int
+	for i, isText := range text {
+		half := 0
+		if i%width >= width/2 {
+			half = 1
+		}
+		black := binary.Pix[(i/width)*binary.Stride+i%width] == 0
+		if isText {
+			strokes[half]++
+			if black {
+				kept[half]++
+			}
+		} else {
+			paper[half]++
+			if black {
+				wrong[half]++
+			}
+		}
+	}
+	for h := 0; h < 2; h++ {
+		recall[h] = float64(kept[h]) / float64(strokes[h])
+		falsePos[h] = float64(wrong[h]) / float64(paper[h])
+	}
+	return recall, falsePos
+}
+
+func TestAdaptiveThresholdLitPage(t *testing.T) {
+	page, text := litPage()
+	for _, method := range []string{"mean", "gaussian"} {
+		binary, err := adaptiveThreshold(page, 31, 10, method)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recall, falsePos := halfScores(binary, text)
+		for h, name := range []string{"escura", "clara"} {
+			if recall[h] < 0.95 {
+				t.Errorf("%s, metade %s: recall do texto %.3f, esperado >= 0.95", method, name, recall[h])
+			}
+			if falsePos[h] > 0.01 {
+				t.Errorf("%s, metade %s: %.3f do papel virou texto", method, name, falsePos[h])
+			}
+		}
+	}
+
+	// o Otsu global apaga a metade escura
+	_, falsePos := halfScores(imaging.Otsu(page), text)
+	if falsePos[0] < 0.5 {
+		t.Errorf("Otsu marcou só %.3f da metade escura: o teste não distingue", falsePos[0])
+	}
+}
+
+func TestAdaptiveThresholdCases(t *testing.T) {
+	tests := []struct {
+		name   string
+		img    *image.Gray
+		window int
+		c      float64
+		method string
+		want   uint8
+		ok     bool
+	}{
+		{"constante fica papel", uniformGray(20, 90), 5, 0, "mean", 255, true},
+		{"c negativo vira texto", uniformGray(20, 90), 5, -1, "mean", 0, true},
+		{"gaussiana constante", uniformGray(20, 90), 7, 0, "gaussian", 255, true},
+		{"janela 1", uniformGray(8, 90), 1, 0, "mean", 255, true},
+		{"janela zero", uniformGray(8, 90), 0, 0, "mean", 0, false},
+		{"método desconhecido", uniformGray(8, 90), 5, 0, "median", 0, false},
+	}
+	for _, tt := range tests {
+		binary, err := adaptiveThreshold(tt.img, tt.window, tt.c, tt.method)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: erro %v", tt.name, err)
+			continue
+		}
+		if !tt.ok {
+			continue
+		}
+		for i, v := range binary.Pix {
+			if v != tt.want {
+				t.Errorf("%s: pixel %d = %d, esperado %d", tt.name, i, v, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestSauvolaLitPage(t *testing.T) {
+	page, text := litPage()
+	tests := []struct {
+		name     string
+		binary   *image.Gray
+		recall   float64
+		falsePos float64
+	}{
+		{"sauvola", sauvolaThreshold(page, 31, 0.2), 0.95, 0.01},
+		{"niblack", niblackThreshold(page, 31, -0.2), 0.95, 0.05},
+	}
+	for _, tt := range tests {
+		recall, falsePos := halfScores(tt.binary, text)
+		for h, name := range []string{"escura", "clara"} {
+			if recall[h] < tt.recall {
+				t.Errorf("%s, metade %s: recall do texto %.3f, esperado >= %g", tt.name, name, recall[h], tt.recall)
+			}
+			if falsePos[h] > tt.falsePos {
+				t.Errorf("%s, metade %s: %.3f do papel virou texto, esperado <= %g", tt.name, name, falsePos[h], tt.falsePos)
+			}
+		}
+	}
+
+	// o Otsu global perde os traços da metade escura no papel escuro
+	if _, otsuFalse := halfScores(imaging.Otsu(page), text); otsuFalse[0] < 0.5 {
+		t.Errorf("Otsu separou o texto da metade escura (%.3f do papel como texto): o teste não distingue", otsuFalse[0])
+	}
+}
+
+func TestSauvolaFeedsCountObjects(t *testing.T) {
+	// três blocos escuros num fundo em degradê: a saída do Sauvola já está
+	// na convenção de imaging.CountObjects (objetos 0, fundo 255)
+	page := image.NewGray(image.Rect(0, 0, 120, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 120; x++ {
+			v := 60 + x*3/2
+			if y >= 12 && y < 28 && (x%40) >= 12 && (x%40) < 28 {
+				v /= 3
+			}
+			page.Pix[y*page.Stride+x] = uint8(v)
+		}
+	}
+	n, err := imaging.CountObjects(sauvolaThreshold(page, 31, 0.2), imaging.CountObjectsOptions{MinArea: 10, Size: 1, Connectivity: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("%d objetos, esperado 3", n)
+	}
+}
+
+func TestLocalThresholdStats(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 9, 7))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 37 % 251)
+	}
+	for _, window := range []int{1, 3, 5, 15} {
+		var means, stds []float64
+		localThreshold(img, window, func(mean, s float64) float64 {
+			means = append(means, mean)
+			stds = append(stds, s)
+			return 0
+		})
+		half := window / 2
+		for y := 0; y < 7; y++ {
+			for x := 0; x < 9; x++ {
+				var sum, sumSq, n float64
+				for yy := max(y-half, 0); yy <= min(y+half, 6); yy++ {
+					for xx := max(x-half, 0); xx <= min(x+half, 8); xx++ {
+						v := float64(img.Pix[yy*img.Stride+xx])
+						sum += v
+						sumSq += v * v
+						n++
+					}
+				}
+				mean := sum / n
+				std := math.Sqrt(math.Max(sumSq/n-mean*mean, 0))
+				i := y*9 + x
+				if math.Abs(means[i]-mean) > 1e-9 || math.Abs(stds[i]-std) > 1e-6 {
+					t.Fatalf("janela %d em %d,%d: média %g e desvio %g, esperado %g e %g", window, x, y, means[i], stds[i], mean, std)
+				}
+			}
+		}
+	}
+}
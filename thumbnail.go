@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// thumbnail reduz a imagem para caber em maxW x maxH mantendo a proporção.
+// Nunca amplia: imagens que já cabem voltam sem alteração. A redução é por
+// média de área, que não serrilha nem em fatores grandes.
+func thumbnail(img image.Image, maxW, maxH int) image.Image {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	scale := math.Min(float64(maxW)/float64(w), float64(maxH)/float64(h))
+	if w == 0 || h == 0 || scale >= 1 {
+		return img
+	}
+	tw := max(1, int(math.Round(float64(w)*scale)))
+	th := max(1, int(math.Round(float64(h)*scale)))
+
+	if gray, ok := img.(*image.Gray); ok {
+		return resizeArea(gray, tw, th)
+	}
+	channels := splitChannels(img)
+	for c := range channels {
+		channels[c] = resizeArea(channels[c], tw, th)
+	}
+	return mergeChannels(channels)
+}
+
+// gotoshop thumbnail -size 256 [-out-dir thumbs/] arquivo...
+func runThumbnail(args []string) int {
+	fs := newFlagSet("thumbnail", "[-size 256] [-out-dir thumbs] arquivo...")
+	size := fs.Int("size", 256, "lado da caixa em que a miniatura deve caber")
+	outDir := fs.String("out-dir", "thumbs", "diretório das miniaturas")
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) == 0 || *size <= 0 {
+		fs.Usage()
+		return 2
+	}
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	status := 0
+	for _, file := range files {
+		img, meta, err := decodeImage(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+			continue
+		}
+		thumb := thumbnail(img, *size, *size)
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)) + ".png"
+		out := filepath.Join(*outDir, name)
+		if err := saveImageMeta(out, thumb, meta); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+			continue
+		}
+		fmt.Printf("%s -> %s (%dx%d)\n", file, out, thumb.Bounds().Dx(), thumb.Bounds().Dy())
+	}
+	return status
+}
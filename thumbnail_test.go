@@ -0,0 +1,97 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+func TestThumbnailSize(t *testing.T) {
+	tests := []struct {
+		name         string
+		img          image.Image
+		maxW, maxH   int
+		wantW, wantH int
+	}{
+		{"paisagem", image.NewGray(image.Rect(0, 0, 1000, 500)), 256, 256, 256, 128},
+		{"retrato", image.NewGray(image.Rect(0, 0, 300, 900)), 256, 256, 85, 256},
+		{"quadrada", image.NewGray(image.Rect(0, 0, 512, 512)), 256, 256, 256, 256},
+		{"caixa retangular", image.NewGray(image.Rect(0, 0, 400, 400)), 100, 50, 50, 50},
+		{"colorida", image.NewRGBA(image.Rect(0, 0, 640, 480)), 320, 320, 320, 240},
+		{"faixa fina", image.NewGray(image.Rect(0, 0, 2000, 3)), 100, 100, 100, 1},
+	}
+	for _, tt := range tests {
+		got := thumbnail(tt.img, tt.maxW, tt.maxH).Bounds()
+		if got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+			t.Errorf("%s: %dx%d, esperado %dx%d", tt.name, got.Dx(), got.Dy(), tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestThumbnailNoUpscale(t *testing.T) {
+	img := synthetic.Checkerboard(100, 60, 10)
+	if got := thumbnail(img, 256, 256); got != image.Image(img) {
+		t.Error("imagem menor que a caixa foi alterada")
+	}
+}
+
+func TestThumbnailNoAliasing(t *testing.T) {
+	// xadrez de 1 pixel reduzido 8x: a média de área dá cinza uniforme
+	img := synthetic.Checkerboard(512, 512, 1)
+	thumb := thumbnail(img, 64, 64).(*image.Gray)
+	for _, v := range thumb.Pix {
+		if v < 120 || v > 135 {
+			t.Fatalf("pixel %d na miniatura do xadrez, esperado cinza médio", v)
+		}
+	}
+}
+
+func TestThumbnailColor(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = 200, 100, 50, 255
+	}
+	thumb, ok := thumbnail(img, 16, 16).(*image.RGBA)
+	if !ok {
+		t.Fatal("miniatura de imagem colorida não é RGBA")
+	}
+	if c := thumb.RGBAAt(5, 5); c != (color.RGBA{200, 100, 50, 255}) {
+		t.Errorf("cor %v, esperado {200 100 50 255}", c)
+	}
+}
+
+func TestRunThumbnail(t *testing.T) {
+	dir := t.TempDir()
+	for name, size := range map[string]int{"a.png": 800, "b.png": 100} {
+		if err := saveImage(filepath.Join(dir, name), synthetic.Checkerboard(size, size/2, 8)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outDir := filepath.Join(dir, "thumbs")
+	code := runThumbnail([]string{"-size", "200", "-out-dir", outDir, filepath.Join(dir, "a.png"), filepath.Join(dir, "b.png")})
+	if code != 0 {
+		t.Fatalf("código de saída %d", code)
+	}
+	tests := []struct {
+		name         string
+		wantW, wantH int
+	}{
+		{"a.png", 200, 100},
+		{"b.png", 100, 50},
+	}
+	for _, tt := range tests {
+		img, err := loadImage(filepath.Join(outDir, tt.name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := img.Bounds(); b.Dx() != tt.wantW || b.Dy() != tt.wantH {
+			t.Errorf("%s: %dx%d, esperado %dx%d", tt.name, b.Dx(), b.Dy(), tt.wantW, tt.wantH)
+		}
+	}
+	if code := runThumbnail([]string{"-out-dir", outDir, filepath.Join(dir, "nada.png")}); code != 1 {
+		t.Errorf("arquivo que não existe: código %d, esperado 1", code)
+	}
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// TIFF de base, como o dos scanners: tons de cinza ou RGB com 8 bits por
+// amostra, em faixas (strips), sem compressão ou com LZW (com ou sem o
+// preditor horizontal). Ladrilhos, paletas e planos separados não são
+// suportados.
+
+func init() {
+	image.RegisterFormat("tiff", "II*\x00", decodeTIFF, decodeTIFFConfig)
+	image.RegisterFormat("tiff", "MM\x00*", decodeTIFF, decodeTIFFConfig)
+}
+
+var errTIFFUnsupported = errors.New("TIFF não suportado: só tons de cinza ou RGB com 8 bits, em faixas, sem compressão ou LZW")
+
+// tags de TIFF usadas
+const (
+	tiffImageWidth      = 256
+	tiffImageLength     = 257
+	tiffBitsPerSample   = 258
+	tiffCompression     = 259
+	tiffPhotometric     = 262
+	tiffStripOffsets    = 273
+	tiffSamplesPerPixel = 277
+	tiffRowsPerStrip    = 278
+	tiffStripByteCounts = 279
+	tiffPlanarConfig    = 284
+	tiffPredictor       = 317
+	tiffTileWidth       = 322
+)
+
+// tiffImage é o que interessa da primeira imagem (IFD) do arquivo.
+type tiffImage struct {
+	width, height  int
+	samples        int // amostras por pixel: 1 ou 2 no cinza, 3 ou 4 no RGB (o extra é ignorado)
+	photometric    int // 0 cinza invertido (WhiteIsZero), 1 cinza, 2 RGB
+	lzw, predictor bool
+	rowsPerStrip   int
+	offsets        []int
+	counts         []int
+}
+
+// readTIFFIFD lê e valida a primeira IFD.
+func readTIFFIFD(data []byte) (tiffImage, error) {
+	var t tiffImage
+	if len(data) < 8 {
+		return t, errors.New("cabeçalho TIFF incompleto")
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if data[0] == 'M' {
+		order = binary.BigEndian
+	}
+	ifd := int(order.Uint32(data[4:]))
+	if ifd+2 > len(data) || ifd < 8 {
+		return t, errors.New("IFD do TIFF fora do arquivo")
+	}
+	n := int(order.Uint16(data[ifd:]))
+	if ifd+2+12*n > len(data) {
+		return t, errors.New("IFD do TIFF truncada")
+	}
+
+	tags := map[int][]int{}
+	for i := 0; i < n; i++ {
+		entry := data[ifd+2+12*i:]
+		tag, typ, count := int(order.Uint16(entry)), order.Uint16(entry[2:]), int(order.Uint32(entry[4:]))
+		var size int
+		switch typ {
+		case 3: // SHORT
+			size = 2
+		case 4: // LONG
+			size = 4
+		default:
+			continue // nenhuma das tags usadas tem outro tipo
+		}
+		// valores de até 4 bytes ficam na própria entrada
+		values := entry[8:12]
+		if count*size > 4 {
+			offset := int(order.Uint32(entry[8:]))
+			if count < 0 || offset < 0 || offset+count*size > len(data) {
+				return t, fmt.Errorf("tag %d do TIFF aponta para fora do arquivo", tag)
+			}
+			values = data[offset : offset+count*size]
+		}
+		list := make([]int, count)
+		for j := range list {
+			if size == 2 {
+				list[j] = int(order.Uint16(values[2*j:]))
+			} else {
+				list[j] = int(order.Uint32(values[4*j:]))
+			}
+		}
+		tags[tag] = list
+	}
+	first := func(tag, fallback int) int {
+		if v := tags[tag]; len(v) > 0 {
+			return v[0]
+		}
+		return fallback
+	}
+
+	t.width, t.height = first(tiffImageWidth, 0), first(tiffImageLength, 0)
+	t.samples = first(tiffSamplesPerPixel, 1)
+	t.rowsPerStrip = min(first(tiffRowsPerStrip, t.height), t.height)
+	t.offsets, t.counts = tags[tiffStripOffsets], tags[tiffStripByteCounts]
+	t.photometric = first(tiffPhotometric, -1)
+	compression := first(tiffCompression, 1)
+	t.lzw = compression == 5
+	t.predictor = first(tiffPredictor, 1) == 2
+
+	// sem BitsPerSample a imagem é de 1 bit
+	bitsPerSample := tags[tiffBitsPerSample]
+	if len(bitsPerSample) == 0 {
+		return t, errTIFFUnsupported
+	}
+	for _, bits := range bitsPerSample {
+		if bits != 8 {
+			return t, errTIFFUnsupported
+		}
+	}
+	gray := t.gray() && t.samples <= 2
+	rgb := t.photometric == 2 && t.samples >= 3 && t.samples <= 4
+	if t.width <= 0 || t.height <= 0 || !gray && !rgb || compression != 1 && compression != 5 ||
+		first(tiffPlanarConfig, 1) != 1 || first(tiffTileWidth, 0) != 0 || t.rowsPerStrip <= 0 {
+		return t, errTIFFUnsupported
+	}
+	if gray {
+		t.samples = max(1, t.samples)
+	}
+	if t.width > maxDecodePixels/t.height || t.width*t.samples > maxDecodePixels*4/t.height {
+		return t, fmt.Errorf("TIFF grande demais: %dx%d", t.width, t.height)
+	}
+	strips := (t.height + t.rowsPerStrip - 1) / t.rowsPerStrip
+	if len(t.offsets) != strips || len(t.counts) != strips {
+		return t, fmt.Errorf("o TIFF tem %d faixas mas %d offsets e %d tamanhos", strips, len(t.offsets), len(t.counts))
+	}
+	return t, nil
+}
+
+// gray diz se a fotometria é de tons de cinza (0 ou 1).
+func (t tiffImage) gray() bool {
+	return t.photometric == 0 || t.photometric == 1
+}
+
+func decodeTIFFConfig(r io.Reader) (image.Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	t, err := readTIFFIFD(data)
+	if err != nil {
+		return image.Config{}, err
+	}
+	model := color.RGBAModel
+	if t.gray() {
+		model = color.GrayModel
+	}
+	return image.Config{ColorModel: model, Width: t.width, Height: t.height}, nil
+}
+
+// decodeTIFF lê um TIFF de base: tons de cinza viram *image.Gray e RGB
+// *image.RGBA (canais extras, como o alfa, são ignorados).
+func decodeTIFF(r io.Reader) (image.Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	t, err := readTIFFIFD(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// as faixas precisam caber no arquivo e ter dados para as suas linhas
+	// antes de alocar a imagem, que o cabeçalho pode declarar enorme
+	rowBytes := t.width * t.samples
+	stripBytes := func(i int) int { return min(t.rowsPerStrip, t.height-i*t.rowsPerStrip) * rowBytes }
+	for i, offset := range t.offsets {
+		count := t.counts[i]
+		if offset < 0 || count < 0 || offset+count > len(data) {
+			return nil, fmt.Errorf("faixa %d do TIFF fora do arquivo", i)
+		}
+		limit := count
+		if t.lzw {
+			limit = lzwMaxOutput(count)
+		}
+		if limit < stripBytes(i) {
+			return nil, fmt.Errorf("faixa %d do TIFF truncada: %d bytes não dão %d bytes de pixels", i, count, stripBytes(i))
+		}
+	}
+
+	// pixels cresce com as faixas decodificadas, não com o tamanho declarado
+	var pixels []byte
+	for i, offset := range t.offsets {
+		strip := data[offset : offset+t.counts[i]]
+		want := stripBytes(i)
+		if t.lzw {
+			if strip, err = decodeTIFFLZW(strip, want); err != nil {
+				return nil, fmt.Errorf("faixa %d do TIFF: %w", i, err)
+			}
+		}
+		if len(strip) < want {
+			return nil, fmt.Errorf("faixa %d do TIFF truncada: %d de %d bytes", i, len(strip), want)
+		}
+		pixels = append(pixels, strip[:want]...)
+	}
+	if t.predictor {
+		// cada amostra foi gravada como a diferença para a do pixel anterior
+		for y := 0; y < t.height; y++ {
+			row := pixels[y*rowBytes : (y+1)*rowBytes]
+			for i := t.samples; i < len(row); i++ {
+				row[i] += row[i-t.samples]
+			}
+		}
+	}
+
+	rect := image.Rect(0, 0, t.width, t.height)
+	if t.gray() {
+		gray := image.NewGray(rect)
+		for i := range gray.Pix {
+			v := pixels[i*t.samples]
+			if t.photometric == 0 {
+				v = 255 - v
+			}
+			gray.Pix[i] = v
+		}
+		return gray, nil
+	}
+	rgba := image.NewRGBA(rect)
+	for i := 0; i < t.width*t.height; i++ {
+		copy(rgba.Pix[4*i:4*i+3], pixels[i*t.samples:])
+		rgba.Pix[4*i+3] = 255
+	}
+	return rgba, nil
+}
+
+// lzwMaxOutput é um teto para o que count bytes de LZW podem render: cada
+// código tem pelo menos 9 bits e vale no máximo um byte a mais que o
+// anterior, até o tamanho da tabela.
+func lzwMaxOutput(count int) int {
+	codes := count * 8 / 9
+	return codes * min(codes, 4096)
+}
+
+// decodeTIFFLZW descomprime uma faixa LZW do TIFF, que difere do
+// compress/lzw da biblioteca padrão: os códigos vêm do bit mais
+// significativo e crescem um código antes ("early change"). Para ao ler o
+// código de fim, ao acabarem os dados ou ao chegar a want bytes.
+func decodeTIFFLZW(src []byte, want int) ([]byte, error) {
+	const clear, eoi = 256, 257
+	// cada código aponta para um trecho da própria saída: a entrada nova é
+	// a anterior mais o primeiro byte da atual, que vem logo depois dela
+	type entry struct{ start, length int }
+	var table [4096]entry
+	out := make([]byte, 0, min(want, 1<<16))
+	var acc uint32
+	bits, pos := 0, 0
+	width, next := 9, 258
+	prev := entry{-1, 0}
+	for len(out) < want {
+		for bits < width && pos < len(src) {
+			acc = acc<<8 | uint32(src[pos])
+			pos++
+			bits += 8
+		}
+		if bits < width {
+			break
+		}
+		code := int(acc>>(bits-width)) & (1<<width - 1)
+		bits -= width
+
+		switch {
+		case code == eoi:
+			return out, nil
+		case code == clear:
+			width, next, prev = 9, 258, entry{-1, 0}
+			continue
+		case prev.start < 0:
+			if code > 255 {
+				return nil, fmt.Errorf("LZW inválido: código %d depois de limpar a tabela", code)
+			}
+			prev = entry{len(out), 1}
+			out = append(out, byte(code))
+			continue
+		}
+
+		start := len(out)
+		switch {
+		case code < 256:
+			out = append(out, byte(code))
+		case code < next:
+			e := table[code]
+			out = append(out, out[e.start:e.start+e.length]...)
+		case code == next:
+			out = append(out, out[prev.start:prev.start+prev.length]...)
+			out = append(out, out[prev.start])
+		default:
+			return nil, fmt.Errorf("LZW inválido: código %d com a tabela em %d", code, next)
+		}
+		if next < len(table) {
+			table[next] = entry{prev.start, prev.length + 1}
+			next++
+		}
+		prev = entry{start, len(out) - start}
+		if next == 1<<width-1 && width < 12 {
+			width++
+		}
+	}
+	return out, nil
+}
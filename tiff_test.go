@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image/color"
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestDecodeTIFFErrors(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/gray-strips.tif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a IFD começa no byte 8 com o número de entradas; cada entrada tem 12
+	// bytes e o valor curto fica no byte 8 dela
+	entry := func(tag uint16) int {
+		for i := 0; i < int(binary.LittleEndian.Uint16(fixture[8:])); i++ {
+			if binary.LittleEndian.Uint16(fixture[10+12*i:]) == tag {
+				return 10 + 12*i
+			}
+		}
+		t.Fatalf("tag %d fora da fixture", tag)
+		return 0
+	}
+	with := func(tag uint16, v uint16) []byte {
+		data := bytes.Clone(fixture)
+		binary.LittleEndian.PutUint16(data[entry(tag)+8:], v)
+		return data
+	}
+	// troca uma entrada por outra tag; SamplesPerPixel 1 é o padrão, então
+	// pode dar lugar a uma tag que a fixture não tem
+	retag := func(tag, newTag uint16, v uint16) []byte {
+		data := with(tag, v)
+		binary.LittleEndian.PutUint16(data[entry(tag):], newTag)
+		return data
+	}
+	tests := []struct {
+		name        string
+		data        []byte
+		unsupported bool
+	}{
+		{"cabeçalho incompleto", fixture[:6], false},
+		{"faixas truncadas", fixture[:len(fixture)-5], false},
+		{"IFD fora do arquivo", append(bytes.Clone(fixture[:4]), 0xff, 0xff, 0, 0), false},
+		{"16 bits", with(tiffBitsPerSample, 16), true},
+		{"JPEG", with(tiffCompression, 7), true},
+		{"paleta", with(tiffPhotometric, 3), true},
+		{"planos separados", retag(tiffSamplesPerPixel, tiffPlanarConfig, 2), true},
+		{"ladrilhos", retag(tiffSamplesPerPixel, tiffTileWidth, 16), true},
+		{"faixas a mais", with(tiffRowsPerStrip, 2), false},
+	}
+	for _, tt := range tests {
+		_, err := decodeTIFF(bytes.NewReader(tt.data))
+		if err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+			continue
+		}
+		if errors.Is(err, errTIFFUnsupported) != tt.unsupported {
+			t.Errorf("%s: errors.Is(errTIFFUnsupported) = %v: %v", tt.name, !tt.unsupported, err)
+		}
+	}
+}
+
+func TestDecodeTIFFLZW(t *testing.T) {
+	// "ABABABA" em LZW do TIFF: limpa, A, B, 258 (AB), 260 (ABA), fim, em
+	// códigos de 9 bits do mais significativo
+	codes := []int{256, 'A', 'B', 258, 260, 257}
+	var acc uint64
+	var src []byte
+	bits := 0
+	for _, c := range codes {
+		acc = acc<<9 | uint64(c)
+		bits += 9
+		for bits >= 8 {
+			bits -= 8
+			src = append(src, byte(acc>>bits))
+		}
+	}
+	src = append(src, byte(acc<<(8-bits)))
+
+	tests := []struct {
+		name string
+		want int
+		out  string
+	}{
+		{"tudo", 100, "ABABABA"},
+		{"para em want", 4, "ABAB"},
+	}
+	for _, tt := range tests {
+		got, err := decodeTIFFLZW(src, tt.want)
+		if err != nil || string(got[:min(len(got), tt.want)]) != tt.out {
+			t.Errorf("%s: %q, %v; esperado %q", tt.name, got, err, tt.out)
+		}
+	}
+	if _, err := decodeTIFFLZW([]byte{0x80, 0x5f, 0xf0}, 10); err == nil {
+		t.Error("código fora da tabela: sem erro")
+	}
+}
+
+// buildTIFF monta um TIFF little-endian de 8 bits com uma faixa só
+func buildTIFF(width, height, samples, photometric, compression int, strip []byte) []byte {
+	type tag struct{ id, typ, count, value int }
+	tags := []tag{
+		{tiffImageWidth, 4, 1, width},
+		{tiffImageLength, 4, 1, height},
+		{tiffBitsPerSample, 3, 1, 8}, // uma entrada basta: todas são conferidas
+		{tiffCompression, 3, 1, compression},
+		{tiffPhotometric, 3, 1, photometric},
+		{tiffStripOffsets, 4, 1, 0},
+		{tiffSamplesPerPixel, 3, 1, samples},
+		{tiffRowsPerStrip, 4, 1, height},
+		{tiffStripByteCounts, 4, 1, len(strip)},
+	}
+	data := []byte("II*\x00\x08\x00\x00\x00")
+	data = binary.LittleEndian.AppendUint16(data, uint16(len(tags)))
+	stripOffset := len(data) + 12*len(tags) + 4
+	for _, tg := range tags {
+		if tg.id == tiffStripOffsets {
+			tg.value = stripOffset
+		}
+		data = binary.LittleEndian.AppendUint16(data, uint16(tg.id))
+		data = binary.LittleEndian.AppendUint16(data, uint16(tg.typ))
+		data = binary.LittleEndian.AppendUint32(data, uint32(tg.count))
+		data = binary.LittleEndian.AppendUint32(data, uint32(tg.value))
+	}
+	data = append(data, 0, 0, 0, 0)
+	return append(data, strip...)
+}
+
+func TestDecodeTIFFSamples(t *testing.T) {
+	tests := []struct {
+		name        string
+		samples     int
+		photometric int
+		ok          bool
+	}{
+		{"cinza", 1, 1, true},
+		{"cinza com alfa", 2, 1, true},
+		{"cinza com 3 amostras", 3, 1, false},
+		{"cinza com 200 amostras", 200, 1, false},
+		{"RGB", 3, 2, true},
+		{"RGBA", 4, 2, true},
+		{"RGB com 2 amostras", 2, 2, false},
+		{"RGB com 5 amostras", 5, 2, false},
+	}
+	for _, tt := range tests {
+		data := buildTIFF(4, 2, tt.samples, tt.photometric, 1, make([]byte, 4*2*tt.samples))
+		_, err := decodeTIFF(bytes.NewReader(data))
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if err != nil && !errors.Is(err, errTIFFUnsupported) {
+			t.Errorf("%s: esperado errTIFFUnsupported: %v", tt.name, err)
+		}
+	}
+}
+
+func TestDecodeTIFFAllocation(t *testing.T) {
+	// 8000x8000 em LZW com 20 KB de zeros: o teto do LZW deixa passar a
+	// faixa, mas a decodificação acaba cedo e não pode reservar os 64 MB
+	// declarados
+	data := buildTIFF(8000, 8000, 1, 1, 5, make([]byte, 20000))
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := decodeTIFF(bytes.NewReader(data))
+	runtime.ReadMemStats(&after)
+	if err == nil {
+		t.Fatal("faixa curta: sem erro")
+	}
+	if used := after.TotalAlloc - before.TotalAlloc; used > 8<<20 {
+		t.Errorf("%d bytes alocados para %d bytes de arquivo", used, len(data))
+	}
+}
+
+func TestDecodeTIFFPhotometric(t *testing.T) {
+	// um pixel; a primeira amostra é 10 e as outras 200
+	tests := []struct {
+		name        string
+		samples     int
+		photometric int
+		want        color.Color
+	}{
+		{"cinza", 1, 1, color.Gray{10}},
+		{"cinza com alfa", 2, 1, color.Gray{10}},
+		{"cinza invertido com alfa", 2, 0, color.Gray{245}},
+		{"RGB", 3, 2, color.RGBA{10, 200, 200, 255}},
+		{"RGBA", 4, 2, color.RGBA{10, 200, 200, 255}},
+	}
+	for _, tt := range tests {
+		strip := bytes.Repeat([]byte{200}, tt.samples)
+		strip[0] = 10
+		data := buildTIFF(1, 1, tt.samples, tt.photometric, 1, strip)
+		img, err := decodeTIFF(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := img.ColorModel().Convert(img.At(0, 0)); got != tt.want {
+			t.Errorf("%s: %v, esperado %v", tt.name, got, tt.want)
+		}
+		config, err := decodeTIFFConfig(bytes.NewReader(data))
+		if err != nil || config.ColorModel != img.ColorModel() {
+			t.Errorf("%s: config com modelo %v, imagem com %v", tt.name, config.ColorModel, img.ColorModel())
+		}
+	}
+}
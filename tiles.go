@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Tile é um pedaço da imagem com a posição na grade e o deslocamento, em
+// pixels, do canto superior esquerdo na imagem original.
+type Tile struct {
+	Row, Col int
+	Offset   image.Point
+	Image    image.Image
+}
+
+// tileName é o nome do arquivo do ladrilho, ex: tile_r02_c03.png.
+func tileName(row, col int) string {
+	return fmt.Sprintf("tile_r%02d_c%02d.png", row, col)
+}
+
+// splitTiles corta a imagem em ladrilhos de tileW x tileH; os da última
+// coluna e da última linha ficam menores quando as dimensões não dividem.
+func splitTiles(img image.Image, tileW, tileH int) []Tile {
+	b := img.Bounds()
+	var tiles []Tile
+	for row, y := 0, b.Min.Y; y < b.Max.Y; row, y = row+1, y+tileH {
+		for col, x := 0, b.Min.X; x < b.Max.X; col, x = col+1, x+tileW {
+			r := image.Rect(x, y, x+tileW, y+tileH).Intersect(b)
+			tile := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+			var dst draw.Image = tile
+			if _, ok := img.(*image.Gray); ok {
+				dst = image.NewGray(tile.Bounds())
+			}
+			draw.Draw(dst, dst.Bounds(), img, r.Min, draw.Src)
+			tiles = append(tiles, Tile{Row: row, Col: col, Offset: r.Min.Sub(b.Min), Image: dst})
+		}
+	}
+	return tiles
+}
+
+// joinTiles remonta a imagem a partir de Row e Col. A grade tem que estar
+// completa, sem repetições, e todos os ladrilhos têm o tamanho do primeiro,
+// exceto os da última coluna (largura menor ou igual) e da última linha
+// (altura menor ou igual). O resultado é cinza se todos forem cinza.
+func joinTiles(tiles []Tile) (image.Image, error) {
+	if len(tiles) == 0 {
+		return nil, fmt.Errorf("nenhum ladrilho")
+	}
+	rows, cols := 0, 0
+	grid := map[image.Point]Tile{}
+	for _, t := range tiles {
+		if t.Row < 0 || t.Col < 0 {
+			return nil, fmt.Errorf("posição inválida r%02d_c%02d", t.Row, t.Col)
+		}
+		p := image.Pt(t.Col, t.Row)
+		if _, dup := grid[p]; dup {
+			return nil, fmt.Errorf("ladrilho repetido: %s", tileName(t.Row, t.Col))
+		}
+		grid[p] = t
+		rows, cols = max(rows, t.Row+1), max(cols, t.Col+1)
+	}
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			if _, ok := grid[image.Pt(col, row)]; !ok {
+				return nil, fmt.Errorf("falta o ladrilho da linha %d, coluna %d (%s)", row, col, tileName(row, col))
+			}
+		}
+	}
+
+	first := grid[image.Point{}].Image.Bounds()
+	tileW, tileH := first.Dx(), first.Dy()
+	width, height := 0, 0
+	allGray := true
+	for p, t := range grid {
+		size := t.Image.Bounds().Size()
+		okW := size.X == tileW || p.X == cols-1 && size.X <= tileW
+		okH := size.Y == tileH || p.Y == rows-1 && size.Y <= tileH
+		if !okW || !okH || size.X == 0 || size.Y == 0 {
+			return nil, fmt.Errorf("%s tem tamanho %dx%d, esperado %dx%d", tileName(p.Y, p.X), size.X, size.Y, tileW, tileH)
+		}
+		if p.Y == 0 {
+			width += size.X
+		}
+		if p.X == 0 {
+			height += size.Y
+		}
+		if _, ok := t.Image.(*image.Gray); !ok {
+			allGray = false
+		}
+	}
+	for p, t := range grid {
+		size := t.Image.Bounds().Size()
+		if p.X == cols-1 && size.X != grid[image.Pt(p.X, 0)].Image.Bounds().Dx() ||
+			p.Y == rows-1 && size.Y != grid[image.Pt(0, p.Y)].Image.Bounds().Dy() {
+			return nil, fmt.Errorf("%s não tem o tamanho dos vizinhos da mesma linha/coluna", tileName(p.Y, p.X))
+		}
+	}
+
+	var result draw.Image = image.NewRGBA(image.Rect(0, 0, width, height))
+	if allGray {
+		result = image.NewGray(result.Bounds())
+	}
+	for p, t := range grid {
+		at := image.Pt(p.X*tileW, p.Y*tileH)
+		b := t.Image.Bounds()
+		draw.Draw(result, image.Rectangle{at, at.Add(b.Size())}, t.Image, b.Min, draw.Src)
+	}
+	return result, nil
+}
+
+// gotoshop split -tile 512x512 [-out-dir tiles] imagem.png
+func runSplit(args []string) int {
+	fs := newFlagSet("split", "-tile LxA [-out-dir tiles] imagem.png")
+	size := fs.String("tile", "512x512", "tamanho dos ladrilhos (LxA)")
+	outDir := fs.String("out-dir", "tiles", "diretório dos ladrilhos")
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) != 1 {
+		fs.Usage()
+		return 2
+	}
+	tileW, tileH, err := parseSize(*size)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	img, meta, err := decodeImage(files[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	tiles := splitTiles(img, tileW, tileH)
+	for _, t := range tiles {
+		if err := saveImageMeta(filepath.Join(*outDir, tileName(t.Row, t.Col)), t.Image, meta); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	fmt.Printf("%d ladrilhos salvos em %s\n", len(tiles), *outDir)
+	return 0
+}
+
+// gotoshop join -dir tiles [-out joined.png]
+func runJoin(args []string) int {
+	fs := newFlagSet("join", "-dir tiles [-out joined.png]")
+	dir := fs.String("dir", "tiles", "diretório com os tile_rNN_cNN.png")
+	out := fs.String("out", "joined.png", "imagem remontada")
+
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(rest) != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	paths, err := filepath.Glob(filepath.Join(*dir, "tile_r*_c*.png"))
+	if err != nil || len(paths) == 0 {
+		fmt.Fprintf(os.Stderr, "nenhum ladrilho em %s\n", *dir)
+		return 1
+	}
+	sort.Strings(paths)
+
+	var tiles []Tile
+	var meta imageMeta
+	for _, path := range paths {
+		var t Tile
+		if _, err := fmt.Sscanf(filepath.Base(path), "tile_r%d_c%d.png", &t.Row, &t.Col); err != nil {
+			fmt.Fprintf(os.Stderr, "Aviso: nome inesperado %s\n", path)
+			continue
+		}
+		t.Image, meta, err = decodeImage(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		tiles = append(tiles, t)
+	}
+
+	img, err := joinTiles(tiles)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Erro ao remontar:", err)
+		return 1
+	}
+	if err := saveImageMeta(*out, img, meta); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Imagem %dx%d salva em %s\n", img.Bounds().Dx(), img.Bounds().Dy(), *out)
+	return 0
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+func TestSplitJoinRoundTrip(t *testing.T) {
+	gray := synthetic.Noise(103, 77, 128, 50, 1)
+	rgba := image.NewRGBA(image.Rect(0, 0, 50, 33))
+	for i := range rgba.Pix {
+		rgba.Pix[i] = uint8(i * 7)
+	}
+	tests := []struct {
+		name         string
+		img          image.Image
+		tileW, tileH int
+		wantTiles    int
+	}{
+		{"não divide", gray, 32, 20, 4 * 4},
+		{"divide", gray.SubImage(image.Rect(0, 0, 96, 60)), 32, 20, 3 * 3},
+		{"ladrilho maior que a imagem", gray, 200, 200, 1},
+		{"colorida", rgba, 16, 16, 4 * 3},
+		{"recorte fora da origem", gray.SubImage(image.Rect(10, 5, 60, 50)), 16, 16, 4 * 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tiles := splitTiles(tt.img, tt.tileW, tt.tileH)
+			if len(tiles) != tt.wantTiles {
+				t.Fatalf("%d ladrilhos, esperado %d", len(tiles), tt.wantTiles)
+			}
+			for _, tile := range tiles {
+				if want := image.Pt(tile.Col*tt.tileW, tile.Row*tt.tileH); tile.Offset != want {
+					t.Errorf("ladrilho r%d c%d com deslocamento %v, esperado %v", tile.Row, tile.Col, tile.Offset, want)
+				}
+			}
+			joined, err := joinTiles(tiles)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b := tt.img.Bounds()
+			if joined.Bounds().Size() != b.Size() {
+				t.Fatalf("remontada com %v, esperado %v", joined.Bounds().Size(), b.Size())
+			}
+			for y := 0; y < b.Dy(); y++ {
+				for x := 0; x < b.Dx(); x++ {
+					got := color.RGBAModel.Convert(joined.At(x, y))
+					want := color.RGBAModel.Convert(tt.img.At(b.Min.X+x, b.Min.Y+y))
+					if got != want {
+						t.Fatalf("pixel %d,%d: %v, esperado %v", x, y, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestJoinTilesErrors(t *testing.T) {
+	tiles := splitTiles(synthetic.Noise(100, 70, 128, 50, 1), 32, 32) // grade 4x3
+	without := func(row, col int) []Tile {
+		var rest []Tile
+		for _, tile := range tiles {
+			if tile.Row != row || tile.Col != col {
+				rest = append(rest, tile)
+			}
+		}
+		return rest
+	}
+	resized := append([]Tile(nil), tiles...)
+	resized[5].Image = image.NewGray(image.Rect(0, 0, 30, 32))
+
+	tests := []struct {
+		name  string
+		tiles []Tile
+		want  string
+	}{
+		{"falta um ladrilho", without(1, 2), "linha 1, coluna 2 (tile_r01_c02.png)"},
+		{"falta o canto", without(0, 0), "linha 0, coluna 0"},
+		{"repetido", append(append([]Tile(nil), tiles...), tiles[3]), "repetido: tile_r00_c03.png"},
+		{"tamanho errado", resized, "tile_r01_c01.png tem tamanho 30x32"},
+		{"nenhum", nil, "nenhum ladrilho"},
+	}
+	for _, tt := range tests {
+		_, err := joinTiles(tt.tiles)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: erro %v, esperado com %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestRunSplitJoin(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "grande.png")
+	img := synthetic.Noise(90, 50, 128, 50, 3)
+	if err := saveImage(src, img); err != nil {
+		t.Fatal(err)
+	}
+	tilesDir := filepath.Join(dir, "tiles")
+	if code := runSplit([]string{"-tile", "40x20", "-out-dir", tilesDir, src}); code != 0 {
+		t.Fatalf("split: código de saída %d", code)
+	}
+	if _, err := os.Stat(filepath.Join(tilesDir, "tile_r02_c02.png")); err != nil {
+		t.Errorf("ladrilho do canto não gravado: %v", err)
+	}
+
+	out := filepath.Join(dir, "junta.png")
+	if code := runJoin([]string{"-dir", tilesDir, "-out", out}); code != 0 {
+		t.Fatalf("join: código de saída %d", code)
+	}
+	joined, err := loadImage(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(joined.Pix, img.Pix) {
+		t.Error("imagem remontada difere da original")
+	}
+
+	if err := os.Remove(filepath.Join(tilesDir, "tile_r01_c00.png")); err != nil {
+		t.Fatal(err)
+	}
+	if code := runJoin([]string{"-dir", tilesDir, "-out", out}); code != 1 {
+		t.Errorf("join sem um ladrilho: código %d, esperado 1", code)
+	}
+}
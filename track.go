@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"processing-images/imaging"
+)
+
+// TrackedObject é um objeto de um quadro; Track é o identificador
+// persistente atribuído pelo rastreamento (0 antes disso).
+type TrackedObject struct {
+	Track    int
+	Frame    int
+	Centroid [2]float64
+	Area     int
+	Box      image.Rectangle
+}
+
+// frameObjects rotula os objetos (8-conexos) de um quadro binário e devolve
+// os que têm pelo menos minArea pixels.
+func frameObjects(img *image.Gray, fg polarity, minArea int) []TrackedObject {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	visited := make([]bool, width*height)
+	inside := func(x, y int) bool {
+		return fg.isForeground(img.Pix[img.PixOffset(b.Min.X+x, b.Min.Y+y)])
+	}
+
+	var objects []TrackedObject
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if visited[y*width+x] || !inside(x, y) {
+				continue
+			}
+			component := []int{y*width + x}
+			visited[y*width+x] = true
+			var sumX, sumY float64
+			box := image.Rect(x, y, x+1, y+1)
+			for i := 0; i < len(component); i++ {
+				px, py := component[i]%width, component[i]/width
+				sumX += float64(px)
+				sumY += float64(py)
+				box = box.Union(image.Rect(px, py, px+1, py+1))
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						nx, ny := px+dx, py+dy
+						if nx < 0 || ny < 0 || nx >= width || ny >= height || visited[ny*width+nx] || !inside(nx, ny) {
+							continue
+						}
+						visited[ny*width+nx] = true
+						component = append(component, ny*width+nx)
+					}
+				}
+			}
+			if len(component) < minArea {
+				continue
+			}
+			n := float64(len(component))
+			objects = append(objects, TrackedObject{
+				Centroid: [2]float64{sumX / n, sumY / n},
+				Area:     len(component),
+				Box:      box,
+			})
+		}
+	}
+	return objects
+}
+
+// trackObjects atribui identificadores persistentes aos objetos de uma
+// sequência de quadros. Cada objeto é ligado ao objeto do quadro anterior
+// com o centroide mais próximo, desde que a distância não passe de gate.
+// A associação é gulosa: os pares candidatos são ligados do mais próximo
+// para o mais distante, com empates decididos pela semelhança de área.
+// Objetos sem par ganham um novo identificador, e trilhas sem continuação
+// terminam (um objeto que some não "pula" para outro mais tarde).
+func trackObjects(frames [][]TrackedObject, gate float64) [][]TrackedObject {
+	next := 1
+	var previous []TrackedObject
+	for f, objects := range frames {
+		type candidate struct {
+			prev, cur int
+			distance  float64
+			areaRatio float64
+		}
+		var candidates []candidate
+		for i, p := range previous {
+			for j, c := range objects {
+				d := math.Hypot(c.Centroid[0]-p.Centroid[0], c.Centroid[1]-p.Centroid[1])
+				if d > gate {
+					continue
+				}
+				ratio := float64(min(p.Area, c.Area)) / float64(max(p.Area, c.Area))
+				candidates = append(candidates, candidate{i, j, d, ratio})
+			}
+		}
+		sort.Slice(candidates, func(a, b int) bool {
+			if candidates[a].distance != candidates[b].distance {
+				return candidates[a].distance < candidates[b].distance
+			}
+			return candidates[a].areaRatio > candidates[b].areaRatio
+		})
+
+		usedPrev := make([]bool, len(previous))
+		for i := range objects {
+			objects[i].Frame = f
+			objects[i].Track = 0
+		}
+		for _, c := range candidates {
+			if usedPrev[c.prev] || objects[c.cur].Track != 0 {
+				continue
+			}
+			usedPrev[c.prev] = true
+			objects[c.cur].Track = previous[c.prev].Track
+		}
+		for i := range objects {
+			if objects[i].Track == 0 {
+				objects[i].Track = next
+				next++
+			}
+		}
+		previous = objects
+	}
+	return frames
+}
+
+// trackColor escolhe uma cor fixa por trilha.
+func trackColor(track int) color.RGBA {
+	return imaging.DirectionColors[track%len(imaging.DirectionColors)]
+}
+
+// trackOverlay desenha a caixa e o número da trilha de cada objeto.
+func trackOverlay(img *image.Gray, objects []TrackedObject) *image.RGBA {
+	result := grayToRGBA(img)
+	for _, o := range objects {
+		c := trackColor(o.Track)
+		drawRect(result, o.Box, c)
+		drawText(result, o.Box.Min.X, o.Box.Min.Y-glyphHeight-2, strconv.Itoa(o.Track), 1, c)
+	}
+	return result
+}
+
+// writeTracksCSV grava uma linha por objeto e quadro.
+func writeTracksCSV(path string, frames [][]TrackedObject) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("erro ao criar %s: %w", path, err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"track", "frame", "x", "y", "area"})
+	for _, objects := range frames {
+		for _, o := range objects {
+			w.Write([]string{
+				strconv.Itoa(o.Track),
+				strconv.Itoa(o.Frame),
+				strconv.FormatFloat(o.Centroid[0], 'f', 2, 64),
+				strconv.FormatFloat(o.Centroid[1], 'f', 2, 64),
+				strconv.Itoa(o.Area),
+			})
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// gotoshop track [-gate 20] [-fg white] [-min-area 10] [-out-dir tracks] quadro...
+func runTrack(args []string) int {
+	fs := newFlagSet("track", "[-gate 20] [-fg white] [-out-dir tracks] quadro1.png quadro2.png...")
+	gate := fs.Float64("gate", 20, "deslocamento máximo de um objeto entre quadros, em pixels")
+	fgName := fs.String("fg", "white", "cor dos objetos nas máscaras: white ou black")
+	minArea := fs.Int("min-area", 10, "objetos menores são ignorados")
+	outDir := fs.String("out-dir", "tracks", "diretório de tracks.csv e dos quadros anotados")
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) == 0 {
+		fs.Usage()
+		return 2
+	}
+	fg, err := parsePolarity(*fgName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	images := make([]*image.Gray, len(files))
+	frames := make([][]TrackedObject, len(files))
+	for i, file := range files {
+		img, err := loadImage(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		images[i] = img
+		frames[i] = frameObjects(images[i], fg, *minArea)
+	}
+	trackObjects(frames, *gate)
+
+	tracks := map[int]bool{}
+	for i, objects := range frames {
+		for _, o := range objects {
+			tracks[o.Track] = true
+		}
+		if err := saveImage(filepath.Join(*outDir, fmt.Sprintf("track_%04d.png", i)), trackOverlay(images[i], objects)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if err := writeTracksCSV(filepath.Join(*outDir, "tracks.csv"), frames); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("%d quadros, %d trilhas\n", len(files), len(tracks))
+	return 0
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// squaresFrame é um quadro 128x128 preto com quadrados brancos de 8x8 com o
+// canto superior esquerdo nos pontos dados.
+func squaresFrame(corners ...image.Point) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 128, 128))
+	for _, c := range corners {
+		for y := c.Y; y < c.Y+8; y++ {
+			for x := c.X; x < c.X+8; x++ {
+				img.Pix[y*img.Stride+x] = 255
+			}
+		}
+	}
+	return img
+}
+
+// trackAt devolve a trilha do objeto com o canto superior esquerdo em p.
+func trackAt(objects []TrackedObject, p image.Point) int {
+	for _, o := range objects {
+		if o.Box.Min == p {
+			return o.Track
+		}
+	}
+	return -1
+}
+
+func TestTrackCrossingPaths(t *testing.T) {
+	// A anda para a direita em y=20 e B sobe em x=60; os caminhos se cruzam
+	// em (60, 20), mas em quadros diferentes, sempre a mais que o gate
+	var frames [][]TrackedObject
+	var a, b []image.Point
+	for f := 0; f < 17; f++ {
+		a = append(a, image.Pt(10+5*f, 20))
+		b = append(b, image.Pt(60, 100-5*f))
+		frames = append(frames, frameObjects(squaresFrame(a[f], b[f]), foregroundWhite, 10))
+	}
+	trackObjects(frames, 8)
+
+	trackA, trackB := trackAt(frames[0], a[0]), trackAt(frames[0], b[0])
+	if trackA == trackB || trackA <= 0 || trackB <= 0 {
+		t.Fatalf("trilhas iniciais %d e %d", trackA, trackB)
+	}
+	for f := range frames {
+		if got := trackAt(frames[f], a[f]); got != trackA {
+			t.Errorf("quadro %d: A na trilha %d, esperado %d", f, got, trackA)
+		}
+		if got := trackAt(frames[f], b[f]); got != trackB {
+			t.Errorf("quadro %d: B na trilha %d, esperado %d", f, got, trackB)
+		}
+	}
+}
+
+func TestTrackDisappearance(t *testing.T) {
+	stay, gone, late := image.Pt(10, 10), image.Pt(60, 60), image.Pt(64, 62)
+	frames := [][]TrackedObject{
+		frameObjects(squaresFrame(stay, gone), foregroundWhite, 10),
+		frameObjects(squaresFrame(stay, gone), foregroundWhite, 10),
+		frameObjects(squaresFrame(stay), foregroundWhite, 10),
+		// um objeto novo surge perto de onde o outro sumiu
+		frameObjects(squaresFrame(stay, late), foregroundWhite, 10),
+	}
+	trackObjects(frames, 20)
+
+	goneTrack := trackAt(frames[1], gone)
+	if trackAt(frames[0], gone) != goneTrack {
+		t.Fatal("objeto trocou de trilha antes de sumir")
+	}
+	if got := trackAt(frames[3], late); got == goneTrack || got == trackAt(frames[3], stay) {
+		t.Errorf("objeto novo herdou a trilha %d", got)
+	}
+	if got := trackAt(frames[3], stay); got != trackAt(frames[0], stay) {
+		t.Errorf("objeto fixo mudou para a trilha %d", got)
+	}
+}
+
+func TestTrackGreedyTieBreak(t *testing.T) {
+	// dois candidatos à mesma distância: fica o de área parecida
+	frames := [][]TrackedObject{
+		{{Centroid: [2]float64{50, 50}, Area: 100}},
+		{
+			{Centroid: [2]float64{45, 50}, Area: 20},
+			{Centroid: [2]float64{55, 50}, Area: 95},
+		},
+	}
+	trackObjects(frames, 10)
+	if frames[1][1].Track != frames[0][0].Track || frames[1][0].Track == frames[0][0].Track {
+		t.Errorf("trilhas %d e %d, esperado a de área parecida com %d", frames[1][0].Track, frames[1][1].Track, frames[0][0].Track)
+	}
+}
+
+func TestWriteTracksCSV(t *testing.T) {
+	frames := [][]TrackedObject{
+		{{Track: 1, Frame: 0, Centroid: [2]float64{3.5, 4}, Area: 64}},
+		{{Track: 1, Frame: 1, Centroid: [2]float64{8.25, 4}, Area: 60}},
+	}
+	path := filepath.Join(t.TempDir(), "tracks.csv")
+	if err := writeTracksCSV(path, frames); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "track,frame,x,y,area\n1,0,3.50,4.00,64\n1,1,8.25,4.00,60\n"
+	if string(data) != want {
+		t.Errorf("CSV:\n%s\nesperado:\n%s", data, want)
+	}
+}
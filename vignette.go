@@ -0,0 +1,128 @@
+package main
+
+import (
+	"image"
+	"math"
+
+	"processing-images/imaging"
+)
+
+// raio normalizado do pixel: 0 no centro, 1 nos cantos
+func normalizedRadius(x, y, width, height int) float64 {
+	cx, cy := float64(width-1)/2, float64(height-1)/2
+	halfDiagonal := math.Hypot(cx, cy)
+	if halfDiagonal == 0 {
+		return 0
+	}
+	return math.Hypot(float64(x)-cx, float64(y)-cy) / halfDiagonal
+}
+
+// estimateVignette ajusta uma superfície de iluminação radial
+// S(r) = c0 + c1·r² + … + cn·r^(2n), com n = polyOrder, por mínimos quadrados.
+// O ajuste é robusto ao conteúdo: os pixels abaixo do percentil 5 e acima do
+// 95 (objetos muito escuros ou reflexos) ficam de fora, e no máximo ~100 mil
+// pixels são amostrados.
+func estimateVignette(img *image.Gray, polyOrder int) [][]float64 {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if polyOrder < 1 {
+		polyOrder = 1
+	}
+	terms := polyOrder + 1
+
+	histogram := imaging.Histogram(img)
+	low, high := imaging.HistogramPercentile(histogram, 0.05), imaging.HistogramPercentile(histogram, 0.95)
+
+	step := max(1, int(math.Sqrt(float64(width*height)/100000)))
+	A := make([][]float64, terms)
+	for i := range A {
+		A[i] = make([]float64, terms)
+	}
+	b := make([]float64, terms)
+	basis := make([]float64, terms)
+	for y := 0; y < height; y += step {
+		for x := 0; x < width; x += step {
+			v := img.Pix[y*img.Stride+x]
+			if v < low || v > high {
+				continue
+			}
+			r2 := math.Pow(normalizedRadius(x, y, width, height), 2)
+			basis[0] = 1
+			for k := 1; k < terms; k++ {
+				basis[k] = basis[k-1] * r2
+			}
+			for i := 0; i < terms; i++ {
+				for j := 0; j < terms; j++ {
+					A[i][j] += basis[i] * basis[j]
+				}
+				b[i] += basis[i] * float64(v)
+			}
+		}
+	}
+
+	coeffs, err := solveLinear(A, b)
+	if err != nil {
+		// sem amostras suficientes: iluminação uniforme
+		coeffs = make([]float64, terms)
+		coeffs[0] = 1
+	}
+
+	surface := make([][]float64, height)
+	for y := 0; y < height; y++ {
+		surface[y] = make([]float64, width)
+		for x := 0; x < width; x++ {
+			r2 := math.Pow(normalizedRadius(x, y, width, height), 2)
+			var s, p float64 = 0, 1
+			for _, c := range coeffs {
+				s += c * p
+				p *= r2
+			}
+			surface[y][x] = s
+		}
+	}
+	return surface
+}
+
+// correctVignette divide a imagem pela superfície de iluminação, levando cada
+// pixel ao brilho que teria no ponto mais iluminado.
+func correctVignette(img *image.Gray, surface [][]float64) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	var peak float64
+	for _, row := range surface {
+		for _, s := range row {
+			peak = math.Max(peak, s)
+		}
+	}
+
+	result := make([][]float64, height)
+	for y := 0; y < height; y++ {
+		result[y] = make([]float64, width)
+		for x := 0; x < width; x++ {
+			v := float64(img.Pix[y*img.Stride+x])
+			if s := surface[y][x]; s > 0 {
+				v = v * peak / s
+			}
+			result[y][x] = v
+		}
+	}
+	return imaging.FloatToGray(result, 255)
+}
+
+// applyVignette escurece a imagem a partir do centro, multiplicando cada pixel
+// por 1 − strength·(r/radius)², com r normalizado (1 nos cantos). Serve para
+// gerar dados de teste para correctVignette.
+func applyVignette(img *image.Gray, strength, radius float64) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if radius <= 0 {
+		radius = 1
+	}
+	result := make([][]float64, height)
+	for y := 0; y < height; y++ {
+		result[y] = make([]float64, width)
+		for x := 0; x < width; x++ {
+			r := normalizedRadius(x, y, width, height) / radius
+			factor := math.Max(0, 1-strength*r*r)
+			result[y][x] = float64(img.Pix[y*img.Stride+x]) * factor
+		}
+	}
+	return imaging.FloatToGray(result, 255)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// meanIn é a média dos pixels do retângulo.
+func meanIn(img *image.Gray, r image.Rectangle) float64 {
+	var sum float64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			sum += float64(img.Pix[y*img.Stride+x])
+		}
+	}
+	return sum / float64(r.Dx()*r.Dy())
+}
+
+func TestVignetteRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		img      *image.Gray
+		strength float64
+		order    int
+		maxDiff  float64 // erro médio tolerado em níveis de cinza
+	}{
+		{"uniforme", uniformGray(128, 200), 0.5, 1, 2},
+		{"uniforme, ordem 2", uniformGray(128, 200), 0.5, 2, 2},
+		{"com ruído", synthetic.Noise(128, 128, 180, 4, 1), 0.4, 2, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dark := applyVignette(tt.img, tt.strength, 1)
+			corrected := correctVignette(dark, estimateVignette(dark, tt.order))
+
+			var diff float64
+			for i := range tt.img.Pix {
+				diff += math.Abs(float64(corrected.Pix[i]) - float64(tt.img.Pix[i]))
+			}
+			if diff /= float64(len(tt.img.Pix)); diff > tt.maxDiff {
+				t.Errorf("erro médio de %.2f níveis após corrigir, esperado <= %v", diff, tt.maxDiff)
+			}
+
+			center := image.Rect(56, 56, 72, 72)
+			corner := image.Rect(0, 0, 16, 16)
+			before := meanIn(dark, center) - meanIn(dark, corner)
+			after := meanIn(corrected, center) - meanIn(corrected, corner)
+			if math.Abs(after) > 3 {
+				t.Errorf("centro e canto diferem em %.1f após corrigir (antes %.1f)", after, before)
+			}
+		})
+	}
+}
+
+func TestApplyVignette(t *testing.T) {
+	dark := applyVignette(uniformGray(65, 200), 0.5, 1)
+	tests := []struct {
+		name string
+		x, y int
+		want uint8
+	}{
+		{"centro", 32, 32, 200},
+		{"canto", 0, 0, 100},
+		{"borda", 0, 32, 150}, // r² = 1/2
+	}
+	for _, tt := range tests {
+		if got := dark.Pix[tt.y*dark.Stride+tt.x]; got != tt.want {
+			t.Errorf("%s: %d, esperado %d", tt.name, got, tt.want)
+		}
+	}
+}
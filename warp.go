@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// As transformações levam a posição (x, y) da imagem de origem para a de
+// destino. Afim: m = [a b c d e f], com x' = a·x + b·y + c e
+// y' = d·x + e·y + f. Perspectiva (homografia): h é a matriz 3×3 por
+// linhas, com x' = (h0·x + h1·y + h2)/w e y' = (h3·x + h4·y + h5)/w,
+// w = h6·x + h7·y + h8. As coordenadas são as dos centros dos pixels.
+
+// warpAffine aplica a transformação afim m pelo mapeamento inverso: cada
+// pixel de saída, do mesmo tamanho da entrada, procura a sua origem pela
+// inversa de m e interpola por interp (nearest, bilinear ou bicubic); o
+// que cai fora da entrada recebe fill. m sem inversa (que achata a imagem
+// numa linha) é erro.
+func warpAffine(img *image.Gray, m [6]float64, interp string, fill uint8) (*image.Gray, error) {
+	h := [9]float64{m[0], m[1], m[2], m[3], m[4], m[5], 0, 0, 1}
+	return warpPerspective(img, h, interp, fill)
+}
+
+// warpPerspective aplica a homografia h como warpAffine. Os pontos de saída
+// que a inversa leva para o infinito ou para trás do plano (w <= 0) recebem
+// fill.
+func warpPerspective(img *image.Gray, h [9]float64, interp string, fill uint8) (*image.Gray, error) {
+	method, err := parseInterpolation(interp)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := invert3(h)
+	if err != nil {
+		return nil, fmt.Errorf("transformação sem inversa: %w", err)
+	}
+	return warpInverse(img, inv, method, fill), nil
+}
+
+// warpInverse monta a saída, do tamanho da entrada, buscando cada pixel na
+// entrada pela homografia inversa inv.
+func warpInverse(img *image.Gray, inv [9]float64, method interpolation, fill uint8) *image.Gray {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	result := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			fx, fy := float64(x), float64(y)
+			w := inv[6]*fx + inv[7]*fy + inv[8]
+			if w <= 1e-12 {
+				result.Pix[y*result.Stride+x] = fill
+				continue
+			}
+			sx := (inv[0]*fx + inv[1]*fy + inv[2]) / w
+			sy := (inv[3]*fx + inv[4]*fy + inv[5]) / w
+			result.Pix[y*result.Stride+x] = sample(img, sx, sy, fill, method)
+		}
+	}
+	return result
+}
+
+// invert3 inverte a matriz 3×3 (por linhas) pela adjunta. O determinante
+// é comparado à escala dos elementos, porque uma homografia vale o mesmo
+// multiplicada por qualquer número.
+func invert3(m [9]float64) ([9]float64, error) {
+	cof := [9]float64{
+		m[4]*m[8] - m[5]*m[7], m[2]*m[7] - m[1]*m[8], m[1]*m[5] - m[2]*m[4],
+		m[5]*m[6] - m[3]*m[8], m[0]*m[8] - m[2]*m[6], m[2]*m[3] - m[0]*m[5],
+		m[3]*m[7] - m[4]*m[6], m[1]*m[6] - m[0]*m[7], m[0]*m[4] - m[1]*m[3],
+	}
+	det := m[0]*cof[0] + m[1]*cof[3] + m[2]*cof[6]
+	scale := 0.0
+	for _, v := range m {
+		scale = math.Max(scale, math.Abs(v))
+	}
+	if scale == 0 || math.Abs(det) < 1e-12*scale*scale*scale {
+		return [9]float64{}, errSingular
+	}
+	for i := range cof {
+		cof[i] /= det
+	}
+	return cof, nil
+}
+
+// affineFromPoints acha a transformação afim que leva os três pontos src
+// para dst. Pontos de origem colineares não definem uma.
+func affineFromPoints(src, dst [3]image.Point) ([6]float64, error) {
+	A := make([][]float64, 3)
+	for i, p := range src {
+		A[i] = []float64{float64(p.X), float64(p.Y), 1}
+	}
+	bx := []float64{float64(dst[0].X), float64(dst[1].X), float64(dst[2].X)}
+	by := []float64{float64(dst[0].Y), float64(dst[1].Y), float64(dst[2].Y)}
+	rowX, err := solveLinear(A, bx)
+	if err != nil {
+		return [6]float64{}, fmt.Errorf("pontos de origem colineares: %w", err)
+	}
+	rowY, err := solveLinear(A, by)
+	if err != nil {
+		return [6]float64{}, fmt.Errorf("pontos de origem colineares: %w", err)
+	}
+	return [6]float64{rowX[0], rowX[1], rowX[2], rowY[0], rowY[1], rowY[2]}, nil
+}
+
+// homographyFromPoints acha a homografia que leva os quatro pontos src para
+// dst, fixando h8 = 1: cada par dá duas equações lineares nos outros oito
+// coeficientes. Três pontos colineares em qualquer dos lados não definem
+// uma.
+func homographyFromPoints(src, dst [4]image.Point) ([9]float64, error) {
+	A := make([][]float64, 0, 8)
+	b := make([]float64, 0, 8)
+	for i := range src {
+		x, y := float64(src[i].X), float64(src[i].Y)
+		u, v := float64(dst[i].X), float64(dst[i].Y)
+		A = append(A,
+			[]float64{x, y, 1, 0, 0, 0, -u * x, -u * y},
+			[]float64{0, 0, 0, x, y, 1, -v * x, -v * y})
+		b = append(b, u, v)
+	}
+	s, err := solveLinear(A, b)
+	if err != nil {
+		return [9]float64{}, fmt.Errorf("pontos degenerados para a homografia: %w", err)
+	}
+	return [9]float64{s[0], s[1], s[2], s[3], s[4], s[5], s[6], s[7], 1}, nil
+}
+
+// parseQuad lê os quatro cantos de -perspective, "x1,y1 x2,y2 x3,y3 x4,y4".
+func parseQuad(s string) ([4]image.Point, error) {
+	var quad [4]image.Point
+	points, err := parsePolygon(s)
+	if err == nil && len(points) != 4 {
+		err = fmt.Errorf("são %d cantos", len(points))
+	}
+	if err != nil {
+		return quad, fmt.Errorf("-perspective precisa de 4 cantos \"x1,y1 x2,y2 x3,y3 x4,y4\": %w", err)
+	}
+	for i, p := range points {
+		quad[i] = image.Pt(int(math.Round(p[0])), int(math.Round(p[1])))
+	}
+	return quad, nil
+}
+
+// rectifyQuad endireita o quadrilátero quad (cantos superior esquerdo,
+// superior direito, inferior direito e inferior esquerdo, como uma página
+// fotografada de lado), esticando-o sobre a imagem inteira; imagens
+// coloridas canal a canal.
+func rectifyQuad(img image.Image, quad [4]image.Point, method interpolation, fill uint8) (image.Image, error) {
+	w, h := img.Bounds().Dx()-1, img.Bounds().Dy()-1
+	corners := [4]image.Point{{0, 0}, {w, 0}, {w, h}, {0, h}}
+	hom, err := homographyFromPoints(quad, corners)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := invert3(hom)
+	if err != nil {
+		return nil, fmt.Errorf("transformação sem inversa: %w", err)
+	}
+	return perChannel(img, func(gray *image.Gray) *image.Gray {
+		return warpInverse(gray, inv, method, fill)
+	}), nil
+}
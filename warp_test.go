@@ -0,0 +1,205 @@
+package main
+
+import (
+	"errors"
+	"image"
+	"math"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+func TestRectifyTiltedCheckerboard(t *testing.T) {
+	// o tabuleiro é inclinado por uma homografia conhecida e endireitado
+	// pelos quatro cantos: as casas voltam a ter bordas retas, alinhadas
+	// aos eixos a cada 20 pixels
+	const size, square = 160, 20
+	board := synthetic.Checkerboard(size, size, square)
+	quad := [4]image.Point{{22, 12}, {150, 30}, {138, 152}, {8, 128}}
+	corners := [4]image.Point{{0, 0}, {size - 1, 0}, {size - 1, size - 1}, {0, size - 1}}
+	tilt, err := homographyFromPoints(corners, quad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tilted, err := warpPerspective(board, tilt, "bilinear", 128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, method := range []interpolation{interpNearest, interpBilinear, interpBicubic} {
+		rectified, err := rectifyQuad(tilted, quad, method, 128)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := rectified.(*image.Gray)
+		for y := 2; y < size-2; y++ {
+			for x := 2; x < size-2; x++ {
+				// longe das bordas das casas o valor é o do tabuleiro
+				if dx, dy := x%square, y%square; dx < 2 || dx >= square-2 || dy < 2 || dy >= square-2 {
+					continue
+				}
+				if (got.GrayAt(x, y).Y >= 128) != (board.GrayAt(x, y).Y >= 128) {
+					t.Fatalf("método %d: pixel %d,%d na casa errada", method, x, y)
+				}
+			}
+		}
+		// cada linha troca de cor só junto das bordas verticais das casas
+		for y := 2; y < size-2; y += 3 {
+			if dy := y % square; dy < 2 || dy >= square-2 {
+				continue
+			}
+			for x := 1; x < size; x++ {
+				if (got.GrayAt(x, y).Y >= 128) == (got.GrayAt(x-1, y).Y >= 128) {
+					continue
+				}
+				if off := x % square; off > 1 && off < square-1 {
+					t.Fatalf("método %d: linha %d troca de cor em x=%d, longe das bordas", method, y, x)
+				}
+			}
+		}
+	}
+}
+
+func TestHomographyFromPoints(t *testing.T) {
+	want := [9]float64{1.1, 0.2, 5, -0.1, 0.9, 8, 0.001, 0.0005, 1}
+	apply := func(h [9]float64, x, y float64) (float64, float64) {
+		w := h[6]*x + h[7]*y + h[8]
+		return (h[0]*x + h[1]*y + h[2]) / w, (h[3]*x + h[4]*y + h[5]) / w
+	}
+	src := [4]image.Point{{0, 0}, {100, 0}, {100, 80}, {0, 80}}
+	var dst [4]image.Point
+	for i, p := range src {
+		u, v := apply(want, float64(p.X), float64(p.Y))
+		dst[i] = image.Pt(int(math.Round(u)), int(math.Round(v)))
+	}
+	got, err := homographyFromPoints(src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range src {
+		u, v := apply(got, float64(p.X), float64(p.Y))
+		if math.Abs(u-float64(dst[i].X)) > 1e-6 || math.Abs(v-float64(dst[i].Y)) > 1e-6 {
+			t.Errorf("canto %v vai para %.3f,%.3f, esperado %v", p, u, v, dst[i])
+		}
+	}
+
+	degenerate := [4]image.Point{{0, 0}, {10, 10}, {20, 20}, {0, 5}}
+	if _, err := homographyFromPoints(degenerate, src); !errors.Is(err, errSingular) {
+		t.Errorf("três pontos colineares: erro %v", err)
+	}
+}
+
+func TestAffineFromPoints(t *testing.T) {
+	tests := []struct {
+		name string
+		m    [6]float64
+	}{
+		{"identidade", [6]float64{1, 0, 0, 0, 1, 0}},
+		{"translação", [6]float64{1, 0, 7, 0, 1, -3}},
+		{"cisalhamento e escala", [6]float64{2, 1, 0, 0, 3, 4}},
+		{"espelho", [6]float64{-1, 0, 50, 0, 1, 0}},
+	}
+	src := [3]image.Point{{0, 0}, {10, 0}, {0, 10}}
+	for _, tt := range tests {
+		var dst [3]image.Point
+		for i, p := range src {
+			x, y := float64(p.X), float64(p.Y)
+			dst[i] = image.Pt(int(tt.m[0]*x+tt.m[1]*y+tt.m[2]), int(tt.m[3]*x+tt.m[4]*y+tt.m[5]))
+		}
+		got, err := affineFromPoints(src, dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range got {
+			if math.Abs(got[i]-tt.m[i]) > 1e-9 {
+				t.Errorf("%s: %v, esperado %v", tt.name, got, tt.m)
+				break
+			}
+		}
+	}
+	if _, err := affineFromPoints([3]image.Point{{0, 0}, {5, 5}, {9, 9}}, src); !errors.Is(err, errSingular) {
+		t.Errorf("pontos colineares: erro %v", err)
+	}
+}
+
+func TestWarpAffine(t *testing.T) {
+	img := randomImage(15, 11, 8)
+	for _, interp := range []string{"nearest", "bilinear", "bicubic"} {
+		// identidade e translação inteira não interpolam nada
+		same, err := warpAffine(img, [6]float64{1, 0, 0, 0, 1, 0}, interp, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameGray(same, img) {
+			t.Errorf("%s: a identidade mudou a imagem", interp)
+		}
+		shifted, err := warpAffine(img, [6]float64{1, 0, 3, 0, 1, -2}, interp, 44)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameGray(shifted, translate(img, 3, -2, 44)) {
+			t.Errorf("%s: a translação difere de translate", interp)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		m      [6]float64
+		interp string
+	}{
+		{"sem inversa", [6]float64{1, 2, 0, 2, 4, 0}, "bilinear"},
+		{"zero", [6]float64{}, "bilinear"},
+		{"interpolação desconhecida", [6]float64{1, 0, 0, 0, 1, 0}, "lanczos"},
+	}
+	for _, tt := range tests {
+		if _, err := warpAffine(img, tt.m, tt.interp, 0); err == nil {
+			t.Errorf("%s: sem erro", tt.name)
+		}
+	}
+}
+
+func TestInvert3(t *testing.T) {
+	m := [9]float64{2, 1, 3, 0, 1, 4, 1, 0, 5}
+	inv, err := invert3(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			var sum float64
+			for k := 0; k < 3; k++ {
+				sum += m[i*3+k] * inv[k*3+j]
+			}
+			want := 0.0
+			if i == j {
+				want = 1
+			}
+			if math.Abs(sum-want) > 1e-12 {
+				t.Fatalf("m·m⁻¹[%d][%d] = %g", i, j, sum)
+			}
+		}
+	}
+	// a escala da homografia não muda a decisão
+	if _, err := invert3([9]float64{1e-6, 0, 0, 0, 1e-6, 0, 0, 0, 1e-6}); err != nil {
+		t.Errorf("identidade escalada: %v", err)
+	}
+}
+
+func TestParseQuad(t *testing.T) {
+	tests := []struct {
+		in   string
+		want [4]image.Point
+		ok   bool
+	}{
+		{"10,20 300,15 310,400 5,390", [4]image.Point{{10, 20}, {300, 15}, {310, 400}, {5, 390}}, true},
+		{"0,0 1.6,0 2,2.4 0,2", [4]image.Point{{0, 0}, {2, 0}, {2, 2}, {0, 2}}, true},
+		{"0,0 1,0 1,1", [4]image.Point{}, false},
+		{"0,0 1,0 1,1 0,1 2,2", [4]image.Point{}, false},
+		{"a,b c,d e,f g,h", [4]image.Point{}, false},
+	}
+	for _, tt := range tests {
+		got, err := parseQuad(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%q: %v, %v", tt.in, got, err)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"path/filepath"
+	"testing"
+
+	"processing-images/synthetic"
+)
+
+// imageMSE é o erro quadrático médio entre duas imagens do mesmo tamanho.
+func imageMSE(a, b *image.Gray) float64 {
+	var sum float64
+	width, height := a.Bounds().Dx(), a.Bounds().Dy()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			d := float64(a.GrayAt(a.Bounds().Min.X+x, a.Bounds().Min.Y+y).Y) - float64(b.GrayAt(b.Bounds().Min.X+x, b.Bounds().Min.Y+y).Y)
+			sum += d * d
+		}
+	}
+	return sum / float64(width*height)
+}
+
+func TestWatermarkRoundTrip(t *testing.T) {
+	img := synthetic.SiemensStar(128, 96, 12)
+	capacity := 128*96/8 - watermarkOverhead
+	tests := []struct {
+		name    string
+		payload []byte
+	}{
+		{"vazio", []byte{}},
+		{"um byte", []byte{0}},
+		{"texto", []byte("batch-42")},
+		{"binário", bytes.Repeat([]byte{0xff, 0x00, 0xa5}, 100)},
+		{"capacidade cheia", bytes.Repeat([]byte{'x'}, capacity)},
+	}
+	for _, tt := range tests {
+		marked, err := embedWatermark(img, tt.payload)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got, err := extractWatermark(marked, defaultWatermarkSeed)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !bytes.Equal(got, tt.payload) {
+			t.Errorf("%s: recuperado %q, esperado %q", tt.name, got, tt.payload)
+		}
+		if p := psnr(imageMSE(img, marked)); p < 50 {
+			t.Errorf("%s: PSNR %.1f dB, esperado acima de 50", tt.name, p)
+		}
+	}
+
+	if _, err := embedWatermark(img, bytes.Repeat([]byte{'x'}, capacity+1)); err == nil {
+		t.Error("payload acima da capacidade sem erro")
+	}
+}
+
+func TestWatermarkMissing(t *testing.T) {
+	img := synthetic.SiemensStar(64, 64, 8)
+	marked, err := embedWatermarkSeed(img, []byte("batch-42"), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noisy := synthetic.Noise(64, 64, 128, 40, 1)
+	tests := []struct {
+		name string
+		img  *image.Gray
+		seed int64
+	}{
+		{"sem marca", img, defaultWatermarkSeed},
+		{"ruído", noisy, defaultWatermarkSeed},
+		{"toda preta", uniformGray(64, 0), defaultWatermarkSeed},
+		{"outra semente", marked, defaultWatermarkSeed},
+		{"pequena demais", uniformGray(7, 0), defaultWatermarkSeed},
+	}
+	for _, tt := range tests {
+		if got, err := extractWatermark(tt.img, tt.seed); !errors.Is(err, errNoWatermark) {
+			t.Errorf("%s: %q, %v; esperado errNoWatermark", tt.name, got, err)
+		}
+	}
+}
+
+func TestWatermarkSubImage(t *testing.T) {
+	img := synthetic.SiemensStar(100, 100, 8)
+	sub := img.SubImage(image.Rect(20, 30, 84, 94)).(*image.Gray)
+	marked, err := embedWatermark(sub, []byte("recorte"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := extractWatermark(marked, defaultWatermarkSeed); err != nil || string(got) != "recorte" {
+		t.Errorf("recuperado %q, %v", got, err)
+	}
+	if d := imageMSE(sub, marked); d > 1 {
+		t.Errorf("MSE %.3f entre o recorte e a marcada", d)
+	}
+}
+
+func TestRunWatermarkRead(t *testing.T) {
+	dir := t.TempDir()
+	marked, err := embedWatermark(synthetic.SiemensStar(64, 64, 8), []byte("batch-42"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	markedPath, cleanPath := filepath.Join(dir, "marcada.png"), filepath.Join(dir, "limpa.png")
+	if err := saveImage(markedPath, marked); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveImage(cleanPath, uniformGray(64, 100)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"marcada", []string{markedPath}, 0},
+		{"sem marca", []string{cleanPath}, 1},
+		{"semente errada", []string{"-seed", "1", markedPath}, 1},
+		{"sem arquivos", nil, 2},
+	}
+	for _, tt := range tests {
+		if code := runWatermarkRead(tt.args); code != tt.code {
+			t.Errorf("%s: código %d, esperado %d", tt.name, code, tt.code)
+		}
+	}
+}
+
+func TestSaveImageWatermark(t *testing.T) {
+	old := *watermarkText
+	*watermarkText = "lote-7"
+	t.Cleanup(func() { *watermarkText = old })
+
+	path := filepath.Join(t.TempDir(), "saida.png")
+	if err := saveImage(path, uniformGray(64, 100)); err != nil {
+		t.Fatal(err)
+	}
+	img, err := loadImage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := extractWatermark(img, *watermarkSeed); err != nil || string(got) != "lote-7" {
+		t.Errorf("recuperado %q, %v; esperado lote-7", got, err)
+	}
+}